@@ -2,17 +2,33 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"runtime"
+	"sort"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apiroles"
 	"github.com/openshift/rbac-permissions-operator/pkg/apis"
 	"github.com/openshift/rbac-permissions-operator/pkg/controller"
+	"github.com/openshift/rbac-permissions-operator/pkg/controller/grouppermission"
+	"github.com/openshift/rbac-permissions-operator/pkg/gc"
+	"github.com/openshift/rbac-permissions-operator/pkg/inventory"
+	"github.com/openshift/rbac-permissions-operator/pkg/migration"
+	"github.com/openshift/rbac-permissions-operator/pkg/readonly"
+	"github.com/openshift/rbac-permissions-operator/pkg/simulation"
+	"github.com/openshift/rbac-permissions-operator/pkg/tracing"
+	"github.com/openshift/rbac-permissions-operator/pkg/webhook"
+	"github.com/openshift/rbac-permissions-operator/version"
 
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	"github.com/operator-framework/operator-sdk/pkg/leader"
@@ -21,6 +37,9 @@ import (
 	"github.com/operator-framework/operator-sdk/pkg/restmapper"
 	sdkVersion "github.com/operator-framework/operator-sdk/version"
 	"github.com/spf13/pflag"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
@@ -45,8 +64,72 @@ func printVersion() {
 	log.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	log.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
 	log.Info(fmt.Sprintf("Version of operator-sdk: %v", sdkVersion.Version))
+	log.Info(fmt.Sprintf("Operator Version: %s, Commit: %s", version.Version, version.Commit))
 }
 
+// leaderLockName returns the name of the leader lock of the operator instances of shard and of the
+// Namespaces they are scoped to, each shard has its own leader
+func leaderLockName(shard string, namespaces []string, namespaceSelector string) string {
+	if shard == "" && len(namespaces) == 0 && namespaceSelector == "" {
+		return "rbac-permissions-operator-lock"
+	}
+	key := shard
+	if len(namespaces) > 0 || namespaceSelector != "" {
+		sorted := append([]string{}, namespaces...)
+		sort.Strings(sorted)
+		key = strings.Join([]string{shard, strings.Join(sorted, ","), namespaceSelector}, ";")
+	}
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("rbac-permissions-operator-lock-%x", sum[:5])
+}
+
+// verify prints the drift between the bindings the GroupPermissions of namespace in the shard of opts
+// resolve to and the cluster, and writes it to output as JSON when set. Returns the exit code of the
+// operator: 0 without drift, 1 on error and 2 on drift.
+func verify(ctx context.Context, cfg *rest.Config, namespace, output string, opts []grouppermission.Option) int {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		log.Error(err, "")
+		return 1
+	}
+	apiClient, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		log.Error(err, "")
+		return 1
+	}
+
+	drift, err := grouppermission.Verify(ctx, apiClient, namespace, time.Now(), opts...)
+	if err != nil {
+		log.Error(err, "Failed to verify the bindings")
+		return 1
+	}
+	drift.Write(os.Stdout)
+	if output != "" {
+		data, err := json.MarshalIndent(drift, "", "  ")
+		if err != nil {
+			log.Error(err, "")
+			return 1
+		}
+		if err := ioutil.WriteFile(output, data, 0644); err != nil {
+			log.Error(err, "Failed to write the drift", "File", output)
+			return 1
+		}
+	}
+
+	if !drift.IsEmpty() {
+		log.Info("Found drift", "Missing", len(drift.Missing), "Changed", len(drift.Changed), "Unexpected", len(drift.Unexpected))
+		return 2
+	}
+	log.Info("The bindings match the GroupPermissions")
+	return 0
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;create;update,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups=apps,resources=deployments;replicasets,verbs=get,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups=apps,resources=deployments/finalizers,resourceNames=rbac-permissions-operator,verbs=update,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;create,namespace=openshift-rbac-permissions-operator
+
 func main() {
 	// Add the zap logger flag set to the CLI. The flag set must
 	// be added before calling pflag.Parse().
@@ -56,6 +139,21 @@ func main() {
 	// controller-runtime)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 
+	gcDryRun := pflag.Bool("gc-dry-run", false, "Only log orphaned managed bindings found on startup instead of deleting them")
+	inventoryInterval := pflag.Duration("inventory-interval", time.Hour, "Interval between reports of the inventory of the managed bindings, 0 disables them")
+	shard := pflag.String("shard", "", "Label selector of the GroupPermissions reconciled by this instance, every GroupPermission when empty")
+	watchNamespaces := pflag.StringSlice("watch-namespaces", nil, "Namespaces whose GroupPermissions are reconciled by this instance instead of the WATCH_NAMESPACE one, the instance must be allowed to watch GroupPermissions cluster wide")
+	watchNamespaceSelector := pflag.String("watch-namespace-selector", "", "Label selector of Namespaces whose GroupPermissions are reconciled by this instance, in addition to --watch-namespaces")
+	verifyOnly := pflag.Bool("verify-only", false, "Print the difference between the bindings the GroupPermissions resolve to and the cluster, then exit non-zero on drift")
+	verifyOutput := pflag.String("verify-output", "", "File the drift found with --verify-only is written to as JSON")
+	readOnly := pflag.Bool("read-only", false, "Reconcile and report statuses, events and metrics without writing RBAC objects and Groups")
+	simulationBindAddress := pflag.String("simulation-bind-address", "", "Address the simulations of candidate GroupPermissions are served on, for reviewers allowed to get grouppermissions/simulation, empty disables them")
+	simulationCertDir := pflag.String("simulation-cert-dir", "", "Directory of the cert.pem and key.pem the simulations are served with over TLS, required unless --simulation-bind-address is a loopback address")
+	removeStaleGrants := pflag.Bool("remove-stale-grants", false, "Remove the grants flagged as stale from their GroupPermissions with their managed bindings, instead of only reporting them")
+	apiAdminGroup := pflag.String("api-admin-group", "", "Group bound to the grouppermission-editor ClusterRole installed by the operator, no Group is bound when empty")
+	roleContentWorkers := pflag.Int("role-content-workers", 1, "Number of GroupPermissions whose Roles created from rules are converged concurrently")
+	roleContentResync := pflag.Duration("role-content-resync", 10*time.Minute, "Interval between two checks of the rules of the Roles created from rules, restoring those changed outside the operator")
+
 	pflag.Parse()
 
 	// Use a zap logr.Logger implementation. If none of the zap
@@ -83,20 +181,46 @@ func main() {
 		os.Exit(1)
 	}
 
+	groupPermissionOptions := []grouppermission.Option{
+		grouppermission.WithShard(*shard),
+		grouppermission.WithWatchNamespaces(*watchNamespaces, *watchNamespaceSelector),
+		grouppermission.WithRemoveStaleGrants(*removeStaleGrants),
+		grouppermission.WithRoleContentTuning(*roleContentWorkers, *roleContentResync),
+	}
+
 	ctx := context.TODO()
 
-	// Become the leader before proceeding
-	err = leader.Become(ctx, "rbac-permissions-operator-lock")
+	// Compare the cluster with the GroupPermissions without changing anything, no leader is needed
+	if *verifyOnly {
+		os.Exit(verify(ctx, cfg, namespace, *verifyOutput, groupPermissionOptions))
+	}
+
+	// Report what would change without changing RBAC objects, to introduce the operator on existing clusters
+	if *readOnly {
+		log.Info("Running in read-only mode, RBAC objects and Groups are not written")
+		readonly.Enable()
+	}
+
+	// Become the leader of the shard before proceeding
+	err = leader.Become(ctx, leaderLockName(*shard, *watchNamespaces, *watchNamespaceSelector))
 	if err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
 
+	// An instance scoped to Namespaces watches every Namespace, the GroupPermissions outside of its
+	// scope are filtered out like those of other shards
+	managerNamespace := namespace
+	if len(*watchNamespaces) > 0 || *watchNamespaceSelector != "" {
+		managerNamespace = ""
+	}
+
 	// Create a new Cmd to provide shared dependencies and start components
 	mgr, err := manager.New(cfg, manager.Options{
-		Namespace:          namespace,
+		Namespace:          managerNamespace,
 		MapperProvider:     restmapper.NewDynamicRESTMapper,
 		MetricsBindAddress: fmt.Sprintf("%s:%d", metricsHost, metricsPort),
+		NewClient:          readonly.NewClient,
 	})
 	if err != nil {
 		log.Error(err, "")
@@ -111,11 +235,115 @@ func main() {
 		os.Exit(1)
 	}
 
+	apiClient, err := client.New(cfg, client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+	apiClient = readonly.Wrap(apiClient, mgr.GetScheme())
+
+	// Export the traces of the reconciles when an OTLP endpoint is set by the OpenTelemetry environment variables
+	exporter, err := tracing.ExporterFromEnvironment(operatorconfig.OperatorName)
+	if err != nil {
+		log.Error(err, "Invalid tracing configuration")
+		os.Exit(1)
+	}
+	if exporter != nil {
+		exporter.ServiceVersion = version.Version
+		tracer := tracing.NewTracer(exporter)
+		tracing.SetTracer(tracer)
+		if err := mgr.Add(tracer); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+		log.Info("Exporting traces", "Endpoint", exporter.Endpoint, "ServiceName", exporter.ServiceName)
+	}
+
+	// Report the version, features and configuration of the operator so their rollout can be tracked across clusters
+	localmetrics.SetBuildInfo(version.Version, version.Commit)
+	localmetrics.SetFeatureGate("read_only", readonly.Enabled())
+	localmetrics.SetFeatureGate("gc_dry_run", *gcDryRun)
+	localmetrics.SetFeatureGate("sharding", *shard != "")
+	localmetrics.SetFeatureGate("scoped_namespaces", len(*watchNamespaces) > 0 || *watchNamespaceSelector != "")
+	localmetrics.SetFeatureGate("inventory", *inventoryInterval > 0)
+	localmetrics.SetFeatureGate("remove_stale_grants", *removeStaleGrants)
+	localmetrics.SetFeatureGate("simulation", *simulationBindAddress != "")
+	localmetrics.SetFeatureGate("api_admin_group", *apiAdminGroup != "")
+	localmetrics.SetFeatureGate("tracing", exporter != nil)
+	operatorConfig, err := operatorconfig.GetOperatorConfig(ctx, apiClient)
+	if err != nil {
+		log.Error(err, "Failed to get operator config")
+	} else {
+		localmetrics.SetConfigValues(operatorConfig.Values())
+	}
+
+	// Upgrade the objects left by older versions of the operator, failed migrations are retried on the next start.
+	// Migrations are recorded as applied, they are not run in read-only mode so they run once it is turned off.
+	if !readonly.Enabled() {
+		migrated, err := migration.Run(ctx, apiClient, namespace, migration.Migrations)
+		if err != nil {
+			log.Error(err, "Failed to apply migrations")
+		}
+		log.Info("Applied migrations", "Count", migrated)
+	}
+
+	// Install the ClusterRoles granting access to the API of the operator, they are left as they are in read-only mode
+	if !readonly.Enabled() {
+		changed, err := apiroles.Ensure(ctx, apiClient, *apiAdminGroup)
+		if err != nil {
+			log.Error(err, "Failed to ensure the API ClusterRoles")
+		}
+		log.Info("Ensured the API ClusterRoles", "Changed", changed)
+	}
+
 	// Setup all Controllers
 	if err := controller.AddToManager(mgr); err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
+	// The GroupPermission controller is configured by the flags, it is added on its own
+	if err := grouppermission.AddWithOptions(mgr, groupPermissionOptions...); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
+	// Collect bindings left behind by GroupPermissions of the shard deleted while the operator was down, once the manager starts
+	inShard, err := grouppermission.OwnerInShard(apiClient, groupPermissionOptions...)
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+	if err := mgr.Add(gc.NewCollector(apiClient, *gcDryRun || readonly.Enabled(), inShard)); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
+	// Report the inventory of every GroupPermission, not only those of the shard, so every instance writes the same inventory
+	if *inventoryInterval > 0 {
+		if err := mgr.Add(inventory.NewReporter(mgr.GetClient(), namespace, *inventoryInterval)); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	}
+
+	// Serve the simulations from the apiserver directly, so candidates are planned against the live state
+	if *simulationBindAddress != "" {
+		simulationServer, err := simulation.NewServer(apiClient, *simulationBindAddress, *simulationCertDir, namespace)
+		if err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+		if err := mgr.Add(simulationServer); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	}
+
+	// Setup all admission webhooks
+	if err := webhook.AddToManager(mgr); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
 
 	// Create Service object to expose the metrics port.
 	_, err = metrics.ExposeMetricsPort(ctx, metricsPort)
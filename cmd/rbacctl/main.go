@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/rbacctl"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		rbacctl.Usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	command, ok := rbacctl.Commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", os.Args[1])
+		rbacctl.Usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	if err := command.Run(os.Args[2:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
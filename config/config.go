@@ -18,4 +18,98 @@ const (
 	OperatorConfigMapName string = "rbac-permissions-operator"
 	OperatorName          string = "rbac-permissions-operator"
 	OperatorNamespace     string = "openshift-rbac-permissions-operator"
+	// MigrationsConfigMapName records the version of the last migration applied to the cluster
+	MigrationsConfigMapName string = "rbac-permissions-operator-migrations"
+	// InventoryConfigMapName holds the inventory of the RBAC objects managed by the operator, for compliance scanners
+	InventoryConfigMapName string = "rbac-permissions-operator-inventory"
+
+	// RequesterAnnotation records the user who last changed a GroupPermission spec.
+	// It is written by the mutating admission webhook.
+	RequesterAnnotation string = "managed.openshift.io/requester"
+	// RequesterGroupsAnnotation records the comma separated groups of the requester
+	RequesterGroupsAnnotation string = "managed.openshift.io/requester-groups"
+	// RequesterUIDAnnotation records the UID of the requester
+	RequesterUIDAnnotation string = "managed.openshift.io/requester-uid"
+	// RequesterExtraAnnotation records, as JSON, the extra information of the requester, such as the
+	// scopes of an OAuth token, which restrict the rights of the requester
+	RequesterExtraAnnotation string = "managed.openshift.io/requester-extra"
+	// AdmissionWarningsAnnotation records the warnings of the admission webhook about the last change
+	// of a GroupPermission spec, the admission API of the cluster cannot return them to the client
+	AdmissionWarningsAnnotation string = "managed.openshift.io/admission-warnings"
+	// OwnerRefAnnotation records on the events of a GroupPermission the team owning it, from its
+	// spec.notify.ownerRef, so its failures can be routed to that team
+	OwnerRefAnnotation string = "managed.openshift.io/owner-ref"
+	// JustificationAnnotation and TicketURLAnnotation record on the managed bindings the justification
+	// and the ticket of the GroupPermission granting them
+	JustificationAnnotation string = "managed.openshift.io/justification"
+	TicketURLAnnotation     string = "managed.openshift.io/ticket-url"
+	// DescriptionAnnotation and TagsAnnotation record on the managed bindings and on the events of a
+	// GroupPermission its description and its comma separated tags
+	DescriptionAnnotation string = "managed.openshift.io/description"
+	TagsAnnotation        string = "managed.openshift.io/tags"
+	// SourceAnnotation, SourceUIDAnnotation and SourceGenerationAnnotation record on the managed RBAC
+	// objects the namespace/name and the UID of the GroupPermission that created them, and its generation
+	// when they were last created or updated
+	SourceAnnotation           string = "managed.openshift.io/source"
+	SourceUIDAnnotation        string = "managed.openshift.io/source-uid"
+	SourceGenerationAnnotation string = "managed.openshift.io/source-generation"
+	// RetainBindingsAnnotation set to "true" on a GroupPermission being deleted releases it without
+	// removing its managed RBAC objects, to abort a mistaken deletion during the deletion grace period
+	RetainBindingsAnnotation string = "managed.openshift.io/retain-bindings"
+	// KeepStaleGrantsAnnotation set to "true" on a GroupPermission opts it out of the stale grant
+	// reaper, its unused roles are neither flagged nor removed
+	KeepStaleGrantsAnnotation string = "managed.openshift.io/keep-stale-grants"
+	// ExplainNamespaceAnnotation set to the name of a Namespace on a GroupPermission records in its
+	// status why each of its permissions matches the Namespace or not
+	ExplainNamespaceAnnotation string = "managed.openshift.io/explain-namespace"
+	// RemovalDueAnnotation records on a managed RBAC object pending removal when it is deleted, in RFC 3339
+	RemovalDueAnnotation string = "managed.openshift.io/removal-due"
+	// RulesHashAnnotation records on a Role created by the operator the hash of the rules it last wrote,
+	// so rules changed outside the operator are told apart from rules changed in the spec
+	RulesHashAnnotation string = "managed.openshift.io/rules-hash"
+
+	// ManagedByLabel marks the RBAC objects managed by the operator, set to OperatorName
+	ManagedByLabel string = "app.kubernetes.io/managed-by"
+	// OwnerNameLabel and OwnerNamespaceLabel identify the GroupPermission owning a managed RBAC object
+	OwnerNameLabel      string = "managed.openshift.io/grouppermission-name"
+	OwnerNamespaceLabel string = "managed.openshift.io/grouppermission-namespace"
+	// FanOutParentLabel identifies, by its name, the GroupPermission with a groupNameSelector a
+	// GroupPermission was created from for one of the selected Groups, in the same namespace
+	FanOutParentLabel string = "managed.openshift.io/fan-out-parent"
+	// InventoryOfLabel identifies, by its name, the GroupPermission whose list of managed RoleBindings a
+	// ConfigMap holds a chunk of, in the same namespace
+	InventoryOfLabel string = "managed.openshift.io/inventory-of"
+	// HistoryOfLabel identifies, by its name, the GroupPermission whose applied specs a ConfigMap
+	// records, in the same namespace
+	HistoryOfLabel string = "managed.openshift.io/history-of"
+	// PendingRemovalLabel set to "true" marks the managed RBAC objects of a Namespace no longer allowed,
+	// deleted once the removal grace period of the operator config elapsed
+	PendingRemovalLabel string = "managed.openshift.io/pending-removal"
+	// DelegableLabelPrefix followed by the name of a Namespace, set to "true" on a ClusterRole, lets the
+	// GroupPermissions of that Namespace grant it when the Namespace is delegated in the operator config
+	DelegableLabelPrefix string = "delegable.managed.openshift.io/"
+
+	// NamespaceRequesterAnnotation records the user who requested a Namespace, set by OpenShift on project requests
+	NamespaceRequesterAnnotation string = "openshift.io/requester"
+	// AllowedGrantersAnnotation on a Namespace lists, comma separated, the groups whose members may author
+	// the GroupPermissions binding roles in it. Without it every GroupPermission may.
+	AllowedGrantersAnnotation string = "rbac.managed.openshift.io/allowed-granters"
+	// ExcludeRolesAnnotation on a Namespace lists, comma separated, the ClusterRoles that must not be
+	// bound in it, for Namespaces with a stricter local policy
+	ExcludeRolesAnnotation string = "rbac.managed.openshift.io/exclude-roles"
+
+	// LastAppliedHashAnnotation records the hash of the desired RBAC state a GroupPermission last converged to,
+	// for GitOps tools to detect when the latest spec is fully applied
+	LastAppliedHashAnnotation string = "rbac.managed.openshift.io/last-applied-hash"
+
+	// GroupPermissionFinalizer holds a GroupPermission until its managed RBAC objects are deleted
+	GroupPermissionFinalizer string = "managed.openshift.io/rbac-cleanup"
+	// ElevationFinalizer holds an Elevation until its ClusterRoleBindings are deleted
+	ElevationFinalizer string = "managed.openshift.io/elevation-revert"
+
+	// ElevationNameLabel identifies the Elevation owning a managed ClusterRoleBinding, in the operator namespace
+	ElevationNameLabel string = "managed.openshift.io/elevation-name"
+
+	// PermissionRequestNameLabel identifies the PermissionRequest owning a managed RoleBinding, in its namespace
+	PermissionRequestNameLabel string = "managed.openshift.io/permissionrequest-name"
 )
@@ -0,0 +1,555 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Keys of the operator ConfigMap
+const (
+	statusNamespaceThresholdKey  string = "status_namespace_threshold"
+	statusFailureSampleSizeKey   string = "status_failure_sample_size"
+	statusMatchedSampleSizeKey   string = "status_matched_sample_size"
+	resyncIntervalSecondsKey     string = "resync_interval_seconds"
+	jitterFactorKey              string = "jitter_factor"
+	allowedRequestRolesKey       string = "permission_request_allowed_roles"
+	policyEndpointKey            string = "policy_endpoint"
+	policyTimeoutSecondsKey      string = "policy_timeout_seconds"
+	sensitiveRolesKey            string = "sensitive_roles"
+	clusterLabelsKey             string = "cluster_labels"
+	stuckDeadlineSecondsKey      string = "stuck_deadline_seconds"
+	deletionGracePeriodKey       string = "deletion_grace_period_seconds"
+	usageEndpointKey             string = "usage_endpoint"
+	staleGrantDaysKey            string = "stale_grant_days"
+	delegatedNamespacesKey       string = "delegated_namespaces"
+	protectedNamespacesKey       string = "protected_namespaces"
+	protectedNamespacePolicyKey  string = "protected_namespace_policy"
+	groupCacheTTLSecondsKey      string = "group_cache_ttl_seconds"
+	removalGracePeriodKey        string = "removal_grace_period_seconds"
+	unmanagedAccessModeKey       string = "unmanaged_access_mode"
+	grantDecisionEndpointKey     string = "grant_decision_endpoint"
+	grantDecisionTimeoutKey      string = "grant_decision_timeout_seconds"
+	grantDecisionFailureModeKey  string = "grant_decision_failure_mode"
+	foreignOperatorsKey          string = "foreign_operators"
+	eventVerbosityKey            string = "event_verbosity"
+	eventSummaryIntervalKey      string = "event_summary_interval_seconds"
+	allowUnrecordedRequestersKey string = "allow_unrecorded_requesters"
+)
+
+// OperatorConfig is the runtime configuration of the operator, read from the operator ConfigMap
+type OperatorConfig struct {
+	// StatusNamespaceThreshold is the number of namespaces above which per-namespace status is summarized
+	StatusNamespaceThreshold int
+	// StatusFailureSampleSize is the maximum number of failed namespaces listed in a summarized status
+	StatusFailureSampleSize int
+	// StatusMatchedSampleSize is the maximum number of matched namespaces listed for each Permission
+	StatusMatchedSampleSize int
+	// ResyncInterval is the base interval between reconciles of a converged GroupPermission, 0 disables them
+	ResyncInterval time.Duration
+	// JitterFactor is the maximum fraction of an interval added at random to each resync and requeue,
+	// so the objects reconciled together after a restart do not keep hitting the apiserver together
+	JitterFactor float64
+	// AllowedRequestRoles are the ClusterRoles a PermissionRequest may grant, none when empty
+	AllowedRequestRoles []string
+	// PolicyEndpoint is the URL of the OPA policy every binding is evaluated against before it is
+	// created, bindings are not evaluated when empty
+	PolicyEndpoint string
+	// PolicyTimeout is the timeout of a single evaluation of the policy
+	PolicyTimeout time.Duration
+	// SensitiveRoles are the ClusterRoles a GroupPermission grants only with a justification and a
+	// ticket URL, none when empty
+	SensitiveRoles []string
+	// ClusterLabels are the labels of the cluster matched by the clusterConditions of the GroupPermissions,
+	// such as environment=prod, they override the labels read from the Infrastructure of the cluster
+	ClusterLabels map[string]string
+	// StuckDeadline is how long a GroupPermission may take to become Active after its spec changed
+	// before it is flagged as stuck, 0 disables the check
+	StuckDeadline time.Duration
+	// DeletionGracePeriod is how long the managed RBAC objects of a deleted GroupPermission are kept
+	// before they are removed, so a mistaken deletion can be aborted. 0 removes them right away.
+	DeletionGracePeriod time.Duration
+	// UsageEndpoint is the URL of the source of the last use of each role by a Group, such as an
+	// aggregation of the apiserver audit log, the usage is not recorded when empty
+	UsageEndpoint string
+	// StaleGrantAge is how long a Group may not use a role bound by a GroupPermission before the grant
+	// is flagged as stale, and removed when the removal of stale grants is enabled. 0 disables the reaper.
+	StaleGrantAge time.Duration
+	// DelegatedNamespaces are the Namespaces whose GroupPermissions may only grant the ClusterRoles
+	// labeled as delegable to them, so team leads can be allowed to create GroupPermissions there
+	DelegatedNamespaces []string
+	// ProtectedNamespaces are the Namespaces no GroupPermission binds roles in, the managed bindings
+	// found there are handled according to ProtectedNamespacePolicy
+	ProtectedNamespaces []string
+	// ProtectedNamespacePolicy is what happens to the managed bindings of the protected Namespaces
+	ProtectedNamespacePolicy ProtectedNamespacePolicy
+	// GroupCacheTTL is how long the existence of a Group, once read, is trusted before it is read again,
+	// so the Groups of GroupPermissions creating them if missing are not read on every reconcile.
+	// 0 disables the cache.
+	GroupCacheTTL time.Duration
+	// RemovalGracePeriod is how long the Roles and RoleBindings of a Namespace no longer allowed are
+	// labeled pending removal before they are deleted, so the owners of the Namespace can fix its labels
+	// without losing access. 0 deletes them right away.
+	RemovalGracePeriod time.Duration
+	// UnmanagedAccessMode is whether the GroupPermissions whose Group has access beyond their spec through
+	// bindings not managed by the operator are flagged, or fail to reconcile
+	UnmanagedAccessMode UnmanagedAccessMode
+	// GrantDecisionEndpoint is the URL of the grant decision service asked before each binding allowed by
+	// the policy is created, which may deny it or add annotations to it. Bindings are not sent when empty.
+	GrantDecisionEndpoint string
+	// GrantDecisionTimeout is the timeout of a single decision of the grant decision service
+	GrantDecisionTimeout time.Duration
+	// GrantDecisionFailureMode is whether the bindings are created when the grant decision service fails
+	GrantDecisionFailureMode GrantDecisionFailureMode
+	// ForeignOperators are the other RBAC operators, such as the dedicated-admin-operator, by the value of
+	// the ManagedByLabel of their bindings. Their bindings are never touched, and the bindings of a
+	// GroupPermission granting the same access are not created but reported as conflicts. None when empty.
+	ForeignOperators []string
+	// EventVerbosity is which events the operator records on the GroupPermissions
+	EventVerbosity EventVerbosity
+	// EventSummaryInterval is the period of the summaries of the per-namespace events aggregated when
+	// EventVerbosity is summary
+	EventSummaryInterval time.Duration
+	// AllowUnrecordedRequesters is whether the bindings of the GroupPermissions without a requester recorded by
+	// the webhook, such as those created before it was deployed, are created without the escalation check.
+	// They are denied otherwise.
+	AllowUnrecordedRequesters bool
+}
+
+// ProtectedNamespacePolicy is what happens to the managed bindings of a Namespace once it is protected
+type ProtectedNamespacePolicy string
+
+const (
+	// ProtectedNamespaceDelete deletes the managed bindings of the protected Namespaces
+	ProtectedNamespaceDelete ProtectedNamespacePolicy = "delete"
+	// ProtectedNamespaceOrphan leaves the bindings of the protected Namespaces in place, no longer managed
+	ProtectedNamespaceOrphan ProtectedNamespacePolicy = "orphan"
+)
+
+// UnmanagedAccessMode is how a GroupPermission is handled when its Group has access beyond its spec
+// through bindings not managed by the operator
+type UnmanagedAccessMode string
+
+const (
+	// UnmanagedAccessOff does not look for the bindings not managed by the operator
+	UnmanagedAccessOff UnmanagedAccessMode = "off"
+	// UnmanagedAccessWarn flags the GroupPermission with a condition and an event
+	UnmanagedAccessWarn UnmanagedAccessMode = "warn"
+	// UnmanagedAccessStrict flags the GroupPermission and fails its reconcile until the bindings are
+	// removed or the access is added to its spec
+	UnmanagedAccessStrict UnmanagedAccessMode = "strict"
+)
+
+// GrantDecisionFailureMode is what happens to a binding when the grant decision service cannot decide on it
+type GrantDecisionFailureMode string
+
+const (
+	// GrantDecisionFailClosed does not create the binding, and retries the reconcile of its GroupPermission
+	GrantDecisionFailClosed GrantDecisionFailureMode = "closed"
+	// GrantDecisionFailOpen creates the binding as the operator computed it
+	GrantDecisionFailOpen GrantDecisionFailureMode = "open"
+)
+
+// EventVerbosity is which events the operator records on the GroupPermissions
+type EventVerbosity string
+
+const (
+	// EventsNone records no event
+	EventsNone EventVerbosity = "none"
+	// EventsSummary records the events of a GroupPermission, and aggregates those about single
+	// Namespaces into periodic summaries so thousands of Namespaces do not flood etcd with events
+	EventsSummary EventVerbosity = "summary"
+	// EventsVerbose records every event, including one per Namespace
+	EventsVerbose EventVerbosity = "verbose"
+)
+
+// DefaultOperatorConfig returns the configuration used when the operator ConfigMap does not exist
+func DefaultOperatorConfig() *OperatorConfig {
+	return &OperatorConfig{
+		StatusNamespaceThreshold: 100,
+		StatusFailureSampleSize:  10,
+		StatusMatchedSampleSize:  20,
+		ResyncInterval:           10 * time.Hour,
+		JitterFactor:             0.1,
+		PolicyTimeout:            5 * time.Second,
+		StuckDeadline:            30 * time.Minute,
+		ProtectedNamespacePolicy: ProtectedNamespaceDelete,
+		GroupCacheTTL:            5 * time.Minute,
+		UnmanagedAccessMode:      UnmanagedAccessOff,
+		GrantDecisionTimeout:     5 * time.Second,
+		GrantDecisionFailureMode: GrantDecisionFailClosed,
+		EventVerbosity:           EventsSummary,
+		EventSummaryInterval:     5 * time.Minute,
+	}
+}
+
+// GetOperatorConfig reads the operator configuration from the operator ConfigMap. Unset keys, or a
+// missing ConfigMap, use the defaults.
+func GetOperatorConfig(ctx context.Context, k8sClient client.Client) (*OperatorConfig, error) {
+	configMap := &corev1.ConfigMap{}
+	err := k8sClient.Get(ctx, types.NamespacedName{Namespace: OperatorNamespace, Name: OperatorConfigMapName}, configMap)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return DefaultOperatorConfig(), nil
+		}
+		return nil, err
+	}
+	return OperatorConfigFromConfigMap(configMap)
+}
+
+// OperatorConfigFromConfigMap parses the operator configuration from configMap
+func OperatorConfigFromConfigMap(configMap *corev1.ConfigMap) (*OperatorConfig, error) {
+	operatorConfig := DefaultOperatorConfig()
+
+	if err := parseInt(configMap.Data, statusNamespaceThresholdKey, &operatorConfig.StatusNamespaceThreshold); err != nil {
+		return nil, err
+	}
+	if err := parseInt(configMap.Data, statusFailureSampleSizeKey, &operatorConfig.StatusFailureSampleSize); err != nil {
+		return nil, err
+	}
+	if err := parseInt(configMap.Data, statusMatchedSampleSizeKey, &operatorConfig.StatusMatchedSampleSize); err != nil {
+		return nil, err
+	}
+	resyncIntervalSeconds := int(operatorConfig.ResyncInterval / time.Second)
+	if err := parseInt(configMap.Data, resyncIntervalSecondsKey, &resyncIntervalSeconds); err != nil {
+		return nil, err
+	}
+	operatorConfig.ResyncInterval = time.Duration(resyncIntervalSeconds) * time.Second
+	if err := parseFactor(configMap.Data, jitterFactorKey, &operatorConfig.JitterFactor); err != nil {
+		return nil, err
+	}
+	operatorConfig.AllowedRequestRoles = parseList(configMap.Data, allowedRequestRolesKey)
+	if err := parseURL(configMap.Data, policyEndpointKey, &operatorConfig.PolicyEndpoint); err != nil {
+		return nil, err
+	}
+	policyTimeoutSeconds := int(operatorConfig.PolicyTimeout / time.Second)
+	if err := parseInt(configMap.Data, policyTimeoutSecondsKey, &policyTimeoutSeconds); err != nil {
+		return nil, err
+	}
+	operatorConfig.PolicyTimeout = time.Duration(policyTimeoutSeconds) * time.Second
+	operatorConfig.SensitiveRoles = parseList(configMap.Data, sensitiveRolesKey)
+	if err := parseLabels(configMap.Data, clusterLabelsKey, &operatorConfig.ClusterLabels); err != nil {
+		return nil, err
+	}
+	stuckDeadlineSeconds := int(operatorConfig.StuckDeadline / time.Second)
+	if err := parseInt(configMap.Data, stuckDeadlineSecondsKey, &stuckDeadlineSeconds); err != nil {
+		return nil, err
+	}
+	operatorConfig.StuckDeadline = time.Duration(stuckDeadlineSeconds) * time.Second
+	deletionGracePeriodSeconds := int(operatorConfig.DeletionGracePeriod / time.Second)
+	if err := parseInt(configMap.Data, deletionGracePeriodKey, &deletionGracePeriodSeconds); err != nil {
+		return nil, err
+	}
+	operatorConfig.DeletionGracePeriod = time.Duration(deletionGracePeriodSeconds) * time.Second
+	if err := parseURL(configMap.Data, usageEndpointKey, &operatorConfig.UsageEndpoint); err != nil {
+		return nil, err
+	}
+	staleGrantDays := int(operatorConfig.StaleGrantAge / (24 * time.Hour))
+	if err := parseInt(configMap.Data, staleGrantDaysKey, &staleGrantDays); err != nil {
+		return nil, err
+	}
+	operatorConfig.StaleGrantAge = time.Duration(staleGrantDays) * 24 * time.Hour
+	operatorConfig.DelegatedNamespaces = parseList(configMap.Data, delegatedNamespacesKey)
+	operatorConfig.ProtectedNamespaces = parseList(configMap.Data, protectedNamespacesKey)
+	operatorConfig.ForeignOperators = parseList(configMap.Data, foreignOperatorsKey)
+	if policy, ok := configMap.Data[protectedNamespacePolicyKey]; ok {
+		operatorConfig.ProtectedNamespacePolicy = ProtectedNamespacePolicy(policy)
+		if operatorConfig.ProtectedNamespacePolicy != ProtectedNamespaceDelete && operatorConfig.ProtectedNamespacePolicy != ProtectedNamespaceOrphan {
+			return nil, fmt.Errorf("invalid value %q for %s, must be %s or %s", policy, protectedNamespacePolicyKey, ProtectedNamespaceDelete, ProtectedNamespaceOrphan)
+		}
+	}
+	groupCacheTTLSeconds := int(operatorConfig.GroupCacheTTL / time.Second)
+	if err := parseInt(configMap.Data, groupCacheTTLSecondsKey, &groupCacheTTLSeconds); err != nil {
+		return nil, err
+	}
+	operatorConfig.GroupCacheTTL = time.Duration(groupCacheTTLSeconds) * time.Second
+	removalGracePeriodSeconds := int(operatorConfig.RemovalGracePeriod / time.Second)
+	if err := parseInt(configMap.Data, removalGracePeriodKey, &removalGracePeriodSeconds); err != nil {
+		return nil, err
+	}
+	operatorConfig.RemovalGracePeriod = time.Duration(removalGracePeriodSeconds) * time.Second
+	if mode, ok := configMap.Data[unmanagedAccessModeKey]; ok {
+		operatorConfig.UnmanagedAccessMode = UnmanagedAccessMode(mode)
+		switch operatorConfig.UnmanagedAccessMode {
+		case UnmanagedAccessOff, UnmanagedAccessWarn, UnmanagedAccessStrict:
+		default:
+			return nil, fmt.Errorf("invalid value %q for %s, must be %s, %s or %s", mode, unmanagedAccessModeKey, UnmanagedAccessOff, UnmanagedAccessWarn, UnmanagedAccessStrict)
+		}
+	}
+	if err := parseURL(configMap.Data, grantDecisionEndpointKey, &operatorConfig.GrantDecisionEndpoint); err != nil {
+		return nil, err
+	}
+	grantDecisionTimeoutSeconds := int(operatorConfig.GrantDecisionTimeout / time.Second)
+	if err := parseInt(configMap.Data, grantDecisionTimeoutKey, &grantDecisionTimeoutSeconds); err != nil {
+		return nil, err
+	}
+	operatorConfig.GrantDecisionTimeout = time.Duration(grantDecisionTimeoutSeconds) * time.Second
+	if mode, ok := configMap.Data[grantDecisionFailureModeKey]; ok {
+		operatorConfig.GrantDecisionFailureMode = GrantDecisionFailureMode(mode)
+		if operatorConfig.GrantDecisionFailureMode != GrantDecisionFailClosed && operatorConfig.GrantDecisionFailureMode != GrantDecisionFailOpen {
+			return nil, fmt.Errorf("invalid value %q for %s, must be %s or %s", mode, grantDecisionFailureModeKey, GrantDecisionFailClosed, GrantDecisionFailOpen)
+		}
+	}
+	if verbosity, ok := configMap.Data[eventVerbosityKey]; ok {
+		operatorConfig.EventVerbosity = EventVerbosity(verbosity)
+		switch operatorConfig.EventVerbosity {
+		case EventsNone, EventsSummary, EventsVerbose:
+		default:
+			return nil, fmt.Errorf("invalid value %q for %s, must be %s, %s or %s", verbosity, eventVerbosityKey, EventsNone, EventsSummary, EventsVerbose)
+		}
+	}
+	eventSummaryIntervalSeconds := int(operatorConfig.EventSummaryInterval / time.Second)
+	if err := parseInt(configMap.Data, eventSummaryIntervalKey, &eventSummaryIntervalSeconds); err != nil {
+		return nil, err
+	}
+	operatorConfig.EventSummaryInterval = time.Duration(eventSummaryIntervalSeconds) * time.Second
+	if err := parseBool(configMap.Data, allowUnrecordedRequestersKey, &operatorConfig.AllowUnrecordedRequesters); err != nil {
+		return nil, err
+	}
+
+	return operatorConfig, nil
+}
+
+// IsRequestRoleAllowed returns whether a PermissionRequest may grant the ClusterRole clusterRoleName
+func (c *OperatorConfig) IsRequestRoleAllowed(clusterRoleName string) bool {
+	for _, allowed := range c.AllowedRequestRoles {
+		if allowed == clusterRoleName {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSensitiveRole returns whether granting the ClusterRole clusterRoleName requires a justification
+// and a ticket URL
+func (c *OperatorConfig) IsSensitiveRole(clusterRoleName string) bool {
+	for _, sensitive := range c.SensitiveRoles {
+		if sensitive == clusterRoleName {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDelegatedNamespace returns whether the GroupPermissions of namespace may only grant the ClusterRoles
+// delegable to it
+func (c *OperatorConfig) IsDelegatedNamespace(namespace string) bool {
+	for _, delegated := range c.DelegatedNamespaces {
+		if delegated == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// IsProtectedNamespace returns whether no GroupPermission may bind roles in namespace
+func (c *OperatorConfig) IsProtectedNamespace(namespace string) bool {
+	for _, protected := range c.ProtectedNamespaces {
+		if protected == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// ForeignOperator returns the foreign operator owning the object of objectLabels, and whether one does
+func (c *OperatorConfig) ForeignOperator(objectLabels map[string]string) (string, bool) {
+	managedBy := objectLabels[ManagedByLabel]
+	for _, foreign := range c.ForeignOperators {
+		if foreign == managedBy {
+			return foreign, true
+		}
+	}
+	return "", false
+}
+
+// Values returns the configuration by key of the operator ConfigMap as numbers: the durations in
+// the unit of their key, the lists and labels by their number of items, the policy, usage and grant decision
+// endpoints by whether they are set, the protected namespace policy by whether it orphans the bindings, the
+// unmanaged access mode as 0 when off, 1 when warning and 2 when strict, the grant decision failure mode
+// by whether it fails open, the event verbosity as 0 when none, 1 when summary and 2 when verbose, and
+// whether unrecorded requesters are allowed.
+// They are exported as metrics so the drift of the configuration across clusters can be tracked.
+func (c *OperatorConfig) Values() map[string]float64 {
+	policyEndpoint := 0.0
+	if c.PolicyEndpoint != "" {
+		policyEndpoint = 1
+	}
+	usageEndpoint := 0.0
+	if c.UsageEndpoint != "" {
+		usageEndpoint = 1
+	}
+	protectedNamespacePolicy := 0.0
+	if c.ProtectedNamespacePolicy == ProtectedNamespaceOrphan {
+		protectedNamespacePolicy = 1
+	}
+	unmanagedAccessMode := 0.0
+	switch c.UnmanagedAccessMode {
+	case UnmanagedAccessWarn:
+		unmanagedAccessMode = 1
+	case UnmanagedAccessStrict:
+		unmanagedAccessMode = 2
+	}
+	grantDecisionEndpoint := 0.0
+	if c.GrantDecisionEndpoint != "" {
+		grantDecisionEndpoint = 1
+	}
+	grantDecisionFailureMode := 0.0
+	if c.GrantDecisionFailureMode == GrantDecisionFailOpen {
+		grantDecisionFailureMode = 1
+	}
+	allowUnrecordedRequesters := 0.0
+	if c.AllowUnrecordedRequesters {
+		allowUnrecordedRequesters = 1
+	}
+	eventVerbosity := 0.0
+	switch c.EventVerbosity {
+	case EventsSummary:
+		eventVerbosity = 1
+	case EventsVerbose:
+		eventVerbosity = 2
+	}
+	return map[string]float64{
+		statusNamespaceThresholdKey:  float64(c.StatusNamespaceThreshold),
+		statusFailureSampleSizeKey:   float64(c.StatusFailureSampleSize),
+		statusMatchedSampleSizeKey:   float64(c.StatusMatchedSampleSize),
+		resyncIntervalSecondsKey:     c.ResyncInterval.Seconds(),
+		jitterFactorKey:              c.JitterFactor,
+		allowedRequestRolesKey:       float64(len(c.AllowedRequestRoles)),
+		policyEndpointKey:            policyEndpoint,
+		policyTimeoutSecondsKey:      c.PolicyTimeout.Seconds(),
+		sensitiveRolesKey:            float64(len(c.SensitiveRoles)),
+		clusterLabelsKey:             float64(len(c.ClusterLabels)),
+		stuckDeadlineSecondsKey:      c.StuckDeadline.Seconds(),
+		deletionGracePeriodKey:       c.DeletionGracePeriod.Seconds(),
+		usageEndpointKey:             usageEndpoint,
+		staleGrantDaysKey:            c.StaleGrantAge.Hours() / 24,
+		delegatedNamespacesKey:       float64(len(c.DelegatedNamespaces)),
+		protectedNamespacesKey:       float64(len(c.ProtectedNamespaces)),
+		protectedNamespacePolicyKey:  protectedNamespacePolicy,
+		groupCacheTTLSecondsKey:      c.GroupCacheTTL.Seconds(),
+		removalGracePeriodKey:        c.RemovalGracePeriod.Seconds(),
+		unmanagedAccessModeKey:       unmanagedAccessMode,
+		grantDecisionEndpointKey:     grantDecisionEndpoint,
+		grantDecisionTimeoutKey:      c.GrantDecisionTimeout.Seconds(),
+		grantDecisionFailureModeKey:  grantDecisionFailureMode,
+		foreignOperatorsKey:          float64(len(c.ForeignOperators)),
+		eventVerbosityKey:            eventVerbosity,
+		eventSummaryIntervalKey:      c.EventSummaryInterval.Seconds(),
+		allowUnrecordedRequestersKey: allowUnrecordedRequesters,
+	}
+}
+
+// Jitter returns interval lengthened by a random fraction of up to JitterFactor, a zero interval is
+// returned unchanged
+func (c *OperatorConfig) Jitter(interval time.Duration) time.Duration {
+	if interval <= 0 || c.JitterFactor <= 0 {
+		return interval
+	}
+	return wait.Jitter(interval, c.JitterFactor)
+}
+
+// parseInt sets value to the non-negative integer in data[key], if it is set
+func parseInt(data map[string]string, key string, value *int) error {
+	s, ok := data[key]
+	if !ok {
+		return nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 {
+		return fmt.Errorf("invalid value %q for %s, must be a non-negative integer", s, key)
+	}
+	*value = i
+	return nil
+}
+
+// parseBool sets value to the true or false in data[key], if it is set
+func parseBool(data map[string]string, key string, value *bool) error {
+	s, ok := data[key]
+	if !ok {
+		return nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("invalid value %q for %s, must be true or false", s, key)
+	}
+	*value = b
+	return nil
+}
+
+// parseFactor sets value to the number between 0 and 1 in data[key], if it is set
+func parseFactor(data map[string]string, key string, value *float64) error {
+	s, ok := data[key]
+	if !ok {
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || f > 1 {
+		return fmt.Errorf("invalid value %q for %s, must be a number between 0 and 1", s, key)
+	}
+	*value = f
+	return nil
+}
+
+// parseURL sets value to the http or https URL in data[key], if it is set
+func parseURL(data map[string]string, key string, value *string) error {
+	s := strings.TrimSpace(data[key])
+	if s == "" {
+		return nil
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid value %q for %s, must be an http or https URL", s, key)
+	}
+	*value = s
+	return nil
+}
+
+// parseLabels sets value to the comma separated key=value labels in data[key], if it is set
+func parseLabels(data map[string]string, key string, value *map[string]string) error {
+	s := strings.TrimSpace(data[key])
+	if s == "" {
+		return nil
+	}
+	set, err := labels.ConvertSelectorToLabelsMap(s)
+	if err != nil {
+		return fmt.Errorf("invalid value %q for %s, must be comma separated key=value labels: %v", s, key, err)
+	}
+	*value = map[string]string(set)
+	return nil
+}
+
+// parseList returns the comma separated values in data[key], without empty values
+func parseList(data map[string]string, key string) []string {
+	var values []string
+	for _, value := range strings.Split(data[key], ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
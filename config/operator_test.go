@@ -0,0 +1,623 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestOperatorConfigFromConfigMap(t *testing.T) {
+	var tests = []struct {
+		label     string
+		data      map[string]string
+		valid     bool
+		threshold int
+		sample    int
+		matched   int
+	}{
+		{"defaults", nil, true, 100, 10, 20},
+		{"all set", map[string]string{"status_namespace_threshold": "5", "status_failure_sample_size": "2", "status_matched_sample_size": "3"}, true, 5, 2, 3},
+		{"not a number", map[string]string{"status_namespace_threshold": "many"}, false, 0, 0, 0},
+		{"negative", map[string]string{"status_failure_sample_size": "-1"}, false, 0, 0, 0},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.StatusNamespaceThreshold != test.threshold {
+			t.Errorf("%s: Mismatch for StatusNamespaceThreshold. Expected(%d), Found(%d)", test.label, test.threshold, operatorConfig.StatusNamespaceThreshold)
+		}
+		if operatorConfig.StatusFailureSampleSize != test.sample {
+			t.Errorf("%s: Mismatch for StatusFailureSampleSize. Expected(%d), Found(%d)", test.label, test.sample, operatorConfig.StatusFailureSampleSize)
+		}
+		if operatorConfig.StatusMatchedSampleSize != test.matched {
+			t.Errorf("%s: Mismatch for StatusMatchedSampleSize. Expected(%d), Found(%d)", test.label, test.matched, operatorConfig.StatusMatchedSampleSize)
+		}
+	}
+}
+
+func TestOperatorConfigIntervals(t *testing.T) {
+	var tests = []struct {
+		label  string
+		data   map[string]string
+		valid  bool
+		resync time.Duration
+		jitter float64
+	}{
+		{"defaults", nil, true, 10 * time.Hour, 0.1},
+		{"all set", map[string]string{"resync_interval_seconds": "600", "jitter_factor": "0.5"}, true, 10 * time.Minute, 0.5},
+		{"disabled", map[string]string{"resync_interval_seconds": "0", "jitter_factor": "0"}, true, 0, 0},
+		{"factor above 1", map[string]string{"jitter_factor": "1.5"}, false, 0, 0},
+		{"factor not a number", map[string]string{"jitter_factor": "some"}, false, 0, 0},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.ResyncInterval != test.resync {
+			t.Errorf("%s: Mismatch for ResyncInterval. Expected(%s), Found(%s)", test.label, test.resync, operatorConfig.ResyncInterval)
+		}
+		if operatorConfig.JitterFactor != test.jitter {
+			t.Errorf("%s: Mismatch for JitterFactor. Expected(%v), Found(%v)", test.label, test.jitter, operatorConfig.JitterFactor)
+		}
+	}
+}
+
+func TestOperatorConfigAllowedRequestRoles(t *testing.T) {
+	operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: map[string]string{"permission_request_allowed_roles": "view, edit,,"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, test := range []struct {
+		clusterRoleName string
+		allowed         bool
+	}{
+		{"view", true},
+		{"edit", true},
+		{"admin", false},
+		{"", false},
+	} {
+		if allowed := operatorConfig.IsRequestRoleAllowed(test.clusterRoleName); allowed != test.allowed {
+			t.Errorf("%q: Mismatch for IsRequestRoleAllowed. Expected(%t), Found(%t)", test.clusterRoleName, test.allowed, allowed)
+		}
+	}
+	if DefaultOperatorConfig().IsRequestRoleAllowed("view") {
+		t.Errorf("expected no ClusterRole to be allowed by default")
+	}
+}
+
+func TestOperatorConfigSensitiveRoles(t *testing.T) {
+	operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: map[string]string{"sensitive_roles": "cluster-admin, admin"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, test := range []struct {
+		clusterRoleName string
+		sensitive       bool
+	}{
+		{"cluster-admin", true},
+		{"admin", true},
+		{"view", false},
+	} {
+		if sensitive := operatorConfig.IsSensitiveRole(test.clusterRoleName); sensitive != test.sensitive {
+			t.Errorf("%q: Mismatch for IsSensitiveRole. Expected(%t), Found(%t)", test.clusterRoleName, test.sensitive, sensitive)
+		}
+	}
+	if DefaultOperatorConfig().IsSensitiveRole("cluster-admin") {
+		t.Errorf("expected no ClusterRole to be sensitive by default")
+	}
+}
+
+func TestOperatorConfigClusterLabels(t *testing.T) {
+	var tests = []struct {
+		label    string
+		data     map[string]string
+		valid    bool
+		expected map[string]string
+	}{
+		{"defaults", nil, true, nil},
+		{"set", map[string]string{"cluster_labels": "environment=prod, region=us-east-1"}, true, map[string]string{"environment": "prod", "region": "us-east-1"}},
+		{"no value", map[string]string{"cluster_labels": "environment"}, false, nil},
+		{"invalid key", map[string]string{"cluster_labels": "-environment=prod"}, false, nil},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if !reflect.DeepEqual(operatorConfig.ClusterLabels, test.expected) {
+			t.Errorf("%s: Mismatch for ClusterLabels. Expected(%v), Found(%v)", test.label, test.expected, operatorConfig.ClusterLabels)
+		}
+	}
+}
+
+func TestOperatorConfigPolicy(t *testing.T) {
+	var tests = []struct {
+		label    string
+		data     map[string]string
+		valid    bool
+		endpoint string
+		timeout  time.Duration
+	}{
+		{"defaults", nil, true, "", 5 * time.Second},
+		{"all set", map[string]string{"policy_endpoint": "https://opa.example.com/v1/data/rbac/allow", "policy_timeout_seconds": "2"}, true, "https://opa.example.com/v1/data/rbac/allow", 2 * time.Second},
+		{"no scheme", map[string]string{"policy_endpoint": "opa.example.com/v1/data/rbac/allow"}, false, "", 0},
+		{"not http", map[string]string{"policy_endpoint": "ftp://opa.example.com"}, false, "", 0},
+		{"timeout not a number", map[string]string{"policy_timeout_seconds": "soon"}, false, "", 0},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.PolicyEndpoint != test.endpoint {
+			t.Errorf("%s: Mismatch for PolicyEndpoint. Expected(%s), Found(%s)", test.label, test.endpoint, operatorConfig.PolicyEndpoint)
+		}
+		if operatorConfig.PolicyTimeout != test.timeout {
+			t.Errorf("%s: Mismatch for PolicyTimeout. Expected(%s), Found(%s)", test.label, test.timeout, operatorConfig.PolicyTimeout)
+		}
+	}
+}
+
+func TestOperatorConfigGrantDecision(t *testing.T) {
+	var tests = []struct {
+		label    string
+		data     map[string]string
+		valid    bool
+		endpoint string
+		timeout  time.Duration
+		mode     GrantDecisionFailureMode
+	}{
+		{"defaults", nil, true, "", 5 * time.Second, GrantDecisionFailClosed},
+		{"all set", map[string]string{"grant_decision_endpoint": "https://grants.example.com/v1/decide", "grant_decision_timeout_seconds": "2", "grant_decision_failure_mode": "open"}, true, "https://grants.example.com/v1/decide", 2 * time.Second, GrantDecisionFailOpen},
+		{"not http", map[string]string{"grant_decision_endpoint": "grpc://grants.example.com"}, false, "", 0, ""},
+		{"timeout not a number", map[string]string{"grant_decision_timeout_seconds": "soon"}, false, "", 0, ""},
+		{"invalid failure mode", map[string]string{"grant_decision_failure_mode": "ignore"}, false, "", 0, ""},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.GrantDecisionEndpoint != test.endpoint {
+			t.Errorf("%s: Mismatch for GrantDecisionEndpoint. Expected(%s), Found(%s)", test.label, test.endpoint, operatorConfig.GrantDecisionEndpoint)
+		}
+		if operatorConfig.GrantDecisionTimeout != test.timeout {
+			t.Errorf("%s: Mismatch for GrantDecisionTimeout. Expected(%s), Found(%s)", test.label, test.timeout, operatorConfig.GrantDecisionTimeout)
+		}
+		if operatorConfig.GrantDecisionFailureMode != test.mode {
+			t.Errorf("%s: Mismatch for GrantDecisionFailureMode. Expected(%s), Found(%s)", test.label, test.mode, operatorConfig.GrantDecisionFailureMode)
+		}
+	}
+}
+
+func TestOperatorConfigEvents(t *testing.T) {
+	var tests = []struct {
+		label     string
+		data      map[string]string
+		valid     bool
+		verbosity EventVerbosity
+		interval  time.Duration
+	}{
+		{"defaults", nil, true, EventsSummary, 5 * time.Minute},
+		{"none", map[string]string{"event_verbosity": "none"}, true, EventsNone, 5 * time.Minute},
+		{"verbose", map[string]string{"event_verbosity": "verbose", "event_summary_interval_seconds": "60"}, true, EventsVerbose, time.Minute},
+		{"invalid verbosity", map[string]string{"event_verbosity": "debug"}, false, "", 0},
+		{"negative interval", map[string]string{"event_summary_interval_seconds": "-1"}, false, "", 0},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.EventVerbosity != test.verbosity {
+			t.Errorf("%s: Mismatch for EventVerbosity. Expected(%s), Found(%s)", test.label, test.verbosity, operatorConfig.EventVerbosity)
+		}
+		if operatorConfig.EventSummaryInterval != test.interval {
+			t.Errorf("%s: Mismatch for EventSummaryInterval. Expected(%s), Found(%s)", test.label, test.interval, operatorConfig.EventSummaryInterval)
+		}
+	}
+}
+
+func TestOperatorConfigAllowUnrecordedRequesters(t *testing.T) {
+	var tests = []struct {
+		label    string
+		data     map[string]string
+		valid    bool
+		expected bool
+	}{
+		{"default", nil, true, false},
+		{"allowed", map[string]string{"allow_unrecorded_requesters": "true"}, true, true},
+		{"denied", map[string]string{"allow_unrecorded_requesters": "false"}, true, false},
+		{"not a bool", map[string]string{"allow_unrecorded_requesters": "sometimes"}, false, false},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if test.valid && operatorConfig.AllowUnrecordedRequesters != test.expected {
+			t.Errorf("%s: Mismatch for AllowUnrecordedRequesters. Expected(%t), Found(%t)", test.label, test.expected, operatorConfig.AllowUnrecordedRequesters)
+		}
+	}
+}
+
+func TestOperatorConfigStuckDeadline(t *testing.T) {
+	var tests = []struct {
+		label    string
+		data     map[string]string
+		valid    bool
+		deadline time.Duration
+	}{
+		{"defaults", nil, true, 30 * time.Minute},
+		{"set", map[string]string{"stuck_deadline_seconds": "600"}, true, 10 * time.Minute},
+		{"disabled", map[string]string{"stuck_deadline_seconds": "0"}, true, 0},
+		{"negative", map[string]string{"stuck_deadline_seconds": "-1"}, false, 0},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.StuckDeadline != test.deadline {
+			t.Errorf("%s: Mismatch for StuckDeadline. Expected(%s), Found(%s)", test.label, test.deadline, operatorConfig.StuckDeadline)
+		}
+	}
+}
+
+func TestOperatorConfigDeletionGracePeriod(t *testing.T) {
+	var tests = []struct {
+		label       string
+		data        map[string]string
+		valid       bool
+		gracePeriod time.Duration
+	}{
+		{"defaults", nil, true, 0},
+		{"set", map[string]string{"deletion_grace_period_seconds": "600"}, true, 10 * time.Minute},
+		{"not a number", map[string]string{"deletion_grace_period_seconds": "later"}, false, 0},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.DeletionGracePeriod != test.gracePeriod {
+			t.Errorf("%s: Mismatch for DeletionGracePeriod. Expected(%s), Found(%s)", test.label, test.gracePeriod, operatorConfig.DeletionGracePeriod)
+		}
+	}
+}
+
+func TestOperatorConfigUsageEndpoint(t *testing.T) {
+	var tests = []struct {
+		label    string
+		data     map[string]string
+		valid    bool
+		endpoint string
+	}{
+		{"defaults", nil, true, ""},
+		{"set", map[string]string{"usage_endpoint": "https://audit.example.com/v1/usage"}, true, "https://audit.example.com/v1/usage"},
+		{"not http", map[string]string{"usage_endpoint": "audit.example.com"}, false, ""},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.UsageEndpoint != test.endpoint {
+			t.Errorf("%s: Mismatch for UsageEndpoint. Expected(%s), Found(%s)", test.label, test.endpoint, operatorConfig.UsageEndpoint)
+		}
+	}
+}
+
+func TestOperatorConfigStaleGrantAge(t *testing.T) {
+	var tests = []struct {
+		label string
+		data  map[string]string
+		valid bool
+		age   time.Duration
+	}{
+		{"defaults", nil, true, 0},
+		{"set", map[string]string{"stale_grant_days": "90"}, true, 90 * 24 * time.Hour},
+		{"negative", map[string]string{"stale_grant_days": "-1"}, false, 0},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.StaleGrantAge != test.age {
+			t.Errorf("%s: Mismatch for StaleGrantAge. Expected(%s), Found(%s)", test.label, test.age, operatorConfig.StaleGrantAge)
+		}
+		if values := operatorConfig.Values(); values["stale_grant_days"] != test.age.Hours()/24 {
+			t.Errorf("%s: Mismatch for stale_grant_days value. Expected(%v), Found(%v)", test.label, test.age.Hours()/24, values["stale_grant_days"])
+		}
+	}
+}
+
+func TestOperatorConfigGroupCacheTTL(t *testing.T) {
+	var tests = []struct {
+		label string
+		data  map[string]string
+		valid bool
+		ttl   time.Duration
+	}{
+		{"defaults", nil, true, 5 * time.Minute},
+		{"set", map[string]string{"group_cache_ttl_seconds": "60"}, true, time.Minute},
+		{"disabled", map[string]string{"group_cache_ttl_seconds": "0"}, true, 0},
+		{"not a number", map[string]string{"group_cache_ttl_seconds": "long"}, false, 0},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.GroupCacheTTL != test.ttl {
+			t.Errorf("%s: Mismatch for GroupCacheTTL. Expected(%s), Found(%s)", test.label, test.ttl, operatorConfig.GroupCacheTTL)
+		}
+	}
+}
+
+func TestOperatorConfigRemovalGracePeriod(t *testing.T) {
+	var tests = []struct {
+		label  string
+		data   map[string]string
+		valid  bool
+		period time.Duration
+	}{
+		{"defaults", nil, true, 0},
+		{"set", map[string]string{"removal_grace_period_seconds": "86400"}, true, 24 * time.Hour},
+		{"negative", map[string]string{"removal_grace_period_seconds": "-1"}, false, 0},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.RemovalGracePeriod != test.period {
+			t.Errorf("%s: Mismatch for RemovalGracePeriod. Expected(%s), Found(%s)", test.label, test.period, operatorConfig.RemovalGracePeriod)
+		}
+	}
+}
+
+func TestOperatorConfigDelegatedNamespaces(t *testing.T) {
+	operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: map[string]string{"delegated_namespaces": "team-a, team-b"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, test := range []struct {
+		namespace string
+		delegated bool
+	}{
+		{"team-a", true},
+		{"team-b", true},
+		{"team-c", false},
+	} {
+		if delegated := operatorConfig.IsDelegatedNamespace(test.namespace); delegated != test.delegated {
+			t.Errorf("%q: Mismatch for IsDelegatedNamespace. Expected(%t), Found(%t)", test.namespace, test.delegated, delegated)
+		}
+	}
+	if values := operatorConfig.Values(); values["delegated_namespaces"] != 2 {
+		t.Errorf("Mismatch for delegated_namespaces value. Expected(2), Found(%v)", values["delegated_namespaces"])
+	}
+	if DefaultOperatorConfig().IsDelegatedNamespace("team-a") {
+		t.Errorf("expected no Namespace to be delegated by default")
+	}
+}
+
+func TestOperatorConfigForeignOperators(t *testing.T) {
+	operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: map[string]string{"foreign_operators": "dedicated-admin-operator, other-operator"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, test := range []struct {
+		managedBy string
+		foreign   bool
+	}{
+		{"dedicated-admin-operator", true},
+		{"other-operator", true},
+		{OperatorName, false},
+		{"", false},
+	} {
+		operator, foreign := operatorConfig.ForeignOperator(map[string]string{ManagedByLabel: test.managedBy})
+		if foreign != test.foreign || (foreign && operator != test.managedBy) {
+			t.Errorf("%q: Mismatch for ForeignOperator. Expected(%t), Found(%q, %t)", test.managedBy, test.foreign, operator, foreign)
+		}
+	}
+	if values := operatorConfig.Values(); values["foreign_operators"] != 2 {
+		t.Errorf("Mismatch for foreign_operators value. Expected(2), Found(%v)", values["foreign_operators"])
+	}
+	if _, foreign := DefaultOperatorConfig().ForeignOperator(map[string]string{ManagedByLabel: "dedicated-admin-operator"}); foreign {
+		t.Errorf("expected no foreign operator by default")
+	}
+}
+
+func TestOperatorConfigProtectedNamespaces(t *testing.T) {
+	var tests = []struct {
+		label    string
+		data     map[string]string
+		valid    bool
+		policy   ProtectedNamespacePolicy
+		orphaned float64
+	}{
+		{"defaults", nil, true, ProtectedNamespaceDelete, 0},
+		{"delete", map[string]string{"protected_namespaces": "default, kube-system", "protected_namespace_policy": "delete"}, true, ProtectedNamespaceDelete, 0},
+		{"orphan", map[string]string{"protected_namespaces": "default, kube-system", "protected_namespace_policy": "orphan"}, true, ProtectedNamespaceOrphan, 1},
+		{"invalid", map[string]string{"protected_namespace_policy": "keep"}, false, "", 0},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.ProtectedNamespacePolicy != test.policy {
+			t.Errorf("%s: Mismatch for ProtectedNamespacePolicy. Expected(%s), Found(%s)", test.label, test.policy, operatorConfig.ProtectedNamespacePolicy)
+		}
+		if protected := operatorConfig.IsProtectedNamespace("kube-system"); protected != (test.data["protected_namespaces"] != "") {
+			t.Errorf("%s: Mismatch for IsProtectedNamespace. Expected(%t), Found(%t)", test.label, !protected, protected)
+		}
+		if values := operatorConfig.Values(); values["protected_namespace_policy"] != test.orphaned {
+			t.Errorf("%s: Mismatch for protected_namespace_policy value. Expected(%v), Found(%v)", test.label, test.orphaned, values["protected_namespace_policy"])
+		}
+	}
+}
+
+func TestOperatorConfigValues(t *testing.T) {
+	operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: map[string]string{
+		"resync_interval_seconds": "600",
+		"sensitive_roles":         "cluster-admin, admin",
+		"policy_endpoint":         "https://opa.example.com/v1/data/rbac/allow",
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var tests = []struct {
+		key      string
+		expected float64
+	}{
+		{"status_namespace_threshold", 100},
+		{"resync_interval_seconds", 600},
+		{"jitter_factor", 0.1},
+		{"policy_endpoint", 1},
+		{"policy_timeout_seconds", 5},
+		{"sensitive_roles", 2},
+		{"permission_request_allowed_roles", 0},
+		{"cluster_labels", 0},
+		{"stuck_deadline_seconds", 1800},
+		{"deletion_grace_period_seconds", 0},
+		{"usage_endpoint", 0},
+		{"stale_grant_days", 0},
+		{"group_cache_ttl_seconds", 300},
+		{"removal_grace_period_seconds", 0},
+		{"grant_decision_endpoint", 0},
+		{"grant_decision_timeout_seconds", 5},
+		{"grant_decision_failure_mode", 0},
+		{"foreign_operators", 0},
+		{"event_verbosity", 1},
+		{"event_summary_interval_seconds", 300},
+		{"allow_unrecorded_requesters", 0},
+	}
+	values := operatorConfig.Values()
+	for _, test := range tests {
+		if values[test.key] != test.expected {
+			t.Errorf("Mismatch for %s. Expected(%v), Found(%v)", test.key, test.expected, values[test.key])
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	operatorConfig := &OperatorConfig{JitterFactor: 0.5}
+	for i := 0; i < 100; i++ {
+		if interval := operatorConfig.Jitter(time.Minute); interval < time.Minute || interval > 90*time.Second {
+			t.Fatalf("expected an interval between 1m and 1m30s, got %s", interval)
+		}
+	}
+	if interval := operatorConfig.Jitter(0); interval != 0 {
+		t.Errorf("expected no requeue to stay unchanged, got %s", interval)
+	}
+	operatorConfig.JitterFactor = 0
+	if interval := operatorConfig.Jitter(time.Minute); interval != time.Minute {
+		t.Errorf("expected no jitter with a zero factor, got %s", interval)
+	}
+}
+
+func TestOperatorConfigUnmanagedAccessMode(t *testing.T) {
+	var tests = []struct {
+		label string
+		data  map[string]string
+		valid bool
+		mode  UnmanagedAccessMode
+		value float64
+	}{
+		{"defaults", nil, true, UnmanagedAccessOff, 0},
+		{"warn", map[string]string{"unmanaged_access_mode": "warn"}, true, UnmanagedAccessWarn, 1},
+		{"strict", map[string]string{"unmanaged_access_mode": "strict"}, true, UnmanagedAccessStrict, 2},
+		{"invalid", map[string]string{"unmanaged_access_mode": "enforce"}, false, "", 0},
+	}
+	for _, test := range tests {
+		operatorConfig, err := OperatorConfigFromConfigMap(&corev1.ConfigMap{Data: test.data})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if operatorConfig.UnmanagedAccessMode != test.mode {
+			t.Errorf("%s: Mismatch for UnmanagedAccessMode. Expected(%s), Found(%s)", test.label, test.mode, operatorConfig.UnmanagedAccessMode)
+		}
+		if values := operatorConfig.Values(); values["unmanaged_access_mode"] != test.value {
+			t.Errorf("%s: Mismatch for unmanaged_access_mode value. Expected(%v), Found(%v)", test.label, test.value, values["unmanaged_access_mode"])
+		}
+	}
+}
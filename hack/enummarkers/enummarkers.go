@@ -0,0 +1,208 @@
+// Package enummarkers reads the +kubebuilder:validation:Enum markers of the API types, and sets the
+// enums they declare on the OpenAPI schemas of the CRDs, so the apiserver rejects the values the
+// operator does not know and kubectl explain lists the ones it does.
+package enummarkers
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// markerPrefix starts the enum markers placed on a type, or on a field to override the enum of its
+// type, e.g. +kubebuilder:validation:Enum=DenyOverAllow;AllowOverDeny
+const markerPrefix = "+kubebuilder:validation:Enum="
+
+// field is a field of an API struct as it is serialized
+type field struct {
+	// jsonName is the name of the property of the field, empty for the fields inlined in their struct
+	jsonName string
+	// typeName is the name of the type of the field, or of its items for a slice or a map
+	typeName string
+	// container is the keyword of the schema of the items, items or additionalProperties, for a slice
+	// or a map
+	container string
+	// enum is declared by the marker of the field
+	enum []string
+}
+
+// Types are the API types of a package, with the enums declared by their markers
+type Types struct {
+	enums   map[string][]string
+	structs map[string][]field
+}
+
+// Collect returns the API types of the Go source files of dir
+func Collect(dir string) (*Types, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	types := &Types{enums: map[string][]string{}, structs: map[string][]field{}}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range genDecl.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					doc := typeSpec.Doc
+					if doc == nil {
+						doc = genDecl.Doc
+					}
+					if enum := markerEnum(doc); enum != nil {
+						types.enums[typeSpec.Name.Name] = enum
+					}
+					if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+						fields, err := structFields(structType)
+						if err != nil {
+							return nil, fmt.Errorf("%s: %v", fset.Position(typeSpec.Pos()), err)
+						}
+						types.structs[typeSpec.Name.Name] = fields
+					}
+				}
+			}
+		}
+	}
+	return types, nil
+}
+
+// markerEnum returns the values of the enum marker of doc, nil without a marker
+func markerEnum(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	for _, comment := range doc.List {
+		line := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if strings.HasPrefix(line, markerPrefix) {
+			return strings.Split(strings.TrimPrefix(line, markerPrefix), ";")
+		}
+	}
+	return nil
+}
+
+// structFields returns the serialized fields of structType
+func structFields(structType *ast.StructType) ([]field, error) {
+	var fields []field
+	for _, astField := range structType.Fields.List {
+		f := field{enum: markerEnum(astField.Doc)}
+		f.typeName, f.container = typeName(astField.Type)
+
+		jsonTag := ""
+		if astField.Tag != nil {
+			tag, err := strconv.Unquote(astField.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			jsonTag = reflect.StructTag(tag).Get("json")
+		}
+		jsonName := strings.Split(jsonTag, ",")[0]
+		if jsonName == "-" {
+			continue
+		}
+
+		switch {
+		case len(astField.Names) == 0 && jsonName == "":
+			// an embedded struct is inlined
+			fields = append(fields, f)
+		case len(astField.Names) == 0:
+			f.jsonName = jsonName
+			fields = append(fields, f)
+		default:
+			for _, name := range astField.Names {
+				f.jsonName = jsonName
+				if f.jsonName == "" {
+					f.jsonName = name.Name
+				}
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// typeName returns the name of the type of expr, or of its items and the keyword of their schema for a
+// slice or a map. Types of other packages have no name, they are not API types.
+func typeName(expr ast.Expr) (string, string) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name, ""
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.ArrayType:
+		name, _ := typeName(t.Elt)
+		return name, "items"
+	case *ast.MapType:
+		name, _ := typeName(t.Value)
+		return name, "additionalProperties"
+	default:
+		return "", ""
+	}
+}
+
+// Apply sets on schema, the OpenAPI schema of the type typeName, the enums of the fields of typeName
+// and of the API types it holds. The enums of the fields without one are removed, the markers being
+// the only source of the enums. It fails when a field with an enum has no property in the schema.
+func (t *Types) Apply(schema map[string]interface{}, typeName string) error {
+	fields, ok := t.structs[typeName]
+	if !ok {
+		return nil
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	for _, f := range fields {
+		if f.jsonName == "" {
+			if err := t.Apply(schema, f.typeName); err != nil {
+				return err
+			}
+			continue
+		}
+
+		enum := f.enum
+		if enum == nil {
+			enum = t.enums[f.typeName]
+		}
+		property, ok := properties[f.jsonName].(map[string]interface{})
+		if !ok {
+			if enum != nil {
+				return fmt.Errorf("%s.%s has an enum but no property %s in the schema", typeName, f.jsonName, f.jsonName)
+			}
+			continue
+		}
+
+		node := property
+		if f.container != "" {
+			if node, ok = property[f.container].(map[string]interface{}); !ok {
+				if enum != nil {
+					return fmt.Errorf("%s.%s has an enum but no %s in the schema", typeName, f.jsonName, f.container)
+				}
+				continue
+			}
+		}
+		if enum != nil {
+			values := make([]interface{}, len(enum))
+			for i, value := range enum {
+				values[i] = value
+			}
+			node["enum"] = values
+		} else {
+			delete(node, "enum")
+		}
+
+		if err := t.Apply(node, f.typeName); err != nil {
+			return fmt.Errorf("%s.%s: %v", typeName, f.jsonName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package enummarkers
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestApply(t *testing.T) {
+	types, err := Collect("testdata")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	schema := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(`
+properties:
+  metadata:
+    type: object
+  spec:
+    properties:
+      colour:
+        type: string
+      subjectKind:
+        type: string
+      parts:
+        items:
+          properties:
+            colour:
+              type: string
+        type: array
+      size:
+        enum: [Small, Large]
+        type: string
+      sides:
+        additionalProperties:
+          type: string
+        type: object
+`), &schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := types.Apply(schema, "Widget"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(`
+properties:
+  metadata:
+    type: object
+  spec:
+    properties:
+      colour:
+        enum: [Red, Blue]
+        type: string
+      subjectKind:
+        enum: [Group, User]
+        type: string
+      parts:
+        items:
+          properties:
+            colour:
+              enum: [Red, Blue]
+              type: string
+        type: array
+      size:
+        type: string
+      sides:
+        additionalProperties:
+          enum: [Red, Blue]
+          type: string
+        type: object
+`), &expected); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("got %+v, want %+v", schema, expected)
+	}
+}
+
+func TestApplyMissingProperty(t *testing.T) {
+	types, err := Collect("testdata")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	schema := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"spec": map[string]interface{}{"properties": map[string]interface{}{}},
+		},
+	}
+	if err := types.Apply(schema, "Widget"); err == nil {
+		t.Errorf("expected an error for the enums without a property")
+	}
+}
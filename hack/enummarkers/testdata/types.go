@@ -0,0 +1,39 @@
+package testdata
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// Widget is a kind of the test API
+type Widget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WidgetSpec `json:"spec,omitempty"`
+}
+
+// WidgetSpec embeds the common fields of the test API
+type WidgetSpec struct {
+	Common `json:",inline"`
+	// Kind of the subject, overriding the enum of Colour
+	// +kubebuilder:validation:Enum=Group;User
+	SubjectKind Colour `json:"subjectKind"`
+	// Parts of the Widget
+	Parts []Part `json:"parts,omitempty"`
+	// Size of the Widget, its former enum is removed
+	Size string `json:"size,omitempty"`
+	// Colours of the sides of the Widget
+	Sides map[string]Colour `json:"sides,omitempty"`
+}
+
+// Common are fields inlined in the specs
+type Common struct {
+	Colour *Colour `json:"colour,omitempty"`
+}
+
+// Part is a part of a Widget
+type Part struct {
+	Colour Colour `json:"colour"`
+}
+
+// Colour is a colour of the test API
+// +kubebuilder:validation:Enum=Red;Blue
+type Colour string
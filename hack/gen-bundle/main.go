@@ -0,0 +1,333 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// gen-bundle generates the OLM bundle of the operator from the code:
+//   - the CSV permissions from the +kubebuilder:rbac markers of cmd and pkg
+//   - the owned CRD descriptions from the doc comments of the API types
+//   - the alm-examples from the example CRs in deploy/crds
+//   - the install deployment from deploy/operator.yaml
+//
+// Run it from the repository root with: go run ./hack/gen-bundle
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/hack/rbacmarkers"
+	"github.com/openshift/rbac-permissions-operator/version"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+var (
+	apiDir        = flag.String("api-dir", "pkg/apis/managed/v1alpha1", "directory of the API types")
+	deployDir     = flag.String("deploy-dir", "deploy", "directory of the deploy manifests")
+	outputDir     = flag.String("output-dir", "deploy/olm-catalog", "directory the bundle is written to")
+	bundleChannel = flag.String("channel", "alpha", "channel of the bundle in the package")
+)
+
+func main() {
+	flag.Parse()
+
+	if err := generate(); err != nil {
+		fmt.Fprintf(os.Stderr, "gen-bundle: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate writes the CSV, CRDs and package of the bundle
+func generate() error {
+	markers, err := rbacmarkers.Collect("cmd", "pkg")
+	if err != nil {
+		return err
+	}
+	descriptions, err := kindDescriptions(*apiDir)
+	if err != nil {
+		return err
+	}
+	crds, err := readCRDs(filepath.Join(*deployDir, "crds"))
+	if err != nil {
+		return err
+	}
+	examples, err := readExamples(filepath.Join(*deployDir, "crds"))
+	if err != nil {
+		return err
+	}
+	deployment := &appsv1.Deployment{}
+	if err := readManifest(filepath.Join(*deployDir, "operator.yaml"), deployment); err != nil {
+		return err
+	}
+
+	var owned []interface{}
+	for _, crd := range crds {
+		description, ok := descriptions[crd.kind]
+		if !ok {
+			return fmt.Errorf("no API type documents kind %s of %s", crd.kind, crd.path)
+		}
+		owned = append(owned, map[string]interface{}{
+			"name":        crd.name,
+			"version":     crd.version,
+			"kind":        crd.kind,
+			"displayName": displayName(crd.kind),
+			"description": description,
+		})
+	}
+
+	almExamples, err := json.Marshal(examples)
+	if err != nil {
+		return err
+	}
+
+	serviceAccountName := deployment.Spec.Template.Spec.ServiceAccountName
+	csvName := fmt.Sprintf("%s.v%s", operatorconfig.OperatorName, version.Version)
+	csv := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "operators.coreos.com/v1alpha1",
+		"kind":       "ClusterServiceVersion",
+		"metadata": map[string]interface{}{
+			"name":      csvName,
+			"namespace": "placeholder",
+			"annotations": map[string]interface{}{
+				"alm-examples": string(almExamples),
+				"capabilities": "Basic Install",
+			},
+		},
+		"spec": map[string]interface{}{
+			"displayName": "RBAC Permissions Operator",
+			"description": "Manages RBAC bindings of Groups to ClusterRoles, cluster wide or in the Namespaces matching regular expressions.",
+			"version":     version.Version,
+			"maturity":    *bundleChannel,
+			"installModes": []interface{}{
+				map[string]interface{}{"type": "OwnNamespace", "supported": true},
+				map[string]interface{}{"type": "SingleNamespace", "supported": true},
+				map[string]interface{}{"type": "MultiNamespace", "supported": false},
+				map[string]interface{}{"type": "AllNamespaces", "supported": false},
+			},
+			"customresourcedefinitions": map[string]interface{}{
+				"owned": owned,
+			},
+			"install": map[string]interface{}{
+				"strategy": "deployment",
+				"spec": map[string]interface{}{
+					"clusterPermissions": []interface{}{map[string]interface{}{
+						"serviceAccountName": serviceAccountName,
+						"rules":              rbacmarkers.BuildRules(markers, false),
+					}},
+					"permissions": []interface{}{map[string]interface{}{
+						"serviceAccountName": serviceAccountName,
+						"rules":              rbacmarkers.BuildRules(markers, true),
+					}},
+					"deployments": []interface{}{map[string]interface{}{
+						"name": deployment.Name,
+						"spec": deployment.Spec,
+					}},
+				},
+			},
+		},
+	}}
+
+	bundleDir := filepath.Join(*outputDir, operatorconfig.OperatorName, version.Version)
+	if err := os.MkdirAll(bundleDir, 0755); err != nil {
+		return err
+	}
+	if err := writeYAML(filepath.Join(bundleDir, csvName+".clusterserviceversion.yaml"), csv); err != nil {
+		return err
+	}
+	for _, crd := range crds {
+		if err := ioutil.WriteFile(filepath.Join(bundleDir, filepath.Base(crd.path)), crd.content, 0644); err != nil {
+			return err
+		}
+	}
+
+	pkg := &unstructured.Unstructured{Object: map[string]interface{}{
+		"packageName":    operatorconfig.OperatorName,
+		"defaultChannel": *bundleChannel,
+		"channels": []interface{}{map[string]interface{}{
+			"name":       *bundleChannel,
+			"currentCSV": csvName,
+		}},
+	}}
+	return writeYAML(filepath.Join(*outputDir, operatorconfig.OperatorName, operatorconfig.OperatorName+".package.yaml"), pkg)
+}
+
+// kindDescriptions returns the doc comment of every API kind in dir, by kind. A kind is a type
+// with a matching <kind>List type.
+func kindDescriptions(dir string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := map[string]string{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range genDecl.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					doc := typeSpec.Doc
+					if doc == nil {
+						doc = genDecl.Doc
+					}
+					docs[typeSpec.Name.Name] = docText(doc)
+				}
+			}
+		}
+	}
+
+	descriptions := map[string]string{}
+	for name, doc := range docs {
+		if _, ok := docs[name+"List"]; ok {
+			descriptions[name] = doc
+		}
+	}
+	return descriptions, nil
+}
+
+// docText returns the text of a doc comment without its markers
+func docText(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	var lines []string
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "+") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+// crdManifest is a CustomResourceDefinition shipped in the deploy directory
+type crdManifest struct {
+	path    string
+	content []byte
+	name    string
+	kind    string
+	version string
+}
+
+// readCRDs returns the CustomResourceDefinitions of dir, sorted by name
+func readCRDs(dir string) ([]crdManifest, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*_crd.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var crds []crdManifest
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		crd := &unstructured.Unstructured{}
+		if err := decodeYAML(content, &crd.Object); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		crdVersion, _, _ := unstructured.NestedString(crd.Object, "spec", "version")
+		crds = append(crds, crdManifest{
+			path:    path,
+			content: content,
+			name:    crd.GetName(),
+			kind:    kind,
+			version: crdVersion,
+		})
+	}
+	sort.Slice(crds, func(i, j int) bool { return crds[i].name < crds[j].name })
+	return crds, nil
+}
+
+// readExamples returns the example custom resources of dir
+func readExamples(dir string) ([]interface{}, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*_cr.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var examples []interface{}
+	for _, path := range paths {
+		example := map[string]interface{}{}
+		if err := readManifest(path, &example); err != nil {
+			return nil, err
+		}
+		examples = append(examples, example)
+	}
+	return examples, nil
+}
+
+// readManifest decodes the YAML manifest at path into obj
+func readManifest(path string, obj interface{}) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := decodeYAML(content, obj); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// decodeYAML decodes the YAML document content into obj
+func decodeYAML(content []byte, obj interface{}) error {
+	j, err := utilyaml.ToJSON(content)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(j, obj)
+}
+
+// writeYAML writes obj as YAML to path
+func writeYAML(path string, obj *unstructured.Unstructured) error {
+	buf := &bytes.Buffer{}
+	if err := kjson.NewYAMLSerializer(kjson.DefaultMetaFactory, nil, nil).Encode(obj, buf); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// displayName splits a CamelCase kind into words
+func displayName(kind string) string {
+	var out []rune
+	for i, r := range kind {
+		if i > 0 && unicode.IsUpper(r) {
+			out = append(out, ' ')
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// gen-crd-enums sets the enums of the OpenAPI schemas of the CRDs in deploy/crds from the
+// +kubebuilder:validation:Enum markers of the API types, so the apiserver enforces the values the
+// operator knows.
+//
+// Run it from the repository root with: go run ./hack/gen-crd-enums
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/openshift/rbac-permissions-operator/hack/enummarkers"
+
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	apiDir    = flag.String("api-dir", "pkg/apis/managed/v1alpha1", "directory of the API types")
+	deployDir = flag.String("deploy-dir", "deploy", "directory of the deploy manifests")
+)
+
+func main() {
+	flag.Parse()
+
+	if err := generate(); err != nil {
+		fmt.Fprintf(os.Stderr, "gen-crd-enums: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate rewrites the CRDs of the deploy directory with the enums of the markers
+func generate() error {
+	types, err := enummarkers.Collect(*apiDir)
+	if err != nil {
+		return err
+	}
+	paths, err := filepath.Glob(filepath.Join(*deployDir, "crds", "*_crd.yaml"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rendered, err := renderCRD(types, content)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if err := ioutil.WriteFile(path, rendered, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderCRD returns the CRD manifest content with the enums of types set on its schema
+func renderCRD(types *enummarkers.Types, content []byte) ([]byte, error) {
+	crd := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &crd); err != nil {
+		return nil, err
+	}
+	spec, _ := crd["spec"].(map[string]interface{})
+	names, _ := spec["names"].(map[string]interface{})
+	kind, _ := names["kind"].(string)
+	validation, _ := spec["validation"].(map[string]interface{})
+	schema, ok := validation["openAPIV3Schema"].(map[string]interface{})
+	if kind == "" || !ok {
+		return nil, fmt.Errorf("no kind or OpenAPI schema")
+	}
+
+	if err := types.Apply(schema, kind); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(crd)
+}
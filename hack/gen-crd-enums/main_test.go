@@ -0,0 +1,36 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/hack/enummarkers"
+)
+
+// TestCRDsUpToDate fails when the CRDs in deploy differ from the enum markers, i.e. when
+// gen-crd-enums was not run after a marker changed
+func TestCRDsUpToDate(t *testing.T) {
+	types, err := enummarkers.Collect("../../pkg/apis/managed/v1alpha1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	paths, err := filepath.Glob("../../deploy/crds/*_crd.yaml")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no CRDs found: %v", err)
+	}
+
+	for _, path := range paths {
+		actual, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected, err := renderCRD(types, actual)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		if string(actual) != string(expected) {
+			t.Errorf("%s is out of date with the enum markers, run: go run ./hack/gen-crd-enums\n%s", path, expected)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// gen-rbac generates the ClusterRole and Role of the operator from the +kubebuilder:rbac markers
+// of cmd and pkg, so the operator holds exactly the permissions its code declares.
+//
+// Run it from the repository root with: go run ./hack/gen-rbac
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/hack/rbacmarkers"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
+)
+
+var deployDir = flag.String("deploy-dir", "deploy", "directory the roles are written to")
+
+func main() {
+	flag.Parse()
+
+	markers, err := rbacmarkers.Collect("cmd", "pkg")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gen-rbac: %v\n", err)
+		os.Exit(1)
+	}
+	clusterRole, role, err := renderRoles(markers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gen-rbac: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(*deployDir, "cluster_role.yaml"), clusterRole, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "gen-rbac: %v\n", err)
+		os.Exit(1)
+	}
+	if err := ioutil.WriteFile(filepath.Join(*deployDir, "role.yaml"), role, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "gen-rbac: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// renderRoles returns the YAML manifests of the ClusterRole and Role granting the markers
+func renderRoles(markers []rbacmarkers.Marker) ([]byte, []byte, error) {
+	clusterRole, err := encode(&rbacv1.ClusterRole{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+		ObjectMeta: metav1.ObjectMeta{Name: operatorconfig.OperatorName},
+		Rules:      rbacmarkers.BuildRules(markers, false),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	role, err := encode(&rbacv1.Role{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "Role"},
+		ObjectMeta: metav1.ObjectMeta{Name: operatorconfig.OperatorName},
+		Rules:      rbacmarkers.BuildRules(markers, true),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return clusterRole, role, nil
+}
+
+// encode returns obj as YAML. obj is converted to unstructured first so empty fields are omitted the
+// same way as in the other generated manifests.
+func encode(obj runtime.Object) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+	buf := &bytes.Buffer{}
+	if err := kjson.NewYAMLSerializer(kjson.DefaultMetaFactory, nil, nil).Encode(&unstructured.Unstructured{Object: content}, buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/hack/rbacmarkers"
+)
+
+// TestRolesUpToDate fails when the roles in deploy differ from the RBAC markers, i.e. when
+// gen-rbac was not run after a marker changed
+func TestRolesUpToDate(t *testing.T) {
+	markers, err := rbacmarkers.Collect("../../cmd", "../../pkg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clusterRole, role, err := renderRoles(markers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for path, expected := range map[string][]byte{
+		"../../deploy/cluster_role.yaml": clusterRole,
+		"../../deploy/role.yaml":         role,
+	} {
+		actual, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(actual) != string(expected) {
+			t.Errorf("%s is out of date with the RBAC markers, run: go run ./hack/gen-rbac\n%s", path, expected)
+		}
+	}
+}
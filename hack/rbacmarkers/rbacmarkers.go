@@ -0,0 +1,169 @@
+// Package rbacmarkers reads the +kubebuilder:rbac markers placed next to the code needing each
+// permission of the operator, and builds the RBAC rules of the operator from them.
+package rbacmarkers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// markerPrefix starts the RBAC markers placed next to the code needing the permissions, e.g.
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list
+const markerPrefix = "+kubebuilder:rbac:"
+
+// Marker is a permission required by the operator. Markers with a Namespace are granted in
+// the operator namespace only, the others cluster wide.
+type Marker struct {
+	Namespace string
+	Rule      rbacv1.PolicyRule
+}
+
+// Collect returns the RBAC markers of every Go source file under dirs
+func Collect(dirs ...string) ([]Marker, error) {
+	var markers []Marker
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for i, line := range strings.Split(string(content), "\n") {
+				line = strings.TrimSpace(line)
+				if !strings.HasPrefix(line, "// "+markerPrefix) {
+					continue
+				}
+				marker, err := parseMarker(strings.TrimPrefix(line, "// "+markerPrefix))
+				if err != nil {
+					return fmt.Errorf("%s:%d: %v", path, i+1, err)
+				}
+				markers = append(markers, marker)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return markers, nil
+}
+
+// parseMarker parses the comma separated key=value options of an RBAC marker.
+// Values are lists separated by semicolons.
+func parseMarker(options string) (Marker, error) {
+	marker := Marker{}
+	for _, option := range strings.Split(options, ",") {
+		kv := strings.SplitN(option, "=", 2)
+		if len(kv) != 2 {
+			return marker, fmt.Errorf("invalid RBAC marker option %q", option)
+		}
+		values := strings.Split(kv[1], ";")
+		for i := range values {
+			values[i] = strings.Trim(values[i], `"`)
+		}
+
+		switch kv[0] {
+		case "groups":
+			marker.Rule.APIGroups = values
+		case "resources":
+			marker.Rule.Resources = values
+		case "resourceNames":
+			marker.Rule.ResourceNames = values
+		case "verbs":
+			marker.Rule.Verbs = values
+		case "urls":
+			marker.Rule.NonResourceURLs = values
+		case "namespace":
+			marker.Namespace = kv[1]
+		default:
+			return marker, fmt.Errorf("unknown RBAC marker option %q", kv[0])
+		}
+	}
+
+	if len(marker.Rule.Verbs) == 0 {
+		return marker, fmt.Errorf("RBAC marker without verbs")
+	}
+	return marker, nil
+}
+
+// BuildRules returns the rules of the markers in namespaced or cluster scope. Rules on the same
+// resources are merged into one with the union of their verbs.
+func BuildRules(markers []Marker, namespaced bool) []rbacv1.PolicyRule {
+	merged := map[string]*rbacv1.PolicyRule{}
+	var keys []string
+	for _, marker := range markers {
+		if (marker.Namespace != "") != namespaced {
+			continue
+		}
+		key := strings.Join([]string{
+			strings.Join(marker.Rule.APIGroups, ";"),
+			strings.Join(marker.Rule.Resources, ";"),
+			strings.Join(marker.Rule.ResourceNames, ";"),
+			strings.Join(marker.Rule.NonResourceURLs, ";"),
+		}, "/")
+
+		rule, ok := merged[key]
+		if !ok {
+			rule = marker.Rule.DeepCopy()
+			rule.Verbs = nil
+			merged[key] = rule
+			keys = append(keys, key)
+		}
+		rule.Verbs = unionVerbs(rule.Verbs, marker.Rule.Verbs)
+	}
+
+	sort.Strings(keys)
+	rules := make([]rbacv1.PolicyRule, 0, len(keys))
+	for _, key := range keys {
+		rules = append(rules, *merged[key])
+	}
+	return rules
+}
+
+// unionVerbs returns the sorted union of verbs a and b, or only "*" if either holds it
+func unionVerbs(a, b []string) []string {
+	set := map[string]bool{}
+	for _, verb := range append(append([]string{}, a...), b...) {
+		if verb == rbacv1.VerbAll {
+			return []string{rbacv1.VerbAll}
+		}
+		set[verb] = true
+	}
+	verbs := make([]string, 0, len(set))
+	for verb := range set {
+		verbs = append(verbs, verb)
+	}
+	sort.Strings(verbs)
+	return verbs
+}
+
+// Allows returns whether rules grant verb on resource, or resource/subresource, of apiGroup
+func Allows(rules []rbacv1.PolicyRule, apiGroup, resource, verb string) bool {
+	for _, rule := range rules {
+		if contains(rule.APIGroups, apiGroup) && contains(rule.Resources, resource) && contains(rule.Verbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns whether values holds value or the "*" wildcard
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value || v == "*" {
+			return true
+		}
+	}
+	return false
+}
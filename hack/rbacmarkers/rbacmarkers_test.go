@@ -0,0 +1,89 @@
+package rbacmarkers
+
+import (
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestParseMarker(t *testing.T) {
+	var tests = []struct {
+		options  string
+		expected Marker
+		valid    bool
+	}{
+		{
+			`groups="",resources=namespaces,verbs=get;list;watch`,
+			Marker{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get", "list", "watch"}}},
+			true,
+		},
+		{
+			`groups=apps,resources=deployments/finalizers,resourceNames=op,verbs=update,namespace=ns`,
+			Marker{Namespace: "ns", Rule: rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"deployments/finalizers"}, ResourceNames: []string{"op"}, Verbs: []string{"update"}}},
+			true,
+		},
+		{`groups=apps,resources=deployments`, Marker{}, false},
+		{`groups=apps,colour=blue,verbs=get`, Marker{}, false},
+		{`groups`, Marker{}, false},
+	}
+	for _, test := range tests {
+		marker, err := parseMarker(test.options)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.options, test.valid, err)
+			continue
+		}
+		if test.valid && !reflect.DeepEqual(marker, test.expected) {
+			t.Errorf("%s: got %+v, want %+v", test.options, marker, test.expected)
+		}
+	}
+}
+
+func TestBuildRules(t *testing.T) {
+	markers := []Marker{
+		{Rule: rbacv1.PolicyRule{APIGroups: []string{"user.openshift.io"}, Resources: []string{"groups"}, Verbs: []string{"get", "create"}}},
+		{Rule: rbacv1.PolicyRule{APIGroups: []string{"user.openshift.io"}, Resources: []string{"groups"}, Verbs: []string{"update", "get"}}},
+		{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get"}}},
+		{Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"*"}}},
+		{Namespace: "ns", Rule: rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}}},
+	}
+
+	expected := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"*"}},
+		{APIGroups: []string{"user.openshift.io"}, Resources: []string{"groups"}, Verbs: []string{"create", "get", "update"}},
+	}
+	if rules := BuildRules(markers, false); !reflect.DeepEqual(rules, expected) {
+		t.Errorf("got cluster rules %+v, want %+v", rules, expected)
+	}
+
+	expected = []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get"}},
+	}
+	if rules := BuildRules(markers, true); !reflect.DeepEqual(rules, expected) {
+		t.Errorf("got namespaced rules %+v, want %+v", rules, expected)
+	}
+}
+
+func TestAllows(t *testing.T) {
+	rules := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get", "list"}},
+		{APIGroups: []string{"managed.openshift.io"}, Resources: []string{"*"}, Verbs: []string{"*"}},
+	}
+
+	var tests = []struct {
+		apiGroup string
+		resource string
+		verb     string
+		allowed  bool
+	}{
+		{"", "namespaces", "get", true},
+		{"", "namespaces", "delete", false},
+		{"apps", "namespaces", "get", false},
+		{"managed.openshift.io", "grouppermissions/status", "update", true},
+	}
+	for _, test := range tests {
+		if Allows(rules, test.apiGroup, test.resource, test.verb) != test.allowed {
+			t.Errorf("Allows(%q, %s, %s) = %t, expected = %t", test.apiGroup, test.resource, test.verb, !test.allowed, test.allowed)
+		}
+	}
+}
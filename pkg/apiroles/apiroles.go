@@ -0,0 +1,189 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apiroles manages the ClusterRoles granting access to the API of the operator, so installing
+// the operator also installs the access controls over its own custom resources.
+package apiroles
+
+import (
+	"context"
+	"reflect"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("apiroles")
+
+const (
+	// ViewerRoleName is the ClusterRole reading the custom resources of the operator
+	ViewerRoleName = "grouppermission-viewer"
+	// EditorRoleName is the ClusterRole changing the custom resources requesting permissions, and
+	// simulating GroupPermissions
+	EditorRoleName = "grouppermission-editor"
+	// AdminRoleName is the ClusterRole with every access to the custom resources of the operator,
+	// including their status, the Elevations and the AccessRevocations
+	AdminRoleName = "grouppermission-admin"
+	// EditorBindingName is the ClusterRoleBinding of the editor ClusterRole to the configured admin group
+	EditorBindingName = "grouppermission-editor-admins"
+)
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;create;update;escalate
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;create;update;delete
+
+var (
+	// readVerbs and writeVerbs are the verbs of the viewer, and those the editor adds
+	readVerbs  = []string{"get", "list", "watch"}
+	writeVerbs = []string{"create", "update", "patch", "delete"}
+	// editableResources are the custom resources the editor may change
+	editableResources = []string{"grouppermissions", "groupsyncs", "permissionrequests"}
+	// allResources are every custom resource of the operator
+	allResources = []string{"accessrevocations", "elevations", "grouppermissions", "groupsyncs", "permissionrequests"}
+)
+
+// ClusterRoles returns the viewer, editor and admin ClusterRoles of the API of the operator
+func ClusterRoles() []*rbacv1.ClusterRole {
+	group := managedv1alpha1.SchemeGroupVersion.Group
+	viewer := []rbacv1.PolicyRule{{APIGroups: []string{group}, Resources: allResources, Verbs: readVerbs}}
+	editor := append(append([]rbacv1.PolicyRule{}, viewer...),
+		rbacv1.PolicyRule{APIGroups: []string{group}, Resources: editableResources, Verbs: writeVerbs},
+		// reviewers simulate candidate GroupPermissions, see the simulation package
+		rbacv1.PolicyRule{APIGroups: []string{group}, Resources: []string{"grouppermissions/simulation"}, Verbs: []string{"get"}},
+	)
+	admin := []rbacv1.PolicyRule{
+		{APIGroups: []string{group}, Resources: allResources, Verbs: append(append([]string{}, readVerbs...), writeVerbs...)},
+		{APIGroups: []string{group}, Resources: statusResources(allResources), Verbs: []string{"get", "update", "patch"}},
+		{APIGroups: []string{group}, Resources: []string{"grouppermissions/simulation"}, Verbs: []string{"get"}},
+	}
+
+	return []*rbacv1.ClusterRole{
+		newClusterRole(ViewerRoleName, viewer),
+		newClusterRole(EditorRoleName, editor),
+		newClusterRole(AdminRoleName, admin),
+	}
+}
+
+// statusResources returns the status subresources of resources
+func statusResources(resources []string) []string {
+	var status []string
+	for _, resource := range resources {
+		status = append(status, resource+"/status")
+	}
+	return status
+}
+
+// newClusterRole returns the ClusterRole name with rules, labeled as managed by the operator
+func newClusterRole(name string, rules []rbacv1.PolicyRule) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: utility.ManagedByLabels()},
+		Rules:      rules,
+	}
+}
+
+// EditorBinding returns the ClusterRoleBinding of the editor ClusterRole to adminGroup
+func EditorBinding(adminGroup string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: EditorBindingName, Labels: utility.ManagedByLabels()},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: EditorRoleName},
+		Subjects:   []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: adminGroup}},
+	}
+}
+
+// Ensure creates the ClusterRoles of the API of the operator, or restores their rules, and binds the
+// editor ClusterRole to adminGroup. Without adminGroup the binding created for a previous one is
+// deleted. ClusterRoles and bindings of the same names not managed by the operator are left as they
+// are. It returns the number of objects created, updated or deleted.
+func Ensure(ctx context.Context, c client.Client, adminGroup string) (int, error) {
+	changed := 0
+	for _, clusterRole := range ClusterRoles() {
+		existing := &rbacv1.ClusterRole{}
+		err := c.Get(ctx, types.NamespacedName{Name: clusterRole.Name}, existing)
+		switch {
+		case errors.IsNotFound(err):
+			if err := c.Create(ctx, clusterRole); err != nil {
+				return changed, err
+			}
+			log.Info("Created ClusterRole", "ClusterRole", clusterRole.Name)
+			changed++
+		case err != nil:
+			return changed, err
+		case !utility.IsManaged(existing.ObjectMeta):
+			log.Info("Skipping ClusterRole not managed by the operator", "ClusterRole", clusterRole.Name)
+		case !reflect.DeepEqual(existing.Rules, clusterRole.Rules):
+			existing.Rules = clusterRole.Rules
+			if err := c.Update(ctx, existing); err != nil {
+				return changed, err
+			}
+			log.Info("Updated ClusterRole", "ClusterRole", clusterRole.Name)
+			changed++
+		}
+	}
+
+	bindingChanged, err := ensureEditorBinding(ctx, c, adminGroup)
+	if bindingChanged {
+		changed++
+	}
+	return changed, err
+}
+
+// ensureEditorBinding binds the editor ClusterRole to adminGroup, or deletes the managed binding when
+// adminGroup is empty, and returns whether the binding changed
+func ensureEditorBinding(ctx context.Context, c client.Client, adminGroup string) (bool, error) {
+	existing := &rbacv1.ClusterRoleBinding{}
+	err := c.Get(ctx, types.NamespacedName{Name: EditorBindingName}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	exists := err == nil
+	if exists && !utility.IsManaged(existing.ObjectMeta) {
+		log.Info("Skipping ClusterRoleBinding not managed by the operator", "ClusterRoleBinding", EditorBindingName)
+		return false, nil
+	}
+
+	if adminGroup == "" {
+		if !exists {
+			return false, nil
+		}
+		if err := c.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		log.Info("Deleted ClusterRoleBinding", "ClusterRoleBinding", EditorBindingName)
+		return true, nil
+	}
+
+	binding := EditorBinding(adminGroup)
+	if !exists {
+		if err := c.Create(ctx, binding); err != nil {
+			return false, err
+		}
+		log.Info("Created ClusterRoleBinding", "ClusterRoleBinding", EditorBindingName, "Group", adminGroup)
+		return true, nil
+	}
+	if reflect.DeepEqual(existing.Subjects, binding.Subjects) {
+		return false, nil
+	}
+	existing.Subjects = binding.Subjects
+	if err := c.Update(ctx, existing); err != nil {
+		return false, err
+	}
+	log.Info("Updated ClusterRoleBinding", "ClusterRoleBinding", EditorBindingName, "Group", adminGroup)
+	return true, nil
+}
@@ -0,0 +1,111 @@
+package apiroles
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnsure(t *testing.T) {
+	// the viewer was edited by hand, the admin is not managed by the operator
+	edited := ClusterRoles()[0]
+	edited.Rules = nil
+	unmanaged := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: AdminRoleName}}
+	c := fake.NewFakeClient(edited, unmanaged)
+
+	changed, err := Ensure(context.TODO(), c, "platform-admins")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changed != 3 {
+		t.Errorf("Mismatch for changed objects. Expected(3), Found(%d)", changed)
+	}
+	for _, expected := range ClusterRoles() {
+		clusterRole := &rbacv1.ClusterRole{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: expected.Name}, clusterRole); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if expected.Name == AdminRoleName {
+			if len(clusterRole.Rules) != 0 || utility.IsManaged(clusterRole.ObjectMeta) {
+				t.Errorf("expected the unmanaged ClusterRole to be left as it is, got %+v", clusterRole)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(clusterRole.Rules, expected.Rules) {
+			t.Errorf("Mismatch for the rules of %s. Expected(%+v), Found(%+v)", expected.Name, expected.Rules, clusterRole.Rules)
+		}
+	}
+	binding := &rbacv1.ClusterRoleBinding{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: EditorBindingName}, binding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if binding.RoleRef.Name != EditorRoleName || !reflect.DeepEqual(binding.Subjects, EditorBinding("platform-admins").Subjects) {
+		t.Errorf("unexpected editor binding %+v", binding)
+	}
+
+	if changed, err := Ensure(context.TODO(), c, "platform-admins"); err != nil || changed != 0 {
+		t.Errorf("expected nothing to change once ensured, got %d, %v", changed, err)
+	}
+	if changed, err := Ensure(context.TODO(), c, "sre"); err != nil || changed != 1 {
+		t.Errorf("expected the binding to be updated for another group, got %d, %v", changed, err)
+	}
+	if changed, err := Ensure(context.TODO(), c, ""); err != nil || changed != 1 {
+		t.Errorf("expected the binding to be deleted without a group, got %d, %v", changed, err)
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: EditorBindingName}, &rbacv1.ClusterRoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the editor binding to be deleted, got %v", err)
+	}
+}
+
+func TestClusterRoles(t *testing.T) {
+	var tests = []struct {
+		role     string
+		resource string
+		verb     string
+		allowed  bool
+	}{
+		{ViewerRoleName, "grouppermissions", "list", true},
+		{ViewerRoleName, "grouppermissions", "create", false},
+		{EditorRoleName, "grouppermissions", "update", true},
+		{EditorRoleName, "grouppermissions/simulation", "get", true},
+		{EditorRoleName, "elevations", "create", false},
+		{EditorRoleName, "grouppermissions/status", "update", false},
+		{AdminRoleName, "elevations", "create", true},
+		{AdminRoleName, "grouppermissions/status", "update", true},
+	}
+	clusterRoles := map[string]*rbacv1.ClusterRole{}
+	for _, clusterRole := range ClusterRoles() {
+		clusterRoles[clusterRole.Name] = clusterRole
+	}
+	for _, test := range tests {
+		if allowed := allows(clusterRoles[test.role], test.resource, test.verb); allowed != test.allowed {
+			t.Errorf("%s %s %s: Mismatch for allowed. Expected(%t), Found(%t)", test.role, test.verb, test.resource, test.allowed, allowed)
+		}
+	}
+}
+
+// allows returns whether a rule of clusterRole allows verb on resource
+func allows(clusterRole *rbacv1.ClusterRole, resource, verb string) bool {
+	for _, rule := range clusterRole.Rules {
+		if contains(rule.Resources, resource) && contains(rule.Verbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
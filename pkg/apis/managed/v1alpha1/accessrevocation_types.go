@@ -0,0 +1,97 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AccessRevocationSpec defines the desired state of AccessRevocation
+// +k8s:openapi-gen=true
+type AccessRevocationSpec struct {
+	// Kind of the subject whose access is revoked
+	// +kubebuilder:validation:Enum=Group;User
+	SubjectKind string `json:"subjectKind"`
+	// Name of the Group or User whose access is revoked
+	// +kubebuilder:validation:MinLength=1
+	SubjectName string `json:"subjectName"`
+	// Flag to list in the status the bindings to the subject not managed by the operator, which are
+	// left in place
+	// +optional
+	ReportUnmanaged bool `json:"reportUnmanaged,omitempty"`
+}
+
+// AccessRevocationStatus defines the observed state of AccessRevocation
+// +k8s:openapi-gen=true
+type AccessRevocationStatus struct {
+	// List of conditions of the AccessRevocation
+	// +optional
+	Conditions []AccessRevocationCondition `json:"conditions,omitempty"`
+	// List of the bindings to the subject deleted by the AccessRevocation, as Kind namespace/name
+	// +optional
+	RevokedBindings []string `json:"revokedBindings,omitempty"`
+	// List of the bindings to the subject not managed by the operator, as Kind namespace/name. Only
+	// set when reportUnmanaged is.
+	// +optional
+	UnmanagedBindings []string `json:"unmanagedBindings,omitempty"`
+	// List of the GroupPermissions, Elevations and PermissionRequests still granting access to the
+	// subject, as Kind namespace/name. The operator creates their bindings again until they are deleted.
+	// +optional
+	Grants []string `json:"grants,omitempty"`
+	// CompletionTime is when every binding to the subject managed by the operator was deleted, and no
+	// grant created them anymore
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// AccessRevocationCondition is an observation of the progress of an AccessRevocation
+type AccessRevocationCondition struct {
+	// Type of the condition
+	Type AccessRevocationConditionType `json:"type"`
+	// Flag to indicate if condition status is currently active
+	Status bool `json:"status"`
+	// LastTransitionTime is the last time the status of the condition changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	// Message related to the condition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// AccessRevocationConditionType defines the conditions an AccessRevocation CR can have
+// +kubebuilder:validation:Enum=Complete;Blocked;Failed
+type AccessRevocationConditionType string
+
+const (
+	// AccessRevocationComplete const for Complete condition, every managed binding to the subject was deleted
+	AccessRevocationComplete AccessRevocationConditionType = "Complete"
+	// AccessRevocationBlocked const for Blocked condition, grants listed in the status still create bindings
+	// to the subject, the AccessRevocation is retried until they are deleted
+	AccessRevocationBlocked AccessRevocationConditionType = "Blocked"
+	// AccessRevocationFailed const for Failed condition, the last attempt to delete the bindings failed
+	AccessRevocationFailed AccessRevocationConditionType = "Failed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AccessRevocation is the Schema for the accessrevocations API. It deletes once every binding managed
+// by the operator to a Group or User across the cluster, a new AccessRevocation revokes the access again.
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type AccessRevocation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AccessRevocationSpec   `json:"spec,omitempty"`
+	Status AccessRevocationStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AccessRevocationList contains a list of AccessRevocation
+type AccessRevocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AccessRevocation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AccessRevocation{}, &AccessRevocationList{})
+}
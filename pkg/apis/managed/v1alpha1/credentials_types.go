@@ -0,0 +1,12 @@
+package v1alpha1
+
+// SecretKeyReference refers to a key of a Secret holding the credentials of an external integration.
+// The Secret is in the Namespace of the referencing object.
+// +k8s:openapi-gen=true
+type SecretKeyReference struct {
+	// Name of the Secret
+	Name string `json:"name"`
+	// Key of the Secret holding the credentials, defaults to "token"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
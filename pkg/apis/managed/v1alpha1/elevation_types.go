@@ -0,0 +1,76 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElevationSpec defines the desired state of Elevation
+// +k8s:openapi-gen=true
+type ElevationSpec struct {
+	// Name of the GroupPermission, in the namespace of the Elevation, whose ElevationClusterPermissions are
+	// granted to the requester of the Elevation
+	GroupPermissionName string `json:"groupPermissionName"`
+	// Duration of the Elevation in hours from its creation, at most the maxElevationHours of the GroupPermission
+	// +kubebuilder:validation:Minimum=1
+	DurationHours int `json:"durationHours"`
+	// Justification of the Elevation, recorded in the audit trail
+	// +kubebuilder:validation:MinLength=1
+	Justification string `json:"justification"`
+}
+
+// ElevationStatus defines the observed state of Elevation
+// +k8s:openapi-gen=true
+type ElevationStatus struct {
+	// State of the Elevation
+	State ElevationState `json:"state"`
+	// Message describing why the Elevation was denied or failed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// ExpirationTime is when the bindings of the Elevation are removed
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+	// List of the names of the ClusterRoleBindings granting the Elevation
+	// +optional
+	ClusterRoleBindings []string `json:"clusterRoleBindings,omitempty"`
+}
+
+// ElevationState defines various states an Elevation CR can be in
+// +kubebuilder:validation:Enum=Active;Expired;Denied;Failed
+type ElevationState string
+
+const (
+	// ElevationActive const for Active status
+	ElevationActive ElevationState = "Active"
+	// ElevationExpired const for Expired status
+	ElevationExpired ElevationState = "Expired"
+	// ElevationDenied const for Denied status
+	ElevationDenied ElevationState = "Denied"
+	// ElevationFailed const for Failed status
+	ElevationFailed ElevationState = "Failed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Elevation is the Schema for the elevations API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type Elevation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElevationSpec   `json:"spec,omitempty"`
+	Status ElevationStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ElevationList contains a list of Elevation
+type ElevationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Elevation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Elevation{}, &ElevationList{})
+}
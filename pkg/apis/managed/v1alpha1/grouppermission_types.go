@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -10,30 +11,179 @@ import (
 // GroupPermissionSpec defines the desired state of GroupPermission
 // +k8s:openapi-gen=true
 type GroupPermissionSpec struct {
-	// Name of the Group granted permissions by the operator
-	GroupName string `json:"groupName"`
+	// Name of the Group granted permissions by the operator, required unless GroupNameSelector is set
+	// +optional
+	GroupName string `json:"groupName,omitempty"`
+	// Selector of the labels of the OpenShift Groups granted permissions, instead of GroupName. A
+	// GroupPermission is created for each matching Group with the rest of the spec, and deleted once the
+	// Group is deleted or no longer matches.
+	// +optional
+	GroupNameSelector *metav1.LabelSelector `json:"groupNameSelector,omitempty"`
+	// API group of the Group subject of the bindings, for groups of an external authorizer,
+	// defaults to rbac.authorization.k8s.io
+	// +optional
+	SubjectAPIGroup string `json:"subjectAPIGroup,omitempty"`
+	// API group of the ClusterRoles and Roles referenced by the bindings, for the roles of a custom
+	// authorizer, defaults to rbac.authorization.k8s.io. It cannot be changed, like the roleRef of a binding.
+	// +optional
+	RoleRefAPIGroup string `json:"roleRefAPIGroup,omitempty"`
+	// Namespace whose service accounts are all granted the permissions. The GroupName is set to
+	// the system:serviceaccounts:<namespace> group by the admission webhook.
+	// +optional
+	ServiceAccountsNamespace string `json:"serviceAccountsNamespace,omitempty"`
 	// List of permissions applied at Cluster scope
 	// +optional
 	ClusterPermissions []string `json:"clusterPermissions,omitempty"`
 	// List of permissions applied at Namespace scope
 	// +optional
 	Permissions []Permission `json:"permissions,omitempty"`
+	// Read-only Role the operator creates in each Namespace matched by the Permissions, bound to the Group,
+	// so teams granted access also read the logs, pods and events of their Namespaces
+	// +optional
+	ViewRole *ViewRole `json:"viewRole,omitempty"`
+	// Flag to indicate if the Group is created, with no members, when it does not exist
+	// +optional
+	CreateGroupIfMissing bool `json:"createGroupIfMissing,omitempty"`
+	// Schedule restricting the bindings to time windows, the bindings exist at all times if unset
+	// +optional
+	Schedule *Schedule `json:"schedule,omitempty"`
+	// Selector of the labels of the clusters the GroupPermission takes effect on, it grants nothing on
+	// other clusters. The labels of a cluster are the cluster_labels of the operator configuration, and
+	// the platform, region and infrastructureName of its Infrastructure. Every cluster matches if unset.
+	// +optional
+	ClusterConditions *metav1.LabelSelector `json:"clusterConditions,omitempty"`
+	// Maximum duration in hours of the Elevations granting the ElevationClusterPermissions to a single user,
+	// Elevations are denied if unset
+	// +optional
+	MaxElevationHours int `json:"maxElevationHours,omitempty"`
+	// List of the ClusterRoles granted to a member of the Group only while an Elevation of theirs is active.
+	// They are never bound to the Group, the requester of the GroupPermission must hold the bind verb on them
+	// +optional
+	ElevationClusterPermissions []string `json:"elevationClusterPermissions,omitempty"`
+	// Routing of the failures of the GroupPermission to the team owning it
+	// +optional
+	Notify *Notify `json:"notify,omitempty"`
+	// Justification of the permissions, required with the TicketURL to grant sensitive roles, recorded
+	// on the bindings
+	// +optional
+	Justification string `json:"justification,omitempty"`
+	// URL of the ticket approving the permissions, required with the Justification to grant sensitive
+	// roles, recorded on the bindings
+	// +optional
+	TicketURL string `json:"ticketURL,omitempty"`
+	// Description of what the permissions are for, recorded on the bindings, on the events and in the
+	// inventory report
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Tags classifying the permissions for auditors, such as a cost center or a compliance scope, recorded
+	// on the bindings, on the events and in the inventory report. Tags cannot contain commas.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+	// Names of the GroupPermissions of the same namespace that must be Active before this one is
+	// reconciled, such as the one granting the ClusterRoles its permissions build on. It waits with a
+	// DependencyNotReady condition until they are, and fails with a DependencyCycle condition when they
+	// depend on it in turn.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Generation whose applied spec the operator restores, for a quick recovery when a change of the
+	// permissions causes an incident. It must be one of the status.specHistory and is cleared once the
+	// spec is restored, which makes a new generation.
+	// +optional
+	RollbackToGeneration int64 `json:"rollbackToGeneration,omitempty"`
+	// Flag to apply the bindings all or nothing, such as a paired view and edit grant. The RoleBindings
+	// are checked before any is created, then created in order followed by the ClusterRoleBindings, and
+	// every managed binding is rolled back with an AtomicRollback condition when one cannot be applied.
+	// +optional
+	Atomic bool `json:"atomic,omitempty"`
+}
+
+// Notify identifies the team to notify of the failures of a GroupPermission
+type Notify struct {
+	// Team owning the GroupPermission, as an email address, a Slack channel or a team label.
+	// It is recorded on the events and in the logs of the GroupPermission.
+	OwnerRef string `json:"ownerRef"`
+}
+
+// Schedule defines the time windows during which the bindings of a GroupPermission exist
+type Schedule struct {
+	// TimeZone of the windows as an IANA name, e.g. Europe/Paris, defaults to UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// List of the windows during which the bindings exist
+	Windows []ScheduleWindow `json:"windows"`
+}
+
+// ScheduleWindow is a daily time window on some days of the week
+type ScheduleWindow struct {
+	// Days of the week the window starts on, in the day of week notation of cron: 0 to 6 from Sunday,
+	// with lists and ranges such as 1-5 or 1,3,5. Every day if unset
+	// +optional
+	Days string `json:"days,omitempty"`
+	// Start of the window as HH:MM
+	// +kubebuilder:validation:Pattern=^([01][0-9]|2[0-3]):[0-5][0-9]$
+	Start string `json:"start"`
+	// End of the window as HH:MM, the window ends on the next day when End is not after Start
+	// +kubebuilder:validation:Pattern=^([01][0-9]|2[0-3]):[0-5][0-9]$
+	End string `json:"end"`
 }
 
 // Permission deines a Role that is bound to the Group
-// Allowed in specific Namespaces
+// Allowed in specific Namespaces. Exactly one of ClusterRoleName and RoleName is set.
 type Permission struct {
 	// ClusterRoleName to bind to the Group as a RoleBindings in allowed Namespaces
-	ClusterRoleName string `json:"clusterRoleName"`
+	// +optional
+	ClusterRoleName string `json:"clusterRoleName,omitempty"`
+	// RoleName of the Role of each allowed Namespace to bind to the Group as a RoleBinding
+	// +optional
+	RoleName string `json:"roleName,omitempty"`
+	// Rules of the Role named RoleName the operator creates in each allowed Namespace, for clusters
+	// whose policy disallows binding ClusterRoles in Namespaces. The Role is removed with its RoleBinding
+	// once the Namespace is no longer allowed.
+	// +optional
+	Rules []rbacv1.PolicyRule `json:"rules,omitempty"`
 	// NamespacesAllowedRegex representing allowed Namespaces
 	NamespacesAllowedRegex string `json:"namespacesAllowedRegex,omitempty"`
 	// NamespacesDeniedRegex representing denied Namespaces
 	NamespacesDeniedRegex string `json:"namespacesDeniedRegex,omitempty"`
 	// Flag to indicate if "allow" regex is applied first
-	// If 'true' order is Allow then Deny, Else order is Deny then Allow
+	// If 'true' order is Allow then Deny, Else order is Deny then Allow.
+	// Only the order of evaluation changes, a Namespace matched by both regexes is decided by ConflictPolicy.
 	AllowFirst bool `json:"allowFirst"`
+	// ConflictPolicy decides the Namespaces matched by both regexes, defaults to DenyOverAllow
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+	// List of users and service accounts whose Namespaces, as recorded in the openshift.io/requester
+	// annotation, are never allowed
+	// +optional
+	ExcludedNamespaceRequesters []string `json:"excludedNamespaceRequesters,omitempty"`
 }
 
+// ViewRole defines the read-only Role created in each Namespace matched by the Permissions of a
+// GroupPermission
+type ViewRole struct {
+	// RoleName of the Role, defaults to the name of the GroupPermission with a -view suffix
+	// +optional
+	RoleName string `json:"roleName,omitempty"`
+	// Resources the Role reads, as resource.group for the resources outside the core API group, such as
+	// deployments.apps, defaults to pods, pods/log and events
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+}
+
+// ConflictPolicy decides whether a Namespace matched by both the allowed and the denied regex of a
+// Permission is allowed
+// +kubebuilder:validation:Enum=DenyOverAllow;AllowOverDeny
+type ConflictPolicy string
+
+const (
+	// ConflictDenyOverAllow denies the Namespaces matched by both regexes. Only the Namespaces matched
+	// by the allowed regex alone are allowed.
+	ConflictDenyOverAllow ConflictPolicy = "DenyOverAllow"
+	// ConflictAllowOverDeny allows the Namespaces matched by both regexes, the allowed regex carving
+	// exceptions out of the denied one. Every Namespace not denied is allowed, so it requires a denied regex.
+	ConflictAllowOverDeny ConflictPolicy = "AllowOverDeny"
+)
+
 // GroupPermissionStatus defines the observed state of GroupPermission
 // +k8s:openapi-gen=true
 type GroupPermissionStatus struct {
@@ -41,6 +191,310 @@ type GroupPermissionStatus struct {
 	Conditions []Condition `json:"conditions,omitempty"`
 	// State that this condition represents
 	State string `json:"state"`
+	// List of verified effective access of the Group for each ClusterPermission
+	// +optional
+	EffectiveAccess []EffectiveAccess `json:"effectiveAccess,omitempty"`
+	// List of RoleBindings managed in each allowed Namespace.
+	// Replaced by NamespaceSummary when too many Namespaces are allowed.
+	// +optional
+	Namespaces []NamespaceStatus `json:"namespaces,omitempty"`
+	// Summary of the RoleBindings managed in allowed Namespaces, set instead of Namespaces
+	// when the number of Namespaces exceeds the operator threshold
+	// +optional
+	NamespaceSummary *NamespaceSummary `json:"namespaceSummary,omitempty"`
+	// List of the Namespaces matched by the regexes of each Permission
+	// +optional
+	MatchedNamespaces []MatchedNamespaces `json:"matchedNamespaces,omitempty"`
+	// Number of distinct Namespaces matched by the Permissions, shown by oc get
+	// +optional
+	MatchedNamespaceCount int `json:"matchedNamespaceCount,omitempty"`
+	// Number of distinct subjects bound by the managed RoleBindings, shown by oc get
+	// +optional
+	BoundSubjectCount int `json:"boundSubjectCount,omitempty"`
+	// Phase of the GroupPermission derived from its conditions, for health checks of GitOps tools
+	// +optional
+	Phase GroupPermissionPhase `json:"phase,omitempty"`
+	// State of the schedule of the bindings, set when the spec has a schedule
+	// +optional
+	Schedule *ScheduleStatus `json:"schedule,omitempty"`
+	// Changes to the bindings planned for the latest generation of the spec
+	// +optional
+	Plan *PlanStatus `json:"plan,omitempty"`
+	// Generation of the spec whose bindings were all verified to exist, with their role and subjects,
+	// for the wait conditions of GitOps tools
+	// +optional
+	AppliedGeneration int64 `json:"appliedGeneration,omitempty"`
+	// Generations of the latest applied specs, oldest first, recorded in the history ConfigMap of the
+	// GroupPermission so spec.rollbackToGeneration can restore them
+	// +optional
+	SpecHistory []int64 `json:"specHistory,omitempty"`
+	// Renaming of the bindings created under a previous naming scheme, set once one is found
+	// +optional
+	BindingMigration *BindingMigrationStatus `json:"bindingMigration,omitempty"`
+	// Flag to indicate if the labels of the cluster match the ClusterConditions, the bindings exist only
+	// when they do. Set when the spec has ClusterConditions.
+	// +optional
+	ClusterConditionsMatched *bool `json:"clusterConditionsMatched,omitempty"`
+	// Managed RBAC objects removed by the deletion of the GroupPermission, set while they are held for
+	// the deletion grace period of the operator
+	// +optional
+	DeletionImpact *DeletionImpact `json:"deletionImpact,omitempty"`
+	// Last use by the Group of each role it is bound to, read from the usage endpoint of the operator
+	// to find the grants that can be removed. Set when a usage endpoint is configured.
+	// +optional
+	RoleUsage []RoleUsage `json:"roleUsage,omitempty"`
+	// Names of the Groups matched by the GroupNameSelector, each granted permissions by a GroupPermission
+	// created for it. Set when the spec has a GroupNameSelector.
+	// +optional
+	SelectedGroups []string `json:"selectedGroups,omitempty"`
+	// Explanation of why each Permission matches the Namespace named by the explain-namespace annotation
+	// or not, set while the GroupPermission has the annotation
+	// +optional
+	NamespaceExplanation *NamespaceExplanation `json:"namespaceExplanation,omitempty"`
+	// Apply state of each ClusterRole of the ClusterPermissions, in the order of the spec, so a single
+	// missing or failing ClusterRole does not hide the health of the others
+	// +optional
+	ClusterPermissions []ClusterPermissionStatus `json:"clusterPermissions,omitempty"`
+	// Checkpoint of the rollout of the RoleBindings in progress, so the operator instance taking over
+	// resumes it where the previous one stopped. Set while a large rollout is applied.
+	// +optional
+	Rollout *RolloutCheckpoint `json:"rollout,omitempty"`
+	// Convergence of the rules of the Roles created from the Permissions with rules, reported by the
+	// role content controller. Set when the spec has a Permission with rules.
+	// +optional
+	RoleContent *RoleContentStatus `json:"roleContent,omitempty"`
+	// Generation of the spec whose reconcile panicked. The GroupPermission is quarantined, skipped by the
+	// operator, until its spec changes.
+	// +optional
+	QuarantinedGeneration int64 `json:"quarantinedGeneration,omitempty"`
+}
+
+// RoleContentStatus reports whether the Roles created from the Permissions with rules of a
+// GroupPermission have the rules of its spec
+type RoleContentStatus struct {
+	// Generation of the spec whose rules the Roles were last converged to
+	ObservedGeneration int64 `json:"observedGeneration"`
+	// Number of Roles created for the GroupPermission
+	Roles int `json:"roles"`
+	// Roles, as namespace/name, whose rules were changed outside the operator and restored by the
+	// last convergence
+	// +optional
+	DriftedRoles []string `json:"driftedRoles,omitempty"`
+	// LastDriftTime is when the rules of a Role were last found changed outside the operator
+	// +optional
+	LastDriftTime *metav1.Time `json:"lastDriftTime,omitempty"`
+}
+
+// RolloutCheckpoint records how far the rollout of the RoleBindings of a GroupPermission went. The
+// RoleBindings of the Permissions before Permission, and those of Permission in the Namespaces up to
+// Namespace in name order, were applied for the desired state of Hash.
+type RolloutCheckpoint struct {
+	// Hash of the desired state rolled out, recorded in the last-applied-hash annotation once applied
+	Hash string `json:"hash"`
+	// Index of the Permission of the last RoleBinding applied
+	Permission int `json:"permission"`
+	// Namespace of the last RoleBinding applied
+	Namespace string `json:"namespace"`
+	// Number of RoleBindings applied
+	Applied int `json:"applied"`
+}
+
+// ClusterPermissionState is the apply state of the ClusterRoleBinding of a ClusterPermission
+// +kubebuilder:validation:Enum=Pending;Applied;RoleMissing;Forbidden;Conflict;Failed
+type ClusterPermissionState string
+
+const (
+	// ClusterPermissionPending the ClusterRoleBinding is not created yet
+	ClusterPermissionPending ClusterPermissionState = "Pending"
+	// ClusterPermissionApplied the ClusterRoleBinding exists
+	ClusterPermissionApplied ClusterPermissionState = "Applied"
+	// ClusterPermissionRoleMissing the ClusterRole does not exist, the ClusterRoleBinding is created
+	// once it does
+	ClusterPermissionRoleMissing ClusterPermissionState = "RoleMissing"
+	// ClusterPermissionForbidden the binding is not allowed, to the requester, to the operator, by the
+	// policy of the cluster admins or by the delegation of the namespace
+	ClusterPermissionForbidden ClusterPermissionState = "Forbidden"
+	// ClusterPermissionConflict a ClusterRoleBinding of the same name exists and is not managed by the
+	// GroupPermission
+	ClusterPermissionConflict ClusterPermissionState = "Conflict"
+	// ClusterPermissionFailed the ClusterRoleBinding failed to be created
+	ClusterPermissionFailed ClusterPermissionState = "Failed"
+)
+
+// ClusterPermissionStatus records the apply state of a ClusterRole of the ClusterPermissions
+type ClusterPermissionStatus struct {
+	// ClusterRoleName of the ClusterPermission
+	ClusterRoleName string `json:"clusterRoleName"`
+	// State of its ClusterRoleBinding
+	State ClusterPermissionState `json:"state"`
+	// Message of the last condition of the ClusterRole, when it is not Applied
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// NamespaceExplanation explains why the Permissions of a GroupPermission match a Namespace or not, to
+// debug the precedence of their regexes
+type NamespaceExplanation struct {
+	// Namespace explained
+	Namespace string `json:"namespace"`
+	// Reason no Permission is evaluated, such as the Namespace does not exist or is protected
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Explanation of each Permission, in the order of the spec
+	// +optional
+	Permissions []PermissionExplanation `json:"permissions,omitempty"`
+}
+
+// PermissionExplanation explains why a Permission matches a Namespace or not
+type PermissionExplanation struct {
+	// ClusterRoleName of the Permission
+	// +optional
+	ClusterRoleName string `json:"clusterRoleName,omitempty"`
+	// RoleName of the Permission
+	// +optional
+	RoleName string `json:"roleName,omitempty"`
+	// Flag to indicate if the Permission applies in the Namespace
+	Matched bool `json:"matched"`
+	// Steps of the evaluation of the Permission, in order, the last one decides
+	// +optional
+	Steps []string `json:"steps,omitempty"`
+}
+
+// DeletionImpact lists what the deletion of a GroupPermission removes once its grace period ends.
+// Setting the retain-bindings annotation before then releases the GroupPermission without removing them.
+type DeletionImpact struct {
+	// List of the managed RBAC objects removed, as Kind namespace/name
+	// +optional
+	Bindings []string `json:"bindings,omitempty"`
+	// List of the Namespaces the Group loses access in
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// CleanupTime is when the managed RBAC objects are removed
+	CleanupTime metav1.Time `json:"cleanupTime"`
+}
+
+// BindingMigrationStatus records the renaming of the bindings of a GroupPermission to the current
+// naming scheme. A binding is created under its new name before the binding of the old name is deleted.
+type BindingMigrationStatus struct {
+	// Number of bindings renamed
+	Renamed int `json:"renamed"`
+	// Number of bindings with an old name left to rename
+	Pending int `json:"pending"`
+	// Error of the last binding that failed to be renamed
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// PlanStatus records the changes to the bindings planned when the spec of a GroupPermission changed
+type PlanStatus struct {
+	// Generation of the spec the plan was made for
+	Generation int64 `json:"generation"`
+	// Hash of the planned changes, also found in the Plan and Applied events
+	Hash string `json:"hash"`
+	// Number of bindings to create
+	Create int `json:"create"`
+	// Number of managed bindings the spec no longer resolves to
+	Delete int `json:"delete"`
+	// Number of bindings with another role or other subjects than the spec resolves to
+	Update int `json:"update"`
+	// Flag to indicate if every binding the spec resolves to was created
+	Applied bool `json:"applied"`
+}
+
+// ScheduleStatus records whether the bindings of a scheduled GroupPermission exist
+type ScheduleStatus struct {
+	// Flag to indicate if the current time is in a window of the schedule, the bindings exist only when it is
+	Active bool `json:"active"`
+	// NextTransitionTime is when the bindings are next created or removed
+	// +optional
+	NextTransitionTime *metav1.Time `json:"nextTransitionTime,omitempty"`
+}
+
+// GroupPermissionPhase summarizes whether the permissions of a GroupPermission are granted
+// +kubebuilder:validation:Enum=Pending;Active;Failed
+type GroupPermissionPhase string
+
+const (
+	// GroupPermissionPhasePending the bindings of the latest spec are not all applied yet
+	GroupPermissionPhasePending GroupPermissionPhase = "Pending"
+	// GroupPermissionPhaseActive every binding of the latest spec is applied
+	GroupPermissionPhaseActive GroupPermissionPhase = "Active"
+	// GroupPermissionPhaseFailed a binding could not be applied, or the reconciles keep failing
+	GroupPermissionPhaseFailed GroupPermissionPhase = "Failed"
+)
+
+// MatchedNamespaces records the Namespaces matched by the allow and deny regexes of a Permission
+type MatchedNamespaces struct {
+	// ClusterRoleName of the Permission
+	ClusterRoleName string `json:"clusterRoleName"`
+	// Number of Namespaces matched
+	Count int `json:"count"`
+	// Capped sample of the names of the matched Namespaces
+	// +optional
+	Sample []string `json:"sample,omitempty"`
+}
+
+// NamespaceSummary summarizes the state of RoleBindings managed across many Namespaces
+type NamespaceSummary struct {
+	// Total number of RoleBindings managed
+	Total int `json:"total"`
+	// Number of RoleBindings in Created state
+	Created int `json:"created"`
+	// Number of RoleBindings in Failed state
+	Failed int `json:"failed"`
+	// Capped sample of the failed RoleBindings
+	// +optional
+	FailedSample []NamespaceStatus `json:"failedSample,omitempty"`
+	// Names of the ConfigMaps holding, in order, the chunks of the full list of RoleBindings managed in
+	// allowed Namespaces, in the Namespace of the GroupPermission
+	// +optional
+	InventoryConfigMaps []string `json:"inventoryConfigMaps,omitempty"`
+}
+
+// NamespaceStatus records the state of a RoleBinding managed in a single Namespace
+type NamespaceStatus struct {
+	// Namespace the RoleBinding is managed in
+	Namespace string `json:"namespace"`
+	// ClusterRoleName bound by the RoleBinding
+	ClusterRoleName string `json:"clusterRoleName"`
+	// BindingName is the name of the RoleBinding
+	BindingName string `json:"bindingName"`
+	// State of the RoleBinding
+	State GroupPermissionState `json:"state"`
+	// LastError encountered managing the RoleBinding
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// EffectiveAccess records whether the permissions of a bound ClusterRole actually resolve for the Group
+type EffectiveAccess struct {
+	// ClusterRoleName that was verified
+	ClusterRoleName string `json:"clusterRoleName"`
+	// Namespace in which namespaced resources were verified
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Flag to indicate if every rule of the ClusterRole is granted to the Group
+	Effective bool `json:"effective"`
+	// Message describing why the access is not effective
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastCheckTime is the last time the access was verified
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+}
+
+// RoleUsage is the last use by the Group of a role it is bound to
+type RoleUsage struct {
+	// Kind of the role, ClusterRole or Role
+	// +kubebuilder:validation:Enum=ClusterRole;Role
+	Kind string `json:"kind"`
+	// Name of the role
+	Name string `json:"name"`
+	// LastUsedTime is the last time the Group exercised the role, unset when the usage source has not
+	// seen it used
+	// +optional
+	LastUsedTime *metav1.Time `json:"lastUsedTime,omitempty"`
+	// LastCheckTime is the last time the usage was read
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
 }
 
 // Condition defines a single condition of running the operator against an instance of the GroupPermission CR
@@ -56,9 +510,63 @@ type Condition struct {
 	Status bool `json:"status"`
 	// State that this condition represents
 	State GroupPermissionState `json:"state"`
+	// Reason is a machine-readable code of why the condition was recorded
+	// +optional
+	Reason ConditionReason `json:"reason,omitempty"`
 }
 
+// ConditionReason is a stable code of why a Condition was recorded, for tooling and alerts
+// +kubebuilder:validation:Enum=ClusterRoleMissing;BindingCreated;BindingFailed;BindingConflict;OperatorForbidden;EscalationDenied;FailureBudgetExhausted;InvalidPermission;PolicyDenied;RoleDeleted;PartiallyApplied;ConvergenceDeadlineExceeded;NotDelegable;DependencyNotReady;DependencyCycle;UnmanagedAccess;NamespaceTerminating;ReconcilePanicked;GrantDenied;ForeignOperatorConflict;AtomicRollback
+type ConditionReason string
+
+const (
+	// ReasonClusterRoleMissing the granted ClusterRole does not exist
+	ReasonClusterRoleMissing ConditionReason = "ClusterRoleMissing"
+	// ReasonBindingCreated the binding granting the ClusterRole was created
+	ReasonBindingCreated ConditionReason = "BindingCreated"
+	// ReasonBindingFailed the binding granting the ClusterRole could not be created
+	ReasonBindingFailed ConditionReason = "BindingFailed"
+	// ReasonBindingConflict the binding was created or changed concurrently
+	ReasonBindingConflict ConditionReason = "BindingConflict"
+	// ReasonOperatorForbidden the operator is not allowed to create the binding
+	ReasonOperatorForbidden ConditionReason = "OperatorForbidden"
+	// ReasonEscalationDenied the requester is not allowed to grant the ClusterRole
+	ReasonEscalationDenied ConditionReason = "EscalationDenied"
+	// ReasonFailureBudgetExhausted the reconciles of the GroupPermission keep failing
+	ReasonFailureBudgetExhausted ConditionReason = "FailureBudgetExhausted"
+	// ReasonInvalidPermission a Permission does not set exactly one of ClusterRoleName and RoleName
+	ReasonInvalidPermission ConditionReason = "InvalidPermission"
+	// ReasonPolicyDenied the binding is denied by the policy configured by the cluster admins
+	ReasonPolicyDenied ConditionReason = "PolicyDenied"
+	// ReasonRoleDeleted the granted ClusterRole was deleted and the bindings to it were removed
+	ReasonRoleDeleted ConditionReason = "RoleDeleted"
+	// ReasonPartiallyApplied some bindings were applied and others failed
+	ReasonPartiallyApplied ConditionReason = "PartiallyApplied"
+	// ReasonConvergenceDeadlineExceeded the GroupPermission did not become Active within the stuck
+	// deadline after its spec changed
+	ReasonConvergenceDeadlineExceeded ConditionReason = "ConvergenceDeadlineExceeded"
+	// ReasonNotDelegable the granted role is not delegable to the delegated Namespace of the GroupPermission
+	ReasonNotDelegable ConditionReason = "NotDelegable"
+	// ReasonDependencyNotReady a GroupPermission of the dependsOn of the GroupPermission is not Active
+	ReasonDependencyNotReady ConditionReason = "DependencyNotReady"
+	// ReasonDependencyCycle the dependsOn of the GroupPermission leads back to it
+	ReasonDependencyCycle ConditionReason = "DependencyCycle"
+	// ReasonUnmanagedAccess the Group has access beyond the spec through bindings not managed by the operator
+	ReasonUnmanagedAccess ConditionReason = "UnmanagedAccess"
+	// ReasonNamespaceTerminating the RoleBindings of allowed Namespaces being deleted were skipped
+	ReasonNamespaceTerminating ConditionReason = "NamespaceTerminating"
+	// ReasonReconcilePanicked the reconcile of the GroupPermission panicked, it is quarantined
+	ReasonReconcilePanicked ConditionReason = "ReconcilePanicked"
+	// ReasonGrantDenied the binding is denied by the grant decision service configured by the cluster admins
+	ReasonGrantDenied ConditionReason = "GrantDenied"
+	// ReasonForeignOperatorConflict bindings are left to other RBAC operators granting the same access
+	ReasonForeignOperatorConflict ConditionReason = "ForeignOperatorConflict"
+	// ReasonAtomicRollback the bindings of an atomic GroupPermission are rolled back, one of them cannot be applied
+	ReasonAtomicRollback ConditionReason = "AtomicRollback"
+)
+
 // GroupPermissionState defines various states a GroupPermission CR can be in
+// +kubebuilder:validation:Enum=Created;Failed;EscalationDenied;Degraded;PartiallyApplied;Stuck;Waiting;Quarantined
 type GroupPermissionState string
 
 const (
@@ -66,6 +574,18 @@ const (
 	GroupPermissionCreated GroupPermissionState = "Created"
 	// GroupPermissionFailed const for Failed status
 	GroupPermissionFailed GroupPermissionState = "Failed"
+	// GroupPermissionEscalationDenied const for EscalationDenied status
+	GroupPermissionEscalationDenied GroupPermissionState = "EscalationDenied"
+	// GroupPermissionDegraded const for Degraded status
+	GroupPermissionDegraded GroupPermissionState = "Degraded"
+	// GroupPermissionPartiallyApplied const for PartiallyApplied status
+	GroupPermissionPartiallyApplied GroupPermissionState = "PartiallyApplied"
+	// GroupPermissionStuck const for Stuck status
+	GroupPermissionStuck GroupPermissionState = "Stuck"
+	// GroupPermissionWaiting const for Waiting status
+	GroupPermissionWaiting GroupPermissionState = "Waiting"
+	// GroupPermissionQuarantined const for Quarantined status
+	GroupPermissionQuarantined GroupPermissionState = "Quarantined"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -73,6 +593,10 @@ const (
 // GroupPermission is the Schema for the grouppermissions API
 // +k8s:openapi-gen=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Phase of the GroupPermission"
+// +kubebuilder:printcolumn:name="Namespaces",type="integer",JSONPath=".status.matchedNamespaceCount",description="Number of Namespaces matched by the Permissions"
+// +kubebuilder:printcolumn:name="Subjects",type="integer",JSONPath=".status.boundSubjectCount",description="Number of subjects bound by the managed RoleBindings"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type GroupPermission struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,86 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GroupSyncSpec defines the desired state of GroupSync
+// +k8s:openapi-gen=true
+type GroupSyncSpec struct {
+	// URL of the SCIM v2 endpoint the Groups are read from, e.g. https://idp.example.com/scim/v2
+	URL string `json:"url"`
+	// Reference to the Secret key holding the bearer token of the endpoint
+	CredentialsSecretRef *SecretKeyReference `json:"credentialsSecretRef"`
+	// List of the names of the Groups to sync. OpenShift Groups of the same name not created by the operator
+	// are never synced
+	// +kubebuilder:validation:MinItems=1
+	Groups []string `json:"groups"`
+	// Interval between syncs in seconds, defaults to 3600
+	// +optional
+	IntervalSeconds int `json:"intervalSeconds,omitempty"`
+}
+
+// GroupSyncStatus defines the observed state of GroupSync
+// +k8s:openapi-gen=true
+type GroupSyncStatus struct {
+	// State of the last sync
+	State GroupSyncState `json:"state"`
+	// Message describing why the last sync failed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastSyncTime is the last time a sync was attempted
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// List of the Groups synced by the last sync
+	// +optional
+	SyncedGroups []SyncedGroup `json:"syncedGroups,omitempty"`
+	// List of the Groups not synced because an OpenShift Group of the same name exists and is not managed by
+	// the operator
+	// +optional
+	ConflictingGroups []string `json:"conflictingGroups,omitempty"`
+}
+
+// SyncedGroup records a Group maintained by a GroupSync
+type SyncedGroup struct {
+	// Name of the Group
+	Name string `json:"name"`
+	// Number of members of the Group
+	Members int `json:"members"`
+}
+
+// GroupSyncState defines various states a GroupSync CR can be in
+// +kubebuilder:validation:Enum=Synced;Failed
+type GroupSyncState string
+
+const (
+	// GroupSyncSynced const for Synced status
+	GroupSyncSynced GroupSyncState = "Synced"
+	// GroupSyncFailed const for Failed status
+	GroupSyncFailed GroupSyncState = "Failed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GroupSync is the Schema for the groupsyncs API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type GroupSync struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GroupSyncSpec   `json:"spec,omitempty"`
+	Status GroupSyncStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GroupSyncList contains a list of GroupSync
+type GroupSyncList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GroupSync `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GroupSync{}, &GroupSyncList{})
+}
@@ -0,0 +1,66 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PermissionRequestSpec defines the desired state of PermissionRequest
+// +k8s:openapi-gen=true
+type PermissionRequestSpec struct {
+	// Name of the Group granted the ClusterRole in the namespace of the PermissionRequest
+	GroupName string `json:"groupName"`
+	// Name of the ClusterRole granted, must be allowed by the operator policy
+	ClusterRoleName string `json:"clusterRoleName"`
+}
+
+// PermissionRequestStatus defines the observed state of PermissionRequest
+// +k8s:openapi-gen=true
+type PermissionRequestStatus struct {
+	// State of the request
+	State PermissionRequestState `json:"state"`
+	// Message describing why the request was denied or failed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Name of the RoleBinding granting the ClusterRole to the Group
+	// +optional
+	RoleBindingName string `json:"roleBindingName,omitempty"`
+}
+
+// PermissionRequestState defines various states a PermissionRequest CR can be in
+// +kubebuilder:validation:Enum=Granted;Denied;Failed
+type PermissionRequestState string
+
+const (
+	// PermissionRequestGranted const for Granted status
+	PermissionRequestGranted PermissionRequestState = "Granted"
+	// PermissionRequestDenied const for Denied status
+	PermissionRequestDenied PermissionRequestState = "Denied"
+	// PermissionRequestFailed const for Failed status
+	PermissionRequestFailed PermissionRequestState = "Failed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PermissionRequest is the Schema for the permissionrequests API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type PermissionRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PermissionRequestSpec   `json:"spec,omitempty"`
+	Status PermissionRequestStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PermissionRequestList contains a list of PermissionRequest
+type PermissionRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PermissionRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PermissionRequest{}, &PermissionRequestList{})
+}
@@ -11,17 +11,30 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermission":       schema_pkg_apis_managed_v1alpha1_GroupPermission(ref),
-		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionSpec":   schema_pkg_apis_managed_v1alpha1_GroupPermissionSpec(ref),
-		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionStatus": schema_pkg_apis_managed_v1alpha1_GroupPermissionStatus(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.AccessRevocation":        schema_pkg_apis_managed_v1alpha1_AccessRevocation(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.AccessRevocationSpec":    schema_pkg_apis_managed_v1alpha1_AccessRevocationSpec(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.AccessRevocationStatus":  schema_pkg_apis_managed_v1alpha1_AccessRevocationStatus(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Elevation":               schema_pkg_apis_managed_v1alpha1_Elevation(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ElevationSpec":           schema_pkg_apis_managed_v1alpha1_ElevationSpec(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ElevationStatus":         schema_pkg_apis_managed_v1alpha1_ElevationStatus(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermission":         schema_pkg_apis_managed_v1alpha1_GroupPermission(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionSpec":     schema_pkg_apis_managed_v1alpha1_GroupPermissionSpec(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionStatus":   schema_pkg_apis_managed_v1alpha1_GroupPermissionStatus(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupSync":               schema_pkg_apis_managed_v1alpha1_GroupSync(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupSyncSpec":           schema_pkg_apis_managed_v1alpha1_GroupSyncSpec(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupSyncStatus":         schema_pkg_apis_managed_v1alpha1_GroupSyncStatus(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.PermissionRequest":       schema_pkg_apis_managed_v1alpha1_PermissionRequest(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.PermissionRequestSpec":   schema_pkg_apis_managed_v1alpha1_PermissionRequestSpec(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.PermissionRequestStatus": schema_pkg_apis_managed_v1alpha1_PermissionRequestStatus(ref),
+		"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.SecretKeyReference":      schema_pkg_apis_managed_v1alpha1_SecretKeyReference(ref),
 	}
 }
 
-func schema_pkg_apis_managed_v1alpha1_GroupPermission(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_managed_v1alpha1_AccessRevocation(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "GroupPermission is the Schema for the grouppermissions API",
+				Description: "AccessRevocation is the Schema for the accessrevocations API. It deletes once every binding managed by the operator to a Group or User across the cluster, a new AccessRevocation revokes the access again.",
 				Properties: map[string]spec.Schema{
 					"kind": {
 						SchemaProps: spec.SchemaProps{
@@ -44,38 +57,79 @@ func schema_pkg_apis_managed_v1alpha1_GroupPermission(ref common.ReferenceCallba
 					},
 					"spec": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionSpec"),
+							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.AccessRevocationSpec"),
 						},
 					},
 					"status": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionStatus"),
+							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.AccessRevocationStatus"),
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionSpec", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.AccessRevocationSpec", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.AccessRevocationStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
 	}
 }
 
-func schema_pkg_apis_managed_v1alpha1_GroupPermissionSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_managed_v1alpha1_AccessRevocationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "GroupPermissionSpec defines the desired state of GroupPermission",
+				Description: "AccessRevocationSpec defines the desired state of AccessRevocation",
 				Properties: map[string]spec.Schema{
-					"groupName": {
+					"subjectKind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name of the Group granted permissions by the operator",
+							Description: "Kind of the subject whose access is revoked",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"clusterPermissions": {
+					"subjectName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "List of permissions applied at Cluster scope",
+							Description: "Name of the Group or User whose access is revoked",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reportUnmanaged": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Flag to list in the status the bindings to the subject not managed by the operator, which are left in place",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"subjectKind", "subjectName"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_AccessRevocationStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AccessRevocationStatus defines the observed state of AccessRevocation",
+				Properties: map[string]spec.Schema{
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of conditions of the AccessRevocation",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.AccessRevocationCondition"),
+									},
+								},
+							},
+						},
+					},
+					"revokedBindings": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of the bindings to the subject deleted by the AccessRevocation, as Kind namespace/name",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -87,59 +141,910 @@ func schema_pkg_apis_managed_v1alpha1_GroupPermissionSpec(ref common.ReferenceCa
 							},
 						},
 					},
-					"permissions": {
+					"unmanagedBindings": {
 						SchemaProps: spec.SchemaProps{
-							Description: "List of permissions applied at Namespace scope",
+							Description: "List of the bindings to the subject not managed by the operator, as Kind namespace/name. Only set when reportUnmanaged is.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Permission"),
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"grants": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of the GroupPermissions, Elevations and PermissionRequests still granting access to the subject, as Kind namespace/name. The operator creates their bindings again until they are deleted.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
+					"completionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CompletionTime is when every binding to the subject managed by the operator was deleted",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
 				},
-				Required: []string{"groupName"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Permission"},
+			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.AccessRevocationCondition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
-func schema_pkg_apis_managed_v1alpha1_GroupPermissionStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_managed_v1alpha1_Elevation(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "GroupPermissionStatus defines the observed state of GroupPermission",
+				Description: "Elevation is the Schema for the elevations API",
 				Properties: map[string]spec.Schema{
-					"conditions": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "List of conditions for the CR",
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ElevationSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ElevationStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ElevationSpec", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ElevationStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_ElevationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ElevationSpec defines the desired state of Elevation",
+				Properties: map[string]spec.Schema{
+					"groupPermissionName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the GroupPermission, in the namespace of the Elevation, whose ElevationClusterPermissions are granted to the requester of the Elevation",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"durationHours": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration of the Elevation in hours from its creation, at most the maxElevationHours of the GroupPermission",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"justification": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Justification of the Elevation, recorded in the audit trail",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"groupPermissionName", "durationHours", "justification"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_ElevationStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ElevationStatus defines the observed state of Elevation",
+				Properties: map[string]spec.Schema{
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State of the Elevation",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message describing why the Elevation was denied or failed",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"expirationTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpirationTime is when the bindings of the Elevation are removed",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"clusterRoleBindings": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of the names of the ClusterRoleBindings granting the Elevation",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Condition"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"state": {
+				},
+				Required: []string{"state"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_GroupPermission(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GroupPermission is the Schema for the grouppermissions API",
+				Properties: map[string]spec.Schema{
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "State that this condition represents",
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionStatus"),
+						},
+					},
 				},
-				Required: []string{"state"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Condition"},
+			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionSpec", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupPermissionStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_GroupPermissionSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GroupPermissionSpec defines the desired state of GroupPermission",
+				Properties: map[string]spec.Schema{
+					"groupName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the Group granted permissions by the operator, required unless GroupNameSelector is set",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"groupNameSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selector of the labels of the OpenShift Groups granted permissions, instead of GroupName. A GroupPermission is created for each matching Group with the rest of the spec, and deleted once the Group is deleted or no longer matches.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+						},
+					},
+					"subjectAPIGroup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "API group of the Group subject of the bindings, for groups of an external authorizer, defaults to rbac.authorization.k8s.io",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"roleRefAPIGroup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "API group of the ClusterRoles and Roles referenced by the bindings, for the roles of a custom authorizer, defaults to rbac.authorization.k8s.io. It cannot be changed, like the roleRef of a binding.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"serviceAccountsNamespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace whose service accounts are all granted the permissions. The GroupName is set to the system:serviceaccounts:<namespace> group by the admission webhook.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"clusterPermissions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of permissions applied at Cluster scope",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"permissions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of permissions applied at Namespace scope",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Permission"),
+									},
+								},
+							},
+						},
+					},
+					"viewRole": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Read-only Role the operator creates in each Namespace matched by the Permissions, bound to the Group, so teams granted access also read the logs, pods and events of their Namespaces",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ViewRole"),
+						},
+					},
+					"createGroupIfMissing": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Flag to indicate if the Group is created, with no members, when it does not exist",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"schedule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Schedule restricting the bindings to time windows, the bindings exist at all times if unset",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Schedule"),
+						},
+					},
+					"clusterConditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selector of the labels of the clusters the GroupPermission takes effect on, it grants nothing on other clusters. The labels of a cluster are the cluster_labels of the operator configuration, and the platform, region and infrastructureName of its Infrastructure. Every cluster matches if unset.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+						},
+					},
+					"maxElevationHours": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Maximum duration in hours of the Elevations granting the ElevationClusterPermissions to a single user, Elevations are denied if unset",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"elevationClusterPermissions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of the ClusterRoles granted to a member of the Group only while an Elevation of theirs is active. They are never bound to the Group, the requester of the GroupPermission must hold the bind verb on them",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"notify": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Routing of the failures of the GroupPermission to the team owning it",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Notify"),
+						},
+					},
+					"justification": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Justification of the permissions, required with the TicketURL to grant sensitive roles, recorded on the bindings",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ticketURL": {
+						SchemaProps: spec.SchemaProps{
+							Description: "URL of the ticket approving the permissions, required with the Justification to grant sensitive roles, recorded on the bindings",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"description": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Description of what the permissions are for, recorded on the bindings, on the events and in the inventory report",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tags": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tags classifying the permissions for auditors, such as a cost center or a compliance scope, recorded on the bindings, on the events and in the inventory report. Tags cannot contain commas.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"dependsOn": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Names of the GroupPermissions of the same namespace that must be Active before this one is reconciled, such as the one granting the ClusterRoles its permissions build on. It waits with a DependencyNotReady condition until they are, and fails with a DependencyCycle condition when they depend on it in turn.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"rollbackToGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Generation whose applied spec the operator restores, for a quick recovery when a change of the permissions causes an incident. It must be one of the status.specHistory and is cleared once the spec is restored, which makes a new generation.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"atomic": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Flag to apply the bindings all or nothing, such as a paired view and edit grant. The RoleBindings are checked before any is created, then created in order followed by the ClusterRoleBindings, and every managed binding is rolled back with an AtomicRollback condition when one cannot be applied.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Notify", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Permission", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Schedule", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ViewRole", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_GroupPermissionStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GroupPermissionStatus defines the observed state of GroupPermission",
+				Properties: map[string]spec.Schema{
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of conditions for the CR",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Condition"),
+									},
+								},
+							},
+						},
+					},
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State that this condition represents",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"effectiveAccess": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of verified effective access of the Group for each ClusterPermission",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.EffectiveAccess"),
+									},
+								},
+							},
+						},
+					},
+					"namespaces": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of RoleBindings managed in each allowed Namespace. Replaced by NamespaceSummary when too many Namespaces are allowed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.NamespaceStatus"),
+									},
+								},
+							},
+						},
+					},
+					"namespaceSummary": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Summary of the RoleBindings managed in allowed Namespaces, set instead of Namespaces when the number of Namespaces exceeds the operator threshold",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.NamespaceSummary"),
+						},
+					},
+					"matchedNamespaces": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of the Namespaces matched by the regexes of each Permission",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.MatchedNamespaces"),
+									},
+								},
+							},
+						},
+					},
+					"matchedNamespaceCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of distinct Namespaces matched by the Permissions, shown by oc get",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"boundSubjectCount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of distinct subjects bound by the managed RoleBindings, shown by oc get",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Phase of the GroupPermission derived from its conditions, for health checks of GitOps tools",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"schedule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State of the schedule of the bindings, set when the spec has a schedule",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ScheduleStatus"),
+						},
+					},
+					"plan": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Changes to the bindings planned for the latest generation of the spec",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.PlanStatus"),
+						},
+					},
+					"appliedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Generation of the spec whose bindings were all verified to exist, with their role and subjects, for the wait conditions of GitOps tools",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"specHistory": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Generations of the latest applied specs, oldest first, recorded in the history ConfigMap of the GroupPermission so spec.rollbackToGeneration can restore them",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int64",
+									},
+								},
+							},
+						},
+					},
+					"bindingMigration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Renaming of the bindings created under a previous naming scheme, set once one is found",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.BindingMigrationStatus"),
+						},
+					},
+					"clusterConditionsMatched": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Flag to indicate if the labels of the cluster match the ClusterConditions, the bindings exist only when they do. Set when the spec has ClusterConditions.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"deletionImpact": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Managed RBAC objects removed by the deletion of the GroupPermission, set while they are held for the deletion grace period of the operator",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.DeletionImpact"),
+						},
+					},
+					"roleUsage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Last use by the Group of each role it is bound to, read from the usage endpoint of the operator to find the grants that can be removed. Set when a usage endpoint is configured.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.RoleUsage"),
+									},
+								},
+							},
+						},
+					},
+					"selectedGroups": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Names of the Groups matched by the GroupNameSelector, each granted permissions by a GroupPermission created for it. Set when the spec has a GroupNameSelector.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"namespaceExplanation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Explanation of why each Permission matches the Namespace named by the explain-namespace annotation or not, set while the GroupPermission has the annotation",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.NamespaceExplanation"),
+						},
+					},
+					"clusterPermissions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Apply state of each ClusterRole of the ClusterPermissions, in the order of the spec, so a single missing or failing ClusterRole does not hide the health of the others",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ClusterPermissionStatus"),
+									},
+								},
+							},
+						},
+					},
+					"rollout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Checkpoint of the rollout of the RoleBindings in progress, so the operator instance taking over resumes it where the previous one stopped. Set while a large rollout is applied.",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.RolloutCheckpoint"),
+						},
+					},
+					"roleContent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Convergence of the rules of the Roles created from the Permissions with rules, reported by the role content controller. Set when the spec has a Permission with rules.",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.RoleContentStatus"),
+						},
+					},
+					"quarantinedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Generation of the spec whose reconcile panicked. The GroupPermission is quarantined, skipped by the operator, until its spec changes.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+				Required: []string{"state"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.BindingMigrationStatus", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ClusterPermissionStatus", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.Condition", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.DeletionImpact", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.EffectiveAccess", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.MatchedNamespaces", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.NamespaceExplanation", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.NamespaceStatus", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.NamespaceSummary", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.PlanStatus", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.RoleContentStatus", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.RoleUsage", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.RolloutCheckpoint", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.ScheduleStatus"},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_GroupSync(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GroupSync is the Schema for the groupsyncs API",
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupSyncSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupSyncStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupSyncSpec", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.GroupSyncStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_GroupSyncSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GroupSyncSpec defines the desired state of GroupSync",
+				Properties: map[string]spec.Schema{
+					"url": {
+						SchemaProps: spec.SchemaProps{
+							Description: "URL of the SCIM v2 endpoint the Groups are read from, e.g. https://idp.example.com/scim/v2",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"credentialsSecretRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reference to the Secret key holding the bearer token of the endpoint",
+							Ref:         ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.SecretKeyReference"),
+						},
+					},
+					"groups": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of the names of the Groups to sync. OpenShift Groups of the same name not created by the operator are never synced",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"intervalSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Interval between syncs in seconds, defaults to 3600",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"url", "credentialsSecretRef", "groups"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.SecretKeyReference"},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_GroupSyncStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GroupSyncStatus defines the observed state of GroupSync",
+				Properties: map[string]spec.Schema{
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State of the last sync",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message describing why the last sync failed",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastSyncTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastSyncTime is the last time a sync was attempted",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"syncedGroups": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of the Groups synced by the last sync",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.SyncedGroup"),
+									},
+								},
+							},
+						},
+					},
+					"conflictingGroups": {
+						SchemaProps: spec.SchemaProps{
+							Description: "List of the Groups not synced because an OpenShift Group of the same name exists and is not managed by the operator",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"state"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.SyncedGroup", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_PermissionRequest(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PermissionRequest is the Schema for the permissionrequests API",
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.PermissionRequestSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.PermissionRequestStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.PermissionRequestSpec", "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1.PermissionRequestStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_PermissionRequestSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PermissionRequestSpec defines the desired state of PermissionRequest",
+				Properties: map[string]spec.Schema{
+					"groupName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the Group granted the ClusterRole in the namespace of the PermissionRequest",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"clusterRoleName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the ClusterRole granted, must be allowed by the operator policy",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"groupName", "clusterRoleName"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_PermissionRequestStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PermissionRequestStatus defines the observed state of PermissionRequest",
+				Properties: map[string]spec.Schema{
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State of the request",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message describing why the request was denied or failed",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"roleBindingName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the RoleBinding granting the ClusterRole to the Group",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"state"},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
+func schema_pkg_apis_managed_v1alpha1_SecretKeyReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SecretKeyReference refers to a key of a Secret holding the credentials of an external integration. The Secret is in the Namespace of the referencing object.",
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name of the Secret",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"key": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Key of the Secret holding the credentials, defaults to \"token\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		Dependencies: []string{},
 	}
 }
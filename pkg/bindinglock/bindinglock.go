@@ -0,0 +1,95 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bindinglock serializes the operations of the controllers of the operator on each binding, so
+// two objects resolving to the same binding never interleave their reads and writes of it.
+package bindinglock
+
+import (
+	"sync"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Kinds of the locked objects, the Roles created for the bindings are locked like them
+const (
+	ClusterRoleBinding = "ClusterRoleBinding"
+	RoleBinding        = "RoleBinding"
+	Role               = "Role"
+)
+
+// keyedMutex holds a mutex for each key in use, and forgets it once it is no longer
+type keyedMutex struct {
+	mutex sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock is the mutex of a key, with the number of callers holding or waiting for it
+type keyedLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// locks are the locks of the bindings shared by every controller of the operator
+var locks = &keyedMutex{locks: map[string]*keyedLock{}}
+
+// Lock locks the binding of kind named name in namespace, empty for a ClusterRoleBinding, and returns
+// the function unlocking it. The operations on the binding between both are serialized with the
+// operations on it of every other caller.
+func Lock(kind, namespace, name string) func() {
+	return locks.lock(kind + " " + namespace + "/" + name)
+}
+
+// LockObject locks obj like Lock when it is a ClusterRoleBinding or a RoleBinding, other objects are
+// not locked
+func LockObject(obj runtime.Object) func() {
+	switch binding := obj.(type) {
+	case *rbacv1.ClusterRoleBinding:
+		return Lock(ClusterRoleBinding, "", binding.Name)
+	case *rbacv1.RoleBinding:
+		return Lock(RoleBinding, binding.Namespace, binding.Name)
+	}
+	return func() {}
+}
+
+// lock locks key and returns the function unlocking it
+func (m *keyedMutex) lock(key string) func() {
+	m.mutex.Lock()
+	lock, ok := m.locks[key]
+	if !ok {
+		lock = &keyedLock{}
+		m.locks[key] = lock
+	}
+	lock.waiters++
+	m.mutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		m.mutex.Lock()
+		lock.waiters--
+		if lock.waiters == 0 {
+			delete(m.locks, key)
+		}
+		m.mutex.Unlock()
+	}
+}
+
+// size returns the number of keys in use
+func (m *keyedMutex) size() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.locks)
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindinglock
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockSerializesKey(t *testing.T) {
+	m := &keyedMutex{locks: map[string]*keyedLock{}}
+	unlock := m.lock("RoleBinding team-a/admin-example")
+
+	acquired := make(chan struct{})
+	go func() {
+		defer m.lock("RoleBinding team-a/admin-example")()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatalf("expected the second lock of the key to wait for the first one")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// other keys are not serialized with it
+	m.lock("RoleBinding team-b/admin-example")()
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the second lock of the key once the first one is released")
+	}
+}
+
+func TestLockForgetsUnusedKeys(t *testing.T) {
+	m := &keyedMutex{locks: map[string]*keyedLock{}}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.lock("ClusterRoleBinding /cluster-admin-example")()
+		}()
+	}
+	wg.Wait()
+	if size := m.size(); size != 0 {
+		t.Errorf("Mismatch for size. Expected(0), Found(%d)", size)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clusterinfo reads the labels of the cluster the operator runs on, which the clusterConditions
+// of the GroupPermissions are matched against, so a GroupPermission applied to a fleet of clusters only
+// takes effect on some of them.
+package clusterinfo
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// infrastructureGVK is the OpenShift Infrastructure kind, handled as unstructured to avoid depending on
+// the OpenShift API types
+var infrastructureGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "Infrastructure"}
+
+// infrastructureName is the name of the Infrastructure of an OpenShift cluster
+const infrastructureName = "cluster"
+
+// Labels of the cluster read from its Infrastructure
+const (
+	// PlatformLabel is the type of the platform of the cluster, such as AWS or GCP
+	PlatformLabel = "platform"
+	// RegionLabel is the region of the cloud the cluster runs in
+	RegionLabel = "region"
+	// InfrastructureNameLabel is the unique name of the infrastructure of the cluster
+	InfrastructureNameLabel = "infrastructureName"
+)
+
+// Labels returns the labels of the cluster: the platform, region and infrastructure name read from
+// its Infrastructure, overridden by the configured labels. A cluster without Infrastructure, which
+// is not an OpenShift cluster, only has the configured labels.
+func Labels(ctx context.Context, c client.Client, configured map[string]string) (labels.Set, error) {
+	set := labels.Set{}
+
+	infrastructure := &unstructured.Unstructured{}
+	infrastructure.SetGroupVersionKind(infrastructureGVK)
+	err := c.Get(ctx, types.NamespacedName{Name: infrastructureName}, infrastructure)
+	switch {
+	case err == nil:
+		for key, value := range infrastructureLabels(infrastructure) {
+			set[key] = value
+		}
+	case errors.IsNotFound(err) || meta.IsNoMatchError(err):
+	default:
+		return nil, err
+	}
+
+	for key, value := range configured {
+		set[key] = value
+	}
+	return set, nil
+}
+
+// infrastructureLabels returns the labels of the cluster found in the status of its infrastructure
+func infrastructureLabels(infrastructure *unstructured.Unstructured) map[string]string {
+	found := map[string]string{}
+	platform, _, _ := unstructured.NestedString(infrastructure.Object, "status", "platformStatus", "type")
+	if platform == "" {
+		// clusters installed before platformStatus only have the deprecated platform
+		platform, _, _ = unstructured.NestedString(infrastructure.Object, "status", "platform")
+	}
+	if platform != "" {
+		found[PlatformLabel] = platform
+	}
+
+	// the region is in the status of the platform, such as platformStatus.aws.region
+	platformStatus, _, _ := unstructured.NestedMap(infrastructure.Object, "status", "platformStatus")
+	for _, status := range platformStatus {
+		if fields, ok := status.(map[string]interface{}); ok {
+			if region, ok := fields["region"].(string); ok && region != "" {
+				found[RegionLabel] = region
+			}
+		}
+	}
+
+	if name, _, _ := unstructured.NestedString(infrastructure.Object, "status", "infrastructureName"); name != "" {
+		found[InfrastructureNameLabel] = name
+	}
+	return found
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterinfo
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLabels(t *testing.T) {
+	infrastructure := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "cluster"},
+		"status": map[string]interface{}{
+			"infrastructureName": "prod-us-x7k2p",
+			"platform":           "AWS",
+			"platformStatus": map[string]interface{}{
+				"type": "AWS",
+				"aws":  map[string]interface{}{"region": "us-east-1"},
+			},
+		},
+	}}
+	infrastructure.SetGroupVersionKind(infrastructureGVK)
+
+	var tests = []struct {
+		label      string
+		objects    []*unstructured.Unstructured
+		configured map[string]string
+		expected   labels.Set
+	}{
+		{"no infrastructure", nil, map[string]string{"environment": "prod"}, labels.Set{"environment": "prod"}},
+		{"infrastructure", []*unstructured.Unstructured{infrastructure}, nil,
+			labels.Set{PlatformLabel: "AWS", RegionLabel: "us-east-1", InfrastructureNameLabel: "prod-us-x7k2p"}},
+		{"configured override", []*unstructured.Unstructured{infrastructure}, map[string]string{"environment": "prod", RegionLabel: "us-east"},
+			labels.Set{PlatformLabel: "AWS", RegionLabel: "us-east", InfrastructureNameLabel: "prod-us-x7k2p", "environment": "prod"}},
+	}
+
+	for _, test := range tests {
+		fakeClient := fake.NewFakeClientWithScheme(scheme.Scheme)
+		for _, object := range test.objects {
+			if err := fakeClient.Create(context.TODO(), object.DeepCopy()); err != nil {
+				t.Fatalf("%s: unexpected error: %v", test.label, err)
+			}
+		}
+		found, err := Labels(context.TODO(), fakeClient, test.configured)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.label, err)
+			continue
+		}
+		if !reflect.DeepEqual(found, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.label, test.expected, found)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conditions records and reads the conditions of a GroupPermission status.
+//
+// A condition is identified by its ClusterRoleName, State and Reason. Setting a condition replaces
+// the one with the same identity and moves it last, so the list holds a single entry per identity
+// and the last condition of a ClusterRole is its current state.
+package conditions
+
+import (
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// now returns the current time, replaced in tests
+var now = metav1.Now
+
+// sameIdentity returns whether a and b are the same condition
+func sameIdentity(a, b managedv1alpha1.Condition) bool {
+	return a.ClusterRoleName == b.ClusterRoleName && a.State == b.State && a.Reason == b.Reason
+}
+
+// Set records condition in conditions. The LastTransitionTime of the condition it replaces is kept
+// when the Status is unchanged, otherwise it is set to the LastTransitionTime of condition, or to
+// the current time when that is zero.
+func Set(conditions *[]managedv1alpha1.Condition, condition managedv1alpha1.Condition) {
+	if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = now()
+	}
+
+	updated := make([]managedv1alpha1.Condition, 0, len(*conditions)+1)
+	for _, existing := range *conditions {
+		if !sameIdentity(existing, condition) {
+			updated = append(updated, existing)
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+	}
+	*conditions = append(updated, condition)
+}
+
+// Get returns the current condition of clusterRoleName, nil when there is none
+func Get(conditions []managedv1alpha1.Condition, clusterRoleName string) *managedv1alpha1.Condition {
+	for i := len(conditions) - 1; i >= 0; i-- {
+		if conditions[i].ClusterRoleName == clusterRoleName {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsTrue returns whether conditions hold an active condition with reason
+func IsTrue(conditions []managedv1alpha1.Condition, reason managedv1alpha1.ConditionReason) bool {
+	for _, condition := range conditions {
+		if condition.Reason == reason && condition.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStateTrue returns whether conditions hold an active condition in state
+func IsStateTrue(conditions []managedv1alpha1.Condition, state managedv1alpha1.GroupPermissionState) bool {
+	for _, condition := range conditions {
+		if condition.State == state && condition.Status {
+			return true
+		}
+	}
+	return false
+}
+
+// Deactivate deactivates the active conditions with reason, recording the transition time
+func Deactivate(conditions []managedv1alpha1.Condition, reason managedv1alpha1.ConditionReason) {
+	for i := range conditions {
+		if conditions[i].Reason == reason && conditions[i].Status {
+			conditions[i].Status = false
+			conditions[i].LastTransitionTime = now()
+		}
+	}
+}
+
+// DeactivateState deactivates the active conditions in state, recording the transition time
+func DeactivateState(conditions []managedv1alpha1.Condition, state managedv1alpha1.GroupPermissionState) {
+	for i := range conditions {
+		if conditions[i].State == state && conditions[i].Status {
+			conditions[i].Status = false
+			conditions[i].LastTransitionTime = now()
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package conditions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setNow replaces the current time of the package until the returned function is called
+func setNow(t time.Time) func() {
+	now = func() metav1.Time { return metav1.NewTime(t) }
+	return func() { now = metav1.Now }
+}
+
+// TestSet tests the Set function
+// given: a new condition, the same condition again, then with another status
+// expected: a single condition moved last, its transition time changed only with its status
+func TestSet(t *testing.T) {
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	defer setNow(start)()
+
+	var conditions []v1alpha1.Condition
+	created := v1alpha1.Condition{ClusterRoleName: "admin", Status: true, State: v1alpha1.GroupPermissionCreated, Reason: v1alpha1.ReasonBindingCreated}
+	failed := v1alpha1.Condition{ClusterRoleName: "admin", Status: true, State: v1alpha1.GroupPermissionFailed, Reason: v1alpha1.ReasonBindingFailed}
+
+	Set(&conditions, created)
+	Set(&conditions, failed)
+	if len(conditions) != 2 || !conditions[0].LastTransitionTime.Time.Equal(start) {
+		t.Fatalf("expected two conditions at %v, got %v", start, conditions)
+	}
+
+	defer setNow(start.Add(time.Hour))()
+	created.Message = "Successfully created ClusterRoleBinding"
+	Set(&conditions, created)
+	if len(conditions) != 2 {
+		t.Fatalf("expected the condition to be deduplicated, got %v", conditions)
+	}
+	if current := Get(conditions, "admin"); current.Reason != v1alpha1.ReasonBindingCreated || current.Message != created.Message {
+		t.Errorf("expected the updated condition last, got %v", current)
+	}
+	if !conditions[1].LastTransitionTime.Time.Equal(start) {
+		t.Errorf("expected the transition time kept with an unchanged status, got %v", conditions[1].LastTransitionTime)
+	}
+
+	created.Status = false
+	Set(&conditions, created)
+	if !conditions[1].LastTransitionTime.Time.Equal(start.Add(time.Hour)) {
+		t.Errorf("expected the transition time updated with the status, got %v", conditions[1].LastTransitionTime)
+	}
+
+	explicit := metav1.NewTime(start.Add(-time.Hour))
+	Set(&conditions, v1alpha1.Condition{ClusterRoleName: "view", Status: true, LastTransitionTime: explicit})
+	if !conditions[2].LastTransitionTime.Equal(&explicit) {
+		t.Errorf("expected the given transition time kept, got %v", conditions[2].LastTransitionTime)
+	}
+}
+
+// TestGet tests the Get function
+// given: conditions of two ClusterRoles
+// expected: the last condition of the ClusterRole, nil for a ClusterRole without conditions
+func TestGet(t *testing.T) {
+	conditions := []v1alpha1.Condition{
+		{ClusterRoleName: "admin", Message: "first"},
+		{ClusterRoleName: "view", Message: "view"},
+		{ClusterRoleName: "admin", Message: "last"},
+	}
+	if condition := Get(conditions, "admin"); condition == nil || condition.Message != "last" {
+		t.Errorf("expected the last condition of admin, got %v", condition)
+	}
+	if condition := Get(conditions, "edit"); condition != nil {
+		t.Errorf("expected no condition for edit, got %v", condition)
+	}
+}
+
+// TestIsTrue tests the IsTrue and IsStateTrue functions
+// given: active and inactive conditions
+// expected: only active conditions match their reason and state
+func TestIsTrue(t *testing.T) {
+	conditions := []v1alpha1.Condition{
+		{Status: true, State: v1alpha1.GroupPermissionDegraded, Reason: v1alpha1.ReasonFailureBudgetExhausted},
+		{Status: false, State: v1alpha1.GroupPermissionFailed, Reason: v1alpha1.ReasonInvalidPermission},
+	}
+	tests := []struct {
+		label string
+		got   bool
+		want  bool
+	}{
+		{"active reason", IsTrue(conditions, v1alpha1.ReasonFailureBudgetExhausted), true},
+		{"inactive reason", IsTrue(conditions, v1alpha1.ReasonInvalidPermission), false},
+		{"missing reason", IsTrue(conditions, v1alpha1.ReasonPolicyDenied), false},
+		{"active state", IsStateTrue(conditions, v1alpha1.GroupPermissionDegraded), true},
+		{"inactive state", IsStateTrue(conditions, v1alpha1.GroupPermissionFailed), false},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %t, want %t", test.label, test.got, test.want)
+		}
+	}
+}
+
+// TestDeactivate tests the Deactivate and DeactivateState functions
+// given: active conditions with several reasons and states
+// expected: only the matching conditions are deactivated, with their transition time recorded
+func TestDeactivate(t *testing.T) {
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	defer setNow(start)()
+
+	conditions := []v1alpha1.Condition{
+		{Status: true, State: v1alpha1.GroupPermissionFailed, Reason: v1alpha1.ReasonInvalidPermission},
+		{Status: true, State: v1alpha1.GroupPermissionDegraded, Reason: v1alpha1.ReasonFailureBudgetExhausted},
+		{Status: true, State: v1alpha1.GroupPermissionCreated, Reason: v1alpha1.ReasonBindingCreated},
+	}
+	Deactivate(conditions, v1alpha1.ReasonInvalidPermission)
+	DeactivateState(conditions, v1alpha1.GroupPermissionDegraded)
+
+	for i, want := range []bool{false, false, true} {
+		if conditions[i].Status != want {
+			t.Errorf("condition %d: got status %t, want %t", i, conditions[i].Status, want)
+		}
+	}
+	if !conditions[0].LastTransitionTime.Time.Equal(start) || !conditions[2].LastTransitionTime.IsZero() {
+		t.Errorf("expected the transition time of deactivated conditions only, got %v", conditions)
+	}
+}
+
+// FuzzConditions applies sequences of Set, Deactivate and DeactivateState, decoded three bytes at a
+// time, and checks that conditions never hold two conditions of the same identity, that the last set
+// condition is the current one of its ClusterRole, and that deactivated conditions are no longer true
+func FuzzConditions(f *testing.F) {
+	f.Add([]byte{0, 0, 1, 0, 1, 1, 1, 0, 0})
+	f.Add([]byte{0, 2, 1, 0, 2, 0, 0, 2, 1, 2, 2, 0})
+	f.Add([]byte{0, 5, 1, 0, 6, 1, 2, 5, 0, 1, 6, 0})
+	clusterRoleNames := []string{"", "admin", "view"}
+	states := []v1alpha1.GroupPermissionState{v1alpha1.GroupPermissionCreated, v1alpha1.GroupPermissionFailed, v1alpha1.GroupPermissionDegraded}
+	reasons := []v1alpha1.ConditionReason{"", v1alpha1.ReasonBindingCreated, v1alpha1.ReasonFailureBudgetExhausted, v1alpha1.ReasonReconcilePanicked}
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		var conditions []v1alpha1.Condition
+		for i := 0; i+2 < len(ops); i += 3 {
+			op, identity, status := ops[i]%3, int(ops[i+1]), ops[i+2]%2 == 1
+			condition := v1alpha1.Condition{
+				ClusterRoleName: clusterRoleNames[identity%len(clusterRoleNames)],
+				State:           states[identity/len(clusterRoleNames)%len(states)],
+				Reason:          reasons[identity/len(clusterRoleNames)/len(states)%len(reasons)],
+				Status:          status,
+			}
+			switch op {
+			case 0:
+				Set(&conditions, condition)
+				current := Get(conditions, condition.ClusterRoleName)
+				if current == nil || !sameIdentity(*current, condition) || current.Status != status {
+					t.Fatalf("expected %+v to be the current condition, got %+v", condition, current)
+				}
+			case 1:
+				Deactivate(conditions, condition.Reason)
+				if IsTrue(conditions, condition.Reason) {
+					t.Fatalf("expected no condition with reason %q to be true after Deactivate, got %+v", condition.Reason, conditions)
+				}
+			case 2:
+				DeactivateState(conditions, condition.State)
+				if IsStateTrue(conditions, condition.State) {
+					t.Fatalf("expected no condition in state %q to be true after DeactivateState, got %+v", condition.State, conditions)
+				}
+			}
+			for j := range conditions {
+				for k := j + 1; k < len(conditions); k++ {
+					if sameIdentity(conditions[j], conditions[k]) {
+						t.Fatalf("conditions %d and %d have the same identity: %+v", j, k, conditions)
+					}
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,345 @@
+package accessrevocation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/readonly"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_accessrevocation")
+
+// blockedRequeueDelay is the delay before an AccessRevocation blocked by grants still creating bindings
+// to its subject is retried
+const blockedRequeueDelay = time.Minute
+
+// Add creates a new AccessRevocation Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) (*ReconcileAccessRevocation, error) {
+	// bindings and PermissionRequests are outside the watched namespace, they are read from the apiserver
+	apiClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconcileAccessRevocation{
+		client:    mgr.GetClient(),
+		apiClient: readonly.Wrap(apiClient, mgr.GetScheme()),
+		scheme:    mgr.GetScheme(),
+		recorder:  mgr.GetRecorder("accessrevocation-controller"),
+		now:       time.Now,
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileAccessRevocation) error {
+	// Create a new controller
+	c, err := controller.New("accessrevocation-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource AccessRevocation
+	err = c.Watch(&source.Kind{Type: &managedv1alpha1.AccessRevocation{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileAccessRevocation implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileAccessRevocation{}
+
+// ReconcileAccessRevocation reconciles an AccessRevocation object
+type ReconcileAccessRevocation struct {
+	// client reads objects from the cache and writes to the apiserver
+	client client.Client
+	// apiClient reads directly from the apiserver, for bindings and PermissionRequests
+	apiClient client.Client
+	scheme    *runtime.Scheme
+	// recorder records the audit trail of the AccessRevocations as events
+	recorder record.EventRecorder
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=accessrevocations,verbs=get;list;watch,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=accessrevocations/status,verbs=update,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=grouppermissions;elevations,verbs=list,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=permissionrequests,verbs=list
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings;rolebindings,verbs=list;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch,namespace=openshift-rbac-permissions-operator
+
+// Reconcile deletes every binding managed by the operator to the subject of an AccessRevocation across
+// the cluster, and records the AccessRevocation as Complete once no grant creates them anymore. It is
+// Blocked and retried while grants remain. A completed AccessRevocation is never processed again.
+// Revocations are recorded as events of the AccessRevocation.
+func (r *ReconcileAccessRevocation) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling AccessRevocation")
+
+	instance := &managedv1alpha1.AccessRevocation{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if instance.DeletionTimestamp != nil || isConditionTrue(instance.Status.Conditions, managedv1alpha1.AccessRevocationComplete) {
+		return reconcile.Result{}, nil
+	}
+
+	status := *instance.Status.DeepCopy()
+	revoked, unmanaged, err := r.revokeBindings(instance)
+	status.RevokedBindings = appendUnique(status.RevokedBindings, revoked...)
+	if err != nil {
+		reqLogger.Error(err, "Failed to revoke bindings")
+		setCondition(&status.Conditions, managedv1alpha1.AccessRevocationFailed, true, err.Error(), r.now())
+		r.recorder.Event(instance, corev1.EventTypeWarning, "RevocationFailed", err.Error())
+		if statusErr := r.updateStatus(instance, status); statusErr != nil {
+			reqLogger.Error(statusErr, "Failed to update status.")
+		}
+		return reconcile.Result{}, err
+	}
+
+	grants, err := r.listGrants(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to list grants")
+		return reconcile.Result{}, err
+	}
+
+	subject := instance.Spec.SubjectKind + " " + instance.Spec.SubjectName
+	message := fmt.Sprintf("Deleted %d bindings managed by the operator to %s", len(status.RevokedBindings), subject)
+	if instance.Spec.ReportUnmanaged {
+		status.UnmanagedBindings = unmanaged
+	}
+	status.Grants = grants
+	setCondition(&status.Conditions, managedv1alpha1.AccessRevocationFailed, false, "", r.now())
+
+	// the grants create the bindings again, the AccessRevocation completes once they are deleted
+	if len(grants) > 0 {
+		message += fmt.Sprintf(", %d grants still create them again", len(grants))
+		if !isConditionTrue(status.Conditions, managedv1alpha1.AccessRevocationBlocked) {
+			r.recorder.Eventf(instance, corev1.EventTypeWarning, "AccessStillGranted", "%s is still granted access by %v", subject, grants)
+		}
+		setCondition(&status.Conditions, managedv1alpha1.AccessRevocationBlocked, true, message, r.now())
+		reqLogger.Info("Revocation blocked by grants", "Subject", subject, "RevokedBindings", len(status.RevokedBindings), "Grants", len(grants))
+		if err := r.updateStatus(instance, status); err != nil {
+			reqLogger.Error(err, "Failed to update status.")
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{RequeueAfter: blockedRequeueDelay}, nil
+	}
+
+	status.CompletionTime = &metav1.Time{Time: r.now()}
+	setCondition(&status.Conditions, managedv1alpha1.AccessRevocationBlocked, false, "", r.now())
+	setCondition(&status.Conditions, managedv1alpha1.AccessRevocationComplete, true, message, r.now())
+
+	reqLogger.Info("Revoked access", "Subject", subject, "RevokedBindings", len(status.RevokedBindings))
+	r.recorder.Event(instance, corev1.EventTypeNormal, "AccessRevoked", message)
+
+	if err := r.updateStatus(instance, status); err != nil {
+		reqLogger.Error(err, "Failed to update status.")
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// revokeBindings deletes the ClusterRoleBindings and RoleBindings managed by the operator to the subject
+// of revocation. It returns the bindings deleted and the bindings to the subject not managed by the
+// operator, which are left in place.
+func (r *ReconcileAccessRevocation) revokeBindings(revocation *managedv1alpha1.AccessRevocation) ([]string, []string, error) {
+	var revoked, unmanaged []string
+
+	clusterRoleBindingList := &rbacv1.ClusterRoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), &client.ListOptions{}, clusterRoleBindingList); err != nil {
+		return revoked, nil, err
+	}
+	for i := range clusterRoleBindingList.Items {
+		clusterRoleBinding := &clusterRoleBindingList.Items[i]
+		if !hasSubject(clusterRoleBinding.Subjects, revocation) {
+			continue
+		}
+		name := "ClusterRoleBinding " + clusterRoleBinding.Name
+		if !utility.IsManaged(clusterRoleBinding.ObjectMeta) {
+			unmanaged = append(unmanaged, name)
+			continue
+		}
+		if err := r.deleteBinding(clusterRoleBinding); err != nil {
+			return revoked, nil, err
+		}
+		revoked = append(revoked, name)
+	}
+
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), &client.ListOptions{}, roleBindingList); err != nil {
+		return revoked, nil, err
+	}
+	for i := range roleBindingList.Items {
+		roleBinding := &roleBindingList.Items[i]
+		if !hasSubject(roleBinding.Subjects, revocation) {
+			continue
+		}
+		name := "RoleBinding " + roleBinding.Namespace + "/" + roleBinding.Name
+		if !utility.IsManaged(roleBinding.ObjectMeta) {
+			unmanaged = append(unmanaged, name)
+			continue
+		}
+		if err := r.deleteBinding(roleBinding); err != nil {
+			return revoked, nil, err
+		}
+		revoked = append(revoked, name)
+	}
+	return revoked, unmanaged, nil
+}
+
+// deleteBinding deletes the ClusterRoleBinding or RoleBinding binding, if it still exists
+func (r *ReconcileAccessRevocation) deleteBinding(binding runtime.Object) error {
+	defer bindinglock.LockObject(binding)()
+	if err := r.client.Delete(context.TODO(), binding); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// listGrants returns the GroupPermissions, Elevations and PermissionRequests granting access to the
+// subject of revocation, whose bindings the operator creates again until they are deleted
+func (r *ReconcileAccessRevocation) listGrants(revocation *managedv1alpha1.AccessRevocation) ([]string, error) {
+	var grants []string
+	switch revocation.Spec.SubjectKind {
+	case rbacv1.GroupKind:
+		groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+		if err := r.client.List(context.TODO(), &client.ListOptions{}, groupPermissionList); err != nil {
+			return nil, err
+		}
+		for _, groupPermission := range groupPermissionList.Items {
+			if groupPermission.Spec.GroupName == revocation.Spec.SubjectName && groupPermission.DeletionTimestamp == nil {
+				grants = append(grants, "GroupPermission "+groupPermission.Namespace+"/"+groupPermission.Name)
+			}
+		}
+
+		permissionRequestList := &managedv1alpha1.PermissionRequestList{}
+		if err := r.apiClient.List(context.TODO(), &client.ListOptions{}, permissionRequestList); err != nil {
+			return nil, err
+		}
+		for _, permissionRequest := range permissionRequestList.Items {
+			if permissionRequest.Spec.GroupName == revocation.Spec.SubjectName && permissionRequest.DeletionTimestamp == nil {
+				grants = append(grants, "PermissionRequest "+permissionRequest.Namespace+"/"+permissionRequest.Name)
+			}
+		}
+	case rbacv1.UserKind:
+		elevationList := &managedv1alpha1.ElevationList{}
+		if err := r.client.List(context.TODO(), &client.ListOptions{}, elevationList); err != nil {
+			return nil, err
+		}
+		for _, elevation := range elevationList.Items {
+			if elevation.Annotations[operatorconfig.RequesterAnnotation] == revocation.Spec.SubjectName && elevation.Status.State == managedv1alpha1.ElevationActive {
+				grants = append(grants, "Elevation "+elevation.Namespace+"/"+elevation.Name)
+			}
+		}
+	}
+	sort.Strings(grants)
+	return grants, nil
+}
+
+// updateStatus records status on revocation, if it changed
+func (r *ReconcileAccessRevocation) updateStatus(revocation *managedv1alpha1.AccessRevocation, status managedv1alpha1.AccessRevocationStatus) error {
+	if reflect.DeepEqual(revocation.Status, status) {
+		return nil
+	}
+	revocation.Status = status
+	return r.client.Status().Update(context.TODO(), revocation)
+}
+
+// hasSubject returns whether subjects hold the subject of revocation
+func hasSubject(subjects []rbacv1.Subject, revocation *managedv1alpha1.AccessRevocation) bool {
+	for _, subject := range subjects {
+		if subject.Kind == revocation.Spec.SubjectKind && subject.Name == revocation.Spec.SubjectName {
+			return true
+		}
+	}
+	return false
+}
+
+// isConditionTrue returns whether the condition of type conditionType is active
+func isConditionTrue(conditions []managedv1alpha1.AccessRevocationCondition, conditionType managedv1alpha1.AccessRevocationConditionType) bool {
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return condition.Status
+		}
+	}
+	return false
+}
+
+// setCondition sets the condition of type conditionType, recording the transition time when its status
+// changes. An inactive condition is only recorded if it existed before.
+func setCondition(conditions *[]managedv1alpha1.AccessRevocationCondition, conditionType managedv1alpha1.AccessRevocationConditionType, status bool, message string, now time.Time) {
+	for i := range *conditions {
+		condition := &(*conditions)[i]
+		if condition.Type != conditionType {
+			continue
+		}
+		if condition.Status != status {
+			condition.LastTransitionTime = metav1.Time{Time: now}
+		}
+		condition.Status = status
+		condition.Message = message
+		return
+	}
+	if !status {
+		return
+	}
+	*conditions = append(*conditions, managedv1alpha1.AccessRevocationCondition{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: metav1.Time{Time: now},
+		Message:            message,
+	})
+}
+
+// appendUnique appends to list the names it does not hold yet
+func appendUnique(list []string, names ...string) []string {
+	held := map[string]bool{}
+	for _, name := range list {
+		held[name] = true
+	}
+	for _, name := range names {
+		if !held[name] {
+			held[name] = true
+			list = append(list, name)
+		}
+	}
+	return list
+}
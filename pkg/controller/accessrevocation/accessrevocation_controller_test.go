@@ -0,0 +1,219 @@
+package accessrevocation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	revocationKey = types.NamespacedName{Namespace: "rbac-permissions-operator", Name: "testAccessRevocation"}
+	revoked       = time.Date(2026, time.October, 15, 9, 0, 0, 0, time.UTC)
+)
+
+func mockAccessRevocation(subjectKind, subjectName string, reportUnmanaged bool) *v1alpha1.AccessRevocation {
+	return &v1alpha1.AccessRevocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      revocationKey.Name,
+			Namespace: revocationKey.Namespace,
+		},
+		Spec: v1alpha1.AccessRevocationSpec{
+			SubjectKind:     subjectKind,
+			SubjectName:     subjectName,
+			ReportUnmanaged: reportUnmanaged,
+		},
+	}
+}
+
+// mockClusterRoleBinding returns a ClusterRoleBinding of name to the subject kind/subjectName, managed
+// by the operator when managed is set
+func mockClusterRoleBinding(name, kind, subjectName string, managed bool) *rbacv1.ClusterRoleBinding {
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Subjects:   []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: kind, Name: subjectName}},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+	}
+	if managed {
+		utility.SetManagedLabels(&clusterRoleBinding.ObjectMeta, revocationKey.Namespace, "testGroupPermission")
+	}
+	return clusterRoleBinding
+}
+
+func newTestReconciler(t *testing.T, objs ...runtime.Object) *ReconcileAccessRevocation {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	fakeClient := fake.NewFakeClient(objs...)
+	return &ReconcileAccessRevocation{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+		now:       func() time.Time { return revoked },
+	}
+}
+
+// reconcileAccessRevocation reconciles the test AccessRevocation and returns it. A Blocked AccessRevocation
+// must be requeued.
+func reconcileAccessRevocation(t *testing.T, r *ReconcileAccessRevocation) *v1alpha1.AccessRevocation {
+	result, err := r.Reconcile(reconcile.Request{NamespacedName: revocationKey})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	revocation := &v1alpha1.AccessRevocation{}
+	if err := r.client.Get(context.TODO(), revocationKey, revocation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if blocked := isConditionTrue(revocation.Status.Conditions, v1alpha1.AccessRevocationBlocked); blocked != (result.RequeueAfter == blockedRequeueDelay) {
+		t.Errorf("Mismatch for requeue. Expected(%t), Found(%+v)", blocked, result)
+	}
+	return revocation
+}
+
+// TestRevokeGroup tests the Reconcile function for a Group
+// given: managed and unmanaged bindings to the Group, a managed binding to another Group, a labeled RoleBinding
+// owned by a PermissionRequest and a GroupPermission still granting access to the Group
+// expected: the managed bindings of the Group are deleted, the unmanaged one is reported and kept, the
+// GroupPermission is reported and the AccessRevocation is Blocked, then Complete once the GroupPermission
+// is deleted
+func TestRevokeGroup(t *testing.T) {
+	groupPermission := &v1alpha1.GroupPermission{
+		ObjectMeta: metav1.ObjectMeta{Namespace: revocationKey.Namespace, Name: "testGroupPermission"},
+		Spec:       v1alpha1.GroupPermissionSpec{GroupName: "leavers"},
+	}
+	requested := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "team-a",
+			Name:      "permissionrequest-edit",
+			Labels: map[string]string{
+				operatorconfig.ManagedByLabel:             operatorconfig.OperatorName,
+				operatorconfig.PermissionRequestNameLabel: "edit",
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       "PermissionRequest",
+				Name:       "edit",
+				Controller: func() *bool { controller := true; return &controller }(),
+			}},
+		},
+		Subjects: []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "leavers"}},
+		RoleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "edit"},
+	}
+	r := newTestReconciler(t, mockAccessRevocation(rbacv1.GroupKind, "leavers", true), groupPermission, requested,
+		mockClusterRoleBinding("leavers-view", rbacv1.GroupKind, "leavers", true),
+		mockClusterRoleBinding("leavers-manual", rbacv1.GroupKind, "leavers", false),
+		mockClusterRoleBinding("stayers-view", rbacv1.GroupKind, "stayers", true))
+
+	revocation := reconcileAccessRevocation(t, r)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "leavers-view"}, &rbacv1.ClusterRoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the managed ClusterRoleBinding of the Group to be deleted, got %v", err)
+	}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: requested.Name}, &rbacv1.RoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the RoleBinding of the PermissionRequest to be deleted, got %v", err)
+	}
+	for _, name := range []string{"leavers-manual", "stayers-view"} {
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name}, &rbacv1.ClusterRoleBinding{}); err != nil {
+			t.Errorf("expected ClusterRoleBinding %s to be kept: %v", name, err)
+		}
+	}
+
+	expectedRevoked := []string{"ClusterRoleBinding leavers-view", "RoleBinding team-a/permissionrequest-edit"}
+	if !reflect.DeepEqual(revocation.Status.RevokedBindings, expectedRevoked) {
+		t.Errorf("Mismatch for RevokedBindings. Expected(%v), Found(%v)", expectedRevoked, revocation.Status.RevokedBindings)
+	}
+	if expected := []string{"ClusterRoleBinding leavers-manual"}; !reflect.DeepEqual(revocation.Status.UnmanagedBindings, expected) {
+		t.Errorf("Mismatch for UnmanagedBindings. Expected(%v), Found(%v)", expected, revocation.Status.UnmanagedBindings)
+	}
+	if expected := []string{"GroupPermission rbac-permissions-operator/testGroupPermission"}; !reflect.DeepEqual(revocation.Status.Grants, expected) {
+		t.Errorf("Mismatch for Grants. Expected(%v), Found(%v)", expected, revocation.Status.Grants)
+	}
+	if !isConditionTrue(revocation.Status.Conditions, v1alpha1.AccessRevocationBlocked) || isConditionTrue(revocation.Status.Conditions, v1alpha1.AccessRevocationComplete) {
+		t.Errorf("expected the AccessRevocation to be Blocked, got %+v", revocation.Status.Conditions)
+	}
+	if revocation.Status.CompletionTime != nil {
+		t.Errorf("Mismatch for CompletionTime. Expected(nil), Found(%v)", revocation.Status.CompletionTime)
+	}
+
+	// the AccessRevocation completes once the GroupPermission is deleted
+	if err := r.client.Delete(context.TODO(), groupPermission); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	revocation = reconcileAccessRevocation(t, r)
+	if len(revocation.Status.Grants) != 0 {
+		t.Errorf("Mismatch for Grants. Expected(none), Found(%v)", revocation.Status.Grants)
+	}
+	if !isConditionTrue(revocation.Status.Conditions, v1alpha1.AccessRevocationComplete) || isConditionTrue(revocation.Status.Conditions, v1alpha1.AccessRevocationBlocked) {
+		t.Errorf("expected the AccessRevocation to be Complete, got %+v", revocation.Status.Conditions)
+	}
+	if revocation.Status.CompletionTime == nil || !revocation.Status.CompletionTime.Time.Equal(revoked) {
+		t.Errorf("Mismatch for CompletionTime. Expected(%s), Found(%v)", revoked, revocation.Status.CompletionTime)
+	}
+}
+
+// TestRevokeUserComplete tests the Reconcile function for a User once the AccessRevocation is Complete
+// given: a completed AccessRevocation of a User, and a managed binding to the User created since
+// expected: the binding is kept, a completed AccessRevocation is never processed again
+func TestRevokeUserComplete(t *testing.T) {
+	revocation := mockAccessRevocation(rbacv1.UserKind, "alice", false)
+	revocation.Status.Conditions = []v1alpha1.AccessRevocationCondition{
+		{Type: v1alpha1.AccessRevocationComplete, Status: true, LastTransitionTime: metav1.Time{Time: revoked}},
+	}
+	r := newTestReconciler(t, revocation, mockClusterRoleBinding("elevation-alice", rbacv1.UserKind, "alice", true))
+
+	reconcileAccessRevocation(t, r)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "elevation-alice"}, &rbacv1.ClusterRoleBinding{}); err != nil {
+		t.Errorf("expected the ClusterRoleBinding to be kept: %v", err)
+	}
+}
+
+// TestRevokeUser tests the Reconcile function for a User
+// given: a managed binding to the User and one to a Group of the same name, and an active Elevation of the User
+// expected: only the binding to the User is deleted, the Elevation is reported and no unmanaged binding
+// is reported since reportUnmanaged is not set
+func TestRevokeUser(t *testing.T) {
+	elevation := &v1alpha1.Elevation{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   revocationKey.Namespace,
+			Name:        "testElevation",
+			Annotations: map[string]string{operatorconfig.RequesterAnnotation: "alice"},
+		},
+		Status: v1alpha1.ElevationStatus{State: v1alpha1.ElevationActive},
+	}
+	r := newTestReconciler(t, mockAccessRevocation(rbacv1.UserKind, "alice", false), elevation,
+		mockClusterRoleBinding("elevation-alice", rbacv1.UserKind, "alice", true),
+		mockClusterRoleBinding("group-alice", rbacv1.GroupKind, "alice", true),
+		mockClusterRoleBinding("alice-manual", rbacv1.UserKind, "alice", false))
+
+	revocation := reconcileAccessRevocation(t, r)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "elevation-alice"}, &rbacv1.ClusterRoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the ClusterRoleBinding of the User to be deleted, got %v", err)
+	}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "group-alice"}, &rbacv1.ClusterRoleBinding{}); err != nil {
+		t.Errorf("expected the ClusterRoleBinding of the Group to be kept: %v", err)
+	}
+	if len(revocation.Status.UnmanagedBindings) != 0 {
+		t.Errorf("expected no unmanaged bindings to be reported, got %v", revocation.Status.UnmanagedBindings)
+	}
+	if expected := []string{"Elevation rbac-permissions-operator/testElevation"}; !reflect.DeepEqual(revocation.Status.Grants, expected) {
+		t.Errorf("Mismatch for Grants. Expected(%v), Found(%v)", expected, revocation.Status.Grants)
+	}
+}
@@ -1,10 +1,10 @@
 package controller
 
 import (
-	"github.com/openshift/rbac-permissions-operator/pkg/controller/grouppermission"
+	"github.com/openshift/rbac-permissions-operator/pkg/controller/elevation"
 )
 
 func init() {
 	// AddToManagerFuncs is a list of functions to create controllers and add them to a manager.
-	AddToManagerFuncs = append(AddToManagerFuncs, grouppermission.Add)
+	AddToManagerFuncs = append(AddToManagerFuncs, elevation.Add)
 }
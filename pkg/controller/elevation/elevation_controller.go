@@ -0,0 +1,467 @@
+package elevation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/readonly"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_elevation")
+
+// Add creates a new Elevation Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) (*ReconcileElevation, error) {
+	// ClusterRoleBindings are not held by the manager cache, they are read from the apiserver
+	apiClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconcileElevation{
+		client:    mgr.GetClient(),
+		apiClient: readonly.Wrap(apiClient, mgr.GetScheme()),
+		scheme:    mgr.GetScheme(),
+		recorder:  mgr.GetRecorder("elevation-controller"),
+		now:       time.Now,
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileElevation) error {
+	// Create a new controller
+	c, err := controller.New("elevation-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource Elevation
+	err = c.Watch(&source.Kind{Type: &managedv1alpha1.Elevation{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to GroupPermissions, so Elevations are revoked as soon as their GroupPermission
+	// is deleted or no longer allows them
+	err = c.Watch(&source.Kind{Type: &managedv1alpha1.GroupPermission{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: &groupPermissionToElevations{client: mgr.GetClient()},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// groupPermissionToElevations maps a GroupPermission to the Elevations to its ClusterPermissions
+type groupPermissionToElevations struct {
+	client client.Client
+}
+
+// Map implements handler.Mapper
+func (m *groupPermissionToElevations) Map(object handler.MapObject) []reconcile.Request {
+	elevationList := &managedv1alpha1.ElevationList{}
+	if err := m.client.List(context.TODO(), &client.ListOptions{Namespace: object.Meta.GetNamespace()}, elevationList); err != nil {
+		log.Error(err, "Failed to list Elevations")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, elevation := range elevationList.Items {
+		if elevation.Spec.GroupPermissionName != object.Meta.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: elevation.Namespace,
+			Name:      elevation.Name,
+		}})
+	}
+	return requests
+}
+
+// blank assignment to verify that ReconcileElevation implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileElevation{}
+
+// ReconcileElevation reconciles an Elevation object
+type ReconcileElevation struct {
+	// client reads objects from the cache and writes to the apiserver
+	client client.Client
+	// apiClient reads directly from the apiserver, for ClusterRoleBindings
+	apiClient client.Client
+	scheme    *runtime.Scheme
+	// recorder records the audit trail of the Elevations as events
+	recorder record.EventRecorder
+	// now returns the current time, replaced in tests
+	now func() time.Time
+}
+
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=elevations,verbs=get;list;watch;update,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=elevations/status,verbs=update,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=grouppermissions,verbs=get;list;watch,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;create;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=bind
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch,namespace=openshift-rbac-permissions-operator
+
+// Reconcile grants the ElevationClusterPermissions of a GroupPermission to the requester of an Elevation,
+// until the Elevation expires. The Elevation is denied unless the requester is a member of the Group of the
+// GroupPermission and the GroupPermission allows Elevations as long as the requested one. Grants and reverts
+// are recorded as events of the Elevation.
+func (r *ReconcileElevation) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling Elevation")
+
+	instance := &managedv1alpha1.Elevation{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if instance.DeletionTimestamp != nil {
+		if hasFinalizer(instance) {
+			reqLogger.Info("Deleting elevation bindings")
+			if err := r.deleteBindings(instance); err != nil {
+				reqLogger.Error(err, "Failed to delete elevation bindings")
+				return reconcile.Result{}, err
+			}
+			removeFinalizer(instance)
+			if err := r.client.Update(context.TODO(), instance); err != nil {
+				reqLogger.Error(err, "Failed to remove finalizer")
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{}, nil
+	}
+
+	// expired and denied Elevations are never granted again, a new Elevation must be requested
+	if instance.Status.State == managedv1alpha1.ElevationExpired || instance.Status.State == managedv1alpha1.ElevationDenied {
+		return reconcile.Result{}, nil
+	}
+
+	expiration := instance.CreationTimestamp.Add(time.Duration(instance.Spec.DurationHours) * time.Hour)
+	if !r.now().Before(expiration) {
+		return reconcile.Result{}, r.expire(instance)
+	}
+
+	groupPermission, reason, err := r.checkAllowed(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to get GroupPermission")
+		return reconcile.Result{}, err
+	}
+	if reason != "" {
+		return reconcile.Result{}, r.deny(instance, reason)
+	}
+
+	// hold the Elevation on deletion until its bindings are deleted
+	if !hasFinalizer(instance) {
+		instance.Finalizers = append(instance.Finalizers, operatorconfig.ElevationFinalizer)
+		if err := r.client.Update(context.TODO(), instance); err != nil {
+			reqLogger.Error(err, "Failed to add finalizer")
+			return reconcile.Result{}, err
+		}
+	}
+
+	var bindingNames []string
+	for _, clusterRoleName := range groupPermission.Spec.ElevationClusterPermissions {
+		binding := newClusterRoleBinding(instance, clusterRoleName)
+		if err := r.ensureBinding(instance, binding); err != nil {
+			reqLogger.Error(err, "Failed to grant Elevation", "ClusterRoleBinding", binding.Name)
+			status := managedv1alpha1.ElevationStatus{
+				State:          managedv1alpha1.ElevationFailed,
+				Message:        err.Error(),
+				ExpirationTime: &metav1.Time{Time: expiration},
+			}
+			if statusErr := r.updateStatus(instance, status); statusErr != nil {
+				reqLogger.Error(statusErr, "Failed to update status.")
+			}
+			return reconcile.Result{}, err
+		}
+		bindingNames = append(bindingNames, binding.Name)
+	}
+	// revoke the ClusterRoles since removed from the ElevationClusterPermissions
+	if err := r.deleteBindings(instance, bindingNames...); err != nil {
+		reqLogger.Error(err, "Failed to delete outdated elevation bindings")
+		return reconcile.Result{}, err
+	}
+
+	if instance.Status.State != managedv1alpha1.ElevationActive {
+		requester := instance.Annotations[operatorconfig.RequesterAnnotation]
+		reqLogger.Info("Granted Elevation", "Requester", requester, "GroupPermission", groupPermission.Name,
+			"Expiration", expiration.UTC().Format(time.RFC3339), "Justification", instance.Spec.Justification)
+		// the audit record is routed to the team owning the GroupPermission, if any, and documented by it
+		r.recorder.AnnotatedEventf(instance, utility.AuditAnnotations(&groupPermission.Spec), corev1.EventTypeNormal, "Elevated",
+			"Granted the ElevationClusterPermissions of GroupPermission %s to %s until %s: %s",
+			groupPermission.Name, requester, expiration.UTC().Format(time.RFC3339), instance.Spec.Justification)
+	}
+
+	status := managedv1alpha1.ElevationStatus{
+		State:               managedv1alpha1.ElevationActive,
+		ExpirationTime:      &metav1.Time{Time: expiration},
+		ClusterRoleBindings: bindingNames,
+	}
+	if err := r.updateStatus(instance, status); err != nil {
+		reqLogger.Error(err, "Failed to update status.")
+		return reconcile.Result{}, err
+	}
+
+	// the bindings are reverted right at expiration, the requeue is not jittered
+	return reconcile.Result{RequeueAfter: expiration.Sub(r.now())}, nil
+}
+
+// checkAllowed returns the GroupPermission of elevation, or the reason elevation is denied
+func (r *ReconcileElevation) checkAllowed(elevation *managedv1alpha1.Elevation) (*managedv1alpha1.GroupPermission, string, error) {
+	if elevation.Annotations[operatorconfig.RequesterAnnotation] == "" {
+		return nil, "no requester is recorded on the Elevation", nil
+	}
+
+	groupPermission := &managedv1alpha1.GroupPermission{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: elevation.Namespace, Name: elevation.Spec.GroupPermissionName}, groupPermission)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Sprintf("GroupPermission %s does not exist", elevation.Spec.GroupPermissionName), nil
+		}
+		return nil, "", err
+	}
+	if groupPermission.DeletionTimestamp != nil {
+		return nil, fmt.Sprintf("GroupPermission %s is being deleted", groupPermission.Name), nil
+	}
+	if elevation.Spec.DurationHours > groupPermission.Spec.MaxElevationHours {
+		return nil, fmt.Sprintf("GroupPermission %s allows Elevations of at most %d hours", groupPermission.Name, groupPermission.Spec.MaxElevationHours), nil
+	}
+	if len(groupPermission.Spec.ElevationClusterPermissions) == 0 {
+		return nil, fmt.Sprintf("GroupPermission %s has no elevationClusterPermissions", groupPermission.Name), nil
+	}
+	// only the members of the Group of the GroupPermission may be elevated to its ElevationClusterPermissions
+	if !isGroupMember(elevation, groupPermission.Spec.GroupName) {
+		return nil, fmt.Sprintf("the requester is not a member of Group %s of GroupPermission %s", groupPermission.Spec.GroupName, groupPermission.Name), nil
+	}
+	// the operator binds them with its own rights, on behalf of the requester of the GroupPermission
+	for _, clusterRoleName := range groupPermission.Spec.ElevationClusterPermissions {
+		allowed, err := r.isBindAllowed(groupPermission, clusterRoleName)
+		if err != nil {
+			return nil, "", err
+		}
+		if !allowed {
+			return nil, fmt.Sprintf("the requester of GroupPermission %s is not allowed to bind ClusterRole %s", groupPermission.Name, clusterRoleName), nil
+		}
+	}
+	return groupPermission, "", nil
+}
+
+// isBindAllowed returns whether the requester of groupPermission, as recorded by its webhook, holds the
+// "bind" verb on the ClusterRole clusterRoleName. A GroupPermission without a recorded requester is never
+// allowed to grant Elevations.
+func (r *ReconcileElevation) isBindAllowed(groupPermission *managedv1alpha1.GroupPermission, clusterRoleName string) (bool, error) {
+	requester, ok := utility.RequesterFromAnnotations(groupPermission.Annotations)
+	if !ok {
+		return false, nil
+	}
+
+	sar := utility.NewSubjectAccessReview(requester, &authorizationv1.ResourceAttributes{
+		Group:    rbacv1.GroupName,
+		Resource: "clusterroles",
+		Verb:     "bind",
+		Name:     clusterRoleName,
+	}, nil)
+	if err := r.client.Create(context.TODO(), sar); err != nil {
+		return false, err
+	}
+	return sar.Status.Allowed, nil
+}
+
+// isGroupMember returns whether groupName is one of the groups of the requester of elevation, as
+// recorded by the webhook on its creation
+func isGroupMember(elevation *managedv1alpha1.Elevation, groupName string) bool {
+	for _, group := range strings.Split(elevation.Annotations[operatorconfig.RequesterGroupsAnnotation], ",") {
+		if group != "" && group == groupName {
+			return true
+		}
+	}
+	return false
+}
+
+// deny revokes the bindings of elevation, which may have been granted before its GroupPermission changed,
+// and records it as denied for reason
+func (r *ReconcileElevation) deny(elevation *managedv1alpha1.Elevation, reason string) error {
+	if err := r.deleteBindings(elevation); err != nil {
+		return err
+	}
+	log.Info("Denied Elevation", "Namespace", elevation.Namespace, "Name", elevation.Name, "Reason", reason)
+	r.recorder.Event(elevation, corev1.EventTypeWarning, "ElevationDenied", reason)
+
+	removeFinalizer(elevation)
+	if err := r.client.Update(context.TODO(), elevation); err != nil {
+		return err
+	}
+	return r.updateStatus(elevation, managedv1alpha1.ElevationStatus{
+		State:   managedv1alpha1.ElevationDenied,
+		Message: reason,
+	})
+}
+
+// expire reverts the bindings of elevation and records it as expired
+func (r *ReconcileElevation) expire(elevation *managedv1alpha1.Elevation) error {
+	if err := r.deleteBindings(elevation); err != nil {
+		return err
+	}
+	log.Info("Reverted expired Elevation", "Namespace", elevation.Namespace, "Name", elevation.Name,
+		"Requester", elevation.Annotations[operatorconfig.RequesterAnnotation])
+	r.recorder.Eventf(elevation, corev1.EventTypeNormal, "ElevationExpired", "Revoked the ElevationClusterPermissions of GroupPermission %s from %s",
+		elevation.Spec.GroupPermissionName, elevation.Annotations[operatorconfig.RequesterAnnotation])
+
+	removeFinalizer(elevation)
+	if err := r.client.Update(context.TODO(), elevation); err != nil {
+		return err
+	}
+	expiration := elevation.CreationTimestamp.Add(time.Duration(elevation.Spec.DurationHours) * time.Hour)
+	return r.updateStatus(elevation, managedv1alpha1.ElevationStatus{
+		State:          managedv1alpha1.ElevationExpired,
+		ExpirationTime: &metav1.Time{Time: expiration},
+	})
+}
+
+// ensureBinding creates binding for elevation, unless it exists. An existing binding that is not
+// managed for elevation is never taken over.
+func (r *ReconcileElevation) ensureBinding(elevation *managedv1alpha1.Elevation, binding *rbacv1.ClusterRoleBinding) error {
+	defer bindinglock.Lock(bindinglock.ClusterRoleBinding, "", binding.Name)()
+	existing := &rbacv1.ClusterRoleBinding{}
+	err := r.apiClient.Get(context.TODO(), types.NamespacedName{Name: binding.Name}, existing)
+	if err == nil {
+		if !isManagedFor(existing.ObjectMeta, elevation) {
+			return fmt.Errorf("ClusterRoleBinding %s exists and is not managed for the Elevation", binding.Name)
+		}
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return err
+	}
+	return r.client.Create(context.TODO(), binding)
+}
+
+// deleteBindings deletes every ClusterRoleBinding managed for elevation, except those named in keep
+func (r *ReconcileElevation) deleteBindings(elevation *managedv1alpha1.Elevation, keep ...string) error {
+	kept := map[string]bool{}
+	for _, name := range keep {
+		kept[name] = true
+	}
+
+	opts := &client.ListOptions{LabelSelector: labels.SelectorFromSet(managedLabels(elevation))}
+	clusterRoleBindingList := &rbacv1.ClusterRoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), opts, clusterRoleBindingList); err != nil {
+		return err
+	}
+	for i := range clusterRoleBindingList.Items {
+		if !isManagedFor(clusterRoleBindingList.Items[i].ObjectMeta, elevation) || kept[clusterRoleBindingList.Items[i].Name] {
+			continue
+		}
+		unlock := bindinglock.Lock(bindinglock.ClusterRoleBinding, "", clusterRoleBindingList.Items[i].Name)
+		err := r.client.Delete(context.TODO(), &clusterRoleBindingList.Items[i])
+		unlock()
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateStatus records status on elevation, if it changed
+func (r *ReconcileElevation) updateStatus(elevation *managedv1alpha1.Elevation, status managedv1alpha1.ElevationStatus) error {
+	if reflect.DeepEqual(elevation.Status, status) {
+		return nil
+	}
+	elevation.Status = status
+	return r.client.Status().Update(context.TODO(), elevation)
+}
+
+// hasFinalizer returns whether elevation holds the elevation finalizer
+func hasFinalizer(elevation *managedv1alpha1.Elevation) bool {
+	for _, finalizer := range elevation.Finalizers {
+		if finalizer == operatorconfig.ElevationFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFinalizer removes the elevation finalizer from elevation
+func removeFinalizer(elevation *managedv1alpha1.Elevation) {
+	var finalizers []string
+	for _, finalizer := range elevation.Finalizers {
+		if finalizer != operatorconfig.ElevationFinalizer {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	elevation.Finalizers = finalizers
+}
+
+// managedLabels returns the labels of the ClusterRoleBindings managed for elevation. They carry no
+// GroupPermission owner labels, so the bindings are not collected as orphans of a GroupPermission.
+func managedLabels(elevation *managedv1alpha1.Elevation) map[string]string {
+	managedLabels := utility.ManagedByLabels()
+	managedLabels[operatorconfig.ElevationNameLabel] = elevation.Name
+	return managedLabels
+}
+
+// isManagedFor returns whether objectMeta belongs to a ClusterRoleBinding managed for elevation
+func isManagedFor(objectMeta metav1.ObjectMeta, elevation *managedv1alpha1.Elevation) bool {
+	return labels.SelectorFromSet(managedLabels(elevation)).Matches(labels.Set(objectMeta.Labels))
+}
+
+// newClusterRoleBinding returns the ClusterRoleBinding granting clusterRoleName to the requester of elevation.
+// API groups are set as defaulted by the apiserver.
+func newClusterRoleBinding(elevation *managedv1alpha1.Elevation, clusterRoleName string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "elevation-" + elevation.Name + "-" + clusterRoleName,
+			Labels: managedLabels(elevation),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				APIGroup: rbacv1.GroupName,
+				Kind:     rbacv1.UserKind,
+				Name:     elevation.Annotations[operatorconfig.RequesterAnnotation],
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRoleName,
+		},
+	}
+}
@@ -0,0 +1,288 @@
+package elevation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	elevationKey = types.NamespacedName{Namespace: "rbac-permissions-operator", Name: "testElevation"}
+	created      = time.Date(2026, time.October, 15, 9, 0, 0, 0, time.UTC)
+)
+
+func mockElevation(durationHours int) *v1alpha1.Elevation {
+	return &v1alpha1.Elevation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              elevationKey.Name,
+			Namespace:         elevationKey.Namespace,
+			CreationTimestamp: metav1.Time{Time: created},
+			Annotations: map[string]string{
+				operatorconfig.RequesterAnnotation:       "alice",
+				operatorconfig.RequesterGroupsAnnotation: "system:authenticated,exampleGroupName",
+			},
+		},
+		Spec: v1alpha1.ElevationSpec{
+			GroupPermissionName: "testGroupPermission",
+			DurationHours:       durationHours,
+			Justification:       "incident 42",
+		},
+	}
+}
+
+// create a GroupPermission requested by the user accessReviewClient allows to bind any ClusterRole
+func mockGroupPermission(maxElevationHours int, elevationClusterPermissions ...string) *v1alpha1.GroupPermission {
+	return &v1alpha1.GroupPermission{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "testGroupPermission",
+			Namespace:   elevationKey.Namespace,
+			Annotations: map[string]string{operatorconfig.RequesterAnnotation: "cluster-admin"},
+		},
+		Spec: v1alpha1.GroupPermissionSpec{
+			GroupName:                   "exampleGroupName",
+			MaxElevationHours:           maxElevationHours,
+			ElevationClusterPermissions: elevationClusterPermissions,
+		},
+	}
+}
+
+// accessReviewClient answers the SubjectAccessReviews created with it as allowed for the cluster-admin user
+// only, and passes the other requests to the client it wraps
+type accessReviewClient struct {
+	client.Client
+}
+
+func (c *accessReviewClient) Create(ctx context.Context, obj runtime.Object) error {
+	if sar, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		sar.Status.Allowed = sar.Spec.User == "cluster-admin"
+		return nil
+	}
+	return c.Client.Create(ctx, obj)
+}
+
+func newTestReconciler(t *testing.T, objs ...runtime.Object) *ReconcileElevation {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	fakeClient := &accessReviewClient{Client: fake.NewFakeClient(objs...)}
+	return &ReconcileElevation{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+		now:       func() time.Time { return created.Add(time.Minute) },
+	}
+}
+
+// reconcileElevation reconciles the test Elevation and returns the result and the Elevation
+func reconcileElevation(t *testing.T, r *ReconcileElevation) (reconcile.Result, *v1alpha1.Elevation) {
+	result, err := r.Reconcile(reconcile.Request{NamespacedName: elevationKey})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	elevation := &v1alpha1.Elevation{}
+	if err := r.client.Get(context.TODO(), elevationKey, elevation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result, elevation
+}
+
+// roleHolders returns the subjects of the ClusterRoleBindings to the ClusterRole clusterRoleName
+func roleHolders(t *testing.T, r *ReconcileElevation, clusterRoleName string) []rbacv1.Subject {
+	clusterRoleBindingList := &rbacv1.ClusterRoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), &client.ListOptions{}, clusterRoleBindingList); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var subjects []rbacv1.Subject
+	for _, binding := range clusterRoleBindingList.Items {
+		if binding.RoleRef.Name == clusterRoleName {
+			subjects = append(subjects, binding.Subjects...)
+		}
+	}
+	return subjects
+}
+
+// bindingExists returns whether the ClusterRoleBinding name exists
+func bindingExists(t *testing.T, r *ReconcileElevation, name string) bool {
+	err := r.apiClient.Get(context.TODO(), types.NamespacedName{Name: name}, &rbacv1.ClusterRoleBinding{})
+	if err != nil && !errors.IsNotFound(err) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return err == nil
+}
+
+// TestReconcileElevated tests the Reconcile function
+// given: an Elevation allowed by its GroupPermission, reconciled before then after its expiration
+// expected: the ElevationClusterPermissions are held by the requester alone until the expiration, then by nobody
+func TestReconcileElevated(t *testing.T) {
+	r := newTestReconciler(t, mockElevation(2), mockGroupPermission(4, "cluster-reader"))
+	if holders := roleHolders(t, r, "cluster-reader"); len(holders) != 0 {
+		t.Fatalf("expected nobody to hold cluster-reader before the Elevation, got %v", holders)
+	}
+
+	result, elevation := reconcileElevation(t, r)
+	if elevation.Status.State != v1alpha1.ElevationActive || len(elevation.Status.ClusterRoleBindings) != 1 {
+		t.Fatalf("expected the Elevation to be active, got %+v", elevation.Status)
+	}
+	if !elevation.Status.ExpirationTime.Time.Equal(created.Add(2 * time.Hour)) {
+		t.Errorf("expected the Elevation to expire 2 hours after its creation, got %v", elevation.Status.ExpirationTime)
+	}
+	if result.RequeueAfter != 2*time.Hour-time.Minute {
+		t.Errorf("expected a requeue at the expiration, got %v", result.RequeueAfter)
+	}
+	if !hasFinalizer(elevation) {
+		t.Errorf("expected the Elevation to hold the finalizer")
+	}
+
+	binding := &rbacv1.ClusterRoleBinding{}
+	if err := r.apiClient.Get(context.TODO(), types.NamespacedName{Name: elevation.Status.ClusterRoleBindings[0]}, binding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if binding.RoleRef.Name != "cluster-reader" || binding.Subjects[0].Kind != rbacv1.UserKind || binding.Subjects[0].Name != "alice" {
+		t.Errorf("expected cluster-reader granted to the user alice, got %v %v", binding.RoleRef, binding.Subjects)
+	}
+	if holders := roleHolders(t, r, "cluster-reader"); len(holders) != 1 {
+		t.Errorf("expected only alice to hold cluster-reader while the Elevation is active, got %v", holders)
+	}
+
+	r.now = func() time.Time { return created.Add(2 * time.Hour) }
+	result, elevation = reconcileElevation(t, r)
+	if elevation.Status.State != v1alpha1.ElevationExpired || result != (reconcile.Result{}) {
+		t.Errorf("expected the Elevation to expire, got %+v %v", elevation.Status, result)
+	}
+	if holders := roleHolders(t, r, "cluster-reader"); len(holders) != 0 {
+		t.Errorf("expected nobody to hold cluster-reader after the expiration, got %v", holders)
+	}
+	if hasFinalizer(elevation) {
+		t.Errorf("expected the finalizer to be removed on expiration")
+	}
+}
+
+// TestReconcileDenied tests the Reconcile function
+// given: Elevations longer than allowed, to a missing GroupPermission, with no requester, or requested
+// by a user who is not a member of the Group of the GroupPermission, or whose groups were not recorded, or
+// whose GroupPermission has no ElevationClusterPermissions, or was requested by a user who may not bind them
+// expected: the Elevations are denied and no ClusterRoleBinding is created
+func TestReconcileDenied(t *testing.T) {
+	noRequester := mockElevation(1)
+	noRequester.Annotations = nil
+	notMember := mockElevation(1)
+	notMember.Annotations[operatorconfig.RequesterGroupsAnnotation] = "system:authenticated,otherGroupName"
+	noGroups := mockElevation(1)
+	delete(noGroups.Annotations, operatorconfig.RequesterGroupsAnnotation)
+	notBindable := mockGroupPermission(4, "cluster-reader")
+	notBindable.Annotations[operatorconfig.RequesterAnnotation] = "bob"
+	unrecorded := mockGroupPermission(4, "cluster-reader")
+	unrecorded.Annotations = nil
+
+	tests := []struct {
+		label     string
+		elevation *v1alpha1.Elevation
+		objs      []runtime.Object
+	}{
+		{"too long", mockElevation(8), []runtime.Object{mockGroupPermission(4, "cluster-reader")}},
+		{"not allowed", mockElevation(1), []runtime.Object{mockGroupPermission(0, "cluster-reader")}},
+		{"missing GroupPermission", mockElevation(1), nil},
+		{"no requester", noRequester, []runtime.Object{mockGroupPermission(4, "cluster-reader")}},
+		{"not a member of the Group", notMember, []runtime.Object{mockGroupPermission(4, "cluster-reader")}},
+		{"no recorded groups", noGroups, []runtime.Object{mockGroupPermission(4, "cluster-reader")}},
+		{"no elevation ClusterPermissions", mockElevation(1), []runtime.Object{mockGroupPermission(4)}},
+		{"requester of the GroupPermission not allowed to bind", mockElevation(1), []runtime.Object{notBindable}},
+		{"no requester of the GroupPermission", mockElevation(1), []runtime.Object{unrecorded}},
+	}
+	for _, test := range tests {
+		r := newTestReconciler(t, append(test.objs, test.elevation)...)
+		_, elevation := reconcileElevation(t, r)
+		if elevation.Status.State != v1alpha1.ElevationDenied || elevation.Status.Message == "" {
+			t.Errorf("%s: expected the Elevation to be denied, got %+v", test.label, elevation.Status)
+		}
+		if bindingExists(t, r, newClusterRoleBinding(elevation, "cluster-reader").Name) {
+			t.Errorf("%s: expected no ClusterRoleBinding", test.label)
+		}
+	}
+}
+
+// TestReconcileGroupPermissionChanged tests the Reconcile function
+// given: an active Elevation whose GroupPermission changed its ElevationClusterPermissions, then stopped allowing
+// Elevations
+// expected: the ClusterRoleBindings follow the ElevationClusterPermissions, then they are all revoked
+func TestReconcileGroupPermissionChanged(t *testing.T) {
+	groupPermission := mockGroupPermission(4, "cluster-reader")
+	r := newTestReconciler(t, mockElevation(1), groupPermission)
+	_, elevation := reconcileElevation(t, r)
+	reader := newClusterRoleBinding(elevation, "cluster-reader").Name
+
+	groupPermission.Spec.ElevationClusterPermissions = []string{"cluster-monitoring-view"}
+	if err := r.client.Update(context.TODO(), groupPermission); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, elevation = reconcileElevation(t, r)
+	monitoring := newClusterRoleBinding(elevation, "cluster-monitoring-view").Name
+	if bindingExists(t, r, reader) || !bindingExists(t, r, monitoring) {
+		t.Errorf("expected only the binding of cluster-monitoring-view, got %v", elevation.Status.ClusterRoleBindings)
+	}
+
+	groupPermission.Spec.MaxElevationHours = 0
+	if err := r.client.Update(context.TODO(), groupPermission); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, elevation = reconcileElevation(t, r)
+	if elevation.Status.State != v1alpha1.ElevationDenied || bindingExists(t, r, monitoring) {
+		t.Errorf("expected the Elevation to be revoked, got %+v", elevation.Status)
+	}
+}
+
+// TestReconcileDeleted tests the Reconcile function
+// given: an active Elevation being deleted
+// expected: its ClusterRoleBindings are deleted and the finalizer removed
+func TestReconcileDeleted(t *testing.T) {
+	r := newTestReconciler(t, mockElevation(1), mockGroupPermission(4, "cluster-reader"))
+	_, elevation := reconcileElevation(t, r)
+
+	elevation.DeletionTimestamp = &metav1.Time{Time: created.Add(time.Minute)}
+	if err := r.client.Update(context.TODO(), elevation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, elevation = reconcileElevation(t, r)
+	if bindingExists(t, r, newClusterRoleBinding(elevation, "cluster-reader").Name) {
+		t.Errorf("expected the ClusterRoleBinding to be deleted")
+	}
+	if hasFinalizer(elevation) {
+		t.Errorf("expected the finalizer to be removed")
+	}
+}
+
+// TestIsManagedFor tests the isManagedFor function
+// given: ClusterRoleBindings of the Elevation, of another Elevation, and of a GroupPermission
+// expected: only the ClusterRoleBinding of the Elevation is managed for it
+func TestIsManagedFor(t *testing.T) {
+	elevation := mockElevation(1)
+	other := mockElevation(1)
+	other.Name = "otherElevation"
+
+	if !isManagedFor(newClusterRoleBinding(elevation, "view").ObjectMeta, elevation) {
+		t.Errorf("expected the binding of the Elevation to be managed for it")
+	}
+	if isManagedFor(newClusterRoleBinding(other, "view").ObjectMeta, elevation) {
+		t.Errorf("expected the binding of another Elevation not to be managed for it")
+	}
+	groupPermissionBinding := metav1.ObjectMeta{Labels: map[string]string{operatorconfig.ManagedByLabel: operatorconfig.OperatorName}}
+	if isManagedFor(groupPermissionBinding, elevation) {
+		t.Errorf("expected the binding of a GroupPermission not to be managed for the Elevation")
+	}
+}
@@ -0,0 +1,124 @@
+package grouppermission
+
+import (
+	"context"
+	"sync"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// apiBudget counts the requests issued by the clients of the reconciler, by source and verb, between
+// two reports. Reconciles are serialized, so the counts of a report are those of a single reconcile,
+// along with the requests of the watchdog and the reaper running meanwhile.
+type apiBudget struct {
+	mutex  sync.Mutex
+	counts map[string]map[string]int
+}
+
+// add counts a request of verb served by source, a nil budget counts nothing
+func (b *apiBudget) add(source, verb string) {
+	if b == nil {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.counts == nil {
+		b.counts = map[string]map[string]int{}
+	}
+	if b.counts[source] == nil {
+		b.counts[source] = map[string]int{}
+	}
+	b.counts[source][verb]++
+}
+
+// take returns the requests counted since the last call, by source and verb, and resets the counts
+func (b *apiBudget) take() map[string]map[string]int {
+	if b == nil {
+		return nil
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	counts := b.counts
+	b.counts = nil
+	return counts
+}
+
+// countingClient counts the requests of a client in budget. Reads are served by readSource, writes
+// always reach the apiserver.
+type countingClient struct {
+	client.Client
+	budget     *apiBudget
+	readSource string
+}
+
+// countRequests returns c, counting its requests in budget
+func countRequests(c client.Client, budget *apiBudget, readSource string) client.Client {
+	return &countingClient{Client: c, budget: budget, readSource: readSource}
+}
+
+// Get implements client.Reader
+func (c *countingClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	c.budget.add(c.readSource, "get")
+	return c.Client.Get(ctx, key, obj)
+}
+
+// List implements client.Reader
+func (c *countingClient) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	c.budget.add(c.readSource, "list")
+	return c.Client.List(ctx, opts, list)
+}
+
+// Create implements client.Writer
+func (c *countingClient) Create(ctx context.Context, obj runtime.Object) error {
+	c.budget.add(localmetrics.SourceAPIServer, "create")
+	return c.Client.Create(ctx, obj)
+}
+
+// Update implements client.Writer
+func (c *countingClient) Update(ctx context.Context, obj runtime.Object) error {
+	c.budget.add(localmetrics.SourceAPIServer, "update")
+	return c.Client.Update(ctx, obj)
+}
+
+// Delete implements client.Writer
+func (c *countingClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOptionFunc) error {
+	c.budget.add(localmetrics.SourceAPIServer, "delete")
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// Status implements client.StatusClient
+func (c *countingClient) Status() client.StatusWriter {
+	return &countingStatusWriter{StatusWriter: c.Client.Status(), budget: c.budget}
+}
+
+// countingStatusWriter counts the status updates of a client in budget
+type countingStatusWriter struct {
+	client.StatusWriter
+	budget *apiBudget
+}
+
+// Update implements client.StatusWriter
+func (w *countingStatusWriter) Update(ctx context.Context, obj runtime.Object) error {
+	w.budget.add(localmetrics.SourceAPIServer, "update")
+	return w.StatusWriter.Update(ctx, obj)
+}
+
+// reportAPIRequests records the requests issued by the reconcile of request in the metrics, and logs
+// them at debug level
+func (r *ReconcileGroupPermission) reportAPIRequests(request reconcile.Request) {
+	if r.budget == nil {
+		return
+	}
+	counts := r.budget.take()
+	localmetrics.ObserveReconcileAPIRequests(counts)
+
+	keysAndValues := []interface{}{"Request.Namespace", request.Namespace, "Request.Name", request.Name}
+	for _, source := range []string{localmetrics.SourceCache, localmetrics.SourceAPIServer} {
+		keysAndValues = append(keysAndValues, source, counts[source])
+	}
+	log.V(1).Info("API requests of the reconcile", keysAndValues...)
+}
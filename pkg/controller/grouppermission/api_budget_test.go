@@ -0,0 +1,70 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestCountingClient tests the requests counted by countRequests
+// given: a client reading from the cache and one reading from the apiserver, sharing a budget
+// expected: the reads are counted by source and the writes, including status updates, as apiserver requests
+func TestCountingClient(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	groupPermission := mockGroupPermission()
+	fakeClient := fake.NewFakeClient(groupPermission)
+	budget := &apiBudget{}
+	cacheClient := countRequests(fakeClient, budget, localmetrics.SourceCache)
+	apiClient := countRequests(fakeClient, budget, localmetrics.SourceAPIServer)
+
+	ctx := context.TODO()
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	binding := &v1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "view-exampleGroupName"}}
+	for _, err := range []error{
+		cacheClient.Get(ctx, key, groupPermission),
+		cacheClient.List(ctx, &client.ListOptions{}, &v1.ClusterRoleBindingList{}),
+		apiClient.Get(ctx, types.NamespacedName{Name: "view"}, &v1.ClusterRole{}),
+		cacheClient.Create(ctx, binding),
+		cacheClient.Delete(ctx, binding),
+		cacheClient.Status().Update(ctx, groupPermission),
+	} {
+		if err != nil && !errors.IsNotFound(err) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := map[string]map[string]int{
+		localmetrics.SourceCache:     {"get": 1, "list": 1},
+		localmetrics.SourceAPIServer: {"get": 1, "create": 1, "delete": 1, "update": 1},
+	}
+	if counts := budget.take(); !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Mismatch for counts. Expected(%v), Found(%v)", expected, counts)
+	}
+	if counts := budget.take(); counts != nil {
+		t.Errorf("Mismatch for counts after take. Expected(nil), Found(%v)", counts)
+	}
+}
+
+// TestAPIBudgetNil tests the apiBudget of a reconciler without one
+// given: a nil budget
+// expected: nothing is counted and nothing is returned
+func TestAPIBudgetNil(t *testing.T) {
+	var budget *apiBudget
+	budget.add(localmetrics.SourceAPIServer, "get")
+	if counts := budget.take(); counts != nil {
+		t.Errorf("Mismatch for counts. Expected(nil), Found(%v)", counts)
+	}
+}
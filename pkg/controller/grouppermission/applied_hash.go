@@ -0,0 +1,50 @@
+package grouppermission
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// desiredStateHash returns a hash of the RBAC bindings groupPermission resolves to in the Namespaces
+// of namespaceList not being deleted. It changes whenever the spec, or the Namespaces it matches, change what is granted.
+func desiredStateHash(groupPermission *managedv1alpha1.GroupPermission, namespaceList *corev1.NamespaceList) string {
+	var bindings []string
+	for _, name := range buildClusterRoleBindingCRList(groupPermission) {
+		bindings = append(bindings, "ClusterRoleBinding "+name)
+	}
+	for _, permission := range groupPermission.Spec.Permissions {
+		for _, namespace := range namespaceList.Items {
+			if isPermissionAllowed(permission, &namespace) && !isNamespaceTerminating(&namespace) {
+				roleBinding := newRoleBinding(namespace.Name, permissionRoleRef(&groupPermission.Spec, permission), utility.GroupSubject(&groupPermission.Spec))
+				bindings = append(bindings, "RoleBinding "+roleBinding.Namespace+"/"+roleBinding.Name)
+				if len(permission.Rules) > 0 {
+					// the rules of the Roles created by the operator are granted as well
+					bindings = append(bindings, fmt.Sprintf("Role %s/%s %v", namespace.Name, permission.RoleName, permission.Rules))
+				}
+			}
+		}
+	}
+	// the hash must not depend on the order of the spec or of the list
+	sort.Strings(bindings)
+
+	sum := sha256.Sum256([]byte(strings.Join(bindings, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// isNamespaceStatusConverged returns whether every RoleBinding of namespaceStatuses was created
+func isNamespaceStatusConverged(namespaceStatuses []managedv1alpha1.NamespaceStatus) bool {
+	for _, namespaceStatus := range namespaceStatuses {
+		if namespaceStatus.State != managedv1alpha1.GroupPermissionCreated {
+			return false
+		}
+	}
+	return true
+}
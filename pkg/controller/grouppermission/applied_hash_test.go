@@ -0,0 +1,105 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestDesiredStateHash tests the desiredStateHash function
+// given: a GroupPermission and Namespaces, reordered or with an added allowed Namespace, and changed rules
+// expected: the same hash regardless of order, a different hash once more is granted
+func TestDesiredStateHash(t *testing.T) {
+	groupPermission := mockNamespacedGroupPermission()
+	namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{*mockNamespace("team-a"), *mockNamespace("team-b"), *mockNamespace("default")}}
+	hash := desiredStateHash(groupPermission, namespaceList)
+
+	reordered := mockNamespacedGroupPermission()
+	reordered.Spec.ClusterPermissions = []string{"exampleClusterRoleNameTwo", "exampleClusterRoleName"}
+	reorderedList := &corev1.NamespaceList{Items: []corev1.Namespace{*mockNamespace("default"), *mockNamespace("team-b"), *mockNamespace("team-a")}}
+	if h := desiredStateHash(reordered, reorderedList); h != hash {
+		t.Errorf("expected the same hash for a reordered spec and list, got %s, want %s", h, hash)
+	}
+
+	namespaceList.Items = append(namespaceList.Items, *mockNamespace("team-secret"))
+	if h := desiredStateHash(groupPermission, namespaceList); h != hash {
+		t.Errorf("expected a denied Namespace not to change the hash")
+	}
+	namespaceList.Items = append(namespaceList.Items, *mockNamespace("team-c"))
+	if h := desiredStateHash(groupPermission, namespaceList); h == hash {
+		t.Errorf("expected an allowed Namespace to change the hash")
+	}
+
+	rules := mockRulesGroupPermission()
+	rulesHash := desiredStateHash(rules, namespaceList)
+	rules.Spec.Permissions[0].Rules[0].Verbs = append(rules.Spec.Permissions[0].Rules[0].Verbs, "delete")
+	if h := desiredStateHash(rules, namespaceList); h == rulesHash {
+		t.Errorf("expected changed rules to change the hash")
+	}
+}
+
+// TestReconcileLastAppliedHash tests that Reconcile records the last applied hash
+// given: GroupPermission with a Permission allowed in a Namespace
+// expected: the RoleBinding is created and the GroupPermission annotated with the hash of its desired state
+func TestReconcileLastAppliedHash(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	groupPermission.Finalizers = []string{operatorconfig.GroupPermissionFinalizer}
+	adminClusterRole := mockClusterRole()
+	adminClusterRole.Name = "admin"
+	fakeClient := newRequesterClient(groupPermission, adminClusterRole, mockNamespace("team-a"))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	if _, err := reconciler.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reconciled := &v1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), key, reconciled); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{*mockNamespace("team-a")}}
+	if hash := reconciled.Annotations[operatorconfig.LastAppliedHashAnnotation]; hash != desiredStateHash(groupPermission, namespaceList) {
+		t.Errorf("expected the last applied hash of the desired state, got %q", hash)
+	}
+
+	roleBinding := &rbacv1.RoleBinding{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "admin-exampleGroupName"}, roleBinding); err != nil {
+		t.Errorf("expected the RoleBinding of team-a: %v", err)
+	}
+}
+
+// TestIsNamespaceStatusConverged tests the isNamespaceStatusConverged function
+// given: NamespaceStatuses with and without a failure
+// expected: converged only when every RoleBinding was created
+func TestIsNamespaceStatusConverged(t *testing.T) {
+	namespaceStatuses := []v1alpha1.NamespaceStatus{
+		{Namespace: "team-a", State: v1alpha1.GroupPermissionCreated},
+	}
+	if !isNamespaceStatusConverged(namespaceStatuses) {
+		t.Errorf("expected created RoleBindings to be converged")
+	}
+	namespaceStatuses = append(namespaceStatuses, v1alpha1.NamespaceStatus{Namespace: "team-b", State: v1alpha1.GroupPermissionFailed})
+	if isNamespaceStatusConverged(namespaceStatuses) {
+		t.Errorf("expected a failed RoleBinding not to be converged")
+	}
+}
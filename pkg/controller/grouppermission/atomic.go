@@ -0,0 +1,184 @@
+package grouppermission
+
+import (
+	"context"
+	goerrors "errors"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// pendingRoleBinding is a RoleBinding of an atomic GroupPermission checked and not created yet
+type pendingRoleBinding struct {
+	roleBinding *v1.RoleBinding
+	namespace   corev1.Namespace
+	permission  managedv1alpha1.Permission
+	// status is the index of the state of the RoleBinding in the namespace statuses
+	status int
+}
+
+// checkAtomicClusterPermissions checks the ClusterRoleBindings the atomic groupPermission has yet to create,
+// before any of its bindings is created, and returns them by ClusterRole name with the name of the ClusterRole
+// whose ClusterRoleBinding fails its checks, empty when none does. A missing ClusterRole and an existing
+// ClusterRoleBinding of the name not managed for groupPermission or granting another role fail, like the
+// checks of checkClusterPermission. The ClusterRoleBindings of a foreign operator grant the role instead.
+func (r *ReconcileGroupPermission) checkAtomicClusterPermissions(groupPermission *managedv1alpha1.GroupPermission, clusterRoleList *v1.ClusterRoleList, undelegable map[string]bool, policy *policyClient, grants *grantDecider, operatorConfig *operatorconfig.OperatorConfig) (map[string]*v1.ClusterRoleBinding, string, error) {
+	foreign, err := r.listForeignBindings(groupPermission, operatorConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	checked := map[string]*v1.ClusterRoleBinding{}
+	for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+		desired := clusterPermissionBinding(&groupPermission.Spec, clusterRoleName)
+		existing := &v1.ClusterRoleBinding{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: desired.Name}, existing)
+		if err == nil {
+			if utility.IsManagedFor(existing.ObjectMeta, groupPermission.Namespace, groupPermission.Name) &&
+				semanticallyEqual(verifiedBinding{roleRef: existing.RoleRef, subjects: existing.Subjects}, verifiedBinding{roleRef: desired.RoleRef, subjects: desired.Subjects}) {
+				continue
+			}
+			conflict := &ConflictError{Resource: "clusterrolebindings", Name: desired.Name, Err: goerrors.New("exists and is not the one of the GroupPermission")}
+			updateCondition(groupPermission, conflict.Error(), clusterRoleName, true, managedv1alpha1.GroupPermissionFailed, errorReason(conflict))
+			return nil, clusterRoleName, r.updateStatus(groupPermission)
+		}
+		if !errors.IsNotFound(err) {
+			return nil, "", err
+		}
+		if _, ok := foreign.conflict("", desired.Name, desired.RoleRef); ok {
+			continue
+		}
+
+		clusterRole := findClusterRole(clusterRoleName, clusterRoleList)
+		if clusterRole == nil {
+			roleErr := &RoleNotFoundError{ClusterRoleName: clusterRoleName}
+			updateCondition(groupPermission, roleErr.Error(), clusterRoleName, true, managedv1alpha1.GroupPermissionFailed, errorReason(roleErr))
+			return nil, clusterRoleName, r.updateStatus(groupPermission)
+		}
+		clusterRoleBinding, err := r.checkClusterPermission(groupPermission, clusterRole, undelegable, policy, grants, operatorConfig)
+		if err != nil {
+			return nil, "", err
+		}
+		if clusterRoleBinding == nil {
+			return nil, clusterRoleName, nil
+		}
+		checked[clusterRoleName] = clusterRoleBinding
+	}
+	return checked, "", nil
+}
+
+// applyAtomic creates the pending RoleBindings of groupPermission in order when none of namespaceStatuses
+// failed, and stops at the first creation failing, recording its error in namespaceStatuses. Nothing
+// is created when a RoleBinding failed its checks, those created before are rolled back by rollbackAtomic.
+func (r *ReconcileGroupPermission) applyAtomic(groupPermission *managedv1alpha1.GroupPermission, namespaceStatuses []managedv1alpha1.NamespaceStatus, pending []pendingRoleBinding) {
+	if len(pending) == 0 || failedBinding(namespaceStatuses) != "" {
+		return
+	}
+	for i := range pending {
+		p := &pending[i]
+		if err := r.createRoleBinding(groupPermission, &p.namespace, p.permission, p.roleBinding); err != nil {
+			namespaceStatuses[p.status].State = managedv1alpha1.GroupPermissionFailed
+			namespaceStatuses[p.status].LastError = err.Error()
+			return
+		}
+	}
+}
+
+// failedBinding describes the first RoleBinding of namespaceStatuses that failed, empty when none did
+func failedBinding(namespaceStatuses []managedv1alpha1.NamespaceStatus) string {
+	for _, namespaceStatus := range namespaceStatuses {
+		if isBindingFailed(namespaceStatus.State) {
+			return "RoleBinding " + namespaceStatus.Namespace + "/" + namespaceStatus.BindingName + ": " + namespaceStatus.LastError
+		}
+	}
+	return ""
+}
+
+// rolledBackMessage returns the message of the AtomicRollback condition of the bindings rolled back because
+// of failure
+func rolledBackMessage(failure string) string {
+	return "Bindings rolled back, the atomic GroupPermission cannot apply " + failure
+}
+
+// rollBackStatuses marks the RoleBindings of namespaceStatuses that did not fail as rolled back because
+// of failure, and returns whether any was
+func rollBackStatuses(namespaceStatuses []managedv1alpha1.NamespaceStatus, failure string) bool {
+	changed := false
+	for i := range namespaceStatuses {
+		if isBindingFailed(namespaceStatuses[i].State) {
+			continue
+		}
+		namespaceStatuses[i].State = managedv1alpha1.GroupPermissionFailed
+		namespaceStatuses[i].LastError = rolledBackMessage(failure)
+		changed = true
+	}
+	return changed
+}
+
+// rollbackAtomic deletes every binding managed for the atomic groupPermission because of failure, the
+// binding it cannot apply, so it grants nothing rather than part of its permissions. The AtomicRollback
+// condition reports the failure until all of its bindings are applied, see clearAtomicRollback. The
+// bindings are deleted right away, the removal grace period of the operator does not hold them.
+func (r *ReconcileGroupPermission) rollbackAtomic(groupPermission *managedv1alpha1.GroupPermission, failure string) error {
+	clusterRoleBindingList, roleBindingList, err := r.listManagedBindings(groupPermission)
+	if err != nil {
+		return err
+	}
+	managed, _ := managedBindingsOf(groupPermission, clusterRoleBindingList, roleBindingList)
+	if len(managed) > 0 {
+		if err := r.deleteManagedBindings(groupPermission); err != nil {
+			return err
+		}
+		log.Info("Rolled back the bindings of the atomic GroupPermission", "Request.Namespace", groupPermission.Namespace, "Request.Name", groupPermission.Name,
+			"Count", len(managed), "Failure", failure)
+	}
+
+	changed := rollBackStatuses(groupPermission.Status.Namespaces, failure)
+	if summary := groupPermission.Status.NamespaceSummary; summary != nil && summary.Created > 0 {
+		summary.Failed += summary.Created
+		summary.Created = 0
+		changed = true
+	}
+	message := rolledBackMessage(failure)
+	if !hasActiveCondition(groupPermission, message, managedv1alpha1.GroupPermissionFailed, managedv1alpha1.ReasonAtomicRollback) {
+		// the condition of a previous failure is replaced
+		conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonAtomicRollback)
+		updateCondition(groupPermission, message, "", true, managedv1alpha1.GroupPermissionFailed, managedv1alpha1.ReasonAtomicRollback)
+		changed = true
+	}
+	if changed {
+		if err := r.updateStatus(groupPermission); err != nil {
+			return err
+		}
+	}
+	if len(managed) > 0 {
+		r.eventf(groupPermission, corev1.EventTypeWarning, string(managedv1alpha1.ReasonAtomicRollback), "Rolled back %d bindings, the atomic GroupPermission cannot apply %s", len(managed), failure)
+	}
+	return nil
+}
+
+// rollbackClusterPermission rolls back the bindings of the atomic groupPermission because of the
+// ClusterRoleBinding of clusterRoleName, whose condition describes why it cannot be applied
+func (r *ReconcileGroupPermission) rollbackClusterPermission(groupPermission *managedv1alpha1.GroupPermission, clusterRoleName string) error {
+	failure := "ClusterRoleBinding " + clusterPermissionBinding(&groupPermission.Spec, clusterRoleName).Name
+	if condition := conditions.Get(groupPermission.Status.Conditions, clusterRoleName); condition != nil {
+		failure += ": " + condition.Message
+	}
+	return r.rollbackAtomic(groupPermission, failure)
+}
+
+// clearAtomicRollback deactivates the AtomicRollback condition of groupPermission once all of its bindings are
+// applied, or once it is no longer atomic
+func (r *ReconcileGroupPermission) clearAtomicRollback(groupPermission *managedv1alpha1.GroupPermission) error {
+	if !conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonAtomicRollback) {
+		return nil
+	}
+	conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonAtomicRollback)
+	return r.updateStatus(groupPermission)
+}
@@ -0,0 +1,267 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestReconcileNamespacePermissionsAtomic tests the reconcileNamespacePermissions function of an atomic GroupPermission
+// given: an atomic GroupPermission binding admin in a Namespace excluding admin and in another one, then
+// in two Namespaces allowing it
+// expected: no RoleBinding is created while one of them fails its checks, both are created otherwise
+func TestReconcileNamespacePermissionsAtomic(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	var tests = []struct {
+		name       string
+		namespaces []*corev1.Namespace
+		created    bool
+	}{
+		{"one RoleBinding excluded", []*corev1.Namespace{mockExcludeRolesNamespace("team-a", "admin"), mockNamespace("team-b")}, false},
+		{"every RoleBinding allowed", []*corev1.Namespace{mockNamespace("team-a"), mockNamespace("team-b")}, true},
+	}
+	for _, test := range tests {
+		groupPermission := mockNamespacedGroupPermission()
+		groupPermission.Spec.Atomic = true
+		fakeClient := newRequesterClient(test.namespaces[0], test.namespaces[1])
+		reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+
+		namespaceStatuses, err := reconciler.reconcileNamespacePermissions(groupPermission, nil, nil, nil, operatorconfig.DefaultOperatorConfig())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if len(namespaceStatuses) != 2 {
+			t.Fatalf("%s: Mismatch for namespace statuses. Expected(2), Found(%+v)", test.name, namespaceStatuses)
+		}
+		if failed := failedBinding(namespaceStatuses) != ""; failed == test.created {
+			t.Errorf("%s: Mismatch for failed binding. Expected(%t), Found(%t)", test.name, !test.created, failed)
+		}
+
+		roleBinding := &v1.RoleBinding{}
+		err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-b", Name: namespaceStatuses[1].BindingName}, roleBinding)
+		if created := err == nil; created != test.created {
+			t.Errorf("%s: Mismatch for RoleBinding of team-b created. Expected(%t), Found(%t, %v)", test.name, test.created, created, err)
+		}
+		if groupPermission.Status.Rollout != nil {
+			t.Errorf("%s: Mismatch for rollout checkpoint. Expected(nil), Found(%+v)", test.name, groupPermission.Status.Rollout)
+		}
+	}
+}
+
+// TestCheckAtomicClusterPermissions tests the checkAtomicClusterPermissions function
+// given: an atomic GroupPermission granting view, with view missing, denied to the requester, bound by a
+// ClusterRoleBinding not managed for it, already bound for it, and allowed
+// expected: the ClusterRoleBinding to create when allowed, nothing to create when already bound, and the
+// view ClusterRole failed with its condition otherwise
+func TestCheckAtomicClusterPermissions(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	view := &v1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "view"},
+		Rules:      []v1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+	}
+	binding := func(managed bool) *v1.ClusterRoleBinding {
+		groupPermission := mockNamespacedGroupPermission()
+		clusterRoleBinding := clusterPermissionBinding(&groupPermission.Spec, "view")
+		if managed {
+			utility.SetManagedLabels(&clusterRoleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+		}
+		return clusterRoleBinding
+	}
+	var tests = []struct {
+		name      string
+		requester string
+		objs      []runtime.Object
+		checked   bool
+		reason    v1alpha1.ConditionReason
+	}{
+		{"missing ClusterRole", "cluster-admin", nil, false, v1alpha1.ReasonClusterRoleMissing},
+		{"escalation denied", "bob", []runtime.Object{view}, false, v1alpha1.ReasonEscalationDenied},
+		{"conflict", "cluster-admin", []runtime.Object{view, binding(false)}, false, v1alpha1.ReasonBindingConflict},
+		{"already bound", "cluster-admin", []runtime.Object{view, binding(true)}, false, ""},
+		{"allowed", "cluster-admin", []runtime.Object{view}, true, ""},
+	}
+	for _, test := range tests {
+		groupPermission := mockNamespacedGroupPermission()
+		groupPermission.Annotations[operatorconfig.RequesterAnnotation] = test.requester
+		groupPermission.Spec.Atomic = true
+		groupPermission.Spec.ClusterPermissions = []string{"view"}
+		fakeClient := newRequesterClient(append(test.objs, groupPermission.DeepCopy())...)
+		reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+		clusterRoleList := &v1.ClusterRoleList{}
+		for _, obj := range test.objs {
+			if clusterRole, ok := obj.(*v1.ClusterRole); ok {
+				clusterRoleList.Items = append(clusterRoleList.Items, *clusterRole)
+			}
+		}
+
+		checked, failedRole, err := reconciler.checkAtomicClusterPermissions(groupPermission, clusterRoleList, nil, nil, nil, operatorconfig.DefaultOperatorConfig())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if _, ok := checked["view"]; ok != test.checked {
+			t.Errorf("%s: Mismatch for the ClusterRoleBinding to create. Expected(%t), Found(%t)", test.name, test.checked, ok)
+		}
+		if failed := failedRole != ""; failed != (test.reason != "") {
+			t.Errorf("%s: Mismatch for the failed ClusterRole. Expected(%t), Found(%q)", test.name, test.reason != "", failedRole)
+		}
+		if condition := conditions.Get(groupPermission.Status.Conditions, "view"); test.reason != "" && (condition == nil || condition.Reason != test.reason) {
+			t.Errorf("%s: Mismatch for the condition of view. Expected(%s), Found(%+v)", test.name, test.reason, condition)
+		}
+	}
+}
+
+// TestReconcileAtomicClusterPermissions tests the Reconcile function of an atomic GroupPermission
+// given: an atomic GroupPermission binding admin in team-a and view cluster wide, whose ClusterRoleBinding
+// exists and is not managed for it
+// expected: the RoleBinding of team-a is never created
+func TestReconcileAtomicClusterPermissions(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.Atomic = true
+	groupPermission.Spec.ClusterPermissions = []string{"view"}
+	conflicting := clusterPermissionBinding(&groupPermission.Spec, "view")
+	fakeClient := newRequesterClient(groupPermission, mockNamespace("team-a"), conflicting,
+		&v1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "admin"}}, &v1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "view"}})
+	var calls []apiCall
+	reconciler := &ReconcileGroupPermission{
+		client:         &recordingClient{Client: fakeClient, cached: true, calls: &calls},
+		apiClient:      &recordingClient{Client: fakeClient, calls: &calls},
+		scheme:         scheme.Scheme,
+		recorder:       record.NewFakeRecorder(10),
+		operatorConfig: operatorconfig.DefaultOperatorConfig(),
+	}
+
+	_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, call := range calls {
+		if call.resource == "rolebindings" && call.verb == "create" {
+			t.Errorf("Mismatch for the RoleBinding of team-a. Expected(not created), Found(%+v)", call)
+		}
+	}
+}
+
+// TestRollbackAtomic tests the rollbackAtomic and clearAtomicRollback functions
+// given: an atomic GroupPermission with a managed ClusterRoleBinding and RoleBinding, a RoleBinding not
+// managed by the operator, and a RoleBinding that cannot be applied
+// expected: the managed bindings are deleted, the other is kept, the statuses of the applied RoleBindings
+// are rolled back and the GroupPermission is Failed with an AtomicRollback condition and event until
+// the condition is cleared
+func TestRollbackAtomic(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.Atomic = true
+	groupPermission.Spec.ClusterPermissions = []string{"view"}
+	groupPermission.Status.Namespaces = []v1alpha1.NamespaceStatus{
+		{Namespace: "team-a", ClusterRoleName: "admin", BindingName: "admin-exampleGroupName", State: v1alpha1.GroupPermissionCreated},
+	}
+	managedRoleBinding := newRoleBinding("team-a", v1.RoleRef{Kind: "ClusterRole", Name: "admin"}, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&managedRoleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	managedClusterRoleBinding := clusterPermissionBinding(&groupPermission.Spec, "view")
+	utility.SetManagedLabels(&managedClusterRoleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	unmanaged := newRoleBinding("team-c", v1.RoleRef{Kind: "ClusterRole", Name: "admin"}, utility.GroupSubject(&groupPermission.Spec))
+	fakeClient := fake.NewFakeClient(groupPermission, managedRoleBinding, managedClusterRoleBinding, unmanaged)
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme, recorder: recorder}
+
+	failure := "RoleBinding team-b/admin-exampleGroupName: denied by policy"
+	if err := reconciler.rollbackAtomic(groupPermission, failure); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: managedRoleBinding.Name}, &v1.RoleBinding{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Mismatch for managed RoleBinding. Expected(deleted), Found(%v)", err)
+	}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: managedClusterRoleBinding.Name}, &v1.ClusterRoleBinding{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("Mismatch for managed ClusterRoleBinding. Expected(deleted), Found(%v)", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-c", Name: unmanaged.Name}, &v1.RoleBinding{}); err != nil {
+		t.Errorf("Mismatch for unmanaged RoleBinding. Expected(kept), Found(%v)", err)
+	}
+
+	namespaceStatus := groupPermission.Status.Namespaces[0]
+	if namespaceStatus.State != v1alpha1.GroupPermissionFailed || !strings.Contains(namespaceStatus.LastError, failure) {
+		t.Errorf("Mismatch for namespace status. Expected(Failed, rolled back), Found(%+v)", namespaceStatus)
+	}
+	if !hasActiveCondition(groupPermission, rolledBackMessage(failure), v1alpha1.GroupPermissionFailed, v1alpha1.ReasonAtomicRollback) {
+		t.Errorf("Mismatch for conditions. Expected(AtomicRollback), Found(%+v)", groupPermission.Status.Conditions)
+	}
+	if phase := groupPermissionPhase(groupPermission, true); phase != v1alpha1.GroupPermissionPhaseFailed {
+		t.Errorf("Mismatch for phase. Expected(%s), Found(%s)", v1alpha1.GroupPermissionPhaseFailed, phase)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "Rolled back 2 bindings") {
+			t.Errorf("Mismatch for event. Expected(Rolled back 2 bindings), Found(%s)", event)
+		}
+	default:
+		t.Error("Mismatch for event. Expected(AtomicRollback), Found(none)")
+	}
+
+	// nothing is left to roll back, the same failure records no event
+	if err := reconciler.rollbackAtomic(groupPermission, failure); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("Mismatch for events. Expected(none), Found(%s)", <-recorder.Events)
+	}
+
+	if err := reconciler.clearAtomicRollback(groupPermission); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conditions.IsTrue(groupPermission.Status.Conditions, v1alpha1.ReasonAtomicRollback) {
+		t.Errorf("Mismatch for conditions. Expected(AtomicRollback inactive), Found(%+v)", groupPermission.Status.Conditions)
+	}
+}
+
+// TestRolloutAtomic tests the rollout of an atomic GroupPermission
+// given: an atomic GroupPermission with the checkpoint of the same desired state in its status
+// expected: the checkpoint is not resumed and no checkpoint is due
+func TestRolloutAtomic(t *testing.T) {
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.Atomic = true
+	namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{*mockNamespace("team-a")}}
+	groupPermission.Status.Rollout = &v1alpha1.RolloutCheckpoint{Hash: desiredStateHash(groupPermission, namespaceList), Namespace: "team-a", Applied: 1}
+
+	progress := newRollout(groupPermission, namespaceList)
+	if progress.done(0, "team-a") {
+		t.Error("Mismatch for done. Expected(false), Found(true)")
+	}
+	for i := 0; i < rolloutCheckpointInterval; i++ {
+		if progress.record(0, "team-a", true) {
+			t.Fatal("Mismatch for checkpoint due. Expected(false), Found(true)")
+		}
+	}
+}
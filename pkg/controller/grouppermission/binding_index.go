@@ -0,0 +1,100 @@
+package grouppermission
+
+import (
+	"context"
+	"sync/atomic"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// ownerUIDField is the field index of the managed RoleBindings by the UID of the GroupPermission they
+// are managed for, as recorded in their source UID annotation
+const ownerUIDField = "managed.ownerUID"
+
+// bindingIndex caches the RoleBindings of every Namespace indexed by owning GroupPermission, so the
+// RoleBindings of a GroupPermission are found without listing every RoleBinding of the cluster. The
+// manager cache only holds the watched namespace, so the index has a cache of its own.
+type bindingIndex struct {
+	cache cache.Cache
+	// synced is set once the cache synced, the RoleBindings are listed from the apiserver until then
+	synced int32
+}
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=list;watch
+
+// newBindingIndex returns a bindingIndex over the RoleBindings of the cluster, started with mgr
+func newBindingIndex(mgr manager.Manager) (*bindingIndex, error) {
+	bindingCache, err := cache.New(mgr.GetConfig(), cache.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+	if err := bindingCache.IndexField(&v1.RoleBinding{}, ownerUIDField, ownerUID); err != nil {
+		return nil, err
+	}
+	index := &bindingIndex{cache: bindingCache}
+	if err := mgr.Add(index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// Start runs the cache of the index until stop is closed
+func (i *bindingIndex) Start(stop <-chan struct{}) error {
+	go func() {
+		if i.cache.WaitForCacheSync(stop) {
+			atomic.StoreInt32(&i.synced, 1)
+		}
+	}()
+	return i.cache.Start(stop)
+}
+
+// ready returns whether the RoleBindings can be read from the index
+func (i *bindingIndex) ready() bool {
+	return i != nil && atomic.LoadInt32(&i.synced) == 1
+}
+
+// ownerUID returns the UID of the GroupPermission a managed RoleBinding is managed for, none for the
+// RoleBindings not managed for a GroupPermission
+func ownerUID(obj runtime.Object) []string {
+	roleBinding, ok := obj.(*v1.RoleBinding)
+	if !ok || !utility.IsManaged(roleBinding.ObjectMeta) {
+		return nil
+	}
+	if uid := roleBinding.Annotations[operatorconfig.SourceUIDAnnotation]; uid != "" {
+		return []string{uid}
+	}
+	return nil
+}
+
+// listManagedRoleBindings returns the RoleBindings managed for groupPermission. They are read from the
+// index once synced, by the UID of groupPermission, and listed by label from the apiserver otherwise.
+func (r *ReconcileGroupPermission) listManagedRoleBindings(groupPermission *managedv1alpha1.GroupPermission) (*v1.RoleBindingList, error) {
+	roleBindingList := &v1.RoleBindingList{}
+	var err error
+	if r.bindings.ready() && groupPermission.UID != "" {
+		err = r.bindings.cache.List(context.TODO(), client.MatchingField(ownerUIDField, string(groupPermission.UID)), roleBindingList)
+	} else {
+		opts := &client.ListOptions{LabelSelector: utility.ManagedForSelector(groupPermission.Namespace, groupPermission.Name)}
+		err = r.apiClient.List(context.TODO(), opts, roleBindingList)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	managed := roleBindingList.Items[:0]
+	for _, roleBinding := range roleBindingList.Items {
+		if utility.IsManagedFor(roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+			managed = append(managed, roleBinding)
+		}
+	}
+	roleBindingList.Items = managed
+	return roleBindingList, nil
+}
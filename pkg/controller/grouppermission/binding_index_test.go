@@ -0,0 +1,76 @@
+package grouppermission
+
+import (
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	v1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestOwnerUID tests the ownerUID function
+// given: a managed RoleBinding recording its source UID, one recording none, an unmanaged RoleBinding
+// recording one and a ClusterRoleBinding
+// expected: only the managed RoleBinding recording its source UID is indexed, by that UID
+func TestOwnerUID(t *testing.T) {
+	roleBinding := func(managed bool, uid string) *v1.RoleBinding {
+		binding := &v1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "admin-exampleGroupName"}}
+		if managed {
+			utility.SetManagedLabels(&binding.ObjectMeta, "rbac-permissions-operator", "example")
+		}
+		if uid != "" {
+			binding.Annotations = map[string]string{operatorconfig.SourceUIDAnnotation: uid}
+		}
+		return binding
+	}
+
+	var tests = []struct {
+		name     string
+		binding  runtime.Object
+		expected []string
+	}{
+		{"managed", roleBinding(true, "uid-1"), []string{"uid-1"}},
+		{"managed without source", roleBinding(true, ""), nil},
+		{"unmanaged", roleBinding(false, "uid-1"), nil},
+		{"cluster role binding", &v1.ClusterRoleBinding{}, nil},
+	}
+	for _, test := range tests {
+		if uids := ownerUID(test.binding); !reflect.DeepEqual(uids, test.expected) {
+			t.Errorf("%s: Mismatch for owner UID. Expected(%v), Found(%v)", test.name, test.expected, uids)
+		}
+	}
+}
+
+// TestListManagedRoleBindings tests the listManagedRoleBindings function
+// given: no synced binding index, RoleBindings managed for the GroupPermission and for another one
+// expected: the RoleBindings managed for the GroupPermission are listed from the apiserver
+func TestListManagedRoleBindings(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.UID = types.UID("uid-1")
+	managed := newRoleBinding("team-a", v1.RoleRef{Kind: "ClusterRole", Name: "admin"}, mockGroupSubject("exampleGroupName"))
+	utility.SetManagedLabels(&managed.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	other := newRoleBinding("team-b", v1.RoleRef{Kind: "ClusterRole", Name: "admin"}, mockGroupSubject("exampleGroupName"))
+	utility.SetManagedLabels(&other.ObjectMeta, groupPermission.Namespace, "other")
+
+	fakeClient := fake.NewFakeClient(managed, other)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme, bindings: &bindingIndex{}}
+	roleBindingList, err := reconciler.listManagedRoleBindings(groupPermission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(roleBindingList.Items) != 1 || roleBindingList.Items[0].Namespace != "team-a" {
+		t.Errorf("Mismatch for RoleBindings. Expected([team-a/%s]), Found(%+v)", managed.Name, roleBindingList.Items)
+	}
+}
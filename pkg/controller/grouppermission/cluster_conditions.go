@@ -0,0 +1,87 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/clusterinfo"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// +kubebuilder:rbac:groups=config.openshift.io,resources=infrastructures,verbs=get
+
+// validateClusterConditions returns an error when the clusterConditions of a GroupPermission are not
+// a valid label selector
+func validateClusterConditions(clusterConditions *metav1.LabelSelector) error {
+	if clusterConditions == nil {
+		return nil
+	}
+	if _, err := metav1.LabelSelectorAsSelector(clusterConditions); err != nil {
+		return fmt.Errorf("invalid clusterConditions: %v", err)
+	}
+	return nil
+}
+
+// clusterConditionsMatch returns whether clusterLabels match the clusterConditions of groupPermission,
+// every cluster matches a GroupPermission without clusterConditions
+func clusterConditionsMatch(groupPermission *managedv1alpha1.GroupPermission, clusterLabels labels.Set) (bool, error) {
+	if groupPermission.Spec.ClusterConditions == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(groupPermission.Spec.ClusterConditions)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(clusterLabels), nil
+}
+
+// reconcileClusterConditions removes the bindings of groupPermission when the labels of the cluster do
+// not match its clusterConditions, and records whether they do in the status. It returns whether they do.
+func (r *ReconcileGroupPermission) reconcileClusterConditions(groupPermission *managedv1alpha1.GroupPermission) (bool, error) {
+	if groupPermission.Spec.ClusterConditions == nil {
+		if groupPermission.Status.ClusterConditionsMatched != nil {
+			groupPermission.Status.ClusterConditionsMatched = nil
+			return true, r.updateStatus(groupPermission)
+		}
+		return true, nil
+	}
+
+	operatorConfig, err := r.getOperatorConfig(context.TODO())
+	if err != nil {
+		return false, err
+	}
+	clusterLabels, err := clusterinfo.Labels(context.TODO(), r.apiClient, operatorConfig.ClusterLabels)
+	if err != nil {
+		return false, err
+	}
+	matched, err := clusterConditionsMatch(groupPermission, clusterLabels)
+	if err != nil {
+		return false, err
+	}
+	if !matched {
+		if _, err := r.reconcileNamespaceInventory(groupPermission, nil); err != nil {
+			return false, err
+		}
+		if err := r.deleteManagedBindings(groupPermission); err != nil {
+			return false, err
+		}
+	}
+
+	changed := groupPermission.Status.ClusterConditionsMatched == nil || *groupPermission.Status.ClusterConditionsMatched != matched
+	groupPermission.Status.ClusterConditionsMatched = &matched
+	if !matched && (groupPermission.Status.Namespaces != nil || groupPermission.Status.NamespaceSummary != nil) {
+		// the RoleBindings were removed
+		groupPermission.Status.Namespaces = nil
+		groupPermission.Status.NamespaceSummary = nil
+		changed = true
+	}
+	if changed {
+		if err := r.updateStatus(groupPermission); err != nil {
+			return matched, err
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,104 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestClusterConditionsMatch tests the clusterConditionsMatch function
+// given: GroupPermissions with and without clusterConditions, and the labels of a cluster
+// expected: whether the cluster matches, and an error for an invalid selector
+func TestClusterConditionsMatch(t *testing.T) {
+	clusterLabels := labels.Set{"platform": "AWS", "region": "us-east-1", "environment": "prod"}
+	tests := []struct {
+		label             string
+		clusterConditions *metav1.LabelSelector
+		matched           bool
+		valid             bool
+	}{
+		{"no conditions", nil, true, true},
+		{"matching labels", &metav1.LabelSelector{MatchLabels: map[string]string{"platform": "AWS", "environment": "prod"}}, true, true},
+		{"other environment", &metav1.LabelSelector{MatchLabels: map[string]string{"environment": "staging"}}, false, true},
+		{"region in", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "region", Operator: metav1.LabelSelectorOpIn, Values: []string{"us-east-1", "us-west-2"}},
+		}}, true, true},
+		{"missing label", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "fedramp", Operator: metav1.LabelSelectorOpExists},
+		}}, false, true},
+		{"invalid operator", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "region", Operator: "Near"},
+		}}, false, false},
+	}
+	for _, test := range tests {
+		groupPermission := mockGroupPermission()
+		groupPermission.Spec.ClusterConditions = test.clusterConditions
+		if err := validateClusterConditions(test.clusterConditions); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+		}
+		matched, err := clusterConditionsMatch(groupPermission, clusterLabels)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+		}
+		if matched != test.matched {
+			t.Errorf("%s: expected matched=%t, got %t", test.label, test.matched, matched)
+		}
+	}
+}
+
+// TestReconcileClusterConditions tests the Reconcile function with clusterConditions
+// given: a GroupPermission for production clusters with a RoleBinding, on a staging cluster
+// expected: the RoleBinding is removed, and the status records the cluster does not match
+func TestReconcileClusterConditions(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	groupPermission.Spec.ClusterConditions = &metav1.LabelSelector{MatchLabels: map[string]string{"environment": "prod"}}
+	roleBinding := newRoleBinding("team-a", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+
+	fakeClient := fake.NewFakeClient(groupPermission, roleBinding, mockNamespace("team-a"),
+		mockOperatorConfigMap(operatorconfig.OperatorNamespace, map[string]string{"cluster_labels": "environment=staging"}))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}}
+
+	if _, err := reconciler.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: roleBinding.Name}, &rbacv1.RoleBinding{})
+	if err == nil {
+		t.Errorf("expected the RoleBinding to be removed on a cluster not matching the conditions")
+	}
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if instance.Status.ClusterConditionsMatched == nil || *instance.Status.ClusterConditionsMatched {
+		t.Errorf("expected the cluster conditions not to match, got %v", instance.Status.ClusterConditionsMatched)
+	}
+	if instance.Status.Phase != managedv1alpha1.GroupPermissionPhasePending {
+		t.Errorf("expected phase Pending, got %s", instance.Status.Phase)
+	}
+}
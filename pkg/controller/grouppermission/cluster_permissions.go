@@ -0,0 +1,64 @@
+package grouppermission
+
+import (
+	"context"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// clusterPermissionStatuses returns the apply state of each ClusterRole of the ClusterPermissions of
+// groupPermission, from its ClusterRoleBinding and the last condition of the ClusterRole
+func (r *ReconcileGroupPermission) clusterPermissionStatuses(groupPermission *managedv1alpha1.GroupPermission) ([]managedv1alpha1.ClusterPermissionStatus, error) {
+	var statuses []managedv1alpha1.ClusterPermissionStatus
+	if groupPermission.Spec.GroupName == "" {
+		// a GroupPermission with a groupNameSelector binds nothing itself
+		return statuses, nil
+	}
+	for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+		name := clusterPermissionBinding(&groupPermission.Spec, clusterRoleName).Name
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: name}, &v1.ClusterRoleBinding{})
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		condition := conditions.Get(groupPermission.Status.Conditions, clusterRoleName)
+		statuses = append(statuses, clusterPermissionStatus(clusterRoleName, condition, err == nil))
+	}
+	return statuses, nil
+}
+
+// clusterPermissionStatus returns the apply state of clusterRoleName with its last condition, nil when
+// there is none. exists is whether its ClusterRoleBinding exists.
+func clusterPermissionStatus(clusterRoleName string, condition *managedv1alpha1.Condition, exists bool) managedv1alpha1.ClusterPermissionStatus {
+	status := managedv1alpha1.ClusterPermissionStatus{ClusterRoleName: clusterRoleName}
+	if condition == nil || !condition.Status || condition.State == managedv1alpha1.GroupPermissionCreated {
+		// the ClusterRoleBinding may have been removed since it was created, such as outside of the
+		// windows of the schedule
+		status.State = managedv1alpha1.ClusterPermissionPending
+		if exists {
+			status.State = managedv1alpha1.ClusterPermissionApplied
+		}
+		return status
+	}
+
+	status.Message = condition.Message
+	switch {
+	case condition.Reason == managedv1alpha1.ReasonClusterRoleMissing || condition.Reason == managedv1alpha1.ReasonRoleDeleted:
+		status.State = managedv1alpha1.ClusterPermissionRoleMissing
+	case condition.State == managedv1alpha1.GroupPermissionEscalationDenied ||
+		condition.Reason == managedv1alpha1.ReasonOperatorForbidden ||
+		condition.Reason == managedv1alpha1.ReasonPolicyDenied ||
+		condition.Reason == managedv1alpha1.ReasonGrantDenied ||
+		condition.Reason == managedv1alpha1.ReasonNotDelegable:
+		status.State = managedv1alpha1.ClusterPermissionForbidden
+	case condition.Reason == managedv1alpha1.ReasonBindingConflict:
+		status.State = managedv1alpha1.ClusterPermissionConflict
+	default:
+		status.State = managedv1alpha1.ClusterPermissionFailed
+	}
+	return status
+}
@@ -0,0 +1,111 @@
+package grouppermission
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestClusterPermissionStatus tests the clusterPermissionStatus function
+// given: the last condition of a ClusterRole and whether its ClusterRoleBinding exists
+// expected: the apply state of the ClusterRole, with the message of a failed condition
+func TestClusterPermissionStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition *v1alpha1.Condition
+		exists    bool
+		expected  v1alpha1.ClusterPermissionState
+	}{
+		{name: "no condition", expected: v1alpha1.ClusterPermissionPending},
+		{name: "binding left from before", exists: true, expected: v1alpha1.ClusterPermissionApplied},
+		{
+			name:      "created",
+			condition: &v1alpha1.Condition{State: v1alpha1.GroupPermissionCreated, Reason: v1alpha1.ReasonBindingCreated, Status: true},
+			exists:    true,
+			expected:  v1alpha1.ClusterPermissionApplied,
+		},
+		{
+			name:      "created then removed",
+			condition: &v1alpha1.Condition{State: v1alpha1.GroupPermissionCreated, Reason: v1alpha1.ReasonBindingCreated, Status: true},
+			expected:  v1alpha1.ClusterPermissionPending,
+		},
+		{
+			name:      "missing ClusterRole",
+			condition: &v1alpha1.Condition{State: v1alpha1.GroupPermissionFailed, Reason: v1alpha1.ReasonClusterRoleMissing, Status: true},
+			expected:  v1alpha1.ClusterPermissionRoleMissing,
+		},
+		{
+			name:      "deleted ClusterRole",
+			condition: &v1alpha1.Condition{State: v1alpha1.GroupPermissionFailed, Reason: v1alpha1.ReasonRoleDeleted, Status: true},
+			expected:  v1alpha1.ClusterPermissionRoleMissing,
+		},
+		{
+			name:      "escalation denied",
+			condition: &v1alpha1.Condition{State: v1alpha1.GroupPermissionEscalationDenied, Reason: v1alpha1.ReasonEscalationDenied, Status: true},
+			expected:  v1alpha1.ClusterPermissionForbidden,
+		},
+		{
+			name:      "policy denied",
+			condition: &v1alpha1.Condition{State: v1alpha1.GroupPermissionFailed, Reason: v1alpha1.ReasonPolicyDenied, Status: true},
+			expected:  v1alpha1.ClusterPermissionForbidden,
+		},
+		{
+			name:      "conflict",
+			condition: &v1alpha1.Condition{State: v1alpha1.GroupPermissionFailed, Reason: v1alpha1.ReasonBindingConflict, Status: true},
+			expected:  v1alpha1.ClusterPermissionConflict,
+		},
+		{
+			name:      "other failure",
+			condition: &v1alpha1.Condition{State: v1alpha1.GroupPermissionFailed, Reason: v1alpha1.ReasonBindingFailed, Status: true},
+			expected:  v1alpha1.ClusterPermissionFailed,
+		},
+	}
+
+	for _, test := range tests {
+		status := clusterPermissionStatus("view", test.condition, test.exists)
+		if status.State != test.expected {
+			t.Errorf("FAILURE: %s. Expected(%s), Found(%s)", test.name, test.expected, status.State)
+		}
+		if status.ClusterRoleName != "view" {
+			t.Errorf("FAILURE: %s. Mismatch for ClusterRoleName. Expected(view), Found(%s)", test.name, status.ClusterRoleName)
+		}
+	}
+}
+
+// TestClusterPermissionStatuses tests the clusterPermissionStatuses function
+// given: a GroupPermission granting view, bound, and edit, whose ClusterRole is missing
+// expected: view is Applied and edit is RoleMissing with the message of its condition
+func TestClusterPermissionStatuses(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockGroupPermission()
+	groupPermission.Spec.ClusterPermissions = []string{"view", "edit"}
+	groupPermission.Status.Conditions = []v1alpha1.Condition{
+		{ClusterRoleName: "view", State: v1alpha1.GroupPermissionCreated, Reason: v1alpha1.ReasonBindingCreated, Status: true},
+		{ClusterRoleName: "edit", State: v1alpha1.GroupPermissionFailed, Reason: v1alpha1.ReasonClusterRoleMissing, Status: true, Message: "ClusterRole edit not found"},
+	}
+	fakeClient := fake.NewFakeClient(groupPermission, &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "view-exampleGroupName"}})
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+
+	statuses, err := reconciler.clusterPermissionStatuses(groupPermission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []v1alpha1.ClusterPermissionStatus{
+		{ClusterRoleName: "view", State: v1alpha1.ClusterPermissionApplied},
+		{ClusterRoleName: "edit", State: v1alpha1.ClusterPermissionRoleMissing, Message: "ClusterRole edit not found"},
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("Mismatch for statuses. Expected(%+v), Found(%+v)", expected, statuses)
+	}
+}
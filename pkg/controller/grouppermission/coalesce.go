@@ -0,0 +1,94 @@
+package grouppermission
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// namespaceBurstWindow is how long the reconcile of a GroupPermission triggered by a Namespace event
+// is delayed, so a burst of Namespace events results in a single reconcile
+const namespaceBurstWindow = 5 * time.Second
+
+// coalescingHandler enqueues the requests mapped from an event once the burst window has passed.
+// Requests for a GroupPermission already waiting in the window are coalesced into the pending one.
+type coalescingHandler struct {
+	mapper handler.Mapper
+	window time.Duration
+	// now returns the current time, replaced in tests
+	now func() time.Time
+
+	mutex sync.Mutex
+	// pending holds the time each waiting request is enqueued at
+	pending map[types.NamespacedName]time.Time
+}
+
+// blank assignment to verify that coalescingHandler implements handler.EventHandler
+var _ handler.EventHandler = &coalescingHandler{}
+
+// newCoalescingHandler returns a coalescingHandler enqueueing the requests of mapper after window
+func newCoalescingHandler(mapper handler.Mapper, window time.Duration) *coalescingHandler {
+	return &coalescingHandler{
+		mapper:  mapper,
+		window:  window,
+		now:     time.Now,
+		pending: map[types.NamespacedName]time.Time{},
+	}
+}
+
+// Create implements handler.EventHandler
+func (h *coalescingHandler) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.mapAndEnqueue(q, handler.MapObject{Meta: evt.Meta, Object: evt.Object})
+}
+
+// Update implements handler.EventHandler
+func (h *coalescingHandler) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.mapAndEnqueue(q, handler.MapObject{Meta: evt.MetaOld, Object: evt.ObjectOld})
+	h.mapAndEnqueue(q, handler.MapObject{Meta: evt.MetaNew, Object: evt.ObjectNew})
+}
+
+// Delete implements handler.EventHandler
+func (h *coalescingHandler) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.mapAndEnqueue(q, handler.MapObject{Meta: evt.Meta, Object: evt.Object})
+}
+
+// Generic implements handler.EventHandler
+func (h *coalescingHandler) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.mapAndEnqueue(q, handler.MapObject{Meta: evt.Meta, Object: evt.Object})
+}
+
+// mapAndEnqueue enqueues the requests mapped from object after the window, unless they are pending
+func (h *coalescingHandler) mapAndEnqueue(q workqueue.RateLimitingInterface, object handler.MapObject) {
+	for _, request := range h.mapper.Map(object) {
+		if h.coalesce(request.NamespacedName) {
+			localmetrics.IncReconcilesCoalesced()
+			continue
+		}
+		q.AddAfter(request, h.window)
+	}
+}
+
+// coalesce returns whether a request for key is already waiting in the window, otherwise it records
+// a new pending request for key
+func (h *coalescingHandler) coalesce(key types.NamespacedName) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	now := h.now()
+	if enqueueAt, ok := h.pending[key]; ok && now.Before(enqueueAt) {
+		return true
+	}
+	for pendingKey, enqueueAt := range h.pending {
+		if !now.Before(enqueueAt) {
+			delete(h.pending, pendingKey)
+		}
+	}
+	h.pending[key] = now.Add(h.window)
+	return false
+}
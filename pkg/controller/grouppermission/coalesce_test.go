@@ -0,0 +1,76 @@
+package grouppermission
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestCoalesce tests the coalesce function
+// given: requests for the same and for another GroupPermission, before and after the window
+// expected: only the requests for a key already waiting in the window are coalesced
+func TestCoalesce(t *testing.T) {
+	now := time.Now()
+	h := newCoalescingHandler(&namespaceToGroupPermissions{}, time.Minute)
+	h.now = func() time.Time { return now }
+
+	key := types.NamespacedName{Namespace: "rbac-permissions-operator", Name: "testGroupPermission"}
+	other := types.NamespacedName{Namespace: "rbac-permissions-operator", Name: "otherGroupPermission"}
+
+	if h.coalesce(key) {
+		t.Errorf("expected the first request not to be coalesced")
+	}
+	if !h.coalesce(key) {
+		t.Errorf("expected a request in the window to be coalesced")
+	}
+	if h.coalesce(other) {
+		t.Errorf("expected the request of another GroupPermission not to be coalesced")
+	}
+
+	now = now.Add(time.Minute)
+	if h.coalesce(key) {
+		t.Errorf("expected a request after the window not to be coalesced")
+	}
+	if len(h.pending) != 1 {
+		t.Errorf("expected expired requests to be forgotten, got %v", h.pending)
+	}
+}
+
+// TestCoalescingHandler tests the coalescingHandler
+// given: a burst of Namespace events allowed by the same GroupPermission
+// expected: a single delayed request for the GroupPermission
+func TestCoalescingHandler(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	h := newCoalescingHandler(&namespaceToGroupPermissions{
+		client: fake.NewFakeClient(mockNamespacedGroupPermission()),
+	}, 10*time.Millisecond)
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+
+	for _, name := range []string{"team-a", "team-b", "team-c", "default"} {
+		namespace := mockNamespace(name)
+		h.Create(event.CreateEvent{Meta: namespace, Object: namespace}, q)
+	}
+	if q.Len() != 0 {
+		t.Errorf("expected the request to wait for the window, got %d queued", q.Len())
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if q.Len() != 1 {
+		t.Fatalf("expected a single request after the window, got %d", q.Len())
+	}
+	item, _ := q.Get()
+	if request := item.(reconcile.Request); request.Name != "testGroupPermission" {
+		t.Errorf("expected a request for testGroupPermission, got %v", item)
+	}
+}
@@ -0,0 +1,96 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// isOperatorConfigMap returns whether meta is the operator ConfigMap
+func isOperatorConfigMap(meta metav1.Object) bool {
+	return meta.GetNamespace() == operatorconfig.OperatorNamespace && meta.GetName() == operatorconfig.OperatorConfigMapName
+}
+
+// operatorConfigChanged passes the events of the operator ConfigMap changing the operator configuration.
+// The configuration is read on each reconcile, the GroupPermissions are resynced so a change applies
+// to the converged ones too instead of waiting for their next resync.
+var operatorConfigChanged = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool { return isOperatorConfigMap(e.Meta) },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return isOperatorConfigMap(e.MetaNew) && operatorConfigDiffers(e.ObjectOld, e.ObjectNew)
+	},
+	DeleteFunc:  func(e event.DeleteEvent) bool { return isOperatorConfigMap(e.Meta) },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// operatorConfigDiffers returns whether the operator configuration parsed from the ConfigMaps
+// oldObj and newObj differs. When either is invalid its data is compared instead.
+func operatorConfigDiffers(oldObj, newObj runtime.Object) bool {
+	oldConfigMap, ok := oldObj.(*corev1.ConfigMap)
+	if !ok {
+		return true
+	}
+	newConfigMap, ok := newObj.(*corev1.ConfigMap)
+	if !ok {
+		return true
+	}
+
+	oldConfig, oldErr := operatorconfig.OperatorConfigFromConfigMap(oldConfigMap)
+	newConfig, newErr := operatorconfig.OperatorConfigFromConfigMap(newConfigMap)
+	if oldErr != nil || newErr != nil {
+		return !reflect.DeepEqual(oldConfigMap.Data, newConfigMap.Data)
+	}
+	return !reflect.DeepEqual(oldConfig, newConfig)
+}
+
+// operatorConfigToGroupPermissions maps a change of the operator configuration to a reconcile of
+// every GroupPermission of the shard
+type operatorConfigToGroupPermissions struct {
+	client client.Client
+	shard  *shard
+}
+
+// blank assignment to verify that operatorConfigToGroupPermissions implements handler.Mapper
+var _ handler.Mapper = &operatorConfigToGroupPermissions{}
+
+// Map implements handler.Mapper, it also reports the new configuration in the metrics
+func (m *operatorConfigToGroupPermissions) Map(obj handler.MapObject) []reconcile.Request {
+	if operatorConfig, err := operatorconfig.GetOperatorConfig(context.TODO(), m.client); err != nil {
+		log.Error(err, "Failed to get operator config", "ConfigMap", obj.Meta.GetName())
+	} else {
+		localmetrics.SetConfigValues(operatorConfig.Values())
+	}
+
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	err := m.client.List(context.TODO(), &client.ListOptions{}, groupPermissionList)
+	if err != nil {
+		log.Error(err, "Failed to list GroupPermissions", "ConfigMap", obj.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, groupPermission := range groupPermissionList.Items {
+		if !m.shard.matches(&groupPermission) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: groupPermission.Namespace,
+			Name:      groupPermission.Name,
+		}})
+	}
+	log.Info("Operator configuration changed, resyncing GroupPermissions", "GroupPermissions", len(requests))
+	return requests
+}
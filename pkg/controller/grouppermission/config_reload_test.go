@@ -0,0 +1,86 @@
+package grouppermission
+
+import (
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func mockOperatorConfigMap(namespace string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      operatorconfig.OperatorConfigMapName,
+			Namespace: namespace,
+		},
+		Data: data,
+	}
+}
+
+// TestOperatorConfigChanged tests the operatorConfigChanged predicate
+// given: events of the operator ConfigMap and of another ConfigMap
+// expected: only the events changing the operator configuration pass
+func TestOperatorConfigChanged(t *testing.T) {
+	resync := map[string]string{"resync_interval_seconds": "3600"}
+	tests := []struct {
+		label   string
+		oldData map[string]string
+		newData map[string]string
+		other   bool
+		valid   bool
+	}{
+		{label: "changed setting", oldData: resync, newData: map[string]string{"resync_interval_seconds": "60"}, valid: true},
+		{label: "setting set to its default", oldData: nil, newData: map[string]string{"jitter_factor": "0.1"}, valid: false},
+		{label: "unknown key", oldData: resync, newData: map[string]string{"resync_interval_seconds": "3600", "comment": "unused"}, valid: false},
+		{label: "invalid setting", oldData: resync, newData: map[string]string{"resync_interval_seconds": "hourly"}, valid: true},
+		{label: "other ConfigMap", oldData: resync, newData: map[string]string{"resync_interval_seconds": "60"}, other: true, valid: false},
+	}
+	for _, test := range tests {
+		namespace := operatorconfig.OperatorNamespace
+		if test.other {
+			namespace = "default"
+		}
+		oldConfigMap := mockOperatorConfigMap(namespace, test.oldData)
+		newConfigMap := mockOperatorConfigMap(namespace, test.newData)
+		passed := operatorConfigChanged.Update(event.UpdateEvent{
+			MetaOld: oldConfigMap, ObjectOld: oldConfigMap,
+			MetaNew: newConfigMap, ObjectNew: newConfigMap,
+		})
+		if passed != test.valid {
+			t.Errorf("%s: got %t, want %t", test.label, passed, test.valid)
+		}
+	}
+
+	configMap := mockOperatorConfigMap(operatorconfig.OperatorNamespace, resync)
+	if !operatorConfigChanged.Create(event.CreateEvent{Meta: configMap, Object: configMap}) {
+		t.Errorf("expected the creation of the operator ConfigMap to pass")
+	}
+	if !operatorConfigChanged.Delete(event.DeleteEvent{Meta: configMap, Object: configMap}) {
+		t.Errorf("expected the deletion of the operator ConfigMap to pass")
+	}
+}
+
+// TestOperatorConfigToGroupPermissions tests the operatorConfigToGroupPermissions mapper
+// given: two GroupPermissions
+// expected: a request for each GroupPermission
+func TestOperatorConfigToGroupPermissions(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	other := mockGroupPermission()
+	other.Name = "otherGroupPermission"
+	mapper := &operatorConfigToGroupPermissions{client: fake.NewFakeClient(mockGroupPermission(), other)}
+
+	configMap := mockOperatorConfigMap(operatorconfig.OperatorNamespace, nil)
+	requests := mapper.Map(handler.MapObject{Meta: configMap, Object: configMap})
+	if len(requests) != 2 {
+		t.Errorf("expected a request for each GroupPermission, got %v", requests)
+	}
+}
@@ -0,0 +1,173 @@
+//go:build contract
+// +build contract
+
+package grouppermission
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// The contract tests run against the kube-apiserver and etcd binaries of KUBEBUILDER_ASSETS, and check
+// that what the apiserver stores for the objects the operator creates is what its diffing logic
+// predicts. They run once by Kubernetes version of ENVTEST_K8S_VERSIONS with: make contract-test
+
+// contractNamespace is the Namespace the namespaced objects of the contract tests are created in
+const contractNamespace = "team-a"
+
+// TestContract tests the defaulting and validation of the RBAC objects the operator creates
+// given: the apiserver of the Kubernetes version under test
+// expected: the stored bindings and Roles are the ones normalizeBinding and rulesHash predict, and the
+// apiserver validates them as the operator expects
+func TestContract(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set, run: make contract-test")
+	}
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	environment := &envtest.Environment{}
+	cfg, err := environment.Start()
+	if err != nil {
+		t.Fatalf("Unable to start the control plane: (%v)", err)
+	}
+	defer func() {
+		if err := environment.Stop(); err != nil {
+			t.Errorf("Unable to stop the control plane: (%v)", err)
+		}
+	}()
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	serverVersion, err := discoveryClient.ServerVersion()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// a matrix entry silently running the binaries of another version would not test anything new
+	if expected := os.Getenv("ENVTEST_K8S_VERSION"); expected != "" && !strings.HasPrefix(serverVersion.GitVersion, "v"+expected) {
+		t.Fatalf("Mismatch for the apiserver version. Expected(%s), Found(%s)", expected, serverVersion.GitVersion)
+	}
+	t.Logf("Testing against the apiserver %s", serverVersion.GitVersion)
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: contractNamespace}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("binding defaults", func(t *testing.T) { testContractBindingDefaults(t, c) })
+	t.Run("roleRef immutable", func(t *testing.T) { testContractRoleRefImmutable(t, c) })
+	t.Run("role rules", func(t *testing.T) { testContractRoleRules(t, c) })
+}
+
+// testContractBindingDefaults checks that the bindings the operator creates without API groups are
+// stored as normalizeBinding predicts, so an unchanged binding is never seen as drifted
+func testContractBindingDefaults(t *testing.T, c client.Client) {
+	group := utility.GroupSubject(&mockGroupPermission().Spec)
+	user := rbacv1.Subject{Kind: rbacv1.UserKind, Name: "alice"}
+	serviceAccount := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: contractNamespace, Name: "builder"}
+	serviceAccounts := rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:" + contractNamespace}
+	clusterRole := rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}
+
+	var tests = []struct {
+		name    string
+		binding runtime.Object
+	}{
+		{"cluster role binding of a group", newClusterRoleBinding("view", group)},
+		{"cluster role binding of a user", newClusterRoleBinding("view", user)},
+		{"role binding of a cluster role", newRoleBinding(contractNamespace, clusterRole, group)},
+		{"role binding of a role", newRoleBinding(contractNamespace, rbacv1.RoleRef{Kind: "Role", Name: "deployer"}, group)},
+		{"role binding of a service account", newRoleBinding(contractNamespace, clusterRole, serviceAccount)},
+		{"role binding of the service accounts group", newRoleBinding(contractNamespace, clusterRole, serviceAccounts)},
+	}
+	for _, test := range tests {
+		if err := c.Create(context.TODO(), test.binding); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		var desired, stored verifiedBinding
+		switch binding := test.binding.(type) {
+		case *rbacv1.ClusterRoleBinding:
+			found := &rbacv1.ClusterRoleBinding{}
+			if err := c.Get(context.TODO(), types.NamespacedName{Name: binding.Name}, found); err != nil {
+				t.Fatalf("%s: unexpected error: %v", test.name, err)
+			}
+			desired = verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects}
+			stored = verifiedBinding{roleRef: found.RoleRef, subjects: found.Subjects}
+		case *rbacv1.RoleBinding:
+			found := &rbacv1.RoleBinding{}
+			if err := c.Get(context.TODO(), types.NamespacedName{Namespace: binding.Namespace, Name: binding.Name}, found); err != nil {
+				t.Fatalf("%s: unexpected error: %v", test.name, err)
+			}
+			desired = verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects}
+			stored = verifiedBinding{roleRef: found.RoleRef, subjects: found.Subjects}
+		}
+		if normalized := normalizeBinding(desired); !reflect.DeepEqual(normalized, stored) {
+			t.Errorf("%s: Mismatch for the stored binding. Expected(%+v), Found(%+v)", test.name, normalized, stored)
+		}
+	}
+}
+
+// testContractRoleRefImmutable checks that the apiserver rejects a change of the roleRef of a binding,
+// the operator recreating a binding to grant another role
+func testContractRoleRefImmutable(t *testing.T, c client.Client) {
+	roleBinding := newRoleBinding(contractNamespace, rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"}, utility.GroupSubject(&mockGroupPermission().Spec))
+	if err := c.Create(context.TODO(), roleBinding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	roleBinding.RoleRef.Name = "admin"
+	if err := c.Update(context.TODO(), roleBinding); !errors.IsInvalid(err) {
+		t.Errorf("expected the update of the roleRef to be invalid, got %v", err)
+	}
+}
+
+// testContractRoleRules checks that the rules of the Roles the operator creates are stored unchanged,
+// so the role content controller does not see their hash drift
+func testContractRoleRules(t *testing.T, c client.Client) {
+	groupPermission := mockRulesGroupPermission()
+	groupPermission.Spec.ViewRole = &v1alpha1.ViewRole{Resources: []string{"pods", "pods/log", "deployments.apps"}}
+	permission, _ := viewPermission(groupPermission)
+	permissions := append([]v1alpha1.Permission{permission}, groupPermission.Spec.Permissions...)
+
+	for _, permission := range permissions {
+		if err := c.Create(context.TODO(), newRole(groupPermission, contractNamespace, permission)); err != nil {
+			t.Errorf("%s: unexpected error: %v", permission.RoleName, err)
+			continue
+		}
+		stored := &rbacv1.Role{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Namespace: contractNamespace, Name: permission.RoleName}, stored); err != nil {
+			t.Fatalf("%s: unexpected error: %v", permission.RoleName, err)
+		}
+		if !reflect.DeepEqual(stored.Rules, permission.Rules) {
+			t.Errorf("%s: Mismatch for the stored rules. Expected(%+v), Found(%+v)", permission.RoleName, permission.Rules, stored.Rules)
+		}
+		if hash := rulesHash(stored.Rules); stored.Annotations[operatorconfig.RulesHashAnnotation] != hash {
+			t.Errorf("%s: Mismatch for the rules hash. Expected(%s), Found(%s)", permission.RoleName, hash, stored.Annotations[operatorconfig.RulesHashAnnotation])
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package grouppermission
+
+import (
+	"fmt"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	v1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// undelegableRoles returns the roles granted by groupPermission, as "Kind name", that are not delegable
+// to its Namespace when the operator configuration delegates it. They are not bound, so neither a
+// GroupPermission admitted before its Namespace was delegated nor a ClusterRole losing its label grant them.
+func undelegableRoles(groupPermission *managedv1alpha1.GroupPermission, clusterRoleList *v1.ClusterRoleList, operatorConfig *operatorconfig.OperatorConfig) map[string]bool {
+	undelegable := map[string]bool{}
+	if !operatorConfig.IsDelegatedNamespace(groupPermission.Namespace) {
+		return undelegable
+	}
+	// the lookup in clusterRoleList never fails
+	roles, _ := utility.UndelegableRoles(&groupPermission.Spec, groupPermission.Namespace, func(clusterRoleName string) (metav1.Object, error) {
+		if clusterRole := findClusterRole(clusterRoleName, clusterRoleList); clusterRole != nil {
+			return clusterRole, nil
+		}
+		return nil, nil
+	})
+	for _, role := range roles {
+		undelegable[role] = true
+	}
+	return undelegable
+}
+
+// notDelegableMessage returns the message of the condition of a role not delegable to namespace
+func notDelegableMessage(roleRef v1.RoleRef, namespace string) string {
+	return fmt.Sprintf("%s %s is not delegable to Namespace %s, it must be a ClusterRole labeled %s=true",
+		roleRef.Kind, roleRef.Name, namespace, utility.DelegableLabel(namespace))
+}
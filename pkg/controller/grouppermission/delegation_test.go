@@ -0,0 +1,76 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestUndelegableRoles tests the undelegableRoles function
+// given: a GroupPermission granting view, labeled as delegable to its Namespace, and admin, not labeled
+// expected: admin is undelegable when the Namespace is delegated, no role is otherwise
+func TestUndelegableRoles(t *testing.T) {
+	groupPermission := mockGroupPermission()
+	groupPermission.Spec.ClusterPermissions = []string{"view", "admin"}
+	clusterRoleList := &rbacv1.ClusterRoleList{Items: []rbacv1.ClusterRole{
+		{ObjectMeta: metav1.ObjectMeta{Name: "view", Labels: map[string]string{utility.DelegableLabel(groupPermission.Namespace): "true"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "admin"}},
+	}}
+
+	delegated := &operatorconfig.OperatorConfig{DelegatedNamespaces: []string{groupPermission.Namespace}}
+	expected := map[string]bool{"ClusterRole admin": true}
+	if undelegable := undelegableRoles(groupPermission, clusterRoleList, delegated); !reflect.DeepEqual(undelegable, expected) {
+		t.Errorf("Mismatch for undelegable roles. Expected(%v), Found(%v)", expected, undelegable)
+	}
+	if undelegable := undelegableRoles(groupPermission, clusterRoleList, operatorconfig.DefaultOperatorConfig()); len(undelegable) != 0 {
+		t.Errorf("expected no undelegable role outside of a delegated Namespace, got %v", undelegable)
+	}
+}
+
+// TestReconcileNamespacePermissionsUndelegable tests the reconcileNamespacePermissions function with
+// undelegable roles
+// given: a GroupPermission granting admin in Namespaces, admin not delegable to its Namespace
+// expected: no RoleBinding is created and every NamespaceStatus is Failed
+func TestReconcileNamespacePermissionsUndelegable(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	fakeClient := newRequesterClient(mockNamespace("team-a"), mockNamespace("team-b"))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+	}
+
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(mockNamespacedGroupPermission(), nil, map[string]bool{"ClusterRole admin": true}, nil, operatorconfig.DefaultOperatorConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(namespaceStatuses) != 2 {
+		t.Fatalf("Mismatch for namespace statuses. Expected(2), Found(%d)", len(namespaceStatuses))
+	}
+	for _, namespaceStatus := range namespaceStatuses {
+		if namespaceStatus.State != v1alpha1.GroupPermissionFailed || namespaceStatus.LastError == "" {
+			t.Errorf("expected a Failed state with an error for %s, got %s %q", namespaceStatus.Namespace, namespaceStatus.State, namespaceStatus.LastError)
+		}
+	}
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := reconciler.client.List(context.TODO(), &client.ListOptions{}, roleBindingList); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(roleBindingList.Items) != 0 {
+		t.Errorf("Mismatch for RoleBindings. Expected(0), Found(%d)", len(roleBindingList.Items))
+	}
+}
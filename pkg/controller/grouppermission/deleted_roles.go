@@ -0,0 +1,160 @@
+package grouppermission
+
+import (
+	"context"
+	"sort"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// clusterRoleCreatedOrDeleted passes the events of ClusterRoles being created or deleted, so the
+// bindings to a ClusterRole are removed with it and created again with it
+var clusterRoleCreatedOrDeleted = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return true },
+	UpdateFunc:  func(event.UpdateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return true },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// missingClusterRoles returns the ClusterRoles granted by groupPermission, cluster wide or in
+// Namespaces, that are not in clusterRoleList
+func missingClusterRoles(groupPermission *managedv1alpha1.GroupPermission, clusterRoleList *v1.ClusterRoleList) map[string]bool {
+	missing := map[string]bool{}
+	for _, clusterRoleName := range grantedClusterRoles(groupPermission) {
+		if findClusterRole(clusterRoleName, clusterRoleList) == nil {
+			missing[clusterRoleName] = true
+		}
+	}
+	return missing
+}
+
+// grantedClusterRoles returns the names of the ClusterRoles granted by groupPermission
+func grantedClusterRoles(groupPermission *managedv1alpha1.GroupPermission) []string {
+	clusterRoleNames := append([]string{}, groupPermission.Spec.ClusterPermissions...)
+	for _, permission := range groupPermission.Spec.Permissions {
+		if permission.ClusterRoleName != "" {
+			clusterRoleNames = append(clusterRoleNames, permission.ClusterRoleName)
+		}
+	}
+	return clusterRoleNames
+}
+
+// deleteDanglingBindings deletes the ClusterRoleBindings and RoleBindings managed for groupPermission
+// that bind one of the missing ClusterRoles, and returns the number deleted for each ClusterRole
+func (r *ReconcileGroupPermission) deleteDanglingBindings(groupPermission *managedv1alpha1.GroupPermission, missing map[string]bool) (map[string]int, error) {
+	deleted := map[string]int{}
+	if len(missing) == 0 {
+		return deleted, nil
+	}
+	isDangling := func(roleRef v1.RoleRef) bool {
+		return roleRef.Kind == "ClusterRole" && missing[roleRef.Name]
+	}
+	deletedByRole, err := r.deleteBindings(groupPermission, isDangling)
+	for roleRef, count := range deletedByRole {
+		deleted[roleRef.Name] += count
+	}
+	return deleted, err
+}
+
+// deleteBindings deletes the ClusterRoleBindings and RoleBindings managed for groupPermission whose
+// role reference matches, and returns the number deleted for each role reference
+func (r *ReconcileGroupPermission) deleteBindings(groupPermission *managedv1alpha1.GroupPermission, matches func(v1.RoleRef) bool) (map[v1.RoleRef]int, error) {
+	deleted := map[v1.RoleRef]int{}
+	opts := &client.ListOptions{LabelSelector: utility.ManagedForSelector(groupPermission.Namespace, groupPermission.Name)}
+
+	clusterRoleBindingList := &v1.ClusterRoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), opts, clusterRoleBindingList); err != nil {
+		return deleted, err
+	}
+	for i := range clusterRoleBindingList.Items {
+		binding := &clusterRoleBindingList.Items[i]
+		if !utility.IsManagedFor(binding.ObjectMeta, groupPermission.Namespace, groupPermission.Name) || !matches(binding.RoleRef) {
+			continue
+		}
+		unlock := bindinglock.Lock(bindinglock.ClusterRoleBinding, "", binding.Name)
+		err := r.client.Delete(context.TODO(), binding)
+		unlock()
+		if err != nil && !errors.IsNotFound(err) {
+			return deleted, err
+		}
+		deleted[binding.RoleRef]++
+	}
+
+	roleBindingList, err := r.listManagedRoleBindings(groupPermission)
+	if err != nil {
+		return deleted, err
+	}
+	for i := range roleBindingList.Items {
+		binding := &roleBindingList.Items[i]
+		if !matches(binding.RoleRef) {
+			continue
+		}
+		unlock := bindinglock.Lock(bindinglock.RoleBinding, binding.Namespace, binding.Name)
+		err := r.client.Delete(context.TODO(), binding)
+		unlock()
+		if err != nil && !errors.IsNotFound(err) {
+			return deleted, err
+		}
+		deleted[binding.RoleRef]++
+	}
+
+	return deleted, nil
+}
+
+// sortedKeys returns the ClusterRole names of counts in sorted order
+func sortedKeys(counts map[string]int) []string {
+	var keys []string
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// clusterRoleToGroupPermissions maps a ClusterRole event to a reconcile of every GroupPermission of
+// the shard granting the ClusterRole
+type clusterRoleToGroupPermissions struct {
+	client client.Client
+	shard  *shard
+}
+
+// blank assignment to verify that clusterRoleToGroupPermissions implements handler.Mapper
+var _ handler.Mapper = &clusterRoleToGroupPermissions{}
+
+// Map implements handler.Mapper
+func (m *clusterRoleToGroupPermissions) Map(obj handler.MapObject) []reconcile.Request {
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	err := m.client.List(context.TODO(), &client.ListOptions{}, groupPermissionList)
+	if err != nil {
+		log.Error(err, "Failed to list GroupPermissions", "ClusterRole", obj.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, groupPermission := range groupPermissionList.Items {
+		if !m.shard.matches(&groupPermission) {
+			continue
+		}
+		for _, clusterRoleName := range grantedClusterRoles(&groupPermission) {
+			if clusterRoleName == obj.Meta.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: groupPermission.Namespace,
+					Name:      groupPermission.Name,
+				}})
+				break
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,107 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// TestMissingClusterRoles tests the missingClusterRoles function
+// given: a GroupPermission granting ClusterRoles cluster wide and in Namespaces, of which some exist
+// expected: only the ClusterRoles that do not exist
+func TestMissingClusterRoles(t *testing.T) {
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.Permissions = append(groupPermission.Spec.Permissions, v1alpha1.Permission{RoleName: "deployer"})
+	clusterRole := mockClusterRole()
+	clusterRole.Name = "exampleClusterRoleName"
+
+	missing := missingClusterRoles(groupPermission, &rbacv1.ClusterRoleList{Items: []rbacv1.ClusterRole{*clusterRole}})
+	expected := map[string]bool{"exampleClusterRoleNameTwo": true, "admin": true}
+	if !reflect.DeepEqual(missing, expected) {
+		t.Errorf("got %v, want %v", missing, expected)
+	}
+}
+
+// TestDeleteDanglingBindings tests the deleteDanglingBindings function
+// given: managed bindings to a deleted and to an existing ClusterRole, and a binding of another GroupPermission
+// expected: only the managed bindings to the deleted ClusterRole are deleted
+func TestDeleteDanglingBindings(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	dangling := newClusterRoleBinding("admin", utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&dangling.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	danglingInNamespace := newRoleBinding("team-a", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&danglingInNamespace.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	kept := newClusterRoleBinding("view", utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&kept.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	other := newRoleBinding("team-b", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&other.ObjectMeta, groupPermission.Namespace, "otherGroupPermission")
+
+	fakeClient := fake.NewFakeClient(dangling, danglingInNamespace, kept, other)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+
+	deleted, err := reconciler.deleteDanglingBindings(groupPermission, map[string]bool{"admin": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(deleted, map[string]int{"admin": 2}) {
+		t.Errorf("expected 2 bindings of admin to be deleted, got %v", deleted)
+	}
+
+	for _, test := range []struct {
+		key    types.NamespacedName
+		obj    interface{}
+		exists bool
+	}{
+		{types.NamespacedName{Name: dangling.Name}, &rbacv1.ClusterRoleBinding{}, false},
+		{types.NamespacedName{Namespace: "team-a", Name: danglingInNamespace.Name}, &rbacv1.RoleBinding{}, false},
+		{types.NamespacedName{Name: kept.Name}, &rbacv1.ClusterRoleBinding{}, true},
+		{types.NamespacedName{Namespace: "team-b", Name: other.Name}, &rbacv1.RoleBinding{}, true},
+	} {
+		var err error
+		switch obj := test.obj.(type) {
+		case *rbacv1.ClusterRoleBinding:
+			err = fakeClient.Get(context.TODO(), test.key, obj)
+		case *rbacv1.RoleBinding:
+			err = fakeClient.Get(context.TODO(), test.key, obj)
+		}
+		if err != nil && !errors.IsNotFound(err) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if exists := err == nil; exists != test.exists {
+			t.Errorf("%s: expected exists=%t, got %t", test.key, test.exists, exists)
+		}
+	}
+}
+
+// TestClusterRoleToGroupPermissions tests the clusterRoleToGroupPermissions mapper
+// given: a ClusterRole granted in Namespaces by a GroupPermission
+// expected: a reconcile request for the GroupPermission, none for a ClusterRole it does not grant
+func TestClusterRoleToGroupPermissions(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	mapper := &clusterRoleToGroupPermissions{client: fake.NewFakeClient(mockNamespacedGroupPermission())}
+	requests := mapper.Map(handler.MapObject{Meta: &metav1.ObjectMeta{Name: "admin"}})
+	if len(requests) != 1 || requests[0].Name != "testGroupPermission" {
+		t.Errorf("expected a request for testGroupPermission, got %v", requests)
+	}
+	if requests := mapper.Map(handler.MapObject{Meta: &metav1.ObjectMeta{Name: "cluster-admin"}}); len(requests) != 0 {
+		t.Errorf("expected no request, got %v", requests)
+	}
+}
@@ -0,0 +1,92 @@
+package grouppermission
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// deletionImpact returns the managed RBAC objects of groupPermission removed by its deletion, and the
+// Namespaces its Group loses access in
+func (r *ReconcileGroupPermission) deletionImpact(groupPermission *managedv1alpha1.GroupPermission) (*managedv1alpha1.DeletionImpact, error) {
+	clusterRoleBindingList, roleBindingList, err := r.listManagedBindings(groupPermission)
+	if err != nil {
+		return nil, err
+	}
+	roleList, err := r.listManagedRoles(groupPermission)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := &managedv1alpha1.DeletionImpact{}
+	namespaces := map[string]bool{}
+	for _, clusterRoleBinding := range clusterRoleBindingList.Items {
+		if utility.IsManagedFor(clusterRoleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+			impact.Bindings = append(impact.Bindings, "ClusterRoleBinding "+clusterRoleBinding.Name)
+		}
+	}
+	for _, roleBinding := range roleBindingList.Items {
+		if !utility.IsManagedFor(roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+			continue
+		}
+		impact.Bindings = append(impact.Bindings, "RoleBinding "+roleBinding.Namespace+"/"+roleBinding.Name)
+		namespaces[roleBinding.Namespace] = true
+	}
+	for _, role := range roleList.Items {
+		impact.Bindings = append(impact.Bindings, "Role "+role.Namespace+"/"+role.Name)
+	}
+	for namespace := range namespaces {
+		impact.Namespaces = append(impact.Namespaces, namespace)
+	}
+	sort.Strings(impact.Bindings)
+	sort.Strings(impact.Namespaces)
+	return impact, nil
+}
+
+// holdDeletion keeps the managed RBAC objects of the deleted groupPermission for the deletion grace
+// period of the operator, recording on its status what its deletion removes. It returns how long until
+// the grace period ends, 0 once the objects can be removed.
+func (r *ReconcileGroupPermission) holdDeletion(groupPermission *managedv1alpha1.GroupPermission) (time.Duration, error) {
+	operatorConfig, err := r.getOperatorConfig(context.TODO())
+	if err != nil {
+		return 0, err
+	}
+	if operatorConfig.DeletionGracePeriod <= 0 {
+		return 0, nil
+	}
+	cleanupTime := groupPermission.DeletionTimestamp.Add(operatorConfig.DeletionGracePeriod)
+	remaining := cleanupTime.Sub(time.Now())
+	if remaining <= 0 {
+		return 0, nil
+	}
+	if groupPermission.Status.DeletionImpact != nil {
+		return remaining, nil
+	}
+
+	impact, err := r.deletionImpact(groupPermission)
+	if err != nil {
+		return 0, err
+	}
+	impact.CleanupTime = metav1.Time{Time: cleanupTime}
+	groupPermission.Status.DeletionImpact = impact
+	if err := r.updateStatus(groupPermission); err != nil {
+		return 0, err
+	}
+	r.eventf(groupPermission, corev1.EventTypeWarning, "DeletionPending",
+		"Removing %d managed RBAC objects in %d Namespaces at %s, set the %s annotation to keep them",
+		len(impact.Bindings), len(impact.Namespaces), cleanupTime.UTC().Format(time.RFC3339), operatorconfig.RetainBindingsAnnotation)
+	return remaining, nil
+}
+
+// retainsBindings returns whether the deleted groupPermission is released without removing its managed
+// RBAC objects
+func retainsBindings(groupPermission *managedv1alpha1.GroupPermission) bool {
+	return groupPermission.Annotations[operatorconfig.RetainBindingsAnnotation] == "true"
+}
@@ -0,0 +1,135 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// mockDeletedGroupPermission returns a GroupPermission deleted at deleted, held by the operator finalizer,
+// with a managed ClusterRoleBinding and RoleBinding
+func mockDeletedGroupPermission(deleted time.Time) []runtime.Object {
+	groupPermission := mockGroupPermission()
+	groupPermission.Finalizers = []string{operatorconfig.GroupPermissionFinalizer}
+	groupPermission.DeletionTimestamp = &metav1.Time{Time: deleted}
+	owned := utility.ManagedLabels(groupPermission.Namespace, groupPermission.Name)
+	return []runtime.Object{
+		groupPermission,
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "owned", Labels: owned}},
+		&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "owned", Labels: owned}},
+	}
+}
+
+// TestHoldDeletion tests the Reconcile function of a deleted GroupPermission with a deletion grace period
+// given: a GroupPermission deleted a minute ago, with a grace period of 10 minutes then of 30 seconds
+// expected: its bindings are kept and listed in its deletion impact until the grace period ends, then
+// they are removed
+func TestHoldDeletion(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	configMap := mockOperatorConfigMap(operatorconfig.OperatorNamespace, map[string]string{"deletion_grace_period_seconds": "600"})
+	fakeClient := fake.NewFakeClient(append(mockDeletedGroupPermission(time.Now().Add(-time.Minute)), configMap)...)
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+	key := types.NamespacedName{Namespace: "rbac-permissions-operator", Name: "testGroupPermission"}
+
+	result, err := reconciler.Reconcile(reconcile.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter <= 8*time.Minute || result.RequeueAfter > 9*time.Minute {
+		t.Errorf("expected a requeue at the end of the grace period, got %s", result.RequeueAfter)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: "owned"}, &rbacv1.ClusterRoleBinding{}); err != nil {
+		t.Errorf("expected the ClusterRoleBinding to be kept during the grace period: %v", err)
+	}
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), key, instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	impact := instance.Status.DeletionImpact
+	if impact == nil {
+		t.Fatalf("expected the deletion impact to be set")
+	}
+	if expected := []string{"ClusterRoleBinding owned", "RoleBinding team-a/owned"}; !reflect.DeepEqual(impact.Bindings, expected) {
+		t.Errorf("Mismatch for Bindings. Expected(%v), Found(%v)", expected, impact.Bindings)
+	}
+	if expected := []string{"team-a"}; !reflect.DeepEqual(impact.Namespaces, expected) {
+		t.Errorf("Mismatch for Namespaces. Expected(%v), Found(%v)", expected, impact.Namespaces)
+	}
+
+	configMap.Data["deletion_grace_period_seconds"] = "30"
+	if err := fakeClient.Update(context.TODO(), configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := reconciler.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: "owned"}, &rbacv1.ClusterRoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the ClusterRoleBinding to be removed after the grace period, got %v", err)
+	}
+	released := &managedv1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), key, released); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hasFinalizer(released) {
+		t.Errorf("expected the finalizer to be removed")
+	}
+}
+
+// TestRetainBindings tests the Reconcile function of a deleted GroupPermission with the retain-bindings annotation
+// given: a GroupPermission deleted during its grace period, annotated to retain its bindings
+// expected: the finalizer is removed and the bindings are kept
+func TestRetainBindings(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	objects := mockDeletedGroupPermission(time.Now())
+	objects[0].(*managedv1alpha1.GroupPermission).Annotations = map[string]string{operatorconfig.RetainBindingsAnnotation: "true"}
+	configMap := mockOperatorConfigMap(operatorconfig.OperatorNamespace, map[string]string{"deletion_grace_period_seconds": "600"})
+	fakeClient := fake.NewFakeClient(append(objects, configMap)...)
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+	key := types.NamespacedName{Namespace: "rbac-permissions-operator", Name: "testGroupPermission"}
+
+	if _, err := reconciler.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), key, instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hasFinalizer(instance) {
+		t.Errorf("expected the finalizer to be removed")
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "owned"}, &rbacv1.RoleBinding{}); err != nil {
+		t.Errorf("expected the RoleBinding to be kept: %v", err)
+	}
+}
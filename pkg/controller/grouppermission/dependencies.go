@@ -0,0 +1,175 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileDependencies returns whether the GroupPermissions of the dependsOn of groupPermission are
+// all Active. Otherwise groupPermission waits with a DependencyNotReady condition, keeping the bindings
+// already applied, or fails with a DependencyCycle condition when its dependsOn leads back to it.
+func (r *ReconcileGroupPermission) reconcileDependencies(groupPermission *managedv1alpha1.GroupPermission) (bool, error) {
+	changed := false
+	if len(groupPermission.Spec.DependsOn) > 0 {
+		groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+		if err := r.client.List(context.TODO(), &client.ListOptions{Namespace: groupPermission.Namespace}, groupPermissionList); err != nil {
+			return false, err
+		}
+		groupPermissions := map[string]*managedv1alpha1.GroupPermission{}
+		for i := range groupPermissionList.Items {
+			if groupPermissionList.Items[i].Namespace == groupPermission.Namespace {
+				groupPermissions[groupPermissionList.Items[i].Name] = &groupPermissionList.Items[i]
+			}
+		}
+		groupPermissions[groupPermission.Name] = groupPermission
+
+		if cycle := dependencyCycle(groupPermission, groupPermissions); cycle != nil {
+			message := "dependsOn cycle " + strings.Join(cycle, " -> ")
+			changed = setDependencyCondition(groupPermission, message, managedv1alpha1.GroupPermissionFailed, managedv1alpha1.ReasonDependencyCycle)
+			if conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonDependencyNotReady) {
+				conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonDependencyNotReady)
+				changed = true
+			}
+			if changed {
+				return false, r.updateStatus(groupPermission)
+			}
+			return false, nil
+		}
+
+		if notReady := notReadyDependencies(groupPermission, groupPermissions); len(notReady) > 0 {
+			message := "Waiting for " + strings.Join(notReady, ", ")
+			changed = setDependencyCondition(groupPermission, message, managedv1alpha1.GroupPermissionWaiting, managedv1alpha1.ReasonDependencyNotReady)
+			if conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonDependencyCycle) {
+				conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonDependencyCycle)
+				changed = true
+			}
+			if changed {
+				return false, r.updateStatus(groupPermission)
+			}
+			return false, nil
+		}
+	}
+
+	for _, reason := range []managedv1alpha1.ConditionReason{managedv1alpha1.ReasonDependencyNotReady, managedv1alpha1.ReasonDependencyCycle} {
+		if conditions.IsTrue(groupPermission.Status.Conditions, reason) {
+			conditions.Deactivate(groupPermission.Status.Conditions, reason)
+			changed = true
+		}
+	}
+	if changed {
+		return true, r.updateStatus(groupPermission)
+	}
+	return true, nil
+}
+
+// setDependencyCondition activates the condition of groupPermission with reason, returning whether it
+// changed. An active one with the same message is kept as is.
+func setDependencyCondition(groupPermission *managedv1alpha1.GroupPermission, message string, state managedv1alpha1.GroupPermissionState, reason managedv1alpha1.ConditionReason) bool {
+	for _, condition := range groupPermission.Status.Conditions {
+		if condition.Reason == reason && condition.Status && condition.Message == message {
+			return false
+		}
+	}
+	updateCondition(groupPermission, message, "", true, state, reason)
+	return true
+}
+
+// dependencyCycle returns the names of a cycle of dependsOn from groupPermission back to it, starting
+// and ending with its name, or nil when there is none. groupPermissions are the GroupPermissions of its
+// namespace by name. Cycles not going through groupPermission are left to their members.
+func dependencyCycle(groupPermission *managedv1alpha1.GroupPermission, groupPermissions map[string]*managedv1alpha1.GroupPermission) []string {
+	visited := map[string]bool{}
+	var path []string
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		path = append(path, name)
+		if len(path) > 1 && name == groupPermission.Name {
+			return true
+		}
+		if !visited[name] {
+			visited[name] = true
+			if dependency, ok := groupPermissions[name]; ok {
+				for _, next := range dependency.Spec.DependsOn {
+					if visit(next) {
+						return true
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+
+	if visit(groupPermission.Name) {
+		return path
+	}
+	return nil
+}
+
+// notReadyDependencies describes each GroupPermission of the dependsOn of groupPermission that is not
+// Active, in the order of its dependsOn. groupPermissions are the GroupPermissions of its namespace by name.
+func notReadyDependencies(groupPermission *managedv1alpha1.GroupPermission, groupPermissions map[string]*managedv1alpha1.GroupPermission) []string {
+	var notReady []string
+	for _, name := range groupPermission.Spec.DependsOn {
+		dependency, ok := groupPermissions[name]
+		switch {
+		case !ok:
+			notReady = append(notReady, fmt.Sprintf("GroupPermission %s to be created", name))
+		case dependency.DeletionTimestamp != nil:
+			notReady = append(notReady, fmt.Sprintf("GroupPermission %s, being deleted", name))
+		case dependency.Status.Phase != managedv1alpha1.GroupPermissionPhaseActive:
+			phase := dependency.Status.Phase
+			if phase == "" {
+				phase = managedv1alpha1.GroupPermissionPhasePending
+			}
+			notReady = append(notReady, fmt.Sprintf("GroupPermission %s to be Active, it is %s", name, phase))
+		}
+	}
+	return notReady
+}
+
+// groupPermissionToDependents maps a GroupPermission to the GroupPermissions of the shard depending on
+// it, so they are reconciled as soon as it becomes Active or stops being
+type groupPermissionToDependents struct {
+	client client.Client
+	shard  *shard
+}
+
+// blank assignment to verify that groupPermissionToDependents implements handler.Mapper
+var _ handler.Mapper = &groupPermissionToDependents{}
+
+// Map implements handler.Mapper
+func (m *groupPermissionToDependents) Map(obj handler.MapObject) []reconcile.Request {
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	err := m.client.List(context.TODO(), &client.ListOptions{Namespace: obj.Meta.GetNamespace()}, groupPermissionList)
+	if err != nil {
+		log.Error(err, "Failed to list GroupPermissions", "GroupPermission", obj.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, groupPermission := range groupPermissionList.Items {
+		if groupPermission.Namespace != obj.Meta.GetNamespace() || !m.shard.matches(&groupPermission) {
+			continue
+		}
+		for _, name := range groupPermission.Spec.DependsOn {
+			if name == obj.Meta.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: groupPermission.Namespace,
+					Name:      groupPermission.Name,
+				}})
+				break
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,156 @@
+package grouppermission
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// mockDependentGroupPermission returns a GroupPermission named name, depending on dependsOn, in phase
+func mockDependentGroupPermission(name string, phase v1alpha1.GroupPermissionPhase, dependsOn ...string) *v1alpha1.GroupPermission {
+	groupPermission := mockGroupPermission()
+	groupPermission.Name = name
+	groupPermission.Spec.DependsOn = dependsOn
+	groupPermission.Status.Phase = phase
+	return groupPermission
+}
+
+// TestDependencyCycle tests the dependencyCycle function
+// given: GroupPermissions depending on each other in various ways
+// expected: the cycle through the GroupPermission when there is one, nil otherwise
+func TestDependencyCycle(t *testing.T) {
+	tests := []struct {
+		name     string
+		graph    map[string][]string
+		expected []string
+	}{
+		{
+			name:  "chain",
+			graph: map[string][]string{"a": {"b"}, "b": {"c"}},
+		},
+		{
+			name:  "diamond",
+			graph: map[string][]string{"a": {"b", "c"}, "b": {"d"}, "c": {"d"}},
+		},
+		{
+			name:     "self",
+			graph:    map[string][]string{"a": {"a"}},
+			expected: []string{"a", "a"},
+		},
+		{
+			name:     "cycle",
+			graph:    map[string][]string{"a": {"d", "b"}, "b": {"c"}, "c": {"a"}},
+			expected: []string{"a", "b", "c", "a"},
+		},
+		{
+			name:  "cycle of dependencies",
+			graph: map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"b"}},
+		},
+	}
+
+	for _, test := range tests {
+		groupPermissions := map[string]*v1alpha1.GroupPermission{}
+		for name, dependsOn := range test.graph {
+			groupPermissions[name] = mockDependentGroupPermission(name, "", dependsOn...)
+		}
+		if cycle := dependencyCycle(groupPermissions["a"], groupPermissions); !reflect.DeepEqual(cycle, test.expected) {
+			t.Errorf("FAILURE: %s. Expected(%v), Found(%v)", test.name, test.expected, cycle)
+		}
+	}
+}
+
+// TestReconcileDependencies tests the reconcileDependencies function
+// given: a GroupPermission depending on one Active, one Pending and one missing, then on the Active one only
+// expected: it waits with a DependencyNotReady condition naming the two others, then is ready with it cleared
+func TestReconcileDependencies(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockDependentGroupPermission("tenant", "", "templates", "base", "missing")
+	fakeClient := fake.NewFakeClient(groupPermission,
+		mockDependentGroupPermission("templates", v1alpha1.GroupPermissionPhaseActive),
+		mockDependentGroupPermission("base", v1alpha1.GroupPermissionPhasePending))
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme, recorder: record.NewFakeRecorder(10)}
+
+	ready, err := reconciler.reconcileDependencies(groupPermission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ready {
+		t.Errorf("expected the GroupPermission to wait for its dependencies")
+	}
+	expected := "Waiting for GroupPermission base to be Active, it is Pending, GroupPermission missing to be created"
+	condition := groupPermission.Status.Conditions[len(groupPermission.Status.Conditions)-1]
+	if condition.Reason != v1alpha1.ReasonDependencyNotReady || condition.State != v1alpha1.GroupPermissionWaiting || condition.Message != expected {
+		t.Errorf("Mismatch for condition. Expected(%s), Found(%+v)", expected, condition)
+	}
+
+	groupPermission.Spec.DependsOn = []string{"templates"}
+	ready, err = reconciler.reconcileDependencies(groupPermission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ready {
+		t.Errorf("expected the GroupPermission to be ready once its dependencies are Active")
+	}
+	if conditions.IsTrue(groupPermission.Status.Conditions, v1alpha1.ReasonDependencyNotReady) {
+		t.Errorf("expected the DependencyNotReady condition to be cleared")
+	}
+}
+
+// TestReconcileDependenciesCycle tests the reconcileDependencies function
+// given: two GroupPermissions depending on each other
+// expected: the GroupPermission is not ready, with a DependencyCycle condition describing the cycle
+func TestReconcileDependenciesCycle(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockDependentGroupPermission("a", "", "b")
+	fakeClient := fake.NewFakeClient(groupPermission, mockDependentGroupPermission("b", v1alpha1.GroupPermissionPhaseActive, "a"))
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme, recorder: record.NewFakeRecorder(10)}
+
+	ready, err := reconciler.reconcileDependencies(groupPermission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ready {
+		t.Errorf("expected a GroupPermission in a cycle not to be ready")
+	}
+	expected := "dependsOn cycle a -> b -> a"
+	condition := groupPermission.Status.Conditions[len(groupPermission.Status.Conditions)-1]
+	if condition.Reason != v1alpha1.ReasonDependencyCycle || condition.Message != expected {
+		t.Errorf("Mismatch for condition. Expected(%s), Found(%+v)", expected, condition)
+	}
+	if phase := groupPermissionPhase(groupPermission, true); phase != v1alpha1.GroupPermissionPhaseFailed {
+		t.Errorf("Mismatch for phase. Expected(Failed), Found(%s)", phase)
+	}
+}
+
+// TestGroupPermissionToDependents tests the Map function of groupPermissionToDependents
+// given: a GroupPermission depending on templates and one depending on nothing
+// expected: only the GroupPermission depending on templates is reconciled on an event of templates
+func TestGroupPermissionToDependents(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	templates := mockDependentGroupPermission("templates", v1alpha1.GroupPermissionPhaseActive)
+	mapper := &groupPermissionToDependents{client: fake.NewFakeClient(templates,
+		mockDependentGroupPermission("tenant", "", "templates"),
+		mockDependentGroupPermission("other", ""))}
+
+	requests := mapper.Map(handler.MapObject{Meta: templates, Object: templates})
+
+	if len(requests) != 1 || requests[0].Name != "tenant" {
+		t.Errorf("Mismatch for requests. Expected([tenant]), Found(%v)", requests)
+	}
+}
@@ -0,0 +1,193 @@
+package grouppermission
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/readonly"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// duplicateCheckInterval is the interval between two checks of the duplicate managed bindings
+	duplicateCheckInterval = 10 * time.Minute
+	// duplicateBindingRemovedReason is the reason of the event reporting a duplicate binding deleted
+	duplicateBindingRemovedReason = "DuplicateBindingRemoved"
+)
+
+// deduplicator deletes the managed bindings granting the same role to the same subjects in the same
+// scope as another managed binding, as left by renames or migrations, keeping a single canonical
+// binding of each. Only the bindings of the GroupPermissions of the shard are considered.
+type deduplicator struct {
+	reconciler *ReconcileGroupPermission
+	interval   time.Duration
+}
+
+// blank assignment to verify that deduplicator implements manager.Runnable
+var _ manager.Runnable = &deduplicator{}
+
+// newDeduplicator returns a deduplicator of the bindings managed for the GroupPermissions reconciled by r
+func newDeduplicator(r *ReconcileGroupPermission) *deduplicator {
+	return &deduplicator{reconciler: r, interval: duplicateCheckInterval}
+}
+
+// Start implements manager.Runnable, it checks the duplicate bindings until stop is closed
+func (d *deduplicator) Start(stop <-chan struct{}) error {
+	wait.Until(func() {
+		if _, err := d.check(context.TODO()); err != nil {
+			log.Error(err, "Failed to check duplicate bindings")
+		}
+	}, d.interval, stop)
+	return nil
+}
+
+// managedBinding is a managed ClusterRoleBinding or RoleBinding with the GroupPermission owning it
+type managedBinding struct {
+	obj        runtime.Object
+	kind       string
+	objectMeta *metav1.ObjectMeta
+	binding    verifiedBinding
+	owner      *managedv1alpha1.GroupPermission
+}
+
+// scope returns the scope of b, as used by bindingIdentity
+func (b managedBinding) scope() string {
+	if b.kind == bindinglock.ClusterRoleBinding {
+		return "ClusterRoleBinding "
+	}
+	return "RoleBinding " + b.objectMeta.Namespace + "/"
+}
+
+// currentName returns whether b is named after the current naming scheme
+func (b managedBinding) currentName() bool {
+	if len(b.binding.subjects) != 1 {
+		return false
+	}
+	if b.kind == bindinglock.ClusterRoleBinding {
+		return b.objectMeta.Name == newClusterRoleBinding(b.binding.roleRef.Name, b.binding.subjects[0]).Name
+	}
+	return b.objectMeta.Name == newRoleBinding(b.objectMeta.Namespace, b.binding.roleRef, b.binding.subjects[0]).Name
+}
+
+// check deletes the duplicate managed bindings, unless the operator is read-only, recording a Normal
+// event on the GroupPermission owning each. Returns the number of duplicates found.
+func (d *deduplicator) check(ctx context.Context) (int, error) {
+	r := d.reconciler
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	if err := r.client.List(ctx, &client.ListOptions{}, groupPermissionList); err != nil {
+		return 0, err
+	}
+	owners := map[types.NamespacedName]*managedv1alpha1.GroupPermission{}
+	for i := range groupPermissionList.Items {
+		groupPermission := &groupPermissionList.Items[i]
+		if r.shard.matches(groupPermission) && groupPermission.DeletionTimestamp == nil {
+			owners[types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}] = groupPermission
+		}
+	}
+
+	opts := &client.ListOptions{LabelSelector: utility.ManagedSelector()}
+	clusterRoleBindingList := &v1.ClusterRoleBindingList{}
+	if err := r.apiClient.List(ctx, opts, clusterRoleBindingList); err != nil {
+		return 0, err
+	}
+	roleBindingList := &v1.RoleBindingList{}
+	if err := r.apiClient.List(ctx, opts, roleBindingList); err != nil {
+		return 0, err
+	}
+
+	var bindings []managedBinding
+	add := func(obj runtime.Object, kind string, objectMeta *metav1.ObjectMeta, binding verifiedBinding) {
+		key, ok := utility.ManagedOwner(*objectMeta)
+		if !ok || owners[key] == nil {
+			// the orphans are left to the garbage collection, the bindings of other shards to their operator
+			return
+		}
+		bindings = append(bindings, managedBinding{obj: obj, kind: kind, objectMeta: objectMeta, binding: binding, owner: owners[key]})
+	}
+	for i := range clusterRoleBindingList.Items {
+		binding := &clusterRoleBindingList.Items[i]
+		add(binding, bindinglock.ClusterRoleBinding, &binding.ObjectMeta, verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects})
+	}
+	for i := range roleBindingList.Items {
+		binding := &roleBindingList.Items[i]
+		add(binding, bindinglock.RoleBinding, &binding.ObjectMeta, verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects})
+	}
+
+	found := 0
+	for _, group := range duplicateBindings(bindings) {
+		kept := group[0]
+		for _, duplicate := range group[1:] {
+			found++
+			if readonly.Enabled() {
+				continue
+			}
+			unlock := bindinglock.Lock(duplicate.kind, duplicate.objectMeta.Namespace, duplicate.objectMeta.Name)
+			err := r.client.Delete(ctx, duplicate.obj)
+			unlock()
+			if err != nil && !errors.IsNotFound(err) {
+				return found, err
+			}
+			r.eventf(duplicate.owner, corev1.EventTypeNormal, duplicateBindingRemovedReason, "Deleted %s %s, it grants %s %s to the same subjects as %s %s of GroupPermission %s/%s",
+				duplicate.kind, describeBinding(duplicate.objectMeta), duplicate.binding.roleRef.Kind, duplicate.binding.roleRef.Name,
+				kept.kind, describeBinding(kept.objectMeta), kept.owner.Namespace, kept.owner.Name)
+		}
+	}
+	return found, nil
+}
+
+// duplicateBindings returns the groups of bindings granting the same role to the same subjects in the
+// same scope, the canonical binding to keep first: the binding named after the current naming scheme,
+// then the oldest, then the first by name. Bindings without a duplicate are left out.
+func duplicateBindings(bindings []managedBinding) [][]managedBinding {
+	groups := map[string][]managedBinding{}
+	var identities []string
+	for _, binding := range bindings {
+		identity := bindingIdentity(binding.scope(), binding.binding)
+		if _, ok := groups[identity]; !ok {
+			identities = append(identities, identity)
+		}
+		groups[identity] = append(groups[identity], binding)
+	}
+	sort.Strings(identities)
+
+	var duplicates [][]managedBinding
+	for _, identity := range identities {
+		group := groups[identity]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool {
+			a, b := group[i], group[j]
+			if a.currentName() != b.currentName() {
+				return a.currentName()
+			}
+			if !a.objectMeta.CreationTimestamp.Equal(&b.objectMeta.CreationTimestamp) {
+				return a.objectMeta.CreationTimestamp.Before(&b.objectMeta.CreationTimestamp)
+			}
+			return a.objectMeta.Name < b.objectMeta.Name
+		})
+		duplicates = append(duplicates, group)
+	}
+	return duplicates
+}
+
+// describeBinding returns the name of the binding of objectMeta, with its namespace for a RoleBinding
+func describeBinding(objectMeta *metav1.ObjectMeta) string {
+	if objectMeta.Namespace == "" {
+		return objectMeta.Name
+	}
+	return objectMeta.Namespace + "/" + objectMeta.Name
+}
@@ -0,0 +1,100 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestDeduplicatorCheck tests the check function of the deduplicator
+// given: a GroupPermission with three RoleBindings of view to its Group in the same Namespace, one named
+// after the current naming scheme, and a RoleBinding of edit
+// expected: the two legacy RoleBindings of view are deleted with an event each, the others are kept
+func TestDeduplicatorCheck(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockGroupPermission()
+	owned := utility.ManagedLabels(groupPermission.Namespace, groupPermission.Name)
+	subjects := []rbacv1.Subject{{Kind: "Group", Name: "exampleGroupName"}}
+	older := metav1.Time{Time: time.Now().Add(-time.Hour)}
+	roleBinding := func(name string, roleName string, created metav1.Time) *rbacv1.RoleBinding {
+		return &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "apps", Name: name, Labels: owned, CreationTimestamp: created},
+			Subjects:   subjects,
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: roleName},
+		}
+	}
+	fakeClient := fake.NewFakeClient(groupPermission,
+		roleBinding("legacy-view", "view", older),
+		roleBinding("view-exampleGroupName", "view", metav1.Now()),
+		roleBinding("other-view", "view", metav1.Now()),
+		roleBinding("edit-exampleGroupName", "edit", metav1.Now()))
+	recorder := record.NewFakeRecorder(10)
+	d := newDeduplicator(&ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  recorder,
+	})
+
+	found, err := d.check(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if found != 2 {
+		t.Errorf("Mismatch for duplicates. Expected(2), Found(%d)", found)
+	}
+	for _, name := range []string{"legacy-view", "other-view"} {
+		if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "apps", Name: name}, &rbacv1.RoleBinding{}); !errors.IsNotFound(err) {
+			t.Errorf("expected RoleBinding %s to be deleted, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"view-exampleGroupName", "edit-exampleGroupName"} {
+		if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "apps", Name: name}, &rbacv1.RoleBinding{}); err != nil {
+			t.Errorf("expected RoleBinding %s to be kept: %v", name, err)
+		}
+	}
+	if len(recorder.Events) != 2 {
+		t.Fatalf("Mismatch for events. Expected(2), Found(%d)", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "DuplicateBindingRemoved Deleted RoleBinding apps/legacy-view, it grants ClusterRole view to the same subjects as RoleBinding apps/view-exampleGroupName") {
+		t.Errorf("expected a DuplicateBindingRemoved event for legacy-view, got %q", event)
+	}
+}
+
+// TestDuplicateBindings tests the duplicateBindings function
+// given: ClusterRoleBindings of view to the same Group, none named after the current naming scheme, and
+// a RoleBinding of view to the same Group
+// expected: a single group of the ClusterRoleBindings, the oldest first
+func TestDuplicateBindings(t *testing.T) {
+	subjects := []rbacv1.Subject{{Kind: "Group", APIGroup: rbacv1.GroupName, Name: "sre"}}
+	view := verifiedBinding{roleRef: rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"}, subjects: subjects}
+	older := metav1.Time{Time: time.Now().Add(-time.Hour)}
+	bindings := []managedBinding{
+		{kind: "ClusterRoleBinding", objectMeta: &metav1.ObjectMeta{Name: "b", CreationTimestamp: metav1.Now()}, binding: view},
+		{kind: "ClusterRoleBinding", objectMeta: &metav1.ObjectMeta{Name: "c", CreationTimestamp: older}, binding: view},
+		{kind: "RoleBinding", objectMeta: &metav1.ObjectMeta{Namespace: "apps", Name: "a", CreationTimestamp: older}, binding: view},
+	}
+
+	duplicates := duplicateBindings(bindings)
+	if len(duplicates) != 1 || len(duplicates[0]) != 2 {
+		t.Fatalf("Mismatch for duplicates. Expected(1 group of 2), Found(%v)", duplicates)
+	}
+	if name := duplicates[0][0].objectMeta.Name; name != "c" {
+		t.Errorf("Mismatch for canonical binding. Expected(c), Found(%s)", name)
+	}
+}
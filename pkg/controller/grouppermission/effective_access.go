@@ -0,0 +1,92 @@
+package grouppermission
+
+import (
+	"fmt"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// verifyEffectiveAccess checks, as the Group of groupPermission, that every rule of each ClusterPermission
+// resolves in sampleNamespace. This catches grants made ineffective by a missing or empty (e.g. aggregated
+// with no matching roles) ClusterRole.
+func (r *ReconcileGroupPermission) verifyEffectiveAccess(groupPermission *managedv1alpha1.GroupPermission, clusterRoleList *v1.ClusterRoleList, sampleNamespace string) ([]managedv1alpha1.EffectiveAccess, error) {
+	var effectiveAccess []managedv1alpha1.EffectiveAccess
+
+	group := &requester{groups: []string{groupPermission.Spec.GroupName}}
+	for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+		access := managedv1alpha1.EffectiveAccess{
+			ClusterRoleName: clusterRoleName,
+			Namespace:       sampleNamespace,
+			LastCheckTime:   metav1.Now(),
+		}
+
+		clusterRole := findClusterRole(clusterRoleName, clusterRoleList)
+		if clusterRole == nil {
+			access.Message = "ClusterRole does not exist"
+			effectiveAccess = append(effectiveAccess, access)
+			continue
+		}
+
+		sars := buildRuleSubjectAccessReviews(group, clusterRole.Rules, sampleNamespace)
+		if len(sars) == 0 {
+			access.Message = "ClusterRole has no rules"
+			effectiveAccess = append(effectiveAccess, access)
+			continue
+		}
+
+		access.Effective = true
+		for _, sar := range sars {
+			allowed, err := r.isAccessAllowed(sar)
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				access.Effective = false
+				access.Message = "Access does not resolve: " + describeSubjectAccessReview(sar)
+				break
+			}
+		}
+		effectiveAccess = append(effectiveAccess, access)
+	}
+
+	return effectiveAccess, nil
+}
+
+// describeSubjectAccessReview returns a short human readable form of the access checked by sar
+func describeSubjectAccessReview(sar *authorizationv1.SubjectAccessReview) string {
+	if attrs := sar.Spec.NonResourceAttributes; attrs != nil {
+		return fmt.Sprintf("%s %s", attrs.Verb, attrs.Path)
+	}
+
+	attrs := sar.Spec.ResourceAttributes
+	resource := attrs.Resource
+	if attrs.Group != "" {
+		resource = resource + "." + attrs.Group
+	}
+	if attrs.Subresource != "" {
+		resource = resource + "/" + attrs.Subresource
+	}
+	if attrs.Name != "" {
+		resource = resource + " " + attrs.Name
+	}
+	return fmt.Sprintf("%s %s", attrs.Verb, resource)
+}
+
+// isEffectiveAccessEqual compares two EffectiveAccess lists ignoring LastCheckTime
+func isEffectiveAccessEqual(a, b []managedv1alpha1.EffectiveAccess) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		x.LastCheckTime, y.LastCheckTime = metav1.Time{}, metav1.Time{}
+		if x != y {
+			return false
+		}
+	}
+	return true
+}
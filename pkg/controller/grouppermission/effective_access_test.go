@@ -0,0 +1,48 @@
+package grouppermission
+
+import (
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDescribeSubjectAccessReview tests the describeSubjectAccessReview function
+// given: resource and non-resource SubjectAccessReviews
+// expected: a short human readable description of each
+func TestDescribeSubjectAccessReview(t *testing.T) {
+	var tests = []struct {
+		sar      *authorizationv1.SubjectAccessReview
+		expected string
+	}{
+		{newSubjectAccessReview(&requester{}, &authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods"}, nil), "get pods"},
+		{newSubjectAccessReview(&requester{}, &authorizationv1.ResourceAttributes{Verb: "get", Group: "apps", Resource: "deployments", Subresource: "scale"}, nil), "get deployments.apps/scale"},
+		{newSubjectAccessReview(&requester{}, &authorizationv1.ResourceAttributes{Verb: "get", Resource: "configmaps", Name: "one"}, nil), "get configmaps one"},
+		{newSubjectAccessReview(&requester{}, nil, &authorizationv1.NonResourceAttributes{Verb: "get", Path: "/healthz"}), "get /healthz"},
+	}
+	for _, test := range tests {
+		if found := describeSubjectAccessReview(test.sar); found != test.expected {
+			t.Errorf("Expected(%s), Found(%s)", test.expected, found)
+		}
+	}
+}
+
+// TestIsEffectiveAccessEqual tests the isEffectiveAccessEqual function
+// given: EffectiveAccess lists which differ by LastCheckTime or content
+// expected: only content differences are reported
+func TestIsEffectiveAccessEqual(t *testing.T) {
+	a := []v1alpha1.EffectiveAccess{{ClusterRoleName: "view", Effective: true, LastCheckTime: metav1.Now()}}
+	b := []v1alpha1.EffectiveAccess{{ClusterRoleName: "view", Effective: true}}
+	c := []v1alpha1.EffectiveAccess{{ClusterRoleName: "view", Effective: false}}
+
+	if !isEffectiveAccessEqual(a, b) {
+		t.Errorf("expected lists differing only by LastCheckTime to be equal")
+	}
+	if isEffectiveAccessEqual(a, c) {
+		t.Errorf("expected lists with different Effective to differ")
+	}
+	if isEffectiveAccessEqual(a, nil) {
+		t.Errorf("expected lists with different length to differ")
+	}
+}
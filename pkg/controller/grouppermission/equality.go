@@ -0,0 +1,60 @@
+package grouppermission
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/rbac/v1"
+)
+
+// canonicalKinds are the kinds of roles and subjects by their lowercase name, the casing a binding is
+// written with does not change what it grants
+var canonicalKinds = map[string]string{
+	"clusterrole":    "ClusterRole",
+	"role":           "Role",
+	"group":          v1.GroupKind,
+	"user":           v1.UserKind,
+	"serviceaccount": v1.ServiceAccountKind,
+}
+
+// canonicalKind returns kind with the casing of its API, unknown kinds are returned unchanged
+func canonicalKind(kind string) string {
+	if canonical, ok := canonicalKinds[strings.ToLower(kind)]; ok {
+		return canonical
+	}
+	return kind
+}
+
+// normalizeBinding returns binding as the apiserver would store it, so bindings differing only by
+// defaulted fields compare equal: the API groups the apiserver defaults are set, kinds have the casing
+// of their API and subjects are sorted.
+func normalizeBinding(binding verifiedBinding) verifiedBinding {
+	normalized := verifiedBinding{roleRef: binding.roleRef}
+	normalized.roleRef.Kind = canonicalKind(binding.roleRef.Kind)
+	if normalized.roleRef.APIGroup == "" {
+		normalized.roleRef.APIGroup = v1.GroupName
+	}
+
+	normalized.subjects = make([]v1.Subject, len(binding.subjects))
+	for i, subject := range binding.subjects {
+		subject.Kind = canonicalKind(subject.Kind)
+		subject.APIGroup = subjectAPIGroup(subject)
+		normalized.subjects[i] = subject
+	}
+	sort.Slice(normalized.subjects, func(i, j int) bool {
+		return subjectKey(normalized.subjects[i]) < subjectKey(normalized.subjects[j])
+	})
+	return normalized
+}
+
+// subjectKey returns a key ordering the subjects of a binding
+func subjectKey(subject v1.Subject) string {
+	return subject.Kind + "/" + subject.APIGroup + "/" + subject.Namespace + "/" + subject.Name
+}
+
+// semanticallyEqual returns whether the bindings a and b grant the same role to the same subjects,
+// ignoring the differences introduced by the apiserver defaults
+func semanticallyEqual(a, b verifiedBinding) bool {
+	return reflect.DeepEqual(normalizeBinding(a), normalizeBinding(b))
+}
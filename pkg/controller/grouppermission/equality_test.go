@@ -0,0 +1,112 @@
+package grouppermission
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// TestSemanticallyEqualDefaulted tests the semanticallyEqual function
+// given: the bindings the operator creates, and the same bindings as returned by the apiserver
+// expected: the bindings are equal despite the defaulted fields
+func TestSemanticallyEqualDefaulted(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	objects, err := decodeObjects(filepath.Join("testdata", "defaulted", "bindings.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	subject := rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "exampleGroupName"}
+	for _, object := range objects {
+		var desired, stored verifiedBinding
+		switch binding := object.(type) {
+		case *rbacv1.ClusterRoleBinding:
+			created := newClusterRoleBinding(binding.RoleRef.Name, subject)
+			desired = verifiedBinding{roleRef: created.RoleRef, subjects: created.Subjects}
+			stored = verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects}
+		case *rbacv1.RoleBinding:
+			created := newRoleBinding(binding.Namespace, rbacv1.RoleRef{Kind: "ClusterRole", Name: binding.RoleRef.Name}, subject)
+			desired = verifiedBinding{roleRef: created.RoleRef, subjects: created.Subjects}
+			stored = verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects}
+		default:
+			t.Fatalf("unexpected object %T", object)
+		}
+		if !semanticallyEqual(desired, stored) {
+			t.Errorf("expected %+v to equal the stored %+v", desired, stored)
+		}
+	}
+}
+
+// TestSemanticallyEqual tests the semanticallyEqual function
+// given: bindings differing by benign fields and by what they grant
+// expected: the benign differences only are suppressed
+func TestSemanticallyEqual(t *testing.T) {
+	group := rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "exampleGroupName"}
+	user := rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"}
+	serviceAccount := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "ci", Name: "builder"}
+	binding := verifiedBinding{
+		roleRef:  rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "admin"},
+		subjects: []rbacv1.Subject{group, user, serviceAccount},
+	}
+
+	var tests = []struct {
+		label string
+		other verifiedBinding
+		equal bool
+	}{
+		{"same", binding, true},
+		{"no API groups", verifiedBinding{
+			roleRef: rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+			subjects: []rbacv1.Subject{
+				{Kind: rbacv1.GroupKind, Name: "exampleGroupName"}, {Kind: rbacv1.UserKind, Name: "alice"}, serviceAccount,
+			},
+		}, true},
+		{"kind casing", verifiedBinding{
+			roleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "clusterrole", Name: "admin"},
+			subjects: []rbacv1.Subject{
+				{Kind: "group", APIGroup: rbacv1.GroupName, Name: "exampleGroupName"}, user, {Kind: "serviceaccount", Namespace: "ci", Name: "builder"},
+			},
+		}, true},
+		{"subjects order", verifiedBinding{roleRef: binding.roleRef, subjects: []rbacv1.Subject{serviceAccount, user, group}}, true},
+		{"other role", verifiedBinding{roleRef: rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"}, subjects: binding.subjects}, false},
+		{"role instead of cluster role", verifiedBinding{roleRef: rbacv1.RoleRef{Kind: "Role", Name: "admin"}, subjects: binding.subjects}, false},
+		{"missing subject", verifiedBinding{roleRef: binding.roleRef, subjects: []rbacv1.Subject{group, user}}, false},
+		{"subject of another API group", verifiedBinding{roleRef: binding.roleRef, subjects: []rbacv1.Subject{
+			{Kind: rbacv1.GroupKind, APIGroup: "idp.example.com", Name: "exampleGroupName"}, user, serviceAccount,
+		}}, false},
+		{"role of another API group", verifiedBinding{roleRef: rbacv1.RoleRef{APIGroup: "authz.example.com", Kind: "ClusterRole", Name: "admin"}, subjects: binding.subjects}, false},
+	}
+
+	for _, test := range tests {
+		if equal := semanticallyEqual(binding, test.other); equal != test.equal {
+			t.Errorf("%s: expected equal %t, got %t", test.label, test.equal, equal)
+		}
+	}
+}
+
+// TestClusterPermissionBinding tests the clusterPermissionBinding function
+// given: a GroupPermission without a RoleRef API group, and one with a custom RoleRef API group
+// expected: the roleRef API group is left to the apiserver default, or set to the custom one
+func TestClusterPermissionBinding(t *testing.T) {
+	groupPermission := mockGroupPermission()
+	if binding := clusterPermissionBinding(&groupPermission.Spec, "view"); binding.RoleRef.APIGroup != "" || binding.Name != "view-exampleGroupName" {
+		t.Errorf("Mismatch for binding. Expected(view-exampleGroupName without API group), Found(%s %+v)", binding.Name, binding.RoleRef)
+	}
+
+	groupPermission.Spec.RoleRefAPIGroup = "authz.example.com"
+	binding := clusterPermissionBinding(&groupPermission.Spec, "view")
+	if binding.RoleRef.APIGroup != "authz.example.com" {
+		t.Errorf("Mismatch for roleRef API group. Expected(authz.example.com), Found(%s)", binding.RoleRef.APIGroup)
+	}
+	roleRef := permissionRoleRef(&groupPermission.Spec, v1alpha1.Permission{RoleName: "deployer"})
+	if roleRef.APIGroup != "authz.example.com" || roleRef.Kind != "Role" {
+		t.Errorf("Mismatch for roleRef. Expected(authz.example.com Role), Found(%+v)", roleRef)
+	}
+}
@@ -0,0 +1,104 @@
+package grouppermission
+
+import (
+	goerrors "errors"
+	"fmt"
+	"time"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// roleNotFoundRequeueInterval is the interval between reconciles of a GroupPermission granting a
+// ClusterRole that does not exist, ClusterRoles are not watched
+const roleNotFoundRequeueInterval = 5 * time.Minute
+
+// RoleNotFoundError is returned when a ClusterRole granted by a GroupPermission does not exist
+type RoleNotFoundError struct {
+	ClusterRoleName string
+}
+
+func (e *RoleNotFoundError) Error() string {
+	return e.ClusterRoleName + " for clusterPermission does not exist"
+}
+
+// ConflictError is returned when an object was changed or created concurrently with the reconcile
+type ConflictError struct {
+	Resource string
+	Name     string
+	Err      error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflict on %s %s: %v", e.Resource, e.Name, e.Err)
+}
+
+// ForbiddenError is returned when the operator is not allowed to make a call to the apiserver
+type ForbiddenError struct {
+	Resource string
+	Name     string
+	Err      error
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("operator is not allowed to access %s %s: %v", e.Resource, e.Name, e.Err)
+}
+
+// UnmanagedAccessError is returned in strict unmanaged access mode when the Group of a GroupPermission
+// has access beyond its spec through bindings not managed by the operator
+type UnmanagedAccessError struct {
+	GroupName string
+	Bindings  []string
+}
+
+func (e *UnmanagedAccessError) Error() string {
+	return fmt.Sprintf("Group %s has access beyond the GroupPermission through %d unmanaged bindings", e.GroupName, len(e.Bindings))
+}
+
+// typedError returns the typed error matching the apiserver error err, or err if there is none
+func typedError(err error) error {
+	var resource, name string
+	if status, ok := err.(errors.APIStatus); ok && status.Status().Details != nil {
+		resource, name = status.Status().Details.Kind, status.Status().Details.Name
+	}
+
+	switch {
+	case errors.IsNotFound(err) && resource == "clusterroles":
+		return &RoleNotFoundError{ClusterRoleName: name}
+	case errors.IsConflict(err), errors.IsAlreadyExists(err):
+		return &ConflictError{Resource: resource, Name: name, Err: err}
+	case errors.IsForbidden(err):
+		return &ForbiddenError{Resource: resource, Name: name, Err: err}
+	}
+	return err
+}
+
+// errorReason returns the reason of the condition recorded for a binding that failed with err
+func errorReason(err error) managedv1alpha1.ConditionReason {
+	switch err.(type) {
+	case *RoleNotFoundError:
+		return managedv1alpha1.ReasonClusterRoleMissing
+	case *ConflictError:
+		return managedv1alpha1.ReasonBindingConflict
+	case *ForbiddenError:
+		return managedv1alpha1.ReasonOperatorForbidden
+	}
+	return managedv1alpha1.ReasonBindingFailed
+}
+
+// requeueForError returns the result of a reconcile failed with err, and whether err is expected
+// and must not count against the failure budget. A missing ClusterRole is checked again later,
+// a conflict is retried right away with the current object. Other errors are retried with backoff.
+func requeueForError(err error) (reconcile.Result, bool) {
+	var roleNotFound *RoleNotFoundError
+	if goerrors.As(err, &roleNotFound) {
+		return reconcile.Result{RequeueAfter: roleNotFoundRequeueInterval}, true
+	}
+	var conflict *ConflictError
+	if goerrors.As(err, &conflict) {
+		return reconcile.Result{Requeue: true}, true
+	}
+	return reconcile.Result{}, false
+}
@@ -0,0 +1,87 @@
+package grouppermission
+
+import (
+	goerrors "errors"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestTypedError tests the typedError function
+// given: errors returned by the apiserver
+// expected: the matching typed error, or the error itself when there is none
+func TestTypedError(t *testing.T) {
+	clusterRoles := schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterroles"}
+	clusterRoleBindings := schema.GroupResource{Group: "rbac.authorization.k8s.io", Resource: "clusterrolebindings"}
+
+	var roleNotFound *RoleNotFoundError
+	if err := typedError(errors.NewNotFound(clusterRoles, "admin")); !goerrors.As(err, &roleNotFound) || roleNotFound.ClusterRoleName != "admin" {
+		t.Errorf("expected RoleNotFoundError for admin, got %v", err)
+	}
+
+	var conflict *ConflictError
+	if err := typedError(errors.NewAlreadyExists(clusterRoleBindings, "admin-group")); !goerrors.As(err, &conflict) || conflict.Name != "admin-group" {
+		t.Errorf("expected ConflictError for admin-group, got %v", err)
+	}
+	if err := typedError(errors.NewConflict(clusterRoleBindings, "admin-group", goerrors.New("modified"))); !goerrors.As(err, &conflict) {
+		t.Errorf("expected ConflictError, got %v", err)
+	}
+
+	var forbidden *ForbiddenError
+	if err := typedError(errors.NewForbidden(clusterRoleBindings, "admin-group", goerrors.New("denied"))); !goerrors.As(err, &forbidden) {
+		t.Errorf("expected ForbiddenError, got %v", err)
+	}
+
+	notFound := errors.NewNotFound(clusterRoleBindings, "admin-group")
+	if err := typedError(notFound); err != notFound {
+		t.Errorf("expected the NotFound error of a ClusterRoleBinding unchanged, got %v", err)
+	}
+	if err := typedError(nil); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+// TestRequeueForError tests the requeueForError function
+// given: typed and untyped errors
+// expected: missing ClusterRoles and conflicts are requeued without an error, other errors are not handled
+func TestRequeueForError(t *testing.T) {
+	tests := []struct {
+		err     error
+		result  reconcile.Result
+		handled bool
+	}{
+		{&RoleNotFoundError{ClusterRoleName: "admin"}, reconcile.Result{RequeueAfter: roleNotFoundRequeueInterval}, true},
+		{&ConflictError{Resource: "grouppermissions", Name: "test"}, reconcile.Result{Requeue: true}, true},
+		{&ForbiddenError{Resource: "clusterrolebindings", Name: "test"}, reconcile.Result{}, false},
+		{goerrors.New("connection refused"), reconcile.Result{}, false},
+	}
+	for _, test := range tests {
+		result, handled := requeueForError(test.err)
+		if result != test.result || handled != test.handled {
+			t.Errorf("%v: got %v, %t, want %v, %t", test.err, result, handled, test.result, test.handled)
+		}
+	}
+}
+
+// TestErrorReason tests the errorReason function
+// given: typed and untyped errors
+// expected: the reason code matching each error
+func TestErrorReason(t *testing.T) {
+	tests := []struct {
+		err    error
+		reason v1alpha1.ConditionReason
+	}{
+		{&RoleNotFoundError{ClusterRoleName: "admin"}, v1alpha1.ReasonClusterRoleMissing},
+		{&ConflictError{Resource: "clusterrolebindings", Name: "test"}, v1alpha1.ReasonBindingConflict},
+		{&ForbiddenError{Resource: "clusterrolebindings", Name: "test"}, v1alpha1.ReasonOperatorForbidden},
+		{goerrors.New("connection refused"), v1alpha1.ReasonBindingFailed},
+	}
+	for _, test := range tests {
+		if reason := errorReason(test.err); reason != test.reason {
+			t.Errorf("%v: got %s, want %s", test.err, reason, test.reason)
+		}
+	}
+}
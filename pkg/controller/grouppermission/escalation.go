@@ -0,0 +1,188 @@
+package grouppermission
+
+import (
+	"context"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// requester is the user recorded by the mutating webhook as the author of the GroupPermission spec
+type requester struct {
+	user   string
+	groups []string
+	uid    string
+	extra  map[string]authenticationv1.ExtraValue
+}
+
+// requesterFromAnnotations returns the recorded requester of groupPermission, or nil if none was recorded
+func requesterFromAnnotations(groupPermission *managedv1alpha1.GroupPermission) *requester {
+	userInfo, ok := utility.RequesterFromAnnotations(groupPermission.Annotations)
+	if !ok {
+		return nil
+	}
+	return &requester{user: userInfo.Username, groups: userInfo.Groups, uid: userInfo.UID, extra: userInfo.Extra}
+}
+
+// userInfo returns the user info of req, as submitted in its SubjectAccessReviews
+func (req *requester) userInfo() authenticationv1.UserInfo {
+	return authenticationv1.UserInfo{Username: req.user, Groups: req.groups, UID: req.uid, Extra: req.extra}
+}
+
+// isEscalationAllowed mirrors the RBAC escalation rules of the API server for the requester of
+// groupPermission: the requester may grant the role of roleRef in namespace, or cluster wide when
+// namespace is empty, if they hold the "bind" verb on it, or if they already hold every permission of
+// rules, the rules of the role.
+// A GroupPermission without a recorded requester cannot be verified, it is denied unless operatorConfig
+// allows unrecorded requesters.
+func (r *ReconcileGroupPermission) isEscalationAllowed(groupPermission *managedv1alpha1.GroupPermission, roleRef v1.RoleRef, rules []v1.PolicyRule, namespace string, operatorConfig *operatorconfig.OperatorConfig) (bool, error) {
+	req := requesterFromAnnotations(groupPermission)
+	if req == nil {
+		return operatorConfig.AllowUnrecordedRequesters, nil
+	}
+
+	// the bind verb allows granting the role without holding its permissions
+	allowed, err := r.isBindAllowed(req, roleRef, namespace)
+	if err != nil || allowed {
+		return allowed, err
+	}
+
+	// otherwise the requester must already hold every rule of the role
+	for _, sar := range buildRuleSubjectAccessReviews(req, rules, namespace) {
+		allowed, err := r.isAccessAllowed(sar)
+		if err != nil || !allowed {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// isMissingRoleEscalationAllowed returns whether the requester of groupPermission may bind the role of
+// roleRef, which does not exist, in namespace. Like the API server, only the "bind" verb allows it,
+// as the permissions of a missing role cannot be compared.
+func (r *ReconcileGroupPermission) isMissingRoleEscalationAllowed(groupPermission *managedv1alpha1.GroupPermission, roleRef v1.RoleRef, namespace string, operatorConfig *operatorconfig.OperatorConfig) (bool, error) {
+	req := requesterFromAnnotations(groupPermission)
+	if req == nil {
+		return operatorConfig.AllowUnrecordedRequesters, nil
+	}
+	return r.isBindAllowed(req, roleRef, namespace)
+}
+
+// isBindAllowed returns whether req holds the "bind" verb on the role of roleRef in namespace
+func (r *ReconcileGroupPermission) isBindAllowed(req *requester, roleRef v1.RoleRef, namespace string) (bool, error) {
+	resource := "clusterroles"
+	if roleRef.Kind == "Role" {
+		resource = "roles"
+	}
+	return r.isAccessAllowed(newSubjectAccessReview(req, &authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Group:     v1.GroupName,
+		Resource:  resource,
+		Verb:      "bind",
+		Name:      roleRef.Name,
+	}, nil))
+}
+
+// isRoleBindingEscalationAllowed returns whether the requester of groupPermission may grant the role of
+// roleBinding, created for permission: the Role built from the rules of permission, the ClusterRole or
+// the existing Role it names
+func (r *ReconcileGroupPermission) isRoleBindingEscalationAllowed(groupPermission *managedv1alpha1.GroupPermission, roleBinding *v1.RoleBinding, permission managedv1alpha1.Permission, operatorConfig *operatorconfig.OperatorConfig) (bool, error) {
+	roleRef, namespace := roleBinding.RoleRef, roleBinding.Namespace
+	if len(permission.Rules) > 0 {
+		return r.isRoleEscalationAllowed(groupPermission, roleRef, permission.Rules, namespace, operatorConfig)
+	}
+
+	var rules []v1.PolicyRule
+	var err error
+	if roleRef.Kind == "ClusterRole" {
+		clusterRole := &v1.ClusterRole{}
+		err = r.client.Get(context.TODO(), types.NamespacedName{Name: roleRef.Name}, clusterRole)
+		rules = clusterRole.Rules
+	} else {
+		// Roles are not cached, they are outside of the watched namespace
+		role := &v1.Role{}
+		err = r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: roleRef.Name}, role)
+		rules = role.Rules
+	}
+	if errors.IsNotFound(err) {
+		return r.isMissingRoleEscalationAllowed(groupPermission, roleRef, namespace, operatorConfig)
+	}
+	if err != nil {
+		return false, err
+	}
+	return r.isEscalationAllowed(groupPermission, roleRef, rules, namespace, operatorConfig)
+}
+
+// isRoleEscalationAllowed returns whether the requester of groupPermission may create the Role of
+// roleRef with rules in namespace and bind it. Like the API server, the requester must already hold every
+// permission of rules, or both the "escalate" and the "bind" verbs on the Role.
+func (r *ReconcileGroupPermission) isRoleEscalationAllowed(groupPermission *managedv1alpha1.GroupPermission, roleRef v1.RoleRef, rules []v1.PolicyRule, namespace string, operatorConfig *operatorconfig.OperatorConfig) (bool, error) {
+	req := requesterFromAnnotations(groupPermission)
+	if req == nil {
+		return operatorConfig.AllowUnrecordedRequesters, nil
+	}
+
+	holdsRules := true
+	for _, sar := range buildRuleSubjectAccessReviews(req, rules, namespace) {
+		allowed, err := r.isAccessAllowed(sar)
+		if err != nil {
+			return false, err
+		}
+		if !allowed {
+			holdsRules = false
+			break
+		}
+	}
+	if holdsRules {
+		return true, nil
+	}
+
+	allowed, err := r.isAccessAllowed(newSubjectAccessReview(req, &authorizationv1.ResourceAttributes{
+		Namespace: namespace,
+		Group:     v1.GroupName,
+		Resource:  "roles",
+		Verb:      "escalate",
+		Name:      roleRef.Name,
+	}, nil))
+	if err != nil || !allowed {
+		return false, err
+	}
+	return r.isBindAllowed(req, roleRef, namespace)
+}
+
+// escalationDeniedMessage returns the message of the EscalationDenied condition of a binding of
+// groupPermission to the role roleName
+func escalationDeniedMessage(groupPermission *managedv1alpha1.GroupPermission, roleName string) string {
+	if requesterFromAnnotations(groupPermission) == nil {
+		return "No requester was recorded for the GroupPermission, it cannot be verified to be allowed to bind " + roleName
+	}
+	return "Requester is not allowed to bind " + roleName
+}
+
+// isAccessAllowed submits the SubjectAccessReview and returns whether the access is allowed
+func (r *ReconcileGroupPermission) isAccessAllowed(sar *authorizationv1.SubjectAccessReview) (bool, error) {
+	err := r.client.Create(context.TODO(), sar)
+	if err != nil {
+		return false, err
+	}
+	return sar.Status.Allowed, nil
+}
+
+// buildRuleSubjectAccessReviews returns one SubjectAccessReview of req for every verb, resource and
+// non-resource URL contained in rules, see utility.RuleSubjectAccessReviews
+func buildRuleSubjectAccessReviews(req *requester, rules []v1.PolicyRule, namespace string) []*authorizationv1.SubjectAccessReview {
+	return utility.RuleSubjectAccessReviews(req.userInfo(), rules, namespace)
+}
+
+// newSubjectAccessReview creates and returns a SubjectAccessReview for the requester
+func newSubjectAccessReview(req *requester, resourceAttributes *authorizationv1.ResourceAttributes, nonResourceAttributes *authorizationv1.NonResourceAttributes) *authorizationv1.SubjectAccessReview {
+	return utility.NewSubjectAccessReview(req.userInfo(), resourceAttributes, nonResourceAttributes)
+}
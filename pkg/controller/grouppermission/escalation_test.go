@@ -0,0 +1,159 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// accessReviewClient answers the SubjectAccessReviews created with it like the apiserver would for the
+// users of allowed, who may do anything, and passes the other requests to the client it wraps
+type accessReviewClient struct {
+	client.Client
+	allowed map[string]bool
+}
+
+func (c *accessReviewClient) Create(ctx context.Context, obj runtime.Object) error {
+	if sar, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		sar.Status.Allowed = c.allowed[sar.Spec.User]
+		return nil
+	}
+	return c.Client.Create(ctx, obj)
+}
+
+// newRequesterClient returns a fake client with objs, answering the SubjectAccessReviews of the cluster-admin
+// requester of mockNamespacedGroupPermission as allowed
+func newRequesterClient(objs ...runtime.Object) client.Client {
+	return &accessReviewClient{Client: fake.NewFakeClient(objs...), allowed: map[string]bool{"cluster-admin": true}}
+}
+
+// TestIsEscalationAllowed tests the isEscalationAllowed and escalationDeniedMessage functions
+// given: GroupPermissions without a recorded requester, or requested by a user allowed to grant any role
+// or by one who is not, with and without unrecorded requesters allowed by the operator config
+// expected: only the allowed requester, and the unrecorded one when the operator config allows it, may
+// grant the ClusterRole, the denial of an unrecorded requester says no requester was recorded
+func TestIsEscalationAllowed(t *testing.T) {
+	clusterRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+		Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}}},
+	}
+	var tests = []struct {
+		name        string
+		requester   string
+		allowUnset  bool
+		expected    bool
+		description string
+	}{
+		{"no requester", "", false, false, "No requester was recorded"},
+		{"no requester allowed by the operator config", "", true, true, ""},
+		{"allowed requester", "alice", false, true, ""},
+		{"denied requester", "mallory", true, false, "Requester is not allowed"},
+	}
+	for _, test := range tests {
+		groupPermission := mockGroupPermission()
+		if test.requester != "" {
+			groupPermission.Annotations = map[string]string{operatorconfig.RequesterAnnotation: test.requester}
+		}
+		operatorConfig := operatorconfig.DefaultOperatorConfig()
+		operatorConfig.AllowUnrecordedRequesters = test.allowUnset
+		reconciler := &ReconcileGroupPermission{client: &accessReviewClient{Client: fake.NewFakeClient(), allowed: map[string]bool{"alice": true}}}
+
+		allowed, err := reconciler.isEscalationAllowed(groupPermission, rbacv1.RoleRef{Kind: "ClusterRole", Name: clusterRole.Name}, clusterRole.Rules, "", operatorConfig)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if allowed != test.expected {
+			t.Errorf("%s: Mismatch for allowed. Expected(%t), Found(%t)", test.name, test.expected, allowed)
+		}
+		if message := escalationDeniedMessage(groupPermission, clusterRole.Name); test.description != "" && !strings.HasPrefix(message, test.description) {
+			t.Errorf("%s: Mismatch for message. Expected(%s...), Found(%s)", test.name, test.description, message)
+		}
+	}
+}
+
+// TestRequesterFromAnnotations tests the requesterFromAnnotations function
+// given: GroupPermission with and without requester annotations
+// expected: the recorded requester, with its UID and extra information, or nil when none is recorded
+func TestRequesterFromAnnotations(t *testing.T) {
+	gp := mockGroupPermission()
+	if req := requesterFromAnnotations(gp); req != nil {
+		t.Errorf("expected no requester, got %v", req)
+	}
+
+	gp.Annotations = map[string]string{
+		operatorconfig.RequesterAnnotation:       "alice",
+		operatorconfig.RequesterGroupsAnnotation: "team-a,system:authenticated",
+		operatorconfig.RequesterUIDAnnotation:    "alice-uid",
+		operatorconfig.RequesterExtraAnnotation:  `{"scopes.authorization.openshift.io":["user:info"]}`,
+	}
+	req := requesterFromAnnotations(gp)
+	if req == nil {
+		t.Fatalf("expected a requester, got nil")
+	}
+	if req.user != "alice" {
+		t.Errorf("got user %s, want alice", req.user)
+	}
+	if len(req.groups) != 2 || req.groups[0] != "team-a" || req.groups[1] != "system:authenticated" {
+		t.Errorf("got groups %v, want [team-a system:authenticated]", req.groups)
+	}
+	// the scopes restrict the rights checked for the requester
+	sar := newSubjectAccessReview(req, &authorizationv1.ResourceAttributes{Verb: "bind", Resource: "clusterroles"}, nil)
+	if sar.Spec.UID != "alice-uid" || len(sar.Spec.Extra["scopes.authorization.openshift.io"]) != 1 {
+		t.Errorf("got uid %s and extra %v, want alice-uid and the scopes", sar.Spec.UID, sar.Spec.Extra)
+	}
+}
+
+// TestBuildRuleSubjectAccessReviews tests the buildRuleSubjectAccessReviews function
+// given: a ClusterRole with resource, subresource, resourceName and non-resource rules
+// expected: one SubjectAccessReview for every combination in the rules
+func TestBuildRuleSubjectAccessReviews(t *testing.T) {
+	clusterRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "example"},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods", "pods/log"},
+				Verbs:     []string{"get", "list"},
+			},
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"configmaps"},
+				ResourceNames: []string{"one", "two"},
+				Verbs:         []string{"get"},
+			},
+			{
+				NonResourceURLs: []string{"/healthz"},
+				Verbs:           []string{"get"},
+			},
+		},
+	}
+
+	sars := buildRuleSubjectAccessReviews(&requester{user: "alice"}, clusterRole.Rules, "")
+
+	// 2 resources * 2 verbs + 2 resourceNames + 1 non-resource URL
+	if len(sars) != 7 {
+		t.Fatalf("got %d SubjectAccessReviews, want 7", len(sars))
+	}
+	for _, sar := range sars {
+		if sar.Spec.User != "alice" {
+			t.Errorf("got user %s, want alice", sar.Spec.User)
+		}
+	}
+	if attrs := sars[1].Spec.ResourceAttributes; attrs.Resource != "pods" || attrs.Subresource != "log" {
+		t.Errorf("got resource %s/%s, want pods/log", attrs.Resource, attrs.Subresource)
+	}
+	if attrs := sars[5].Spec.ResourceAttributes; attrs.Name != "two" {
+		t.Errorf("got resourceName %s, want two", attrs.Name)
+	}
+	if attrs := sars[6].Spec.NonResourceAttributes; attrs == nil || attrs.Path != "/healthz" {
+		t.Errorf("got %v, want non-resource attributes for /healthz", attrs)
+	}
+}
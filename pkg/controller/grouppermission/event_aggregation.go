@@ -0,0 +1,180 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// eventAggregationCheckInterval is the interval between two checks of the aggregated events due
+	// for a summary
+	eventAggregationCheckInterval = 10 * time.Second
+	// eventSummarySampleSize is the maximum number of Namespaces named by a summary event
+	eventSummarySampleSize = 10
+)
+
+// aggregatedEventKey identifies the events summarized together: those of a GroupPermission with the
+// same type and reason
+type aggregatedEventKey struct {
+	groupPermission types.NamespacedName
+	eventtype       string
+	reason          string
+}
+
+// aggregatedEvents are the Namespaces of the events of a key since its last summary
+type aggregatedEvents struct {
+	// groupPermission is the latest copy of the GroupPermission, the object of the summary
+	groupPermission *managedv1alpha1.GroupPermission
+	namespaces      map[string]bool
+	since           time.Time
+}
+
+// eventAggregator applies the event verbosity of the operator config to the events of the
+// GroupPermissions, and aggregates their per-namespace events into periodic summaries in summary mode
+type eventAggregator struct {
+	reconciler *ReconcileGroupPermission
+	now        func() time.Time
+
+	mu        sync.Mutex
+	verbosity operatorconfig.EventVerbosity
+	interval  time.Duration
+	pending   map[aggregatedEventKey]*aggregatedEvents
+}
+
+// blank assignment to verify that eventAggregator implements manager.Runnable
+var _ manager.Runnable = &eventAggregator{}
+
+// newEventAggregator returns an eventAggregator of the events recorded by r, with the default
+// configuration until configure is called
+func newEventAggregator(r *ReconcileGroupPermission) *eventAggregator {
+	defaults := operatorconfig.DefaultOperatorConfig()
+	return &eventAggregator{
+		reconciler: r,
+		now:        time.Now,
+		verbosity:  defaults.EventVerbosity,
+		interval:   defaults.EventSummaryInterval,
+		pending:    map[aggregatedEventKey]*aggregatedEvents{},
+	}
+}
+
+// configure applies the event verbosity and summary interval of operatorConfig, nothing is done when a is nil
+func (a *eventAggregator) configure(operatorConfig *operatorconfig.OperatorConfig) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.verbosity = operatorConfig.EventVerbosity
+	a.interval = operatorConfig.EventSummaryInterval
+}
+
+// enabled returns whether events are recorded, they always are when a is nil
+func (a *eventAggregator) enabled() bool {
+	if a == nil {
+		return true
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.verbosity != operatorconfig.EventsNone
+}
+
+// add takes the event of groupPermission about namespace, and returns whether it did: in summary mode
+// the event is part of the next summary, when no event is recorded it is dropped. The event is left to
+// be recorded on its own in verbose mode, or when a is nil.
+func (a *eventAggregator) add(groupPermission *managedv1alpha1.GroupPermission, namespace, eventtype, reason string) bool {
+	if a == nil {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch a.verbosity {
+	case operatorconfig.EventsVerbose:
+		return false
+	case operatorconfig.EventsNone:
+		return true
+	}
+
+	key := aggregatedEventKey{
+		groupPermission: types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name},
+		eventtype:       eventtype,
+		reason:          reason,
+	}
+	events, ok := a.pending[key]
+	if !ok {
+		events = &aggregatedEvents{namespaces: map[string]bool{}, since: a.now()}
+		a.pending[key] = events
+	}
+	events.groupPermission = groupPermission.DeepCopy()
+	events.namespaces[namespace] = true
+	return true
+}
+
+// Start implements manager.Runnable, it records the summaries due and follows the changes of the
+// operator config until stop is closed
+func (a *eventAggregator) Start(stop <-chan struct{}) error {
+	wait.Until(func() {
+		operatorConfig, err := a.reconciler.getOperatorConfig(context.TODO())
+		if err != nil {
+			log.Error(err, "Failed to get operator config, keeping the previous event verbosity")
+		} else {
+			a.configure(operatorConfig)
+		}
+		a.flush()
+	}, eventAggregationCheckInterval, stop)
+	return nil
+}
+
+// flush records a summary of the events aggregated for the summary interval, or of all of them once
+// the verbosity is no longer summary. Returns the number of summaries.
+func (a *eventAggregator) flush() int {
+	a.mu.Lock()
+	now := a.now()
+	var due []aggregatedEventKey
+	for key, events := range a.pending {
+		if a.verbosity != operatorconfig.EventsSummary || now.Sub(events.since) >= a.interval {
+			due = append(due, key)
+		}
+	}
+	summaries := make([]*aggregatedEvents, len(due))
+	for i, key := range due {
+		summaries[i] = a.pending[key]
+		delete(a.pending, key)
+	}
+	a.mu.Unlock()
+
+	// recorded outside of the lock, eventf checks the verbosity
+	for i, key := range due {
+		a.reconciler.eventf(summaries[i].groupPermission, key.eventtype, key.reason, "%s", eventSummaryMessage(key.reason, summaries[i], now))
+	}
+	return len(due)
+}
+
+// eventSummaryMessage returns the message of the summary of events of reason at now, naming up to
+// eventSummarySampleSize of their Namespaces
+func eventSummaryMessage(reason string, events *aggregatedEvents, now time.Time) string {
+	var namespaces []string
+	for namespace := range events.namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	sample := namespaces
+	if len(sample) > eventSummarySampleSize {
+		sample = sample[:eventSummarySampleSize]
+	}
+	message := fmt.Sprintf("%s in %d Namespaces in the last %s: %s", reason, len(namespaces), now.Sub(events.since).Round(time.Second), strings.Join(sample, ", "))
+	if len(sample) < len(namespaces) {
+		message += fmt.Sprintf(" and %d more", len(namespaces)-len(sample))
+	}
+	return message
+}
@@ -0,0 +1,113 @@
+package grouppermission
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// newAggregatingReconciler returns a ReconcileGroupPermission recording its events in recorder through an
+// eventAggregator with verbosity, whose clock is now
+func newAggregatingReconciler(recorder *record.FakeRecorder, verbosity operatorconfig.EventVerbosity, now *time.Time) *ReconcileGroupPermission {
+	reconciler := &ReconcileGroupPermission{recorder: recorder}
+	reconciler.events = newEventAggregator(reconciler)
+	reconciler.events.now = func() time.Time { return *now }
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+	operatorConfig.EventVerbosity = verbosity
+	reconciler.events.configure(operatorConfig)
+	return reconciler
+}
+
+// TestEventAggregatorSummary tests the eventAggregator in summary mode
+// given: per-namespace events of a GroupPermission with the same reason, one of them twice, and an
+// event of the GroupPermission itself
+// expected: the event of the GroupPermission is recorded right away, the per-namespace events once in
+// a single summary when the summary interval elapsed
+func TestEventAggregatorSummary(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	recorder := record.NewFakeRecorder(10)
+	reconciler := newAggregatingReconciler(recorder, operatorconfig.EventsSummary, &now)
+	groupPermission := mockNamespacedGroupPermission()
+
+	for _, namespace := range []string{"team-b", "team-a", "team-b"} {
+		reconciler.namespacedEventf(groupPermission, namespace, corev1.EventTypeWarning, "PendingRemoval", "Role in Namespace %s", namespace)
+	}
+	reconciler.eventf(groupPermission, corev1.EventTypeNormal, "GroupCreated", "Created Group")
+	if len(recorder.Events) != 1 || !strings.Contains(<-recorder.Events, "GroupCreated") {
+		t.Fatalf("expected only the GroupCreated event before the summary")
+	}
+
+	now = now.Add(time.Minute)
+	if summaries := reconciler.events.flush(); summaries != 0 || len(recorder.Events) != 0 {
+		t.Fatalf("expected no summary before the summary interval, got %d", summaries)
+	}
+	now = now.Add(4 * time.Minute)
+	if summaries := reconciler.events.flush(); summaries != 1 {
+		t.Fatalf("Mismatch for summaries. Expected(1), Found(%d)", summaries)
+	}
+	expected := "Warning PendingRemoval PendingRemoval in 2 Namespaces in the last 5m0s: team-a, team-b"
+	if event := <-recorder.Events; event != expected {
+		t.Errorf("Mismatch for summary. Expected(%s), Found(%s)", expected, event)
+	}
+	if summaries := reconciler.events.flush(); summaries != 0 {
+		t.Errorf("expected the events to be summarized once, got %d more summaries", summaries)
+	}
+}
+
+// TestEventAggregatorVerbosity tests the eventAggregator in verbose and none modes, and a switch from
+// summary to verbose
+// given: per-namespace events in each mode
+// expected: every event is recorded on its own in verbose mode, none in none mode, and the pending
+// events are summarized right away once the mode is no longer summary
+func TestEventAggregatorVerbosity(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	groupPermission := mockNamespacedGroupPermission()
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := newAggregatingReconciler(recorder, operatorconfig.EventsVerbose, &now)
+	reconciler.namespacedEventf(groupPermission, "team-a", corev1.EventTypeWarning, "PendingRemoval", "Role in Namespace %s", "team-a")
+	reconciler.namespacedEventf(groupPermission, "team-b", corev1.EventTypeWarning, "PendingRemoval", "Role in Namespace %s", "team-b")
+	if len(recorder.Events) != 2 {
+		t.Errorf("Mismatch for verbose events. Expected(2), Found(%d)", len(recorder.Events))
+	}
+
+	recorder = record.NewFakeRecorder(10)
+	reconciler = newAggregatingReconciler(recorder, operatorconfig.EventsNone, &now)
+	reconciler.namespacedEventf(groupPermission, "team-a", corev1.EventTypeWarning, "PendingRemoval", "Role in Namespace %s", "team-a")
+	reconciler.eventf(groupPermission, corev1.EventTypeNormal, "GroupCreated", "Created Group")
+	if summaries := reconciler.events.flush(); summaries != 0 || len(recorder.Events) != 0 {
+		t.Errorf("expected no event in none mode, got %d events and %d summaries", len(recorder.Events), summaries)
+	}
+
+	recorder = record.NewFakeRecorder(10)
+	reconciler = newAggregatingReconciler(recorder, operatorconfig.EventsSummary, &now)
+	reconciler.namespacedEventf(groupPermission, "team-a", corev1.EventTypeWarning, "PendingRemoval", "Role in Namespace %s", "team-a")
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+	operatorConfig.EventVerbosity = operatorconfig.EventsVerbose
+	reconciler.events.configure(operatorConfig)
+	if summaries := reconciler.events.flush(); summaries != 1 || len(recorder.Events) != 1 {
+		t.Errorf("expected the pending events to be summarized when switching to verbose, got %d summaries", summaries)
+	}
+}
+
+// TestEventSummaryMessage tests the eventSummaryMessage function
+// given: the events of more Namespaces than the sample size
+// expected: the message names the first Namespaces of the sample in order and counts the others
+func TestEventSummaryMessage(t *testing.T) {
+	since := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	events := &aggregatedEvents{namespaces: map[string]bool{}, since: since}
+	for i := 0; i < eventSummarySampleSize+2; i++ {
+		events.namespaces[fmt.Sprintf("team-%02d", i)] = true
+	}
+	message := eventSummaryMessage("ProtectedNamespaceDeleted", events, since.Add(90*time.Second))
+	expected := "ProtectedNamespaceDeleted in 12 Namespaces in the last 1m30s: team-00, team-01, team-02, team-03, team-04, team-05, team-06, team-07, team-08, team-09 and 2 more"
+	if message != expected {
+		t.Errorf("Mismatch for message. Expected(%s), Found(%s)", expected, message)
+	}
+}
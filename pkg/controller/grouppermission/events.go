@@ -0,0 +1,29 @@
+package grouppermission
+
+import (
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+)
+
+// eventf records an event of groupPermission, annotated with the team owning it so its failures can be
+// routed to that team, and with its description and tags for auditors. Nothing is recorded when the
+// event verbosity is none.
+func (r *ReconcileGroupPermission) eventf(groupPermission *managedv1alpha1.GroupPermission, eventtype, reason, messageFmt string, args ...interface{}) {
+	if r.recorder == nil || !r.events.enabled() {
+		return
+	}
+	if annotations := utility.AuditAnnotations(&groupPermission.Spec); annotations != nil {
+		r.recorder.AnnotatedEventf(groupPermission, annotations, eventtype, reason, messageFmt, args...)
+		return
+	}
+	r.recorder.Eventf(groupPermission, eventtype, reason, messageFmt, args...)
+}
+
+// namespacedEventf records an event of groupPermission about namespace like eventf, unless the event
+// verbosity aggregates it into a periodic summary of the events of groupPermission with reason
+func (r *ReconcileGroupPermission) namespacedEventf(groupPermission *managedv1alpha1.GroupPermission, namespace, eventtype, reason, messageFmt string, args ...interface{}) {
+	if r.recorder == nil || r.events.add(groupPermission, namespace, eventtype, reason) {
+		return
+	}
+	r.eventf(groupPermission, eventtype, reason, messageFmt, args...)
+}
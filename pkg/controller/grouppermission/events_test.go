@@ -0,0 +1,51 @@
+package grouppermission
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// annotatedRecorder records the annotations of the events, the FakeRecorder drops them
+type annotatedRecorder struct {
+	*record.FakeRecorder
+	annotations []map[string]string
+}
+
+func (r *annotatedRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.annotations = append(r.annotations, annotations)
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// TestEventfOwnerRef tests the eventf function
+// given: a GroupPermission without and with an owner to notify
+// expected: the events are annotated with the owner when it is set only
+func TestEventfOwnerRef(t *testing.T) {
+	recorder := &annotatedRecorder{FakeRecorder: record.NewFakeRecorder(10)}
+	reconciler := &ReconcileGroupPermission{recorder: recorder}
+
+	groupPermission := mockGroupPermission()
+	reconciler.eventf(groupPermission, corev1.EventTypeNormal, "Test", "event %d", 1)
+	if len(recorder.annotations) != 0 {
+		t.Errorf("expected no annotated event, got %v", recorder.annotations)
+	}
+
+	groupPermission.Spec.Notify = &v1alpha1.Notify{OwnerRef: "team-sre@example.com"}
+	reconciler.eventf(groupPermission, corev1.EventTypeWarning, "Test", "event %d", 2)
+	expected := []map[string]string{{operatorconfig.OwnerRefAnnotation: "team-sre@example.com"}}
+	if !reflect.DeepEqual(recorder.annotations, expected) {
+		t.Errorf("expected annotations %v, got %v", expected, recorder.annotations)
+	}
+	for _, event := range []string{"Normal Test event 1", "Warning Test event 2"} {
+		if got := <-recorder.Events; got != event {
+			t.Errorf("expected event %q, got %q", event, got)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// excludedRoles returns the ClusterRoles that must not be bound in namespace
+func excludedRoles(namespace metav1.Object) []string {
+	var roles []string
+	for _, role := range strings.Split(namespace.GetAnnotations()[operatorconfig.ExcludeRolesAnnotation], ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// isRoleExcluded returns whether namespace excludes the ClusterRole of roleRef. Roles are never
+// excluded, they are defined in the Namespace itself.
+func isRoleExcluded(roleRef v1.RoleRef, namespace metav1.Object) bool {
+	if roleRef.Kind != "ClusterRole" {
+		return false
+	}
+	for _, role := range excludedRoles(namespace) {
+		if role == roleRef.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// roleExcludedMessage returns the error of a RoleBinding not created in namespace because its
+// ClusterRole is excluded there
+func roleExcludedMessage(roleRef v1.RoleRef, namespace metav1.Object) string {
+	return fmt.Sprintf("Namespace %s excludes the ClusterRole %s by its %s annotation", namespace.GetName(), roleRef.Name, operatorconfig.ExcludeRolesAnnotation)
+}
+
+// deleteDeniedRoleBinding deletes the RoleBinding managed for groupPermission like roleBinding, which its
+// Namespace denied after it was created, by excluding its ClusterRole or the requester from its granters
+func (r *ReconcileGroupPermission) deleteDeniedRoleBinding(groupPermission *managedv1alpha1.GroupPermission, roleBinding *v1.RoleBinding) error {
+	existing := &v1.RoleBinding{}
+	err := r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: roleBinding.Namespace, Name: roleBinding.Name}, existing)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !utility.IsManagedFor(existing.ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+		return nil
+	}
+
+	defer bindinglock.Lock(bindinglock.RoleBinding, existing.Namespace, existing.Name)()
+	if err := r.client.Delete(context.TODO(), existing); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// mockExcludeRolesNamespace returns the Namespace name excluding the ClusterRoles roles
+func mockExcludeRolesNamespace(name, roles string) *corev1.Namespace {
+	namespace := mockNamespace(name)
+	namespace.Annotations = map[string]string{operatorconfig.ExcludeRolesAnnotation: roles}
+	return namespace
+}
+
+// TestIsRoleExcluded tests the isRoleExcluded function
+// given: Namespaces excluding ClusterRoles or not, and references to ClusterRoles and Roles
+// expected: only the ClusterRoles listed by the Namespace are excluded
+func TestIsRoleExcluded(t *testing.T) {
+	var tests = []struct {
+		name     string
+		roles    string
+		roleRef  v1.RoleRef
+		excluded bool
+	}{
+		{"no exclusion", "", v1.RoleRef{Kind: "ClusterRole", Name: "admin"}, false},
+		{"excluded", "admin", v1.RoleRef{Kind: "ClusterRole", Name: "admin"}, true},
+		{"one of the excluded", "edit, admin", v1.RoleRef{Kind: "ClusterRole", Name: "admin"}, true},
+		{"not excluded", "edit,admin", v1.RoleRef{Kind: "ClusterRole", Name: "view"}, false},
+		{"Role of an excluded name", "admin", v1.RoleRef{Kind: "Role", Name: "admin"}, false},
+	}
+	for _, test := range tests {
+		namespace := mockExcludeRolesNamespace("team-a", test.roles)
+		if excluded := isRoleExcluded(test.roleRef, namespace); excluded != test.excluded {
+			t.Errorf("%s: Mismatch for excluded. Expected(%t), Found(%t)", test.name, test.excluded, excluded)
+		}
+	}
+}
+
+// TestReconcileNamespacePermissionsExcludedRoles tests the reconcileNamespacePermissions function
+// given: GroupPermission binding admin, allowed in a Namespace excluding admin where its RoleBinding was
+// created before the exclusion, and in a Namespace excluding edit only
+// expected: the RoleBinding is deleted from the Namespace excluding admin, which is failed with the
+// reason, and created in the other Namespace
+func TestReconcileNamespacePermissionsExcludedRoles(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	existing := newRoleBinding("team-a", v1.RoleRef{Kind: "ClusterRole", Name: "admin"}, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&existing.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	fakeClient := newRequesterClient(existing, mockExcludeRolesNamespace("team-a", "admin"), mockExcludeRolesNamespace("team-b", "edit"))
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(groupPermission, nil, nil, nil, operatorconfig.DefaultOperatorConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	states := map[string]v1alpha1.NamespaceStatus{}
+	for _, namespaceStatus := range namespaceStatuses {
+		states[namespaceStatus.Namespace] = namespaceStatus
+	}
+	if states["team-a"].State != v1alpha1.GroupPermissionFailed || !strings.Contains(states["team-a"].LastError, "excludes the ClusterRole admin") {
+		t.Errorf("Mismatch for team-a. Expected(Failed, ClusterRole excluded), Found(%+v)", states["team-a"])
+	}
+	if states["team-b"].State != v1alpha1.GroupPermissionCreated {
+		t.Errorf("Mismatch for team-b. Expected(%s), Found(%s)", v1alpha1.GroupPermissionCreated, states["team-b"].State)
+	}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: existing.Name}, &v1.RoleBinding{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected the RoleBinding in team-a to be deleted, got %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-b", Name: states["team-b"].BindingName}, &v1.RoleBinding{}); err != nil {
+		t.Errorf("expected the RoleBinding in team-b, got %v", err)
+	}
+}
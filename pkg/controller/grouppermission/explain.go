@@ -0,0 +1,54 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// explainNamespace returns why each Permission of groupPermission matches the Namespace named by its
+// explain-namespace annotation or not, or nil when it is not annotated
+func (r *ReconcileGroupPermission) explainNamespace(groupPermission *managedv1alpha1.GroupPermission, operatorConfig *operatorconfig.OperatorConfig) (*managedv1alpha1.NamespaceExplanation, error) {
+	name := groupPermission.Annotations[operatorconfig.ExplainNamespaceAnnotation]
+	if name == "" {
+		return nil, nil
+	}
+	explanation := &managedv1alpha1.NamespaceExplanation{Namespace: name}
+
+	namespace := &corev1.Namespace{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name}, namespace)
+	if errors.IsNotFound(err) {
+		explanation.Reason = "the Namespace does not exist"
+		return explanation, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if operatorConfig.IsProtectedNamespace(name) {
+		explanation.Reason = "the Namespace is protected by the operator configuration, no permission applies in it"
+	}
+
+	for _, permission := range groupPermission.Spec.Permissions {
+		explanation.Permissions = append(explanation.Permissions, explainPermission(permission, namespace))
+	}
+	return explanation, nil
+}
+
+// explainPermission returns the steps deciding whether permission applies in namespace, in the order
+// isPermissionAllowed evaluates them
+func explainPermission(permission managedv1alpha1.Permission, namespace *corev1.Namespace) managedv1alpha1.PermissionExplanation {
+	explanation := managedv1alpha1.PermissionExplanation{ClusterRoleName: permission.ClusterRoleName, RoleName: permission.RoleName}
+	if utility.IsNamespaceRequesterExcluded(permission.ExcludedNamespaceRequesters, namespace.Annotations) {
+		explanation.Steps = []string{fmt.Sprintf("requester %q of the Namespace is excluded", namespace.Annotations[operatorconfig.NamespaceRequesterAnnotation])}
+		return explanation
+	}
+	explanation.Matched, explanation.Steps = utility.PermissionNamespaceMatcher(permission).Explain(namespace.Name)
+	return explanation
+}
@@ -0,0 +1,60 @@
+package grouppermission
+
+import (
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestExplainNamespace tests the explainNamespace function
+// given: a GroupPermission allowed in ^team-.* but denied in team-secret, explaining team-secret
+// expected: the permission is not matched, the allowed regex matching before the denied one
+func TestExplainNamespace(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	fakeClient := fake.NewFakeClient(mockNamespace("team-secret"))
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Annotations = map[string]string{operatorconfig.ExplainNamespaceAnnotation: "team-secret"}
+	explanation, err := reconciler.explainNamespace(groupPermission, operatorconfig.DefaultOperatorConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &v1alpha1.NamespaceExplanation{
+		Namespace: "team-secret",
+		Permissions: []v1alpha1.PermissionExplanation{{
+			ClusterRoleName: "admin",
+			Steps: []string{
+				"allowFirst is set, namespacesAllowedRegex is evaluated first",
+				`namespacesAllowedRegex "^team-.*" matches`,
+				`namespacesDeniedRegex "^team-secret$" matches`,
+				"denied by namespacesDeniedRegex",
+			},
+		}},
+	}
+	if !reflect.DeepEqual(explanation, expected) {
+		t.Errorf("Mismatch for explanation. Expected(%+v), Found(%+v)", expected, explanation)
+	}
+
+	groupPermission.Annotations[operatorconfig.ExplainNamespaceAnnotation] = "missing"
+	explanation, err = reconciler.explainNamespace(groupPermission, operatorconfig.DefaultOperatorConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if explanation.Reason != "the Namespace does not exist" || len(explanation.Permissions) != 0 {
+		t.Errorf("Mismatch for explanation of a missing Namespace. Found(%+v)", explanation)
+	}
+
+	if explanation, _ := reconciler.explainNamespace(mockNamespacedGroupPermission(), operatorconfig.DefaultOperatorConfig()); explanation != nil {
+		t.Errorf("expected no explanation without the annotation, got %+v", explanation)
+	}
+}
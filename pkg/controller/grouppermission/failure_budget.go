@@ -0,0 +1,119 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// number of consecutive failed reconciles after which a GroupPermission is Degraded
+	failureBudgetSize = 5
+	// interval between reconciles of a Degraded GroupPermission
+	degradedRequeueInterval = 30 * time.Minute
+	// minimum interval between the aggregated events of a Degraded GroupPermission
+	degradedEventInterval = time.Hour
+)
+
+// failureBudget tracks consecutive reconcile failures of each GroupPermission
+type failureBudget struct {
+	mu      sync.Mutex
+	records map[types.NamespacedName]*failureRecord
+}
+
+// failureRecord holds the consecutive failures of a single GroupPermission
+type failureRecord struct {
+	failures  int
+	lastEvent time.Time
+}
+
+// recordFailure records a failed reconcile of name at now. It returns whether the failure budget is
+// exhausted, whether an aggregated event is due, and the number of consecutive failures.
+func (b *failureBudget) recordFailure(name types.NamespacedName, now time.Time) (bool, bool, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.records == nil {
+		b.records = map[types.NamespacedName]*failureRecord{}
+	}
+	record, ok := b.records[name]
+	if !ok {
+		record = &failureRecord{}
+		b.records[name] = record
+	}
+	record.failures++
+
+	if record.failures < failureBudgetSize {
+		return false, false, record.failures
+	}
+
+	emitEvent := now.Sub(record.lastEvent) >= degradedEventInterval
+	if emitEvent {
+		record.lastEvent = now
+	}
+	return true, emitEvent, record.failures
+}
+
+// recordSuccess resets the failures of name and returns whether its failure budget was exhausted
+func (b *failureBudget) recordSuccess(name types.NamespacedName) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	record, ok := b.records[name]
+	if !ok {
+		return false
+	}
+	delete(b.records, name)
+	return record.failures >= failureBudgetSize
+}
+
+// setDegraded marks the GroupPermission as Degraded and emits an aggregated event when one is due
+func (r *ReconcileGroupPermission) setDegraded(request reconcile.Request, failures int, reconcileErr error, emitEvent bool) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		reqLogger.Error(err, "Failed to get GroupPermission")
+		return
+	}
+	reqLogger.Error(reconcileErr, "GroupPermission exhausted its failure budget", "Failures", failures, "RequeueAfter", degradedRequeueInterval,
+		"OwnerRef", utility.OwnerRef(&instance.Spec))
+
+	message := fmt.Sprintf("Reconcile failed %d times in a row, retrying every %s: %v", failures, degradedRequeueInterval, reconcileErr)
+	if emitEvent {
+		r.eventf(instance, corev1.EventTypeWarning, string(managedv1alpha1.GroupPermissionDegraded), "%s", message)
+	}
+
+	if conditions.IsStateTrue(instance.Status.Conditions, managedv1alpha1.GroupPermissionDegraded) {
+		return
+	}
+	instance = updateCondition(instance, message, "", true, managedv1alpha1.GroupPermissionDegraded, managedv1alpha1.ReasonFailureBudgetExhausted)
+	if err := r.updateStatus(instance); err != nil {
+		reqLogger.Error(err, "Failed to update condition.")
+	}
+}
+
+// clearDegraded deactivates the Degraded conditions of a GroupPermission that reconciled successfully
+func (r *ReconcileGroupPermission) clearDegraded(request reconcile.Request) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		reqLogger.Error(err, "Failed to get GroupPermission")
+		return
+	}
+
+	conditions.DeactivateState(instance.Status.Conditions, managedv1alpha1.GroupPermissionDegraded)
+	if err := r.updateStatus(instance); err != nil {
+		reqLogger.Error(err, "Failed to update condition.")
+	}
+}
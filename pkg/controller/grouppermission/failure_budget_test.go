@@ -0,0 +1,50 @@
+package grouppermission
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestFailureBudget tests the failureBudget type
+// given: consecutive failures of a GroupPermission followed by a success
+// expected: the budget is exhausted after failureBudgetSize failures, events are aggregated
+// to one per degradedEventInterval, and a success resets the budget
+func TestFailureBudget(t *testing.T) {
+	budget := failureBudget{}
+	name := types.NamespacedName{Namespace: "ns", Name: "gp"}
+	now := time.Now()
+
+	for i := 1; i < failureBudgetSize; i++ {
+		exhausted, emitEvent, failures := budget.recordFailure(name, now)
+		if exhausted || emitEvent || failures != i {
+			t.Errorf("failure %d: got exhausted=%t emitEvent=%t failures=%d", i, exhausted, emitEvent, failures)
+		}
+	}
+
+	exhausted, emitEvent, _ := budget.recordFailure(name, now)
+	if !exhausted || !emitEvent {
+		t.Errorf("expected the budget to be exhausted with an event, got exhausted=%t emitEvent=%t", exhausted, emitEvent)
+	}
+
+	exhausted, emitEvent, _ = budget.recordFailure(name, now.Add(degradedEventInterval/2))
+	if !exhausted || emitEvent {
+		t.Errorf("expected no event within the event interval, got exhausted=%t emitEvent=%t", exhausted, emitEvent)
+	}
+
+	_, emitEvent, _ = budget.recordFailure(name, now.Add(degradedEventInterval))
+	if !emitEvent {
+		t.Errorf("expected an event after the event interval")
+	}
+
+	if !budget.recordSuccess(name) {
+		t.Errorf("expected recordSuccess to report the exhausted budget")
+	}
+	if budget.recordSuccess(name) {
+		t.Errorf("expected recordSuccess to report nothing after a reset")
+	}
+	if exhausted, _, failures := budget.recordFailure(name, now); exhausted || failures != 1 {
+		t.Errorf("expected a fresh budget after success, got exhausted=%t failures=%d", exhausted, failures)
+	}
+}
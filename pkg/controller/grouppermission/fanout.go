@@ -0,0 +1,163 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// +kubebuilder:rbac:groups=user.openshift.io,resources=groups,verbs=list;watch
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=grouppermissions,verbs=create;delete,namespace=openshift-rbac-permissions-operator
+
+// selectedGroups returns the names of the OpenShift Groups matching the groupNameSelector of
+// groupPermission, sorted
+func (r *ReconcileGroupPermission) selectedGroups(groupPermission *managedv1alpha1.GroupPermission) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(groupPermission.Spec.GroupNameSelector)
+	if err != nil {
+		return nil, err
+	}
+	groupList := &unstructured.UnstructuredList{}
+	groupList.SetGroupVersionKind(groupGVK.GroupVersion().WithKind(groupGVK.Kind + "List"))
+	if err := r.client.List(context.TODO(), &client.ListOptions{LabelSelector: selector}, groupList); err != nil {
+		return nil, err
+	}
+
+	var groups []string
+	for _, group := range groupList.Items {
+		if selector.Matches(labels.Set(group.GetLabels())) {
+			groups = append(groups, group.GetName())
+		}
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
+
+// reconcileFanOut creates a GroupPermission for each Group selected by the groupNameSelector of
+// groupPermission, with its spec for the Group, keeps their spec up to date and deletes those of the
+// Groups no longer selected. The GroupPermissions are owned by groupPermission, so they are garbage
+// collected with it. Returns the names of the selected Groups.
+func (r *ReconcileGroupPermission) reconcileFanOut(groupPermission *managedv1alpha1.GroupPermission) ([]string, error) {
+	groups, err := r.selectedGroups(groupPermission)
+	if err != nil {
+		return nil, err
+	}
+
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	fanOutSelector := utility.ParentSelector(operatorconfig.FanOutParentLabel, groupPermission.Name)
+	if err := r.client.List(context.TODO(), &client.ListOptions{Namespace: groupPermission.Namespace, LabelSelector: fanOutSelector}, groupPermissionList); err != nil {
+		return nil, err
+	}
+	existing := map[string]*managedv1alpha1.GroupPermission{}
+	for i := range groupPermissionList.Items {
+		fanOut := &groupPermissionList.Items[i]
+		if fanOutSelector.Matches(labels.Set(fanOut.Labels)) {
+			existing[fanOut.Spec.GroupName] = fanOut
+		}
+	}
+
+	selected := map[string]bool{}
+	for _, groupName := range groups {
+		selected[groupName] = true
+		spec := utility.FanOutSpec(&groupPermission.Spec, groupName)
+		if fanOut, ok := existing[groupName]; ok {
+			if reflect.DeepEqual(fanOut.Spec, spec) {
+				continue
+			}
+			fanOut.Spec = spec
+			if err := r.client.Update(context.TODO(), fanOut); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		fanOut, err := r.newFanOut(groupPermission, groupName, spec)
+		if err != nil {
+			return nil, err
+		}
+		err = r.client.Create(context.TODO(), fanOut)
+		if errors.IsAlreadyExists(err) {
+			// a GroupPermission not created for the Group already has its name, it is left alone
+			r.eventf(groupPermission, corev1.EventTypeWarning, "FanOutConflict", "GroupPermission %s already exists, Group %s is not granted permissions", fanOut.Name, groupName)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		r.eventf(groupPermission, corev1.EventTypeNormal, "FanOutCreated", "Created GroupPermission %s for Group %s", fanOut.Name, groupName)
+	}
+
+	for groupName, fanOut := range existing {
+		if selected[groupName] {
+			continue
+		}
+		if err := r.client.Delete(context.TODO(), fanOut); err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		r.eventf(groupPermission, corev1.EventTypeNormal, "FanOutDeleted", "Deleted GroupPermission %s of Group %s, no longer selected", fanOut.Name, groupName)
+	}
+	return groups, nil
+}
+
+// newFanOut returns the GroupPermission granting groupName the permissions of groupPermission, with spec
+func (r *ReconcileGroupPermission) newFanOut(groupPermission *managedv1alpha1.GroupPermission, groupName string, spec managedv1alpha1.GroupPermissionSpec) (*managedv1alpha1.GroupPermission, error) {
+	fanOut := &managedv1alpha1.GroupPermission{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utility.FanOutName(groupPermission.Name, groupName),
+			Namespace: groupPermission.Namespace,
+			Labels:    utility.ParentLabels(operatorconfig.FanOutParentLabel, groupPermission.Name),
+		},
+		Spec: spec,
+	}
+	if err := controllerutil.SetControllerReference(groupPermission, fanOut, r.scheme); err != nil {
+		return nil, err
+	}
+	return fanOut, nil
+}
+
+// groupToGroupPermissions maps a Group event to a reconcile of every GroupPermission of the shard with
+// a groupNameSelector. The labels the Group had before the event are unknown, so every selector is
+// evaluated again.
+type groupToGroupPermissions struct {
+	client client.Client
+	shard  *shard
+}
+
+// blank assignment to verify that groupToGroupPermissions implements handler.Mapper
+var _ handler.Mapper = &groupToGroupPermissions{}
+
+// Map implements handler.Mapper
+func (m *groupToGroupPermissions) Map(obj handler.MapObject) []reconcile.Request {
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	err := m.client.List(context.TODO(), &client.ListOptions{}, groupPermissionList)
+	if err != nil {
+		log.Error(err, "Failed to list GroupPermissions", "Group", obj.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, groupPermission := range groupPermissionList.Items {
+		if groupPermission.Spec.GroupNameSelector == nil || !m.shard.matches(&groupPermission) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: groupPermission.Namespace,
+			Name:      groupPermission.Name,
+		}})
+	}
+	return requests
+}
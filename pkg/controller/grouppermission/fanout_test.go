@@ -0,0 +1,141 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// mockSelectorGroupPermission returns a GroupPermission granting view to the Groups labeled team-kind=tenant
+func mockSelectorGroupPermission() *v1alpha1.GroupPermission {
+	groupPermission := mockGroupPermission()
+	groupPermission.UID = "parent-uid"
+	groupPermission.Spec.GroupName = ""
+	groupPermission.Spec.GroupNameSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team-kind": "tenant"}}
+	groupPermission.Spec.ClusterPermissions = []string{"view"}
+	return groupPermission
+}
+
+// mockGroup returns an OpenShift Group named name with labels
+func mockGroup(name string, labels map[string]string) *unstructured.Unstructured {
+	group := newGroup(name)
+	group.SetLabels(labels)
+	return group
+}
+
+// groupListingClient lists the OpenShift Groups it holds, the fake client cannot decode them into an
+// UnstructuredList
+type groupListingClient struct {
+	client.Client
+	groups []*unstructured.Unstructured
+}
+
+func (c *groupListingClient) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	groupList, ok := list.(*unstructured.UnstructuredList)
+	if !ok {
+		return c.Client.List(ctx, opts, list)
+	}
+	for _, group := range c.groups {
+		groupList.Items = append(groupList.Items, *group.DeepCopy())
+	}
+	return nil
+}
+
+// TestReconcileFanOut tests the reconcileFanOut function
+// given: a GroupPermission selecting the Groups labeled team-kind=tenant, two such Groups and another,
+// and the GroupPermission created for a Group no longer selected
+// expected: a GroupPermission owned by it for each selected Group, the one of the unselected Group deleted
+func TestReconcileFanOut(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	parent := mockSelectorGroupPermission()
+	tenant := map[string]string{"team-kind": "tenant"}
+	stale := &v1alpha1.GroupPermission{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: parent.Namespace,
+			Name:      "testGroupPermission-team-c",
+			Labels:    map[string]string{operatorconfig.FanOutParentLabel: parent.Name},
+		},
+		Spec: v1alpha1.GroupPermissionSpec{GroupName: "team-c", ClusterPermissions: []string{"view"}},
+	}
+	fakeClient := &groupListingClient{
+		Client: fake.NewFakeClient(parent, stale),
+		groups: []*unstructured.Unstructured{mockGroup("team-a", tenant), mockGroup("team-b", tenant), mockGroup("team-c", nil), mockGroup("ops", map[string]string{"team-kind": "sre"})},
+	}
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme, recorder: recorder}
+
+	groups, err := reconciler.reconcileFanOut(parent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := []string{"team-a", "team-b"}; !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Mismatch for groups. Expected(%v), Found(%v)", expected, groups)
+	}
+	for _, groupName := range groups {
+		fanOut := &v1alpha1.GroupPermission{}
+		if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: parent.Namespace, Name: utility.FanOutName(parent.Name, groupName)}, fanOut); err != nil {
+			t.Fatalf("expected the GroupPermission of Group %s: %v", groupName, err)
+		}
+		expected := v1alpha1.GroupPermissionSpec{GroupName: groupName, ClusterPermissions: []string{"view"}}
+		if !reflect.DeepEqual(fanOut.Spec, expected) {
+			t.Errorf("Mismatch for spec. Expected(%+v), Found(%+v)", expected, fanOut.Spec)
+		}
+		if owner := metav1.GetControllerOf(fanOut); owner == nil || owner.UID != parent.UID {
+			t.Errorf("expected the GroupPermission of Group %s to be owned by its parent, got %v", groupName, owner)
+		}
+	}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: parent.Namespace, Name: stale.Name}, &v1alpha1.GroupPermission{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected the GroupPermission of Group team-c to be deleted, got %v", err)
+	}
+	if len(recorder.Events) != 3 {
+		t.Errorf("Mismatch for events. Expected(3), Found(%d)", len(recorder.Events))
+	}
+
+	// a second pass changes nothing
+	if _, err := reconciler.reconcileFanOut(parent); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.Events) != 3 {
+		t.Errorf("Mismatch for events after a second pass. Expected(3), Found(%d)", len(recorder.Events))
+	}
+}
+
+// TestGroupToGroupPermissions tests the Map function of groupToGroupPermissions
+// given: a GroupPermission with a groupNameSelector and one with a groupName
+// expected: only the GroupPermission with a groupNameSelector is reconciled on a Group event
+func TestGroupToGroupPermissions(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	single := mockGroupPermission()
+	single.Name = "single"
+	mapper := &groupToGroupPermissions{client: fake.NewFakeClient(mockSelectorGroupPermission(), single)}
+
+	group := mockGroup("team-a", nil)
+	requests := mapper.Map(handler.MapObject{Meta: group, Object: group})
+
+	if len(requests) != 1 || requests[0].Name != "testGroupPermission" {
+		t.Errorf("Mismatch for requests. Expected([testGroupPermission]), Found(%v)", requests)
+	}
+}
@@ -0,0 +1,71 @@
+package grouppermission
+
+import (
+	"context"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// hasFinalizer returns whether groupPermission holds the operator finalizer
+func hasFinalizer(groupPermission *managedv1alpha1.GroupPermission) bool {
+	for _, finalizer := range groupPermission.Finalizers {
+		if finalizer == operatorconfig.GroupPermissionFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFinalizer removes the operator finalizer from groupPermission
+func removeFinalizer(groupPermission *managedv1alpha1.GroupPermission) {
+	var finalizers []string
+	for _, finalizer := range groupPermission.Finalizers {
+		if finalizer != operatorconfig.GroupPermissionFinalizer {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	groupPermission.Finalizers = finalizers
+}
+
+// deleteManagedBindings deletes every ClusterRoleBinding, RoleBinding and Role managed for groupPermission
+func (r *ReconcileGroupPermission) deleteManagedBindings(groupPermission *managedv1alpha1.GroupPermission) error {
+	opts := &client.ListOptions{LabelSelector: utility.ManagedForSelector(groupPermission.Namespace, groupPermission.Name)}
+
+	clusterRoleBindingList := &v1.ClusterRoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), opts, clusterRoleBindingList); err != nil {
+		return err
+	}
+	for i := range clusterRoleBindingList.Items {
+		if !utility.IsManagedFor(clusterRoleBindingList.Items[i].ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+			continue
+		}
+		unlock := bindinglock.Lock(bindinglock.ClusterRoleBinding, "", clusterRoleBindingList.Items[i].Name)
+		err := r.client.Delete(context.TODO(), &clusterRoleBindingList.Items[i])
+		unlock()
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	roleBindingList, err := r.listManagedRoleBindings(groupPermission)
+	if err != nil {
+		return err
+	}
+	for i := range roleBindingList.Items {
+		unlock := bindinglock.Lock(bindinglock.RoleBinding, roleBindingList.Items[i].Namespace, roleBindingList.Items[i].Name)
+		err := r.client.Delete(context.TODO(), &roleBindingList.Items[i])
+		unlock()
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return r.deleteManagedRoles(groupPermission)
+}
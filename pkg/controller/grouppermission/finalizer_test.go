@@ -0,0 +1,72 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestDeleteManagedBindings tests the deleteManagedBindings function
+// given: bindings managed for the GroupPermission and for another one
+// expected: only the bindings of the GroupPermission are deleted
+func TestDeleteManagedBindings(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockGroupPermission()
+	owned := utility.ManagedLabels(groupPermission.Namespace, groupPermission.Name)
+	other := utility.ManagedLabels(groupPermission.Namespace, "other")
+
+	fakeClient := fake.NewFakeClient(
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "owned", Labels: owned}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: other}},
+		&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "owned", Labels: owned}},
+	)
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+	}
+
+	if err := reconciler.deleteManagedBindings(groupPermission); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: "owned"}, &rbacv1.ClusterRoleBinding{}); err == nil {
+		t.Errorf("expected owned ClusterRoleBinding to be deleted")
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "owned"}, &rbacv1.RoleBinding{}); err == nil {
+		t.Errorf("expected owned RoleBinding to be deleted")
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: "other"}, &rbacv1.ClusterRoleBinding{}); err != nil {
+		t.Errorf("expected ClusterRoleBinding of another GroupPermission to be kept: %v", err)
+	}
+}
+
+// TestRemoveFinalizer tests the hasFinalizer and removeFinalizer functions
+// given: GroupPermission with the operator finalizer and another finalizer
+// expected: only the operator finalizer is removed
+func TestRemoveFinalizer(t *testing.T) {
+	groupPermission := mockGroupPermission()
+	groupPermission.Finalizers = []string{"example.com/other", operatorconfig.GroupPermissionFinalizer}
+
+	if !hasFinalizer(groupPermission) {
+		t.Fatalf("expected finalizer to be present")
+	}
+	removeFinalizer(groupPermission)
+	if hasFinalizer(groupPermission) {
+		t.Errorf("expected finalizer to be removed")
+	}
+	if len(groupPermission.Finalizers) != 1 || groupPermission.Finalizers[0] != "example.com/other" {
+		t.Errorf("got finalizers %v, want [example.com/other]", groupPermission.Finalizers)
+	}
+}
@@ -0,0 +1,168 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// foreignBinding is a binding of a foreign operator, one of the ForeignOperators of the operator config
+type foreignBinding struct {
+	kind       string
+	objectMeta metav1.ObjectMeta
+	operator   string
+}
+
+// String returns the kind and name of b with its operator, for the status and events
+func (b foreignBinding) String() string {
+	return fmt.Sprintf("%s %s of %s", b.kind, describeBinding(&b.objectMeta), b.operator)
+}
+
+// foreignBindings are the bindings of the foreign operators a GroupPermission must leave to them: by
+// "namespace/name", and those granting a role to its Group by "namespace/Kind/name", the namespace
+// empty for the ClusterRoleBindings
+type foreignBindings struct {
+	byName   map[string]foreignBinding
+	byAccess map[string]foreignBinding
+}
+
+// listForeignBindings returns the bindings of the foreign operators of operatorConfig, nil when there
+// are none or groupPermission binds no Group itself
+func (r *ReconcileGroupPermission) listForeignBindings(groupPermission *managedv1alpha1.GroupPermission, operatorConfig *operatorconfig.OperatorConfig) (*foreignBindings, error) {
+	if len(operatorConfig.ForeignOperators) == 0 || groupPermission.Spec.GroupName == "" {
+		return nil, nil
+	}
+	requirement, err := labels.NewRequirement(operatorconfig.ManagedByLabel, selection.In, operatorConfig.ForeignOperators)
+	if err != nil {
+		return nil, err
+	}
+	opts := &client.ListOptions{LabelSelector: labels.NewSelector().Add(*requirement)}
+
+	foreign := &foreignBindings{byName: map[string]foreignBinding{}, byAccess: map[string]foreignBinding{}}
+	add := func(kind string, objectMeta metav1.ObjectMeta, roleRef v1.RoleRef, subjects []v1.Subject) {
+		operator, ok := operatorConfig.ForeignOperator(objectMeta.Labels)
+		if !ok {
+			return
+		}
+		binding := foreignBinding{kind: kind, objectMeta: objectMeta, operator: operator}
+		foreign.byName[objectMeta.Namespace+"/"+objectMeta.Name] = binding
+		if bindsGroup(subjects, groupPermission.Spec.GroupName) {
+			foreign.byAccess[objectMeta.Namespace+"/"+roleRef.Kind+"/"+roleRef.Name] = binding
+		}
+	}
+
+	clusterRoleBindingList := &v1.ClusterRoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), opts, clusterRoleBindingList); err != nil {
+		return nil, err
+	}
+	for _, clusterRoleBinding := range clusterRoleBindingList.Items {
+		add("ClusterRoleBinding", clusterRoleBinding.ObjectMeta, clusterRoleBinding.RoleRef, clusterRoleBinding.Subjects)
+	}
+	roleBindingList := &v1.RoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), opts, roleBindingList); err != nil {
+		return nil, err
+	}
+	for _, roleBinding := range roleBindingList.Items {
+		add("RoleBinding", roleBinding.ObjectMeta, roleBinding.RoleRef, roleBinding.Subjects)
+	}
+	return foreign, nil
+}
+
+// conflict returns the foreign binding a binding named name of roleRef in namespace, empty for a
+// ClusterRoleBinding, conflicts with: one of the same name, or one already granting the role to the Group
+func (f *foreignBindings) conflict(namespace, name string, roleRef v1.RoleRef) (foreignBinding, bool) {
+	if f == nil {
+		return foreignBinding{}, false
+	}
+	if binding, ok := f.byName[namespace+"/"+name]; ok {
+		return binding, true
+	}
+	if binding, ok := f.byAccess[namespace+"/"+roleRef.Kind+"/"+roleRef.Name]; ok {
+		return binding, true
+	}
+	// a ClusterRole granted cluster wide covers its bindings in any Namespace
+	if binding, ok := f.byAccess["/"+roleRef.Kind+"/"+roleRef.Name]; ok && roleRef.Kind == "ClusterRole" {
+		return binding, true
+	}
+	return foreignBinding{}, false
+}
+
+// foreignConflicts returns the bindings of groupPermission in the Namespaces of namespaceList left to
+// the foreign bindings they conflict with, each as "Kind namespace/name to <foreign binding>", sorted
+func (r *ReconcileGroupPermission) foreignConflicts(groupPermission *managedv1alpha1.GroupPermission, foreign *foreignBindings, namespaceList *corev1.NamespaceList) []string {
+	if foreign == nil {
+		return nil
+	}
+	var conflicts []string
+	for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+		binding := clusterPermissionBinding(&groupPermission.Spec, clusterRoleName)
+		if owner, ok := foreign.conflict("", binding.Name, binding.RoleRef); ok {
+			conflicts = append(conflicts, fmt.Sprintf("ClusterRoleBinding %s to %s", binding.Name, owner))
+		}
+	}
+	allowed := r.namespaces.allowedNamespaces(groupPermission, namespaceList)
+	for i, permission := range groupPermission.Spec.Permissions {
+		for namespace := range allowed[i] {
+			binding := newRoleBinding(namespace, permissionRoleRef(&groupPermission.Spec, permission), utility.GroupSubject(&groupPermission.Spec))
+			if owner, ok := foreign.conflict(namespace, binding.Name, binding.RoleRef); ok {
+				conflicts = append(conflicts, fmt.Sprintf("RoleBinding %s/%s to %s", namespace, binding.Name, owner))
+			}
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// reconcileForeignConflicts flags groupPermission with a ForeignOperatorConflict condition and event
+// when some of its bindings are left to the foreign operators of operatorConfig, and clears the
+// condition once none are. The bindings left are not failures, the foreign operators grant the access.
+func (r *ReconcileGroupPermission) reconcileForeignConflicts(groupPermission *managedv1alpha1.GroupPermission, foreign *foreignBindings, namespaceList *corev1.NamespaceList, operatorConfig *operatorconfig.OperatorConfig) error {
+	conflicts := r.foreignConflicts(groupPermission, foreign, namespaceList)
+	if len(conflicts) == 0 {
+		if conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonForeignOperatorConflict) {
+			conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonForeignOperatorConflict)
+			return r.updateStatus(groupPermission)
+		}
+		return nil
+	}
+
+	message := foreignConflictsMessage(conflicts, operatorConfig.StatusFailureSampleSize)
+	if hasActiveCondition(groupPermission, message, managedv1alpha1.GroupPermissionCreated, managedv1alpha1.ReasonForeignOperatorConflict) {
+		return nil
+	}
+	// the condition listing the previous conflicts is replaced
+	conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonForeignOperatorConflict)
+	updateCondition(groupPermission, message, "", true, managedv1alpha1.GroupPermissionCreated, managedv1alpha1.ReasonForeignOperatorConflict)
+	if err := r.updateStatus(groupPermission); err != nil {
+		return err
+	}
+	r.eventf(groupPermission, corev1.EventTypeWarning, string(managedv1alpha1.ReasonForeignOperatorConflict), "%s", message)
+	return nil
+}
+
+// foreignConflictsMessage returns the message of the ForeignOperatorConflict condition, listing up to
+// sampleSize of conflicts
+func foreignConflictsMessage(conflicts []string, sampleSize int) string {
+	sample := conflicts
+	if sampleSize > 0 && len(sample) > sampleSize {
+		sample = sample[:sampleSize]
+	}
+	message := fmt.Sprintf("%d bindings are left to other RBAC operators granting the same access: %s", len(conflicts), strings.Join(sample, ", "))
+	if len(sample) < len(conflicts) {
+		message += fmt.Sprintf(" and %d more", len(conflicts)-len(sample))
+	}
+	return message
+}
@@ -0,0 +1,133 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// mockForeignRoleBinding returns a RoleBinding name in namespace of the ClusterRole clusterRoleName to
+// subject, managed by the operator managedBy
+func mockForeignRoleBinding(namespace, name, clusterRoleName string, subject rbacv1.Subject, managedBy string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{operatorconfig.ManagedByLabel: managedBy}},
+		RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: clusterRoleName},
+		Subjects:   []rbacv1.Subject{subject},
+	}
+}
+
+// TestReconcileNamespacePermissionsForeignOperators tests the reconcileNamespacePermissions function
+// given: GroupPermission binding admin, allowed in a Namespace where the dedicated-admin-operator
+// already binds admin to its Group, in a Namespace where it has a binding of the same name to another
+// Group, in a Namespace where an unknown operator binds admin to the Group, and in a Namespace without bindings
+// expected: the bindings of the dedicated-admin-operator are left unchanged and no RoleBinding nor state
+// is recorded in their Namespaces, the RoleBindings are created in the other Namespaces
+func TestReconcileNamespacePermissionsForeignOperators(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	group := mockGroupSubject(groupPermission.Spec.GroupName)
+	sameAccess := mockForeignRoleBinding("team-a", "dedicated-admins", "admin", group, "dedicated-admin-operator")
+	sameName := mockForeignRoleBinding("team-b", newRoleBinding("team-b", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, group).Name, "view", mockGroupSubject("other"), "dedicated-admin-operator")
+	unknown := mockForeignRoleBinding("team-c", "admins", "admin", group, "unknown-operator")
+	fakeClient := newRequesterClient(sameAccess, sameName, unknown, mockNamespace("team-a"), mockNamespace("team-b"), mockNamespace("team-c"), mockNamespace("team-d"))
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+	operatorConfig.ForeignOperators = []string{"dedicated-admin-operator"}
+
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(groupPermission, nil, nil, nil, operatorConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var namespaces []string
+	for _, namespaceStatus := range namespaceStatuses {
+		namespaces = append(namespaces, namespaceStatus.Namespace)
+		if namespaceStatus.State != v1alpha1.GroupPermissionCreated {
+			t.Errorf("Mismatch for %s. Expected(%s), Found(%+v)", namespaceStatus.Namespace, v1alpha1.GroupPermissionCreated, namespaceStatus)
+		}
+	}
+	if strings.Join(namespaces, ",") != "team-c,team-d" {
+		t.Errorf("Mismatch for namespace statuses. Expected(team-c,team-d), Found(%v)", namespaces)
+	}
+
+	ours := newRoleBinding("team-a", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, group)
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: ours.Name}, &rbacv1.RoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("expected no RoleBinding in team-a, got %v", err)
+	}
+	roleBinding := &rbacv1.RoleBinding{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-b", Name: sameName.Name}, roleBinding); err != nil {
+		t.Fatalf("expected the RoleBinding of the dedicated-admin-operator in team-b: %v", err)
+	}
+	if roleBinding.RoleRef.Name != "view" || roleBinding.Subjects[0].Name != "other" || roleBinding.Labels[operatorconfig.ManagedByLabel] != "dedicated-admin-operator" {
+		t.Errorf("expected the RoleBinding of the dedicated-admin-operator to be left unchanged, got %+v", roleBinding)
+	}
+}
+
+// TestReconcileForeignConflicts tests the reconcileForeignConflicts function
+// given: a GroupPermission whose Group is bound to admin in an allowed Namespace and to one of its
+// ClusterPermissions by the dedicated-admin-operator, then no more
+// expected: a ForeignOperatorConflict condition and event listing both conflicts, which does not fail
+// the GroupPermission, then the condition is cleared
+func TestReconcileForeignConflicts(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	group := mockGroupSubject(groupPermission.Spec.GroupName)
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "dedicated-admins-cluster", Labels: map[string]string{operatorconfig.ManagedByLabel: "dedicated-admin-operator"}},
+		RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "exampleClusterRoleName"},
+		Subjects:   []rbacv1.Subject{group},
+	}
+	fakeClient := fake.NewFakeClient(groupPermission.DeepCopy(), clusterRoleBinding, mockForeignRoleBinding("team-a", "dedicated-admins", "admin", group, "dedicated-admin-operator"))
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme, recorder: recorder}
+	namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{*mockNamespace("team-a"), *mockNamespace("default")}}
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+	operatorConfig.ForeignOperators = []string{"dedicated-admin-operator"}
+
+	foreign, err := reconciler.listForeignBindings(groupPermission, operatorConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reconciler.reconcileForeignConflicts(groupPermission, foreign, namespaceList, operatorConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := foreignConflictsMessage([]string{
+		"ClusterRoleBinding exampleClusterRoleName-exampleGroupName to ClusterRoleBinding dedicated-admins-cluster of dedicated-admin-operator",
+		"RoleBinding team-a/admin-exampleGroupName to RoleBinding team-a/dedicated-admins of dedicated-admin-operator",
+	}, 10)
+	if !hasActiveCondition(groupPermission, expected, v1alpha1.GroupPermissionCreated, v1alpha1.ReasonForeignOperatorConflict) {
+		t.Errorf("expected a condition %q, got %+v", expected, groupPermission.Status.Conditions)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, expected) {
+		t.Errorf("Mismatch for event. Expected(%s), Found(%s)", expected, event)
+	}
+	if phase := groupPermissionPhase(groupPermission, true); phase != v1alpha1.GroupPermissionPhaseActive {
+		t.Errorf("Mismatch for phase. Expected(%s), Found(%s)", v1alpha1.GroupPermissionPhaseActive, phase)
+	}
+
+	if err := reconciler.reconcileForeignConflicts(groupPermission, nil, namespaceList, operatorConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conditions.IsTrue(groupPermission.Status.Conditions, v1alpha1.ReasonForeignOperatorConflict) {
+		t.Errorf("expected the condition to be cleared, got %+v", groupPermission.Status.Conditions)
+	}
+}
@@ -0,0 +1,71 @@
+package grouppermission
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// FuzzSpec decodes a GroupPermission spec, and checks that neither its validation nor, once valid, the
+// matching of its Permissions, its schedule and cluster conditions, and the building of its RoleBindings
+// and Roles panic. Seeds beyond those added here are in testdata/fuzz/FuzzSpec.
+func FuzzSpec(f *testing.F) {
+	f.Add([]byte(`{"groupName":"team-a","permissions":[{"clusterRoleName":"admin","namespacesAllowedRegex":"^team-.*","namespacesDeniedRegex":"^team-secret$","allowFirst":true}]}`))
+	f.Add([]byte(`{"groupName":"team-a","permissions":[{"roleName":"deployer","rules":[{"apiGroups":["apps"],"resources":["deployments"],"verbs":["get"]}],"namespacesAllowedRegex":"^team-a$"}],"viewRole":{"resources":["pods/log","deployments.apps"]}}`))
+	f.Add([]byte(`{"groupName":"team-a","clusterPermissions":["view"],"schedule":{"timeZone":"Europe/Paris","windows":[{"days":"1-5","start":"22:00","end":"06:00"}]}}`))
+	f.Add([]byte(`{"groupNameSelector":{"matchExpressions":[{"key":"team","operator":"In","values":["a"]}]},"clusterConditions":{"matchLabels":{"region":"eu"}}}`))
+
+	namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{
+		*mockNamespace("team-a"),
+		*mockNamespace("team-secret"),
+		*mockNamespace("default"),
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Annotations: map[string]string{"openshift.io/requester": "alice"}}},
+	}}
+	now := time.Date(2019, 6, 3, 23, 0, 0, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		groupPermission := &v1alpha1.GroupPermission{ObjectMeta: metav1.ObjectMeta{Name: "fuzz", Namespace: "rbac-permissions-operator"}}
+		if err := json.Unmarshal(data, &groupPermission.Spec); err != nil {
+			return
+		}
+		if validateSpec(groupPermission) != nil {
+			return
+		}
+
+		allowed := evaluateNamespaces(groupPermission.Spec.Permissions, namespaceList)
+		for i, match := range matchNamespaces(groupPermission, allowed, 2) {
+			if match.Count != len(allowed[i]) || len(match.Sample) > 2 {
+				t.Errorf("Mismatch for the matched namespaces of permission %d. Expected(%d), Found(%+v)", i, len(allowed[i]), match)
+			}
+		}
+		matchedNamespaceCount(allowed)
+		desiredRoles(groupPermission, allowed)
+		viewPermission(groupPermission)
+
+		subject := utility.GroupSubject(&groupPermission.Spec)
+		for _, permission := range groupPermission.Spec.Permissions {
+			for i := range namespaceList.Items {
+				newRoleBinding(namespaceList.Items[i].Name, permissionRoleRef(&groupPermission.Spec, permission), subject)
+				isGranterAllowed(groupPermission, &namespaceList.Items[i])
+			}
+		}
+		for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+			newClusterRoleBinding(clusterRoleName, subject)
+		}
+
+		if groupPermission.Spec.Schedule != nil {
+			if _, _, err := scheduleState(groupPermission.Spec.Schedule, now); err != nil {
+				t.Errorf("unexpected error for the validated schedule %+v: %v", groupPermission.Spec.Schedule, err)
+			}
+		}
+		if _, err := clusterConditionsMatch(groupPermission, labels.Set{"region": "eu"}); err != nil {
+			t.Errorf("unexpected error for the validated cluster conditions %+v: %v", groupPermission.Spec.ClusterConditions, err)
+		}
+	})
+}
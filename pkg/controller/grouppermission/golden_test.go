@@ -0,0 +1,179 @@
+package grouppermission
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+)
+
+// updateGolden rewrites the expected bindings of the golden cases with the actual ones:
+// go test ./pkg/controller/grouppermission -run TestGolden -update
+var updateGolden = flag.Bool("update", false, "update the expected bindings of the golden cases")
+
+// goldenDir holds a directory per golden case, with the objects to reconcile in input.yaml and the
+// bindings expected after the reconcile in expected.yaml
+const goldenDir = "testdata/golden"
+
+// goldenBinding is the part of a ClusterRoleBinding or RoleBinding compared by the golden cases
+type goldenBinding struct {
+	Kind      string           `json:"kind"`
+	Name      string           `json:"name"`
+	Namespace string           `json:"namespace,omitempty"`
+	RoleRef   rbacv1.RoleRef   `json:"roleRef"`
+	Subjects  []rbacv1.Subject `json:"subjects"`
+}
+
+// decodeObjects decodes the YAML documents of path
+func decodeObjects(path string) ([]runtime.Object, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var objects []runtime.Object
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(file))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+		object, _, err := scheme.Codecs.UniversalDeserializer().Decode(document, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+}
+
+// listGoldenBindings returns the bindings of c, sorted by kind, namespace and name
+func listGoldenBindings(c client.Client) ([]goldenBinding, error) {
+	bindings := []goldenBinding{}
+
+	clusterRoleBindingList := &rbacv1.ClusterRoleBindingList{}
+	if err := c.List(context.TODO(), &client.ListOptions{}, clusterRoleBindingList); err != nil {
+		return nil, err
+	}
+	for _, binding := range clusterRoleBindingList.Items {
+		bindings = append(bindings, goldenBinding{Kind: "ClusterRoleBinding", Name: binding.Name, RoleRef: binding.RoleRef, Subjects: binding.Subjects})
+	}
+
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := c.List(context.TODO(), &client.ListOptions{}, roleBindingList); err != nil {
+		return nil, err
+	}
+	for _, binding := range roleBindingList.Items {
+		bindings = append(bindings, goldenBinding{Kind: "RoleBinding", Name: binding.Name, Namespace: binding.Namespace, RoleRef: binding.RoleRef, Subjects: binding.Subjects})
+	}
+
+	sort.Slice(bindings, func(i, j int) bool {
+		if bindings[i].Kind != bindings[j].Kind {
+			return bindings[i].Kind < bindings[j].Kind
+		}
+		if bindings[i].Namespace != bindings[j].Namespace {
+			return bindings[i].Namespace < bindings[j].Namespace
+		}
+		return bindings[i].Name < bindings[j].Name
+	})
+	return bindings, nil
+}
+
+// runGoldenCase reconciles the GroupPermissions of the input of dir and compares the resulting
+// bindings with the expected ones
+func runGoldenCase(t *testing.T, dir string) {
+	objects, err := decodeObjects(filepath.Join(dir, "input.yaml"))
+	if err != nil {
+		t.Fatalf("unable to decode the input: %v", err)
+	}
+
+	// the GroupPermissions of the inputs are requested by a cluster admin, allowed to grant any role
+	fakeClient := fake.NewFakeClient(objects...)
+	reconciler := &ReconcileGroupPermission{
+		client:    &accessReviewClient{Client: fakeClient, allowed: map[string]bool{"cluster-admin": true}},
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+	for _, object := range objects {
+		groupPermission, ok := object.(*v1alpha1.GroupPermission)
+		if !ok {
+			continue
+		}
+		key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+		if _, err := reconciler.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("unexpected error reconciling %s: %v", key, err)
+		}
+	}
+
+	bindings, err := listGoldenBindings(fakeClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual, err := yaml.Marshal(bindings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedPath := filepath.Join(dir, "expected.yaml")
+	if *updateGolden {
+		if err := ioutil.WriteFile(expectedPath, actual, 0644); err != nil {
+			t.Fatalf("unable to update the expected bindings: %v", err)
+		}
+		return
+	}
+	expected, err := ioutil.ReadFile(expectedPath)
+	if err != nil {
+		t.Fatalf("unable to read the expected bindings: %v", err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("bindings differ from %s, run with -update to accept them\nexpected:\n%s\nactual:\n%s", expectedPath, expected, actual)
+	}
+}
+
+// TestGolden tests the bindings created by Reconcile
+// given: the objects of each case under testdata/golden
+// expected: the bindings of the case
+func TestGolden(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	dirs, err := ioutil.ReadDir(goldenDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		t.Run(dir.Name(), func(t *testing.T) {
+			runGoldenCase(t, filepath.Join(goldenDir, dir.Name()))
+		})
+	}
+}
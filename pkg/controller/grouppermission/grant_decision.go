@@ -0,0 +1,100 @@
+package grouppermission
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/grantdecision"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	v1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// grantDecider asks the grant decision service configured in the operator config about the bindings the
+// policy allows, within the timeout of the config, and applies its failure mode when the service fails
+type grantDecider struct {
+	decider  grantdecision.Decider
+	timeout  time.Duration
+	failOpen bool
+}
+
+// newGrantDecider returns the grantDecider of operatorConfig, or nil when no grant decision service is
+// configured
+func newGrantDecider(httpClient *http.Client, operatorConfig *operatorconfig.OperatorConfig) *grantDecider {
+	if operatorConfig.GrantDecisionEndpoint == "" {
+		return nil
+	}
+	return &grantDecider{
+		decider:  &grantdecision.Client{HTTPClient: httpClient, Endpoint: operatorConfig.GrantDecisionEndpoint},
+		timeout:  operatorConfig.GrantDecisionTimeout,
+		failOpen: operatorConfig.GrantDecisionFailureMode == operatorconfig.GrantDecisionFailOpen,
+	}
+}
+
+// decide returns the decision of the grant decision service on the binding of roleRef to subject in
+// namespace for groupPermission. Every binding is allowed when d is nil. When the service fails, the
+// binding is allowed unchanged if d fails open, the error is returned otherwise.
+func (d *grantDecider) decide(groupPermission *managedv1alpha1.GroupPermission, subject v1.Subject, roleRef v1.RoleRef, namespace string) (grantdecision.Decision, error) {
+	if d == nil {
+		return grantdecision.Decision{Allowed: true}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), d.timeout)
+	defer cancel()
+	start := time.Now()
+	decision, err := d.decider.Decide(ctx, grantdecision.Request{
+		Subject:         subject,
+		RoleRef:         roleRef,
+		Namespace:       namespace,
+		GroupPermission: grantdecision.Owner{Namespace: groupPermission.Namespace, Name: groupPermission.Name},
+	})
+	if err != nil {
+		localmetrics.ObserveGrantDecision(localmetrics.GrantDecisionFailed, time.Since(start))
+		if !d.failOpen {
+			return grantdecision.Decision{}, err
+		}
+		log.Error(err, "Grant decision service failed, creating the binding", "Request.Namespace", groupPermission.Namespace,
+			"Request.Name", groupPermission.Name, "RoleRef", roleRef.Kind+" "+roleRef.Name, "Namespace", namespace)
+		return grantdecision.Decision{Allowed: true}, nil
+	}
+
+	result := localmetrics.GrantDecisionAllowed
+	if !decision.Allowed {
+		result = localmetrics.GrantDecisionDenied
+	}
+	localmetrics.ObserveGrantDecision(result, time.Since(start))
+	return decision, nil
+}
+
+// grantDeniedMessage returns the message recorded for a binding denied by decision
+func grantDeniedMessage(decision grantdecision.Decision) string {
+	if decision.Message == "" {
+		return "Denied by the grant decision service"
+	}
+	return "Denied by the grant decision service: " + decision.Message
+}
+
+// setGrantAnnotations adds the annotations of decision to objectMeta, except those of the domains of the
+// operator, which a grant decision service cannot override
+func setGrantAnnotations(objectMeta *metav1.ObjectMeta, decision grantdecision.Decision) {
+	for key, value := range decision.Annotations {
+		if isOperatorAnnotation(key) {
+			continue
+		}
+		if objectMeta.Annotations == nil {
+			objectMeta.Annotations = map[string]string{}
+		}
+		objectMeta.Annotations[key] = value
+	}
+}
+
+// isOperatorAnnotation returns whether the annotation key is in a domain the operator writes on bindings
+func isOperatorAnnotation(key string) bool {
+	domain := strings.SplitN(key, "/", 2)[0]
+	return domain == "managed.openshift.io" || domain == "rbac.managed.openshift.io"
+}
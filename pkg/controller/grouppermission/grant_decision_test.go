@@ -0,0 +1,114 @@
+package grouppermission
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/grantdecision"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// TestGrantDeciderFailureMode tests the decide function
+// given: no grant decision service, and a failing one with each failure mode
+// expected: every binding is allowed without a service, a failure is returned when failing closed and
+// the binding allowed unchanged when failing open
+func TestGrantDeciderFailureMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	groupPermission := mockGroupPermission()
+	subject := rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "exampleGroupName"}
+	roleRef := rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}
+	if decision, err := newGrantDecider(nil, operatorconfig.DefaultOperatorConfig()).decide(groupPermission, subject, roleRef, ""); err != nil || !decision.Allowed {
+		t.Errorf("expected the binding to be allowed without a grant decision service, got %+v, %v", decision, err)
+	}
+
+	operatorConfig := &operatorconfig.OperatorConfig{GrantDecisionEndpoint: server.URL, GrantDecisionTimeout: time.Second, GrantDecisionFailureMode: operatorconfig.GrantDecisionFailClosed}
+	if _, err := newGrantDecider(nil, operatorConfig).decide(groupPermission, subject, roleRef, ""); err == nil {
+		t.Errorf("expected an error when failing closed")
+	}
+	operatorConfig.GrantDecisionFailureMode = operatorconfig.GrantDecisionFailOpen
+	decision, err := newGrantDecider(nil, operatorConfig).decide(groupPermission, subject, roleRef, "")
+	if err != nil || !decision.Allowed || len(decision.Annotations) != 0 {
+		t.Errorf("expected the binding to be allowed unchanged when failing open, got %+v, %v", decision, err)
+	}
+}
+
+// TestReconcileNamespacePermissionsGrantDecision tests the reconcileNamespacePermissions function
+// given: a GroupPermission allowed in two Namespaces, and a grant decision service denying one of them
+// and annotating the binding of the other, including with an annotation of the operator
+// expected: the RoleBinding of the denied Namespace is not created and its NamespaceStatus records the
+// message of the service, the other RoleBinding has the annotations of the service but that of the operator
+func TestReconcileNamespacePermissionsGrantDecision(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	server := httptest.NewServer(grantdecision.Handler(grantdecision.DeciderFunc(func(ctx context.Context, request grantdecision.Request) (grantdecision.Decision, error) {
+		if request.Namespace == "team-b" {
+			return grantdecision.Decision{Message: "no approval for team-b"}, nil
+		}
+		return grantdecision.Decision{Allowed: true, Annotations: map[string]string{
+			"approvals.example.com/id":               "CHG-42",
+			operatorconfig.SourceAnnotation:          "elsewhere/other",
+			operatorconfig.LastAppliedHashAnnotation: "0",
+		}}, nil
+	})))
+	defer server.Close()
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+	operatorConfig.GrantDecisionEndpoint = server.URL
+
+	fakeClient := newRequesterClient(mockNamespace("team-a"), mockNamespace("team-b"))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+	}
+	groupPermission := mockNamespacedGroupPermission()
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(groupPermission, nil, nil, nil, operatorConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaceStatuses) != 2 {
+		t.Fatalf("got %d namespace statuses, want 2: %v", len(namespaceStatuses), namespaceStatuses)
+	}
+
+	for _, namespaceStatus := range namespaceStatuses {
+		roleBinding := &rbacv1.RoleBinding{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: namespaceStatus.Namespace, Name: namespaceStatus.BindingName}, roleBinding)
+		if namespaceStatus.Namespace == "team-b" {
+			if namespaceStatus.State != v1alpha1.GroupPermissionFailed || namespaceStatus.LastError != "Denied by the grant decision service: no approval for team-b" {
+				t.Errorf("expected team-b to be denied by the grant decision service, got %+v", namespaceStatus)
+			}
+			if !errors.IsNotFound(err) {
+				t.Errorf("expected no RoleBinding in team-b, got %v", err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("expected the RoleBinding in %s: %v", namespaceStatus.Namespace, err)
+		}
+		if roleBinding.Annotations["approvals.example.com/id"] != "CHG-42" {
+			t.Errorf("expected the annotation of the grant decision service, got %v", roleBinding.Annotations)
+		}
+		if source := groupPermission.Namespace + "/" + groupPermission.Name; roleBinding.Annotations[operatorconfig.SourceAnnotation] != source {
+			t.Errorf("Mismatch for the source annotation. Expected(%s), Found(%s)", source, roleBinding.Annotations[operatorconfig.SourceAnnotation])
+		}
+		if _, ok := roleBinding.Annotations[operatorconfig.LastAppliedHashAnnotation]; ok {
+			t.Errorf("expected the annotations of the operator not to be set by the grant decision service, got %v", roleBinding.Annotations)
+		}
+	}
+}
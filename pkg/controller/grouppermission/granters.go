@@ -0,0 +1,33 @@
+package grouppermission
+
+import (
+	"fmt"
+	"strings"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// isGranterAllowed returns whether the requester of groupPermission, as recorded by the webhook, is a
+// member of a group allowed to grant roles in namespace. A GroupPermission without a recorded requester
+// is only allowed in the Namespaces that do not restrict their granters.
+func isGranterAllowed(groupPermission *managedv1alpha1.GroupPermission, namespace metav1.Object) bool {
+	if len(utility.AllowedGranters(namespace)) == 0 {
+		return true
+	}
+	req := requesterFromAnnotations(groupPermission)
+	return req != nil && utility.IsAllowedGranter(namespace, req.groups)
+}
+
+// notGranterMessage returns the error of a RoleBinding not created in namespace because the requester
+// of groupPermission is not an allowed granter
+func notGranterMessage(groupPermission *managedv1alpha1.GroupPermission, namespace metav1.Object) string {
+	requester := "no recorded requester"
+	if req := requesterFromAnnotations(groupPermission); req != nil {
+		requester = "requester " + req.user
+	}
+	return fmt.Sprintf("Namespace %s only allows GroupPermissions authored by members of %s, the GroupPermission has %s",
+		namespace.GetName(), strings.Join(utility.AllowedGranters(namespace), ", "), requester)
+}
@@ -0,0 +1,110 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// mockGrantersNamespace returns the Namespace name allowing the granters
+func mockGrantersNamespace(name, granters string) *corev1.Namespace {
+	namespace := mockNamespace(name)
+	namespace.Annotations = map[string]string{operatorconfig.AllowedGrantersAnnotation: granters}
+	return namespace
+}
+
+// TestIsGranterAllowed tests the isGranterAllowed function
+// given: Namespaces restricting their granters or not, and GroupPermissions of requesters in various groups
+// expected: only the requesters in an allowed group, or any GroupPermission without restriction, are allowed
+func TestIsGranterAllowed(t *testing.T) {
+	var tests = []struct {
+		name      string
+		granters  string
+		requester map[string]string
+		allowed   bool
+	}{
+		{"no restriction", "", nil, true},
+		{"member", "team-a-admins", map[string]string{operatorconfig.RequesterAnnotation: "alice", operatorconfig.RequesterGroupsAnnotation: "team-a-admins,system:authenticated"}, true},
+		{"member of one of the granters", "sre, team-a-admins", map[string]string{operatorconfig.RequesterAnnotation: "alice", operatorconfig.RequesterGroupsAnnotation: "team-a-admins"}, true},
+		{"not a member", "team-a-admins", map[string]string{operatorconfig.RequesterAnnotation: "bob", operatorconfig.RequesterGroupsAnnotation: "team-b-admins"}, false},
+		{"no recorded requester", "team-a-admins", nil, false},
+	}
+	for _, test := range tests {
+		groupPermission := mockNamespacedGroupPermission()
+		groupPermission.Annotations = test.requester
+		namespace := mockNamespace("team-a")
+		if test.granters != "" {
+			namespace = mockGrantersNamespace("team-a", test.granters)
+		}
+		if allowed := isGranterAllowed(groupPermission, namespace); allowed != test.allowed {
+			t.Errorf("%s: Mismatch for allowed. Expected(%t), Found(%t)", test.name, test.allowed, allowed)
+		}
+	}
+}
+
+// TestReconcileNamespacePermissionsGranters tests the reconcileNamespacePermissions and reconcileViewRoles
+// functions
+// given: GroupPermission with a view Role authored by a member of team-b-admins, allowed in a Namespace
+// restricted to team-a-admins since its RoleBindings were created there, and in a Namespace without
+// restriction
+// expected: the RoleBindings are only created in the Namespace without restriction, the other one is
+// failed and its RoleBindings deleted
+func TestReconcileNamespacePermissionsGranters(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Annotations = map[string]string{operatorconfig.RequesterAnnotation: "bob", operatorconfig.RequesterGroupsAnnotation: "team-b-admins"}
+	groupPermission.Spec.ViewRole = &v1alpha1.ViewRole{}
+	permission, _ := viewPermission(groupPermission)
+	var staleRoleBindings []*v1.RoleBinding
+	for _, roleRef := range []v1.RoleRef{{Kind: "ClusterRole", Name: "admin"}, {Kind: "Role", Name: permission.RoleName}} {
+		roleBinding := newRoleBinding("team-a", roleRef, utility.GroupSubject(&groupPermission.Spec))
+		utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+		staleRoleBindings = append(staleRoleBindings, roleBinding)
+	}
+	fakeClient := &accessReviewClient{Client: fake.NewFakeClient(groupPermission.DeepCopy(), mockGrantersNamespace("team-a", "team-a-admins"), mockNamespace("team-b"),
+		staleRoleBindings[0], staleRoleBindings[1]), allowed: map[string]bool{"bob": true}}
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(groupPermission, nil, nil, nil, operatorconfig.DefaultOperatorConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reconciler.reconcileViewRoles(groupPermission, nil, operatorconfig.DefaultOperatorConfig()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	states := map[string]v1alpha1.NamespaceStatus{}
+	for _, namespaceStatus := range namespaceStatuses {
+		states[namespaceStatus.Namespace] = namespaceStatus
+	}
+	if states["team-a"].State != v1alpha1.GroupPermissionFailed || !strings.Contains(states["team-a"].LastError, "team-a-admins") {
+		t.Errorf("Mismatch for team-a. Expected(Failed, not an allowed granter), Found(%+v)", states["team-a"])
+	}
+	if states["team-b"].State != v1alpha1.GroupPermissionCreated {
+		t.Errorf("Mismatch for team-b. Expected(%s), Found(%s)", v1alpha1.GroupPermissionCreated, states["team-b"].State)
+	}
+	for _, roleBinding := range staleRoleBindings {
+		err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: roleBinding.Name}, &v1.RoleBinding{})
+		if !errors.IsNotFound(err) {
+			t.Errorf("expected the RoleBinding %s in team-a to be deleted, got %v", roleBinding.Name, err)
+		}
+	}
+	viewRoleBinding := newRoleBinding("team-b", v1.RoleRef{Kind: "Role", Name: permission.RoleName}, utility.GroupSubject(&groupPermission.Spec))
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-b", Name: viewRoleBinding.Name}, &v1.RoleBinding{}); err != nil {
+		t.Errorf("expected the RoleBinding of the view Role in team-b: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package grouppermission
+
+import (
+	"context"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// groupGVK is the OpenShift Group kind, handled as unstructured to avoid depending on the OpenShift API types
+var groupGVK = schema.GroupVersionKind{Group: "user.openshift.io", Version: "v1", Kind: "Group"}
+
+// ensureGroup creates the Group of groupPermission, with no members, if it does not exist. A Group
+// known to exist by the group cache is not read again until its entry expires.
+// Returns whether the Group was created.
+func (r *ReconcileGroupPermission) ensureGroup(groupPermission *managedv1alpha1.GroupPermission) (bool, error) {
+	groupName := groupPermission.Spec.GroupName
+	if r.groups.exists(groupName) {
+		return false, nil
+	}
+	operatorConfig, err := r.getOperatorConfig(context.TODO())
+	if err != nil {
+		return false, err
+	}
+
+	group := &unstructured.Unstructured{}
+	group.SetGroupVersionKind(groupGVK)
+	err = r.apiClient.Get(context.TODO(), types.NamespacedName{Name: groupName}, group)
+	if err == nil {
+		r.groups.add(groupName, operatorConfig.GroupCacheTTL)
+		return false, nil
+	}
+	if !errors.IsNotFound(err) {
+		return false, err
+	}
+
+	group = newGroup(groupPermission.Spec.GroupName)
+	group.SetLabels(utility.ManagedLabels(groupPermission.Namespace, groupPermission.Name))
+	err = r.apiClient.Create(context.TODO(), group)
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			r.groups.add(groupName, operatorConfig.GroupCacheTTL)
+			return false, nil
+		}
+		return false, err
+	}
+	r.groups.add(groupName, operatorConfig.GroupCacheTTL)
+
+	r.eventf(groupPermission, corev1.EventTypeNormal, "GroupCreated", "Created Group %s with no members", groupPermission.Spec.GroupName)
+	return true, nil
+}
+
+// newGroup creates and returns a Group named groupName with no members
+func newGroup(groupName string) *unstructured.Unstructured {
+	group := &unstructured.Unstructured{Object: map[string]interface{}{
+		"users": []interface{}{},
+	}}
+	group.SetGroupVersionKind(groupGVK)
+	group.SetName(groupName)
+	return group
+}
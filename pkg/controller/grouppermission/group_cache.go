@@ -0,0 +1,85 @@
+package grouppermission
+
+import (
+	"sync"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// groupCache records the Groups known to exist, each until its entry expires, so their existence is
+// not read from the apiserver on every reconcile
+type groupCache struct {
+	mutex sync.Mutex
+	// expiries holds when the existence of each Group was last read plus the TTL of the cache then
+	expiries map[string]time.Time
+	now      func() time.Time
+}
+
+// newGroupCache returns an empty groupCache
+func newGroupCache() *groupCache {
+	return &groupCache{expiries: map[string]time.Time{}, now: time.Now}
+}
+
+// exists returns whether groupName is known to exist, counting the lookup in the metrics. A nil cache
+// knows no Group and counts nothing.
+func (c *groupCache) exists(groupName string) bool {
+	if c == nil {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	expiry, ok := c.expiries[groupName]
+	hit := ok && c.now().Before(expiry)
+	if ok && !hit {
+		delete(c.expiries, groupName)
+	}
+	localmetrics.IncGroupCacheLookup(hit)
+	return hit
+}
+
+// add records that groupName exists, for ttl. Nothing is recorded when ttl is 0.
+func (c *groupCache) add(groupName string, ttl time.Duration) {
+	if c == nil || ttl <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.expiries[groupName] = c.now().Add(ttl)
+}
+
+// forget removes groupName from the cache, so its existence is read again
+func (c *groupCache) forget(groupName string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.expiries, groupName)
+}
+
+// groupCacheInvalidator forgets the deleted Groups in a groupCache, it enqueues nothing
+type groupCacheInvalidator struct {
+	cache *groupCache
+}
+
+// blank assignment to verify that groupCacheInvalidator implements handler.EventHandler
+var _ handler.EventHandler = &groupCacheInvalidator{}
+
+// Create implements handler.EventHandler
+func (i *groupCacheInvalidator) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {}
+
+// Update implements handler.EventHandler
+func (i *groupCacheInvalidator) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {}
+
+// Delete implements handler.EventHandler
+func (i *groupCacheInvalidator) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	i.cache.forget(evt.Meta.GetName())
+}
+
+// Generic implements handler.EventHandler
+func (i *groupCacheInvalidator) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {}
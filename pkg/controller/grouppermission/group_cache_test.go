@@ -0,0 +1,83 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// TestGroupCache tests the groupCache type
+// given: a Group added to the cache, then the cache past its TTL, then a Group deleted
+// expected: the Group exists until its entry expires or it is forgotten, nothing is cached without a TTL
+func TestGroupCache(t *testing.T) {
+	now := time.Now()
+	cache := newGroupCache()
+	cache.now = func() time.Time { return now }
+
+	cache.add("team-a", time.Minute)
+	cache.add("team-b", 0)
+	if !cache.exists("team-a") {
+		t.Errorf("expected team-a to exist within the TTL")
+	}
+	if cache.exists("team-b") {
+		t.Errorf("expected team-b not to be cached without a TTL")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if cache.exists("team-a") {
+		t.Errorf("expected the entry of team-a to expire after the TTL")
+	}
+
+	cache.add("team-a", time.Minute)
+	invalidator := &groupCacheInvalidator{cache: cache}
+	invalidator.Delete(event.DeleteEvent{Meta: newGroup("team-a")}, nil)
+	if cache.exists("team-a") {
+		t.Errorf("expected team-a to be forgotten once deleted")
+	}
+
+	var disabled *groupCache
+	disabled.add("team-a", time.Minute)
+	if disabled.exists("team-a") {
+		t.Errorf("expected a nil cache to know no Group")
+	}
+}
+
+// TestEnsureGroupCached tests the ensureGroup function with a group cache
+// given: GroupPermission whose Group is created, then deleted behind the cache, then forgotten
+// expected: the Group is not read again while cached, and created again once forgotten
+func TestEnsureGroupCached(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	fakeClient := fake.NewFakeClient()
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		groups:    newGroupCache(),
+	}
+	groupPermission := mockGroupPermission()
+
+	if created, err := reconciler.ensureGroup(groupPermission); err != nil || !created {
+		t.Fatalf("expected Group to be created, got %t, %v", created, err)
+	}
+	if err := fakeClient.Delete(context.TODO(), newGroup(groupPermission.Spec.GroupName)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if created, err := reconciler.ensureGroup(groupPermission); err != nil || created {
+		t.Errorf("expected the cached Group not to be read again, got %t, %v", created, err)
+	}
+
+	reconciler.groups.forget(groupPermission.Spec.GroupName)
+	if created, err := reconciler.ensureGroup(groupPermission); err != nil || !created {
+		t.Errorf("expected the forgotten Group to be created again, got %t, %v", created, err)
+	}
+}
@@ -0,0 +1,54 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestEnsureGroup tests the ensureGroup function
+// given: GroupPermission whose Group does not exist
+// expected: the Group is created with no members once, then left alone
+func TestEnsureGroup(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	fakeClient := fake.NewFakeClient()
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+	}
+	groupPermission := mockGroupPermission()
+
+	created, err := reconciler.ensureGroup(groupPermission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !created {
+		t.Errorf("expected Group to be created")
+	}
+
+	group := &unstructured.Unstructured{}
+	group.SetGroupVersionKind(groupGVK)
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: groupPermission.Spec.GroupName}, group); err != nil {
+		t.Fatalf("expected Group %s: %v", groupPermission.Spec.GroupName, err)
+	}
+	if users, _, _ := unstructured.NestedSlice(group.Object, "users"); len(users) != 0 {
+		t.Errorf("expected Group with no members, got %v", users)
+	}
+
+	created, err = reconciler.ensureGroup(groupPermission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created {
+		t.Errorf("expected existing Group not to be created again")
+	}
+}
@@ -3,15 +3,28 @@ package grouppermission
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"reflect"
 	"strings"
+	"sync"
+	"time"
 
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
 	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
 	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/openshift/rbac-permissions-operator/pkg/readonly"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
 
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -31,12 +44,80 @@ var log = logf.Log.WithName("controller_grouppermission")
 // Add creates a new GroupPermission Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	return AddWithOptions(mgr)
 }
 
-// newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileGroupPermission{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+// AddWithOptions creates a new GroupPermission Controller configured by opts and adds it to the Manager, so
+// the controller can be embedded in the manager of another binary alongside other controllers
+func AddWithOptions(mgr manager.Manager, opts ...Option) error {
+	r, err := NewGroupPermissionReconciler(mgr, opts...)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
+}
+
+// NewGroupPermissionReconciler returns a new ReconcileGroupPermission of mgr configured by opts. The clients
+// of opts count their requests and skip writes in read-only mode like the default ones.
+func NewGroupPermissionReconciler(mgr manager.Manager, opts ...Option) (*ReconcileGroupPermission, error) {
+	options := newReconcilerOptions(opts)
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	groupPermissionShard, err := options.shard(mgr.GetClient())
+	if err != nil {
+		return nil, err
+	}
+
+	if options.client == nil {
+		// the client of the manager is wrapped when it is created
+		options.client = mgr.GetClient()
+	} else {
+		options.client = readonly.Wrap(options.client, mgr.GetScheme())
+	}
+	if options.apiClient == nil {
+		// the manager cache only holds the watched namespace, RoleBindings are read from the apiserver
+		apiClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		if err != nil {
+			return nil, err
+		}
+		options.apiClient = apiClient
+	}
+	if options.recorder == nil {
+		options.recorder = mgr.GetRecorder("grouppermission-controller")
+	}
+	statusClient, err := newStatusClient(mgr.GetConfig(), mgr.GetScheme())
+	if err != nil {
+		return nil, err
+	}
+	bindings, err := newBindingIndex(mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := &apiBudget{}
+	traces := &reconcileTraces{}
+	reconciler := &ReconcileGroupPermission{
+		client:     countRequests(traceRequests(options.client, traces, localmetrics.SourceCache), budget, localmetrics.SourceCache),
+		apiClient:  countRequests(traceRequests(readonly.Wrap(options.apiClient, mgr.GetScheme()), traces, localmetrics.SourceAPIServer), budget, localmetrics.SourceAPIServer),
+		scheme:     mgr.GetScheme(),
+		recorder:   options.recorder,
+		shard:      groupPermissionShard,
+		httpClient: &http.Client{},
+		namespaces: newNamespaceIndex(),
+		groups:     newGroupCache(),
+		bindings:   bindings,
+		// status patches are passed through in read-only mode, like status updates
+		statusClient:       statusClient,
+		budget:             budget,
+		traces:             traces,
+		operatorConfig:     options.operatorConfig,
+		removeStaleGrants:  options.removeStaleGrants,
+		roleContentWorkers: options.roleContentWorkers,
+		roleContentResync:  options.roleContentResync,
+	}
+	reconciler.events = newEventAggregator(reconciler)
+	return reconciler, nil
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -47,8 +128,122 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
-	// Watch for changes to primary resource GroupPermission
-	err = c.Watch(&source.Kind{Type: &managedv1alpha1.GroupPermission{}}, &handler.EnqueueRequestForObject{})
+	// The GroupPermissions of the shard of the reconciler are watched, every one without a shard
+	var groupPermissionShard *shard
+	if reconciler, ok := r.(*ReconcileGroupPermission); ok {
+		groupPermissionShard = reconciler.shard
+	}
+
+	// Watch for changes to primary resource GroupPermission, in the shard of this instance
+	err = c.Watch(&source.Kind{Type: &managedv1alpha1.GroupPermission{}}, &handler.EnqueueRequestForObject{}, groupPermissionShard.predicate())
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the GroupPermissions created for the Groups selected by a groupNameSelector,
+	// to create them again when deleted
+	err = c.Watch(&source.Kind{Type: &managedv1alpha1.GroupPermission{}}, &handler.EnqueueRequestForOwner{
+		OwnerType:    &managedv1alpha1.GroupPermission{},
+		IsController: true,
+	}, groupPermissionShard.predicate())
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to GroupPermissions, to reconcile the GroupPermissions depending on them once
+	// they become Active
+	err = c.Watch(&source.Kind{Type: &managedv1alpha1.GroupPermission{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: &groupPermissionToDependents{client: mgr.GetClient(), shard: groupPermissionShard},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Groups, to keep the GroupPermissions of the Groups selected by a
+	// groupNameSelector up to date with the created, deleted and relabeled Groups
+	group := &unstructured.Unstructured{}
+	group.SetGroupVersionKind(groupGVK)
+	err = c.Watch(&source.Kind{Type: group}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: &groupToGroupPermissions{client: mgr.GetClient(), shard: groupPermissionShard},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Namespaces, reconciling every GroupPermission allowed in them once per burst
+	err = c.Watch(&source.Kind{Type: &corev1.Namespace{}}, newCoalescingHandler(
+		&namespaceToGroupPermissions{client: mgr.GetClient(), shard: groupPermissionShard}, namespaceBurstWindow,
+	))
+	if err != nil {
+		return err
+	}
+
+	// Keep the index of allowed Namespaces up to date with changed and deleted Namespaces
+	if reconciler, ok := r.(*ReconcileGroupPermission); ok && reconciler.namespaces != nil {
+		err = c.Watch(&source.Kind{Type: &corev1.Namespace{}}, &namespaceIndexUpdater{index: reconciler.namespaces})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Forget the deleted Groups in the group cache, so they are created again by the next reconcile
+	if reconciler, ok := r.(*ReconcileGroupPermission); ok && reconciler.groups != nil {
+		group := &unstructured.Unstructured{}
+		group.SetGroupVersionKind(groupGVK)
+		err = c.Watch(&source.Kind{Type: group}, &groupCacheInvalidator{cache: reconciler.groups})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Flag the GroupPermissions not Active within the stuck deadline after their spec changed
+	if reconciler, ok := r.(*ReconcileGroupPermission); ok {
+		if err := mgr.Add(newWatchdog(reconciler)); err != nil {
+			return err
+		}
+	}
+
+	// Flag the roles their Group did not use within the stale grant age, and remove them when enabled
+	if reconciler, ok := r.(*ReconcileGroupPermission); ok {
+		if err := mgr.Add(newReaper(reconciler)); err != nil {
+			return err
+		}
+	}
+
+	// Record the summaries of the per-namespace events
+	if reconciler, ok := r.(*ReconcileGroupPermission); ok && reconciler.events != nil {
+		if err := mgr.Add(reconciler.events); err != nil {
+			return err
+		}
+	}
+
+	// Collapse the managed bindings granting the same role to the same subjects, as left by renames
+	if reconciler, ok := r.(*ReconcileGroupPermission); ok {
+		if err := mgr.Add(newDeduplicator(reconciler)); err != nil {
+			return err
+		}
+	}
+
+	// Converge the rules of the Roles created from the Permissions with rules in a controller of its own
+	if reconciler, ok := r.(*ReconcileGroupPermission); ok {
+		if err := addRoleContent(mgr, reconciler); err != nil {
+			return err
+		}
+	}
+
+	// Watch for ClusterRoles being created or deleted, to remove the bindings to a deleted ClusterRole
+	// and create them again once it is back
+	err = c.Watch(&source.Kind{Type: &v1.ClusterRole{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: &clusterRoleToGroupPermissions{client: mgr.GetClient(), shard: groupPermissionShard},
+	}, clusterRoleCreatedOrDeleted)
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the operator configuration, resyncing every GroupPermission of the shard
+	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: &operatorConfigToGroupPermissions{client: mgr.GetClient(), shard: groupPermissionShard},
+	}, operatorConfigChanged)
 	if err != nil {
 		return err
 	}
@@ -64,15 +259,137 @@ type ReconcileGroupPermission struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
-	scheme *runtime.Scheme
+	// apiClient reads directly from the apiserver, for objects outside the watched namespace
+	apiClient client.Client
+	scheme    *runtime.Scheme
+	recorder  record.EventRecorder
+	// failures tracks consecutive reconcile failures of each GroupPermission
+	failures failureBudget
+	// shard selects the GroupPermissions reconciled by this instance, every one when nil
+	shard *shard
+	// httpClient calls the policy, usage and grant decision endpoints
+	httpClient *http.Client
+	// namespaces indexes the Namespaces allowed by each GroupPermission, every Namespace is
+	// evaluated when nil
+	namespaces *namespaceIndex
+	// namesChecked records the GroupPermissions whose bindings were checked for a previous naming scheme
+	namesChecked sync.Map
+	// statusClient patches the status of GroupPermissions, the whole status is updated when nil
+	statusClient rest.Interface
+	// statusBases records the status of each GroupPermission last read or written, the base of its
+	// status patches
+	statusBases sync.Map
+	// groups caches the Groups known to exist, every Group is read when nil
+	groups *groupCache
+	// budget counts the requests of the clients, for the metrics of the load on the apiserver, nothing
+	// is counted when nil
+	budget *apiBudget
+	// traces holds the span of the reconcile in progress, the parent of the spans of the requests of the
+	// clients, nothing is traced when nil
+	traces *reconcileTraces
+	// bindings indexes the managed RoleBindings by owning GroupPermission, they are listed from the
+	// apiserver when nil
+	bindings *bindingIndex
+	// operatorConfig is the configuration of the operator, read from the operator ConfigMap on each
+	// reconcile when nil
+	operatorConfig *operatorconfig.OperatorConfig
+	// events applies the event verbosity of the operator config and aggregates the per-namespace
+	// events, every event is recorded on its own when nil
+	events *eventAggregator
+	// removeStaleGrants enables the removal of the stale grants by the reaper, they are only flagged otherwise
+	removeStaleGrants bool
+	// roleContentWorkers is the number of GroupPermissions converged concurrently by the role content
+	// controller, and roleContentResync the interval between two convergences of the Roles of each,
+	// bounding how long a Role edited outside the operator keeps its rules
+	roleContentWorkers int
+	roleContentResync  time.Duration
 }
 
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch;bind
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=bind
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings;rolebindings,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+// +kubebuilder:rbac:groups=user.openshift.io,resources=groups,verbs=get;create
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=grouppermissions,verbs=get;list;watch;update,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=grouppermissions/status,verbs=update;patch,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch,namespace=openshift-rbac-permissions-operator
+
 // Reconcile reads that state of the cluster for a GroupPermission object and makes changes based on the state read
 // and what is in the GroupPermission.Spec
 // Note:
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+// A GroupPermission that exhausts its failure budget is marked Degraded and retried at a long interval.
+// A GroupPermission whose reconcile panics is quarantined, and skipped until its spec changes.
+// Each reconcile is traced as a span, the parent of the spans of its API requests.
 func (r *ReconcileGroupPermission) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	// deferred first, so the requests of updatePhase are counted and traced
+	defer r.reportAPIRequests(request)
+	span := r.traces.begin(request)
+	defer r.traces.end(span)
+
+	// GroupPermissions of other shards are reconciled by the operator instance of their shard
+	inShard, err := r.inShard(request)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !inShard {
+		return reconcile.Result{}, nil
+	}
+
+	// a GroupPermission whose reconcile panicked is skipped until its spec changes
+	quarantined, err := r.isQuarantined(request)
+	if err != nil || quarantined {
+		return reconcile.Result{}, err
+	}
+
+	// the phase reflects the conditions of every outcome, including Degraded
+	defer r.updatePhase(request)
+
+	result, err := r.recoverReconcile(request, r.reconcile)
+	span.RecordError(err)
+	if err != nil {
+		err = typedError(err)
+		if expectedResult, ok := requeueForError(err); ok {
+			log.Info("Requeuing GroupPermission", "Request.Namespace", request.Namespace, "Request.Name", request.Name, "Reason", err.Error())
+			return r.jitter(expectedResult), nil
+		}
+	}
+	if err == nil {
+		if r.failures.recordSuccess(request.NamespacedName) {
+			r.clearDegraded(request)
+		}
+		// the resync is jittered by reconcile, schedule transitions must not be
+		return result, nil
+	}
+
+	exhausted, emitEvent, failures := r.failures.recordFailure(request.NamespacedName, time.Now())
+	if !exhausted {
+		return result, err
+	}
+	r.setDegraded(request, failures, err, emitEvent)
+	return r.jitter(reconcile.Result{RequeueAfter: degradedRequeueInterval}), nil
+}
+
+// jitter lengthens the requeue interval of result by a random fraction, so GroupPermissions
+// reconciled together are not requeued together
+func (r *ReconcileGroupPermission) jitter(result reconcile.Result) reconcile.Result {
+	if result.RequeueAfter <= 0 {
+		return result
+	}
+	operatorConfig, err := r.getOperatorConfig(context.TODO())
+	if err != nil {
+		log.Error(err, "Failed to get operator config, using the default jitter")
+		operatorConfig = operatorconfig.DefaultOperatorConfig()
+	}
+	result.RequeueAfter = operatorConfig.Jitter(result.RequeueAfter)
+	return result
+}
+
+// reconcile does the work of Reconcile
+func (r *ReconcileGroupPermission) reconcile(request reconcile.Request) (reconcile.Result, error) {
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Reconciling GroupPermission")
 
@@ -84,11 +401,19 @@ func (r *ReconcileGroupPermission) Reconcile(request reconcile.Request) (reconci
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			r.namespaces.forget(request.NamespacedName)
+			r.namesChecked.Delete(request.NamespacedName)
+			r.statusBases.Delete(request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
+	r.recordStatus(instance)
+	// route the errors logged for the GroupPermission to the team owning it
+	if ownerRef := utility.OwnerRef(&instance.Spec); ownerRef != "" {
+		reqLogger = reqLogger.WithValues("OwnerRef", ownerRef)
+	}
 
 	// The GroupPermission CR is about to be deleted, so we need to clean up the
 	// Prometheus metrics, otherwise there will be stale data exported (for CRs
@@ -96,34 +421,392 @@ func (r *ReconcileGroupPermission) Reconcile(request reconcile.Request) (reconci
 	if instance.DeletionTimestamp != nil {
 		reqLogger.Info(fmt.Sprintf("Removing Prometheus metrics for GroupPermission name='%s'", instance.ObjectMeta.GetName()))
 		localmetrics.DeletePrometheusMetric(instance)
+		r.namespaces.forget(request.NamespacedName)
+		r.namesChecked.Delete(request.NamespacedName)
+
+		if hasFinalizer(instance) {
+			if retainsBindings(instance) {
+				reqLogger.Info("Retaining managed bindings")
+				r.eventf(instance, corev1.EventTypeWarning, "BindingsRetained", "Released the GroupPermission without removing its managed RBAC objects")
+			} else {
+				remaining, err := r.holdDeletion(instance)
+				if err != nil {
+					reqLogger.Error(err, "Failed to hold deletion")
+					return reconcile.Result{}, err
+				}
+				if remaining > 0 {
+					reqLogger.Info("Holding managed bindings for the deletion grace period", "Remaining", remaining.String())
+					return reconcile.Result{RequeueAfter: remaining}, nil
+				}
+				reqLogger.Info("Deleting managed bindings")
+				if err := r.deleteManagedBindings(instance); err != nil {
+					reqLogger.Error(err, "Failed to delete managed bindings")
+					return reconcile.Result{}, err
+				}
+			}
+			removeFinalizer(instance)
+			if err := r.client.Update(context.TODO(), instance); err != nil {
+				reqLogger.Error(err, "Failed to remove finalizer")
+				return reconcile.Result{}, err
+			}
+		}
 		return reconcile.Result{}, nil
 	}
 
+	// hold the GroupPermission on deletion until its managed bindings are deleted
+	if !hasFinalizer(instance) {
+		instance.Finalizers = append(instance.Finalizers, operatorconfig.GroupPermissionFinalizer)
+		if err := r.client.Update(context.TODO(), instance); err != nil {
+			reqLogger.Error(err, "Failed to add finalizer")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// restore the spec of a previous generation, the update reconciles the GroupPermission again
+	rolledBack, err := r.reconcileRollback(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to roll back", "Generation", instance.Spec.RollbackToGeneration)
+		return reconcile.Result{}, err
+	}
+	if rolledBack {
+		return reconcile.Result{}, nil
+	}
+
+	// a Permission granting no role, or two, an invalid or too complex regex, an invalid schedule, or a
+	// Group conflicting with the service accounts granted can never be applied, stop until the spec is fixed
+	err = validateSpec(instance)
+	if err != nil {
+		reqLogger.Info("Invalid GroupPermission", "Error", err.Error())
+		if !conditions.IsTrue(instance.Status.Conditions, managedv1alpha1.ReasonInvalidPermission) {
+			instance = updateCondition(instance, err.Error(), "", true, managedv1alpha1.GroupPermissionFailed, managedv1alpha1.ReasonInvalidPermission)
+			if err := r.updateStatus(instance); err != nil {
+				reqLogger.Error(err, "Failed to update condition.")
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{}, nil
+	}
+	if conditions.IsTrue(instance.Status.Conditions, managedv1alpha1.ReasonInvalidPermission) {
+		conditions.Deactivate(instance.Status.Conditions, managedv1alpha1.ReasonInvalidPermission)
+		if err := r.updateStatus(instance); err != nil {
+			reqLogger.Error(err, "Failed to update condition.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// wait for the GroupPermissions of the dependsOn to be Active, they are watched to resume
+	ready, err := r.reconcileDependencies(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile dependencies")
+		return reconcile.Result{}, err
+	}
+	if !ready {
+		reqLogger.Info("Waiting for dependencies", "DependsOn", instance.Spec.DependsOn)
+		return reconcile.Result{}, nil
+	}
+
+	// a GroupPermission with a groupNameSelector grants nothing itself, a GroupPermission is created
+	// for each selected Group instead
+	if instance.Spec.GroupNameSelector != nil {
+		groups, err := r.reconcileFanOut(instance)
+		if err != nil {
+			reqLogger.Error(err, "Failed to fan out to the selected Groups")
+			return reconcile.Result{}, err
+		}
+		if !reflect.DeepEqual(instance.Status.SelectedGroups, groups) {
+			instance.Status.SelectedGroups = groups
+			if err := r.updateStatus(instance); err != nil {
+				reqLogger.Error(err, "Failed to update selected groups status.")
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{}, nil
+	}
+
+	// outside of the windows of its schedule a GroupPermission grants nothing
+	scheduleActive, nextTransition, err := r.reconcileSchedule(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile schedule")
+		return reconcile.Result{}, err
+	}
+	if !scheduleActive {
+		reqLogger.Info("Outside of the schedule windows", "NextTransition", nextTransition)
+		return requeueBefore(reconcile.Result{}, nextTransition), nil
+	}
+
+	// on a cluster whose labels do not match its clusterConditions a GroupPermission grants nothing
+	clusterMatched, err := r.reconcileClusterConditions(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile cluster conditions")
+		return reconcile.Result{}, err
+	}
+	if !clusterMatched {
+		reqLogger.Info("Cluster does not match the cluster conditions")
+		return reconcile.Result{}, nil
+	}
+
+	// bootstrap the Group before its members are synced from the identity provider
+	if instance.Spec.CreateGroupIfMissing {
+		created, err := r.ensureGroup(instance)
+		if err != nil {
+			reqLogger.Error(err, "Failed to create Group", "Group", instance.Spec.GroupName)
+			return reconcile.Result{}, err
+		}
+		if created {
+			reqLogger.Info("Created Group", "Group", instance.Spec.GroupName)
+		}
+	}
+
 	// get list of clusterRole on k8s
+	// ClusterRoles are cluster scoped, listing them in the namespace of the request finds none
 	clusterRoleList := &v1.ClusterRoleList{}
-	opts := client.ListOptions{Namespace: request.Namespace}
-	err = r.client.List(context.TODO(), &opts, clusterRoleList)
+	err = r.client.List(context.TODO(), &client.ListOptions{}, clusterRoleList)
 	if err != nil {
 		reqLogger.Error(err, "Failed to get clusterRoleList")
 		return reconcile.Result{}, err
 	}
 
+	// rename the bindings left by a previous naming scheme, creating each before deleting the old one
+	renamed, err := r.renameLegacyBindings(instance, clusterRoleList)
+	if err != nil {
+		reqLogger.Error(err, "Failed to rename legacy bindings")
+		return reconcile.Result{}, err
+	}
+	if renamed {
+		err = r.updateStatus(instance)
+		if err != nil {
+			reqLogger.Error(err, "Failed to update binding migration.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// summarize the changes to the bindings once per generation of the spec, before applying them
+	planned, err := r.reconcilePlan(instance, clusterRoleList, time.Now())
+	if err != nil {
+		reqLogger.Error(err, "Failed to plan bindings")
+		return reconcile.Result{}, err
+	}
+	if planned {
+		err = r.updateStatus(instance)
+		if err != nil {
+			reqLogger.Error(err, "Failed to update plan.")
+			return reconcile.Result{}, err
+		}
+	}
+
 	// if crClusterRoleNameList returns list of clusterRoleNames
 	crClusterRoleNameList := populateCrClusterRoleNames(instance, clusterRoleList)
 	for _, crClusterRoleName := range crClusterRoleNameList {
 
 		// helper func to update the condition of the GroupPermission object
-		instance := updateCondition(instance, crClusterRoleName+" for clusterPermission does not exist", crClusterRoleName, true, "Failed")
-		err = r.client.Status().Update(context.TODO(), instance)
+		roleErr := &RoleNotFoundError{ClusterRoleName: crClusterRoleName}
+		instance := updateCondition(instance, roleErr.Error(), crClusterRoleName, true, managedv1alpha1.GroupPermissionFailed, errorReason(roleErr))
+		err = r.updateStatus(instance)
+		if err != nil {
+			reqLogger.Error(err, "Failed to update condition.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// remove the bindings left dangling by deleted ClusterRoles, they are created again with the ClusterRole
+	missingRoles := missingClusterRoles(instance, clusterRoleList)
+	deletedBindings, err := r.deleteDanglingBindings(instance, missingRoles)
+	if err != nil {
+		reqLogger.Error(err, "Failed to delete bindings of deleted ClusterRoles")
+		return reconcile.Result{}, err
+	}
+	for _, clusterRoleName := range sortedKeys(deletedBindings) {
+		reqLogger.Info("Deleted bindings of deleted ClusterRole", "ClusterRole", clusterRoleName, "Count", deletedBindings[clusterRoleName])
+		message := fmt.Sprintf("ClusterRole %s was deleted, removed %d bindings to it", clusterRoleName, deletedBindings[clusterRoleName])
+		instance := updateCondition(instance, message, clusterRoleName, true, managedv1alpha1.GroupPermissionFailed, managedv1alpha1.ReasonRoleDeleted)
+		err = r.updateStatus(instance)
 		if err != nil {
 			reqLogger.Error(err, "Failed to update condition.")
 			return reconcile.Result{}, err
 		}
 	}
 
+	operatorConfig, err := r.getOperatorConfig(context.TODO())
+	if err != nil {
+		reqLogger.Error(err, "Failed to get operator config")
+		return reconcile.Result{}, err
+	}
+
+	// the events of this reconcile follow the latest event verbosity
+	r.events.configure(operatorConfig)
+
+	// bindings denied by the policy of the cluster admins, or by their grant decision service, are not created
+	policy := newPolicyClient(r.httpClient, operatorConfig)
+	grants := newGrantDecider(r.httpClient, operatorConfig)
+
+	// the bindings left in namespaces protected since they were created are deleted or orphaned
+	err = r.releaseProtectedNamespaces(instance, operatorConfig)
+	if err != nil {
+		reqLogger.Error(err, "Failed to release protected namespaces")
+		return reconcile.Result{}, err
+	}
+
+	// the GroupPermissions of delegated namespaces only grant the roles delegable to them
+	undelegable := undelegableRoles(instance, clusterRoleList, operatorConfig)
+
+	// the ClusterRoleBindings of an atomic GroupPermission are checked before any of its bindings is created
+	var checkedBindings map[string]*v1.ClusterRoleBinding
+	if instance.Spec.Atomic {
+		var failedRole string
+		checkedBindings, failedRole, err = r.checkAtomicClusterPermissions(instance, clusterRoleList, undelegable, policy, grants, operatorConfig)
+		if err != nil {
+			reqLogger.Error(err, "Failed to check ClusterRoleBindings")
+			return reconcile.Result{}, err
+		}
+		if failedRole != "" {
+			return reconcile.Result{}, r.rollbackClusterPermission(instance, failedRole)
+		}
+	}
+
+	// ensure RoleBindings exist in every allowed namespace
+	namespaceStatuses, err := r.reconcileNamespacePermissions(instance, missingRoles, undelegable, policy, operatorConfig)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile namespace permissions")
+		return reconcile.Result{}, err
+	}
+
+	// the bindings of an atomic GroupPermission are all applied or none is
+	atomicFailed := false
+	if instance.Spec.Atomic {
+		if failure := failedBinding(namespaceStatuses); failure != "" {
+			rollBackStatuses(namespaceStatuses, failure)
+			err = r.rollbackAtomic(instance, failure)
+			if err != nil {
+				reqLogger.Error(err, "Failed to roll back the bindings")
+				return reconcile.Result{}, err
+			}
+			atomicFailed = true
+		}
+	}
+	namespacesConverged := isNamespaceStatusConverged(namespaceStatuses)
+
+	// create the read-only view Role in every matched namespace, unless the bindings were rolled back
+	if !atomicFailed {
+		err = r.reconcileViewRoles(instance, policy, operatorConfig)
+		if err != nil {
+			reqLogger.Error(err, "Failed to reconcile view roles")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// remove the Roles created in the namespaces no longer allowed, with their RoleBindings
+	deletedRoles, nextRemoval, err := r.deleteUnmatchedRoles(instance, operatorConfig, time.Now())
+	if err != nil {
+		reqLogger.Error(err, "Failed to delete unmatched Roles")
+		return reconcile.Result{}, err
+	}
+	if deletedRoles > 0 {
+		reqLogger.Info("Deleted Roles of unmatched namespaces", "Count", deletedRoles)
+	}
+
+	// remove the RoleBindings of the Permissions without rules in the namespaces no longer allowed
+	deletedRoleBindings, nextBindingRemoval, err := r.deleteUnmatchedRoleBindings(instance, operatorConfig, time.Now())
+	if err != nil {
+		reqLogger.Error(err, "Failed to delete unmatched RoleBindings")
+		return reconcile.Result{}, err
+	}
+	if deletedRoleBindings > 0 {
+		reqLogger.Info("Deleted RoleBindings of unmatched namespaces", "Count", deletedRoleBindings)
+	}
+
+	// some bindings applied while others failed are reported apart from a total failure
+	applied, failed, failedSample := bindingApplication(instance, namespaceStatuses, operatorConfig.StatusFailureSampleSize)
+	localmetrics.SetGroupPermissionApplication(instance, applicationState(applied, failed))
+	partialChanged := updatePartiallyApplied(instance, applied, failed, failedSample)
+
+	// the full list left out of a summarized status is kept in the inventory ConfigMaps
+	inventory := namespaceStatuses
+	namespaceStatuses, namespaceSummary := compactNamespaceStatuses(namespaceStatuses, operatorConfig.StatusNamespaceThreshold, operatorConfig.StatusFailureSampleSize)
+	if namespaceSummary == nil {
+		inventory = nil
+	}
+	inventoryConfigMaps, err := r.reconcileNamespaceInventory(instance, inventory)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile the namespace inventory")
+		return reconcile.Result{}, err
+	}
+	if namespaceSummary != nil {
+		namespaceSummary.InventoryConfigMaps = inventoryConfigMaps
+	}
+	if partialChanged || !isNamespaceStatusEqual(instance.Status.Namespaces, namespaceStatuses) || !reflect.DeepEqual(instance.Status.NamespaceSummary, namespaceSummary) {
+		instance.Status.Namespaces = namespaceStatuses
+		instance.Status.NamespaceSummary = namespaceSummary
+		err = r.updateStatus(instance)
+		if err != nil {
+			reqLogger.Error(err, "Failed to update namespace status.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// record the namespaces matched by each permission so users can verify their regexes
+	namespaceList, err := r.listNamespaces(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to get namespaceList")
+		return reconcile.Result{}, err
+	}
+	namespaceList = unprotectedNamespaces(namespaceList, operatorConfig)
+	allowed := r.namespaces.allowedNamespaces(instance, namespaceList)
+	matchedNamespaces := matchNamespaces(instance, allowed, operatorConfig.StatusMatchedSampleSize)
+	namespaceCount := matchedNamespaceCount(allowed)
+	// the counts are shown as columns by oc get
+	subjectCount, err := r.boundSubjectCount(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to count bound subjects")
+		return reconcile.Result{}, err
+	}
+	if !reflect.DeepEqual(instance.Status.MatchedNamespaces, matchedNamespaces) || instance.Status.MatchedNamespaceCount != namespaceCount || instance.Status.BoundSubjectCount != subjectCount {
+		instance.Status.MatchedNamespaces = matchedNamespaces
+		instance.Status.MatchedNamespaceCount = namespaceCount
+		instance.Status.BoundSubjectCount = subjectCount
+		err = r.updateStatus(instance)
+		if err != nil {
+			reqLogger.Error(err, "Failed to update matched namespaces status.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// report the RoleBindings skipped in namespaces being deleted apart from failures
+	err = r.reconcileTerminatingNamespaces(instance, namespaceList, operatorConfig)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile terminating namespaces")
+		return reconcile.Result{}, err
+	}
+
+	// explain why the Namespace named by the explain-namespace annotation is matched or not
+	namespaceExplanation, err := r.explainNamespace(instance, operatorConfig)
+	if err != nil {
+		reqLogger.Error(err, "Failed to explain namespace")
+		return reconcile.Result{}, err
+	}
+	if !reflect.DeepEqual(instance.Status.NamespaceExplanation, namespaceExplanation) {
+		instance.Status.NamespaceExplanation = namespaceExplanation
+		err = r.updateStatus(instance)
+		if err != nil {
+			reqLogger.Error(err, "Failed to update namespace explanation status.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// the ClusterRoleBindings of an atomic GroupPermission are not created once its RoleBindings failed
+	if atomicFailed {
+		return reconcile.Result{}, nil
+	}
+
+	// the bindings of the other RBAC operators are left to them
+	foreign, err := r.listForeignBindings(instance, operatorConfig)
+	if err != nil {
+		reqLogger.Error(err, "Failed to list foreign bindings")
+		return reconcile.Result{}, err
+	}
+
 	// get a list of clusterRoleBinding from k8s cluster list
 	clusterRoleBindingList := &v1.ClusterRoleBindingList{}
-	opts = client.ListOptions{Namespace: request.Namespace}
+	opts := client.ListOptions{Namespace: request.Namespace}
 	err = r.client.List(context.TODO(), &opts, clusterRoleBindingList)
 	if err != nil {
 		reqLogger.Error(err, "Failed to get clusterRoleBindingList")
@@ -143,49 +826,189 @@ func (r *ReconcileGroupPermission) Reconcile(request reconcile.Request) (reconci
 		// get the clusterRoleName by spliting the clusterRoleBindng name
 		clusterRBName := strings.Split(clusterRoleBindingName, "-")
 		clusterRoleName := clusterRBName[0]
-		groupName := clusterRBName[1]
+
+		// a binding to a missing ClusterRole would dangle, it is created once the ClusterRole is
+		clusterRole := findClusterRole(clusterRoleName, clusterRoleList)
+		if clusterRole == nil {
+			continue
+		}
+		// a ClusterRoleBinding of a foreign operator grants it instead, see reconcileForeignConflicts
+		desired := clusterPermissionBinding(&instance.Spec, clusterRoleName)
+		if _, ok := foreign.conflict("", desired.Name, desired.RoleRef); ok {
+			continue
+		}
+
+		// the ClusterRoleBindings of an atomic GroupPermission were checked before its RoleBindings were created
+		newCRB, checked := checkedBindings[clusterRoleName]
+		if !checked {
+			newCRB, err = r.checkClusterPermission(instance, clusterRole, undelegable, policy, grants, operatorConfig)
+			if err != nil {
+				reqLogger.Error(err, "Failed to check ClusterRoleBinding", "ClusterRole", clusterRoleName)
+				return reconcile.Result{}, err
+			}
+			if newCRB == nil {
+				if instance.Spec.Atomic {
+					return reconcile.Result{}, r.rollbackClusterPermission(instance, clusterRoleName)
+				}
+				continue
+			}
+		}
 
 		// create a new clusterRoleBinding on cluster
-		newCRB := newClusterRoleBinding(clusterRoleName, groupName)
-		err := r.client.Create(context.TODO(), newCRB)
+		unlock := bindinglock.Lock(bindinglock.ClusterRoleBinding, "", newCRB.Name)
+		err = typedError(r.client.Create(context.TODO(), newCRB))
+		unlock()
 		if err != nil {
 			// calls on helper function to update the condition of the groupPermission object
-			instance := updateCondition(instance, "Unable to create ClusterRoleBinding: "+err.Error(), clusterRoleName, true, managedv1alpha1.GroupPermissionFailed)
-			err = r.client.Status().Update(context.TODO(), instance)
-			if err != nil {
-				reqLogger.Error(err, "Failed to update condition.")
-				return reconcile.Result{}, err
+			instance := updateCondition(instance, "Unable to create ClusterRoleBinding: "+err.Error(), clusterRoleName, true, managedv1alpha1.GroupPermissionFailed, errorReason(err))
+			if statusErr := r.updateStatus(instance); statusErr != nil {
+				reqLogger.Error(statusErr, "Failed to update condition.")
+				return reconcile.Result{}, statusErr
 			}
 			reqLogger.Error(err, "Failed to create clusterRoleBinding")
+			if instance.Spec.Atomic {
+				if rollbackErr := r.rollbackClusterPermission(instance, clusterRoleName); rollbackErr != nil {
+					reqLogger.Error(rollbackErr, "Failed to roll back the bindings")
+				}
+			}
 			return reconcile.Result{}, err
 		}
 		// helper func to update condition of groupPermission object
-		instance := updateCondition(instance, "Successfully created ClusterRoleBinding", clusterRoleName, true, managedv1alpha1.GroupPermissionCreated)
-		err = r.client.Status().Update(context.TODO(), instance)
+		instance := updateCondition(instance, "Successfully created ClusterRoleBinding", clusterRoleName, true, managedv1alpha1.GroupPermissionCreated, managedv1alpha1.ReasonBindingCreated)
+		err = r.updateStatus(instance)
 		if err != nil {
 			reqLogger.Error(err, "Failed to update condition.")
 			return reconcile.Result{}, err
 		}
 		// Add Prometheus metrics for this CR
 		localmetrics.AddPrometheusMetric(instance)
+		// the ClusterRoleBindings of an atomic GroupPermission are created in a single reconcile
+		if instance.Spec.Atomic {
+			continue
+		}
 		return reconcile.Result{}, nil
 	}
 
-	return reconcile.Result{}, nil
+	// every binding is applied, a previous rollback is over
+	err = r.clearAtomicRollback(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to clear the rollback condition.")
+		return reconcile.Result{}, err
+	}
+
+	// all bindings exist, verify the access they grant actually resolves for the group
+	effectiveAccess, err := r.verifyEffectiveAccess(instance, clusterRoleList, request.Namespace)
+	if err != nil {
+		reqLogger.Error(err, "Failed to verify effective access")
+		return reconcile.Result{}, err
+	}
+	if !isEffectiveAccessEqual(instance.Status.EffectiveAccess, effectiveAccess) {
+		instance.Status.EffectiveAccess = effectiveAccess
+		err = r.updateStatus(instance)
+		if err != nil {
+			reqLogger.Error(err, "Failed to update effective access.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// report the bindings left to the other RBAC operators
+	err = r.reconcileForeignConflicts(instance, foreign, namespaceList, operatorConfig)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile foreign operator conflicts")
+		return reconcile.Result{}, err
+	}
+
+	// flag the access of the Group beyond the spec through bindings not managed by the operator, failing
+	// the reconcile before the desired state is recorded as applied in strict mode
+	err = r.reconcileUnmanagedAccess(instance, namespaceList, operatorConfig)
+	if err != nil {
+		reqLogger.Error(err, "Failed to reconcile unmanaged access")
+		return reconcile.Result{}, err
+	}
+
+	// record when the Group last used each bound role, to find the grants that can be removed
+	if usage := newUsageClient(r.httpClient, operatorConfig); usage != nil {
+		roles := boundRoles(instance)
+		lastUsed, err := usage.lastUsed(instance.Spec.GroupName, roles)
+		if err != nil {
+			// the usage is informational, an unavailable source does not fail the reconcile
+			reqLogger.Error(err, "Failed to read role usage")
+		} else if usages := roleUsage(instance.Status.RoleUsage, roles, lastUsed, metav1.Now()); !isRoleUsageEqual(instance.Status.RoleUsage, usages) {
+			instance.Status.RoleUsage = usages
+			err = r.updateStatus(instance)
+			if err != nil {
+				reqLogger.Error(err, "Failed to update role usage.")
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
+	// every binding exists, record the desired state the cluster converged to for GitOps tools
+	if namespacesConverged {
+		hash := desiredStateHash(instance, namespaceList)
+		if instance.Annotations[operatorconfig.LastAppliedHashAnnotation] != hash {
+			if instance.Annotations == nil {
+				instance.Annotations = map[string]string{}
+			}
+			instance.Annotations[operatorconfig.LastAppliedHashAnnotation] = hash
+			err = r.client.Update(context.TODO(), instance)
+			if err != nil {
+				reqLogger.Error(err, "Failed to update last applied hash.")
+				return reconcile.Result{}, err
+			}
+		}
+		if r.recordPlanApplied(instance) {
+			err = r.updateStatus(instance)
+			if err != nil {
+				reqLogger.Error(err, "Failed to update plan.")
+				return reconcile.Result{}, err
+			}
+		}
+
+		// the generation is reported applied only once its bindings are read back from the apiserver
+		applied, err := r.recordAppliedGeneration(instance, clusterRoleList, time.Now())
+		if err != nil {
+			reqLogger.Error(err, "Failed to verify the applied bindings.")
+			return reconcile.Result{}, err
+		}
+		if applied {
+			err = r.updateStatus(instance)
+			if err != nil {
+				reqLogger.Error(err, "Failed to update applied generation.")
+				return reconcile.Result{}, err
+			}
+		}
+
+		// the applied specs are recorded so a later generation can be rolled back to them
+		if instance.Status.AppliedGeneration == instance.Generation {
+			recorded, err := r.recordSpecHistory(instance)
+			if err != nil {
+				reqLogger.Error(err, "Failed to record the spec history.")
+				return reconcile.Result{}, err
+			}
+			if recorded {
+				err = r.updateStatus(instance)
+				if err != nil {
+					reqLogger.Error(err, "Failed to update spec history.")
+					return reconcile.Result{}, err
+				}
+			}
+		}
+	}
+
+	// resync periodically, so changes missed by the watches are eventually corrected, and remove the
+	// bindings right at the end of the schedule window and the Roles once their removal is due
+	result := reconcile.Result{RequeueAfter: operatorConfig.Jitter(operatorConfig.ResyncInterval)}
+	return requeueBefore(requeueBefore(requeueBefore(result, nextTransition), nextRemoval), nextBindingRemoval), nil
 }
 
-// newClusterRoleBinding creates and returns ClusterRoleBinding
-func newClusterRoleBinding(clusterRoleName, groupName string) *v1.ClusterRoleBinding {
+// newClusterRoleBinding creates and returns ClusterRoleBinding of clusterRoleName to subject
+func newClusterRoleBinding(clusterRoleName string, subject v1.Subject) *v1.ClusterRoleBinding {
 	return &v1.ClusterRoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: clusterRoleName + "-" + groupName,
-		},
-		Subjects: []v1.Subject{
-			{
-				Kind: "Group",
-				Name: groupName,
-			},
+			Name: clusterRoleName + "-" + subject.Name,
 		},
+		Subjects: []v1.Subject{subject},
 		RoleRef: v1.RoleRef{
 			Kind: "ClusterRole",
 			Name: clusterRoleName,
@@ -193,6 +1016,24 @@ func newClusterRoleBinding(clusterRoleName, groupName string) *v1.ClusterRoleBin
 	}
 }
 
+// clusterPermissionBinding returns the ClusterRoleBinding of clusterRoleName to the Group of spec. The API
+// group of its roleRef is left to the apiserver default unless spec sets one.
+func clusterPermissionBinding(spec *managedv1alpha1.GroupPermissionSpec, clusterRoleName string) *v1.ClusterRoleBinding {
+	binding := newClusterRoleBinding(clusterRoleName, utility.GroupSubject(spec))
+	binding.RoleRef.APIGroup = spec.RoleRefAPIGroup
+	return binding
+}
+
+// findClusterRole returns the ClusterRole named clusterRoleName from clusterRoleList, or nil if it does not exist
+func findClusterRole(clusterRoleName string, clusterRoleList *v1.ClusterRoleList) *v1.ClusterRole {
+	for i := range clusterRoleList.Items {
+		if clusterRoleList.Items[i].Name == clusterRoleName {
+			return &clusterRoleList.Items[i]
+		}
+	}
+	return nil
+}
+
 // populateCrClusterRoleNames to see if ClusterRoleName exists as a ClusterRole
 // returns list of ClusterRoleNames that do not exist
 func populateCrClusterRoleNames(groupPermission *managedv1alpha1.GroupPermission, clusterRoleList *v1.ClusterRoleList) []string {
@@ -202,15 +1043,17 @@ func populateCrClusterRoleNames(groupPermission *managedv1alpha1.GroupPermission
 	// items is list of clusterRole on k8s
 	onClusterItems := clusterRoleList.Items
 
+	onCluster := map[string]bool{}
+	for _, i := range onClusterItems {
+		onCluster[i.Name] = true
+	}
+
 	var crClusterRoleNameList []string
 
-	// for every cluster role names on cluster, loop through all crClusterRoleNames, if it doesn't exist then append
-	for _, i := range onClusterItems {
-		//name := i.Name
-		for _, a := range crClusterRoleNames {
-			if i.Name != a {
-				crClusterRoleNameList = append(crClusterRoleNameList, a)
-			}
+	// loop through all crClusterRoleNames, if it doesn't exist on cluster then append
+	for _, a := range crClusterRoleNames {
+		if !onCluster[a] {
+			crClusterRoleNameList = append(crClusterRoleNameList, a)
 		}
 	}
 
@@ -250,21 +1093,64 @@ func buildClusterRoleBindingCRList(clusterPermission *managedv1alpha1.GroupPermi
 	return clusterRoleBindingNameList
 }
 
-// update the condition of GroupPermission
-func updateCondition(groupPermission *managedv1alpha1.GroupPermission, message string, clusterRoleName string, status bool, state managedv1alpha1.GroupPermissionState) *managedv1alpha1.GroupPermission {
-	groupPermissionConditions := groupPermission.Status.Conditions
+// checkClusterPermission checks the ClusterRoleBinding of clusterRole for groupPermission: the requester
+// must be allowed to grant clusterRole, which must be delegable to the Namespace of groupPermission, and
+// the policy and the grant decision service must allow it. It returns the ClusterRoleBinding to create,
+// or nil with the condition of the check failing recorded in the status.
+func (r *ReconcileGroupPermission) checkClusterPermission(groupPermission *managedv1alpha1.GroupPermission, clusterRole *v1.ClusterRole, undelegable map[string]bool, policy *policyClient, grants *grantDecider, operatorConfig *operatorconfig.OperatorConfig) (*v1.ClusterRoleBinding, error) {
+	clusterRoleBinding := clusterPermissionBinding(&groupPermission.Spec, clusterRole.Name)
+	deny := func(message string, state managedv1alpha1.GroupPermissionState, reason managedv1alpha1.ConditionReason) (*v1.ClusterRoleBinding, error) {
+		return nil, r.updateStatus(updateCondition(groupPermission, message, clusterRole.Name, true, state, reason))
+	}
+
+	// the operator binds with its own rights, the requester must be allowed to grant the ClusterRole
+	allowed, err := r.isEscalationAllowed(groupPermission, clusterRoleBinding.RoleRef, clusterRole.Rules, "", operatorConfig)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return deny(escalationDeniedMessage(groupPermission, clusterRole.Name), managedv1alpha1.GroupPermissionEscalationDenied, managedv1alpha1.ReasonEscalationDenied)
+	}
+
+	// a GroupPermission of a delegated Namespace may only grant the roles delegable to it
+	if undelegable[clusterRoleBinding.RoleRef.Kind+" "+clusterRoleBinding.RoleRef.Name] {
+		return deny(notDelegableMessage(clusterRoleBinding.RoleRef, groupPermission.Namespace), managedv1alpha1.GroupPermissionFailed, managedv1alpha1.ReasonNotDelegable)
+	}
 
-	// make a new condition
-	newCondition := managedv1alpha1.Condition{
-		LastTransitionTime: metav1.Now(),
-		ClusterRoleName:    clusterRoleName,
-		Message:            message,
-		Status:             status,
-		State:              state,
+	// the policy may deny the binding
+	decision, err := policy.evaluate(newPolicyBinding(groupPermission, clusterRoleBinding.Subjects[0], clusterRoleBinding.RoleRef, ""))
+	if err != nil {
+		return nil, err
+	}
+	if !decision.Allowed {
+		return deny(policyDeniedMessage(decision), managedv1alpha1.GroupPermissionFailed, managedv1alpha1.ReasonPolicyDenied)
 	}
 
-	// append new condition back to the conditions array
-	groupPermission.Status.Conditions = append(groupPermissionConditions, newCondition)
+	// the grant decision service may deny the binding, or annotate it
+	grant, err := grants.decide(groupPermission, clusterRoleBinding.Subjects[0], clusterRoleBinding.RoleRef, "")
+	if err != nil {
+		return nil, err
+	}
+	if !grant.Allowed {
+		return deny(grantDeniedMessage(grant), managedv1alpha1.GroupPermissionFailed, managedv1alpha1.ReasonGrantDenied)
+	}
+
+	utility.SetManagedLabels(&clusterRoleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	utility.SetJustificationAnnotations(&clusterRoleBinding.ObjectMeta, &groupPermission.Spec)
+	utility.SetDocumentationAnnotations(&clusterRoleBinding.ObjectMeta, &groupPermission.Spec)
+	utility.SetSourceAnnotations(&clusterRoleBinding.ObjectMeta, groupPermission)
+	setGrantAnnotations(&clusterRoleBinding.ObjectMeta, grant)
+	return clusterRoleBinding, nil
+}
 
+// updateCondition records a condition of groupPermission
+func updateCondition(groupPermission *managedv1alpha1.GroupPermission, message string, clusterRoleName string, status bool, state managedv1alpha1.GroupPermissionState, reason managedv1alpha1.ConditionReason) *managedv1alpha1.GroupPermission {
+	conditions.Set(&groupPermission.Status.Conditions, managedv1alpha1.Condition{
+		ClusterRoleName: clusterRoleName,
+		Message:         message,
+		Status:          status,
+		State:           state,
+		Reason:          reason,
+	})
 	return groupPermission
 }
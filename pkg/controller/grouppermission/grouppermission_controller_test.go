@@ -5,14 +5,20 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
 	"github.com/openshift/rbac-permissions-operator/pkg/apis"
 	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // create fake client to mock API calls
@@ -63,17 +69,20 @@ func mockClusterRole() *rbacv1.ClusterRole {
 	}
 }
 
+func mockGroupSubject(groupName string) rbacv1.Subject {
+	return rbacv1.Subject{
+		Kind:     "Group",
+		APIGroup: "rbac.authorization.k8s.io",
+		Name:     groupName,
+	}
+}
+
 func mockClusterRoleBinding() *rbacv1.ClusterRoleBinding {
 	return &rbacv1.ClusterRoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "exampleClusterRoleName" + "-" + "exampleGroupName",
 		},
-		Subjects: []rbacv1.Subject{
-			{
-				Kind: "Group",
-				Name: "exampleGroupName",
-			},
-		},
+		Subjects: []rbacv1.Subject{mockGroupSubject("exampleGroupName")},
 		RoleRef: rbacv1.RoleRef{
 			Kind: "ClusterRole",
 			Name: "exampleClusterRoleName",
@@ -199,7 +208,7 @@ func TestCreateValidClusterRoleBinding(t *testing.T) {
 
 	// this is the function we are testing
 	// it should return mockClusterRoleBinding() which contains the same clusterRoleName and GroupName
-	newClusterRoleBinding := newClusterRoleBinding("exampleClusterRoleName", "exampleGroupName")
+	newClusterRoleBinding := newClusterRoleBinding("exampleClusterRoleName", mockGroupSubject("exampleGroupName"))
 
 	// compare the two clusterRoleBinding. They should be exactly the same
 	// if not our test fails, log out the difference
@@ -236,7 +245,7 @@ func TestValidClusterRoleBindingListCreation(t *testing.T) {
 // expected: an updated GroupPermission object with the correct updated fields
 func TestSuccesfulConditionUpdateForGroupPermission(t *testing.T) {
 	// this is the function we are testing with a mock
-	buildCondition := updateCondition(mockGroupPermission(), "testMessage", "testClusterRoleName", false, "testState")
+	buildCondition := updateCondition(mockGroupPermission(), "testMessage", "testClusterRoleName", false, "testState", v1alpha1.ReasonBindingCreated)
 
 	// make a map of the result that we want to check mock against
 	testMap := make(map[int]v1alpha1.Condition)
@@ -251,6 +260,7 @@ func TestSuccesfulConditionUpdateForGroupPermission(t *testing.T) {
 		Message:         "testMessage",
 		Status:          false,
 		State:           "testState",
+		Reason:          v1alpha1.ReasonBindingCreated,
 	}
 
 	testMap[0] = initConOne
@@ -284,5 +294,87 @@ func testCondition(con0 v1alpha1.Condition, con1 v1alpha1.Condition) bool {
 		fmt.Printf("Error, wanted: %s, received: %s\n", con0.State, con1.State)
 		return false
 	}
+	if con0.Reason != con1.Reason {
+		fmt.Printf("Error, wanted: %s, received: %s\n", con0.Reason, con1.Reason)
+		return false
+	}
 	return true
 }
+
+// TestReconcileElevationClusterPermissions tests the Reconcile function
+// given: a GroupPermission allowing Elevations with a ClusterPermission and an ElevationClusterPermission
+// expected: only the ClusterPermission is bound to the Group, the ElevationClusterPermission is left to Elevations
+func TestReconcileElevationClusterPermissions(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.Permissions = nil
+	groupPermission.Spec.ClusterPermissions = []string{"view"}
+	groupPermission.Spec.MaxElevationHours = 4
+	groupPermission.Spec.ElevationClusterPermissions = []string{"cluster-reader"}
+	fakeClient := newRequesterClient(groupPermission,
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "view"}}, &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "cluster-reader"}})
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: groupPermission.Namespace,
+			Name:      groupPermission.Name,
+		}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	clusterRoleBindingList := &rbacv1.ClusterRoleBindingList{}
+	if err := fakeClient.List(context.TODO(), &client.ListOptions{}, clusterRoleBindingList); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var bound []string
+	for _, binding := range clusterRoleBindingList.Items {
+		bound = append(bound, binding.RoleRef.Name)
+	}
+	if len(bound) != 1 || bound[0] != "view" {
+		t.Errorf("Mismatch for the ClusterRoles bound to the Group. Expected([view]), Found(%v)", bound)
+	}
+}
+
+// TestReconcileResync tests the Reconcile function
+// given: a converged GroupPermission and an operator config with a resync interval and jitter factor
+// expected: the GroupPermission is requeued after the resync interval, lengthened by up to the jitter factor
+func TestReconcileResync(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: operatorconfig.OperatorNamespace, Name: operatorconfig.OperatorConfigMapName},
+		Data:       map[string]string{"resync_interval_seconds": "60", "jitter_factor": "0.5"},
+	}
+	fakeClient := fake.NewFakeClient(groupPermission, configMap)
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	result, err := reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{
+		Namespace: groupPermission.Namespace,
+		Name:      groupPermission.Name,
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter < time.Minute || result.RequeueAfter > 90*time.Second {
+		t.Errorf("expected a requeue between 1m and 1m30s, got %s", result.RequeueAfter)
+	}
+}
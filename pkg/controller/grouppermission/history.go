@@ -0,0 +1,144 @@
+package grouppermission
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// specHistoryLimit is the number of applied specs recorded for each GroupPermission
+	specHistoryLimit = 10
+	// rolledBackReason is the reason of the event emitted when the spec of a previous generation is restored
+	rolledBackReason = "RolledBack"
+	// rollbackFailedReason is the reason of the event emitted when the generation to roll back to is not recorded
+	rollbackFailedReason = "RollbackFailed"
+)
+
+// historyConfigMapName returns the name of the ConfigMap recording the applied specs of groupPermission
+func historyConfigMapName(groupPermission *managedv1alpha1.GroupPermission) string {
+	return groupPermission.Name + "-history"
+}
+
+// recordSpecHistory records the spec of groupPermission, keyed by its generation, in its history
+// ConfigMap once the generation is applied, dropping the oldest specs past specHistoryLimit. It returns
+// whether the status changed.
+func (r *ReconcileGroupPermission) recordSpecHistory(groupPermission *managedv1alpha1.GroupPermission) (bool, error) {
+	generation := groupPermission.Generation
+	for _, recorded := range groupPermission.Status.SpecHistory {
+		if recorded == generation {
+			return false, nil
+		}
+	}
+
+	spec := groupPermission.Spec.DeepCopy()
+	spec.RollbackToGeneration = 0
+	content, err := json.Marshal(spec)
+	if err != nil {
+		return false, err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Namespace: groupPermission.Namespace, Name: historyConfigMapName(groupPermission)}, configMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	exists := err == nil
+	if !exists {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      historyConfigMapName(groupPermission),
+				Namespace: groupPermission.Namespace,
+				Labels:    utility.ParentLabels(operatorconfig.HistoryOfLabel, groupPermission.Name),
+			},
+		}
+		if err := controllerutil.SetControllerReference(groupPermission, configMap, r.scheme); err != nil {
+			return false, err
+		}
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[strconv.FormatInt(generation, 10)] = string(content)
+	generations := trimSpecHistory(configMap.Data, specHistoryLimit)
+
+	if exists {
+		err = r.client.Update(context.TODO(), configMap)
+	} else {
+		err = r.client.Create(context.TODO(), configMap)
+	}
+	if err != nil {
+		return false, err
+	}
+	groupPermission.Status.SpecHistory = generations
+	return true, nil
+}
+
+// trimSpecHistory deletes the oldest specs of data, keyed by generation, past limit and returns the
+// generations left, oldest first. Keys that are not generations are deleted.
+func trimSpecHistory(data map[string]string, limit int) []int64 {
+	var generations []int64
+	for key := range data {
+		generation, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			delete(data, key)
+			continue
+		}
+		generations = append(generations, generation)
+	}
+	sort.Slice(generations, func(i, j int) bool { return generations[i] < generations[j] })
+	for len(generations) > limit {
+		delete(data, strconv.FormatInt(generations[0], 10))
+		generations = generations[1:]
+	}
+	return generations
+}
+
+// reconcileRollback restores the spec recorded for the generation named by spec.rollbackToGeneration
+// of groupPermission, which clears it, and emits a RolledBack event. A generation that is not recorded
+// is only cleared, with a RollbackFailed event. It returns whether groupPermission was updated, its
+// next generation is then reconciled instead.
+func (r *ReconcileGroupPermission) reconcileRollback(groupPermission *managedv1alpha1.GroupPermission) (bool, error) {
+	target := groupPermission.Spec.RollbackToGeneration
+	if target == 0 {
+		return false, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: groupPermission.Namespace, Name: historyConfigMapName(groupPermission)}, configMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	content, recorded := configMap.Data[strconv.FormatInt(target, 10)]
+	if !recorded {
+		groupPermission.Spec.RollbackToGeneration = 0
+		if err := r.client.Update(context.TODO(), groupPermission); err != nil {
+			return false, err
+		}
+		r.eventf(groupPermission, corev1.EventTypeWarning, rollbackFailedReason,
+			"Cannot roll back to generation %d, its spec is not recorded, recorded generations are %v", target, groupPermission.Status.SpecHistory)
+		return true, nil
+	}
+
+	spec := &managedv1alpha1.GroupPermissionSpec{}
+	if err := json.Unmarshal([]byte(content), spec); err != nil {
+		return false, err
+	}
+	groupPermission.Spec = *spec
+	if err := r.client.Update(context.TODO(), groupPermission); err != nil {
+		return false, err
+	}
+	r.eventf(groupPermission, corev1.EventTypeNormal, rolledBackReason, "Restored the spec of generation %d", target)
+	return true, nil
+}
@@ -0,0 +1,104 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestRecordSpecHistory tests the recordSpecHistory function
+// given: a GroupPermission applied at more generations than the history limit, one of them twice
+// expected: the latest generations only are recorded, in order, each once
+func TestRecordSpecHistory(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockGroupPermission()
+	reconciler := &ReconcileGroupPermission{client: fake.NewFakeClient(groupPermission.DeepCopy()), scheme: scheme.Scheme}
+	for generation := int64(1); generation <= specHistoryLimit+2; generation++ {
+		groupPermission.Generation = generation
+		groupPermission.Spec.RollbackToGeneration = generation - 1
+		if recorded, err := reconciler.recordSpecHistory(groupPermission); err != nil || !recorded {
+			t.Fatalf("expected generation %d to be recorded, got %t, %v", generation, recorded, err)
+		}
+	}
+	if recorded, _ := reconciler.recordSpecHistory(groupPermission); recorded {
+		t.Errorf("expected generation %d to be recorded once", groupPermission.Generation)
+	}
+
+	expected := []int64{3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	if !reflect.DeepEqual(groupPermission.Status.SpecHistory, expected) {
+		t.Errorf("Mismatch for spec history. Expected(%v), Found(%v)", expected, groupPermission.Status.SpecHistory)
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: groupPermission.Namespace, Name: historyConfigMapName(groupPermission)}, configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(configMap.Data) != specHistoryLimit || configMap.Data["2"] != "" {
+		t.Errorf("Mismatch for recorded generations. Expected(%v), Found(%d keys)", expected, len(configMap.Data))
+	}
+	if strings.Contains(configMap.Data["12"], "rollbackToGeneration") {
+		t.Errorf("expected the recorded spec without rollbackToGeneration, got %s", configMap.Data["12"])
+	}
+}
+
+// TestReconcileRollback tests the reconcileRollback function
+// given: a GroupPermission rolling back to a recorded generation, then to a generation not recorded
+// expected: the spec of the recorded generation is restored with a RolledBack event, the generation
+// not recorded is cleared with a RollbackFailed event
+func TestReconcileRollback(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockGroupPermission()
+	groupPermission.Generation = 1
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ReconcileGroupPermission{client: fake.NewFakeClient(groupPermission.DeepCopy()), scheme: scheme.Scheme, recorder: recorder}
+	if _, err := reconciler.recordSpecHistory(groupPermission); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	changed := &v1alpha1.GroupPermission{}
+	if err := reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}, changed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changed.Spec.ClusterPermissions = []string{"cluster-admin"}
+	changed.Spec.RollbackToGeneration = 1
+	rolledBack, err := reconciler.reconcileRollback(changed)
+	if err != nil || !rolledBack {
+		t.Fatalf("expected a rollback, got %t, %v", rolledBack, err)
+	}
+	restored := &v1alpha1.GroupPermission{}
+	if err := reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}, restored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(restored.Spec, groupPermission.Spec) {
+		t.Errorf("Mismatch for restored spec. Expected(%+v), Found(%+v)", groupPermission.Spec, restored.Spec)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, rolledBackReason) {
+		t.Errorf("Mismatch for event. Expected(%s), Found(%s)", rolledBackReason, event)
+	}
+
+	restored.Spec.RollbackToGeneration = 5
+	if rolledBack, err := reconciler.reconcileRollback(restored); err != nil || !rolledBack {
+		t.Fatalf("expected the rollback to be cleared, got %t, %v", rolledBack, err)
+	}
+	if restored.Spec.RollbackToGeneration != 0 {
+		t.Errorf("Mismatch for rollbackToGeneration. Expected(0), Found(%d)", restored.Spec.RollbackToGeneration)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, rollbackFailedReason) {
+		t.Errorf("Mismatch for event. Expected(%s), Found(%s)", rollbackFailedReason, event)
+	}
+}
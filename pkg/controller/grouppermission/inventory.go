@@ -0,0 +1,132 @@
+package grouppermission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=create;update;delete,namespace=openshift-rbac-permissions-operator
+
+const (
+	// inventoryKey is the key of the ConfigMaps of an inventory holding their chunk of the list
+	inventoryKey = "namespaces.json"
+	// inventoryChunkBytes caps the size of the chunk of a ConfigMap, well below the size limit of objects
+	inventoryChunkBytes = 512 * 1024
+)
+
+// reconcileNamespaceInventory stores namespaceStatuses, the full list of RoleBindings managed for
+// groupPermission, in chunks held by ConfigMaps owned by groupPermission, so the list left out of its
+// status when summarized is preserved. The ConfigMaps of chunks no longer needed are deleted, all of
+// them when namespaceStatuses is empty. Returns the names of the ConfigMaps, in the order of the chunks.
+func (r *ReconcileGroupPermission) reconcileNamespaceInventory(groupPermission *managedv1alpha1.GroupPermission, namespaceStatuses []managedv1alpha1.NamespaceStatus) ([]string, error) {
+	chunks, err := chunkNamespaceStatuses(namespaceStatuses, inventoryChunkBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	configMapList := &corev1.ConfigMapList{}
+	selector := utility.ParentSelector(operatorconfig.InventoryOfLabel, groupPermission.Name)
+	if err := r.client.List(context.TODO(), &client.ListOptions{Namespace: groupPermission.Namespace, LabelSelector: selector}, configMapList); err != nil {
+		return nil, err
+	}
+	existing := map[string]*corev1.ConfigMap{}
+	for i := range configMapList.Items {
+		configMap := &configMapList.Items[i]
+		if selector.Matches(labels.Set(configMap.Labels)) {
+			existing[configMap.Name] = configMap
+		}
+	}
+
+	var names []string
+	for i, chunk := range chunks {
+		name := fmt.Sprintf("%s-inventory-%d", groupPermission.Name, i)
+		names = append(names, name)
+		data := map[string]string{inventoryKey: chunk}
+		if configMap, ok := existing[name]; ok {
+			delete(existing, name)
+			if reflect.DeepEqual(configMap.Data, data) {
+				continue
+			}
+			configMap.Data = data
+			if err := r.client.Update(context.TODO(), configMap); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: groupPermission.Namespace,
+				Labels:    utility.ParentLabels(operatorconfig.InventoryOfLabel, groupPermission.Name),
+			},
+			Data: data,
+		}
+		if err := controllerutil.SetControllerReference(groupPermission, configMap, r.scheme); err != nil {
+			return nil, err
+		}
+		if err := r.client.Create(context.TODO(), configMap); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, configMap := range existing {
+		if err := r.client.Delete(context.TODO(), configMap); err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+// chunkNamespaceStatuses returns namespaceStatuses as JSON lists of at most maxBytes, but for a single
+// larger NamespaceStatus, in order. Returns nil when namespaceStatuses is empty.
+func chunkNamespaceStatuses(namespaceStatuses []managedv1alpha1.NamespaceStatus, maxBytes int) ([]string, error) {
+	var chunks []string
+	var chunk []json.RawMessage
+	size := 0
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		encoded, err := json.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, string(encoded))
+		chunk = nil
+		size = 0
+		return nil
+	}
+
+	for _, namespaceStatus := range namespaceStatuses {
+		encoded, err := json.Marshal(namespaceStatus)
+		if err != nil {
+			return nil, err
+		}
+		// the brackets of the list and a comma before each NamespaceStatus
+		if len(chunk) > 0 && size+len(encoded)+2 > maxBytes {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+		chunk = append(chunk, encoded)
+		size += len(encoded) + 1
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
@@ -0,0 +1,126 @@
+package grouppermission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// mockNamespaceStatuses returns count NamespaceStatuses of created RoleBindings
+func mockNamespaceStatuses(count int) []v1alpha1.NamespaceStatus {
+	var namespaceStatuses []v1alpha1.NamespaceStatus
+	for i := 0; i < count; i++ {
+		namespaceStatuses = append(namespaceStatuses, v1alpha1.NamespaceStatus{
+			Namespace:       fmt.Sprintf("team-%d", i),
+			ClusterRoleName: "view",
+			BindingName:     "view-exampleGroupName",
+			State:           v1alpha1.GroupPermissionCreated,
+		})
+	}
+	return namespaceStatuses
+}
+
+// TestChunkNamespaceStatuses tests the chunkNamespaceStatuses function
+// given: NamespaceStatuses larger than the size of a chunk
+// expected: chunks within the size, decoding in order to the NamespaceStatuses
+func TestChunkNamespaceStatuses(t *testing.T) {
+	namespaceStatuses := mockNamespaceStatuses(10)
+	encoded, _ := json.Marshal(namespaceStatuses[0])
+	maxBytes := 3*(len(encoded)+1) + 1
+
+	chunks, err := chunkNamespaceStatuses(namespaceStatuses, maxBytes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chunks) != 4 {
+		t.Errorf("Mismatch for chunks. Expected(4), Found(%d)", len(chunks))
+	}
+	var decoded []v1alpha1.NamespaceStatus
+	for _, chunk := range chunks {
+		if len(chunk) > maxBytes {
+			t.Errorf("Mismatch for the size of a chunk. Expected(at most %d), Found(%d)", maxBytes, len(chunk))
+		}
+		var namespaceStatuses []v1alpha1.NamespaceStatus
+		if err := json.Unmarshal([]byte(chunk), &namespaceStatuses); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		decoded = append(decoded, namespaceStatuses...)
+	}
+	if !reflect.DeepEqual(decoded, namespaceStatuses) {
+		t.Errorf("Mismatch for decoded. Expected(%v), Found(%v)", namespaceStatuses, decoded)
+	}
+
+	if chunks, err := chunkNamespaceStatuses(nil, maxBytes); err != nil || chunks != nil {
+		t.Errorf("Mismatch for no NamespaceStatus. Expected(nil), Found(%v, %v)", chunks, err)
+	}
+}
+
+// TestReconcileNamespaceInventory tests the reconcileNamespaceInventory function
+// given: a GroupPermission with the ConfigMap of a stale chunk, then with no NamespaceStatus
+// expected: a ConfigMap owned by the GroupPermission holding the list and the stale one deleted, then none
+func TestReconcileNamespaceInventory(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockGroupPermission()
+	groupPermission.UID = "owner-uid"
+	stale := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: groupPermission.Namespace,
+		Name:      "testGroupPermission-inventory-1",
+		Labels:    map[string]string{operatorconfig.InventoryOfLabel: groupPermission.Name},
+	}}
+	fakeClient := fake.NewFakeClient(groupPermission, stale)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+
+	names, err := reconciler.reconcileNamespaceInventory(groupPermission, mockNamespaceStatuses(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"testGroupPermission-inventory-0"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Mismatch for names. Expected(%v), Found(%v)", expected, names)
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: groupPermission.Namespace, Name: names[0]}, configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var namespaceStatuses []v1alpha1.NamespaceStatus
+	if err := json.Unmarshal([]byte(configMap.Data[inventoryKey]), &namespaceStatuses); err != nil || len(namespaceStatuses) != 2 {
+		t.Errorf("Mismatch for the inventory. Expected(2 NamespaceStatuses), Found(%v, %v)", namespaceStatuses, err)
+	}
+	if owner := metav1.GetControllerOf(configMap); owner == nil || owner.UID != groupPermission.UID {
+		t.Errorf("Mismatch for owner. Expected(%s), Found(%v)", groupPermission.UID, owner)
+	}
+
+	configMapList := &corev1.ConfigMapList{}
+	if err := fakeClient.List(context.TODO(), &client.ListOptions{Namespace: groupPermission.Namespace}, configMapList); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(configMapList.Items) != 1 {
+		t.Errorf("Mismatch for ConfigMaps. Expected(1), Found(%d)", len(configMapList.Items))
+	}
+
+	names, err = reconciler.reconcileNamespaceInventory(groupPermission, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fakeClient.List(context.TODO(), &client.ListOptions{Namespace: groupPermission.Namespace}, configMapList); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names != nil || len(configMapList.Items) != 0 {
+		t.Errorf("Mismatch for an empty inventory. Expected(no ConfigMap), Found(%v, %d)", names, len(configMapList.Items))
+	}
+}
@@ -0,0 +1,79 @@
+package grouppermission
+
+import (
+	"context"
+	"sort"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxNamedNamespaces is the largest number of Namespaces named by the regexes of a GroupPermission
+// that are got one by one, more are listed
+const maxNamedNamespaces = 50
+
+// namedNamespaces returns the names of the only Namespaces the Permissions of groupPermission can be
+// allowed in, when every allowed regex names its Namespaces, such as "^(team-a|team-b)$". It returns
+// false when a Permission may be allowed in other Namespaces, and they have to be listed.
+func namedNamespaces(groupPermission *managedv1alpha1.GroupPermission) ([]string, bool) {
+	unique := map[string]bool{}
+	for _, permission := range groupPermission.Spec.Permissions {
+		// every Namespace not denied is allowed over deny
+		if permission.ConflictPolicy == managedv1alpha1.ConflictAllowOverDeny {
+			return nil, false
+		}
+		// no Namespace is allowed without an allowed regex
+		if permission.NamespacesAllowedRegex == "" {
+			continue
+		}
+		names, ok := utility.LiteralNamespaces(permission.NamespacesAllowedRegex, maxNamedNamespaces)
+		if !ok {
+			return nil, false
+		}
+		for _, name := range names {
+			unique[name] = true
+		}
+	}
+	if len(unique) > maxNamedNamespaces {
+		return nil, false
+	}
+
+	names := make([]string, 0, len(unique))
+	for name := range unique {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, true
+}
+
+// listNamespaces returns the Namespaces the Permissions of groupPermission can be allowed in. When the
+// allowed regexes name their Namespaces they are got by name, those that do not exist are left out, instead
+// of listing every Namespace of the cluster. The denied regexes and excluded requesters still apply.
+func (r *ReconcileGroupPermission) listNamespaces(groupPermission *managedv1alpha1.GroupPermission) (*corev1.NamespaceList, error) {
+	namespaceList := &corev1.NamespaceList{}
+	names, ok := namedNamespaces(groupPermission)
+	if !ok {
+		if err := r.client.List(context.TODO(), &client.ListOptions{}, namespaceList); err != nil {
+			return nil, err
+		}
+		return namespaceList, nil
+	}
+
+	for _, name := range names {
+		namespace := &corev1.Namespace{}
+		err := r.client.Get(context.TODO(), types.NamespacedName{Name: name}, namespace)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		namespaceList.Items = append(namespaceList.Items, *namespace)
+	}
+	return namespaceList, nil
+}
@@ -0,0 +1,81 @@
+package grouppermission
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestListNamespaces tests the listNamespaces function
+// given: a GroupPermission whose allowed regexes name their Namespaces, one of which does not exist,
+// then a Permission whose allowed regex matches a pattern
+// expected: only the named Namespaces that exist are returned, then every Namespace is listed
+func TestListNamespaces(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockGroupPermission()
+	groupPermission.Spec.Permissions = []v1alpha1.Permission{
+		{ClusterRoleName: "admin", NamespacesAllowedRegex: "^(team-a|team-b)$"},
+		{ClusterRoleName: "view", NamespacesAllowedRegex: "^team-a$", NamespacesDeniedRegex: "^team-.*"},
+		{ClusterRoleName: "edit"},
+	}
+	reconciler := &ReconcileGroupPermission{
+		client: fake.NewFakeClient(mockNamespace("team-a"), mockNamespace("team-c"), mockNamespace("default")),
+		scheme: scheme.Scheme,
+	}
+
+	namespaceList, err := reconciler.listNamespaces(groupPermission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if names := namespaceNames(namespaceList); !reflect.DeepEqual(names, []string{"team-a"}) {
+		t.Errorf("Mismatch for named Namespaces. Expected([team-a]), Found(%v)", names)
+	}
+
+	groupPermission.Spec.Permissions = append(groupPermission.Spec.Permissions, v1alpha1.Permission{ClusterRoleName: "edit", NamespacesAllowedRegex: "^team-.*"})
+	namespaceList, err = reconciler.listNamespaces(groupPermission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaceList.Items) != 3 {
+		t.Errorf("Mismatch for listed Namespaces. Expected(3), Found(%d)", len(namespaceList.Items))
+	}
+}
+
+// TestNamedNamespaces tests the namedNamespaces function
+// given: GroupPermissions allowed over deny, and naming more Namespaces than maxNamedNamespaces
+// expected: their Namespaces are not named and have to be listed
+func TestNamedNamespaces(t *testing.T) {
+	groupPermission := mockGroupPermission()
+	groupPermission.Spec.Permissions = []v1alpha1.Permission{
+		{ClusterRoleName: "admin", NamespacesAllowedRegex: "^team-a$", NamespacesDeniedRegex: "^team-.*", ConflictPolicy: v1alpha1.ConflictAllowOverDeny},
+	}
+	if names, ok := namedNamespaces(groupPermission); ok {
+		t.Errorf("expected the Namespaces allowed over deny to be listed, got %v", names)
+	}
+
+	groupPermission.Spec.Permissions = []v1alpha1.Permission{
+		{ClusterRoleName: "admin", NamespacesAllowedRegex: "^team-[a-z]$"},
+		{ClusterRoleName: "view", NamespacesAllowedRegex: "^dev-[a-z]$"},
+	}
+	if names, ok := namedNamespaces(groupPermission); ok {
+		t.Errorf("expected more than %d Namespaces to be listed, got %v", maxNamedNamespaces, names)
+	}
+}
+
+// namespaceNames returns the names of the Namespaces of namespaceList
+func namespaceNames(namespaceList *corev1.NamespaceList) []string {
+	var names []string
+	for _, namespace := range namespaceList.Items {
+		names = append(names, namespace.Name)
+	}
+	return names
+}
@@ -0,0 +1,176 @@
+package grouppermission
+
+import (
+	"reflect"
+	"sync"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// namespaceIndex holds the Namespaces allowed by every Permission of each GroupPermission, so a
+// reconcile only evaluates the regexes of the Namespaces created since the previous one instead of
+// the full cross product of Permissions and Namespaces
+type namespaceIndex struct {
+	mutex   sync.Mutex
+	entries map[types.NamespacedName]*namespaceIndexEntry
+}
+
+// namespaceIndexEntry is the indexed state of a GroupPermission
+type namespaceIndexEntry struct {
+	// permissions the entry was computed for, it is dropped when they change
+	permissions []managedv1alpha1.Permission
+	// allowed holds the names of the Namespaces allowed by each Permission
+	allowed []map[string]bool
+	// known holds the names of every Namespace evaluated
+	known map[string]bool
+}
+
+// newNamespaceIndex returns an empty namespaceIndex
+func newNamespaceIndex() *namespaceIndex {
+	return &namespaceIndex{entries: map[types.NamespacedName]*namespaceIndexEntry{}}
+}
+
+// allowedNamespaces returns, for every Permission of groupPermission, the names of the Namespaces of
+// namespaceList it is allowed in. Only Namespaces the index does not know yet are evaluated, those
+// missing from namespaceList are dropped. A nil index evaluates every Namespace.
+func (idx *namespaceIndex) allowedNamespaces(groupPermission *managedv1alpha1.GroupPermission, namespaceList *corev1.NamespaceList) []map[string]bool {
+	if idx == nil {
+		return evaluateNamespaces(groupPermission.Spec.Permissions, namespaceList)
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	entry, ok := idx.entries[key]
+	if !ok || !reflect.DeepEqual(entry.permissions, groupPermission.Spec.Permissions) {
+		entry = &namespaceIndexEntry{
+			permissions: groupPermission.DeepCopy().Spec.Permissions,
+			allowed:     make([]map[string]bool, len(groupPermission.Spec.Permissions)),
+			known:       map[string]bool{},
+		}
+		for i := range entry.allowed {
+			entry.allowed[i] = map[string]bool{}
+		}
+		idx.entries[key] = entry
+	}
+
+	listed := make(map[string]bool, len(namespaceList.Items))
+	for i := range namespaceList.Items {
+		namespace := &namespaceList.Items[i]
+		listed[namespace.Name] = true
+		if !entry.known[namespace.Name] {
+			entry.evaluate(namespace)
+		}
+	}
+	for name := range entry.known {
+		if !listed[name] {
+			entry.forget(name)
+		}
+	}
+
+	return entry.copyAllowed()
+}
+
+// update evaluates namespace again for every indexed GroupPermission, or forgets it when deleted
+func (idx *namespaceIndex) update(namespace metav1.Object, deleted bool) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	for _, entry := range idx.entries {
+		if deleted {
+			entry.forget(namespace.GetName())
+		} else if entry.known[namespace.GetName()] {
+			entry.evaluate(namespace)
+		}
+	}
+}
+
+// forget drops the entry of the GroupPermission key
+func (idx *namespaceIndex) forget(key types.NamespacedName) {
+	if idx == nil {
+		return
+	}
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	delete(idx.entries, key)
+}
+
+// evaluate records the Permissions of the entry allowed in namespace
+func (e *namespaceIndexEntry) evaluate(namespace metav1.Object) {
+	name := namespace.GetName()
+	e.known[name] = true
+	for i, permission := range e.permissions {
+		if isPermissionAllowed(permission, namespace) {
+			e.allowed[i][name] = true
+		} else {
+			delete(e.allowed[i], name)
+		}
+	}
+}
+
+// forget drops the Namespace name from the entry
+func (e *namespaceIndexEntry) forget(name string) {
+	delete(e.known, name)
+	for i := range e.allowed {
+		delete(e.allowed[i], name)
+	}
+}
+
+// copyAllowed returns a copy of the allowed Namespaces of the entry, safe to read without the lock
+func (e *namespaceIndexEntry) copyAllowed() []map[string]bool {
+	allowed := make([]map[string]bool, len(e.allowed))
+	for i, names := range e.allowed {
+		allowed[i] = make(map[string]bool, len(names))
+		for name := range names {
+			allowed[i][name] = true
+		}
+	}
+	return allowed
+}
+
+// evaluateNamespaces returns, for every one of permissions, the names of the Namespaces of
+// namespaceList it is allowed in
+func evaluateNamespaces(permissions []managedv1alpha1.Permission, namespaceList *corev1.NamespaceList) []map[string]bool {
+	allowed := make([]map[string]bool, len(permissions))
+	for i, permission := range permissions {
+		allowed[i] = map[string]bool{}
+		for j := range namespaceList.Items {
+			if isPermissionAllowed(permission, &namespaceList.Items[j]) {
+				allowed[i][namespaceList.Items[j].Name] = true
+			}
+		}
+	}
+	return allowed
+}
+
+// namespaceIndexUpdater keeps a namespaceIndex up to date with Namespace events, it enqueues nothing
+type namespaceIndexUpdater struct {
+	index *namespaceIndex
+}
+
+// blank assignment to verify that namespaceIndexUpdater implements handler.EventHandler
+var _ handler.EventHandler = &namespaceIndexUpdater{}
+
+// Create implements handler.EventHandler, a new Namespace is evaluated by the next reconcile
+func (u *namespaceIndexUpdater) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {}
+
+// Update implements handler.EventHandler
+func (u *namespaceIndexUpdater) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	u.index.update(evt.MetaNew, false)
+}
+
+// Delete implements handler.EventHandler
+func (u *namespaceIndexUpdater) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	u.index.update(evt.Meta, true)
+}
+
+// Generic implements handler.EventHandler
+func (u *namespaceIndexUpdater) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {}
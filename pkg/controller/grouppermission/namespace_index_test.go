@@ -0,0 +1,61 @@
+package grouppermission
+
+import (
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestNamespaceIndex tests the namespaceIndex
+// given: a GroupPermission indexed against created, changed and deleted Namespaces, and a changed spec
+// expected: the allowed Namespaces match evaluating every Namespace from scratch
+func TestNamespaceIndex(t *testing.T) {
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.Permissions[0].ExcludedNamespaceRequesters = []string{"bot"}
+	namespaceList := &corev1.NamespaceList{
+		Items: []corev1.Namespace{*mockNamespace("team-a"), *mockNamespace("team-secret"), *mockNamespace("default")},
+	}
+	idx := newNamespaceIndex()
+
+	check := func(step string) {
+		t.Helper()
+		got := idx.allowedNamespaces(groupPermission, namespaceList)
+		want := evaluateNamespaces(groupPermission.Spec.Permissions, namespaceList)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", step, got, want)
+		}
+	}
+
+	check("initial")
+
+	namespaceList.Items = append(namespaceList.Items, *mockNamespace("team-b"))
+	check("created")
+
+	namespaceList.Items[0].Annotations = map[string]string{operatorconfig.NamespaceRequesterAnnotation: "bot"}
+	idx.update(&namespaceList.Items[0], false)
+	check("changed")
+
+	deleted := namespaceList.Items[3]
+	namespaceList.Items = namespaceList.Items[:3]
+	check("deleted from the list")
+	idx.update(&deleted, true)
+	check("deleted")
+
+	groupPermission.Spec.Permissions[0].NamespacesAllowedRegex = "^default$"
+	check("spec changed")
+
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	idx.forget(key)
+	if _, ok := idx.entries[key]; ok {
+		t.Errorf("expected the entry to be forgotten")
+	}
+
+	var nilIndex *namespaceIndex
+	if got := nilIndex.allowedNamespaces(groupPermission, namespaceList); !reflect.DeepEqual(got, evaluateNamespaces(groupPermission.Spec.Permissions, namespaceList)) {
+		t.Errorf("nil index: got %v", got)
+	}
+}
@@ -0,0 +1,452 @@
+package grouppermission
+
+import (
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileNamespacePermissions ensures a RoleBinding exists for every Permission of groupPermission in
+// every allowed Namespace not protected by operatorConfig nor being deleted, along the Role of the
+// Permissions with rules, and returns the state of each. A RoleBinding failing one of the checks below,
+// such as the escalation check of the requester, is not created. The managed RoleBindings no longer
+// desired are deleted, see deleteUndesiredRoleBindings. The rollout is checkpointed in the status, and
+// an atomic groupPermission is applied once every RoleBinding is checked, see applyAtomic.
+func (r *ReconcileGroupPermission) reconcileNamespacePermissions(groupPermission *managedv1alpha1.GroupPermission, missingRoles map[string]bool, undelegable map[string]bool, policy *policyClient, operatorConfig *operatorconfig.OperatorConfig) ([]managedv1alpha1.NamespaceStatus, error) {
+	if len(groupPermission.Spec.Permissions) == 0 {
+		// the RoleBindings of the Permissions removed from the spec are deleted
+		return nil, r.deleteUndesiredRoleBindings(groupPermission, nil, nil)
+	}
+
+	namespaceList, err := r.listNamespaces(groupPermission)
+	if err != nil {
+		return nil, err
+	}
+	namespaceList = unprotectedNamespaces(namespaceList, operatorConfig)
+	// the rollout goes through the Namespaces in name order, so a checkpoint is a position
+	sort.Slice(namespaceList.Items, func(i, j int) bool { return namespaceList.Items[i].Name < namespaceList.Items[j].Name })
+	progress := newRollout(groupPermission, namespaceList)
+	if progress.resumed != nil {
+		log.Info("Resuming the rollout of the RoleBindings", "Request.Namespace", groupPermission.Namespace, "Request.Name", groupPermission.Name,
+			"Applied", progress.resumed.Applied)
+	}
+
+	allowed := r.namespaces.allowedNamespaces(groupPermission, namespaceList)
+	grants := newGrantDecider(r.httpClient, operatorConfig)
+	foreign, err := r.listForeignBindings(groupPermission, operatorConfig)
+	if err != nil {
+		return nil, err
+	}
+	var namespaceStatuses []managedv1alpha1.NamespaceStatus
+	var pending []pendingRoleBinding
+	// desired are the RoleBindings kept, and matched those of the allowed Namespaces, by namespace/name
+	desired, matched := map[string]bool{}, map[string]bool{}
+	// checkpoint records the state of the last of namespaceStatuses, that of the RoleBinding of the
+	// Permission of index permission in namespace, and saves the checkpoint of the rollout when due
+	checkpoint := func(permission int, namespace string) {
+		applied := namespaceStatuses[len(namespaceStatuses)-1].State == managedv1alpha1.GroupPermissionCreated
+		if !progress.record(permission, namespace, applied) {
+			return
+		}
+		if err := r.saveRollout(groupPermission, progress); err != nil {
+			// the rollout goes on, it is resumed from the previous checkpoint if interrupted
+			log.Error(err, "Failed to checkpoint the rollout of the RoleBindings", "Request.Namespace", groupPermission.Namespace, "Request.Name", groupPermission.Name)
+		}
+	}
+	for i, permission := range groupPermission.Spec.Permissions {
+		for _, namespace := range namespaceList.Items {
+			if !allowed[i][namespace.Name] {
+				continue
+			}
+
+			roleBinding := newRoleBinding(namespace.Name, permissionRoleRef(&groupPermission.Spec, permission), utility.GroupSubject(&groupPermission.Spec))
+			key := roleBinding.Namespace + "/" + roleBinding.Name
+			matched[key] = true
+			// creates are forbidden in a Namespace being deleted, see reconcileTerminatingNamespaces
+			if isNamespaceTerminating(&namespace) {
+				desired[key] = true
+				continue
+			}
+
+			utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+			utility.SetJustificationAnnotations(&roleBinding.ObjectMeta, &groupPermission.Spec)
+			utility.SetDocumentationAnnotations(&roleBinding.ObjectMeta, &groupPermission.Spec)
+			utility.SetSourceAnnotations(&roleBinding.ObjectMeta, groupPermission)
+			// the bindings of the foreign operators are left to them, see reconcileForeignConflicts
+			if _, ok := foreign.conflict(namespace.Name, roleBinding.Name, roleBinding.RoleRef); ok {
+				desired[key] = true
+				continue
+			}
+			namespaceStatus := managedv1alpha1.NamespaceStatus{
+				Namespace:       namespace.Name,
+				ClusterRoleName: roleBinding.RoleRef.Name,
+				BindingName:     roleBinding.Name,
+				State:           managedv1alpha1.GroupPermissionCreated,
+			}
+
+			if roleBinding.RoleRef.Kind == "ClusterRole" && missingRoles[roleBinding.RoleRef.Name] {
+				// a RoleBinding to a missing ClusterRole would dangle
+				namespaceStatus.State = managedv1alpha1.GroupPermissionFailed
+				namespaceStatus.LastError = (&RoleNotFoundError{ClusterRoleName: roleBinding.RoleRef.Name}).Error()
+				namespaceStatuses = append(namespaceStatuses, namespaceStatus)
+				checkpoint(i, namespace.Name)
+				continue
+			}
+
+			// a GroupPermission of a delegated Namespace may only grant the roles delegable to it
+			if undelegable[roleBinding.RoleRef.Kind+" "+roleBinding.RoleRef.Name] {
+				namespaceStatus.State = managedv1alpha1.GroupPermissionFailed
+				namespaceStatus.LastError = notDelegableMessage(roleBinding.RoleRef, groupPermission.Namespace)
+				namespaceStatuses = append(namespaceStatuses, namespaceStatus)
+				checkpoint(i, namespace.Name)
+				continue
+			}
+
+			// the Namespace may restrict the teams allowed to grant roles in it, a RoleBinding created before
+			// is not desired and deleted by deleteUndesiredRoleBindings
+			if !isGranterAllowed(groupPermission, &namespace) {
+				namespaceStatus.State = managedv1alpha1.GroupPermissionFailed
+				namespaceStatus.LastError = notGranterMessage(groupPermission, &namespace)
+				namespaceStatuses = append(namespaceStatuses, namespaceStatus)
+				checkpoint(i, namespace.Name)
+				continue
+			}
+
+			// the Namespace may exclude ClusterRoles, even those bound before it did
+			if isRoleExcluded(roleBinding.RoleRef, &namespace) {
+				namespaceStatus.State = managedv1alpha1.GroupPermissionFailed
+				namespaceStatus.LastError = roleExcludedMessage(roleBinding.RoleRef, &namespace)
+				if err := r.deleteDeniedRoleBinding(groupPermission, roleBinding); err != nil {
+					namespaceStatus.LastError = err.Error()
+				}
+				namespaceStatuses = append(namespaceStatuses, namespaceStatus)
+				checkpoint(i, namespace.Name)
+				continue
+			}
+
+			// applied before the checkpoint, the checks are skipped while the RoleBinding still exists, one
+			// deleted since is checked and created again. The next rollout checks them all again.
+			if progress.done(i, namespace.Name) {
+				if err := r.checkExistingRoleBinding(groupPermission, roleBinding); !errors.IsNotFound(err) {
+					desired[key] = true
+					if err != nil {
+						namespaceStatus.State = managedv1alpha1.GroupPermissionFailed
+						namespaceStatus.LastError = err.Error()
+					}
+					namespaceStatuses = append(namespaceStatuses, namespaceStatus)
+					checkpoint(i, namespace.Name)
+					continue
+				}
+			}
+
+			// the operator binds with its own rights, the requester must be allowed to grant the role
+			escalationAllowed, err := r.isRoleBindingEscalationAllowed(groupPermission, roleBinding, permission, operatorConfig)
+			if err != nil {
+				return nil, err
+			}
+			if !escalationAllowed {
+				namespaceStatus.State = managedv1alpha1.GroupPermissionEscalationDenied
+				namespaceStatus.LastError = escalationDeniedMessage(groupPermission, roleBinding.RoleRef.Name)
+				namespaceStatuses = append(namespaceStatuses, namespaceStatus)
+				checkpoint(i, namespace.Name)
+				continue
+			}
+
+			// the policy may deny the binding
+			decision, err := policy.evaluate(newPolicyBinding(groupPermission, roleBinding.Subjects[0], roleBinding.RoleRef, namespace.Name))
+			if err != nil {
+				return nil, err
+			}
+			if !decision.Allowed {
+				namespaceStatus.State = managedv1alpha1.GroupPermissionFailed
+				namespaceStatus.LastError = policyDeniedMessage(decision)
+				namespaceStatuses = append(namespaceStatuses, namespaceStatus)
+				checkpoint(i, namespace.Name)
+				continue
+			}
+			// the grant decision service may deny the binding, or annotate it
+			grant, err := grants.decide(groupPermission, roleBinding.Subjects[0], roleBinding.RoleRef, namespace.Name)
+			if err != nil {
+				return nil, err
+			}
+			if !grant.Allowed {
+				namespaceStatus.State = managedv1alpha1.GroupPermissionFailed
+				namespaceStatus.LastError = grantDeniedMessage(grant)
+				namespaceStatuses = append(namespaceStatuses, namespaceStatus)
+				checkpoint(i, namespace.Name)
+				continue
+			}
+			setGrantAnnotations(&roleBinding.ObjectMeta, grant)
+
+			// the RoleBindings of an atomic GroupPermission are created once every one of them is checked
+			if groupPermission.Spec.Atomic {
+				pending = append(pending, pendingRoleBinding{roleBinding: roleBinding, namespace: namespace, permission: permission, status: len(namespaceStatuses)})
+				desired[key] = true
+				namespaceStatuses = append(namespaceStatuses, namespaceStatus)
+				checkpoint(i, namespace.Name)
+				continue
+			}
+
+			// a RoleBinding failing to apply is kept, it is reported and retried
+			desired[key] = true
+			if err := r.createRoleBinding(groupPermission, &namespace, permission, roleBinding); err != nil {
+				namespaceStatus.State = managedv1alpha1.GroupPermissionFailed
+				namespaceStatus.LastError = err.Error()
+			}
+			namespaceStatuses = append(namespaceStatuses, namespaceStatus)
+			checkpoint(i, namespace.Name)
+		}
+	}
+	r.applyAtomic(groupPermission, namespaceStatuses, pending)
+
+	// the RoleBindings of the Namespaces no longer allowed, of the Permissions removed from the spec, and
+	// those denied since they were created are deleted
+	if err := r.deleteUndesiredRoleBindings(groupPermission, desired, matched); err != nil {
+		return nil, err
+	}
+
+	if err := r.finishRollout(groupPermission); err != nil {
+		return nil, err
+	}
+
+	return namespaceStatuses, nil
+}
+
+// createRoleBinding creates roleBinding in namespace for groupPermission, along the Role of permission
+// when it has rules. A RoleBinding that already exists is not an error when it is managed for
+// groupPermission and grants the same role to the same subjects, it is a conflict otherwise.
+func (r *ReconcileGroupPermission) createRoleBinding(groupPermission *managedv1alpha1.GroupPermission, namespace *corev1.Namespace, permission managedv1alpha1.Permission, roleBinding *v1.RoleBinding) error {
+	if len(permission.Rules) > 0 {
+		if err := r.ensureRole(groupPermission, newRole(groupPermission, namespace.Name, permission)); err != nil {
+			return err
+		}
+	}
+
+	unlock := bindinglock.Lock(bindinglock.RoleBinding, roleBinding.Namespace, roleBinding.Name)
+	err := r.client.Create(context.TODO(), roleBinding)
+	unlock()
+	if err == nil {
+		observePropagation(groupPermission, namespace, time.Now())
+	}
+	if errors.IsAlreadyExists(err) {
+		return r.checkExistingRoleBinding(groupPermission, roleBinding)
+	}
+	return err
+}
+
+// checkExistingRoleBinding returns a ConflictError when the existing RoleBinding of the name of
+// roleBinding is not managed for groupPermission or does not grant the same role to the same subjects
+func (r *ReconcileGroupPermission) checkExistingRoleBinding(groupPermission *managedv1alpha1.GroupPermission, roleBinding *v1.RoleBinding) error {
+	existing := &v1.RoleBinding{}
+	if err := r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: roleBinding.Namespace, Name: roleBinding.Name}, existing); err != nil {
+		return err
+	}
+	var reason string
+	switch {
+	case !utility.IsManagedFor(existing.ObjectMeta, groupPermission.Namespace, groupPermission.Name):
+		reason = "exists and is not managed for the GroupPermission"
+	case !semanticallyEqual(verifiedBinding{roleRef: existing.RoleRef, subjects: existing.Subjects}, verifiedBinding{roleRef: roleBinding.RoleRef, subjects: roleBinding.Subjects}):
+		reason = "exists and grants another role or to other subjects"
+	default:
+		return nil
+	}
+	return &ConflictError{Resource: "rolebindings", Name: roleBinding.Namespace + "/" + roleBinding.Name, Err: goerrors.New(reason)}
+}
+
+// matchNamespaces returns, for every Permission of groupPermission, the number of Namespaces it is
+// allowed in according to allowed and the first sampleSize of their names in sorted order
+func matchNamespaces(groupPermission *managedv1alpha1.GroupPermission, allowed []map[string]bool, sampleSize int) []managedv1alpha1.MatchedNamespaces {
+	var matched []managedv1alpha1.MatchedNamespaces
+	for i, permission := range groupPermission.Spec.Permissions {
+		var names []string
+		for name := range allowed[i] {
+			names = append(names, name)
+		}
+
+		// sort so the sample is stable across reconciles
+		sort.Strings(names)
+		match := managedv1alpha1.MatchedNamespaces{ClusterRoleName: permissionRoleRef(&groupPermission.Spec, permission).Name, Count: len(names)}
+		if len(names) > sampleSize {
+			names = names[:sampleSize]
+		}
+		if len(names) > 0 {
+			match.Sample = names
+		}
+		matched = append(matched, match)
+	}
+	return matched
+}
+
+// isPermissionAllowed returns whether permission applies in namespace, by its regexes, conflict policy and
+// excluded requesters
+func isPermissionAllowed(permission managedv1alpha1.Permission, namespace metav1.Object) bool {
+	if utility.IsNamespaceRequesterExcluded(permission.ExcludedNamespaceRequesters, namespace.GetAnnotations()) {
+		return false
+	}
+	return utility.PermissionNamespaceMatcher(permission).Matches(namespace.GetName())
+}
+
+// maxNamespaceStatusBytes caps the size of the per-namespace status, whatever the number of namespaces
+const maxNamespaceStatusBytes = 256 * 1024
+
+// compactNamespaceStatuses returns namespaceStatuses unchanged when there are at most threshold of them
+// and they fit in maxNamespaceStatusBytes. Otherwise a summary with counts and up to sampleSize failures
+// is returned instead, to keep the status of GroupPermissions matching thousands of namespaces small.
+func compactNamespaceStatuses(namespaceStatuses []managedv1alpha1.NamespaceStatus, threshold, sampleSize int) ([]managedv1alpha1.NamespaceStatus, *managedv1alpha1.NamespaceSummary) {
+	if len(namespaceStatuses) <= threshold {
+		if encoded, err := json.Marshal(namespaceStatuses); err != nil || len(encoded) <= maxNamespaceStatusBytes {
+			return namespaceStatuses, nil
+		}
+	}
+
+	summary := &managedv1alpha1.NamespaceSummary{Total: len(namespaceStatuses)}
+	for _, namespaceStatus := range namespaceStatuses {
+		switch {
+		case namespaceStatus.State == managedv1alpha1.GroupPermissionCreated:
+			summary.Created++
+		case isBindingFailed(namespaceStatus.State):
+			summary.Failed++
+			if len(summary.FailedSample) < sampleSize {
+				summary.FailedSample = append(summary.FailedSample, namespaceStatus)
+			}
+		}
+	}
+	return nil, summary
+}
+
+// isBindingFailed returns whether a binding in state was not applied, because it failed or was denied
+func isBindingFailed(state managedv1alpha1.GroupPermissionState) bool {
+	return state == managedv1alpha1.GroupPermissionFailed || state == managedv1alpha1.GroupPermissionEscalationDenied
+}
+
+// isNamespaceStatusEqual compares two NamespaceStatus lists
+func isNamespaceStatusEqual(a, b []managedv1alpha1.NamespaceStatus) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// newRoleBinding creates and returns a RoleBinding of roleRef to subject in namespace
+func newRoleBinding(namespace string, roleRef v1.RoleRef, subject v1.Subject) *v1.RoleBinding {
+	name := roleRef.Name + "-" + subject.Name
+	if roleRef.Kind == "Role" {
+		// a Role and a ClusterRole may share a name, keep their bindings apart
+		name = "role-" + name
+	}
+	return &v1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Subjects: []v1.Subject{subject},
+		RoleRef:  roleRef,
+	}
+}
+
+// permissionRoleRef returns the reference to the ClusterRole or Role granted by permission of spec. The
+// API group is left to the apiserver default unless spec sets one.
+func permissionRoleRef(spec *managedv1alpha1.GroupPermissionSpec, permission managedv1alpha1.Permission) v1.RoleRef {
+	if permission.RoleName != "" {
+		return v1.RoleRef{APIGroup: spec.RoleRefAPIGroup, Kind: "Role", Name: permission.RoleName}
+	}
+	return v1.RoleRef{APIGroup: spec.RoleRefAPIGroup, Kind: "ClusterRole", Name: permission.ClusterRoleName}
+}
+
+// validateSpec returns an error describing the first invalid field of the spec of groupPermission
+func validateSpec(groupPermission *managedv1alpha1.GroupPermission) error {
+	if err := validatePermissions(groupPermission); err != nil {
+		return err
+	}
+	if err := validateViewRole(groupPermission); err != nil {
+		return err
+	}
+	if err := utility.ValidateRegexes(&groupPermission.Spec); err != nil {
+		return err
+	}
+	if err := validateSchedule(groupPermission.Spec.Schedule); err != nil {
+		return err
+	}
+	if err := validateClusterConditions(groupPermission.Spec.ClusterConditions); err != nil {
+		return err
+	}
+	if err := utility.ValidateSubjectAPIGroup(&groupPermission.Spec); err != nil {
+		return err
+	}
+	if err := utility.ValidateRoleRefAPIGroup(&groupPermission.Spec); err != nil {
+		return err
+	}
+	if err := utility.ValidateGroupNameSelector(&groupPermission.Spec); err != nil {
+		return err
+	}
+	return utility.ValidateServiceAccountsNamespace(&groupPermission.Spec)
+}
+
+// validatePermissions returns an error describing the first Permission of groupPermission that does
+// not set exactly one of ClusterRoleName and RoleName, or sets Rules without RoleName
+func validatePermissions(groupPermission *managedv1alpha1.GroupPermission) error {
+	for i, permission := range groupPermission.Spec.Permissions {
+		if (permission.ClusterRoleName == "") == (permission.RoleName == "") {
+			return fmt.Errorf("permission %d must set exactly one of clusterRoleName and roleName", i)
+		}
+		if len(permission.Rules) > 0 && permission.RoleName == "" {
+			return fmt.Errorf("permission %d must set roleName with rules", i)
+		}
+	}
+	return nil
+}
+
+// namespaceToGroupPermissions maps a Namespace event to a reconcile of every GroupPermission
+// of the shard with a Permission allowed in the Namespace
+type namespaceToGroupPermissions struct {
+	client client.Client
+	shard  *shard
+}
+
+// blank assignment to verify that namespaceToGroupPermissions implements handler.Mapper
+var _ handler.Mapper = &namespaceToGroupPermissions{}
+
+// Map implements handler.Mapper
+func (m *namespaceToGroupPermissions) Map(obj handler.MapObject) []reconcile.Request {
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	err := m.client.List(context.TODO(), &client.ListOptions{}, groupPermissionList)
+	if err != nil {
+		log.Error(err, "Failed to list GroupPermissions", "Namespace", obj.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, groupPermission := range groupPermissionList.Items {
+		if !m.shard.matches(&groupPermission) {
+			continue
+		}
+		for _, permission := range groupPermission.Spec.Permissions {
+			if isPermissionAllowed(permission, obj.Meta) {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: groupPermission.Namespace,
+					Name:      groupPermission.Name,
+				}})
+				break
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,403 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func mockNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+}
+
+// create a GroupPermission with a namespace scoped Permission, requested by the user newRequesterClient allows
+func mockNamespacedGroupPermission() *v1alpha1.GroupPermission {
+	groupPermission := mockGroupPermission()
+	groupPermission.Annotations = map[string]string{operatorconfig.RequesterAnnotation: "cluster-admin"}
+	groupPermission.Spec.Permissions = []v1alpha1.Permission{
+		{
+			ClusterRoleName:        "admin",
+			NamespacesAllowedRegex: "^team-.*",
+			NamespacesDeniedRegex:  "^team-secret$",
+			AllowFirst:             true,
+		},
+	}
+	return groupPermission
+}
+
+// TestReconcileNamespacePermissions tests the reconcileNamespacePermissions function
+// given: GroupPermission with a Permission, Namespaces of which some are allowed
+// expected: a RoleBinding and a NamespaceStatus for each allowed Namespace only
+func TestReconcileNamespacePermissions(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	fakeClient := newRequesterClient(mockNamespace("team-a"), mockNamespace("team-secret"), mockNamespace("default"), mockNamespace("team-b"))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+	}
+
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(mockNamespacedGroupPermission(), nil, nil, nil, operatorconfig.DefaultOperatorConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]bool{"team-a": true, "team-b": true}
+	if len(namespaceStatuses) != len(expected) {
+		t.Fatalf("got %d namespace statuses, want %d: %v", len(namespaceStatuses), len(expected), namespaceStatuses)
+	}
+	for _, namespaceStatus := range namespaceStatuses {
+		if !expected[namespaceStatus.Namespace] {
+			t.Errorf("unexpected namespace %s", namespaceStatus.Namespace)
+		}
+		if namespaceStatus.State != v1alpha1.GroupPermissionCreated {
+			t.Errorf("got state %s for %s, want %s", namespaceStatus.State, namespaceStatus.Namespace, v1alpha1.GroupPermissionCreated)
+		}
+
+		roleBinding := &rbacv1.RoleBinding{}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: namespaceStatus.Namespace, Name: namespaceStatus.BindingName}, roleBinding)
+		if err != nil {
+			t.Errorf("expected RoleBinding %s/%s: %v", namespaceStatus.Namespace, namespaceStatus.BindingName, err)
+		}
+		if source := roleBinding.Annotations[operatorconfig.SourceAnnotation]; err == nil && source != "rbac-permissions-operator/testGroupPermission" {
+			t.Errorf("Mismatch for source annotation. Expected(rbac-permissions-operator/testGroupPermission), Found(%s)", source)
+		}
+	}
+
+	// a second pass finds the existing RoleBindings
+	again, err := reconciler.reconcileNamespacePermissions(mockNamespacedGroupPermission(), nil, nil, nil, operatorconfig.DefaultOperatorConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !isNamespaceStatusEqual(namespaceStatuses, again) {
+		t.Errorf("got %v, want %v", again, namespaceStatuses)
+	}
+}
+
+// TestReconcileNamespacePermissionsEscalation tests the reconcileNamespacePermissions function of a
+// GroupPermission whose requester may not grant its role
+// given: a GroupPermission binding admin in team-a and team-b, requested by a user allowed nothing, and
+// the managed RoleBinding of team-a created before
+// expected: both NamespaceStatuses are EscalationDenied, no RoleBinding is created and the existing one is deleted
+func TestReconcileNamespacePermissionsEscalation(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Annotations = map[string]string{operatorconfig.RequesterAnnotation: "bob"}
+	existing := newRoleBinding("team-a", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&existing.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	fakeClient := newRequesterClient(existing, mockNamespace("team-a"), mockNamespace("team-b"))
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(groupPermission, nil, nil, nil, operatorconfig.DefaultOperatorConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaceStatuses) != 2 {
+		t.Fatalf("Mismatch for namespace statuses. Expected(2), Found(%+v)", namespaceStatuses)
+	}
+	for _, namespaceStatus := range namespaceStatuses {
+		if namespaceStatus.State != v1alpha1.GroupPermissionEscalationDenied || namespaceStatus.LastError != escalationDeniedMessage(groupPermission, "admin") {
+			t.Errorf("Mismatch for %s. Expected(EscalationDenied), Found(%s %q)", namespaceStatus.Namespace, namespaceStatus.State, namespaceStatus.LastError)
+		}
+	}
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := fakeClient.List(context.TODO(), &client.ListOptions{}, roleBindingList); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(roleBindingList.Items) != 0 {
+		t.Errorf("Mismatch for RoleBindings. Expected(none), Found(%+v)", roleBindingList.Items)
+	}
+}
+
+// TestCreateRoleBindingExisting tests the createRoleBinding function of a RoleBinding that already exists
+// given: the RoleBinding managed for the GroupPermission, one binding other subjects, and one not managed
+// expected: only the managed RoleBinding of the same subjects is not a conflict
+func TestCreateRoleBindingExisting(t *testing.T) {
+	groupPermission := mockNamespacedGroupPermission()
+	subjects := utility.GroupSubject(&groupPermission.Spec)
+	roleRef := rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}
+
+	managed := newRoleBinding("team-a", roleRef, subjects)
+	utility.SetManagedLabels(&managed.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	otherSubjects := newRoleBinding("team-b", roleRef, subjects)
+	utility.SetManagedLabels(&otherSubjects.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	otherSubjects.Subjects = []rbacv1.Subject{{Kind: "Group", APIGroup: rbacv1.GroupName, Name: "someone-else"}}
+	unmanaged := newRoleBinding("team-c", roleRef, subjects)
+
+	var tests = []struct {
+		namespace string
+		conflict  bool
+	}{
+		{"team-a", false},
+		{"team-b", true},
+		{"team-c", true},
+	}
+	fakeClient := fake.NewFakeClient(managed, otherSubjects, unmanaged)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+	for _, test := range tests {
+		roleBinding := newRoleBinding(test.namespace, roleRef, subjects)
+		utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+		err := reconciler.createRoleBinding(groupPermission, mockNamespace(test.namespace), groupPermission.Spec.Permissions[0], roleBinding)
+		if _, conflict := err.(*ConflictError); conflict != test.conflict || (err != nil && !conflict) {
+			t.Errorf("Mismatch for %s. Expected(conflict %t), Found(%v)", test.namespace, test.conflict, err)
+		}
+	}
+}
+
+// TestNamespaceToGroupPermissions tests the namespaceToGroupPermissions mapper
+// given: a Namespace and GroupPermissions allowed or not in it
+// expected: a reconcile request only for the allowed GroupPermission
+func TestNamespaceToGroupPermissions(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	mapper := &namespaceToGroupPermissions{
+		client: fake.NewFakeClient(mockNamespacedGroupPermission()),
+	}
+
+	if requests := mapper.Map(handler.MapObject{Meta: mockNamespace("team-a")}); len(requests) != 1 || requests[0].Name != "testGroupPermission" {
+		t.Errorf("expected a request for testGroupPermission, got %v", requests)
+	}
+	if requests := mapper.Map(handler.MapObject{Meta: mockNamespace("team-secret")}); len(requests) != 0 {
+		t.Errorf("expected no requests, got %v", requests)
+	}
+}
+
+// TestCompactNamespaceStatuses tests the compactNamespaceStatuses function
+// given: NamespaceStatuses below and above the threshold
+// expected: full detail below the threshold, a summary with a capped failure sample above it
+func TestCompactNamespaceStatuses(t *testing.T) {
+	namespaceStatuses := []v1alpha1.NamespaceStatus{
+		{Namespace: "a", State: v1alpha1.GroupPermissionCreated},
+		{Namespace: "b", State: v1alpha1.GroupPermissionFailed, LastError: "forbidden"},
+		{Namespace: "c", State: v1alpha1.GroupPermissionFailed, LastError: "forbidden"},
+		{Namespace: "d", State: v1alpha1.GroupPermissionCreated},
+	}
+
+	detail, summary := compactNamespaceStatuses(namespaceStatuses, 4, 1)
+	if len(detail) != 4 || summary != nil {
+		t.Errorf("expected full detail at the threshold, got %v, %v", detail, summary)
+	}
+
+	detail, summary = compactNamespaceStatuses(namespaceStatuses, 3, 1)
+	if detail != nil || summary == nil {
+		t.Fatalf("expected a summary above the threshold, got %v, %v", detail, summary)
+	}
+	if summary.Total != 4 || summary.Created != 2 || summary.Failed != 2 {
+		t.Errorf("got total=%d created=%d failed=%d, want 4, 2, 2", summary.Total, summary.Created, summary.Failed)
+	}
+	if len(summary.FailedSample) != 1 || summary.FailedSample[0].Namespace != "b" {
+		t.Errorf("got failed sample %v, want only namespace b", summary.FailedSample)
+	}
+}
+
+// TestCompactNamespaceStatusesSize tests the compactNamespaceStatuses function
+// given: NamespaceStatuses below the threshold, too large for the status
+// expected: a summary instead of the full detail
+func TestCompactNamespaceStatusesSize(t *testing.T) {
+	namespaceStatuses := []v1alpha1.NamespaceStatus{
+		{Namespace: "a", State: v1alpha1.GroupPermissionFailed, LastError: strings.Repeat("x", maxNamespaceStatusBytes)},
+	}
+
+	detail, summary := compactNamespaceStatuses(namespaceStatuses, 100, 0)
+	if detail != nil || summary == nil || summary.Failed != 1 {
+		t.Errorf("expected a summary of a status above the size limit, got %v, %v", detail, summary)
+	}
+}
+
+// TestMatchNamespaces tests the matchNamespaces function
+// given: GroupPermission with a Permission, unsorted Namespaces of which some are allowed
+// expected: the count of allowed Namespaces and a sorted sample capped at the sample size
+func TestMatchNamespaces(t *testing.T) {
+	namespaceList := &corev1.NamespaceList{
+		Items: []corev1.Namespace{*mockNamespace("team-c"), *mockNamespace("team-secret"), *mockNamespace("default"), *mockNamespace("team-a"), *mockNamespace("team-b")},
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	matched := matchNamespaces(groupPermission, evaluateNamespaces(groupPermission.Spec.Permissions, namespaceList), 2)
+	if len(matched) != 1 {
+		t.Fatalf("got %d matches, want 1: %v", len(matched), matched)
+	}
+	expected := v1alpha1.MatchedNamespaces{ClusterRoleName: "admin", Count: 3, Sample: []string{"team-a", "team-b"}}
+	if !reflect.DeepEqual(matched[0], expected) {
+		t.Errorf("got %v, want %v", matched[0], expected)
+	}
+
+	matched = matchNamespaces(groupPermission, evaluateNamespaces(groupPermission.Spec.Permissions, &corev1.NamespaceList{}), 2)
+	if len(matched) != 1 || matched[0].Count != 0 || matched[0].Sample != nil {
+		t.Errorf("expected no matched namespaces, got %v", matched)
+	}
+}
+
+// TestIsPermissionAllowedExcludedRequester tests the isPermissionAllowed function
+// given: a Permission excluding a requester, Namespaces matching its regex requested by various users
+// expected: Namespaces requested by the excluded requester are not allowed
+func TestIsPermissionAllowedExcludedRequester(t *testing.T) {
+	permission := mockNamespacedGroupPermission().Spec.Permissions[0]
+	permission.ExcludedNamespaceRequesters = []string{"system:serviceaccount:openshift-infra:bot"}
+
+	botNamespace := mockNamespace("team-utility")
+	botNamespace.Annotations = map[string]string{"openshift.io/requester": "system:serviceaccount:openshift-infra:bot"}
+	userNamespace := mockNamespace("team-a")
+	userNamespace.Annotations = map[string]string{"openshift.io/requester": "alice"}
+
+	if isPermissionAllowed(permission, botNamespace) {
+		t.Errorf("expected namespace requested by an excluded requester not to be allowed")
+	}
+	if !isPermissionAllowed(permission, userNamespace) {
+		t.Errorf("expected namespace requested by another user to be allowed")
+	}
+	if !isPermissionAllowed(permission, mockNamespace("team-b")) {
+		t.Errorf("expected namespace without a requester to be allowed")
+	}
+}
+
+// TestValidatePermissions tests the validatePermissions function
+// given: Permissions setting a ClusterRole, a Role, both or neither, and rules
+// expected: an error only when not exactly one role is set, or rules without a Role
+func TestValidatePermissions(t *testing.T) {
+	tests := []struct {
+		permission v1alpha1.Permission
+		valid      bool
+	}{
+		{v1alpha1.Permission{ClusterRoleName: "admin"}, true},
+		{v1alpha1.Permission{RoleName: "deployer"}, true},
+		{v1alpha1.Permission{ClusterRoleName: "admin", RoleName: "deployer"}, false},
+		{v1alpha1.Permission{}, false},
+		{v1alpha1.Permission{RoleName: "deployer", Rules: []rbacv1.PolicyRule{{Verbs: []string{"get"}}}}, true},
+		{v1alpha1.Permission{ClusterRoleName: "admin", Rules: []rbacv1.PolicyRule{{Verbs: []string{"get"}}}}, false},
+	}
+	for _, test := range tests {
+		groupPermission := mockGroupPermission()
+		groupPermission.Spec.Permissions = []v1alpha1.Permission{test.permission}
+		if err := validatePermissions(groupPermission); (err == nil) != test.valid {
+			t.Errorf("%+v: got error %v, want valid %t", test.permission, err, test.valid)
+		}
+	}
+}
+
+// TestValidateSpecRegex tests the validateSpec function on the namespace regexes
+// given: GroupPermission with a Permission whose denied regex has a syntax error
+// expected: an error with the offset of the syntax error
+func TestValidateSpecRegex(t *testing.T) {
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.Permissions[0].NamespacesDeniedRegex = "^team-(secret"
+
+	expected := `namespacesDeniedRegex of permissions[0] is invalid at offset 0, missing closing ): "^team-(secret"`
+	if err := validateSpec(groupPermission); err == nil || err.Error() != expected {
+		t.Errorf("Mismatch for error. Expected(%s), Found(%v)", expected, err)
+	}
+}
+
+// TestReconcileRolePermission tests that Reconcile binds the Role of a Permission
+// given: GroupPermission with a Permission granting a Role
+// expected: a RoleBinding of the Role in the allowed Namespace
+func TestReconcileRolePermission(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	groupPermission.Spec.Permissions[0].ClusterRoleName = ""
+	groupPermission.Spec.Permissions[0].RoleName = "deployer"
+	fakeClient := newRequesterClient(groupPermission, mockNamespace("team-a"))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	if _, err := reconciler.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	roleBinding := &rbacv1.RoleBinding{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "role-deployer-exampleGroupName"}, roleBinding); err != nil {
+		t.Fatalf("expected the RoleBinding of the Role: %v", err)
+	}
+	if roleBinding.RoleRef.Kind != "Role" || roleBinding.RoleRef.Name != "deployer" {
+		t.Errorf("expected a binding of Role deployer, got %v", roleBinding.RoleRef)
+	}
+}
+
+// TestReconcileInvalidPermission tests that Reconcile stops on an invalid Permission
+// given: GroupPermission with a Permission granting both a ClusterRole and a Role
+// expected: no RoleBinding, a single active InvalidPermission condition, no requeue, phase Failed
+func TestReconcileInvalidPermission(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.Permissions[0].RoleName = "deployer"
+	fakeClient := fake.NewFakeClient(groupPermission, mockNamespace("team-a"))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	for i := 0; i < 2; i++ {
+		result, err := reconciler.Reconcile(reconcile.Request{NamespacedName: key})
+		if err != nil || result != (reconcile.Result{}) {
+			t.Fatalf("expected no requeue, got %v, %v", result, err)
+		}
+	}
+
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := fakeClient.List(context.TODO(), &client.ListOptions{}, roleBindingList); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(roleBindingList.Items) != 0 {
+		t.Errorf("expected no RoleBinding, got %d", len(roleBindingList.Items))
+	}
+
+	reconciled := &v1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), key, reconciled); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	invalid := 0
+	for _, condition := range reconciled.Status.Conditions {
+		if condition.Reason == v1alpha1.ReasonInvalidPermission && condition.Status {
+			invalid++
+		}
+	}
+	if invalid != 1 {
+		t.Errorf("expected a single active InvalidPermission condition, got %d", invalid)
+	}
+	if reconciled.Status.Phase != v1alpha1.GroupPermissionPhaseFailed {
+		t.Errorf("expected phase Failed, got %q", reconciled.Status.Phase)
+	}
+}
@@ -0,0 +1,157 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;create;update;delete;escalate
+
+// newRole returns the Role of permission the operator creates in namespace for groupPermission
+func newRole(groupPermission *managedv1alpha1.GroupPermission, namespace string, permission managedv1alpha1.Permission) *v1.Role {
+	role := &v1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      permission.RoleName,
+			Namespace: namespace,
+		},
+		Rules: permission.Rules,
+	}
+	utility.SetManagedLabels(&role.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	utility.SetSourceAnnotations(&role.ObjectMeta, groupPermission)
+	role.Annotations[operatorconfig.RulesHashAnnotation] = rulesHash(role.Rules)
+	return role
+}
+
+// ensureRole creates role for groupPermission. The rules of an existing Role are converged by the role
+// content controller. An existing Role that is not managed for groupPermission is never taken over.
+func (r *ReconcileGroupPermission) ensureRole(groupPermission *managedv1alpha1.GroupPermission, role *v1.Role) error {
+	defer bindinglock.Lock(bindinglock.Role, role.Namespace, role.Name)()
+
+	existing := &v1.Role{}
+	err := r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: role.Namespace, Name: role.Name}, existing)
+	if errors.IsNotFound(err) {
+		return r.client.Create(context.TODO(), role)
+	}
+	if err != nil {
+		return err
+	}
+	if !utility.IsManagedFor(existing.ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+		return fmt.Errorf("Role %s exists and is not managed for the GroupPermission", role.Name)
+	}
+	return nil
+}
+
+// deleteUnmatchedRoles deletes the Roles created for groupPermission that no Permission with rules
+// resolves to anymore, such as those of the Namespaces no longer allowed or protected by operatorConfig,
+// with the RoleBindings managed for groupPermission to them. With a removal grace period in operatorConfig,
+// they are labeled pending removal first and deleted once it elapsed, unless resolved to again meanwhile.
+// It returns the number of Roles deleted and when the next pending removal is due, zero when none is.
+func (r *ReconcileGroupPermission) deleteUnmatchedRoles(groupPermission *managedv1alpha1.GroupPermission, operatorConfig *operatorconfig.OperatorConfig, now time.Time) (int, time.Time, error) {
+	roleList, err := r.listManagedRoles(groupPermission)
+	if err != nil || len(roleList.Items) == 0 {
+		return 0, time.Time{}, err
+	}
+
+	namespaceList, err := r.listNamespaces(groupPermission)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	desired := desiredRoles(groupPermission, r.namespaces.allowedNamespaces(groupPermission, unprotectedNamespaces(namespaceList, operatorConfig)))
+
+	deleted := 0
+	var nextRemoval time.Time
+	for i := range roleList.Items {
+		role := &roleList.Items[i]
+		if _, ok := desired[role.Namespace+"/"+role.Name]; ok {
+			if err := r.cancelRemoval(groupPermission, role); err != nil {
+				return deleted, nextRemoval, err
+			}
+			continue
+		}
+		due, err := r.scheduleRemoval(groupPermission, role, operatorConfig.RemovalGracePeriod, now)
+		if err != nil {
+			return deleted, nextRemoval, err
+		}
+		if due.After(now) {
+			if nextRemoval.IsZero() || due.Before(nextRemoval) {
+				nextRemoval = due
+			}
+			continue
+		}
+		if err := r.deleteRole(groupPermission, role); err != nil {
+			return deleted, nextRemoval, err
+		}
+		deleted++
+	}
+	return deleted, nextRemoval, nil
+}
+
+// deleteManagedRoles deletes every Role created for groupPermission, with the RoleBindings managed for
+// groupPermission to them
+func (r *ReconcileGroupPermission) deleteManagedRoles(groupPermission *managedv1alpha1.GroupPermission) error {
+	roleList, err := r.listManagedRoles(groupPermission)
+	if err != nil {
+		return err
+	}
+	for i := range roleList.Items {
+		if err := r.deleteRole(groupPermission, &roleList.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listManagedRoles returns the Roles labeled as managed for groupPermission
+func (r *ReconcileGroupPermission) listManagedRoles(groupPermission *managedv1alpha1.GroupPermission) (*v1.RoleList, error) {
+	opts := &client.ListOptions{LabelSelector: utility.ManagedForSelector(groupPermission.Namespace, groupPermission.Name)}
+	roleList := &v1.RoleList{}
+	if err := r.apiClient.List(context.TODO(), opts, roleList); err != nil {
+		return nil, err
+	}
+
+	managed := roleList.Items[:0]
+	for _, role := range roleList.Items {
+		if utility.IsManagedFor(role.ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+			managed = append(managed, role)
+		}
+	}
+	roleList.Items = managed
+	return roleList, nil
+}
+
+// deleteRole deletes the RoleBinding managed for groupPermission to role, then role, so the binding
+// never dangles
+func (r *ReconcileGroupPermission) deleteRole(groupPermission *managedv1alpha1.GroupPermission, role *v1.Role) error {
+	roleBinding := newRoleBinding(role.Namespace, v1.RoleRef{APIGroup: groupPermission.Spec.RoleRefAPIGroup, Kind: "Role", Name: role.Name}, utility.GroupSubject(&groupPermission.Spec))
+	existing := &v1.RoleBinding{}
+	err := r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: roleBinding.Namespace, Name: roleBinding.Name}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && utility.IsManagedFor(existing.ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+		unlock := bindinglock.Lock(bindinglock.RoleBinding, existing.Namespace, existing.Name)
+		err := r.client.Delete(context.TODO(), existing)
+		unlock()
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	defer bindinglock.Lock(bindinglock.Role, role.Namespace, role.Name)()
+	if err := r.client.Delete(context.TODO(), role); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// mockRulesGroupPermission returns a GroupPermission creating the Role deployer in the team Namespaces
+func mockRulesGroupPermission() *v1alpha1.GroupPermission {
+	groupPermission := mockGroupPermission()
+	groupPermission.Annotations = map[string]string{operatorconfig.RequesterAnnotation: "cluster-admin"}
+	groupPermission.Spec.ClusterPermissions = nil
+	groupPermission.Spec.Permissions = []v1alpha1.Permission{
+		{
+			RoleName: "deployer",
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "update"}},
+			},
+			NamespacesAllowedRegex: "^team-.*",
+			AllowFirst:             true,
+		},
+	}
+	return groupPermission
+}
+
+// TestReconcileRulesPermission tests the reconcileNamespacePermissions and deleteUnmatchedRoles functions
+// given: a Permission with rules, an allowed Namespace and a Namespace no longer allowed with a Role and
+// a RoleBinding created for it before
+// expected: the Role and its RoleBinding are created in the allowed Namespace and removed from the other one
+func TestReconcileRulesPermission(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockRulesGroupPermission()
+	staleRole := newRole(groupPermission, "legacy", groupPermission.Spec.Permissions[0])
+	staleRoleBinding := newRoleBinding("legacy", rbacv1.RoleRef{Kind: "Role", Name: "deployer"}, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&staleRoleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+
+	fakeClient := newRequesterClient(mockNamespace("team-a"), mockNamespace("legacy"), staleRole, staleRoleBinding)
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+	}
+
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(groupPermission, nil, nil, nil, operatorconfig.DefaultOperatorConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaceStatuses) != 1 || namespaceStatuses[0].State != v1alpha1.GroupPermissionCreated {
+		t.Fatalf("expected the RoleBinding of team-a to be created, got %+v", namespaceStatuses)
+	}
+	role := &rbacv1.Role{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "deployer"}, role); err != nil {
+		t.Fatalf("expected the Role in team-a: %v", err)
+	}
+	if !utility.IsManagedFor(role.ObjectMeta, groupPermission.Namespace, groupPermission.Name) || !reflect.DeepEqual(role.Rules, groupPermission.Spec.Permissions[0].Rules) {
+		t.Errorf("unexpected Role %+v", role)
+	}
+	roleBinding := &rbacv1.RoleBinding{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: namespaceStatuses[0].BindingName}, roleBinding); err != nil {
+		t.Fatalf("expected the RoleBinding in team-a: %v", err)
+	}
+	if roleBinding.RoleRef.Kind != "Role" || roleBinding.RoleRef.Name != "deployer" {
+		t.Errorf("unexpected RoleRef %+v", roleBinding.RoleRef)
+	}
+
+	deleted, _, err := reconciler.deleteUnmatchedRoles(groupPermission, operatorconfig.DefaultOperatorConfig(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Mismatch for deleted Roles. Expected(1), Found(%d)", deleted)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "legacy", Name: "deployer"}, &rbacv1.Role{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the Role of the unmatched Namespace to be deleted, got %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "legacy", Name: staleRoleBinding.Name}, &rbacv1.RoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the RoleBinding of the unmatched Namespace to be deleted, got %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "deployer"}, &rbacv1.Role{}); err != nil {
+		t.Errorf("expected the Role of the allowed Namespace to be kept: %v", err)
+	}
+}
+
+// TestReconcileRulesPermissionEscalation tests the reconcileNamespacePermissions function of a Permission
+// with rules the requester may not grant
+// given: a Permission with rules requested by a user allowed nothing, and an allowed Namespace
+// expected: the RoleBinding of the Namespace is EscalationDenied and neither the Role nor the RoleBinding is created
+func TestReconcileRulesPermissionEscalation(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockRulesGroupPermission()
+	groupPermission.Annotations = map[string]string{operatorconfig.RequesterAnnotation: "bob"}
+	fakeClient := newRequesterClient(mockNamespace("team-a"))
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(groupPermission, nil, nil, nil, operatorconfig.DefaultOperatorConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaceStatuses) != 1 || namespaceStatuses[0].State != v1alpha1.GroupPermissionEscalationDenied {
+		t.Fatalf("Mismatch for namespace statuses. Expected(team-a EscalationDenied), Found(%+v)", namespaceStatuses)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "deployer"}, &rbacv1.Role{}); !errors.IsNotFound(err) {
+		t.Errorf("Mismatch for Role. Expected(not created), Found(%v)", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: namespaceStatuses[0].BindingName}, &rbacv1.RoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("Mismatch for RoleBinding. Expected(not created), Found(%v)", err)
+	}
+}
+
+// TestDeleteUnmatchedRolesGracePeriod tests the deleteUnmatchedRoles function with a removal grace period
+// given: a Role of a Namespace no longer allowed, and a Role pending removal of a Namespace allowed again
+// expected: the first Role and its RoleBinding are labeled pending removal, then deleted once due, and
+// the pending removal of the second one is cleared
+func TestDeleteUnmatchedRolesGracePeriod(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	groupPermission := mockRulesGroupPermission()
+	staleRole := newRole(groupPermission, "legacy", groupPermission.Spec.Permissions[0])
+	staleRoleBinding := newRoleBinding("legacy", rbacv1.RoleRef{Kind: "Role", Name: "deployer"}, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&staleRoleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	restoredRole := newRole(groupPermission, "team-a", groupPermission.Spec.Permissions[0])
+	utility.SetPendingRemoval(&restoredRole.ObjectMeta, now)
+
+	fakeClient := newRequesterClient(mockNamespace("team-a"), mockNamespace("legacy"), staleRole, staleRoleBinding, restoredRole)
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  recorder,
+	}
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+	operatorConfig.RemovalGracePeriod = time.Hour
+
+	deleted, nextRemoval, err := reconciler.deleteUnmatchedRoles(groupPermission, operatorConfig, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 0 || !nextRemoval.Equal(now.Add(time.Hour)) {
+		t.Errorf("Mismatch for the pending removal. Expected(0, %s), Found(%d, %s)", now.Add(time.Hour), deleted, nextRemoval)
+	}
+	role := &rbacv1.Role{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "legacy", Name: "deployer"}, role); err != nil {
+		t.Fatalf("expected the Role pending removal to be kept: %v", err)
+	}
+	roleBinding := &rbacv1.RoleBinding{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "legacy", Name: staleRoleBinding.Name}, roleBinding); err != nil {
+		t.Fatalf("expected the RoleBinding pending removal to be kept: %v", err)
+	}
+	for _, objectMeta := range []metav1.ObjectMeta{role.ObjectMeta, roleBinding.ObjectMeta} {
+		if due, ok := utility.RemovalDue(objectMeta); !ok || !due.Equal(now.Add(time.Hour)) {
+			t.Errorf("Mismatch for the removal of %s. Expected(%s), Found(%s, %t)", objectMeta.Name, now.Add(time.Hour), due, ok)
+		}
+	}
+	restored := &rbacv1.Role{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "deployer"}, restored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := utility.RemovalDue(restored.ObjectMeta); ok {
+		t.Errorf("expected the pending removal of the Role of the allowed Namespace to be cleared")
+	}
+	if len(recorder.Events) != 2 {
+		t.Errorf("Mismatch for events. Expected(2), Found(%d)", len(recorder.Events))
+	}
+
+	deleted, nextRemoval, err = reconciler.deleteUnmatchedRoles(groupPermission, operatorConfig, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 1 || !nextRemoval.IsZero() {
+		t.Errorf("Mismatch for the removal once due. Expected(1, zero), Found(%d, %s)", deleted, nextRemoval)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "legacy", Name: staleRoleBinding.Name}, &rbacv1.RoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the RoleBinding to be deleted once due, got %v", err)
+	}
+}
+
+// TestEnsureRole tests the ensureRole function
+// given: a managed Role with outdated rules, and a Role of the same name not managed by the operator
+// expected: the managed Role is left to the role content controller, the other Role is left as it is and
+// an error returned
+func TestEnsureRole(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockRulesGroupPermission()
+	outdated := newRole(groupPermission, "team-a", groupPermission.Spec.Permissions[0])
+	outdated.Rules = []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get"}}}
+	unmanaged := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "deployer"}}
+
+	fakeClient := fake.NewFakeClient(outdated, unmanaged)
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+	}
+
+	if err := reconciler.ensureRole(groupPermission, newRole(groupPermission, "team-a", groupPermission.Spec.Permissions[0])); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	role := &rbacv1.Role{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "deployer"}, role); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(role.Rules, outdated.Rules) {
+		t.Errorf("expected the rules to be left to the role content controller, got %+v", role.Rules)
+	}
+
+	if err := reconciler.ensureRole(groupPermission, newRole(groupPermission, "team-b", groupPermission.Spec.Permissions[0])); err == nil {
+		t.Errorf("expected an error for a Role not managed for the GroupPermission")
+	}
+	kept := &rbacv1.Role{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-b", Name: "deployer"}, kept); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kept.Rules) != 0 || utility.IsManaged(kept.ObjectMeta) {
+		t.Errorf("expected the unmanaged Role to be left as it is, got %+v", kept)
+	}
+}
@@ -0,0 +1,140 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcilerOptions are the dependencies of a ReconcileGroupPermission set by Options, those left unset
+// are created from the manager
+type reconcilerOptions struct {
+	client         client.Client
+	apiClient      client.Client
+	recorder       record.EventRecorder
+	operatorConfig *operatorconfig.OperatorConfig
+	// shardSelector, watchNamespaces and watchNamespaceSelector select the GroupPermissions reconciled,
+	// every one when empty
+	shardSelector          string
+	watchNamespaces        []string
+	watchNamespaceSelector string
+	removeStaleGrants      bool
+	roleContentWorkers     int
+	roleContentResync      time.Duration
+}
+
+// Option configures the ReconcileGroupPermission returned by NewGroupPermissionReconciler
+type Option func(*reconcilerOptions)
+
+// newReconcilerOptions returns the options set by opts over the defaults
+func newReconcilerOptions(opts []Option) *reconcilerOptions {
+	options := &reconcilerOptions{
+		roleContentWorkers: 1,
+		roleContentResync:  10 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithClient sets the client reading from the cache and writing to the apiserver, the client of the
+// manager by default
+func WithClient(c client.Client) Option {
+	return func(o *reconcilerOptions) { o.client = c }
+}
+
+// WithAPIClient sets the client reading from the apiserver the objects outside the cache of the manager,
+// such as RoleBindings, a new client of the configuration of the manager by default
+func WithAPIClient(c client.Client) Option {
+	return func(o *reconcilerOptions) { o.apiClient = c }
+}
+
+// WithRecorder sets the recorder of the events of the GroupPermissions, a recorder of the manager by default
+func WithRecorder(recorder record.EventRecorder) Option {
+	return func(o *reconcilerOptions) { o.recorder = recorder }
+}
+
+// WithOperatorConfig sets a fixed configuration of the operator, instead of reading the operator
+// ConfigMap on each reconcile
+func WithOperatorConfig(operatorConfig *operatorconfig.OperatorConfig) Option {
+	return func(o *reconcilerOptions) { o.operatorConfig = operatorConfig }
+}
+
+// WithShard restricts the GroupPermissions reconciled to the ones matching the label selector, an empty
+// selector matches every GroupPermission
+func WithShard(selector string) Option {
+	return func(o *reconcilerOptions) { o.shardSelector = selector }
+}
+
+// WithWatchNamespaces restricts the GroupPermissions reconciled to the ones in namespaces or in a Namespace
+// matching the label selector namespaceSelector, so instances started for each tenant do not reconcile
+// each other's GroupPermissions. Without namespaces nor a selector, the GroupPermissions of every watched
+// Namespace are reconciled.
+func WithWatchNamespaces(namespaces []string, namespaceSelector string) Option {
+	return func(o *reconcilerOptions) {
+		o.watchNamespaces = namespaces
+		o.watchNamespaceSelector = namespaceSelector
+	}
+}
+
+// WithRemoveStaleGrants enables the removal of the grants flagged as stale by the stale grant reaper, they
+// are only flagged by default
+func WithRemoveStaleGrants(enabled bool) Option {
+	return func(o *reconcilerOptions) { o.removeStaleGrants = enabled }
+}
+
+// WithRoleContentTuning sets the number of GroupPermissions the role content controller converges
+// concurrently and the interval between two convergences of the Roles of each, independently of the
+// binding controller. The defaults are 1 and 10 minutes.
+func WithRoleContentTuning(workers int, resync time.Duration) Option {
+	return func(o *reconcilerOptions) {
+		o.roleContentWorkers = workers
+		o.roleContentResync = resync
+	}
+}
+
+// shard returns the shard of the GroupPermissions selected by o, reading the labels of their Namespaces
+// with reader
+func (o *reconcilerOptions) shard(reader client.Reader) (*shard, error) {
+	selector, err := labels.Parse(o.shardSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shard selector: %v", err)
+	}
+	namespaceSelector, err := labels.Parse(o.watchNamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector: %v", err)
+	}
+	s := newShard(selector)
+	for _, namespace := range o.watchNamespaces {
+		s.namespaces[namespace] = true
+	}
+	s.namespaceSelector = namespaceSelector
+	s.reader = reader
+	return s, nil
+}
+
+// validate returns an error when the role content tuning of o is invalid
+func (o *reconcilerOptions) validate() error {
+	if o.roleContentWorkers < 1 {
+		return fmt.Errorf("role content workers must be at least 1, got %d", o.roleContentWorkers)
+	}
+	if o.roleContentResync <= 0 {
+		return fmt.Errorf("role content resync must be positive, got %s", o.roleContentResync)
+	}
+	return nil
+}
+
+// getOperatorConfig returns the fixed configuration of the operator, or reads it from the operator ConfigMap
+func (r *ReconcileGroupPermission) getOperatorConfig(ctx context.Context) (*operatorconfig.OperatorConfig, error) {
+	if r.operatorConfig != nil {
+		return r.operatorConfig, nil
+	}
+	return operatorconfig.GetOperatorConfig(ctx, r.client)
+}
@@ -0,0 +1,161 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/readonly"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// mockManager provides the dependencies NewGroupPermissionReconciler reads from a manager, without an
+// apiserver
+type mockManager struct {
+	manager.Manager
+	client    client.Client
+	recorder  record.EventRecorder
+	runnables []manager.Runnable
+}
+
+func (m *mockManager) Add(runnable manager.Runnable) error {
+	m.runnables = append(m.runnables, runnable)
+	return nil
+}
+
+func (m *mockManager) GetConfig() *rest.Config                      { return &rest.Config{Host: "https://127.0.0.1:6443"} }
+func (m *mockManager) GetScheme() *runtime.Scheme                   { return scheme.Scheme }
+func (m *mockManager) GetClient() client.Client                     { return m.client }
+func (m *mockManager) GetRecorder(name string) record.EventRecorder { return m.recorder }
+
+// GetRESTMapper maps the RoleBindings indexed by the reconciler
+func (m *mockManager) GetRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("RoleBinding"), meta.RESTScopeNamespace)
+	return mapper
+}
+
+// TestOptions tests the Options of NewGroupPermissionReconciler
+// given: every Option
+// expected: each sets its dependency of the reconciler
+func TestOptions(t *testing.T) {
+	c := fake.NewFakeClient()
+	apiClient := fake.NewFakeClient()
+	recorder := record.NewFakeRecorder(1)
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+
+	options := &reconcilerOptions{}
+	for _, opt := range []Option{WithClient(c), WithAPIClient(apiClient), WithRecorder(recorder), WithOperatorConfig(operatorConfig)} {
+		opt(options)
+	}
+	if options.client != c || options.apiClient != apiClient || options.recorder != recorder || options.operatorConfig != operatorConfig {
+		t.Errorf("expected every Option to set its dependency, got %+v", options)
+	}
+}
+
+// TestGetOperatorConfig tests the getOperatorConfig function
+// given: an operator ConfigMap, with and without a fixed operator configuration
+// expected: the fixed configuration is used when set, the ConfigMap is read otherwise
+func TestGetOperatorConfig(t *testing.T) {
+	configMap := mockOperatorConfigMap(operatorconfig.OperatorNamespace, map[string]string{"status_failure_sample_size": "3"})
+	reconciler := &ReconcileGroupPermission{client: fake.NewFakeClient(configMap)}
+
+	operatorConfig, err := reconciler.getOperatorConfig(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if operatorConfig.StatusFailureSampleSize != 3 {
+		t.Errorf("Mismatch for the sample size read from the ConfigMap. Expected(3), Found(%d)", operatorConfig.StatusFailureSampleSize)
+	}
+
+	reconciler.operatorConfig = operatorconfig.DefaultOperatorConfig()
+	operatorConfig, err = reconciler.getOperatorConfig(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if operatorConfig != reconciler.operatorConfig {
+		t.Errorf("expected the fixed operator configuration, got %+v", operatorConfig)
+	}
+}
+
+// TestNewGroupPermissionReconciler tests the NewGroupPermissionReconciler function
+// given: a manager, without Options, with Options and with invalid Options
+// expected: the dependencies of the manager and the default tuning without Options, those of the
+// Options otherwise, and an error for the invalid ones
+func TestNewGroupPermissionReconciler(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	mgr := &mockManager{client: fake.NewFakeClient(), recorder: record.NewFakeRecorder(1)}
+
+	reconciler, err := NewGroupPermissionReconciler(mgr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reconciler.recorder != mgr.recorder || reconciler.operatorConfig != nil || reconciler.apiClient == nil || reconciler.bindings == nil {
+		t.Errorf("expected the dependencies of the manager, got %+v", reconciler)
+	}
+	if reconciler.removeStaleGrants || reconciler.roleContentWorkers != 1 || reconciler.roleContentResync != 10*time.Minute {
+		t.Errorf("Mismatch for the defaults. Expected(false, 1, 10m), Found(%t, %d, %s)", reconciler.removeStaleGrants, reconciler.roleContentWorkers, reconciler.roleContentResync)
+	}
+	if !reconciler.shard.selector.Empty() || reconciler.shard.reader != mgr.client {
+		t.Errorf("expected the shard of every GroupPermission reading the manager client, got %+v", reconciler.shard)
+	}
+
+	recorder := record.NewFakeRecorder(1)
+	reconciler, err = NewGroupPermissionReconciler(mgr, WithRecorder(recorder), WithShard("shard=a"), WithRemoveStaleGrants(true), WithRoleContentTuning(4, time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reconciler.recorder != recorder || !reconciler.removeStaleGrants || reconciler.roleContentWorkers != 4 || reconciler.roleContentResync != time.Minute {
+		t.Errorf("expected the dependencies and tuning of the Options, got %+v", reconciler)
+	}
+	if reconciler.shard.String() != "shard=a" {
+		t.Errorf("Mismatch for the shard. Expected(shard=a), Found(%s)", reconciler.shard)
+	}
+
+	for _, opt := range []Option{WithShard("shard in ("), WithWatchNamespaces(nil, "team in ("), WithRoleContentTuning(0, time.Minute), WithRoleContentTuning(1, 0)} {
+		if _, err := NewGroupPermissionReconciler(mgr, opt); err == nil {
+			t.Errorf("expected an error for invalid Options")
+		}
+	}
+}
+
+// TestNewGroupPermissionReconcilerReadOnly tests the client of WithClient in read-only mode
+// given: the read-only mode and a client set with WithClient
+// expected: the RoleBindings created with the client of the reconciler are skipped
+func TestNewGroupPermissionReconcilerReadOnly(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	readonly.Enable()
+	defer readonly.Disable()
+
+	c := fake.NewFakeClient()
+	reconciler, err := NewGroupPermissionReconciler(&mockManager{client: fake.NewFakeClient()}, WithClient(c))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	groupPermission := mockNamespacedGroupPermission()
+	roleBinding := newRoleBinding("team-a", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, utility.GroupSubject(&groupPermission.Spec))
+	if err := reconciler.client.Create(context.TODO(), roleBinding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: roleBinding.Name}, &rbacv1.RoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("Mismatch for the RoleBinding written in read-only mode. Expected(skipped), Found(%v)", err)
+	}
+}
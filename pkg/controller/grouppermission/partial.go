@@ -0,0 +1,83 @@
+package grouppermission
+
+import (
+	"fmt"
+	"strings"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+)
+
+// bindingApplication counts the bindings of groupPermission applied and failed, from the current
+// condition of each ClusterPermission and from namespaceStatuses, and returns the names of the
+// first sampleSize failed bindings
+func bindingApplication(groupPermission *managedv1alpha1.GroupPermission, namespaceStatuses []managedv1alpha1.NamespaceStatus, sampleSize int) (int, int, []string) {
+	applied, failed := 0, 0
+	var failedSample []string
+	recordFailure := func(name string) {
+		failed++
+		if len(failedSample) < sampleSize {
+			failedSample = append(failedSample, name)
+		}
+	}
+
+	for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+		condition := conditions.Get(groupPermission.Status.Conditions, clusterRoleName)
+		if condition == nil || !condition.Status {
+			continue
+		}
+		switch {
+		case condition.State == managedv1alpha1.GroupPermissionCreated:
+			applied++
+		case isBindingFailed(condition.State):
+			recordFailure(clusterPermissionBinding(&groupPermission.Spec, clusterRoleName).Name)
+		}
+	}
+	for _, namespaceStatus := range namespaceStatuses {
+		switch {
+		case namespaceStatus.State == managedv1alpha1.GroupPermissionCreated:
+			applied++
+		case isBindingFailed(namespaceStatus.State):
+			recordFailure(namespaceStatus.Namespace + "/" + namespaceStatus.BindingName)
+		}
+	}
+	return applied, failed, failedSample
+}
+
+// applicationState returns the application state of bindings of which applied were applied and
+// failed failed
+func applicationState(applied, failed int) string {
+	switch {
+	case failed == 0:
+		return localmetrics.ApplicationApplied
+	case applied == 0:
+		return localmetrics.ApplicationFailed
+	}
+	return localmetrics.ApplicationPartial
+}
+
+// updatePartiallyApplied activates the PartiallyApplied condition of groupPermission when some of
+// its bindings were applied and others failed, and deactivates it otherwise. Returns whether the
+// conditions changed.
+func updatePartiallyApplied(groupPermission *managedv1alpha1.GroupPermission, applied, failed int, failedSample []string) bool {
+	if applicationState(applied, failed) != localmetrics.ApplicationPartial {
+		if !conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonPartiallyApplied) {
+			return false
+		}
+		conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonPartiallyApplied)
+		return true
+	}
+
+	message := fmt.Sprintf("%d of %d bindings failed: %s", failed, applied+failed, strings.Join(failedSample, ", "))
+	if failed > len(failedSample) {
+		message += ", ..."
+	}
+	for _, condition := range groupPermission.Status.Conditions {
+		if condition.Reason == managedv1alpha1.ReasonPartiallyApplied && condition.Status && condition.Message == message {
+			return false
+		}
+	}
+	updateCondition(groupPermission, message, "", true, managedv1alpha1.GroupPermissionPartiallyApplied, managedv1alpha1.ReasonPartiallyApplied)
+	return true
+}
@@ -0,0 +1,129 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestBindingApplication tests the bindingApplication and applicationState functions
+// given: ClusterPermissions and Namespaces with created and failed bindings
+// expected: the counts, the sample of the failed bindings and the application state
+func TestBindingApplication(t *testing.T) {
+	groupPermission := mockGroupPermission()
+	groupPermission.Status.Conditions = []v1alpha1.Condition{
+		{ClusterRoleName: "exampleClusterRoleName", Status: true, State: v1alpha1.GroupPermissionCreated},
+		{ClusterRoleName: "exampleClusterRoleNameTwo", Status: true, State: v1alpha1.GroupPermissionEscalationDenied},
+	}
+	namespaceStatuses := []v1alpha1.NamespaceStatus{
+		{Namespace: "team-a", BindingName: "admin-exampleGroupName", State: v1alpha1.GroupPermissionCreated},
+		{Namespace: "team-b", BindingName: "admin-exampleGroupName", State: v1alpha1.GroupPermissionFailed},
+		{Namespace: "team-c", BindingName: "admin-exampleGroupName", State: v1alpha1.GroupPermissionFailed},
+	}
+
+	applied, failed, failedSample := bindingApplication(groupPermission, namespaceStatuses, 2)
+	if applied != 2 || failed != 3 {
+		t.Errorf("expected 2 applied and 3 failed, got %d and %d", applied, failed)
+	}
+	expected := "exampleClusterRoleNameTwo-exampleGroupName,team-b/admin-exampleGroupName"
+	if strings.Join(failedSample, ",") != expected {
+		t.Errorf("expected sample %s, got %v", expected, failedSample)
+	}
+
+	tests := []struct {
+		applied, failed int
+		state           string
+	}{
+		{2, 0, localmetrics.ApplicationApplied},
+		{0, 0, localmetrics.ApplicationApplied},
+		{2, 3, localmetrics.ApplicationPartial},
+		{0, 3, localmetrics.ApplicationFailed},
+	}
+	for _, test := range tests {
+		if state := applicationState(test.applied, test.failed); state != test.state {
+			t.Errorf("%d applied, %d failed: got %s, want %s", test.applied, test.failed, state, test.state)
+		}
+	}
+}
+
+// TestUpdatePartiallyApplied tests the updatePartiallyApplied function
+// given: a partial application, the same again, then a complete one
+// expected: the condition activated once, then deactivated
+func TestUpdatePartiallyApplied(t *testing.T) {
+	groupPermission := mockGroupPermission()
+
+	if !updatePartiallyApplied(groupPermission, 1, 3, []string{"team-b/admin-exampleGroupName", "team-c/admin-exampleGroupName"}) {
+		t.Fatalf("expected the condition to change")
+	}
+	condition := groupPermission.Status.Conditions[len(groupPermission.Status.Conditions)-1]
+	if condition.Reason != v1alpha1.ReasonPartiallyApplied || !condition.Status || condition.Message != "3 of 4 bindings failed: team-b/admin-exampleGroupName, team-c/admin-exampleGroupName, ..." {
+		t.Errorf("unexpected condition %v", condition)
+	}
+	if updatePartiallyApplied(groupPermission, 1, 3, []string{"team-b/admin-exampleGroupName", "team-c/admin-exampleGroupName"}) {
+		t.Errorf("expected an unchanged application to leave the condition")
+	}
+
+	if !updatePartiallyApplied(groupPermission, 4, 0, nil) {
+		t.Fatalf("expected the condition to be deactivated")
+	}
+	for _, condition := range groupPermission.Status.Conditions {
+		if condition.Reason == v1alpha1.ReasonPartiallyApplied && condition.Status {
+			t.Errorf("expected no active PartiallyApplied condition, got %v", condition)
+		}
+	}
+	if updatePartiallyApplied(groupPermission, 4, 0, nil) {
+		t.Errorf("expected no change without an active condition")
+	}
+}
+
+// TestReconcilePartiallyApplied tests that Reconcile reports a partial application
+// given: a GroupPermission granting an existing and a missing ClusterRole in the same Namespaces
+// expected: an active PartiallyApplied condition
+func TestReconcilePartiallyApplied(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	groupPermission.Spec.Permissions = append(groupPermission.Spec.Permissions, v1alpha1.Permission{
+		ClusterRoleName:        "edit",
+		NamespacesAllowedRegex: "^team-a$",
+		AllowFirst:             true,
+	})
+	admin := mockClusterRole()
+	admin.Name = "admin"
+	fakeClient := newRequesterClient(groupPermission, admin, mockNamespace("team-a"))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	if _, err := reconciler.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reconciled := &v1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), key, reconciled); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, condition := range reconciled.Status.Conditions {
+		if condition.Reason == v1alpha1.ReasonPartiallyApplied && condition.Status {
+			if condition.Message != "1 of 2 bindings failed: team-a/edit-exampleGroupName" {
+				t.Errorf("unexpected message %q", condition.Message)
+			}
+			return
+		}
+	}
+	t.Errorf("expected an active PartiallyApplied condition, got %v", reconciled.Status.Conditions)
+}
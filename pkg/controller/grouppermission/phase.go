@@ -0,0 +1,106 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// groupPermissionPhase derives the phase of groupPermission from its conditions and the states of its
+// RoleBindings. converged is whether every binding of the latest spec was applied.
+func groupPermissionPhase(groupPermission *managedv1alpha1.GroupPermission, converged bool) managedv1alpha1.GroupPermissionPhase {
+	if conditions.IsStateTrue(groupPermission.Status.Conditions, managedv1alpha1.GroupPermissionDegraded) ||
+		conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonReconcilePanicked) ||
+		conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonInvalidPermission) ||
+		conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonDependencyCycle) ||
+		conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonAtomicRollback) {
+		return managedv1alpha1.GroupPermissionPhaseFailed
+	}
+
+	// in strict unmanaged access mode the condition fails the GroupPermission, it only warns otherwise
+	for _, condition := range groupPermission.Status.Conditions {
+		if condition.Reason == managedv1alpha1.ReasonUnmanagedAccess && condition.Status && condition.State == managedv1alpha1.GroupPermissionFailed {
+			return managedv1alpha1.GroupPermissionPhaseFailed
+		}
+	}
+
+	for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+		condition := conditions.Get(groupPermission.Status.Conditions, clusterRoleName)
+		if condition != nil && condition.Status && isBindingFailed(condition.State) {
+			return managedv1alpha1.GroupPermissionPhaseFailed
+		}
+	}
+
+	for _, namespaceStatus := range groupPermission.Status.Namespaces {
+		if isBindingFailed(namespaceStatus.State) {
+			return managedv1alpha1.GroupPermissionPhaseFailed
+		}
+	}
+	if summary := groupPermission.Status.NamespaceSummary; summary != nil && summary.Failed > 0 {
+		return managedv1alpha1.GroupPermissionPhaseFailed
+	}
+
+	// until its dependencies are Active the bindings of its latest spec are not applied
+	if conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonDependencyNotReady) {
+		return managedv1alpha1.GroupPermissionPhasePending
+	}
+	// outside of the windows of its schedule the bindings are removed until the next window
+	if schedule := groupPermission.Status.Schedule; schedule != nil && !schedule.Active {
+		return managedv1alpha1.GroupPermissionPhasePending
+	}
+	// on a cluster not matching its clusterConditions the bindings are removed until it does
+	if matched := groupPermission.Status.ClusterConditionsMatched; matched != nil && !*matched {
+		return managedv1alpha1.GroupPermissionPhasePending
+	}
+	if converged {
+		return managedv1alpha1.GroupPermissionPhaseActive
+	}
+	return managedv1alpha1.GroupPermissionPhasePending
+}
+
+// updatePhase sets the phase and the clusterPermissions status of the GroupPermission of request. The
+// bindings are converged when the last applied hash matches the desired state of the current spec.
+func (r *ReconcileGroupPermission) updatePhase(request reconcile.Request) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		if !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Failed to get GroupPermission")
+		}
+		return
+	}
+	if instance.DeletionTimestamp != nil {
+		return
+	}
+
+	namespaceList, err := r.listNamespaces(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to get namespaceList")
+		return
+	}
+	converged := instance.Annotations[operatorconfig.LastAppliedHashAnnotation] == desiredStateHash(instance, namespaceList)
+
+	clusterPermissions, err := r.clusterPermissionStatuses(instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to get clusterPermissions status")
+		return
+	}
+
+	phase := groupPermissionPhase(instance, converged)
+	r.traces.annotate(instance, phase, len(namespaceList.Items))
+	if instance.Status.Phase == phase && reflect.DeepEqual(instance.Status.ClusterPermissions, clusterPermissions) {
+		return
+	}
+	instance.Status.Phase = phase
+	instance.Status.ClusterPermissions = clusterPermissions
+	if err := r.updateStatus(instance); err != nil {
+		reqLogger.Error(err, "Failed to update phase.")
+	}
+}
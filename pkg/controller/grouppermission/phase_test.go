@@ -0,0 +1,163 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestGroupPermissionPhase tests the groupPermissionPhase function
+// given: GroupPermissions with various conditions and RoleBinding states
+// expected: Failed on any current failure, otherwise Active once converged and Pending before
+func TestGroupPermissionPhase(t *testing.T) {
+	condition := func(clusterRoleName string, state v1alpha1.GroupPermissionState, status bool) v1alpha1.Condition {
+		return v1alpha1.Condition{ClusterRoleName: clusterRoleName, State: state, Status: status}
+	}
+
+	tests := []struct {
+		name       string
+		conditions []v1alpha1.Condition
+		namespaces []v1alpha1.NamespaceStatus
+		summary    *v1alpha1.NamespaceSummary
+		converged  bool
+		expected   v1alpha1.GroupPermissionPhase
+	}{
+		{
+			name:     "no conditions, not converged",
+			expected: v1alpha1.GroupPermissionPhasePending,
+		},
+		{
+			name:      "no conditions, converged",
+			converged: true,
+			expected:  v1alpha1.GroupPermissionPhaseActive,
+		},
+		{
+			name:       "failed ClusterRole",
+			conditions: []v1alpha1.Condition{condition("exampleClusterRoleName", v1alpha1.GroupPermissionFailed, true)},
+			converged:  true,
+			expected:   v1alpha1.GroupPermissionPhaseFailed,
+		},
+		{
+			name:       "escalation denied",
+			conditions: []v1alpha1.Condition{condition("exampleClusterRoleNameTwo", v1alpha1.GroupPermissionEscalationDenied, true)},
+			expected:   v1alpha1.GroupPermissionPhaseFailed,
+		},
+		{
+			name: "failure followed by creation",
+			conditions: []v1alpha1.Condition{
+				condition("exampleClusterRoleName", v1alpha1.GroupPermissionFailed, true),
+				condition("exampleClusterRoleName", v1alpha1.GroupPermissionCreated, true),
+			},
+			converged: true,
+			expected:  v1alpha1.GroupPermissionPhaseActive,
+		},
+		{
+			name:       "failure of a ClusterRole no longer granted",
+			conditions: []v1alpha1.Condition{condition("removedClusterRoleName", v1alpha1.GroupPermissionFailed, true)},
+			converged:  true,
+			expected:   v1alpha1.GroupPermissionPhaseActive,
+		},
+		{
+			name:       "active Degraded",
+			conditions: []v1alpha1.Condition{condition("", v1alpha1.GroupPermissionDegraded, true)},
+			converged:  true,
+			expected:   v1alpha1.GroupPermissionPhaseFailed,
+		},
+		{
+			name:       "cleared Degraded",
+			conditions: []v1alpha1.Condition{condition("", v1alpha1.GroupPermissionDegraded, false)},
+			converged:  true,
+			expected:   v1alpha1.GroupPermissionPhaseActive,
+		},
+		{
+			name:       "failed RoleBinding",
+			namespaces: []v1alpha1.NamespaceStatus{{Namespace: "team-a", State: v1alpha1.GroupPermissionFailed}},
+			converged:  true,
+			expected:   v1alpha1.GroupPermissionPhaseFailed,
+		},
+		{
+			name:       "waiting for a dependency",
+			conditions: []v1alpha1.Condition{{State: v1alpha1.GroupPermissionWaiting, Reason: v1alpha1.ReasonDependencyNotReady, Status: true}},
+			converged:  true,
+			expected:   v1alpha1.GroupPermissionPhasePending,
+		},
+		{
+			name:       "dependency cycle",
+			conditions: []v1alpha1.Condition{{State: v1alpha1.GroupPermissionFailed, Reason: v1alpha1.ReasonDependencyCycle, Status: true}},
+			converged:  true,
+			expected:   v1alpha1.GroupPermissionPhaseFailed,
+		},
+		{
+			name:      "failed RoleBinding in summary",
+			summary:   &v1alpha1.NamespaceSummary{Total: 200, Created: 199, Failed: 1},
+			converged: true,
+			expected:  v1alpha1.GroupPermissionPhaseFailed,
+		},
+	}
+
+	for _, test := range tests {
+		groupPermission := mockGroupPermission()
+		groupPermission.Status.Conditions = test.conditions
+		groupPermission.Status.Namespaces = test.namespaces
+		groupPermission.Status.NamespaceSummary = test.summary
+		if phase := groupPermissionPhase(groupPermission, test.converged); phase != test.expected {
+			t.Errorf("%s: got %s, want %s", test.name, phase, test.expected)
+		}
+	}
+}
+
+// TestReconcilePhase tests that Reconcile keeps the phase consistent with the conditions
+// given: GroupPermission whose RoleBinding is applied, then with an active Degraded condition
+// expected: the phase is Active, then Failed
+func TestReconcilePhase(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	groupPermission.Finalizers = []string{operatorconfig.GroupPermissionFinalizer}
+	adminClusterRole := mockClusterRole()
+	adminClusterRole.Name = "admin"
+	fakeClient := newRequesterClient(groupPermission, adminClusterRole, mockNamespace("team-a"))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	if _, err := reconciler.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reconciled := &v1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), key, reconciled); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reconciled.Status.Phase != v1alpha1.GroupPermissionPhaseActive {
+		t.Errorf("expected phase Active, got %q", reconciled.Status.Phase)
+	}
+
+	reconciled.Status.Conditions = append(reconciled.Status.Conditions, v1alpha1.Condition{
+		State:  v1alpha1.GroupPermissionDegraded,
+		Status: true,
+	})
+	if err := fakeClient.Status().Update(context.TODO(), reconciled); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reconciler.updatePhase(reconcile.Request{NamespacedName: key})
+	if err := fakeClient.Get(context.TODO(), key, reconciled); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reconciled.Status.Phase != v1alpha1.GroupPermissionPhaseFailed {
+		t.Errorf("expected phase Failed, got %q", reconciled.Status.Phase)
+	}
+}
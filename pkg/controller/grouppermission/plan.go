@@ -0,0 +1,163 @@
+package grouppermission
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// planEventReason is the reason of the event summarizing the changes planned for a generation
+	planEventReason = "Plan"
+	// appliedEventReason is the reason of the event emitted once the bindings of a plan are applied
+	appliedEventReason = "Applied"
+)
+
+// planHashLength is the number of characters of the plan hash shown in events
+const planHashLength = 12
+
+// reconcilePlan plans the changes to the bindings of groupPermission when its generation was not
+// planned yet, records the plan in the status and emits a Plan event. It returns whether the status
+// changed.
+func (r *ReconcileGroupPermission) reconcilePlan(groupPermission *managedv1alpha1.GroupPermission, clusterRoleList *v1.ClusterRoleList, now time.Time) (bool, error) {
+	if groupPermission.Status.Plan != nil && groupPermission.Status.Plan.Generation == groupPermission.Generation {
+		return false, nil
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{}, namespaceList); err != nil {
+		return false, err
+	}
+	clusterRoleBindingList, roleBindingList, err := r.listManagedBindings(groupPermission)
+	if err != nil {
+		return false, err
+	}
+
+	drift := planBindings(groupPermission, namespaceList, clusterRoleList, clusterRoleBindingList, roleBindingList, now)
+	plan := &managedv1alpha1.PlanStatus{
+		Generation: groupPermission.Generation,
+		Hash:       planHash(groupPermission.Generation, drift),
+		Create:     len(drift.Missing),
+		Delete:     len(drift.Unexpected),
+		Update:     len(drift.Changed),
+	}
+	groupPermission.Status.Plan = plan
+	r.eventf(groupPermission, corev1.EventTypeNormal, planEventReason, "Plan %s for generation %d: %s",
+		plan.Hash[:planHashLength], plan.Generation, planSummary(plan))
+	return true, nil
+}
+
+// recordPlanApplied marks the plan of groupPermission applied and emits an Applied event, unless it
+// was already. It returns whether the status changed.
+func (r *ReconcileGroupPermission) recordPlanApplied(groupPermission *managedv1alpha1.GroupPermission) bool {
+	plan := groupPermission.Status.Plan
+	if plan == nil || plan.Applied {
+		return false
+	}
+	plan.Applied = true
+	r.eventf(groupPermission, corev1.EventTypeNormal, appliedEventReason, "Applied plan %s for generation %d: %s",
+		plan.Hash[:planHashLength], plan.Generation, planSummary(plan))
+	return true
+}
+
+// listManagedBindings returns the ClusterRoleBindings and RoleBindings labeled as managed for groupPermission
+func (r *ReconcileGroupPermission) listManagedBindings(groupPermission *managedv1alpha1.GroupPermission) (*v1.ClusterRoleBindingList, *v1.RoleBindingList, error) {
+	opts := &client.ListOptions{LabelSelector: utility.ManagedForSelector(groupPermission.Namespace, groupPermission.Name)}
+	clusterRoleBindingList := &v1.ClusterRoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), opts, clusterRoleBindingList); err != nil {
+		return nil, nil, err
+	}
+	roleBindingList := &v1.RoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), opts, roleBindingList); err != nil {
+		return nil, nil, err
+	}
+	return clusterRoleBindingList, roleBindingList, nil
+}
+
+// planBindings returns the changes to the bindings of groupPermission, from its managed bindings to
+// the bindings it resolves to at now
+func planBindings(groupPermission *managedv1alpha1.GroupPermission, namespaceList *corev1.NamespaceList, clusterRoleList *v1.ClusterRoleList,
+	clusterRoleBindingList *v1.ClusterRoleBindingList, roleBindingList *v1.RoleBindingList, now time.Time) *Drift {
+	desired, valid := resolveBindings(groupPermission, namespaceList, clusterRoleList, now)
+	if !valid {
+		// the bindings of an invalid GroupPermission are left as they are
+		return &Drift{}
+	}
+	actual, managed := managedBindingsOf(groupPermission, clusterRoleBindingList, roleBindingList)
+	return diffBindings(desired, actual, managed)
+}
+
+// managedBindingsOf returns the bindings of clusterRoleBindingList and roleBindingList managed for
+// groupPermission, by name, and the set of their names
+func managedBindingsOf(groupPermission *managedv1alpha1.GroupPermission, clusterRoleBindingList *v1.ClusterRoleBindingList,
+	roleBindingList *v1.RoleBindingList) (map[string]verifiedBinding, map[string]bool) {
+	actual := map[string]verifiedBinding{}
+	managed := map[string]bool{}
+	for _, binding := range clusterRoleBindingList.Items {
+		if utility.IsManagedFor(binding.ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+			name := "ClusterRoleBinding " + binding.Name
+			actual[name] = verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects}
+			managed[name] = true
+		}
+	}
+	for _, binding := range roleBindingList.Items {
+		if utility.IsManagedFor(binding.ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+			name := "RoleBinding " + binding.Namespace + "/" + binding.Name
+			actual[name] = verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects}
+			managed[name] = true
+		}
+	}
+	return actual, managed
+}
+
+// recordAppliedGeneration sets the appliedGeneration of groupPermission to its generation once every
+// binding it resolves to at now is verified to exist in the apiserver with its role and subjects. The
+// managed bindings it no longer resolves to do not hold it back. It returns whether the status changed.
+func (r *ReconcileGroupPermission) recordAppliedGeneration(groupPermission *managedv1alpha1.GroupPermission, clusterRoleList *v1.ClusterRoleList, now time.Time) (bool, error) {
+	if groupPermission.Status.AppliedGeneration == groupPermission.Generation {
+		return false, nil
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{}, namespaceList); err != nil {
+		return false, err
+	}
+	desired, valid := resolveBindings(groupPermission, namespaceList, clusterRoleList, now)
+	if !valid {
+		return false, nil
+	}
+	clusterRoleBindingList, roleBindingList, err := r.listManagedBindings(groupPermission)
+	if err != nil {
+		return false, err
+	}
+	actual, managed := managedBindingsOf(groupPermission, clusterRoleBindingList, roleBindingList)
+	if drift := diffBindings(desired, actual, managed); len(drift.Missing) > 0 || len(drift.Changed) > 0 {
+		return false, nil
+	}
+	groupPermission.Status.AppliedGeneration = groupPermission.Generation
+	return true, nil
+}
+
+// planHash returns a hash of the changes of drift planned for generation
+func planHash(generation int64, drift *Drift) string {
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "generation %d\n", generation)
+	drift.Write(out)
+	sum := sha256.Sum256(out.Bytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// planSummary returns the number of bindings plan creates, deletes and updates as +N -M ~K
+func planSummary(plan *managedv1alpha1.PlanStatus) string {
+	return fmt.Sprintf("+%d -%d ~%d bindings", plan.Create, plan.Delete, plan.Update)
+}
@@ -0,0 +1,188 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestPlanBindings tests the planBindings function
+// given: a GroupPermission allowed in two Namespaces, a managed RoleBinding up to date, one changed,
+// one in a Namespace no longer allowed and one of another GroupPermission
+// expected: the missing, changed and unexpected bindings of the GroupPermission only
+func TestPlanBindings(t *testing.T) {
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	admin := mockClusterRole()
+	admin.Name = "admin"
+	namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{*mockNamespace("team-a"), *mockNamespace("team-b"), *mockNamespace("team-c")}}
+
+	managedRoleBinding := func(namespace string, roleName string) rbacv1.RoleBinding {
+		roleBinding := newRoleBinding(namespace, rbacv1.RoleRef{Kind: "ClusterRole", Name: roleName}, mockGroupSubject("exampleGroupName"))
+		roleBinding.Name = "admin-exampleGroupName"
+		utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+		return *roleBinding
+	}
+	other := managedRoleBinding("team-b", "admin")
+	utility.SetManagedLabels(&other.ObjectMeta, groupPermission.Namespace, "otherGroupPermission")
+	roleBindingList := &rbacv1.RoleBindingList{Items: []rbacv1.RoleBinding{
+		managedRoleBinding("team-a", "admin"),
+		managedRoleBinding("team-c", "edit"),
+		managedRoleBinding("team-old", "admin"),
+		other,
+	}}
+
+	drift := planBindings(groupPermission, namespaceList, &rbacv1.ClusterRoleList{Items: []rbacv1.ClusterRole{*admin}},
+		&rbacv1.ClusterRoleBindingList{}, roleBindingList, time.Now())
+	if strings.Join(drift.Missing, ",") != "RoleBinding team-b/admin-exampleGroupName" {
+		t.Errorf("unexpected missing bindings %v", drift.Missing)
+	}
+	if strings.Join(drift.Changed, ",") != "RoleBinding team-c/admin-exampleGroupName" {
+		t.Errorf("unexpected changed bindings %v", drift.Changed)
+	}
+	if strings.Join(drift.Unexpected, ",") != "RoleBinding team-old/admin-exampleGroupName" {
+		t.Errorf("unexpected unexpected bindings %v", drift.Unexpected)
+	}
+}
+
+// TestPlanHash tests the planHash function
+// given: the same drift for the same and for another generation, and another drift
+// expected: the same hash for the same plan only
+func TestPlanHash(t *testing.T) {
+	drift := &Drift{Missing: []string{"RoleBinding team-a/admin-exampleGroupName"}}
+	hash := planHash(1, drift)
+	if planHash(1, &Drift{Missing: []string{"RoleBinding team-a/admin-exampleGroupName"}}) != hash {
+		t.Errorf("expected the same hash for the same plan")
+	}
+	if planHash(2, drift) == hash {
+		t.Errorf("expected another hash for another generation")
+	}
+	if planHash(1, &Drift{Unexpected: []string{"RoleBinding team-a/admin-exampleGroupName"}}) == hash {
+		t.Errorf("expected another hash for another drift")
+	}
+}
+
+// TestReconcilePlanEvents tests that Reconcile plans the changes of each generation
+// given: a GroupPermission reconciled twice, then again with a new generation
+// expected: a Plan and an Applied event for each generation only, the plan recorded in the status
+func TestReconcilePlanEvents(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	groupPermission.Finalizers = []string{operatorconfig.GroupPermissionFinalizer}
+	groupPermission.Generation = 1
+	admin := mockClusterRole()
+	admin.Name = "admin"
+	fakeClient := newRequesterClient(groupPermission, admin, mockNamespace("team-a"), mockNamespace("team-b"))
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  recorder,
+	}
+
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	reconcileTwice := func() {
+		for i := 0; i < 2; i++ {
+			if _, err := reconciler.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+	expectEvents := func(expected ...string) {
+		for _, prefix := range expected {
+			select {
+			case event := <-recorder.Events:
+				if !strings.HasPrefix(event, prefix) {
+					t.Errorf("got event %q, want %q", event, prefix)
+				}
+			default:
+				t.Errorf("missing event %q", prefix)
+			}
+		}
+		select {
+		case event := <-recorder.Events:
+			t.Errorf("unexpected event %q", event)
+		default:
+		}
+	}
+
+	reconcileTwice()
+	reconciled := &v1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), key, reconciled); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plan := reconciled.Status.Plan
+	if plan == nil || plan.Generation != 1 || plan.Create != 2 || plan.Delete != 0 || plan.Update != 0 || !plan.Applied {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	if reconciled.Status.AppliedGeneration != 1 {
+		t.Errorf("Mismatch for appliedGeneration. Expected(1), Found(%d)", reconciled.Status.AppliedGeneration)
+	}
+	expectEvents("Normal Plan Plan "+plan.Hash[:planHashLength]+" for generation 1: +2 -0 ~0 bindings",
+		"Normal Applied Applied plan "+plan.Hash[:planHashLength])
+
+	reconciled.Generation = 2
+	reconciled.Spec.Permissions[0].NamespacesAllowedRegex = "^team-a$"
+	if err := fakeClient.Update(context.TODO(), reconciled); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reconcileTwice()
+	expectEvents("Normal Plan Plan", "Normal Applied")
+}
+
+// TestRecordAppliedGeneration tests the recordAppliedGeneration function
+// given: a GroupPermission of generation 3 allowed in team-a and team-b, with the RoleBinding of team-a
+// only, then of both
+// expected: the generation is recorded applied only once both RoleBindings exist
+func TestRecordAppliedGeneration(t *testing.T) {
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	groupPermission.Generation = 3
+	admin := mockClusterRole()
+	admin.Name = "admin"
+	clusterRoleList := &rbacv1.ClusterRoleList{Items: []rbacv1.ClusterRole{*admin}}
+	managedRoleBinding := func(namespace string) *rbacv1.RoleBinding {
+		roleBinding := newRoleBinding(namespace, rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, mockGroupSubject("exampleGroupName"))
+		utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+		return roleBinding
+	}
+	fakeClient := fake.NewFakeClient(mockNamespace("team-a"), mockNamespace("team-b"), managedRoleBinding("team-a"))
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+
+	changed, err := reconciler.recordAppliedGeneration(groupPermission, clusterRoleList, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changed || groupPermission.Status.AppliedGeneration != 0 {
+		t.Errorf("Mismatch for appliedGeneration with a missing RoleBinding. Expected(0), Found(%d)", groupPermission.Status.AppliedGeneration)
+	}
+
+	if err := fakeClient.Create(context.TODO(), managedRoleBinding("team-b")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changed, err = reconciler.recordAppliedGeneration(groupPermission, clusterRoleList, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed || groupPermission.Status.AppliedGeneration != 3 {
+		t.Errorf("Mismatch for appliedGeneration. Expected(3), Found(%d)", groupPermission.Status.AppliedGeneration)
+	}
+}
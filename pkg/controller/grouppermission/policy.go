@@ -0,0 +1,124 @@
+package grouppermission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	v1 "k8s.io/api/rbac/v1"
+)
+
+// policyBinding is a binding evaluated against the policy, sent as the input of the OPA query
+type policyBinding struct {
+	Subject v1.Subject `json:"subject"`
+	RoleRef v1.RoleRef `json:"roleRef"`
+	// Namespace of a RoleBinding, empty for a ClusterRoleBinding
+	Namespace       string      `json:"namespace,omitempty"`
+	GroupPermission policyOwner `json:"groupPermission"`
+}
+
+// policyOwner identifies the GroupPermission a binding is managed for
+type policyOwner struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// policyDecision is the decision of the policy on a binding
+type policyDecision struct {
+	Allowed bool `json:"allowed"`
+	// Message describing why the binding is denied
+	Message string `json:"message,omitempty"`
+}
+
+// policyClient evaluates bindings against the OPA policy configured in the operator config, through
+// the OPA data API. The policy document must be either a boolean or a policyDecision.
+type policyClient struct {
+	httpClient *http.Client
+	endpoint   string
+	timeout    time.Duration
+}
+
+// newPolicyClient returns the policyClient of operatorConfig, or nil when no policy is configured
+func newPolicyClient(httpClient *http.Client, operatorConfig *operatorconfig.OperatorConfig) *policyClient {
+	if operatorConfig.PolicyEndpoint == "" {
+		return nil
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &policyClient{httpClient: httpClient, endpoint: operatorConfig.PolicyEndpoint, timeout: operatorConfig.PolicyTimeout}
+}
+
+// newPolicyBinding returns the binding of roleRef to subject in namespace managed for groupPermission
+func newPolicyBinding(groupPermission *managedv1alpha1.GroupPermission, subject v1.Subject, roleRef v1.RoleRef, namespace string) policyBinding {
+	return policyBinding{
+		Subject:         subject,
+		RoleRef:         roleRef,
+		Namespace:       namespace,
+		GroupPermission: policyOwner{Namespace: groupPermission.Namespace, Name: groupPermission.Name},
+	}
+}
+
+// evaluate returns the decision of the policy on binding. Every binding is allowed when c is nil.
+// An undefined policy document denies the binding, errors never allow it.
+func (c *policyClient) evaluate(binding policyBinding) (policyDecision, error) {
+	if c == nil {
+		return policyDecision{Allowed: true}, nil
+	}
+
+	body, err := json.Marshal(map[string]policyBinding{"input": binding})
+	if err != nil {
+		return policyDecision{}, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return policyDecision{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), c.timeout)
+	defer cancel()
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return policyDecision{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return policyDecision{}, fmt.Errorf("policy endpoint returned %s", resp.Status)
+	}
+
+	response := struct {
+		Result *json.RawMessage `json:"result"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return policyDecision{}, fmt.Errorf("failed to decode policy response: %v", err)
+	}
+	if response.Result == nil {
+		return policyDecision{Message: "policy is undefined"}, nil
+	}
+
+	decision := policyDecision{}
+	if err := json.Unmarshal(*response.Result, &decision.Allowed); err == nil {
+		return decision, nil
+	}
+	if err := json.Unmarshal(*response.Result, &decision); err != nil {
+		return policyDecision{}, fmt.Errorf("failed to decode policy decision: %v", err)
+	}
+	return decision, nil
+}
+
+// policyDeniedMessage returns the message recorded for a binding denied by decision
+func policyDeniedMessage(decision policyDecision) string {
+	if decision.Message == "" {
+		return "Denied by policy"
+	}
+	return "Denied by policy: " + decision.Message
+}
@@ -0,0 +1,120 @@
+package grouppermission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// newPolicyServer returns an OPA server answering every query with response, recording the inputs in inputs
+func newPolicyServer(t *testing.T, status int, response string, inputs *[]policyBinding) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		query := struct {
+			Input policyBinding `json:"input"`
+		}{}
+		if err := json.NewDecoder(req.Body).Decode(&query); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if inputs != nil {
+			*inputs = append(*inputs, query.Input)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+}
+
+// TestPolicyEvaluate tests the evaluate function
+// given: responses of the policy endpoint
+// expected: the decision of the policy, undefined policies deny and failures return an error
+func TestPolicyEvaluate(t *testing.T) {
+	tests := []struct {
+		label    string
+		status   int
+		response string
+		valid    bool
+		decision policyDecision
+	}{
+		{"boolean allowed", http.StatusOK, `{"result": true}`, true, policyDecision{Allowed: true}},
+		{"boolean denied", http.StatusOK, `{"result": false}`, true, policyDecision{}},
+		{"decision denied", http.StatusOK, `{"result": {"allowed": false, "message": "no admin in prod"}}`, true, policyDecision{Message: "no admin in prod"}},
+		{"undefined", http.StatusOK, `{}`, true, policyDecision{Message: "policy is undefined"}},
+		{"server error", http.StatusInternalServerError, `{}`, false, policyDecision{}},
+		{"invalid response", http.StatusOK, `{"result": "yes"}`, false, policyDecision{}},
+	}
+
+	groupPermission := mockGroupPermission()
+	binding := newPolicyBinding(groupPermission, rbacv1.Subject{Kind: "Group", Name: "exampleGroupName"}, rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, "team-a")
+	for _, test := range tests {
+		var inputs []policyBinding
+		server := newPolicyServer(t, test.status, test.response, &inputs)
+		policy := newPolicyClient(nil, &operatorconfig.OperatorConfig{PolicyEndpoint: server.URL, PolicyTimeout: time.Second})
+
+		decision, err := policy.evaluate(binding)
+		server.Close()
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if decision != test.decision {
+			t.Errorf("%s: got %+v, want %+v", test.label, decision, test.decision)
+		}
+		if len(inputs) != 1 || inputs[0] != binding {
+			t.Errorf("%s: expected the binding as input, got %+v", test.label, inputs)
+		}
+	}
+
+	var policy *policyClient
+	if decision, err := policy.evaluate(binding); err != nil || !decision.Allowed {
+		t.Errorf("expected every binding to be allowed without a policy, got %+v %v", decision, err)
+	}
+	if newPolicyClient(nil, operatorconfig.DefaultOperatorConfig()) != nil {
+		t.Errorf("expected no policy client without an endpoint")
+	}
+}
+
+// TestReconcileNamespacePermissionsPolicyDenied tests the reconcileNamespacePermissions function
+// given: a GroupPermission allowed in Namespaces and a policy denying every binding
+// expected: no RoleBinding is created and each NamespaceStatus records the policy message
+func TestReconcileNamespacePermissionsPolicyDenied(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	server := newPolicyServer(t, http.StatusOK, `{"result": {"allowed": false, "message": "no admin for teams"}}`, nil)
+	defer server.Close()
+	policy := newPolicyClient(nil, &operatorconfig.OperatorConfig{PolicyEndpoint: server.URL, PolicyTimeout: time.Second})
+
+	fakeClient := newRequesterClient(mockNamespace("team-a"), mockNamespace("team-b"))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+	}
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(mockNamespacedGroupPermission(), nil, nil, policy, operatorconfig.DefaultOperatorConfig())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaceStatuses) != 2 {
+		t.Fatalf("got %d namespace statuses, want 2: %v", len(namespaceStatuses), namespaceStatuses)
+	}
+	for _, namespaceStatus := range namespaceStatuses {
+		if namespaceStatus.State != v1alpha1.GroupPermissionFailed || namespaceStatus.LastError != "Denied by policy: no admin for teams" {
+			t.Errorf("expected %s to be denied by the policy, got %+v", namespaceStatus.Namespace, namespaceStatus)
+		}
+		err := reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: namespaceStatus.Namespace, Name: namespaceStatus.BindingName}, &rbacv1.RoleBinding{})
+		if !errors.IsNotFound(err) {
+			t.Errorf("expected no RoleBinding in %s, got %v", namespaceStatus.Namespace, err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package grouppermission
+
+import (
+	"time"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/openshift/rbac-permissions-operator/pkg/readonly"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// propagationTrigger returns what the RoleBinding of groupPermission in namespace was created for and
+// when it happened: the creation of namespace, or of groupPermission when it is newer. The changes of
+// the spec are not timestamped, a RoleBinding created for a later generation has no trigger.
+func propagationTrigger(groupPermission *managedv1alpha1.GroupPermission, namespace *corev1.Namespace) (string, time.Time, bool) {
+	if namespace.CreationTimestamp.IsZero() || groupPermission.CreationTimestamp.IsZero() {
+		return "", time.Time{}, false
+	}
+	if namespace.CreationTimestamp.After(groupPermission.CreationTimestamp.Time) {
+		return localmetrics.TriggerNamespace, namespace.CreationTimestamp.Time, true
+	}
+	if groupPermission.Generation > 1 {
+		return "", time.Time{}, false
+	}
+	return localmetrics.TriggerGroupPermission, groupPermission.CreationTimestamp.Time, true
+}
+
+// observePropagation records the time from the trigger of the RoleBinding of groupPermission in
+// namespace to now, when it was created
+func observePropagation(groupPermission *managedv1alpha1.GroupPermission, namespace *corev1.Namespace, now time.Time) {
+	// nothing is created in read-only mode
+	if readonly.Enabled() {
+		return
+	}
+	if trigger, triggered, ok := propagationTrigger(groupPermission, namespace); ok {
+		localmetrics.ObserveBindingPropagation(trigger, now.Sub(triggered))
+	}
+}
@@ -0,0 +1,44 @@
+package grouppermission
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestPropagationTrigger tests the propagationTrigger function
+// given: Namespaces created before and after a GroupPermission, at its first and at a later generation
+// expected: the creation of the newer of the two is the trigger, none for a later generation of the
+// GroupPermission or a missing creation time
+func TestPropagationTrigger(t *testing.T) {
+	created := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		label      string
+		namespace  time.Time
+		generation int64
+		trigger    string
+		triggered  time.Time
+	}{
+		{"new namespace", created.Add(time.Hour), 3, localmetrics.TriggerNamespace, created.Add(time.Hour)},
+		{"new grouppermission", created.Add(-time.Hour), 1, localmetrics.TriggerGroupPermission, created},
+		{"changed grouppermission", created.Add(-time.Hour), 2, "", time.Time{}},
+		{"no creation time", time.Time{}, 1, "", time.Time{}},
+	}
+
+	for _, test := range tests {
+		groupPermission := mockNamespacedGroupPermission()
+		groupPermission.CreationTimestamp = metav1.NewTime(created)
+		groupPermission.Generation = test.generation
+		namespace := mockNamespace("team-a")
+		namespace.CreationTimestamp = metav1.NewTime(test.namespace)
+
+		trigger, triggered, ok := propagationTrigger(groupPermission, namespace)
+		if trigger != test.trigger || !triggered.Equal(test.triggered) || ok != (test.trigger != "") {
+			t.Errorf("%s: Mismatch for trigger. Expected(%s at %v), Found(%s at %v)", test.label, test.trigger, test.triggered, trigger, triggered)
+		}
+	}
+}
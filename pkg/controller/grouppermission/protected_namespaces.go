@@ -0,0 +1,104 @@
+package grouppermission
+
+import (
+	"context"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=update
+
+// unprotectedNamespaces returns the Namespaces of namespaceList that operatorConfig does not protect
+func unprotectedNamespaces(namespaceList *corev1.NamespaceList, operatorConfig *operatorconfig.OperatorConfig) *corev1.NamespaceList {
+	if len(operatorConfig.ProtectedNamespaces) == 0 {
+		return namespaceList
+	}
+	unprotected := &corev1.NamespaceList{ListMeta: namespaceList.ListMeta}
+	for _, namespace := range namespaceList.Items {
+		if !operatorConfig.IsProtectedNamespace(namespace.Name) {
+			unprotected.Items = append(unprotected.Items, namespace)
+		}
+	}
+	return unprotected
+}
+
+// releaseProtectedNamespaces deletes the RoleBindings and Roles managed for groupPermission in the
+// Namespaces operatorConfig protects, or only removes their managed labels when the policy orphans them,
+// and records an event for each Namespace. A change of the operator configuration resyncs every
+// GroupPermission, so the bindings of a Namespace are released as soon as it is protected.
+func (r *ReconcileGroupPermission) releaseProtectedNamespaces(groupPermission *managedv1alpha1.GroupPermission, operatorConfig *operatorconfig.OperatorConfig) error {
+	if len(operatorConfig.ProtectedNamespaces) == 0 {
+		return nil
+	}
+	policy := operatorConfig.ProtectedNamespacePolicy
+
+	released := map[string]int{}
+	_, roleBindingList, err := r.listManagedBindings(groupPermission)
+	if err != nil {
+		return err
+	}
+	// the RoleBindings are released before the Roles, so a binding never dangles
+	for i := range roleBindingList.Items {
+		binding := &roleBindingList.Items[i]
+		if !utility.IsManagedFor(binding.ObjectMeta, groupPermission.Namespace, groupPermission.Name) || !operatorConfig.IsProtectedNamespace(binding.Namespace) {
+			continue
+		}
+		unlock := bindinglock.Lock(bindinglock.RoleBinding, binding.Namespace, binding.Name)
+		err := r.release(binding, &binding.ObjectMeta, policy)
+		unlock()
+		if err != nil {
+			return err
+		}
+		released[binding.Namespace]++
+	}
+
+	roleList, err := r.listManagedRoles(groupPermission)
+	if err != nil {
+		return err
+	}
+	for i := range roleList.Items {
+		role := &roleList.Items[i]
+		if !operatorConfig.IsProtectedNamespace(role.Namespace) {
+			continue
+		}
+		unlock := bindinglock.Lock(bindinglock.Role, role.Namespace, role.Name)
+		err := r.release(role, &role.ObjectMeta, policy)
+		unlock()
+		if err != nil {
+			return err
+		}
+		released[role.Namespace]++
+	}
+
+	for _, namespace := range sortedKeys(released) {
+		if policy == operatorconfig.ProtectedNamespaceOrphan {
+			r.namespacedEventf(groupPermission, namespace, corev1.EventTypeWarning, "ProtectedNamespaceOrphaned", "Orphaned %d RBAC objects in protected Namespace %s, they are no longer managed",
+				released[namespace], namespace)
+		} else {
+			r.namespacedEventf(groupPermission, namespace, corev1.EventTypeWarning, "ProtectedNamespaceDeleted", "Deleted %d managed RBAC objects in protected Namespace %s",
+				released[namespace], namespace)
+		}
+	}
+	return nil
+}
+
+// release deletes the managed RBAC object obj, or removes the managed labels of its objectMeta when
+// policy orphans it
+func (r *ReconcileGroupPermission) release(obj runtime.Object, objectMeta *metav1.ObjectMeta, policy operatorconfig.ProtectedNamespacePolicy) error {
+	if policy == operatorconfig.ProtectedNamespaceOrphan {
+		utility.RemoveManagedLabels(objectMeta)
+		return r.client.Update(context.TODO(), obj)
+	}
+	if err := r.client.Delete(context.TODO(), obj); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
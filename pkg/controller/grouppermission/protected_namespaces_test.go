@@ -0,0 +1,99 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newProtectedNamespacesReconciler returns a reconciler with the Namespaces team-a and team-b, and
+// a RoleBinding managed for the mock GroupPermission in each
+func newProtectedNamespacesReconciler(t *testing.T) (*ReconcileGroupPermission, *record.FakeRecorder) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	owned := utility.ManagedLabels(groupPermission.Namespace, groupPermission.Name)
+	fakeClient := fake.NewFakeClient(mockNamespace("team-a"), mockNamespace("team-b"),
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "admin-exampleGroupName", Labels: owned},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "admin-exampleGroupName", Labels: owned},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+		})
+	recorder := record.NewFakeRecorder(10)
+	return &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme, recorder: recorder}, recorder
+}
+
+// TestReleaseProtectedNamespacesDelete tests the releaseProtectedNamespaces function with the delete policy
+// given: RoleBindings managed for a GroupPermission in team-a and in the protected team-b
+// expected: the RoleBinding of team-b is deleted with an event, the one of team-a is kept
+func TestReleaseProtectedNamespacesDelete(t *testing.T) {
+	reconciler, recorder := newProtectedNamespacesReconciler(t)
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+	operatorConfig.ProtectedNamespaces = []string{"team-b"}
+
+	if err := reconciler.releaseProtectedNamespaces(mockNamespacedGroupPermission(), operatorConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: "team-b", Name: "admin-exampleGroupName"}, &rbacv1.RoleBinding{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected the RoleBinding of team-b to be deleted, got %v", err)
+	}
+	if err := reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "admin-exampleGroupName"}, &rbacv1.RoleBinding{}); err != nil {
+		t.Errorf("expected the RoleBinding of team-a to be kept: %v", err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("Mismatch for events. Expected(1), Found(%d)", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "ProtectedNamespaceDeleted Deleted 1 managed RBAC objects in protected Namespace team-b") {
+		t.Errorf("expected a ProtectedNamespaceDeleted event, got %q", event)
+	}
+}
+
+// TestReleaseProtectedNamespacesOrphan tests the releaseProtectedNamespaces function with the orphan policy
+// given: RoleBindings managed for a GroupPermission in team-a and in the protected team-b
+// expected: the RoleBinding of team-b is kept without its managed labels, and no RoleBinding is
+// created there again
+func TestReleaseProtectedNamespacesOrphan(t *testing.T) {
+	reconciler, _ := newProtectedNamespacesReconciler(t)
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+	operatorConfig.ProtectedNamespaces = []string{"team-b"}
+	operatorConfig.ProtectedNamespacePolicy = operatorconfig.ProtectedNamespaceOrphan
+
+	if err := reconciler.releaseProtectedNamespaces(mockNamespacedGroupPermission(), operatorConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	orphaned := &rbacv1.RoleBinding{}
+	if err := reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: "team-b", Name: "admin-exampleGroupName"}, orphaned); err != nil {
+		t.Fatalf("expected the RoleBinding of team-b to be kept: %v", err)
+	}
+	if utility.IsManaged(orphaned.ObjectMeta) {
+		t.Errorf("expected the RoleBinding of team-b to be no longer managed, got labels %v", orphaned.Labels)
+	}
+
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(mockNamespacedGroupPermission(), nil, nil, nil, operatorConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaceStatuses) != 1 || namespaceStatuses[0].Namespace != "team-a" {
+		t.Errorf("expected a NamespaceStatus for team-a only, got %v", namespaceStatuses)
+	}
+}
@@ -0,0 +1,76 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// recoverReconcile runs reconcileFunc for request and quarantines the GroupPermission of request when it
+// panics, so a single GroupPermission triggering a bug does not crash the operator for all of them
+func (r *ReconcileGroupPermission) recoverReconcile(request reconcile.Request, reconcileFunc func(reconcile.Request) (reconcile.Result, error)) (result reconcile.Result, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		log.Error(fmt.Errorf("%v", recovered), "Reconcile panicked, quarantining the GroupPermission",
+			"Request.Namespace", request.Namespace, "Request.Name", request.Name, "Stack", string(debug.Stack()))
+		localmetrics.IncReconcilePanics()
+		result, err = reconcile.Result{}, r.quarantine(request, recovered)
+	}()
+	return reconcileFunc(request)
+}
+
+// quarantine records in a Quarantined condition of the GroupPermission of request that its reconcile
+// panicked with recovered, and the generation of its spec that did
+func (r *ReconcileGroupPermission) quarantine(request reconcile.Request, recovered interface{}) error {
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	message := fmt.Sprintf("Reconcile panicked, skipping the GroupPermission until its spec changes: %v", recovered)
+	r.eventf(instance, corev1.EventTypeWarning, string(managedv1alpha1.GroupPermissionQuarantined), "%s", message)
+	localmetrics.SetGroupPermissionQuarantined(instance, true)
+	instance = updateCondition(instance, message, "", true, managedv1alpha1.GroupPermissionQuarantined, managedv1alpha1.ReasonReconcilePanicked)
+	instance.Status.QuarantinedGeneration = instance.Generation
+	return r.updateStatus(instance)
+}
+
+// isQuarantined returns whether the GroupPermission of request is quarantined and must be skipped. A
+// GroupPermission is released once its spec changes from the generation that panicked, or it is
+// deleted so its finalizer runs.
+func (r *ReconcileGroupPermission) isQuarantined(request reconcile.Request) (bool, error) {
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !conditions.IsTrue(instance.Status.Conditions, managedv1alpha1.ReasonReconcilePanicked) {
+		return false, nil
+	}
+	if instance.DeletionTimestamp == nil && instance.Status.QuarantinedGeneration == instance.Generation {
+		return true, nil
+	}
+
+	log.Info("Releasing the quarantined GroupPermission", "Request.Namespace", request.Namespace, "Request.Name", request.Name,
+		"QuarantinedGeneration", instance.Status.QuarantinedGeneration, "Generation", instance.Generation)
+	localmetrics.SetGroupPermissionQuarantined(instance, false)
+	conditions.Deactivate(instance.Status.Conditions, managedv1alpha1.ReasonReconcilePanicked)
+	instance.Status.QuarantinedGeneration = 0
+	return false, r.updateStatus(instance)
+}
@@ -0,0 +1,81 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestRecoverReconcile tests the recoverReconcile and isQuarantined functions
+// given: a reconcile returning normally, then one panicking, then a change of the spec
+// expected: the result of the first is returned, the panic is recovered into a Quarantined condition
+// recording the generation and an event, the GroupPermission is skipped until its generation changes
+func TestRecoverReconcile(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockGroupPermission()
+	groupPermission.Generation = 2
+	fakeClient := fake.NewFakeClient(groupPermission.DeepCopy())
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme, recorder: recorder}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}}
+
+	result, err := reconciler.recoverReconcile(request, func(reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{RequeueAfter: time.Minute}, nil
+	})
+	if err != nil || result.RequeueAfter != time.Minute {
+		t.Errorf("expected the result of the reconcile, got %+v, %v", result, err)
+	}
+
+	result, err = reconciler.recoverReconcile(request, func(reconcile.Request) (reconcile.Result, error) {
+		panic("malformed GroupPermission")
+	})
+	if err != nil || result.Requeue || result.RequeueAfter != 0 {
+		t.Errorf("expected the panic to be recovered without a requeue, got %+v, %v", result, err)
+	}
+	instance := &v1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !conditions.IsTrue(instance.Status.Conditions, v1alpha1.ReasonReconcilePanicked) || instance.Status.QuarantinedGeneration != 2 {
+		t.Errorf("expected a Quarantined condition for generation 2, got %+v", instance.Status)
+	}
+	if phase := groupPermissionPhase(instance, true); phase != v1alpha1.GroupPermissionPhaseFailed {
+		t.Errorf("Mismatch for phase. Expected(%s), Found(%s)", v1alpha1.GroupPermissionPhaseFailed, phase)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, string(v1alpha1.GroupPermissionQuarantined)) {
+		t.Errorf("expected a Quarantined event, got %s", event)
+	}
+
+	if quarantined, err := reconciler.isQuarantined(request); err != nil || !quarantined {
+		t.Errorf("expected the GroupPermission to be quarantined, got %t, %v", quarantined, err)
+	}
+
+	instance.Generation = 3
+	if err := fakeClient.Update(context.TODO(), instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quarantined, err := reconciler.isQuarantined(request); err != nil || quarantined {
+		t.Errorf("expected the GroupPermission to be released once its spec changed, got %t, %v", quarantined, err)
+	}
+	instance = &v1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conditions.IsTrue(instance.Status.Conditions, v1alpha1.ReasonReconcilePanicked) || instance.Status.QuarantinedGeneration != 0 {
+		t.Errorf("expected the Quarantined condition to be cleared, got %+v", instance.Status)
+	}
+}
@@ -0,0 +1,167 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/hack/rbacmarkers"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// clusterScopedResources are the cluster scoped resources the controller calls, any other resource
+// is namespaced
+var clusterScopedResources = map[string]bool{
+	"namespaces":           true,
+	"clusterroles":         true,
+	"clusterrolebindings":  true,
+	"subjectaccessreviews": true,
+	"groups":               true,
+}
+
+// apiCall is a call of the controller to the apiserver
+type apiCall struct {
+	group    string
+	resource string
+	verb     string
+}
+
+// recordingClient records the calls made through the wrapped client. Lists made through the cache
+// also require the watch verb.
+type recordingClient struct {
+	client.Client
+	cached bool
+	calls  *[]apiCall
+}
+
+func (c *recordingClient) record(obj runtime.Object, subresource string, verbs ...string) {
+	gvk, err := apiutil.GVKForObject(obj, scheme.Scheme)
+	if err != nil {
+		return
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	resource, _ := meta.UnsafeGuessKindToResource(gvk)
+	name := resource.Resource
+	if subresource != "" {
+		name += "/" + subresource
+	}
+	for _, verb := range verbs {
+		*c.calls = append(*c.calls, apiCall{group: gvk.Group, resource: name, verb: verb})
+	}
+}
+
+func (c *recordingClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	c.record(obj, "", "get")
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *recordingClient) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	if c.cached {
+		c.record(list, "", "list", "watch")
+	} else {
+		c.record(list, "", "list")
+	}
+	return c.Client.List(ctx, opts, list)
+}
+
+func (c *recordingClient) Create(ctx context.Context, obj runtime.Object) error {
+	c.record(obj, "", "create")
+	return c.Client.Create(ctx, obj)
+}
+
+func (c *recordingClient) Update(ctx context.Context, obj runtime.Object) error {
+	c.record(obj, "", "update")
+	return c.Client.Update(ctx, obj)
+}
+
+func (c *recordingClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOptionFunc) error {
+	c.record(obj, "", "delete")
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *recordingClient) Status() client.StatusWriter {
+	return &recordingStatusWriter{client: c}
+}
+
+// recordingStatusWriter records the status updates made through a recordingClient
+type recordingStatusWriter struct {
+	client *recordingClient
+}
+
+func (w *recordingStatusWriter) Update(ctx context.Context, obj runtime.Object) error {
+	w.client.record(obj, "status", "update")
+	return w.client.Client.Status().Update(ctx, obj)
+}
+
+// TestReconcileCallsDeclaredInRBACMarkers tests the RBAC markers of the operator
+// given: reconciles creating and deleting the bindings of a GroupPermission, through clients recording their calls
+// expected: every call is allowed by the roles generated from the RBAC markers
+func TestReconcileCallsDeclaredInRBACMarkers(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	markers, err := rbacmarkers.Collect("../../../cmd", "../../../pkg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clusterRules := rbacmarkers.BuildRules(markers, false)
+	namespacedRules := rbacmarkers.BuildRules(markers, true)
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.CreateGroupIfMissing = true
+	groupPermission.Annotations = map[string]string{operatorconfig.RequesterAnnotation: "requester"}
+	clusterRole := mockClusterRole()
+	clusterRole.Name = "exampleClusterRoleName"
+
+	deletedGroupPermission := mockGroupPermission()
+	deletedGroupPermission.Name = "deletedGroupPermission"
+	deletedGroupPermission.Finalizers = []string{operatorconfig.GroupPermissionFinalizer}
+	deletedGroupPermission.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+
+	managedBinding := mockClusterRoleBinding()
+	utility.SetManagedLabels(&managedBinding.ObjectMeta, deletedGroupPermission.Namespace, deletedGroupPermission.Name)
+
+	fakeClient := fake.NewFakeClient(groupPermission, deletedGroupPermission, clusterRole, managedBinding, mockNamespace("team-a"))
+	var calls []apiCall
+	reconciler := &ReconcileGroupPermission{
+		client:    &recordingClient{Client: fakeClient, cached: true, calls: &calls},
+		apiClient: &recordingClient{Client: fakeClient, calls: &calls},
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	for _, name := range []string{groupPermission.Name, deletedGroupPermission.Name} {
+		// errors of the fake client are irrelevant, only the calls made are checked
+		_, _ = reconciler.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: groupPermission.Namespace,
+			Name:      name,
+		}})
+	}
+
+	if len(calls) == 0 {
+		t.Fatalf("expected calls to be recorded")
+	}
+	for _, call := range calls {
+		allowed := rbacmarkers.Allows(clusterRules, call.group, call.resource, call.verb)
+		if !allowed && !clusterScopedResources[call.resource] {
+			allowed = rbacmarkers.Allows(namespacedRules, call.group, call.resource, call.verb)
+		}
+		if !allowed {
+			t.Errorf("%s of %s.%s is not declared in the RBAC markers", call.verb, call.resource, call.group)
+		}
+	}
+}
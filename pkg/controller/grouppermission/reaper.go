@@ -0,0 +1,203 @@
+package grouppermission
+
+import (
+	"context"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/openshift/rbac-permissions-operator/pkg/readonly"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// reaperInterval is the interval between two checks of the stale grants
+const reaperInterval = time.Hour
+
+// reaper flags the roles bound by GroupPermissions that their Group did not use within the stale grant
+// age of the operator configuration, according to the roleUsage of their status. When the removal is
+// enabled, they are also removed from the GroupPermissions with their managed bindings.
+type reaper struct {
+	reconciler *ReconcileGroupPermission
+	interval   time.Duration
+	now        func() time.Time
+	remove     bool
+	// flagged holds the stale grants of each GroupPermission, by kind/name, already recorded in an
+	// event. It is only used by the goroutine running the checks.
+	flagged map[types.NamespacedName]map[string]bool
+}
+
+// blank assignment to verify that reaper implements manager.Runnable
+var _ manager.Runnable = &reaper{}
+
+// newReaper returns a reaper of the stale grants of the GroupPermissions reconciled by r
+func newReaper(r *ReconcileGroupPermission) *reaper {
+	return &reaper{
+		reconciler: r,
+		interval:   reaperInterval,
+		now:        time.Now,
+		remove:     r.removeStaleGrants,
+		flagged:    map[types.NamespacedName]map[string]bool{},
+	}
+}
+
+// Start implements manager.Runnable, it checks the stale grants until stop is closed
+func (p *reaper) Start(stop <-chan struct{}) error {
+	wait.Until(func() {
+		if err := p.check(context.TODO()); err != nil {
+			log.Error(err, "Failed to check stale grants")
+		}
+	}, p.interval, stop)
+	return nil
+}
+
+// check records a Warning event for each new stale grant of the GroupPermissions of the shard, removes
+// them when enabled and exports the number of stale grants. GroupPermissions annotated to keep their
+// stale grants are skipped.
+func (p *reaper) check(ctx context.Context) error {
+	r := p.reconciler
+	operatorConfig, err := r.getOperatorConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if operatorConfig.StaleGrantAge <= 0 {
+		p.flagged = map[types.NamespacedName]map[string]bool{}
+		localmetrics.SetStaleGrants(0)
+		return nil
+	}
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	if err := r.client.List(ctx, &client.ListOptions{}, groupPermissionList); err != nil {
+		return err
+	}
+
+	now := p.now()
+	seen := map[types.NamespacedName]bool{}
+	stale := 0
+	for i := range groupPermissionList.Items {
+		groupPermission := &groupPermissionList.Items[i]
+		if !r.shard.matches(groupPermission) || groupPermission.DeletionTimestamp != nil ||
+			groupPermission.Annotations[operatorconfig.KeepStaleGrantsAnnotation] == "true" {
+			continue
+		}
+		key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+		usages := staleGrants(groupPermission, operatorConfig.StaleGrantAge, now)
+		if len(usages) == 0 {
+			continue
+		}
+		seen[key] = true
+		stale += len(usages)
+
+		flagged := map[string]bool{}
+		for _, usage := range usages {
+			name := usage.Kind + "/" + usage.Name
+			flagged[name] = true
+			if !p.flagged[key][name] {
+				r.eventf(groupPermission, corev1.EventTypeWarning, "StaleGrant", "%s %s was not used by Group %s %s",
+					usage.Kind, usage.Name, groupPermission.Spec.GroupName, describeLastUse(groupPermission, usage))
+			}
+		}
+		p.flagged[key] = flagged
+
+		if !p.remove || readonly.Enabled() {
+			continue
+		}
+		if err := p.removeGrants(ctx, groupPermission, usages, operatorConfig.StaleGrantAge); err != nil {
+			log.Error(err, "Failed to remove stale grants", "Request.Namespace", key.Namespace, "Request.Name", key.Name)
+			continue
+		}
+		stale -= len(usages)
+		delete(p.flagged, key)
+	}
+
+	for key := range p.flagged {
+		if !seen[key] {
+			delete(p.flagged, key)
+		}
+	}
+	localmetrics.SetStaleGrants(stale)
+	return nil
+}
+
+// staleGrants returns the usage of the roles still bound by groupPermission that its Group did not use
+// within age of now. A role never seen used is stale once the GroupPermission is older than age.
+func staleGrants(groupPermission *managedv1alpha1.GroupPermission, age time.Duration, now time.Time) []managedv1alpha1.RoleUsage {
+	bound := map[string]bool{}
+	for _, role := range boundRoles(groupPermission) {
+		bound[role.Kind+"/"+role.Name] = true
+	}
+
+	var stale []managedv1alpha1.RoleUsage
+	for _, usage := range groupPermission.Status.RoleUsage {
+		if !bound[usage.Kind+"/"+usage.Name] {
+			continue
+		}
+		since := groupPermission.CreationTimestamp.Time
+		if usage.LastUsedTime != nil {
+			since = usage.LastUsedTime.Time
+		}
+		if now.Sub(since) > age {
+			stale = append(stale, usage)
+		}
+	}
+	return stale
+}
+
+// describeLastUse returns when the Group of groupPermission last used the role of usage
+func describeLastUse(groupPermission *managedv1alpha1.GroupPermission, usage managedv1alpha1.RoleUsage) string {
+	if usage.LastUsedTime == nil {
+		return "since the GroupPermission was created on " + groupPermission.CreationTimestamp.UTC().Format(time.RFC3339)
+	}
+	return "since " + usage.LastUsedTime.UTC().Format(time.RFC3339)
+}
+
+// removeGrants removes the roles of usages from the spec of groupPermission, then deletes its managed
+// bindings to them, recording a Warning event for each. The spec is updated first so the bindings are
+// not created again. The webhook keeps the requester of the spec, the operator only removes grants from it.
+func (p *reaper) removeGrants(ctx context.Context, groupPermission *managedv1alpha1.GroupPermission, usages []managedv1alpha1.RoleUsage, age time.Duration) error {
+	r := p.reconciler
+	stale := map[string]bool{}
+	for _, usage := range usages {
+		stale[usage.Kind+"/"+usage.Name] = true
+	}
+
+	var clusterPermissions []string
+	for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+		if !stale["ClusterRole/"+clusterRoleName] {
+			clusterPermissions = append(clusterPermissions, clusterRoleName)
+		}
+	}
+	var permissions []managedv1alpha1.Permission
+	for _, permission := range groupPermission.Spec.Permissions {
+		roleRef := permissionRoleRef(&groupPermission.Spec, permission)
+		if !stale[roleRef.Kind+"/"+roleRef.Name] {
+			permissions = append(permissions, permission)
+		}
+	}
+	groupPermission.Spec.ClusterPermissions = clusterPermissions
+	groupPermission.Spec.Permissions = permissions
+	if err := r.client.Update(ctx, groupPermission); err != nil {
+		return err
+	}
+
+	deleted, err := r.deleteBindings(groupPermission, func(roleRef v1.RoleRef) bool {
+		return stale[roleRef.Kind+"/"+roleRef.Name]
+	})
+	if err != nil {
+		return err
+	}
+	counts := map[string]int{}
+	for roleRef, count := range deleted {
+		counts[roleRef.Kind+"/"+roleRef.Name] += count
+	}
+	for _, usage := range usages {
+		r.eventf(groupPermission, corev1.EventTypeWarning, "StaleGrantRemoved", "Removed %s %s and its %d managed bindings, not used by Group %s in %d days",
+			usage.Kind, usage.Name, counts[usage.Kind+"/"+usage.Name], groupPermission.Spec.GroupName, int(age.Hours()/24))
+	}
+	return nil
+}
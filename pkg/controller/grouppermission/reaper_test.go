@@ -0,0 +1,150 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestReaper returns a reaper checking at now a GroupPermission binding view, last used 100 days
+// ago, and edit, used yesterday, with a managed ClusterRoleBinding to each and a stale grant age of 90 days
+func newTestReaper(t *testing.T, now time.Time, remove bool, annotations map[string]string) (*reaper, *record.FakeRecorder) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockGroupPermission()
+	groupPermission.Annotations = annotations
+	groupPermission.CreationTimestamp = metav1.Time{Time: now.Add(-365 * 24 * time.Hour)}
+	groupPermission.Spec.ClusterPermissions = []string{"view", "edit"}
+	viewUsed := metav1.Time{Time: now.Add(-100 * 24 * time.Hour)}
+	editUsed := metav1.Time{Time: now.Add(-24 * time.Hour)}
+	groupPermission.Status.RoleUsage = []v1alpha1.RoleUsage{
+		{Kind: "ClusterRole", Name: "view", LastUsedTime: &viewUsed},
+		{Kind: "ClusterRole", Name: "edit", LastUsedTime: &editUsed},
+	}
+	owned := utility.ManagedLabels(groupPermission.Namespace, groupPermission.Name)
+	fakeClient := fake.NewFakeClient(groupPermission,
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "view-exampleGroupName", Labels: owned},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "edit-exampleGroupName", Labels: owned},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+		},
+		mockOperatorConfigMap(operatorconfig.OperatorNamespace, map[string]string{"stale_grant_days": "90"}))
+	recorder := record.NewFakeRecorder(10)
+	p := newReaper(&ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  recorder,
+	})
+	p.now = func() time.Time { return now }
+	p.remove = remove
+	return p, recorder
+}
+
+// TestReaperReportOnly tests the check function of the reaper without the removal of stale grants
+// given: a GroupPermission binding view, not used for 100 days, and edit, used yesterday, checked twice
+// expected: only view is flagged, with a single event, and both grants and bindings are kept
+func TestReaperReportOnly(t *testing.T) {
+	p, recorder := newTestReaper(t, time.Now(), false, nil)
+
+	for i := 0; i < 2; i++ {
+		if err := p.check(context.TODO()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if value := testutil.ToFloat64(localmetrics.RBACStaleGrants); value != 1 {
+		t.Errorf("Mismatch for stale grants. Expected(1), Found(%v)", value)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("Mismatch for events. Expected(1), Found(%d)", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "StaleGrant ClusterRole view") {
+		t.Errorf("expected a StaleGrant event for view, got %q", event)
+	}
+	instance := &v1alpha1.GroupPermission{}
+	if err := p.reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: "rbac-permissions-operator", Name: "testGroupPermission"}, instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"view", "edit"}; !reflect.DeepEqual(instance.Spec.ClusterPermissions, expected) {
+		t.Errorf("Mismatch for ClusterPermissions. Expected(%v), Found(%v)", expected, instance.Spec.ClusterPermissions)
+	}
+	if err := p.reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "view-exampleGroupName"}, &rbacv1.ClusterRoleBinding{}); err != nil {
+		t.Errorf("expected the ClusterRoleBinding of view to be kept: %v", err)
+	}
+}
+
+// TestReaperRemove tests the check function of the reaper with the removal of stale grants
+// given: a GroupPermission binding view, not used for 100 days, and edit, used yesterday
+// expected: view is removed from the GroupPermission with its binding, edit is kept
+func TestReaperRemove(t *testing.T) {
+	p, recorder := newTestReaper(t, time.Now(), true, nil)
+
+	if err := p.check(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	instance := &v1alpha1.GroupPermission{}
+	if err := p.reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: "rbac-permissions-operator", Name: "testGroupPermission"}, instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"edit"}; !reflect.DeepEqual(instance.Spec.ClusterPermissions, expected) {
+		t.Errorf("Mismatch for ClusterPermissions. Expected(%v), Found(%v)", expected, instance.Spec.ClusterPermissions)
+	}
+	if err := p.reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "view-exampleGroupName"}, &rbacv1.ClusterRoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the ClusterRoleBinding of view to be removed, got %v", err)
+	}
+	if err := p.reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "edit-exampleGroupName"}, &rbacv1.ClusterRoleBinding{}); err != nil {
+		t.Errorf("expected the ClusterRoleBinding of edit to be kept: %v", err)
+	}
+	if value := testutil.ToFloat64(localmetrics.RBACStaleGrants); value != 0 {
+		t.Errorf("Mismatch for stale grants. Expected(0), Found(%v)", value)
+	}
+	if len(recorder.Events) != 2 {
+		t.Fatalf("Mismatch for events. Expected(2), Found(%d)", len(recorder.Events))
+	}
+	<-recorder.Events
+	if event := <-recorder.Events; !strings.Contains(event, "StaleGrantRemoved Removed ClusterRole view and its 1 managed bindings") {
+		t.Errorf("expected a StaleGrantRemoved event for view, got %q", event)
+	}
+}
+
+// TestReaperOptOut tests the check function of the reaper on a GroupPermission opted out
+// given: a GroupPermission annotated to keep its stale grants, binding view not used for 100 days
+// expected: nothing is flagged nor removed
+func TestReaperOptOut(t *testing.T) {
+	p, recorder := newTestReaper(t, time.Now(), true, map[string]string{operatorconfig.KeepStaleGrantsAnnotation: "true"})
+
+	if err := p.check(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(recorder.Events) != 0 {
+		t.Errorf("Mismatch for events. Expected(0), Found(%d)", len(recorder.Events))
+	}
+	if err := p.reconciler.client.Get(context.TODO(), types.NamespacedName{Name: "view-exampleGroupName"}, &rbacv1.ClusterRoleBinding{}); err != nil {
+		t.Errorf("expected the ClusterRoleBinding of view to be kept: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package grouppermission
+
+import (
+	"context"
+	"time"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=update
+
+// scheduleRemoval labels role, created for groupPermission in a Namespace no longer allowed, and its
+// RoleBinding as pending removal until gracePeriod after now, with an event, unless they already are.
+// Returns when role is due for removal, now when there is no grace period.
+func (r *ReconcileGroupPermission) scheduleRemoval(groupPermission *managedv1alpha1.GroupPermission, role *v1.Role, gracePeriod time.Duration, now time.Time) (time.Time, error) {
+	if gracePeriod <= 0 {
+		return now, nil
+	}
+	// a removal without a valid due time is scheduled again
+	if due, ok := utility.RemovalDue(role.ObjectMeta); ok {
+		return due, nil
+	}
+
+	due := now.Add(gracePeriod).UTC()
+	if err := r.updateRemoval(groupPermission, role, func(objectMeta *metav1.ObjectMeta) bool {
+		return utility.SetPendingRemoval(objectMeta, due)
+	}); err != nil {
+		return due, err
+	}
+	r.namespacedEventf(groupPermission, role.Namespace, corev1.EventTypeWarning, "PendingRemoval", "Role %s and its RoleBinding in Namespace %s are removed at %s, unless the Namespace is allowed again",
+		role.Name, role.Namespace, due.Format(time.RFC3339))
+	return due, nil
+}
+
+// cancelRemoval clears the pending removal of role, created for groupPermission in a Namespace allowed
+// again, and of its RoleBinding, with an event. Nothing is done when role is not pending removal.
+func (r *ReconcileGroupPermission) cancelRemoval(groupPermission *managedv1alpha1.GroupPermission, role *v1.Role) error {
+	if !utility.IsPendingRemoval(role.ObjectMeta) {
+		return nil
+	}
+	if err := r.updateRemoval(groupPermission, role, utility.ClearPendingRemoval); err != nil {
+		return err
+	}
+	r.namespacedEventf(groupPermission, role.Namespace, corev1.EventTypeNormal, "RemovalCancelled", "Role %s and its RoleBinding in Namespace %s are kept, the Namespace is allowed again",
+		role.Name, role.Namespace)
+	return nil
+}
+
+// updateRemoval applies change to the metadata of the RoleBinding managed for groupPermission to role,
+// then of role, and updates those it changed
+func (r *ReconcileGroupPermission) updateRemoval(groupPermission *managedv1alpha1.GroupPermission, role *v1.Role, change func(*metav1.ObjectMeta) bool) error {
+	roleBinding := newRoleBinding(role.Namespace, v1.RoleRef{APIGroup: groupPermission.Spec.RoleRefAPIGroup, Kind: "Role", Name: role.Name}, utility.GroupSubject(&groupPermission.Spec))
+	existing := &v1.RoleBinding{}
+	err := r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: roleBinding.Namespace, Name: roleBinding.Name}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && utility.IsManagedFor(existing.ObjectMeta, groupPermission.Namespace, groupPermission.Name) && change(&existing.ObjectMeta) {
+		unlock := bindinglock.Lock(bindinglock.RoleBinding, existing.Namespace, existing.Name)
+		err := r.client.Update(context.TODO(), existing)
+		unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	if !change(&role.ObjectMeta) {
+		return nil
+	}
+	defer bindinglock.Lock(bindinglock.Role, role.Namespace, role.Name)()
+	return r.client.Update(context.TODO(), role)
+}
+
+// scheduleBindingRemoval labels roleBinding, managed for groupPermission in a Namespace no longer allowed
+// and not bound to a Role created for it, as pending removal until gracePeriod after now, with an event,
+// unless it already is. Returns when roleBinding is due for removal, now when there is no grace period.
+func (r *ReconcileGroupPermission) scheduleBindingRemoval(groupPermission *managedv1alpha1.GroupPermission, roleBinding *v1.RoleBinding, gracePeriod time.Duration, now time.Time) (time.Time, error) {
+	if gracePeriod <= 0 {
+		return now, nil
+	}
+	if due, ok := utility.RemovalDue(roleBinding.ObjectMeta); ok {
+		return due, nil
+	}
+
+	due := now.Add(gracePeriod).UTC()
+	if err := r.updateBindingRemoval(roleBinding, func(objectMeta *metav1.ObjectMeta) bool {
+		return utility.SetPendingRemoval(objectMeta, due)
+	}); err != nil {
+		return due, err
+	}
+	r.namespacedEventf(groupPermission, roleBinding.Namespace, corev1.EventTypeWarning, "PendingRemoval", "RoleBinding %s of %s %s in Namespace %s is removed at %s, unless the Namespace is allowed again",
+		roleBinding.Name, roleBinding.RoleRef.Kind, roleBinding.RoleRef.Name, roleBinding.Namespace, due.Format(time.RFC3339))
+	return due, nil
+}
+
+// cancelBindingRemoval clears the pending removal of roleBinding, managed for groupPermission in a Namespace
+// allowed again, with an event. Nothing is done when roleBinding is not pending removal.
+func (r *ReconcileGroupPermission) cancelBindingRemoval(groupPermission *managedv1alpha1.GroupPermission, roleBinding *v1.RoleBinding) error {
+	if !utility.IsPendingRemoval(roleBinding.ObjectMeta) {
+		return nil
+	}
+	if err := r.updateBindingRemoval(roleBinding, utility.ClearPendingRemoval); err != nil {
+		return err
+	}
+	r.namespacedEventf(groupPermission, roleBinding.Namespace, corev1.EventTypeNormal, "RemovalCancelled", "RoleBinding %s in Namespace %s is kept, the Namespace is allowed again",
+		roleBinding.Name, roleBinding.Namespace)
+	return nil
+}
+
+// updateBindingRemoval applies change to the metadata of roleBinding and updates it if it changed
+func (r *ReconcileGroupPermission) updateBindingRemoval(roleBinding *v1.RoleBinding, change func(*metav1.ObjectMeta) bool) error {
+	if !change(&roleBinding.ObjectMeta) {
+		return nil
+	}
+	defer bindinglock.Lock(bindinglock.RoleBinding, roleBinding.Namespace, roleBinding.Name)()
+	return r.client.Update(context.TODO(), roleBinding)
+}
@@ -0,0 +1,179 @@
+package grouppermission
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bindingsRenamedReason is the reason of the event reporting bindings renamed to the current naming scheme
+const bindingsRenamedReason = "BindingsRenamed"
+
+// bindingRename is a managed binding to create under a new name, then delete under its old name
+type bindingRename struct {
+	old runtime.Object
+	new runtime.Object
+}
+
+// renameLegacyBindings renames the managed bindings of groupPermission granting the same role to the
+// same subjects as a binding it resolves to under another name, as left by a previous naming scheme.
+// The binding of the new name is created before the binding of the old name is deleted, so the access
+// is never interrupted. The renaming is recorded in the status, it returns whether the status changed.
+// The bindings of each GroupPermission are checked once per run of the operator, or until none is pending.
+func (r *ReconcileGroupPermission) renameLegacyBindings(groupPermission *managedv1alpha1.GroupPermission, clusterRoleList *v1.ClusterRoleList) (bool, error) {
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	if _, checked := r.namesChecked.Load(key); checked {
+		return false, nil
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{}, namespaceList); err != nil {
+		return false, err
+	}
+	desired, valid := resolveBindings(groupPermission, namespaceList, clusterRoleList, time.Now())
+	if !valid {
+		return false, nil
+	}
+	clusterRoleBindingList, roleBindingList, err := r.listManagedBindings(groupPermission)
+	if err != nil {
+		return false, err
+	}
+
+	renames := legacyBindings(groupPermission, desired, clusterRoleBindingList, roleBindingList)
+	status := groupPermission.Status.BindingMigration
+	if len(renames) == 0 {
+		r.namesChecked.Store(key, true)
+		if status == nil || (status.Pending == 0 && status.LastError == "") {
+			return false, nil
+		}
+		status.Pending = 0
+		status.LastError = ""
+		return true, nil
+	}
+
+	if status == nil {
+		status = &managedv1alpha1.BindingMigrationStatus{}
+		groupPermission.Status.BindingMigration = status
+	}
+	renamed := 0
+	status.LastError = ""
+	for _, rename := range renames {
+		if err := r.renameBinding(rename); err != nil {
+			status.LastError = err.Error()
+			continue
+		}
+		renamed++
+	}
+	status.Renamed += renamed
+	status.Pending = len(renames) - renamed
+	if status.Pending == 0 {
+		r.namesChecked.Store(key, true)
+	}
+
+	if renamed > 0 {
+		r.eventf(groupPermission, corev1.EventTypeNormal, bindingsRenamedReason, "Renamed %d bindings to the current naming scheme", renamed)
+	}
+	return true, nil
+}
+
+// renameBinding creates the binding of rename under its new name, then deletes it under its old name.
+// Each name is locked for its own operation only, so renames never wait on each other in a cycle.
+func (r *ReconcileGroupPermission) renameBinding(rename bindingRename) error {
+	unlock := bindinglock.LockObject(rename.new)
+	err := r.client.Create(context.TODO(), rename.new)
+	unlock()
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	unlock = bindinglock.LockObject(rename.old)
+	err = r.client.Delete(context.TODO(), rename.old)
+	unlock()
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// legacyBindings returns the renames of the managed bindings of groupPermission that are not desired
+// under their name, but grant the same role to the same subjects as a desired binding in the same scope
+func legacyBindings(groupPermission *managedv1alpha1.GroupPermission, desired map[string]verifiedBinding,
+	clusterRoleBindingList *v1.ClusterRoleBindingList, roleBindingList *v1.RoleBindingList) []bindingRename {
+	// desired names by scope and grant, the name is what changes between naming schemes
+	desiredNames := map[string]string{}
+	for name, binding := range desired {
+		scope := name[:strings.LastIndexAny(name, " /")+1]
+		desiredNames[bindingIdentity(scope, binding)] = strings.TrimPrefix(name, scope)
+	}
+
+	var renames []bindingRename
+	for i := range clusterRoleBindingList.Items {
+		binding := &clusterRoleBindingList.Items[i]
+		if !utility.IsManagedFor(binding.ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+			continue
+		}
+		scope := "ClusterRoleBinding "
+		if _, ok := desired[scope+binding.Name]; ok {
+			continue
+		}
+		if newName, ok := desiredNames[bindingIdentity(scope, verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects})]; ok {
+			renamed := &v1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: newName, Labels: binding.Labels, Annotations: copyAnnotations(binding.Annotations)},
+				Subjects:   binding.Subjects,
+				RoleRef:    binding.RoleRef,
+			}
+			utility.SetSourceAnnotations(&renamed.ObjectMeta, groupPermission)
+			renames = append(renames, bindingRename{old: binding, new: renamed})
+		}
+	}
+	for i := range roleBindingList.Items {
+		binding := &roleBindingList.Items[i]
+		if !utility.IsManagedFor(binding.ObjectMeta, groupPermission.Namespace, groupPermission.Name) {
+			continue
+		}
+		scope := "RoleBinding " + binding.Namespace + "/"
+		if _, ok := desired[scope+binding.Name]; ok {
+			continue
+		}
+		if newName, ok := desiredNames[bindingIdentity(scope, verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects})]; ok {
+			renamed := &v1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: binding.Namespace, Name: newName, Labels: binding.Labels, Annotations: copyAnnotations(binding.Annotations)},
+				Subjects:   binding.Subjects,
+				RoleRef:    binding.RoleRef,
+			}
+			utility.SetSourceAnnotations(&renamed.ObjectMeta, groupPermission)
+			renames = append(renames, bindingRename{old: binding, new: renamed})
+		}
+	}
+	return renames
+}
+
+// copyAnnotations returns a copy of annotations, so the binding of the new name is stamped alone
+func copyAnnotations(annotations map[string]string) map[string]string {
+	copied := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		copied[key] = value
+	}
+	return copied
+}
+
+// bindingIdentity returns what a binding in scope grants to whom, regardless of its name
+func bindingIdentity(scope string, binding verifiedBinding) string {
+	normalized := normalizeBinding(binding)
+	var subjects []string
+	for _, subject := range normalized.subjects {
+		subjects = append(subjects, subjectKey(subject))
+	}
+	return scope + normalized.roleRef.Kind + "/" + normalized.roleRef.Name + " " + strings.Join(subjects, ",")
+}
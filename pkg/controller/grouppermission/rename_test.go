@@ -0,0 +1,79 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestRenameLegacyBindings tests the renameLegacyBindings function
+// given: a managed RoleBinding under a previous name granting a desired role, one granting another role
+// and one of another GroupPermission
+// expected: the first one only is renamed, the renaming recorded in the status, and not checked again
+func TestRenameLegacyBindings(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	admin := mockClusterRole()
+	admin.Name = "admin"
+
+	legacyRoleBinding := func(name string, roleName string, groupPermissionName string) *rbacv1.RoleBinding {
+		roleBinding := newRoleBinding("team-a", rbacv1.RoleRef{Kind: "ClusterRole", Name: roleName}, mockGroupSubject("exampleGroupName"))
+		roleBinding.Name = name
+		utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermissionName)
+		return roleBinding
+	}
+	fakeClient := fake.NewFakeClient(groupPermission, admin, mockNamespace("team-a"),
+		legacyRoleBinding("legacy-admin", "admin", groupPermission.Name),
+		legacyRoleBinding("legacy-edit", "edit", groupPermission.Name),
+		legacyRoleBinding("other-admin", "admin", "otherGroupPermission"))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+
+	clusterRoleList := &rbacv1.ClusterRoleList{Items: []rbacv1.ClusterRole{*admin}}
+	changed, err := reconciler.renameLegacyBindings(groupPermission, clusterRoleList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	migration := groupPermission.Status.BindingMigration
+	if !changed || migration == nil || migration.Renamed != 1 || migration.Pending != 0 || migration.LastError != "" {
+		t.Fatalf("unexpected binding migration %+v", migration)
+	}
+
+	renamed := &rbacv1.RoleBinding{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "admin-exampleGroupName"}, renamed); err != nil {
+		t.Fatalf("expected the renamed RoleBinding: %v", err)
+	}
+	if !utility.IsManagedFor(renamed.ObjectMeta, groupPermission.Namespace, groupPermission.Name) || renamed.RoleRef.Name != "admin" {
+		t.Errorf("unexpected renamed RoleBinding %+v", renamed)
+	}
+	for name, exists := range map[string]bool{"legacy-admin": false, "legacy-edit": true, "other-admin": true} {
+		err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: name}, &rbacv1.RoleBinding{})
+		if exists && err != nil {
+			t.Errorf("expected RoleBinding %s to be kept: %v", name, err)
+		}
+		if !exists && !errors.IsNotFound(err) {
+			t.Errorf("expected RoleBinding %s to be deleted: %v", name, err)
+		}
+	}
+
+	if changed, err := reconciler.renameLegacyBindings(groupPermission, clusterRoleList); err != nil || changed {
+		t.Errorf("expected the bindings not to be checked again, got %v, %v", changed, err)
+	}
+}
@@ -0,0 +1,203 @@
+package grouppermission
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// roleContentDriftReason is the reason of the event emitted when the rules of Roles changed outside the
+// operator are restored
+const roleContentDriftReason = "RoleContentDrift"
+
+// addRoleContent adds the role content controller of reconciler to mgr, converging the rules of the
+// Roles created by reconciler
+func addRoleContent(mgr manager.Manager, reconciler *ReconcileGroupPermission) error {
+	c, err := controller.New("rolecontent-controller", mgr, controller.Options{
+		Reconciler:              &ReconcileRoleContent{ReconcileGroupPermission: reconciler, resync: reconciler.roleContentResync},
+		MaxConcurrentReconciles: reconciler.roleContentWorkers,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Roles outside the watched namespace are not in the cache, their drift is found by the resync
+	return c.Watch(&source.Kind{Type: &managedv1alpha1.GroupPermission{}}, &handler.EnqueueRequestForObject{}, reconciler.shard.predicate())
+}
+
+// blank assignment to verify that ReconcileRoleContent implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileRoleContent{}
+
+// ReconcileRoleContent converges the rules of the Roles created from the Permissions with rules of a
+// GroupPermission to its spec, and reports in status.roleContent the Roles whose rules were changed
+// outside the operator. The binding controller only creates the Roles, so both are tuned independently.
+type ReconcileRoleContent struct {
+	*ReconcileGroupPermission
+	// resync is the interval between two convergences of the Roles of a GroupPermission
+	resync time.Duration
+}
+
+// Reconcile converges the rules of the Roles of the GroupPermission of request
+func (r *ReconcileRoleContent) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	inShard, err := r.inShard(request)
+	if err != nil || !inShard {
+		return reconcile.Result{}, err
+	}
+
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	// the Roles of a deleted or invalid GroupPermission are left to the binding controller
+	if instance.DeletionTimestamp != nil || validateSpec(instance) != nil {
+		return reconcile.Result{}, nil
+	}
+
+	var status *managedv1alpha1.RoleContentStatus
+	if hasRules(instance) {
+		operatorConfig, err := r.getOperatorConfig(context.TODO())
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		status, err = r.convergeRoleContent(instance, operatorConfig, time.Now())
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if !reflect.DeepEqual(instance.Status.RoleContent, status) {
+		instance.Status.RoleContent = status
+		if err := r.updateStatus(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if status == nil {
+		return reconcile.Result{}, nil
+	}
+	return reconcile.Result{RequeueAfter: r.resync}, nil
+}
+
+// hasRules returns whether groupPermission has a Permission with rules or a view Role
+func hasRules(groupPermission *managedv1alpha1.GroupPermission) bool {
+	for _, permission := range groupPermission.Spec.Permissions {
+		if len(permission.Rules) > 0 {
+			return true
+		}
+	}
+	return groupPermission.Spec.ViewRole != nil
+}
+
+// desiredRoles returns the Permission with rules each Role of groupPermission is created from, by
+// namespace/name, given the Namespaces allowed for each Permission. The view Role is desired in every
+// Namespace a Permission is allowed in.
+func desiredRoles(groupPermission *managedv1alpha1.GroupPermission, allowed []map[string]bool) map[string]managedv1alpha1.Permission {
+	desired := map[string]managedv1alpha1.Permission{}
+	for i, permission := range groupPermission.Spec.Permissions {
+		if len(permission.Rules) == 0 {
+			continue
+		}
+		for namespace := range allowed[i] {
+			desired[namespace+"/"+permission.RoleName] = permission
+		}
+	}
+	if permission, ok := viewPermission(groupPermission); ok {
+		for namespace := range matchedNamespaceNames(allowed) {
+			desired[namespace+"/"+permission.RoleName] = permission
+		}
+	}
+	return desired
+}
+
+// rulesHash returns a hash of rules, recorded on the Roles created by the operator
+func rulesHash(rules []v1.PolicyRule) string {
+	content, _ := json.Marshal(rules)
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// convergeRoleContent updates the rules of the Roles created for groupPermission that differ from the
+// rules of their Permission, and returns their status. A Role whose rules no longer match the rules
+// hash the operator recorded on it was changed outside the operator and is reported as drifted. The
+// rules the requester of groupPermission may not grant are not written. The Roles no Permission resolves
+// to anymore are left to the binding controller, which deletes them.
+func (r *ReconcileRoleContent) convergeRoleContent(groupPermission *managedv1alpha1.GroupPermission, operatorConfig *operatorconfig.OperatorConfig, now time.Time) (*managedv1alpha1.RoleContentStatus, error) {
+	namespaceList, err := r.listNamespaces(groupPermission)
+	if err != nil {
+		return nil, err
+	}
+	desired := desiredRoles(groupPermission, r.namespaces.allowedNamespaces(groupPermission, unprotectedNamespaces(namespaceList, operatorConfig)))
+
+	roleList, err := r.listManagedRoles(groupPermission)
+	if err != nil {
+		return nil, err
+	}
+	status := &managedv1alpha1.RoleContentStatus{ObservedGeneration: groupPermission.Generation, Roles: len(roleList.Items)}
+	if previous := groupPermission.Status.RoleContent; previous != nil {
+		status.LastDriftTime = previous.LastDriftTime
+	}
+
+	for i := range roleList.Items {
+		role := &roleList.Items[i]
+		key := role.Namespace + "/" + role.Name
+		permission, ok := desired[key]
+		if !ok || reflect.DeepEqual(role.Rules, permission.Rules) {
+			continue
+		}
+		if recorded, ok := role.Annotations[operatorconfig.RulesHashAnnotation]; ok && recorded != rulesHash(role.Rules) {
+			status.DriftedRoles = append(status.DriftedRoles, key)
+		}
+		// the rules are written with the rights of the operator, the requester must be allowed to grant them
+		escalationAllowed, err := r.isRoleEscalationAllowed(groupPermission, v1.RoleRef{APIGroup: v1.GroupName, Kind: "Role", Name: role.Name}, permission.Rules, role.Namespace, operatorConfig)
+		if err != nil {
+			return nil, err
+		}
+		if !escalationAllowed {
+			log.Info("Skipping the rules of the Role", "Request.Namespace", groupPermission.Namespace, "Request.Name", groupPermission.Name,
+				"Role", key, "Reason", escalationDeniedMessage(groupPermission, role.Name))
+			continue
+		}
+		if err := r.updateRoleRules(groupPermission, role, permission.Rules); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(status.DriftedRoles) > 0 {
+		sort.Strings(status.DriftedRoles)
+		driftTime := metav1.NewTime(now)
+		status.LastDriftTime = &driftTime
+		r.eventf(groupPermission, corev1.EventTypeWarning, roleContentDriftReason,
+			"Restored the rules of the Roles changed outside the operator: %v", status.DriftedRoles)
+	}
+	return status, nil
+}
+
+// updateRoleRules sets the rules of role, managed for groupPermission, to rules
+func (r *ReconcileRoleContent) updateRoleRules(groupPermission *managedv1alpha1.GroupPermission, role *v1.Role, rules []v1.PolicyRule) error {
+	defer bindinglock.Lock(bindinglock.Role, role.Namespace, role.Name)()
+
+	role.Rules = rules
+	utility.SetSourceAnnotations(&role.ObjectMeta, groupPermission)
+	role.Annotations[operatorconfig.RulesHashAnnotation] = rulesHash(rules)
+	return r.apiClient.Update(context.TODO(), role)
+}
@@ -0,0 +1,80 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+)
+
+// TestConvergeRoleContent tests the convergeRoleContent function
+// given: a Role edited outside the operator, a Role created for rules since changed in the spec, and a
+// Role of a Namespace no longer allowed
+// expected: both Roles of allowed Namespaces get the rules of the spec, only the edited one is reported
+// as drifted with a RoleContentDrift event, the other Role is left to the binding controller
+func TestConvergeRoleContent(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockRulesGroupPermission()
+	edited := newRole(groupPermission, "team-a", groupPermission.Spec.Permissions[0])
+	edited.Rules = []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}
+	previous := groupPermission.DeepCopy()
+	previous.Spec.Permissions[0].Rules = []rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get"}}}
+	outdated := newRole(previous, "team-b", previous.Spec.Permissions[0])
+	unmatched := newRole(previous, "legacy", previous.Spec.Permissions[0])
+
+	fakeClient := newRequesterClient(mockNamespace("team-a"), mockNamespace("team-b"), mockNamespace("legacy"), edited, outdated, unmatched)
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ReconcileRoleContent{ReconcileGroupPermission: &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  recorder,
+	}}
+
+	now := time.Now()
+	status, err := reconciler.convergeRoleContent(groupPermission, operatorconfig.DefaultOperatorConfig(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Roles != 3 || !reflect.DeepEqual(status.DriftedRoles, []string{"team-a/deployer"}) || status.LastDriftTime == nil {
+		t.Errorf("Mismatch for status. Expected(3 Roles, team-a/deployer drifted), Found(%+v)", status)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, roleContentDriftReason) {
+		t.Errorf("Mismatch for event. Expected(%s), Found(%s)", roleContentDriftReason, event)
+	}
+
+	for namespace, expected := range map[string][]rbacv1.PolicyRule{
+		"team-a": groupPermission.Spec.Permissions[0].Rules,
+		"team-b": groupPermission.Spec.Permissions[0].Rules,
+		"legacy": previous.Spec.Permissions[0].Rules,
+	} {
+		role := &rbacv1.Role{}
+		if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "deployer"}, role); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(role.Rules, expected) {
+			t.Errorf("Mismatch for the rules of %s. Expected(%+v), Found(%+v)", namespace, expected, role.Rules)
+		}
+	}
+
+	groupPermission.Status.RoleContent = status
+	status, err = reconciler.convergeRoleContent(groupPermission, operatorconfig.DefaultOperatorConfig(), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(status.DriftedRoles) != 0 || status.LastDriftTime == nil || !status.LastDriftTime.Time.Equal(groupPermission.Status.RoleContent.LastDriftTime.Time) {
+		t.Errorf("expected no drift once converged and the last drift time kept, got %+v", status)
+	}
+}
@@ -0,0 +1,90 @@
+package grouppermission
+
+import (
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// rolloutCheckpointInterval is the number of RoleBindings applied between two checkpoints of a rollout
+const rolloutCheckpointInterval = 100
+
+// rollout tracks the progress of the rollout of the RoleBindings of a GroupPermission, applied by
+// Permission then by Namespace in name order, so it can be checkpointed in the status and resumed by
+// the operator instance taking over after a restart or a change of leader
+type rollout struct {
+	// resumed is the checkpoint of the same desired state the rollout resumes from, nil otherwise
+	resumed *managedv1alpha1.RolloutCheckpoint
+	// checkpoint is how far the rollout went without a failure
+	checkpoint managedv1alpha1.RolloutCheckpoint
+	// failed is whether a RoleBinding failed, the checkpoint no longer moves afterwards
+	failed bool
+	// unsaved is the number of RoleBindings applied since the checkpoint was last saved
+	unsaved int
+	// atomic is whether the GroupPermission is atomic, its rollouts are applied whole and never resumed
+	atomic bool
+}
+
+// newRollout returns the rollout of the RoleBindings of groupPermission in the Namespaces of
+// namespaceList, resuming the checkpoint of its status when it is for the same desired state. The
+// rollouts of an atomic groupPermission are not checkpointed, a rollback removes what they applied.
+func newRollout(groupPermission *managedv1alpha1.GroupPermission, namespaceList *corev1.NamespaceList) *rollout {
+	p := &rollout{checkpoint: managedv1alpha1.RolloutCheckpoint{Hash: desiredStateHash(groupPermission, namespaceList)}, atomic: groupPermission.Spec.Atomic}
+	if checkpoint := groupPermission.Status.Rollout; checkpoint != nil && checkpoint.Hash == p.checkpoint.Hash && !p.atomic {
+		p.resumed = checkpoint.DeepCopy()
+	}
+	return p
+}
+
+// done returns whether the RoleBinding of the Permission of index permission in namespace was applied
+// before the checkpoint the rollout resumes from, it only needs to still exist
+func (p *rollout) done(permission int, namespace string) bool {
+	if p.resumed == nil {
+		return false
+	}
+	return permission < p.resumed.Permission || (permission == p.resumed.Permission && namespace <= p.resumed.Namespace)
+}
+
+// record records whether the RoleBinding of the Permission of index permission in namespace was applied,
+// and returns whether a checkpoint is due
+func (p *rollout) record(permission int, namespace string, applied bool) bool {
+	if p.atomic {
+		return false
+	}
+	if !applied {
+		p.failed = true
+	}
+	if p.failed {
+		return false
+	}
+	p.checkpoint.Permission = permission
+	p.checkpoint.Namespace = namespace
+	p.checkpoint.Applied++
+	if p.done(permission, namespace) {
+		// applied before, nothing new to save
+		return false
+	}
+	p.unsaved++
+	return p.unsaved >= rolloutCheckpointInterval
+}
+
+// saveRollout records the checkpoint of p in the status of groupPermission and writes it
+func (r *ReconcileGroupPermission) saveRollout(groupPermission *managedv1alpha1.GroupPermission, p *rollout) error {
+	checkpoint := p.checkpoint
+	groupPermission.Status.Rollout = &checkpoint
+	if err := r.updateStatus(groupPermission); err != nil {
+		return err
+	}
+	p.unsaved = 0
+	return nil
+}
+
+// finishRollout clears the checkpoint of the status of groupPermission once every RoleBinding was
+// processed, whether applied or failed
+func (r *ReconcileGroupPermission) finishRollout(groupPermission *managedv1alpha1.GroupPermission) error {
+	if groupPermission.Status.Rollout == nil {
+		return nil
+	}
+	groupPermission.Status.Rollout = nil
+	return r.updateStatus(groupPermission)
+}
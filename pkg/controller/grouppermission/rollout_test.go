@@ -0,0 +1,110 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestRolloutRecord tests the record function of a rollout
+// given: RoleBindings applied past the checkpoint interval, then a failed one
+// expected: a checkpoint is due at the interval, the checkpoint stops at the last RoleBinding applied
+// before the failure
+func TestRolloutRecord(t *testing.T) {
+	p := &rollout{}
+	for i := 0; i < rolloutCheckpointInterval; i++ {
+		expected := i == rolloutCheckpointInterval-1
+		if due := p.record(0, fmt.Sprintf("team-%03d", i), true); due != expected {
+			t.Errorf("Mismatch for checkpoint due after %d RoleBindings. Expected(%t), Found(%t)", i+1, expected, due)
+		}
+	}
+	p.unsaved = 0
+	p.record(1, "team-a", true)
+	p.record(1, "team-b", false)
+	if p.record(1, "team-c", true) {
+		t.Errorf("expected no checkpoint due after a failure")
+	}
+
+	expected := v1alpha1.RolloutCheckpoint{Permission: 1, Namespace: "team-a", Applied: rolloutCheckpointInterval + 1}
+	if p.checkpoint != expected {
+		t.Errorf("Mismatch for checkpoint. Expected(%+v), Found(%+v)", expected, p.checkpoint)
+	}
+}
+
+// TestReconcileNamespacePermissionsResume tests the reconcileNamespacePermissions function resuming a rollout
+// given: a GroupPermission with a checkpoint of its desired state in team-b, allowed in team-a, team-b and
+// team-c, the RoleBinding of team-a applied and that of team-b deleted since, requested by a user allowed or
+// no longer allowed to grant the role
+// expected: the RoleBinding of team-a is reported Created without checks, those of team-b and team-c are
+// checked and created when allowed, and the checkpoint is cleared
+func TestReconcileNamespacePermissionsResume(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	var tests = []struct {
+		name     string
+		allowed  bool
+		expected []v1alpha1.GroupPermissionState
+	}{
+		{"allowed requester", true, []v1alpha1.GroupPermissionState{v1alpha1.GroupPermissionCreated, v1alpha1.GroupPermissionCreated, v1alpha1.GroupPermissionCreated}},
+		{"denied requester", false, []v1alpha1.GroupPermissionState{v1alpha1.GroupPermissionCreated, v1alpha1.GroupPermissionEscalationDenied, v1alpha1.GroupPermissionEscalationDenied}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			groupPermission := mockNamespacedGroupPermission()
+			namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{*mockNamespace("team-a"), *mockNamespace("team-b"), *mockNamespace("team-c")}}
+			groupPermission.Status.Rollout = &v1alpha1.RolloutCheckpoint{Hash: desiredStateHash(groupPermission, namespaceList), Namespace: "team-b", Applied: 2}
+			applied := newRoleBinding("team-a", permissionRoleRef(&groupPermission.Spec, groupPermission.Spec.Permissions[0]), utility.GroupSubject(&groupPermission.Spec))
+			utility.SetManagedLabels(&applied.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+			fakeClient := &accessReviewClient{
+				Client:  fake.NewFakeClient(groupPermission.DeepCopy(), mockNamespace("team-c"), mockNamespace("team-b"), mockNamespace("team-a"), applied),
+				allowed: map[string]bool{"cluster-admin": test.allowed},
+			}
+			reconciler := &ReconcileGroupPermission{
+				client:    fakeClient,
+				apiClient: fakeClient,
+				scheme:    scheme.Scheme,
+			}
+
+			namespaceStatuses, err := reconciler.reconcileNamespacePermissions(groupPermission, nil, nil, nil, operatorconfig.DefaultOperatorConfig())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var states []v1alpha1.GroupPermissionState
+			for _, namespaceStatus := range namespaceStatuses {
+				states = append(states, namespaceStatus.State)
+			}
+			if !reflect.DeepEqual(states, test.expected) {
+				t.Errorf("Mismatch for namespace states. Expected(%v), Found(%v)", test.expected, states)
+			}
+			for i, namespace := range []string{"team-a", "team-b", "team-c"} {
+				err := reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "admin-exampleGroupName"}, &rbacv1.RoleBinding{})
+				if created := test.expected[i] == v1alpha1.GroupPermissionCreated; (err == nil) != created {
+					t.Errorf("Mismatch for the RoleBinding of %s. Expected(%v), Found(%v)", namespace, created, err)
+				}
+			}
+			instance := &v1alpha1.GroupPermission{}
+			if err := reconciler.client.Get(context.TODO(), types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}, instance); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if instance.Status.Rollout != nil {
+				t.Errorf("Mismatch for rollout. Expected(nil), Found(%+v)", instance.Status.Rollout)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package grouppermission
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// scheduleHorizon is how far ahead the windows of a schedule are expanded to find the next transition,
+// every window repeats within a week
+const scheduleHorizon = 8 * 24 * time.Hour
+
+// scheduleWindow is a parsed managedv1alpha1.ScheduleWindow
+type scheduleWindow struct {
+	// days the window starts on, indexed by time.Weekday
+	days [7]bool
+	// start and end are the hour and minute of the bounds of the window
+	startHour, startMinute int
+	endHour, endMinute     int
+}
+
+// interval is a period of time during which the bindings of a schedule exist
+type interval struct {
+	start, end time.Time
+}
+
+// validateSchedule returns an error describing the first invalid field of schedule, if any
+func validateSchedule(schedule *managedv1alpha1.Schedule) error {
+	if schedule == nil {
+		return nil
+	}
+	_, _, err := parseSchedule(schedule)
+	return err
+}
+
+// parseSchedule returns the location and the parsed windows of schedule
+func parseSchedule(schedule *managedv1alpha1.Schedule) (*time.Location, []scheduleWindow, error) {
+	location, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule time zone %q: %v", schedule.TimeZone, err)
+	}
+
+	windows := make([]scheduleWindow, 0, len(schedule.Windows))
+	for i, window := range schedule.Windows {
+		parsed := scheduleWindow{}
+		if parsed.days, err = parseDays(window.Days); err != nil {
+			return nil, nil, fmt.Errorf("schedule window %d: %v", i, err)
+		}
+		if parsed.startHour, parsed.startMinute, err = parseTimeOfDay(window.Start); err != nil {
+			return nil, nil, fmt.Errorf("schedule window %d: %v", i, err)
+		}
+		if parsed.endHour, parsed.endMinute, err = parseTimeOfDay(window.End); err != nil {
+			return nil, nil, fmt.Errorf("schedule window %d: %v", i, err)
+		}
+		windows = append(windows, parsed)
+	}
+	return location, windows, nil
+}
+
+// parseDays parses days of the week in cron notation, such as 1-5 or 0,6. Empty or * is every day.
+func parseDays(days string) ([7]bool, error) {
+	var parsed [7]bool
+	if days == "" || days == "*" {
+		for i := range parsed {
+			parsed[i] = true
+		}
+		return parsed, nil
+	}
+
+	for _, field := range strings.Split(days, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(field), "-", 2)
+		first, err := parseDay(bounds[0])
+		if err != nil {
+			return parsed, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = parseDay(bounds[1]); err != nil {
+				return parsed, err
+			}
+		}
+		if last < first {
+			return parsed, fmt.Errorf("invalid days range %q", field)
+		}
+		for day := first; day <= last; day++ {
+			// 7 is Sunday as in cron
+			parsed[day%7] = true
+		}
+	}
+	return parsed, nil
+}
+
+// parseDay parses a day of the week from 0 to 7, both Sunday
+func parseDay(day string) (int, error) {
+	parsed, err := strconv.Atoi(day)
+	if err != nil || parsed < 0 || parsed > 7 {
+		return 0, fmt.Errorf("invalid day %q, must be from 0 to 7", day)
+	}
+	return parsed, nil
+}
+
+// parseTimeOfDay parses a HH:MM time of day
+func parseTimeOfDay(timeOfDay string) (int, int, error) {
+	parsed, err := time.Parse("15:04", timeOfDay)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q, must be HH:MM", timeOfDay)
+	}
+	return parsed.Hour(), parsed.Minute(), nil
+}
+
+// scheduleState returns whether now is in a window of schedule, and when the bindings are next
+// created or removed. The next transition is zero when the schedule has no window.
+func scheduleState(schedule *managedv1alpha1.Schedule, now time.Time) (bool, time.Time, error) {
+	location, windows, err := parseSchedule(schedule)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	for _, current := range scheduleIntervals(windows, now.In(location)) {
+		if now.Before(current.start) {
+			return false, current.start, nil
+		}
+		if now.Before(current.end) {
+			return true, current.end, nil
+		}
+	}
+	return false, time.Time{}, nil
+}
+
+// scheduleIntervals returns the sorted and merged intervals of windows from the day before now up to
+// the horizon, so the window started the day before and still open is included
+func scheduleIntervals(windows []scheduleWindow, now time.Time) []interval {
+	var intervals []interval
+	year, month, day := now.AddDate(0, 0, -1).Date()
+	for offset := 0; offset <= int(scheduleHorizon/(24*time.Hour)); offset++ {
+		date := time.Date(year, month, day+offset, 0, 0, 0, 0, now.Location())
+		for _, window := range windows {
+			if !window.days[date.Weekday()] {
+				continue
+			}
+			start := time.Date(date.Year(), date.Month(), date.Day(), window.startHour, window.startMinute, 0, 0, now.Location())
+			end := time.Date(date.Year(), date.Month(), date.Day(), window.endHour, window.endMinute, 0, 0, now.Location())
+			if !end.After(start) {
+				end = time.Date(date.Year(), date.Month(), date.Day()+1, window.endHour, window.endMinute, 0, 0, now.Location())
+			}
+			intervals = append(intervals, interval{start: start, end: end})
+		}
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+	var merged []interval
+	for _, current := range intervals {
+		if last := len(merged) - 1; last >= 0 && !current.start.After(merged[last].end) {
+			if current.end.After(merged[last].end) {
+				merged[last].end = current.end
+			}
+			continue
+		}
+		merged = append(merged, current)
+	}
+	return merged
+}
+
+// requeueBefore shortens the requeue of result so the GroupPermission is reconciled at transition
+func requeueBefore(result reconcile.Result, transition time.Time) reconcile.Result {
+	if transition.IsZero() {
+		return result
+	}
+	untilTransition := time.Until(transition)
+	if untilTransition <= 0 {
+		untilTransition = time.Second
+	}
+	if result.RequeueAfter <= 0 || untilTransition < result.RequeueAfter {
+		result.RequeueAfter = untilTransition
+	}
+	return result
+}
+
+// updateScheduleStatus records the state of the schedule of groupPermission, it returns whether the
+// status changed
+func updateScheduleStatus(groupPermission *managedv1alpha1.GroupPermission, active bool, nextTransition time.Time) bool {
+	status := &managedv1alpha1.ScheduleStatus{Active: active}
+	if !nextTransition.IsZero() {
+		status.NextTransitionTime = &metav1.Time{Time: nextTransition}
+	}
+
+	current := groupPermission.Status.Schedule
+	if current != nil && current.Active == status.Active && current.NextTransitionTime.Equal(status.NextTransitionTime) {
+		return false
+	}
+	groupPermission.Status.Schedule = status
+	return true
+}
+
+// reconcileSchedule records the state of the schedule of groupPermission and removes its bindings
+// outside of the windows. It returns whether the bindings may exist, and the next transition.
+func (r *ReconcileGroupPermission) reconcileSchedule(groupPermission *managedv1alpha1.GroupPermission) (bool, time.Time, error) {
+	if groupPermission.Spec.Schedule == nil {
+		if groupPermission.Status.Schedule != nil {
+			groupPermission.Status.Schedule = nil
+			return true, time.Time{}, r.updateStatus(groupPermission)
+		}
+		return true, time.Time{}, nil
+	}
+
+	active, nextTransition, err := scheduleState(groupPermission.Spec.Schedule, time.Now())
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	if !active {
+		if _, err := r.reconcileNamespaceInventory(groupPermission, nil); err != nil {
+			return false, nextTransition, err
+		}
+		if err := r.deleteManagedBindings(groupPermission); err != nil {
+			return false, nextTransition, err
+		}
+	}
+
+	changed := updateScheduleStatus(groupPermission, active, nextTransition)
+	if !active && (groupPermission.Status.Namespaces != nil || groupPermission.Status.NamespaceSummary != nil) {
+		// the RoleBindings were removed
+		groupPermission.Status.Namespaces = nil
+		groupPermission.Status.NamespaceSummary = nil
+		changed = true
+	}
+	if changed {
+		if err := r.updateStatus(groupPermission); err != nil {
+			return active, nextTransition, err
+		}
+	}
+	return active, nextTransition, nil
+}
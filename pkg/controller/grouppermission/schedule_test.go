@@ -0,0 +1,169 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestValidateSchedule tests the validateSchedule function
+// given: valid and invalid schedules
+// expected: an error for the invalid time zones, days and times only
+func TestValidateSchedule(t *testing.T) {
+	tests := []struct {
+		label    string
+		schedule *managedv1alpha1.Schedule
+		valid    bool
+	}{
+		{"no schedule", nil, true},
+		{"business hours", &managedv1alpha1.Schedule{TimeZone: "Europe/Paris", Windows: []managedv1alpha1.ScheduleWindow{{Days: "1-5", Start: "09:00", End: "18:00"}}}, true},
+		{"lists and Sunday as 7", &managedv1alpha1.Schedule{Windows: []managedv1alpha1.ScheduleWindow{{Days: "0,3,5-7", Start: "22:00", End: "02:00"}}}, true},
+		{"unknown time zone", &managedv1alpha1.Schedule{TimeZone: "Mars/Olympus", Windows: []managedv1alpha1.ScheduleWindow{{Start: "09:00", End: "18:00"}}}, false},
+		{"day out of range", &managedv1alpha1.Schedule{Windows: []managedv1alpha1.ScheduleWindow{{Days: "1-8", Start: "09:00", End: "18:00"}}}, false},
+		{"reversed range", &managedv1alpha1.Schedule{Windows: []managedv1alpha1.ScheduleWindow{{Days: "5-1", Start: "09:00", End: "18:00"}}}, false},
+		{"invalid time", &managedv1alpha1.Schedule{Windows: []managedv1alpha1.ScheduleWindow{{Start: "9am", End: "18:00"}}}, false},
+	}
+	for _, test := range tests {
+		if err := validateSchedule(test.schedule); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+		}
+	}
+}
+
+// TestScheduleState tests the scheduleState function
+// given: business hours on week days and a window spanning midnight, at various times
+// expected: whether the time is in a window, and the next time the bindings are created or removed
+func TestScheduleState(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	businessHours := &managedv1alpha1.Schedule{
+		TimeZone: "Europe/Paris",
+		Windows:  []managedv1alpha1.ScheduleWindow{{Days: "1-5", Start: "09:00", End: "18:00"}},
+	}
+	nights := &managedv1alpha1.Schedule{
+		Windows: []managedv1alpha1.ScheduleWindow{{Days: "5", Start: "22:00", End: "02:00"}},
+	}
+
+	// 2019-06-03 is a Monday
+	tests := []struct {
+		label          string
+		schedule       *managedv1alpha1.Schedule
+		now            time.Time
+		active         bool
+		nextTransition time.Time
+	}{
+		{"monday morning", businessHours, time.Date(2019, 6, 3, 8, 0, 0, 0, paris), false, time.Date(2019, 6, 3, 9, 0, 0, 0, paris)},
+		{"monday noon", businessHours, time.Date(2019, 6, 3, 12, 0, 0, 0, paris), true, time.Date(2019, 6, 3, 18, 0, 0, 0, paris)},
+		{"noon in UTC", businessHours, time.Date(2019, 6, 3, 12, 0, 0, 0, time.UTC), true, time.Date(2019, 6, 3, 18, 0, 0, 0, paris)},
+		{"friday evening", businessHours, time.Date(2019, 6, 7, 18, 0, 0, 0, paris), false, time.Date(2019, 6, 10, 9, 0, 0, 0, paris)},
+		{"friday night", nights, time.Date(2019, 6, 7, 23, 0, 0, 0, time.UTC), true, time.Date(2019, 6, 8, 2, 0, 0, 0, time.UTC)},
+		{"saturday after midnight", nights, time.Date(2019, 6, 8, 1, 0, 0, 0, time.UTC), true, time.Date(2019, 6, 8, 2, 0, 0, 0, time.UTC)},
+		{"saturday morning", nights, time.Date(2019, 6, 8, 9, 0, 0, 0, time.UTC), false, time.Date(2019, 6, 14, 22, 0, 0, 0, time.UTC)},
+		{"no window", &managedv1alpha1.Schedule{}, time.Date(2019, 6, 3, 12, 0, 0, 0, time.UTC), false, time.Time{}},
+	}
+	for _, test := range tests {
+		active, nextTransition, err := scheduleState(test.schedule, test.now)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.label, err)
+		}
+		if active != test.active || !nextTransition.Equal(test.nextTransition) {
+			t.Errorf("%s: got active=%t next=%s, want active=%t next=%s", test.label, active, nextTransition, test.active, test.nextTransition)
+		}
+	}
+}
+
+// TestScheduleStateOverlapping tests the scheduleState function
+// given: overlapping windows
+// expected: the bindings are removed at the end of the last overlapping window
+func TestScheduleStateOverlapping(t *testing.T) {
+	schedule := &managedv1alpha1.Schedule{
+		Windows: []managedv1alpha1.ScheduleWindow{{Start: "09:00", End: "12:00"}, {Start: "11:00", End: "14:00"}},
+	}
+	active, nextTransition, err := scheduleState(schedule, time.Date(2019, 6, 3, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !active || !nextTransition.Equal(time.Date(2019, 6, 3, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("got active=%t next=%s, want the bindings until 14:00", active, nextTransition)
+	}
+}
+
+// TestRequeueBefore tests the requeueBefore function
+// given: results requeued before and after a transition
+// expected: the earliest of the requeue and the transition
+func TestRequeueBefore(t *testing.T) {
+	if result := requeueBefore(reconcile.Result{RequeueAfter: time.Hour}, time.Time{}); result.RequeueAfter != time.Hour {
+		t.Errorf("expected the requeue unchanged without transition, got %s", result.RequeueAfter)
+	}
+	if result := requeueBefore(reconcile.Result{RequeueAfter: time.Minute}, time.Now().Add(time.Hour)); result.RequeueAfter != time.Minute {
+		t.Errorf("expected the requeue before the transition unchanged, got %s", result.RequeueAfter)
+	}
+	if result := requeueBefore(reconcile.Result{}, time.Now().Add(time.Hour)); result.RequeueAfter <= 59*time.Minute || result.RequeueAfter > time.Hour {
+		t.Errorf("expected a requeue at the transition, got %s", result.RequeueAfter)
+	}
+}
+
+// TestReconcileSchedule tests the Reconcile function
+// given: a scheduled GroupPermission with a managed RoleBinding, outside of its window
+// expected: the RoleBinding is removed, the schedule status records the next window and the phase is Pending
+func TestReconcileSchedule(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	// a window starting in an hour, every day
+	now := time.Now().UTC()
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	groupPermission.Spec.Schedule = &managedv1alpha1.Schedule{Windows: []managedv1alpha1.ScheduleWindow{{
+		Start: now.Add(time.Hour).Format("15:04"),
+		End:   now.Add(2 * time.Hour).Format("15:04"),
+	}}}
+	roleBinding := newRoleBinding("team-a", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+
+	fakeClient := fake.NewFakeClient(groupPermission, roleBinding, mockNamespace("team-a"))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}}
+
+	result, err := reconciler.Reconcile(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter <= 0 || result.RequeueAfter > time.Hour {
+		t.Errorf("expected a requeue at the start of the window, got %s", result.RequeueAfter)
+	}
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: roleBinding.Name}, &rbacv1.RoleBinding{})
+	if err == nil {
+		t.Errorf("expected the RoleBinding to be removed outside of the window")
+	}
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if instance.Status.Schedule == nil || instance.Status.Schedule.Active || instance.Status.Schedule.NextTransitionTime == nil {
+		t.Errorf("expected an inactive schedule with the next transition, got %+v", instance.Status.Schedule)
+	}
+	if instance.Status.Phase != managedv1alpha1.GroupPermissionPhasePending {
+		t.Errorf("expected phase Pending, got %s", instance.Status.Phase)
+	}
+}
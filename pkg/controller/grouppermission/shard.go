@@ -0,0 +1,163 @@
+package grouppermission
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// shard selects the GroupPermissions reconciled by an instance of the operator, so clusters with
+// thousands of GroupPermissions can be split across instances started with disjoint selectors
+type shard struct {
+	selector labels.Selector
+	// namespaces and namespaceSelector select the Namespaces of the GroupPermissions of the shard,
+	// every Namespace when both are empty
+	namespaces        map[string]bool
+	namespaceSelector labels.Selector
+	// reader reads the labels of the Namespaces matched against namespaceSelector
+	reader client.Reader
+
+	mutex sync.Mutex
+	// members holds the GroupPermissions of the shard reconciled so far
+	members map[types.NamespacedName]bool
+}
+
+// newShard returns a shard of the GroupPermissions matching selector
+func newShard(selector labels.Selector) *shard {
+	return &shard{
+		selector:          selector,
+		namespaces:        map[string]bool{},
+		namespaceSelector: labels.Everything(),
+		members:           map[types.NamespacedName]bool{},
+	}
+}
+
+// matches returns whether the object is in the shard, a nil shard holds every object
+func (s *shard) matches(object metav1.Object) bool {
+	return s == nil || (s.selector.Matches(labels.Set(object.GetLabels())) && s.inNamespaces(object.GetNamespace()))
+}
+
+// inNamespaces returns whether the GroupPermissions of namespace are in the shard. A Namespace that
+// cannot be read is not matched against the namespace selector.
+func (s *shard) inNamespaces(namespace string) bool {
+	if len(s.namespaces) == 0 && s.namespaceSelector.Empty() {
+		return true
+	}
+	if s.namespaces[namespace] {
+		return true
+	}
+	if s.namespaceSelector.Empty() || s.reader == nil {
+		return false
+	}
+	ns := &corev1.Namespace{}
+	if err := s.reader.Get(context.TODO(), types.NamespacedName{Name: namespace}, ns); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get Namespace of the shard", "Namespace", namespace)
+		}
+		return false
+	}
+	return s.namespaceSelector.Matches(labels.Set(ns.Labels))
+}
+
+// String returns the label selector and the Namespaces of the shard, empty for the shard of every
+// GroupPermission
+func (s *shard) String() string {
+	var parts []string
+	if !s.selector.Empty() {
+		parts = append(parts, s.selector.String())
+	}
+	if len(s.namespaces) > 0 {
+		var namespaces []string
+		for namespace := range s.namespaces {
+			namespaces = append(namespaces, namespace)
+		}
+		sort.Strings(namespaces)
+		parts = append(parts, "namespaces="+strings.Join(namespaces, ","))
+	}
+	if !s.namespaceSelector.Empty() {
+		parts = append(parts, "namespaceSelector="+s.namespaceSelector.String())
+	}
+	return strings.Join(parts, ";")
+}
+
+// record adds or removes key from the members of the shard and updates the shard metric
+func (s *shard) record(key types.NamespacedName, member bool) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if member == s.members[key] {
+		return
+	}
+	if member {
+		s.members[key] = true
+	} else {
+		delete(s.members, key)
+	}
+	localmetrics.SetShardGroupPermissions(s.String(), len(s.members))
+}
+
+// predicate returns the predicate filtering the GroupPermission events of the shard. An update of
+// a GroupPermission moved out of the shard passes, so its reconcile removes it from the members.
+func (s *shard) predicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(evt event.CreateEvent) bool {
+			return s.matches(evt.Meta)
+		},
+		UpdateFunc: func(evt event.UpdateEvent) bool {
+			return s.matches(evt.MetaOld) || s.matches(evt.MetaNew)
+		},
+		DeleteFunc: func(evt event.DeleteEvent) bool {
+			return s.matches(evt.Meta)
+		},
+		GenericFunc: func(evt event.GenericEvent) bool {
+			return s.matches(evt.Meta)
+		},
+	}
+}
+
+// inShard returns whether the GroupPermission of request is reconciled by this instance of the
+// operator. A GroupPermission that no longer exists is reconciled, to forget its state.
+func (r *ReconcileGroupPermission) inShard(request reconcile.Request) (bool, error) {
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		if errors.IsNotFound(err) {
+			r.shard.record(request.NamespacedName, false)
+			return true, nil
+		}
+		return false, err
+	}
+	member := r.shard.matches(instance)
+	r.shard.record(request.NamespacedName, member)
+	return member, nil
+}
+
+// OwnerInShard returns whether the bindings of the GroupPermission owner, which may no longer exist,
+// belong to the shard selected by opts, reading the labels of the Namespaces with reader. A deleted
+// GroupPermission has no labels left, its bindings belong to the shards of its Namespace whose selector
+// matches no labels, such as the shard of every GroupPermission.
+func OwnerInShard(reader client.Reader, opts ...Option) (func(owner types.NamespacedName) bool, error) {
+	s, err := newReconcilerOptions(opts).shard(reader)
+	if err != nil {
+		return nil, err
+	}
+	return func(owner types.NamespacedName) bool {
+		return s.matches(&metav1.ObjectMeta{Namespace: owner.Namespace, Name: owner.Name})
+	}, nil
+}
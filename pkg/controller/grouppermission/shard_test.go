@@ -0,0 +1,198 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestWithShard tests the shard of the WithShard Option
+// given: valid and invalid label selectors
+// expected: the selector of the shard, or an error
+func TestWithShard(t *testing.T) {
+	s, err := newReconcilerOptions([]Option{WithShard("shard in (a, b)")}).shard(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.matches(&mockGroupPermission().ObjectMeta) {
+		t.Errorf("expected a GroupPermission without shard label not to match %s", s.selector)
+	}
+	if _, err := newReconcilerOptions([]Option{WithShard("shard in (")}).shard(nil); err == nil {
+		t.Errorf("expected an error for an invalid selector")
+	}
+	if s, err := newReconcilerOptions(nil).shard(nil); err != nil || !s.selector.Empty() {
+		t.Errorf("expected an empty selector to match everything, got %v, %v", s, err)
+	}
+}
+
+// TestWithWatchNamespaces tests the shard of the WithWatchNamespaces Option
+// given: a list of Namespaces and a Namespace selector
+// expected: GroupPermissions in the listed Namespaces or in Namespaces matching the selector match
+func TestWithWatchNamespaces(t *testing.T) {
+	reader := fake.NewFakeClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"team": "b"}}})
+	s, err := newReconcilerOptions([]Option{WithWatchNamespaces([]string{"rbac-permissions-operator"}, "team=a")}).shard(reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for namespace, expected := range map[string]bool{"rbac-permissions-operator": true, "team-a": true, "team-b": false, "missing": false} {
+		groupPermission := mockGroupPermission()
+		groupPermission.Namespace = namespace
+		if matches := s.matches(&groupPermission.ObjectMeta); matches != expected {
+			t.Errorf("Mismatch for Namespace %s. Expected(%v), Found(%v)", namespace, expected, matches)
+		}
+	}
+	if expected := "namespaces=rbac-permissions-operator;namespaceSelector=team=a"; s.String() != expected {
+		t.Errorf("Mismatch for String. Expected(%s), Found(%s)", expected, s.String())
+	}
+	if _, err := newReconcilerOptions([]Option{WithWatchNamespaces(nil, "team in (")}).shard(nil); err == nil {
+		t.Errorf("expected an error for an invalid selector")
+	}
+}
+
+// TestOwnerInShard tests the OwnerInShard function
+// given: shards of Namespaces and of label selectors matching or not a GroupPermission without labels
+// expected: the owners of the Namespaces of the shard are in it when its selector matches no labels
+func TestOwnerInShard(t *testing.T) {
+	owner := types.NamespacedName{Namespace: "team-a", Name: "deleted"}
+	var tests = []struct {
+		name     string
+		opts     []Option
+		expected bool
+	}{
+		{"every GroupPermission", nil, true},
+		{"Namespace of the owner", []Option{WithWatchNamespaces([]string{"team-a"}, "")}, true},
+		{"other Namespace", []Option{WithWatchNamespaces([]string{"team-b"}, "")}, false},
+		{"selector matching no labels", []Option{WithShard("shard!=b")}, true},
+		{"selector requiring a label", []Option{WithShard("shard=a")}, false},
+	}
+
+	for _, test := range tests {
+		inShard, err := OwnerInShard(fake.NewFakeClient(), test.opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if found := inShard(owner); found != test.expected {
+			t.Errorf("%s: Mismatch for owner in shard. Expected(%v), Found(%v)", test.name, test.expected, found)
+		}
+	}
+	if _, err := OwnerInShard(nil, WithShard("shard in (")); err == nil {
+		t.Errorf("expected an error for an invalid selector")
+	}
+}
+
+// TestShardPredicate tests the predicate of a shard
+// given: events of GroupPermissions in and out of the shard
+// expected: only the events of GroupPermissions in the shard, or leaving it, pass
+func TestShardPredicate(t *testing.T) {
+	s := newShard(labels.SelectorFromSet(labels.Set{"shard": "a"}))
+	inShard := mockGroupPermission()
+	inShard.Labels = map[string]string{"shard": "a"}
+	outOfShard := mockGroupPermission()
+	outOfShard.Labels = map[string]string{"shard": "b"}
+	p := s.predicate()
+
+	if !p.Create(event.CreateEvent{Meta: inShard, Object: inShard}) {
+		t.Errorf("expected the creation of a GroupPermission in the shard to pass")
+	}
+	if p.Create(event.CreateEvent{Meta: outOfShard, Object: outOfShard}) {
+		t.Errorf("expected the creation of a GroupPermission of another shard to be filtered")
+	}
+	if !p.Update(event.UpdateEvent{MetaOld: inShard, ObjectOld: inShard, MetaNew: outOfShard, ObjectNew: outOfShard}) {
+		t.Errorf("expected the update of a GroupPermission leaving the shard to pass")
+	}
+	if p.Delete(event.DeleteEvent{Meta: outOfShard, Object: outOfShard}) {
+		t.Errorf("expected the deletion of a GroupPermission of another shard to be filtered")
+	}
+}
+
+// TestReconcileShard tests the Reconcile function with a shard
+// given: a GroupPermission in the shard, then moved to another shard
+// expected: the GroupPermission is reconciled and counted in the shard, then ignored and no longer counted
+func TestReconcileShard(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Labels = map[string]string{"shard": "a"}
+	groupPermission.Spec.Permissions = nil
+	fakeClient := fake.NewFakeClient(groupPermission, mockNamespace("team-a"))
+	s := newShard(labels.SelectorFromSet(labels.Set{"shard": "a"}))
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  record.NewFakeRecorder(10),
+		shard:     s,
+	}
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}}
+	count := localmetrics.RBACShardGroupPermissions.WithLabelValues("shard=a")
+
+	if _, err := reconciler.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasFinalizer(instance) {
+		t.Errorf("expected the GroupPermission of the shard to be reconciled")
+	}
+	if value := testutil.ToFloat64(count); value != 1 {
+		t.Errorf("expected 1 GroupPermission in the shard, got %v", value)
+	}
+
+	instance.Labels["shard"] = "b"
+	instance.Finalizers = nil
+	if err := fakeClient.Update(context.TODO(), instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := reconciler.Reconcile(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), request.NamespacedName, instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hasFinalizer(instance) {
+		t.Errorf("expected the GroupPermission of another shard not to be reconciled")
+	}
+	if value := testutil.ToFloat64(count); value != 0 {
+		t.Errorf("expected no GroupPermission in the shard, got %v", value)
+	}
+}
+
+// TestNamespaceToGroupPermissionsShard tests the namespaceToGroupPermissions mapper with a shard
+// given: a Namespace allowed by a GroupPermission of another shard
+// expected: no request
+func TestNamespaceToGroupPermissionsShard(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	mapper := &namespaceToGroupPermissions{
+		client: fake.NewFakeClient(mockNamespacedGroupPermission()),
+		shard:  newShard(labels.SelectorFromSet(labels.Set{"shard": "a"})),
+	}
+	namespace := mockNamespace("team-a")
+	if requests := mapper.Map(handler.MapObject{Meta: namespace, Object: namespace}); len(requests) != 0 {
+		t.Errorf("expected no request for a GroupPermission of another shard, got %v", requests)
+	}
+}
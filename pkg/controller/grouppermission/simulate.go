@@ -0,0 +1,68 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/clusterinfo"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Simulate returns the changes to the bindings applying the candidate groupPermission would make at
+// now, from the bindings managed for the GroupPermission of the same namespace and name to the bindings
+// it resolves to, without changing anything. Like Verify, the requester escalation check and the policy
+// are not taken into account. It returns an error when the spec of groupPermission is invalid.
+func Simulate(ctx context.Context, c client.Client, groupPermission *managedv1alpha1.GroupPermission, now time.Time) (*Drift, error) {
+	if err := validateSpec(groupPermission); err != nil {
+		return nil, err
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := c.List(ctx, &client.ListOptions{}, namespaceList); err != nil {
+		return nil, err
+	}
+	clusterRoleList := &v1.ClusterRoleList{}
+	if err := c.List(ctx, &client.ListOptions{}, clusterRoleList); err != nil {
+		return nil, err
+	}
+	desired, valid := resolveBindings(groupPermission, namespaceList, clusterRoleList, now)
+	if !valid {
+		return nil, fmt.Errorf("invalid schedule")
+	}
+
+	if groupPermission.Spec.ClusterConditions != nil {
+		operatorConfig, err := operatorconfig.GetOperatorConfig(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		clusterLabels, err := clusterinfo.Labels(ctx, c, operatorConfig.ClusterLabels)
+		if err != nil {
+			return nil, err
+		}
+		// the GroupPermission grants nothing on a cluster it does not match
+		if matched, err := clusterConditionsMatch(groupPermission, clusterLabels); err != nil {
+			return nil, err
+		} else if !matched {
+			desired = map[string]verifiedBinding{}
+		}
+	}
+
+	opts := &client.ListOptions{LabelSelector: utility.ManagedForSelector(groupPermission.Namespace, groupPermission.Name)}
+	clusterRoleBindingList := &v1.ClusterRoleBindingList{}
+	if err := c.List(ctx, opts, clusterRoleBindingList); err != nil {
+		return nil, err
+	}
+	roleBindingList := &v1.RoleBindingList{}
+	if err := c.List(ctx, opts, roleBindingList); err != nil {
+		return nil, err
+	}
+	actual, managed := managedBindingsOf(groupPermission, clusterRoleBindingList, roleBindingList)
+	return diffBindings(desired, actual, managed), nil
+}
@@ -0,0 +1,65 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestSimulate tests the Simulate function
+// given: a candidate GroupPermission allowed in team-a and team-b, replacing a GroupPermission with a
+// RoleBinding in team-a and one in default, and an invalid candidate
+// expected: the RoleBinding of team-b is missing, the one of default unexpected and nothing is created,
+// the invalid candidate is an error
+func TestSimulate(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ClusterPermissions = nil
+	admin := mockClusterRole()
+	admin.Name = "admin"
+	managedRoleBinding := func(namespace string) *rbacv1.RoleBinding {
+		roleBinding := newRoleBinding(namespace, rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, mockGroupSubject("exampleGroupName"))
+		roleBinding.Name = "admin-exampleGroupName"
+		utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+		return roleBinding
+	}
+	fakeClient := fake.NewFakeClient(admin, mockNamespace("team-a"), mockNamespace("team-b"), mockNamespace("default"),
+		managedRoleBinding("team-a"), managedRoleBinding("default"))
+
+	drift, err := Simulate(context.TODO(), fakeClient, groupPermission, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &Drift{
+		Missing:    []string{"RoleBinding team-b/admin-exampleGroupName"},
+		Unexpected: []string{"RoleBinding default/admin-exampleGroupName"},
+	}
+	if !reflect.DeepEqual(drift, expected) {
+		t.Errorf("Mismatch for drift. Expected(%+v), Found(%+v)", expected, drift)
+	}
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := fakeClient.List(context.TODO(), &client.ListOptions{}, roleBindingList); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(roleBindingList.Items) != 2 {
+		t.Errorf("Mismatch for RoleBindings. Expected(2), Found(%d)", len(roleBindingList.Items))
+	}
+
+	invalid := groupPermission.DeepCopy()
+	invalid.Spec.Permissions = []v1alpha1.Permission{{ClusterRoleName: "admin", RoleName: "deployer"}}
+	if _, err := Simulate(context.TODO(), fakeClient, invalid, time.Now()); err == nil {
+		t.Errorf("expected an error for an invalid GroupPermission")
+	}
+}
@@ -0,0 +1,39 @@
+package grouppermission
+
+import (
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+)
+
+// matchedNamespaceCount returns the number of distinct Namespaces in which a Permission is allowed
+// according to allowed, a Namespace matched by several Permissions being counted once
+func matchedNamespaceCount(allowed []map[string]bool) int {
+	return len(matchedNamespaceNames(allowed))
+}
+
+// matchedNamespaceNames returns the names of the Namespaces in which a Permission is allowed according
+// to allowed
+func matchedNamespaceNames(allowed []map[string]bool) map[string]bool {
+	names := map[string]bool{}
+	for _, namespaces := range allowed {
+		for name := range namespaces {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// boundSubjectCount returns the number of distinct subjects bound by the RoleBindings managed for
+// groupPermission
+func (r *ReconcileGroupPermission) boundSubjectCount(groupPermission *managedv1alpha1.GroupPermission) (int, error) {
+	roleBindingList, err := r.listManagedRoleBindings(groupPermission)
+	if err != nil {
+		return 0, err
+	}
+	subjects := map[string]bool{}
+	for _, roleBinding := range roleBindingList.Items {
+		for _, subject := range roleBinding.Subjects {
+			subjects[subjectKey(subject)] = true
+		}
+	}
+	return len(subjects), nil
+}
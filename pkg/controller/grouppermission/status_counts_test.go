@@ -0,0 +1,64 @@
+package grouppermission
+
+import (
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestMatchedNamespaceCount tests the matchedNamespaceCount function
+// given: Permissions allowed in no Namespace, in distinct Namespaces and in the same Namespaces
+// expected: each Namespace is counted once
+func TestMatchedNamespaceCount(t *testing.T) {
+	var tests = []struct {
+		name     string
+		allowed  []map[string]bool
+		expected int
+	}{
+		{"no permission", nil, 0},
+		{"no namespace", []map[string]bool{{}}, 0},
+		{"distinct", []map[string]bool{{"team-a": true}, {"team-b": true, "team-c": true}}, 3},
+		{"overlapping", []map[string]bool{{"team-a": true, "team-b": true}, {"team-b": true}}, 2},
+	}
+	for _, test := range tests {
+		if count := matchedNamespaceCount(test.allowed); count != test.expected {
+			t.Errorf("%s: Mismatch for count. Expected(%d), Found(%d)", test.name, test.expected, count)
+		}
+	}
+}
+
+// TestBoundSubjectCount tests the boundSubjectCount method
+// given: RoleBindings managed for the GroupPermission binding the same Group in two Namespaces and
+// another Group in one, and a RoleBinding managed for another GroupPermission binding a third Group
+// expected: the two Groups bound for the GroupPermission are counted
+func TestBoundSubjectCount(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	roleRef := v1.RoleRef{Kind: "ClusterRole", Name: "admin"}
+	teamA := newRoleBinding("team-a", roleRef, mockGroupSubject("exampleGroupName"))
+	teamB := newRoleBinding("team-b", roleRef, mockGroupSubject("exampleGroupName"))
+	teamB.Subjects = append(teamB.Subjects, mockGroupSubject("otherGroupName"))
+	other := newRoleBinding("team-c", roleRef, mockGroupSubject("thirdGroupName"))
+	for _, roleBinding := range []*v1.RoleBinding{teamA, teamB} {
+		utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	}
+	utility.SetManagedLabels(&other.ObjectMeta, groupPermission.Namespace, "other")
+
+	fakeClient := fake.NewFakeClient(teamA, teamB, other)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+	count, err := reconciler.boundSubjectCount(groupPermission)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Mismatch for bound subjects. Expected(2), Found(%d)", count)
+	}
+}
@@ -0,0 +1,188 @@
+package grouppermission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/openshift/rbac-permissions-operator/pkg/tracing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+// statusBase is the status of a GroupPermission at a resourceVersion, the base of the patches of its
+// next status updates
+type statusBase struct {
+	resourceVersion string
+	status          *managedv1alpha1.GroupPermissionStatus
+}
+
+// patchOperation is an operation of a JSON patch (RFC 6902)
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// newStatusClient returns a REST client of the managed.openshift.io API, patching the status of GroupPermissions
+func newStatusClient(config *rest.Config, scheme *runtime.Scheme) (rest.Interface, error) {
+	config = rest.CopyConfig(config)
+	config.GroupVersion = &managedv1alpha1.SchemeGroupVersion
+	config.APIPath = "/apis"
+	config.ContentType = runtime.ContentTypeJSON
+	config.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: serializer.NewCodecFactory(scheme)}
+	return rest.RESTClientFor(config)
+}
+
+// recordStatus records the status of groupPermission as read or written at its resourceVersion
+func (r *ReconcileGroupPermission) recordStatus(groupPermission *managedv1alpha1.GroupPermission) {
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	r.statusBases.Store(key, statusBase{
+		resourceVersion: groupPermission.ResourceVersion,
+		status:          groupPermission.Status.DeepCopy(),
+	})
+}
+
+// updateStatus writes the status of groupPermission. The changes since its status was last recorded at
+// the same resourceVersion are sent as a JSON patch, guarded by a test of each changed condition, so
+// concurrent writes of other status fields and conditions do not conflict. The whole status is updated
+// when no status client is set or no status was recorded at the resourceVersion of groupPermission.
+func (r *ReconcileGroupPermission) updateStatus(groupPermission *managedv1alpha1.GroupPermission) error {
+	key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+	value, ok := r.statusBases.Load(key)
+	if r.statusClient == nil || !ok || value.(statusBase).resourceVersion != groupPermission.ResourceVersion {
+		if err := r.client.Status().Update(context.TODO(), groupPermission); err != nil {
+			return err
+		}
+		r.recordStatus(groupPermission)
+		return nil
+	}
+
+	patch, err := statusPatch(value.(statusBase).status, &groupPermission.Status)
+	if err != nil || patch == nil {
+		return err
+	}
+	patched := &managedv1alpha1.GroupPermission{}
+	r.budget.add(localmetrics.SourceAPIServer, "patch")
+	span := r.traces.request(localmetrics.SourceAPIServer, "patch", groupPermission)
+	span.SetAttributes(tracing.String("k8s.subresource", "status"))
+	err = r.statusClient.Patch(types.JSONPatchType).
+		Namespace(groupPermission.Namespace).
+		Resource("grouppermissions").
+		Name(groupPermission.Name).
+		SubResource("status").
+		Body(patch).
+		Do().
+		Into(patched)
+	endRequest(span, groupPermission, err)
+	if err != nil {
+		// a failed test means the recorded status is stale, the next update replaces the whole status
+		r.statusBases.Delete(key)
+		return err
+	}
+	patched.DeepCopyInto(groupPermission)
+	r.recordStatus(groupPermission)
+	return nil
+}
+
+// statusPatch returns the JSON patch of the changes from base to updated, nil when they are equal. A
+// changed condition is replaced at its index once a test confirms it is still the one of base, added
+// conditions are appended and removed ones are tested and removed from the end of the list. Other
+// fields are replaced whole.
+func statusPatch(base, updated *managedv1alpha1.GroupPermissionStatus) ([]byte, error) {
+	if reflect.DeepEqual(base, updated) {
+		return nil, nil
+	}
+	// a GroupPermission without a status gets the whole status
+	if reflect.DeepEqual(base, &managedv1alpha1.GroupPermissionStatus{}) {
+		return json.Marshal([]patchOperation{{Op: "add", Path: "/status", Value: updated}})
+	}
+
+	baseFields, err := statusFields(base)
+	if err != nil {
+		return nil, err
+	}
+	updatedFields, err := statusFields(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	operations := []patchOperation{}
+	if _, ok := baseFields["conditions"]; ok && len(updated.Conditions) > 0 {
+		operations = append(operations, conditionsPatch(base.Conditions, updated.Conditions)...)
+		delete(baseFields, "conditions")
+		delete(updatedFields, "conditions")
+	}
+
+	names := []string{}
+	for name := range baseFields {
+		names = append(names, name)
+	}
+	for name := range updatedFields {
+		if _, ok := baseFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		baseValue, inBase := baseFields[name]
+		updatedValue, inUpdated := updatedFields[name]
+		switch {
+		case !inUpdated:
+			operations = append(operations, patchOperation{Op: "remove", Path: "/status/" + name})
+		case !inBase || string(baseValue) != string(updatedValue):
+			// add replaces an existing member of an object
+			operations = append(operations, patchOperation{Op: "add", Path: "/status/" + name, Value: updatedValue})
+		}
+	}
+	if len(operations) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(operations)
+}
+
+// conditionsPatch returns the operations changing the conditions of base into those of updated
+func conditionsPatch(base, updated []managedv1alpha1.Condition) []patchOperation {
+	operations := []patchOperation{}
+	for i := 0; i < len(base) && i < len(updated); i++ {
+		if reflect.DeepEqual(base[i], updated[i]) {
+			continue
+		}
+		path := fmt.Sprintf("/status/conditions/%d", i)
+		operations = append(operations,
+			patchOperation{Op: "test", Path: path, Value: base[i]},
+			patchOperation{Op: "replace", Path: path, Value: updated[i]},
+		)
+	}
+	for i := len(base); i < len(updated); i++ {
+		operations = append(operations, patchOperation{Op: "add", Path: "/status/conditions/-", Value: updated[i]})
+	}
+	for i := len(base) - 1; i >= len(updated); i-- {
+		path := fmt.Sprintf("/status/conditions/%d", i)
+		operations = append(operations,
+			patchOperation{Op: "test", Path: path, Value: base[i]},
+			patchOperation{Op: "remove", Path: path},
+		)
+	}
+	return operations
+}
+
+// statusFields returns the JSON encoding of each field set in status
+func statusFields(status *managedv1alpha1.GroupPermissionStatus) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,194 @@
+package grouppermission
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+	restfake "k8s.io/client-go/rest/fake"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// decodePatch returns the operations of the JSON patch data
+func decodePatch(t *testing.T, data []byte) []map[string]interface{} {
+	operations := []map[string]interface{}{}
+	if err := json.Unmarshal(data, &operations); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return operations
+}
+
+// TestStatusPatch tests the statusPatch function
+// given: a status with two conditions, updated with a changed second condition, an added condition and a new phase
+// expected: the second condition is tested and replaced, the third appended and the phase added, the
+// unchanged first condition is not part of the patch
+func TestStatusPatch(t *testing.T) {
+	transition := metav1.Date(2026, time.October, 15, 9, 0, 0, 0, time.UTC)
+	base := &managedv1alpha1.GroupPermissionStatus{
+		State: "Created",
+		Conditions: []managedv1alpha1.Condition{
+			{LastTransitionTime: transition, ClusterRoleName: "view", Status: true, State: managedv1alpha1.GroupPermissionCreated},
+			{LastTransitionTime: transition, ClusterRoleName: "edit", Status: true, State: managedv1alpha1.GroupPermissionCreated},
+		},
+	}
+	updated := base.DeepCopy()
+	updated.Conditions[1].Status = false
+	updated.Conditions = append(updated.Conditions, managedv1alpha1.Condition{
+		LastTransitionTime: transition, ClusterRoleName: "admin", Status: true, State: managedv1alpha1.GroupPermissionFailed,
+	})
+	updated.Phase = managedv1alpha1.GroupPermissionPhaseFailed
+
+	data, err := statusPatch(base, updated)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	operations := decodePatch(t, data)
+
+	expected := []struct{ op, path string }{
+		{"test", "/status/conditions/1"},
+		{"replace", "/status/conditions/1"},
+		{"add", "/status/conditions/-"},
+		{"add", "/status/phase"},
+	}
+	if len(operations) != len(expected) {
+		t.Fatalf("Mismatch for operations. Expected(%v), Found(%s)", expected, data)
+	}
+	for i, operation := range operations {
+		if operation["op"] != expected[i].op || operation["path"] != expected[i].path {
+			t.Errorf("Mismatch for operation %d. Expected(%v), Found(%v)", i, expected[i], operation)
+		}
+	}
+	if tested := operations[0]["value"].(map[string]interface{}); tested["status"] != true {
+		t.Errorf("expected the test of the condition as it was, got %v", tested)
+	}
+
+	if data, err := statusPatch(base, base.DeepCopy()); err != nil || data != nil {
+		t.Errorf("expected no patch of an unchanged status, got %s (%v)", data, err)
+	}
+}
+
+// TestStatusPatchRemoved tests the statusPatch function with removed conditions and fields
+// given: a status with two conditions and a plan, updated without the second condition and the plan
+// expected: the second condition is tested and removed and the plan is removed
+func TestStatusPatchRemoved(t *testing.T) {
+	base := &managedv1alpha1.GroupPermissionStatus{
+		State: "Created",
+		Conditions: []managedv1alpha1.Condition{
+			{ClusterRoleName: "view", Status: true, State: managedv1alpha1.GroupPermissionCreated},
+			{ClusterRoleName: "edit", Status: true, State: managedv1alpha1.GroupPermissionCreated},
+		},
+		Plan: &managedv1alpha1.PlanStatus{},
+	}
+	updated := base.DeepCopy()
+	updated.Conditions = updated.Conditions[:1]
+	updated.Plan = nil
+
+	data, err := statusPatch(base, updated)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	operations := decodePatch(t, data)
+
+	expected := []struct{ op, path string }{
+		{"test", "/status/conditions/1"},
+		{"remove", "/status/conditions/1"},
+		{"remove", "/status/plan"},
+	}
+	if len(operations) != len(expected) {
+		t.Fatalf("Mismatch for operations. Expected(%v), Found(%s)", expected, data)
+	}
+	for i, operation := range operations {
+		if operation["op"] != expected[i].op || operation["path"] != expected[i].path {
+			t.Errorf("Mismatch for operation %d. Expected(%v), Found(%v)", i, expected[i], operation)
+		}
+	}
+}
+
+// TestUpdateStatusPatch tests the updateStatus function with a status client
+// given: a GroupPermission whose status was recorded at its resourceVersion, then at another one
+// expected: a changed condition is sent as a JSON patch of the status subresource and the
+// GroupPermission takes the patched object, the whole status is updated once the recorded
+// resourceVersion differs
+func TestUpdateStatusPatch(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	instance := mockGroupPermission()
+	instance.ResourceVersion = "1"
+	fakeClient := fake.NewFakeClient(instance.DeepCopy())
+
+	var request *http.Request
+	var body []byte
+	statusClient := &restfake.RESTClient{
+		NegotiatedSerializer: serializer.DirectCodecFactory{CodecFactory: serializer.NewCodecFactory(scheme.Scheme)},
+		GroupVersion:         managedv1alpha1.SchemeGroupVersion,
+		Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			request = req
+			body, _ = ioutil.ReadAll(req.Body)
+			patched := instance.DeepCopy()
+			patched.ResourceVersion = "2"
+			patched.Status.Conditions[0].Status = false
+			data, err := json.Marshal(patched)
+			if err != nil {
+				return nil, err
+			}
+			header := http.Header{}
+			header.Set("Content-Type", "application/json")
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: ioutil.NopCloser(bytes.NewReader(data))}, nil
+		}),
+	}
+	reconciler := &ReconcileGroupPermission{
+		client:       fakeClient,
+		apiClient:    fakeClient,
+		scheme:       scheme.Scheme,
+		recorder:     record.NewFakeRecorder(10),
+		statusClient: statusClient,
+	}
+
+	reconciler.recordStatus(instance)
+	instance.Status.Conditions[0].Status = false
+	if err := reconciler.updateStatus(instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request == nil {
+		t.Fatalf("expected the status to be patched")
+	}
+	if request.Method != http.MethodPatch || request.URL.Path != "/namespaces/rbac-permissions-operator/grouppermissions/testGroupPermission/status" {
+		t.Errorf("Mismatch for request. Expected(PATCH of the status), Found(%s %s)", request.Method, request.URL.Path)
+	}
+	if contentType := request.Header.Get("Content-Type"); contentType != "application/json-patch+json" {
+		t.Errorf("Mismatch for Content-Type. Expected(application/json-patch+json), Found(%s)", contentType)
+	}
+	if operations := decodePatch(t, body); len(operations) != 2 || operations[0]["op"] != "test" {
+		t.Errorf("expected the condition to be tested and replaced, got %s", body)
+	}
+	if instance.ResourceVersion != "2" {
+		t.Errorf("Mismatch for ResourceVersion. Expected(2), Found(%s)", instance.ResourceVersion)
+	}
+
+	// a resourceVersion without a recorded status is updated whole
+	request = nil
+	stale := mockGroupPermission()
+	stale.ResourceVersion = "1"
+	stale.Status.State = "Failed"
+	if err := reconciler.updateStatus(stale); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request != nil {
+		t.Errorf("expected the whole status to be updated, got a patch %s", body)
+	}
+	if stale.Status.State != "Failed" {
+		t.Errorf("Mismatch for State. Expected(Failed), Found(%s)", stale.Status.State)
+	}
+}
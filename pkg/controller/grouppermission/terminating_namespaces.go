@@ -0,0 +1,85 @@
+package grouppermission
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// isNamespaceTerminating returns whether namespace is being deleted. The apiserver forbids creating
+// objects in such a Namespace, so its RoleBindings are skipped rather than failed.
+func isNamespaceTerminating(namespace *corev1.Namespace) bool {
+	return namespace.DeletionTimestamp != nil || namespace.Status.Phase == corev1.NamespaceTerminating
+}
+
+// terminatingNamespaces returns the names of the Namespaces of namespaceList being deleted in which a
+// Permission of groupPermission is allowed according to allowed, sorted, and the number of RoleBindings
+// skipped in them
+func terminatingNamespaces(groupPermission *managedv1alpha1.GroupPermission, namespaceList *corev1.NamespaceList, allowed []map[string]bool) ([]string, int) {
+	var names []string
+	skipped := 0
+	for i := range namespaceList.Items {
+		namespace := &namespaceList.Items[i]
+		if !isNamespaceTerminating(namespace) {
+			continue
+		}
+		bindings := 0
+		for j := range groupPermission.Spec.Permissions {
+			if allowed[j][namespace.Name] {
+				bindings++
+			}
+		}
+		if bindings > 0 {
+			names = append(names, namespace.Name)
+			skipped += bindings
+		}
+	}
+	sort.Strings(names)
+	return names, skipped
+}
+
+// reconcileTerminatingNamespaces records the allowed Namespaces of namespaceList being deleted, whose
+// RoleBindings were skipped, in a NamespaceTerminating condition of groupPermission, so they are told
+// apart from failed RoleBindings. The condition is informational and cleared once they are gone.
+func (r *ReconcileGroupPermission) reconcileTerminatingNamespaces(groupPermission *managedv1alpha1.GroupPermission, namespaceList *corev1.NamespaceList, operatorConfig *operatorconfig.OperatorConfig) error {
+	names, skipped := terminatingNamespaces(groupPermission, namespaceList, r.namespaces.allowedNamespaces(groupPermission, namespaceList))
+	localmetrics.AddTerminatingNamespaceBindingsSkipped(skipped)
+
+	if len(names) == 0 {
+		if conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonNamespaceTerminating) {
+			conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonNamespaceTerminating)
+			return r.updateStatus(groupPermission)
+		}
+		return nil
+	}
+
+	message := terminatingNamespacesMessage(names, operatorConfig.StatusFailureSampleSize)
+	if hasActiveCondition(groupPermission, message, managedv1alpha1.GroupPermissionCreated, managedv1alpha1.ReasonNamespaceTerminating) {
+		return nil
+	}
+	// the condition listing the previous Namespaces is replaced
+	conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonNamespaceTerminating)
+	updateCondition(groupPermission, message, "", true, managedv1alpha1.GroupPermissionCreated, managedv1alpha1.ReasonNamespaceTerminating)
+	return r.updateStatus(groupPermission)
+}
+
+// terminatingNamespacesMessage returns the message of the NamespaceTerminating condition, listing up to
+// sampleSize of names
+func terminatingNamespacesMessage(names []string, sampleSize int) string {
+	sample := names
+	if sampleSize > 0 && len(sample) > sampleSize {
+		sample = sample[:sampleSize]
+	}
+	message := fmt.Sprintf("Skipped the RoleBindings of %d Namespaces being deleted: %s", len(names), strings.Join(sample, ", "))
+	if len(sample) < len(names) {
+		message += fmt.Sprintf(" and %d more", len(names)-len(sample))
+	}
+	return message
+}
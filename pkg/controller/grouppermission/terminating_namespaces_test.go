@@ -0,0 +1,82 @@
+package grouppermission
+
+import (
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// mockTerminatingNamespace returns the Namespace name being deleted
+func mockTerminatingNamespace(name string) *corev1.Namespace {
+	namespace := mockNamespace(name)
+	now := metav1.Now()
+	namespace.DeletionTimestamp = &now
+	namespace.Status.Phase = corev1.NamespaceTerminating
+	return namespace
+}
+
+// TestReconcileTerminatingNamespaces tests the reconcileNamespacePermissions and reconcileTerminatingNamespaces functions
+// given: GroupPermission with a Permission allowed in a Namespace and in a Namespace being deleted
+// expected: only the RoleBinding of the first Namespace is created and reported, the other one is
+// counted as skipped in a NamespaceTerminating condition, which is cleared once the Namespace is gone
+func TestReconcileTerminatingNamespaces(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	fakeClient := newRequesterClient(groupPermission.DeepCopy(), mockNamespace("team-a"), mockTerminatingNamespace("team-b"))
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+
+	namespaceStatuses, err := reconciler.reconcileNamespacePermissions(groupPermission, nil, nil, nil, operatorConfig)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(namespaceStatuses) != 1 || namespaceStatuses[0].Namespace != "team-a" || namespaceStatuses[0].State != v1alpha1.GroupPermissionCreated {
+		t.Errorf("Mismatch for namespace statuses. Expected([team-a Created]), Found(%+v)", namespaceStatuses)
+	}
+
+	skipped := testutil.ToFloat64(localmetrics.RBACTerminatingNamespaceBindingsSkipped)
+	namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{*mockNamespace("team-a"), *mockTerminatingNamespace("team-b")}}
+	if err := reconciler.reconcileTerminatingNamespaces(groupPermission, namespaceList, operatorConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := terminatingNamespacesMessage([]string{"team-b"}, operatorConfig.StatusFailureSampleSize)
+	if !hasActiveCondition(groupPermission, expected, v1alpha1.GroupPermissionCreated, v1alpha1.ReasonNamespaceTerminating) {
+		t.Errorf("expected a condition %q, got %+v", expected, groupPermission.Status.Conditions)
+	}
+	if value := testutil.ToFloat64(localmetrics.RBACTerminatingNamespaceBindingsSkipped) - skipped; value != 1 {
+		t.Errorf("Mismatch for skipped RoleBindings. Expected(1), Found(%v)", value)
+	}
+	if phase := groupPermissionPhase(groupPermission, isNamespaceStatusConverged(namespaceStatuses)); phase != v1alpha1.GroupPermissionPhaseActive {
+		t.Errorf("Mismatch for phase. Expected(%s), Found(%s)", v1alpha1.GroupPermissionPhaseActive, phase)
+	}
+
+	namespaceList.Items = namespaceList.Items[:1]
+	if err := reconciler.reconcileTerminatingNamespaces(groupPermission, namespaceList, operatorConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conditions.IsTrue(groupPermission.Status.Conditions, v1alpha1.ReasonNamespaceTerminating) {
+		t.Errorf("expected the condition to be cleared once the Namespace is gone, got %+v", groupPermission.Status.Conditions)
+	}
+}
+
+// TestTerminatingNamespacesMessage tests the terminatingNamespacesMessage function
+// given: more Namespaces being deleted than the sample size
+// expected: the sample is listed with the number of the others
+func TestTerminatingNamespacesMessage(t *testing.T) {
+	expected := "Skipped the RoleBindings of 3 Namespaces being deleted: team-a, team-b and 1 more"
+	if message := terminatingNamespacesMessage([]string{"team-a", "team-b", "team-c"}, 2); message != expected {
+		t.Errorf("Mismatch for message. Expected(%s), Found(%s)", expected, message)
+	}
+}
@@ -0,0 +1,180 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/openshift/rbac-permissions-operator/pkg/tracing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileTraces holds the span of the reconcile in progress, the parent of the spans of the requests
+// issued by the clients. Reconciles are serialized, so the requests of the watchdog and the reaper
+// running meanwhile are traced in the reconcile, like apiBudget counts them.
+type reconcileTraces struct {
+	mutex   sync.Mutex
+	current *tracing.Span
+}
+
+// begin starts the span of the reconcile of request, nil when tracing is disabled or traces is nil
+func (t *reconcileTraces) begin(request reconcile.Request) *tracing.Span {
+	if t == nil {
+		return nil
+	}
+	span := tracing.StartSpan(nil, "Reconcile GroupPermission", tracing.SpanKindInternal,
+		tracing.String("grouppermission.namespace", request.Namespace),
+		tracing.String("grouppermission.name", request.Name))
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.current = span
+	return span
+}
+
+// end ends span, the span of the reconcile in progress
+func (t *reconcileTraces) end(span *tracing.Span) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	if t.current == span {
+		t.current = nil
+	}
+	t.mutex.Unlock()
+	span.End()
+}
+
+// active returns the span of the reconcile in progress, nil when there is none
+func (t *reconcileTraces) active() *tracing.Span {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.current
+}
+
+// annotate adds the phase and counts of groupPermission to the span of the reconcile in progress
+func (t *reconcileTraces) annotate(groupPermission *managedv1alpha1.GroupPermission, phase managedv1alpha1.GroupPermissionPhase, namespaces int) {
+	t.active().SetAttributes(
+		tracing.String("grouppermission.phase", string(phase)),
+		tracing.Int("grouppermission.namespaces", namespaces),
+		tracing.Int("grouppermission.matched_namespaces", groupPermission.Status.MatchedNamespaceCount),
+		tracing.Int("grouppermission.bound_subjects", groupPermission.Status.BoundSubjectCount),
+		tracing.Int("grouppermission.conditions", len(groupPermission.Status.Conditions)))
+}
+
+// request starts the span of a request of verb on obj served by source, the child of the reconcile in
+// progress. It is nil when no reconcile is traced.
+func (t *reconcileTraces) request(source, verb string, obj runtime.Object) *tracing.Span {
+	parent := t.active()
+	if parent == nil {
+		return nil
+	}
+	kind := reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+	span := tracing.StartSpan(parent, verb+" "+kind, tracing.SpanKindClient,
+		tracing.String("k8s.verb", verb),
+		tracing.String("k8s.kind", kind),
+		tracing.String("k8s.source", source))
+	if accessor, err := meta.Accessor(obj); err == nil && !meta.IsListType(obj) {
+		span.SetAttributes(tracing.String("k8s.namespace", accessor.GetNamespace()), tracing.String("k8s.name", accessor.GetName()))
+	}
+	return span
+}
+
+// endRequest ends the span of a request, recording its error and the number of items it listed
+func endRequest(span *tracing.Span, obj runtime.Object, err error) {
+	if span == nil {
+		return
+	}
+	if err == nil && meta.IsListType(obj) {
+		if items, listErr := meta.ExtractList(obj); listErr == nil {
+			span.SetAttributes(tracing.Int("k8s.items", len(items)))
+		}
+	}
+	span.RecordError(err)
+	span.End()
+}
+
+// tracingClient traces the requests of a client as children of the reconcile in progress. Reads are
+// served by readSource, writes always reach the apiserver.
+type tracingClient struct {
+	client.Client
+	traces     *reconcileTraces
+	readSource string
+}
+
+// traceRequests returns c, tracing its requests in the reconciles of traces
+func traceRequests(c client.Client, traces *reconcileTraces, readSource string) client.Client {
+	return &tracingClient{Client: c, traces: traces, readSource: readSource}
+}
+
+// Get implements client.Reader
+func (c *tracingClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	span := c.traces.request(c.readSource, "get", obj)
+	span.SetAttributes(tracing.String("k8s.namespace", key.Namespace), tracing.String("k8s.name", key.Name))
+	err := c.Client.Get(ctx, key, obj)
+	endRequest(span, obj, err)
+	return err
+}
+
+// List implements client.Reader
+func (c *tracingClient) List(ctx context.Context, opts *client.ListOptions, list runtime.Object) error {
+	span := c.traces.request(c.readSource, "list", list)
+	if opts != nil && opts.Namespace != "" {
+		span.SetAttributes(tracing.String("k8s.namespace", opts.Namespace))
+	}
+	err := c.Client.List(ctx, opts, list)
+	endRequest(span, list, err)
+	return err
+}
+
+// Create implements client.Writer
+func (c *tracingClient) Create(ctx context.Context, obj runtime.Object) error {
+	span := c.traces.request(localmetrics.SourceAPIServer, "create", obj)
+	err := c.Client.Create(ctx, obj)
+	endRequest(span, obj, err)
+	return err
+}
+
+// Update implements client.Writer
+func (c *tracingClient) Update(ctx context.Context, obj runtime.Object) error {
+	span := c.traces.request(localmetrics.SourceAPIServer, "update", obj)
+	err := c.Client.Update(ctx, obj)
+	endRequest(span, obj, err)
+	return err
+}
+
+// Delete implements client.Writer
+func (c *tracingClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOptionFunc) error {
+	span := c.traces.request(localmetrics.SourceAPIServer, "delete", obj)
+	err := c.Client.Delete(ctx, obj, opts...)
+	endRequest(span, obj, err)
+	return err
+}
+
+// Status implements client.StatusClient
+func (c *tracingClient) Status() client.StatusWriter {
+	return &tracingStatusWriter{StatusWriter: c.Client.Status(), traces: c.traces}
+}
+
+// tracingStatusWriter traces the status updates of a client in the reconciles of traces
+type tracingStatusWriter struct {
+	client.StatusWriter
+	traces *reconcileTraces
+}
+
+// Update implements client.StatusWriter
+func (w *tracingStatusWriter) Update(ctx context.Context, obj runtime.Object) error {
+	span := w.traces.request(localmetrics.SourceAPIServer, "update", obj)
+	span.SetAttributes(tracing.String("k8s.subresource", "status"))
+	err := w.StatusWriter.Update(ctx, obj)
+	endRequest(span, obj, err)
+	return err
+}
@@ -0,0 +1,124 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/openshift/rbac-permissions-operator/pkg/tracing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// recordingExporter records the spans exported by a tracer
+type recordingExporter struct {
+	spans []*tracing.Span
+}
+
+func (e *recordingExporter) Export(spans []*tracing.Span) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+// spanAttributes returns the attributes of span by key
+func spanAttributes(span *tracing.Span) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	for _, attr := range span.Attributes() {
+		attributes[attr.Key] = attr.Value
+	}
+	return attributes
+}
+
+// TestTracingClient tests the spans of the requests traced by traceRequests
+// given: a reconcile in progress getting a missing GroupPermission and listing two Namespaces, then
+// requests issued after it ended
+// expected: the requests are child spans of the reconcile, with their verb, source, error and items,
+// and the requests issued after the reconcile are not traced
+func TestTracingClient(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	exporter := &recordingExporter{}
+	tracer := tracing.NewTracer(exporter)
+	tracing.SetTracer(tracer)
+	defer tracing.SetTracer(nil)
+
+	traces := &reconcileTraces{}
+	cacheClient := traceRequests(fake.NewFakeClient(mockNamespace("team-a"), mockNamespace("team-b")), traces, localmetrics.SourceCache)
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "rbac", Name: "missing"}}
+
+	ctx := context.TODO()
+	span := traces.begin(request)
+	getErr := cacheClient.Get(ctx, request.NamespacedName, &managedv1alpha1.GroupPermission{})
+	listErr := cacheClient.List(ctx, &client.ListOptions{}, &corev1.NamespaceList{})
+	traces.end(span)
+	_ = cacheClient.List(ctx, &client.ListOptions{}, &corev1.NamespaceList{})
+
+	if getErr == nil || listErr != nil {
+		t.Fatalf("Mismatch for errors. Expected(not found, nil), Found(%v, %v)", getErr, listErr)
+	}
+	if err := tracer.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exporter.spans) != 3 {
+		t.Fatalf("Mismatch for span count. Expected(3), Found(%d)", len(exporter.spans))
+	}
+	get, list, reconcileSpan := exporter.spans[0], exporter.spans[1], exporter.spans[2]
+
+	if reconcileSpan.Name != "Reconcile GroupPermission" || spanAttributes(reconcileSpan)["grouppermission.name"] != "missing" {
+		t.Errorf("Mismatch for reconcile span. Expected(Reconcile GroupPermission of missing), Found(%s %v)", reconcileSpan.Name, spanAttributes(reconcileSpan))
+	}
+	for _, child := range []*tracing.Span{get, list} {
+		if child.TraceID != reconcileSpan.TraceID || child.ParentID != reconcileSpan.SpanID || child.Kind != tracing.SpanKindClient {
+			t.Errorf("Mismatch for parent of %s. Expected(%x), Found(%x)", child.Name, reconcileSpan.SpanID, child.ParentID)
+		}
+	}
+	if get.Name != "get GroupPermission" || get.ErrorMessage() == "" || spanAttributes(get)["k8s.name"] != "missing" {
+		t.Errorf("Mismatch for get span. Expected(failed get GroupPermission of missing), Found(%s %q %v)", get.Name, get.ErrorMessage(), spanAttributes(get))
+	}
+	attributes := spanAttributes(list)
+	if list.Name != "list NamespaceList" || attributes["k8s.source"] != localmetrics.SourceCache || attributes["k8s.items"] != 2 {
+		t.Errorf("Mismatch for list span. Expected(list NamespaceList of 2 items from the cache), Found(%s %v)", list.Name, attributes)
+	}
+}
+
+// TestReconcileTracesAnnotate tests the phase and counts added to the span of a reconcile
+// given: a reconcile in progress and a GroupPermission with matched Namespaces and bound subjects
+// expected: the span of the reconcile has the phase and counts, a reconciler without traces annotates nothing
+func TestReconcileTracesAnnotate(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracing.SetTracer(tracing.NewTracer(exporter))
+	defer tracing.SetTracer(nil)
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Status.MatchedNamespaceCount = 3
+	groupPermission.Status.BoundSubjectCount = 1
+
+	traces := &reconcileTraces{}
+	span := traces.begin(reconcile.Request{})
+	traces.annotate(groupPermission, managedv1alpha1.GroupPermissionPhaseActive, 4)
+	traces.end(span)
+
+	attributes := spanAttributes(span)
+	if attributes["grouppermission.phase"] != string(managedv1alpha1.GroupPermissionPhaseActive) || attributes["grouppermission.namespaces"] != 4 ||
+		attributes["grouppermission.matched_namespaces"] != 3 || attributes["grouppermission.bound_subjects"] != 1 {
+		t.Errorf("Mismatch for attributes. Expected(Active phase and counts), Found(%v)", attributes)
+	}
+	if traces.active() != nil {
+		t.Errorf("Mismatch for active span after end. Expected(nil), Found(%v)", traces.active())
+	}
+
+	var none *reconcileTraces
+	if span := none.begin(reconcile.Request{}); span != nil {
+		t.Errorf("Mismatch for span without traces. Expected(nil), Found(%v)", span)
+	}
+	none.annotate(groupPermission, managedv1alpha1.GroupPermissionPhaseActive, 4)
+	none.end(nil)
+}
@@ -0,0 +1,131 @@
+package grouppermission
+
+import (
+	"context"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// deleteUndesiredRoleBindings deletes the RoleBindings managed for groupPermission that are not desired,
+// by namespace/name: those of the Permissions removed from its spec, and those of matched, its allowed
+// Namespaces, that were denied since they were created. The RoleBindings of the roles still granted in
+// the Namespaces no longer allowed are left to deleteUnmatchedRoles and deleteUnmatchedRoleBindings,
+// which remove them after the removal grace period.
+func (r *ReconcileGroupPermission) deleteUndesiredRoleBindings(groupPermission *managedv1alpha1.GroupPermission, desired, matched map[string]bool) error {
+	roleBindingList, err := r.listManagedRoleBindings(groupPermission)
+	if err != nil {
+		return err
+	}
+
+	granted := grantedRoleRefs(groupPermission)
+	for i := range roleBindingList.Items {
+		binding := &roleBindingList.Items[i]
+		key := binding.Namespace + "/" + binding.Name
+		if desired[key] || (!matched[key] && granted[roleRefKey(binding.RoleRef)]) {
+			continue
+		}
+
+		unlock := bindinglock.Lock(bindinglock.RoleBinding, binding.Namespace, binding.Name)
+		err := r.client.Delete(context.TODO(), binding)
+		unlock()
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info("Deleted undesired RoleBinding", "Request.Namespace", groupPermission.Namespace, "Request.Name", groupPermission.Name,
+			"Namespace", binding.Namespace, "RoleBinding", binding.Name)
+	}
+	return nil
+}
+
+// deleteUnmatchedRoleBindings deletes the RoleBindings managed for groupPermission to the roles of its
+// Permissions without rules in the Namespaces no longer allowed or protected by operatorConfig. The
+// RoleBindings to the Roles created for groupPermission are deleted along them by deleteUnmatchedRoles.
+// With a removal grace period in operatorConfig, they are labeled pending removal first and deleted once
+// it elapsed, unless allowed again meanwhile. It returns the number of RoleBindings deleted and when the
+// next pending removal is due, zero when none is.
+func (r *ReconcileGroupPermission) deleteUnmatchedRoleBindings(groupPermission *managedv1alpha1.GroupPermission, operatorConfig *operatorconfig.OperatorConfig, now time.Time) (int, time.Time, error) {
+	bound := map[string]bool{}
+	for _, permission := range groupPermission.Spec.Permissions {
+		if len(permission.Rules) == 0 {
+			bound[roleRefKey(permissionRoleRef(&groupPermission.Spec, permission))] = true
+		}
+	}
+	if len(bound) == 0 {
+		return 0, time.Time{}, nil
+	}
+
+	roleBindingList, err := r.listManagedRoleBindings(groupPermission)
+	if err != nil || len(roleBindingList.Items) == 0 {
+		return 0, time.Time{}, err
+	}
+	namespaceList, err := r.listNamespaces(groupPermission)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	allowed := r.namespaces.allowedNamespaces(groupPermission, unprotectedNamespaces(namespaceList, operatorConfig))
+	desired := map[string]bool{}
+	for i, permission := range groupPermission.Spec.Permissions {
+		roleRef := permissionRoleRef(&groupPermission.Spec, permission)
+		for namespace := range allowed[i] {
+			desired[namespace+"/"+newRoleBinding(namespace, roleRef, utility.GroupSubject(&groupPermission.Spec)).Name] = true
+		}
+	}
+
+	deleted := 0
+	var nextRemoval time.Time
+	for i := range roleBindingList.Items {
+		roleBinding := &roleBindingList.Items[i]
+		if !bound[roleRefKey(roleBinding.RoleRef)] {
+			continue
+		}
+		if desired[roleBinding.Namespace+"/"+roleBinding.Name] {
+			if err := r.cancelBindingRemoval(groupPermission, roleBinding); err != nil {
+				return deleted, nextRemoval, err
+			}
+			continue
+		}
+		due, err := r.scheduleBindingRemoval(groupPermission, roleBinding, operatorConfig.RemovalGracePeriod, now)
+		if err != nil {
+			return deleted, nextRemoval, err
+		}
+		if due.After(now) {
+			if nextRemoval.IsZero() || due.Before(nextRemoval) {
+				nextRemoval = due
+			}
+			continue
+		}
+		unlock := bindinglock.Lock(bindinglock.RoleBinding, roleBinding.Namespace, roleBinding.Name)
+		err = r.client.Delete(context.TODO(), roleBinding)
+		unlock()
+		if err != nil && !errors.IsNotFound(err) {
+			return deleted, nextRemoval, err
+		}
+		deleted++
+	}
+	return deleted, nextRemoval, nil
+}
+
+// grantedRoleRefs returns the roles granted by the Permissions and the view Role of groupPermission, by
+// roleRefKey
+func grantedRoleRefs(groupPermission *managedv1alpha1.GroupPermission) map[string]bool {
+	granted := map[string]bool{}
+	for _, permission := range groupPermission.Spec.Permissions {
+		granted[roleRefKey(permissionRoleRef(&groupPermission.Spec, permission))] = true
+	}
+	if permission, ok := viewPermission(groupPermission); ok {
+		granted[roleRefKey(permissionRoleRef(&groupPermission.Spec, permission))] = true
+	}
+	return granted
+}
+
+// roleRefKey returns the Kind/name of the role of roleRef
+func roleRefKey(roleRef v1.RoleRef) string {
+	return roleRef.Kind + "/" + roleRef.Name
+}
@@ -0,0 +1,140 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestDeleteUndesiredRoleBindings tests the deleteUndesiredRoleBindings function
+// given: the managed RoleBindings of a GroupPermission, desired in team-a, denied in team-b, in a Namespace
+// no longer allowed, to a managed Role in a Namespace no longer allowed, and a RoleBinding not managed
+// expected: the denied RoleBinding and that of the Namespace no longer allowed are deleted, the desired one,
+// the one left to deleteUnmatchedRoles and the unmanaged one are kept
+func TestDeleteUndesiredRoleBindings(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockRulesGroupPermission()
+	subject := utility.GroupSubject(&groupPermission.Spec)
+	admin := rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}
+	managed := func(namespace string, roleRef rbacv1.RoleRef) *rbacv1.RoleBinding {
+		roleBinding := newRoleBinding(namespace, roleRef, subject)
+		utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+		return roleBinding
+	}
+	desired := managed("team-a", admin)
+	denied := managed("team-b", admin)
+	unmatched := managed("legacy", admin)
+	legacyRole := newRole(groupPermission, "legacy", groupPermission.Spec.Permissions[0])
+	roleBinding := managed("legacy", rbacv1.RoleRef{Kind: "Role", Name: legacyRole.Name})
+	unmanaged := newRoleBinding("other", admin, subject)
+	fakeClient := fake.NewFakeClient(desired, denied, unmatched, legacyRole, roleBinding, unmanaged)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+
+	err := reconciler.deleteUndesiredRoleBindings(groupPermission,
+		map[string]bool{"team-a/" + desired.Name: true},
+		map[string]bool{"team-a/" + desired.Name: true, "team-b/" + denied.Name: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var tests = []struct {
+		roleBinding *rbacv1.RoleBinding
+		kept        bool
+	}{
+		{desired, true},
+		{denied, false},
+		{unmatched, false},
+		{roleBinding, true},
+		{unmanaged, true},
+	}
+	for _, test := range tests {
+		err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: test.roleBinding.Namespace, Name: test.roleBinding.Name}, &rbacv1.RoleBinding{})
+		if kept := !errors.IsNotFound(err); kept != test.kept {
+			t.Errorf("Mismatch for RoleBinding %s/%s kept. Expected(%t), Found(%t, %v)", test.roleBinding.Namespace, test.roleBinding.Name, test.kept, kept, err)
+		}
+	}
+}
+
+// TestDeleteUnmatchedRoleBindingsGracePeriod tests the deleteUnmatchedRoleBindings function
+// given: a RoleBinding to a ClusterRole in a Namespace no longer allowed, and one pending removal in a
+// Namespace allowed again
+// expected: the first RoleBinding is labeled pending removal, then deleted once due, and the pending
+// removal of the second one is cleared
+func TestDeleteUnmatchedRoleBindingsGracePeriod(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	groupPermission := mockNamespacedGroupPermission()
+	admin := rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}
+	staleRoleBinding := newRoleBinding("legacy", admin, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&staleRoleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	restoredRoleBinding := newRoleBinding("team-a", admin, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&restoredRoleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	utility.SetPendingRemoval(&restoredRoleBinding.ObjectMeta, now)
+
+	fakeClient := newRequesterClient(mockNamespace("team-a"), mockNamespace("legacy"), staleRoleBinding, restoredRoleBinding)
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  recorder,
+	}
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+	operatorConfig.RemovalGracePeriod = time.Hour
+
+	deleted, nextRemoval, err := reconciler.deleteUnmatchedRoleBindings(groupPermission, operatorConfig, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 0 || !nextRemoval.Equal(now.Add(time.Hour)) {
+		t.Errorf("Mismatch for the pending removal. Expected(0, %s), Found(%d, %s)", now.Add(time.Hour), deleted, nextRemoval)
+	}
+	roleBinding := &rbacv1.RoleBinding{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "legacy", Name: staleRoleBinding.Name}, roleBinding); err != nil {
+		t.Fatalf("expected the RoleBinding pending removal to be kept: %v", err)
+	}
+	if due, ok := utility.RemovalDue(roleBinding.ObjectMeta); !ok || !due.Equal(now.Add(time.Hour)) {
+		t.Errorf("Mismatch for the removal of %s. Expected(%s), Found(%s, %t)", roleBinding.Name, now.Add(time.Hour), due, ok)
+	}
+	restored := &rbacv1.RoleBinding{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: restoredRoleBinding.Name}, restored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := utility.RemovalDue(restored.ObjectMeta); ok {
+		t.Errorf("expected the pending removal of the RoleBinding of the allowed Namespace to be cleared")
+	}
+	if len(recorder.Events) != 2 {
+		t.Errorf("Mismatch for events. Expected(2), Found(%d)", len(recorder.Events))
+	}
+
+	deleted, nextRemoval, err = reconciler.deleteUnmatchedRoleBindings(groupPermission, operatorConfig, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 1 || !nextRemoval.IsZero() {
+		t.Errorf("Mismatch for the removal once due. Expected(1, zero), Found(%d, %s)", deleted, nextRemoval)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "legacy", Name: staleRoleBinding.Name}, &rbacv1.RoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the RoleBinding to be deleted once due, got %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: restoredRoleBinding.Name}, &rbacv1.RoleBinding{}); err != nil {
+		t.Errorf("expected the RoleBinding of the allowed Namespace to be kept: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings;rolebindings,verbs=list
+
+// reconcileUnmanagedAccess flags groupPermission with an UnmanagedAccess condition and event when its
+// Group has access beyond its spec through bindings not managed by the operator, according to the
+// unmanaged access mode of operatorConfig. In strict mode an UnmanagedAccessError is returned, so the
+// reconcile fails until the bindings are removed or their access is added to the spec.
+func (r *ReconcileGroupPermission) reconcileUnmanagedAccess(groupPermission *managedv1alpha1.GroupPermission, namespaceList *corev1.NamespaceList, operatorConfig *operatorconfig.OperatorConfig) error {
+	var bindings []string
+	if operatorConfig.UnmanagedAccessMode == operatorconfig.UnmanagedAccessWarn || operatorConfig.UnmanagedAccessMode == operatorconfig.UnmanagedAccessStrict {
+		var err error
+		bindings, err = r.unmanagedAccess(groupPermission, namespaceList, operatorConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(bindings) == 0 {
+		if conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonUnmanagedAccess) {
+			conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonUnmanagedAccess)
+			return r.updateStatus(groupPermission)
+		}
+		return nil
+	}
+
+	state := managedv1alpha1.GroupPermissionCreated
+	if operatorConfig.UnmanagedAccessMode == operatorconfig.UnmanagedAccessStrict {
+		state = managedv1alpha1.GroupPermissionFailed
+	}
+	message := unmanagedAccessMessage(groupPermission, bindings, operatorConfig.StatusFailureSampleSize)
+	if !hasActiveCondition(groupPermission, message, state, managedv1alpha1.ReasonUnmanagedAccess) {
+		// the condition of the other mode is replaced when the mode changes
+		conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonUnmanagedAccess)
+		updateCondition(groupPermission, message, "", true, state, managedv1alpha1.ReasonUnmanagedAccess)
+		if err := r.updateStatus(groupPermission); err != nil {
+			return err
+		}
+		r.eventf(groupPermission, corev1.EventTypeWarning, string(managedv1alpha1.ReasonUnmanagedAccess), "%s", message)
+	}
+	if state == managedv1alpha1.GroupPermissionFailed {
+		return &UnmanagedAccessError{GroupName: groupPermission.Spec.GroupName, Bindings: bindings}
+	}
+	return nil
+}
+
+// unmanagedAccess returns the bindings not managed by the operator granting the Group of groupPermission
+// a role its spec does not grant, a ClusterRole cluster wide or a role in a Namespace of namespaceList,
+// as "Kind namespace/name", sorted. The bindings of the foreign operators of operatorConfig are left out.
+func (r *ReconcileGroupPermission) unmanagedAccess(groupPermission *managedv1alpha1.GroupPermission, namespaceList *corev1.NamespaceList, operatorConfig *operatorconfig.OperatorConfig) ([]string, error) {
+	clusterRoles := map[string]bool{}
+	for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+		clusterRoles[clusterRoleName] = true
+	}
+	// the roles granted in each Namespace, by "namespace/Kind/name"
+	roles := map[string]bool{}
+	allowed := r.namespaces.allowedNamespaces(groupPermission, namespaceList)
+	for i, permission := range groupPermission.Spec.Permissions {
+		roleRef := permissionRoleRef(&groupPermission.Spec, permission)
+		for namespace := range allowed[i] {
+			roles[namespace+"/"+roleRef.Kind+"/"+roleRef.Name] = true
+		}
+	}
+
+	var bindings []string
+	clusterRoleBindingList := &v1.ClusterRoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), &client.ListOptions{}, clusterRoleBindingList); err != nil {
+		return nil, err
+	}
+	for _, clusterRoleBinding := range clusterRoleBindingList.Items {
+		if _, foreign := operatorConfig.ForeignOperator(clusterRoleBinding.Labels); foreign {
+			continue
+		}
+		if utility.IsManaged(clusterRoleBinding.ObjectMeta) || !bindsGroup(clusterRoleBinding.Subjects, groupPermission.Spec.GroupName) {
+			continue
+		}
+		if !clusterRoles[clusterRoleBinding.RoleRef.Name] {
+			bindings = append(bindings, "ClusterRoleBinding "+clusterRoleBinding.Name)
+		}
+	}
+
+	roleBindingList := &v1.RoleBindingList{}
+	if err := r.apiClient.List(context.TODO(), &client.ListOptions{}, roleBindingList); err != nil {
+		return nil, err
+	}
+	for _, roleBinding := range roleBindingList.Items {
+		if _, foreign := operatorConfig.ForeignOperator(roleBinding.Labels); foreign {
+			continue
+		}
+		if utility.IsManaged(roleBinding.ObjectMeta) || !bindsGroup(roleBinding.Subjects, groupPermission.Spec.GroupName) {
+			continue
+		}
+		// a ClusterRole granted cluster wide covers its bindings in any Namespace
+		if roleBinding.RoleRef.Kind == "ClusterRole" && clusterRoles[roleBinding.RoleRef.Name] {
+			continue
+		}
+		if !roles[roleBinding.Namespace+"/"+roleBinding.RoleRef.Kind+"/"+roleBinding.RoleRef.Name] {
+			bindings = append(bindings, "RoleBinding "+roleBinding.Namespace+"/"+roleBinding.Name)
+		}
+	}
+	sort.Strings(bindings)
+	return bindings, nil
+}
+
+// hasActiveCondition returns whether groupPermission has an active condition with reason, state and message
+func hasActiveCondition(groupPermission *managedv1alpha1.GroupPermission, message string, state managedv1alpha1.GroupPermissionState, reason managedv1alpha1.ConditionReason) bool {
+	for _, condition := range groupPermission.Status.Conditions {
+		if condition.Reason == reason && condition.State == state && condition.Status && condition.Message == message {
+			return true
+		}
+	}
+	return false
+}
+
+// bindsGroup returns whether subjects include the Group groupName
+func bindsGroup(subjects []v1.Subject, groupName string) bool {
+	for _, subject := range subjects {
+		if subject.Kind == v1.GroupKind && subject.Name == groupName {
+			return true
+		}
+	}
+	return false
+}
+
+// unmanagedAccessMessage returns the message of the UnmanagedAccess condition of groupPermission, listing
+// up to sampleSize of bindings
+func unmanagedAccessMessage(groupPermission *managedv1alpha1.GroupPermission, bindings []string, sampleSize int) string {
+	sample := bindings
+	if sampleSize > 0 && len(sample) > sampleSize {
+		sample = sample[:sampleSize]
+	}
+	message := fmt.Sprintf("Group %s has access beyond the GroupPermission through %d unmanaged bindings: %s",
+		groupPermission.Spec.GroupName, len(bindings), strings.Join(sample, ", "))
+	if len(sample) < len(bindings) {
+		message += fmt.Sprintf(" and %d more", len(bindings)-len(sample))
+	}
+	return message
+}
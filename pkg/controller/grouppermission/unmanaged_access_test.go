@@ -0,0 +1,89 @@
+package grouppermission
+
+import (
+	"strings"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestReconcileUnmanagedAccess tests the reconcileUnmanagedAccess function
+// given: a Group bound by unmanaged bindings to a ClusterRole not granted by the spec, to admin in a
+// Namespace not allowed and in an allowed Namespace, to a ClusterRole of the spec, and by a managed
+// binding, in each unmanaged access mode
+// expected: only the first two bindings are reported, as a warning condition and event in warn mode, as
+// a failed condition and an error in strict mode, and nothing is reported when the mode is off
+func TestReconcileUnmanagedAccess(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	group := mockGroupSubject("exampleGroupName")
+	unmanagedClusterRoleBinding := func(name, clusterRoleName string) *rbacv1.ClusterRoleBinding {
+		return &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: clusterRoleName},
+			Subjects:   []rbacv1.Subject{group},
+		}
+	}
+	managed := newRoleBinding("default", rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"}, group)
+	utility.SetManagedLabels(&managed.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	unmanagedDefault := newRoleBinding("default", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, group)
+	unmanagedDefault.Name = "admin-by-hand"
+	unmanagedAllowed := newRoleBinding("team-a", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, group)
+	unmanagedAllowed.Name = "admin-by-hand"
+
+	fakeClient := fake.NewFakeClient(groupPermission.DeepCopy(), mockNamespace("team-a"), mockNamespace("default"),
+		unmanagedClusterRoleBinding("cluster-admin-by-hand", "cluster-admin"),
+		unmanagedClusterRoleBinding("granted-by-hand", "exampleClusterRoleName"),
+		managed, unmanagedDefault, unmanagedAllowed)
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme, recorder: recorder}
+	namespaceList := &corev1.NamespaceList{Items: []corev1.Namespace{*mockNamespace("team-a"), *mockNamespace("default")}}
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+
+	operatorConfig.UnmanagedAccessMode = operatorconfig.UnmanagedAccessWarn
+	if err := reconciler.reconcileUnmanagedAccess(groupPermission, namespaceList, operatorConfig); err != nil {
+		t.Fatalf("unexpected error in warn mode: %v", err)
+	}
+	expected := "ClusterRoleBinding cluster-admin-by-hand, RoleBinding default/admin-by-hand"
+	if !hasActiveCondition(groupPermission, unmanagedAccessMessage(groupPermission, []string{"ClusterRoleBinding cluster-admin-by-hand", "RoleBinding default/admin-by-hand"}, 10),
+		v1alpha1.GroupPermissionCreated, v1alpha1.ReasonUnmanagedAccess) {
+		t.Errorf("expected a warning condition listing %s, got %+v", expected, groupPermission.Status.Conditions)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, expected) {
+		t.Errorf("Mismatch for event. Expected(%s), Found(%s)", expected, event)
+	}
+	if phase := groupPermissionPhase(groupPermission, true); phase != v1alpha1.GroupPermissionPhaseActive {
+		t.Errorf("Mismatch for phase in warn mode. Expected(%s), Found(%s)", v1alpha1.GroupPermissionPhaseActive, phase)
+	}
+
+	operatorConfig.UnmanagedAccessMode = operatorconfig.UnmanagedAccessStrict
+	err := reconciler.reconcileUnmanagedAccess(groupPermission, namespaceList, operatorConfig)
+	if _, ok := err.(*UnmanagedAccessError); !ok {
+		t.Errorf("expected an UnmanagedAccessError in strict mode, got %v", err)
+	}
+	if phase := groupPermissionPhase(groupPermission, true); phase != v1alpha1.GroupPermissionPhaseFailed {
+		t.Errorf("Mismatch for phase in strict mode. Expected(%s), Found(%s)", v1alpha1.GroupPermissionPhaseFailed, phase)
+	}
+
+	operatorConfig.UnmanagedAccessMode = operatorconfig.UnmanagedAccessOff
+	if err := reconciler.reconcileUnmanagedAccess(groupPermission, namespaceList, operatorConfig); err != nil {
+		t.Fatalf("unexpected error with the mode off: %v", err)
+	}
+	if conditions.IsTrue(groupPermission.Status.Conditions, v1alpha1.ReasonUnmanagedAccess) {
+		t.Errorf("expected the condition to be cleared with the mode off, got %+v", groupPermission.Status.Conditions)
+	}
+}
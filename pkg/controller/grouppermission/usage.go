@@ -0,0 +1,156 @@
+package grouppermission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// usageTimeout is the timeout of a single query of the usage endpoint
+const usageTimeout = 10 * time.Second
+
+// usageRole is a role bound to a Group, with the last time the Group used it in the usage source
+type usageRole struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// LastUsed is unset when the usage source has not seen the role used by the Group
+	LastUsed *metav1.Time `json:"lastUsed,omitempty"`
+}
+
+// usageQuery is the body of a query of the usage endpoint
+type usageQuery struct {
+	Group string      `json:"group"`
+	Roles []usageRole `json:"roles"`
+}
+
+// usageClient reads the last use of the roles bound to a Group from the usage endpoint configured in
+// the operator config. The endpoint is sent a usageQuery and answers with the roles it has seen used,
+// as {"roles": [{"kind": "ClusterRole", "name": "view", "lastUsed": "2019-06-01T00:00:00Z"}]}.
+// Its source may be as coarse-grained as daily aggregates of the apiserver audit log.
+type usageClient struct {
+	httpClient *http.Client
+	endpoint   string
+}
+
+// newUsageClient returns the usageClient of operatorConfig, or nil when no usage endpoint is configured
+func newUsageClient(httpClient *http.Client, operatorConfig *operatorconfig.OperatorConfig) *usageClient {
+	if operatorConfig.UsageEndpoint == "" {
+		return nil
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &usageClient{httpClient: httpClient, endpoint: operatorConfig.UsageEndpoint}
+}
+
+// boundRoles returns the roles groupPermission binds to its Group, each once
+func boundRoles(groupPermission *managedv1alpha1.GroupPermission) []usageRole {
+	var roles []usageRole
+	seen := map[usageRole]bool{}
+	add := func(kind, name string) {
+		role := usageRole{Kind: kind, Name: name}
+		if name == "" || seen[role] {
+			return
+		}
+		seen[role] = true
+		roles = append(roles, role)
+	}
+	for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+		add("ClusterRole", clusterRoleName)
+	}
+	for _, permission := range groupPermission.Spec.Permissions {
+		add("ClusterRole", permission.ClusterRoleName)
+		add("Role", permission.RoleName)
+	}
+	return roles
+}
+
+// lastUsed returns the last use by group of each of roles seen by the usage source, by kind/name
+func (c *usageClient) lastUsed(group string, roles []usageRole) (map[string]metav1.Time, error) {
+	body, err := json.Marshal(usageQuery{Group: group, Roles: roles})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), usageTimeout)
+	defer cancel()
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("usage endpoint returned %s", resp.Status)
+	}
+
+	response := struct {
+		Roles []usageRole `json:"roles"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode usage response: %v", err)
+	}
+	lastUsed := map[string]metav1.Time{}
+	for _, role := range response.Roles {
+		if role.LastUsed != nil {
+			lastUsed[role.Kind+"/"+role.Name] = *role.LastUsed
+		}
+	}
+	return lastUsed, nil
+}
+
+// roleUsage returns the usage of each of roles checked at now. A use older than the one recorded in
+// previous, such as one past the retention of the usage source, keeps the recorded one.
+func roleUsage(previous []managedv1alpha1.RoleUsage, roles []usageRole, lastUsed map[string]metav1.Time, now metav1.Time) []managedv1alpha1.RoleUsage {
+	recorded := map[string]*metav1.Time{}
+	for _, usage := range previous {
+		recorded[usage.Kind+"/"+usage.Name] = usage.LastUsedTime
+	}
+
+	var usages []managedv1alpha1.RoleUsage
+	for _, role := range roles {
+		key := role.Kind + "/" + role.Name
+		usage := managedv1alpha1.RoleUsage{Kind: role.Kind, Name: role.Name, LastCheckTime: now}
+		if used, ok := lastUsed[key]; ok {
+			usage.LastUsedTime = used.DeepCopy()
+		}
+		if previousUse := recorded[key]; previousUse != nil && (usage.LastUsedTime == nil || usage.LastUsedTime.Before(previousUse)) {
+			usage.LastUsedTime = previousUse.DeepCopy()
+		}
+		usages = append(usages, usage)
+	}
+	return usages
+}
+
+// isRoleUsageEqual compares two RoleUsage lists ignoring LastCheckTime
+func isRoleUsageEqual(a, b []managedv1alpha1.RoleUsage) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Kind != b[i].Kind || a[i].Name != b[i].Name {
+			return false
+		}
+		if (a[i].LastUsedTime == nil) != (b[i].LastUsedTime == nil) {
+			return false
+		}
+		if a[i].LastUsedTime != nil && !a[i].LastUsedTime.Equal(b[i].LastUsedTime) {
+			return false
+		}
+	}
+	return true
+}
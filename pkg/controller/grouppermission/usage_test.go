@@ -0,0 +1,96 @@
+package grouppermission
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestUsageLastUsed tests the lastUsed function
+// given: a usage endpoint that has seen the view ClusterRole used and not the edit one
+// expected: the query lists the Group and its roles, the last use of view is returned and edit is absent
+func TestUsageLastUsed(t *testing.T) {
+	var query usageQuery
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := json.NewDecoder(req.Body).Decode(&query); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"roles": [{"kind": "ClusterRole", "name": "view", "lastUsed": "2026-10-01T00:00:00Z"}, {"kind": "ClusterRole", "name": "edit"}]}`))
+	}))
+	defer server.Close()
+
+	usage := newUsageClient(nil, &operatorconfig.OperatorConfig{UsageEndpoint: server.URL})
+	roles := []usageRole{{Kind: "ClusterRole", Name: "view"}, {Kind: "ClusterRole", Name: "edit"}}
+	lastUsed, err := usage.lastUsed("exampleGroupName", roles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if query.Group != "exampleGroupName" || !reflect.DeepEqual(query.Roles, roles) {
+		t.Errorf("Mismatch for query. Expected(exampleGroupName %v), Found(%+v)", roles, query)
+	}
+	expected := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	if len(lastUsed) != 1 || !lastUsed["ClusterRole/view"].Time.Equal(expected) {
+		t.Errorf("Mismatch for lastUsed. Expected(map[ClusterRole/view:%s]), Found(%v)", expected, lastUsed)
+	}
+
+	if newUsageClient(nil, &operatorconfig.OperatorConfig{}) != nil {
+		t.Errorf("expected no usage client without a usage endpoint")
+	}
+}
+
+// TestBoundRoles tests the boundRoles function
+// given: a GroupPermission binding view cluster-wide and in Namespaces, and a Role in Namespaces
+// expected: each role once, ClusterRoles then Roles in the order of the spec
+func TestBoundRoles(t *testing.T) {
+	groupPermission := mockGroupPermission()
+	groupPermission.Spec.ClusterPermissions = []string{"view"}
+	groupPermission.Spec.Permissions = []v1alpha1.Permission{
+		{ClusterRoleName: "view", NamespacesAllowedRegex: "^team-"},
+		{RoleName: "deployer", NamespacesAllowedRegex: "^team-"},
+	}
+
+	expected := []usageRole{{Kind: "ClusterRole", Name: "view"}, {Kind: "Role", Name: "deployer"}}
+	if roles := boundRoles(groupPermission); !reflect.DeepEqual(roles, expected) {
+		t.Errorf("Mismatch for roles. Expected(%v), Found(%v)", expected, roles)
+	}
+}
+
+// TestRoleUsage tests the roleUsage function
+// given: a recorded use of view and edit, and a source that has seen view used since and edit no longer
+// expected: view takes the newer use, edit keeps the recorded one and admin, never seen, has no use
+func TestRoleUsage(t *testing.T) {
+	recorded := metav1.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	used := metav1.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	now := metav1.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC)
+	previous := []v1alpha1.RoleUsage{
+		{Kind: "ClusterRole", Name: "view", LastUsedTime: &recorded},
+		{Kind: "ClusterRole", Name: "edit", LastUsedTime: &recorded},
+	}
+	roles := []usageRole{{Kind: "ClusterRole", Name: "view"}, {Kind: "ClusterRole", Name: "edit"}, {Kind: "ClusterRole", Name: "admin"}}
+
+	usages := roleUsage(previous, roles, map[string]metav1.Time{"ClusterRole/view": used}, now)
+
+	expected := []v1alpha1.RoleUsage{
+		{Kind: "ClusterRole", Name: "view", LastUsedTime: &used, LastCheckTime: now},
+		{Kind: "ClusterRole", Name: "edit", LastUsedTime: &recorded, LastCheckTime: now},
+		{Kind: "ClusterRole", Name: "admin", LastCheckTime: now},
+	}
+	if !reflect.DeepEqual(usages, expected) {
+		t.Errorf("Mismatch for usages. Expected(%v), Found(%v)", expected, usages)
+	}
+	if isRoleUsageEqual(previous, usages) {
+		t.Errorf("expected the newer use of view to change the usage")
+	}
+	if !isRoleUsageEqual(usages, roleUsage(usages, roles, nil, metav1.Now())) {
+		t.Errorf("expected the usage to be equal ignoring LastCheckTime")
+	}
+}
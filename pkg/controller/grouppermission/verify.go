@@ -0,0 +1,218 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/clusterinfo"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Drift is the difference between the bindings the GroupPermissions of the shard resolve to and the
+// bindings of the cluster. Bindings are named "ClusterRoleBinding <name>" or "RoleBinding <namespace>/<name>".
+type Drift struct {
+	// Missing are the bindings resolved that do not exist
+	Missing []string `json:"missing,omitempty"`
+	// Changed are the bindings resolved that exist with another role or other subjects
+	Changed []string `json:"changed,omitempty"`
+	// Unexpected are the managed bindings no GroupPermission resolves to
+	Unexpected []string `json:"unexpected,omitempty"`
+}
+
+// IsEmpty returns whether the cluster matches the GroupPermissions
+func (d *Drift) IsEmpty() bool {
+	return len(d.Missing) == 0 && len(d.Changed) == 0 && len(d.Unexpected) == 0
+}
+
+// Write prints the drift to out, a binding per line prefixed with + when missing, ~ when changed and
+// - when unexpected
+func (d *Drift) Write(out io.Writer) {
+	for _, binding := range d.Missing {
+		fmt.Fprintf(out, "+ %s\n", binding)
+	}
+	for _, binding := range d.Changed {
+		fmt.Fprintf(out, "~ %s\n", binding)
+	}
+	for _, binding := range d.Unexpected {
+		fmt.Fprintf(out, "- %s\n", binding)
+	}
+}
+
+// verifiedBinding is the part of a binding compared by Verify
+type verifiedBinding struct {
+	roleRef  v1.RoleRef
+	subjects []v1.Subject
+}
+
+// matches returns whether the binding other grants the same role to the same subjects
+func (b verifiedBinding) matches(other verifiedBinding) bool {
+	return semanticallyEqual(b, other)
+}
+
+// subjectAPIGroup returns the API group of subject, as defaulted by the apiserver
+func subjectAPIGroup(subject v1.Subject) string {
+	if subject.APIGroup == "" && subject.Kind != v1.ServiceAccountKind {
+		return v1.GroupName
+	}
+	return subject.APIGroup
+}
+
+// Verify compares the bindings the GroupPermissions of namespace in the shard of opts resolve to at now with
+// the bindings of the cluster, without changing anything. ClusterRoles that do not exist, schedules and cluster
+// conditions are taken into account, the requester escalation check and the policy are not.
+func Verify(ctx context.Context, c client.Client, namespace string, now time.Time, opts ...Option) (*Drift, error) {
+	groupPermissionShard, err := newReconcilerOptions(opts).shard(c)
+	if err != nil {
+		return nil, err
+	}
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	if err := c.List(ctx, &client.ListOptions{Namespace: namespace}, groupPermissionList); err != nil {
+		return nil, err
+	}
+	namespaceList := &corev1.NamespaceList{}
+	if err := c.List(ctx, &client.ListOptions{}, namespaceList); err != nil {
+		return nil, err
+	}
+	clusterRoleList := &v1.ClusterRoleList{}
+	if err := c.List(ctx, &client.ListOptions{}, clusterRoleList); err != nil {
+		return nil, err
+	}
+	clusterRoleBindingList := &v1.ClusterRoleBindingList{}
+	if err := c.List(ctx, &client.ListOptions{}, clusterRoleBindingList); err != nil {
+		return nil, err
+	}
+	roleBindingList := &v1.RoleBindingList{}
+	if err := c.List(ctx, &client.ListOptions{}, roleBindingList); err != nil {
+		return nil, err
+	}
+
+	// the labels of the cluster are read once a GroupPermission has cluster conditions
+	var clusterLabels labels.Set
+	desired := map[string]verifiedBinding{}
+	// ignored holds the GroupPermissions reconciled by other instances, or left as is until their
+	// spec is fixed, their bindings are not compared
+	ignored := map[string]bool{}
+	for i := range groupPermissionList.Items {
+		groupPermission := &groupPermissionList.Items[i]
+		bindings, valid := resolveBindings(groupPermission, namespaceList, clusterRoleList, now)
+		if !valid || !groupPermissionShard.matches(groupPermission) {
+			ignored[groupPermission.Namespace+"/"+groupPermission.Name] = true
+			continue
+		}
+		if groupPermission.Spec.ClusterConditions != nil {
+			if clusterLabels == nil {
+				operatorConfig, err := operatorconfig.GetOperatorConfig(ctx, c)
+				if err != nil {
+					return nil, err
+				}
+				if clusterLabels, err = clusterinfo.Labels(ctx, c, operatorConfig.ClusterLabels); err != nil {
+					return nil, err
+				}
+			}
+			// the GroupPermission grants nothing on a cluster it does not match
+			if matched, err := clusterConditionsMatch(groupPermission, clusterLabels); err != nil || !matched {
+				continue
+			}
+		}
+		for name, binding := range bindings {
+			desired[name] = binding
+		}
+	}
+
+	actual := map[string]verifiedBinding{}
+	managed := map[string]bool{}
+	record := func(name string, objectMeta metav1.ObjectMeta, binding verifiedBinding) {
+		actual[name] = binding
+		// bindings of Elevations are managed without an owning GroupPermission
+		if owner, ok := utility.ManagedOwner(objectMeta); ok && !ignored[owner.String()] {
+			managed[name] = true
+		}
+	}
+	for _, binding := range clusterRoleBindingList.Items {
+		record("ClusterRoleBinding "+binding.Name, binding.ObjectMeta, verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects})
+	}
+	for _, binding := range roleBindingList.Items {
+		record("RoleBinding "+binding.Namespace+"/"+binding.Name, binding.ObjectMeta, verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects})
+	}
+
+	return diffBindings(desired, actual, managed), nil
+}
+
+// diffBindings returns the drift between the desired bindings and the actual bindings, of which the
+// managed ones are unexpected when not desired
+func diffBindings(desired, actual map[string]verifiedBinding, managed map[string]bool) *Drift {
+	drift := &Drift{}
+	for name, binding := range desired {
+		existing, ok := actual[name]
+		switch {
+		case !ok:
+			drift.Missing = append(drift.Missing, name)
+		case !binding.matches(existing):
+			drift.Changed = append(drift.Changed, name)
+		}
+	}
+	for name := range managed {
+		if _, ok := desired[name]; !ok {
+			drift.Unexpected = append(drift.Unexpected, name)
+		}
+	}
+	sort.Strings(drift.Missing)
+	sort.Strings(drift.Changed)
+	sort.Strings(drift.Unexpected)
+	return drift
+}
+
+// resolveBindings returns the bindings groupPermission resolves to at now, by name, and whether
+// groupPermission is valid. The bindings of an invalid GroupPermission are left as they are.
+func resolveBindings(groupPermission *managedv1alpha1.GroupPermission, namespaceList *corev1.NamespaceList, clusterRoleList *v1.ClusterRoleList, now time.Time) (map[string]verifiedBinding, bool) {
+	bindings := map[string]verifiedBinding{}
+	// the bindings of a GroupPermission being deleted, or outside the windows of its schedule, are removed
+	if groupPermission.DeletionTimestamp != nil {
+		return bindings, true
+	}
+	if validateSpec(groupPermission) != nil {
+		return nil, false
+	}
+	if groupPermission.Spec.Schedule != nil {
+		active, _, err := scheduleState(groupPermission.Spec.Schedule, now)
+		if err != nil {
+			return nil, false
+		}
+		if !active {
+			return bindings, true
+		}
+	}
+
+	missing := missingClusterRoles(groupPermission, clusterRoleList)
+	for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+		if missing[clusterRoleName] {
+			continue
+		}
+		binding := clusterPermissionBinding(&groupPermission.Spec, clusterRoleName)
+		bindings["ClusterRoleBinding "+binding.Name] = verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects}
+	}
+	for _, permission := range groupPermission.Spec.Permissions {
+		if missing[permission.ClusterRoleName] {
+			continue
+		}
+		for i := range namespaceList.Items {
+			if !isPermissionAllowed(permission, &namespaceList.Items[i]) {
+				continue
+			}
+			binding := newRoleBinding(namespaceList.Items[i].Name, permissionRoleRef(&groupPermission.Spec, permission), utility.GroupSubject(&groupPermission.Spec))
+			bindings["RoleBinding "+binding.Namespace+"/"+binding.Name] = verifiedBinding{roleRef: binding.RoleRef, subjects: binding.Subjects}
+		}
+	}
+	return bindings, true
+}
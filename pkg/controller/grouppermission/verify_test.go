@@ -0,0 +1,94 @@
+package grouppermission
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestVerify tests the Verify function
+// given: bindings of a GroupPermission missing, changed, unexpected and up to date, the bindings of an
+// Elevation and of an invalid GroupPermission
+// expected: the drift of the GroupPermission only
+func TestVerify(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	admin := mockClusterRole()
+	admin.Name = "admin"
+	view := mockClusterRole()
+	view.Name = "exampleClusterRoleName"
+
+	upToDate := newClusterRoleBinding("exampleClusterRoleName", mockGroupSubject("exampleGroupName"))
+	utility.SetManagedLabels(&upToDate.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	changed := newRoleBinding("team-a", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, mockGroupSubject("otherGroupName"))
+	changed.Name = "admin-exampleGroupName"
+	utility.SetManagedLabels(&changed.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	unexpected := newRoleBinding("default", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}, mockGroupSubject("exampleGroupName"))
+	utility.SetManagedLabels(&unexpected.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+	elevation := newClusterRoleBinding("admin", mockGroupSubject("incident"))
+	elevation.Labels = map[string]string{operatorconfig.ManagedByLabel: operatorconfig.OperatorName}
+
+	invalid := mockGroupPermission()
+	invalid.Name = "invalidGroupPermission"
+	invalid.Spec.Permissions = []v1alpha1.Permission{{ClusterRoleName: "admin", RoleName: "deployer"}}
+	invalidBinding := newRoleBinding("default", rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"}, mockGroupSubject("invalidGroupName"))
+	utility.SetManagedLabels(&invalidBinding.ObjectMeta, invalid.Namespace, invalid.Name)
+
+	fakeClient := fake.NewFakeClient(groupPermission, invalid, admin, view,
+		mockNamespace("team-a"), mockNamespace("team-b"), mockNamespace("default"),
+		upToDate, changed, unexpected, elevation, invalidBinding)
+
+	drift, err := Verify(context.TODO(), fakeClient, "", time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &Drift{
+		Missing:    []string{"RoleBinding team-b/admin-exampleGroupName"},
+		Changed:    []string{"RoleBinding team-a/admin-exampleGroupName"},
+		Unexpected: []string{"RoleBinding default/admin-exampleGroupName"},
+	}
+	if !reflect.DeepEqual(drift, expected) {
+		t.Errorf("got %+v, want %+v", drift, expected)
+	}
+
+	out := &bytes.Buffer{}
+	drift.Write(out)
+	if out.String() != "+ RoleBinding team-b/admin-exampleGroupName\n~ RoleBinding team-a/admin-exampleGroupName\n- RoleBinding default/admin-exampleGroupName\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	if drift.IsEmpty() {
+		t.Errorf("expected drift")
+	}
+}
+
+// TestVerifiedBindingMatches tests the matches function of verifiedBinding
+// given: bindings whose subject has the default API group, no API group or another API group
+// expected: only the subject with another API group does not match, the apiserver defaults an empty one
+func TestVerifiedBindingMatches(t *testing.T) {
+	roleRef := rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}
+	desired := verifiedBinding{roleRef: roleRef, subjects: []rbacv1.Subject{mockGroupSubject("exampleGroupName")}}
+
+	defaulted := verifiedBinding{roleRef: roleRef, subjects: []rbacv1.Subject{{Kind: "Group", Name: "exampleGroupName"}}}
+	if !desired.matches(defaulted) {
+		t.Errorf("expected a subject without API group to match the default one")
+	}
+
+	external := mockGroupSubject("exampleGroupName")
+	external.APIGroup = "idp.example.com"
+	if desired.matches(verifiedBinding{roleRef: roleRef, subjects: []rbacv1.Subject{external}}) {
+		t.Errorf("expected a subject of another API group not to match")
+	}
+}
@@ -0,0 +1,172 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// defaultViewResources are the resources the view Role reads when its ViewRole lists none
+var defaultViewResources = []string{"pods", "pods/log", "events"}
+
+// viewVerbs are the verbs of the rules of the view Role
+var viewVerbs = []string{"get", "list", "watch"}
+
+// viewPermission returns the Permission with rules the view Role of groupPermission is created from,
+// and whether groupPermission has a ViewRole. It is not part of the spec, the Role is only created in
+// the Namespaces matched by the Permissions of the spec.
+func viewPermission(groupPermission *managedv1alpha1.GroupPermission) (managedv1alpha1.Permission, bool) {
+	viewRole := groupPermission.Spec.ViewRole
+	if viewRole == nil {
+		return managedv1alpha1.Permission{}, false
+	}
+	roleName := viewRole.RoleName
+	if roleName == "" {
+		roleName = groupPermission.Name + "-view"
+	}
+	resources := viewRole.Resources
+	if len(resources) == 0 {
+		resources = defaultViewResources
+	}
+	return managedv1alpha1.Permission{RoleName: roleName, Rules: viewRules(resources)}, true
+}
+
+// viewRules returns the rules reading resources, given as resource.group outside the core API group,
+// with one rule by API group in name order so the rules hash is stable
+func viewRules(resources []string) []v1.PolicyRule {
+	byGroup := map[string][]string{}
+	for _, resource := range resources {
+		group := ""
+		name, subresource := resource, ""
+		if i := strings.Index(name, "/"); i >= 0 {
+			name, subresource = name[:i], name[i:]
+		}
+		if i := strings.Index(name, "."); i >= 0 {
+			name, group = name[:i], name[i+1:]
+		}
+		byGroup[group] = append(byGroup[group], name+subresource)
+	}
+
+	var groups []string
+	for group := range byGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	var rules []v1.PolicyRule
+	for _, group := range groups {
+		rules = append(rules, v1.PolicyRule{APIGroups: []string{group}, Resources: byGroup[group], Verbs: viewVerbs})
+	}
+	return rules
+}
+
+// validateViewRole returns an error when the view Role of groupPermission reads an empty resource or
+// has the name of the Role of a Permission
+func validateViewRole(groupPermission *managedv1alpha1.GroupPermission) error {
+	viewRole := groupPermission.Spec.ViewRole
+	if viewRole == nil {
+		return nil
+	}
+	for i, resource := range viewRole.Resources {
+		if resource == "" || strings.HasPrefix(resource, ".") || strings.HasPrefix(resource, "/") {
+			return fmt.Errorf("viewRole resource %d is not a resource name", i)
+		}
+	}
+	permission, _ := viewPermission(groupPermission)
+	for i, p := range groupPermission.Spec.Permissions {
+		if p.RoleName == permission.RoleName {
+			return fmt.Errorf("viewRole roleName %s is the roleName of permission %d", permission.RoleName, i)
+		}
+	}
+	return nil
+}
+
+// reconcileViewRoles creates the view Role of groupPermission, with its RoleBinding to the Group, in
+// every Namespace matched by a Permission that is not protected by operatorConfig nor being deleted. The
+// RoleBinding is deleted from the Namespaces whose allowed granters exclude the requester of
+// groupPermission. The Roles the requester may not create, and the bindings denied by policy or by the
+// grant decision service, are skipped. The Roles of the Namespaces no longer matched are deleted with the
+// other Roles of groupPermission by deleteUnmatchedRoles, and their rules converged by the role content
+// controller.
+func (r *ReconcileGroupPermission) reconcileViewRoles(groupPermission *managedv1alpha1.GroupPermission, policy *policyClient, operatorConfig *operatorconfig.OperatorConfig) error {
+	permission, ok := viewPermission(groupPermission)
+	if !ok {
+		return nil
+	}
+
+	namespaceList, err := r.listNamespaces(groupPermission)
+	if err != nil {
+		return err
+	}
+	namespaceList = unprotectedNamespaces(namespaceList, operatorConfig)
+	matched := matchedNamespaceNames(r.namespaces.allowedNamespaces(groupPermission, namespaceList))
+	grants := newGrantDecider(r.httpClient, operatorConfig)
+	for i := range namespaceList.Items {
+		namespace := &namespaceList.Items[i]
+		if !matched[namespace.Name] || isNamespaceTerminating(namespace) {
+			continue
+		}
+
+		roleBinding := newRoleBinding(namespace.Name, permissionRoleRef(&groupPermission.Spec, permission), utility.GroupSubject(&groupPermission.Spec))
+		// the Namespace may restrict the teams allowed to grant roles in it, even after the Role was bound
+		if !isGranterAllowed(groupPermission, namespace) {
+			if err := r.deleteDeniedRoleBinding(groupPermission, roleBinding); err != nil {
+				return fmt.Errorf("failed to unbind the view Role in Namespace %s: %v", namespace.Name, err)
+			}
+			continue
+		}
+		utility.SetManagedLabels(&roleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+		utility.SetJustificationAnnotations(&roleBinding.ObjectMeta, &groupPermission.Spec)
+		utility.SetDocumentationAnnotations(&roleBinding.ObjectMeta, &groupPermission.Spec)
+		utility.SetSourceAnnotations(&roleBinding.ObjectMeta, groupPermission)
+
+		decision, err := policy.evaluate(newPolicyBinding(groupPermission, roleBinding.Subjects[0], roleBinding.RoleRef, namespace.Name))
+		if err != nil {
+			return err
+		}
+		if !decision.Allowed {
+			log.Info("Skipping the view Role", "Request.Namespace", groupPermission.Namespace, "Request.Name", groupPermission.Name,
+				"Namespace", namespace.Name, "Reason", policyDeniedMessage(decision))
+			continue
+		}
+		grant, err := grants.decide(groupPermission, roleBinding.Subjects[0], roleBinding.RoleRef, namespace.Name)
+		if err != nil {
+			return err
+		}
+		if !grant.Allowed {
+			log.Info("Skipping the view Role", "Request.Namespace", groupPermission.Namespace, "Request.Name", groupPermission.Name,
+				"Namespace", namespace.Name, "Reason", grantDeniedMessage(grant))
+			continue
+		}
+		setGrantAnnotations(&roleBinding.ObjectMeta, grant)
+
+		// the operator creates the Role with its own rights, the requester must be allowed to create it
+		escalationAllowed, err := r.isRoleEscalationAllowed(groupPermission, roleBinding.RoleRef, permission.Rules, namespace.Name, operatorConfig)
+		if err != nil {
+			return err
+		}
+		if !escalationAllowed {
+			log.Info("Skipping the view Role", "Request.Namespace", groupPermission.Namespace, "Request.Name", groupPermission.Name,
+				"Namespace", namespace.Name, "Reason", escalationDeniedMessage(groupPermission, permission.RoleName))
+			continue
+		}
+		if err := r.ensureRole(groupPermission, newRole(groupPermission, namespace.Name, permission)); err != nil {
+			return fmt.Errorf("failed to create the view Role in Namespace %s: %v", namespace.Name, err)
+		}
+		unlock := bindinglock.Lock(bindinglock.RoleBinding, roleBinding.Namespace, roleBinding.Name)
+		err = r.client.Create(context.TODO(), roleBinding)
+		unlock()
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to bind the view Role in Namespace %s: %v", namespace.Name, err)
+		}
+	}
+	return nil
+}
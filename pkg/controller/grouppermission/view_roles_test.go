@@ -0,0 +1,110 @@
+package grouppermission
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// TestViewRules tests the viewRules function
+// given: core resources with a subresource and resources of other API groups
+// expected: one read-only rule by API group, the core API group first
+func TestViewRules(t *testing.T) {
+	rules := viewRules([]string{"pods", "deployments.apps", "pods/log", "routes.route.openshift.io", "replicasets.apps"})
+	expected := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}, Verbs: viewVerbs},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments", "replicasets"}, Verbs: viewVerbs},
+		{APIGroups: []string{"route.openshift.io"}, Resources: []string{"routes"}, Verbs: viewVerbs},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("Mismatch for rules. Expected(%+v), Found(%+v)", expected, rules)
+	}
+}
+
+// TestValidateViewRole tests the validateViewRole function
+// given: no view Role, the default view Role, view Roles with an empty resource, a resource without a
+// name and the name of the Role of a Permission
+// expected: only the last three are invalid
+func TestValidateViewRole(t *testing.T) {
+	var tests = []struct {
+		name     string
+		viewRole *v1alpha1.ViewRole
+		valid    bool
+	}{
+		{"none", nil, true},
+		{"default", &v1alpha1.ViewRole{}, true},
+		{"empty resource", &v1alpha1.ViewRole{Resources: []string{"pods", ""}}, false},
+		{"group only", &v1alpha1.ViewRole{Resources: []string{".apps"}}, false},
+		{"role of a permission", &v1alpha1.ViewRole{RoleName: "deployer"}, false},
+	}
+	for _, test := range tests {
+		groupPermission := mockRulesGroupPermission()
+		groupPermission.Spec.ViewRole = test.viewRole
+		if err := validateViewRole(groupPermission); (err == nil) != test.valid {
+			t.Errorf("%s: Mismatch for valid. Expected(%t), Found(%v)", test.name, test.valid, err)
+		}
+	}
+}
+
+// TestReconcileViewRoles tests the reconcileViewRoles and deleteUnmatchedRoles functions
+// given: a GroupPermission with the default view Role, an allowed Namespace, a denied Namespace and a
+// Namespace no longer allowed with a view Role and its RoleBinding created for it before
+// expected: the view Role and its RoleBinding are created in the allowed Namespace only, and removed
+// from the Namespace no longer allowed
+func TestReconcileViewRoles(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := mockNamespacedGroupPermission()
+	groupPermission.Spec.ViewRole = &v1alpha1.ViewRole{}
+	permission, _ := viewPermission(groupPermission)
+	roleRef := rbacv1.RoleRef{Kind: "Role", Name: permission.RoleName}
+	staleRole := newRole(groupPermission, "legacy", permission)
+	staleRoleBinding := newRoleBinding("legacy", roleRef, utility.GroupSubject(&groupPermission.Spec))
+	utility.SetManagedLabels(&staleRoleBinding.ObjectMeta, groupPermission.Namespace, groupPermission.Name)
+
+	fakeClient := newRequesterClient(mockNamespace("team-a"), mockNamespace("team-secret"), mockNamespace("legacy"), staleRole, staleRoleBinding)
+	reconciler := &ReconcileGroupPermission{client: fakeClient, apiClient: fakeClient, scheme: scheme.Scheme}
+	if err := reconciler.reconcileViewRoles(groupPermission, nil, operatorconfig.DefaultOperatorConfig()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	role := &rbacv1.Role{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: permission.RoleName}, role); err != nil {
+		t.Fatalf("expected the view Role in team-a: %v", err)
+	}
+	expected := []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: defaultViewResources, Verbs: viewVerbs}}
+	if !utility.IsManagedFor(role.ObjectMeta, groupPermission.Namespace, groupPermission.Name) || !reflect.DeepEqual(role.Rules, expected) {
+		t.Errorf("unexpected view Role %+v", role)
+	}
+	roleBinding := newRoleBinding("team-a", roleRef, utility.GroupSubject(&groupPermission.Spec))
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: roleBinding.Name}, roleBinding); err != nil {
+		t.Errorf("expected the RoleBinding of the view Role in team-a: %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-secret", Name: permission.RoleName}, &rbacv1.Role{}); !errors.IsNotFound(err) {
+		t.Errorf("expected no view Role in the denied Namespace, got %v", err)
+	}
+
+	deleted, _, err := reconciler.deleteUnmatchedRoles(groupPermission, operatorconfig.DefaultOperatorConfig(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Mismatch for deleted Roles. Expected(1), Found(%d)", deleted)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "legacy", Name: permission.RoleName}, &rbacv1.Role{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the view Role of the unmatched Namespace to be deleted, got %v", err)
+	}
+}
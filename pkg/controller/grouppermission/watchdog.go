@@ -0,0 +1,145 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// watchdogInterval is the interval between two checks of the stuck GroupPermissions
+const watchdogInterval = time.Minute
+
+// convergence is when the watchdog first saw a generation of a GroupPermission, and whether that
+// generation became Active since
+type convergence struct {
+	generation int64
+	observed   time.Time
+	active     bool
+}
+
+// watchdog flags the GroupPermissions that did not become Active within the stuck deadline of the
+// operator configuration after their spec changed, so they can be alerted on
+type watchdog struct {
+	reconciler *ReconcileGroupPermission
+	interval   time.Duration
+	now        func() time.Time
+	// generations holds the convergence of the latest generation of each GroupPermission. It is only
+	// used by the goroutine running the checks.
+	generations map[types.NamespacedName]convergence
+}
+
+// blank assignment to verify that watchdog implements manager.Runnable
+var _ manager.Runnable = &watchdog{}
+
+// newWatchdog returns a watchdog of the GroupPermissions reconciled by r
+func newWatchdog(r *ReconcileGroupPermission) *watchdog {
+	return &watchdog{
+		reconciler:  r,
+		interval:    watchdogInterval,
+		now:         time.Now,
+		generations: map[types.NamespacedName]convergence{},
+	}
+}
+
+// Start implements manager.Runnable, it checks the GroupPermissions until stop is closed
+func (w *watchdog) Start(stop <-chan struct{}) error {
+	wait.Until(func() {
+		if err := w.check(context.TODO()); err != nil {
+			log.Error(err, "Failed to check stuck GroupPermissions")
+		}
+	}, w.interval, stop)
+	return nil
+}
+
+// check sets the Stuck condition of the GroupPermissions of the shard that are stuck, clears it on
+// the ones that are not anymore and exports the number of stuck GroupPermissions
+func (w *watchdog) check(ctx context.Context) error {
+	r := w.reconciler
+	operatorConfig, err := r.getOperatorConfig(ctx)
+	if err != nil {
+		return err
+	}
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	if err := r.client.List(ctx, &client.ListOptions{}, groupPermissionList); err != nil {
+		return err
+	}
+
+	now := w.now()
+	seen := map[types.NamespacedName]bool{}
+	stuck := 0
+	for i := range groupPermissionList.Items {
+		groupPermission := &groupPermissionList.Items[i]
+		if !r.shard.matches(groupPermission) || groupPermission.DeletionTimestamp != nil {
+			continue
+		}
+		key := types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}
+		seen[key] = true
+
+		record, ok := w.generations[key]
+		if !ok || record.generation != groupPermission.Generation {
+			record = convergence{generation: groupPermission.Generation, observed: now}
+			// the spec of the first generation changed when the GroupPermission was created, which
+			// holds across restarts of the operator
+			if groupPermission.Generation <= 1 && !groupPermission.CreationTimestamp.IsZero() {
+				record.observed = groupPermission.CreationTimestamp.Time
+			}
+		}
+		if groupPermission.Status.Phase == managedv1alpha1.GroupPermissionPhaseActive {
+			record.active = true
+		}
+		w.generations[key] = record
+
+		elapsed := now.Sub(record.observed)
+		isStuck := operatorConfig.StuckDeadline > 0 && !record.active && !intentionallyInactive(groupPermission) && elapsed > operatorConfig.StuckDeadline
+		if isStuck {
+			stuck++
+		}
+		if err := w.updateStuck(ctx, groupPermission, isStuck, elapsed); err != nil {
+			log.Error(err, "Failed to update Stuck condition", "Request.Namespace", key.Namespace, "Request.Name", key.Name)
+		}
+	}
+
+	for key := range w.generations {
+		if !seen[key] {
+			delete(w.generations, key)
+		}
+	}
+	localmetrics.SetStuckGroupPermissions(stuck)
+	return nil
+}
+
+// intentionallyInactive returns whether the bindings of groupPermission are removed on purpose, outside
+// of the windows of its schedule or on a cluster not matching its clusterConditions
+func intentionallyInactive(groupPermission *managedv1alpha1.GroupPermission) bool {
+	if schedule := groupPermission.Status.Schedule; schedule != nil && !schedule.Active {
+		return true
+	}
+	matched := groupPermission.Status.ClusterConditionsMatched
+	return matched != nil && !*matched
+}
+
+// updateStuck activates or deactivates the Stuck condition of groupPermission, recording a Warning
+// event when it becomes stuck. The status is only updated when the condition changes.
+func (w *watchdog) updateStuck(ctx context.Context, groupPermission *managedv1alpha1.GroupPermission, stuck bool, elapsed time.Duration) error {
+	if stuck == conditions.IsTrue(groupPermission.Status.Conditions, managedv1alpha1.ReasonConvergenceDeadlineExceeded) {
+		return nil
+	}
+	if stuck {
+		message := fmt.Sprintf("Generation %d is not Active %s after the spec changed", groupPermission.Generation, elapsed.Round(time.Second))
+		updateCondition(groupPermission, message, "", true, managedv1alpha1.GroupPermissionStuck, managedv1alpha1.ReasonConvergenceDeadlineExceeded)
+		w.reconciler.eventf(groupPermission, corev1.EventTypeWarning, string(managedv1alpha1.GroupPermissionStuck), "%s", message)
+	} else {
+		conditions.Deactivate(groupPermission.Status.Conditions, managedv1alpha1.ReasonConvergenceDeadlineExceeded)
+	}
+	return w.reconciler.updateStatus(groupPermission)
+}
@@ -0,0 +1,105 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/conditions"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestWatchdog tests the check function of the watchdog
+// given: a GroupPermission still Pending after its spec changed, and one outside of the windows of its
+// schedule, checked before and after the stuck deadline and once the first one became Active
+// expected: only the Pending GroupPermission is flagged as stuck after the deadline, and the condition
+// is cleared once it is Active
+func TestWatchdog(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	pending := mockGroupPermission()
+	pending.Generation = 2
+	pending.Status.Phase = v1alpha1.GroupPermissionPhasePending
+	scheduled := mockGroupPermission()
+	scheduled.Name = "scheduledGroupPermission"
+	scheduled.Generation = 2
+	scheduled.Status.Phase = v1alpha1.GroupPermissionPhasePending
+	scheduled.Status.Schedule = &v1alpha1.ScheduleStatus{Active: false}
+
+	fakeClient := fake.NewFakeClient(pending, scheduled,
+		mockOperatorConfigMap(operatorconfig.OperatorNamespace, map[string]string{"stuck_deadline_seconds": "600"}))
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &ReconcileGroupPermission{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+		recorder:  recorder,
+	}
+	now := time.Now()
+	w := newWatchdog(reconciler)
+	w.now = func() time.Time { return now }
+	key := types.NamespacedName{Namespace: pending.Namespace, Name: pending.Name}
+	scheduledKey := types.NamespacedName{Namespace: scheduled.Namespace, Name: scheduled.Name}
+
+	if err := w.check(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value := testutil.ToFloat64(localmetrics.RBACStuckGroupPermissions); value != 0 {
+		t.Errorf("Mismatch for stuck GroupPermissions. Expected(0), Found(%v)", value)
+	}
+
+	now = now.Add(11 * time.Minute)
+	if err := w.check(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value := testutil.ToFloat64(localmetrics.RBACStuckGroupPermissions); value != 1 {
+		t.Errorf("Mismatch for stuck GroupPermissions. Expected(1), Found(%v)", value)
+	}
+	instance := &v1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), key, instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !conditions.IsTrue(instance.Status.Conditions, v1alpha1.ReasonConvergenceDeadlineExceeded) {
+		t.Errorf("expected the GroupPermission to be flagged as stuck, got %+v", instance.Status.Conditions)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("Mismatch for events. Expected(1), Found(%d)", len(recorder.Events))
+	}
+	scheduledInstance := &v1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), scheduledKey, scheduledInstance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conditions.IsTrue(scheduledInstance.Status.Conditions, v1alpha1.ReasonConvergenceDeadlineExceeded) {
+		t.Errorf("expected the GroupPermission outside of its schedule not to be flagged as stuck")
+	}
+
+	instance.Status.Phase = v1alpha1.GroupPermissionPhaseActive
+	if err := fakeClient.Status().Update(context.TODO(), instance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := w.check(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value := testutil.ToFloat64(localmetrics.RBACStuckGroupPermissions); value != 0 {
+		t.Errorf("Mismatch for stuck GroupPermissions. Expected(0), Found(%v)", value)
+	}
+	active := &v1alpha1.GroupPermission{}
+	if err := fakeClient.Get(context.TODO(), key, active); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conditions.IsTrue(active.Status.Conditions, v1alpha1.ReasonConvergenceDeadlineExceeded) {
+		t.Errorf("expected the Stuck condition to be cleared once Active, got %+v", active.Status.Conditions)
+	}
+}
@@ -0,0 +1,297 @@
+package groupsync
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/credentials"
+	"github.com/openshift/rbac-permissions-operator/pkg/readonly"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_groupsync")
+
+const (
+	// defaultSyncInterval is used when a GroupSync does not set IntervalSeconds
+	defaultSyncInterval = time.Hour
+	// scimTimeout bounds each request to the SCIM endpoint
+	scimTimeout = 30 * time.Second
+)
+
+// groupGVK is the OpenShift Group kind, handled as unstructured to avoid depending on the OpenShift API types
+var groupGVK = schema.GroupVersionKind{Group: "user.openshift.io", Version: "v1", Kind: "Group"}
+
+// Add creates a new GroupSync Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	// Groups are cluster scoped and unstructured, they are read from the apiserver
+	apiClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconcileGroupSync{
+		client:     mgr.GetClient(),
+		apiClient:  readonly.Wrap(apiClient, mgr.GetScheme()),
+		scheme:     mgr.GetScheme(),
+		httpClient: &http.Client{Timeout: scimTimeout},
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("groupsync-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource GroupSync
+	err = c.Watch(&source.Kind{Type: &managedv1alpha1.GroupSync{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to Secrets, so rotated or fixed credentials are used right away
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: &secretToGroupSyncs{client: mgr.GetClient()},
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcileGroupSync implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileGroupSync{}
+
+// ReconcileGroupSync reconciles a GroupSync object
+type ReconcileGroupSync struct {
+	// client reads objects from the cache and writes to the apiserver
+	client client.Client
+	// apiClient reads directly from the apiserver, for the cluster scoped Groups
+	apiClient  client.Client
+	scheme     *runtime.Scheme
+	httpClient *http.Client
+}
+
+// +kubebuilder:rbac:groups=user.openshift.io,resources=groups,verbs=get;create;update
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=groupsyncs,verbs=get;list;watch,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=groupsyncs/status,verbs=update,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch,namespace=openshift-rbac-permissions-operator
+
+// Reconcile pulls the membership of the Groups of a GroupSync from its SCIM endpoint and maintains
+// the matching OpenShift Groups created by the operator, then requeues itself after the sync interval.
+func (r *ReconcileGroupSync) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling GroupSync")
+
+	instance := &managedv1alpha1.GroupSync{}
+	err := r.client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	interval := syncInterval(instance)
+	token, syncErr := credentials.Resolve(context.TODO(), r.client, instance.Namespace, instance.Spec.CredentialsSecretRef)
+	var syncedGroups []managedv1alpha1.SyncedGroup
+	var conflictingGroups []string
+	if syncErr == nil {
+		syncedGroups, conflictingGroups, syncErr = r.sync(instance, token)
+	}
+
+	now := metav1.Now()
+	status := managedv1alpha1.GroupSyncStatus{
+		State:             managedv1alpha1.GroupSyncSynced,
+		LastSyncTime:      &now,
+		SyncedGroups:      syncedGroups,
+		ConflictingGroups: conflictingGroups,
+	}
+	if syncErr != nil {
+		// errors of the endpoint may echo the request, never record the token
+		message := credentials.Redact(syncErr.Error(), token)
+		reqLogger.Info("Failed to sync Groups", "Error", message)
+		status.State = managedv1alpha1.GroupSyncFailed
+		status.Message = message
+	}
+
+	instance.Status = status
+	err = r.client.Status().Update(context.TODO(), instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to update status.")
+		return reconcile.Result{}, err
+	}
+
+	// spread the syncs of GroupSyncs reconciled together, such as after a restart of the operator
+	operatorConfig, err := operatorconfig.GetOperatorConfig(context.TODO(), r.client)
+	if err != nil {
+		reqLogger.Error(err, "Failed to get operator config, using the default jitter")
+		operatorConfig = operatorconfig.DefaultOperatorConfig()
+	}
+	return reconcile.Result{RequeueAfter: operatorConfig.Jitter(interval)}, nil
+}
+
+// errGroupConflict is returned by updateGroup for a Group not managed by the operator
+var errGroupConflict = goerrors.New("group is not managed by the operator")
+
+// sync updates the Groups of groupSync and returns the Groups synced, and those left unchanged because
+// an OpenShift Group of the same name is not managed by the operator. Groups missing from the endpoint
+// or conflicting are left unchanged and reported in the returned error.
+func (r *ReconcileGroupSync) sync(groupSync *managedv1alpha1.GroupSync, token string) ([]managedv1alpha1.SyncedGroup, []string, error) {
+	// syncing every Group of the endpoint would let it take over any Group of the cluster
+	if len(groupSync.Spec.Groups) == 0 {
+		return nil, nil, goerrors.New("spec.groups must list the Groups to sync")
+	}
+
+	scim := &scimClient{httpClient: r.httpClient, baseURL: groupSync.Spec.URL, token: token}
+	members, err := scim.listGroups(context.TODO())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var syncedGroups []managedv1alpha1.SyncedGroup
+	var missing, conflicts []string
+	userNames := map[string]string{}
+	for _, name := range groupSync.Spec.Groups {
+		groupMembers, ok := members[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		users, err := scim.userNames(context.TODO(), groupMembers, userNames)
+		if err != nil {
+			return syncedGroups, conflicts, err
+		}
+		if err := r.updateGroup(name, users); err != nil {
+			if err == errGroupConflict {
+				conflicts = append(conflicts, name)
+				continue
+			}
+			return syncedGroups, conflicts, err
+		}
+		syncedGroups = append(syncedGroups, managedv1alpha1.SyncedGroup{Name: name, Members: len(users)})
+	}
+
+	if len(missing) > 0 {
+		return syncedGroups, conflicts, fmt.Errorf("groups not found at the SCIM endpoint: %s", strings.Join(missing, ", "))
+	}
+	if len(conflicts) > 0 {
+		return syncedGroups, conflicts, fmt.Errorf("groups not managed by the operator: %s", strings.Join(conflicts, ", "))
+	}
+	return syncedGroups, conflicts, nil
+}
+
+// updateGroup sets the members of the Group name to users, creating the Group if it does not exist. A
+// Group not created by the operator is left unchanged and errGroupConflict is returned.
+func (r *ReconcileGroupSync) updateGroup(name string, users []string) error {
+	sorted := append([]string(nil), users...)
+	sort.Strings(sorted)
+	desired := make([]interface{}, 0, len(sorted))
+	for _, user := range sorted {
+		desired = append(desired, user)
+	}
+
+	group := &unstructured.Unstructured{}
+	group.SetGroupVersionKind(groupGVK)
+	err := r.apiClient.Get(context.TODO(), types.NamespacedName{Name: name}, group)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		group = &unstructured.Unstructured{Object: map[string]interface{}{"users": desired}}
+		group.SetGroupVersionKind(groupGVK)
+		group.SetName(name)
+		group.SetLabels(utility.ManagedByLabels())
+		return r.apiClient.Create(context.TODO(), group)
+	}
+	if !utility.ManagedSelector().Matches(labels.Set(group.GetLabels())) {
+		return errGroupConflict
+	}
+
+	current, _, err := unstructured.NestedSlice(group.Object, "users")
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(current, desired) || (len(current) == 0 && len(desired) == 0) {
+		return nil
+	}
+	if err := unstructured.SetNestedSlice(group.Object, desired, "users"); err != nil {
+		return err
+	}
+	return r.apiClient.Update(context.TODO(), group)
+}
+
+// syncInterval returns the interval between syncs of groupSync
+func syncInterval(groupSync *managedv1alpha1.GroupSync) time.Duration {
+	if groupSync.Spec.IntervalSeconds <= 0 {
+		return defaultSyncInterval
+	}
+	return time.Duration(groupSync.Spec.IntervalSeconds) * time.Second
+}
+
+// secretToGroupSyncs maps a Secret event to a reconcile of every GroupSync referencing the Secret
+type secretToGroupSyncs struct {
+	client client.Client
+}
+
+// blank assignment to verify that secretToGroupSyncs implements handler.Mapper
+var _ handler.Mapper = &secretToGroupSyncs{}
+
+// Map implements handler.Mapper
+func (m *secretToGroupSyncs) Map(obj handler.MapObject) []reconcile.Request {
+	groupSyncList := &managedv1alpha1.GroupSyncList{}
+	err := m.client.List(context.TODO(), &client.ListOptions{Namespace: obj.Meta.GetNamespace()}, groupSyncList)
+	if err != nil {
+		log.Error(err, "Failed to list GroupSyncs", "Secret", obj.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, groupSync := range groupSyncList.Items {
+		ref := groupSync.Spec.CredentialsSecretRef
+		if ref != nil && ref.Name == obj.Meta.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: groupSync.Namespace,
+				Name:      groupSync.Name,
+			}})
+		}
+	}
+	return requests
+}
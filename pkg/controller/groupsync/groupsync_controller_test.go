@@ -0,0 +1,235 @@
+package groupsync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// serve a SCIM endpoint with two Groups split over two pages, whose members are Users resolved by id
+func newSCIMServer(t *testing.T) *httptest.Server {
+	userNames := map[string]string{"1": "bob", "2": "alice", "3": "carol"}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/scim+json")
+		if strings.HasPrefix(req.URL.Path, "/Users/") {
+			id := strings.TrimPrefix(req.URL.Path, "/Users/")
+			userName, ok := userNames[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"id":"` + id + `","userName":"` + userName + `"}`))
+			return
+		}
+		switch req.URL.Query().Get("startIndex") {
+		case "1":
+			w.Write([]byte(`{"totalResults":3,"startIndex":1,"itemsPerPage":2,"Resources":[{"displayName":"team-a","members":[{"value":"1","display":"Bob Smith"},{"value":"2","display":"Alice Jones"}]},{"displayName":"cluster-admins","members":[{"value":"1"}]}]}`))
+		case "3":
+			w.Write([]byte(`{"totalResults":3,"startIndex":3,"itemsPerPage":1,"Resources":[{"displayName":"team-b","members":[{"value":"3"},{"display":"Dave"}]}]}`))
+		default:
+			t.Errorf("unexpected startIndex %s", req.URL.Query().Get("startIndex"))
+		}
+	}))
+}
+
+func mockGroupSync(url string, groups ...string) *v1alpha1.GroupSync {
+	return &v1alpha1.GroupSync{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testGroupSync",
+			Namespace: "rbac-permissions-operator",
+		},
+		Spec: v1alpha1.GroupSyncSpec{
+			URL:                  url,
+			CredentialsSecretRef: &v1alpha1.SecretKeyReference{Name: "scim"},
+			Groups:               groups,
+		},
+	}
+}
+
+func mockSecret(token string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "scim",
+			Namespace: "rbac-permissions-operator",
+		},
+		Data: map[string][]byte{"token": []byte(token)},
+	}
+}
+
+func newTestReconciler(server *httptest.Server, objs ...runtime.Object) *ReconcileGroupSync {
+	fakeClient := fake.NewFakeClient(objs...)
+	return &ReconcileGroupSync{
+		client:     fakeClient,
+		apiClient:  fakeClient,
+		scheme:     scheme.Scheme,
+		httpClient: server.Client(),
+	}
+}
+
+func reconcileGroupSync(t *testing.T, r *ReconcileGroupSync) *v1alpha1.GroupSync {
+	key := types.NamespacedName{Namespace: "rbac-permissions-operator", Name: "testGroupSync"}
+	result, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	maxInterval := time.Duration(float64(defaultSyncInterval) * (1 + operatorconfig.DefaultOperatorConfig().JitterFactor))
+	if result.RequeueAfter < defaultSyncInterval || result.RequeueAfter > maxInterval {
+		t.Errorf("got RequeueAfter %s, want between %s and %s", result.RequeueAfter, defaultSyncInterval, maxInterval)
+	}
+
+	groupSync := &v1alpha1.GroupSync{}
+	if err := r.client.Get(context.TODO(), key, groupSync); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return groupSync
+}
+
+func getGroupUsers(t *testing.T, r *ReconcileGroupSync, name string) []interface{} {
+	group := &unstructured.Unstructured{}
+	group.SetGroupVersionKind(groupGVK)
+	if err := r.apiClient.Get(context.TODO(), types.NamespacedName{Name: name}, group); err != nil {
+		t.Fatalf("expected Group %s: %v", name, err)
+	}
+	users, _, _ := unstructured.NestedSlice(group.Object, "users")
+	return users
+}
+
+// TestReconcileGroupSync tests the Reconcile function
+// given: a GroupSync of two Groups of a paginated SCIM endpoint, one Group managed by the operator existing
+// with stale members, and a member without an id
+// expected: both Groups created or updated with the sorted userNames of their members and reported in status
+func TestReconcileGroupSync(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	server := newSCIMServer(t)
+	defer server.Close()
+
+	stale := &unstructured.Unstructured{Object: map[string]interface{}{"users": []interface{}{"mallory"}}}
+	stale.SetGroupVersionKind(groupGVK)
+	stale.SetName("team-b")
+	stale.SetLabels(utility.ManagedByLabels())
+
+	r := newTestReconciler(server, mockGroupSync(server.URL, "team-a", "team-b"), mockSecret("secret-token"), stale)
+	groupSync := reconcileGroupSync(t, r)
+
+	if groupSync.Status.State != v1alpha1.GroupSyncSynced {
+		t.Errorf("got state %s, want %s: %s", groupSync.Status.State, v1alpha1.GroupSyncSynced, groupSync.Status.Message)
+	}
+	if len(groupSync.Status.SyncedGroups) != 2 {
+		t.Errorf("got synced groups %v, want team-a and team-b", groupSync.Status.SyncedGroups)
+	}
+	if users := getGroupUsers(t, r, "team-a"); len(users) != 2 || users[0] != "alice" || users[1] != "bob" {
+		t.Errorf("got team-a users %v, want [alice bob]", users)
+	}
+	if users := getGroupUsers(t, r, "team-b"); len(users) != 1 || users[0] != "carol" {
+		t.Errorf("got team-b users %v, want [carol]", users)
+	}
+}
+
+// TestReconcileGroupSyncFailures tests the Reconcile function
+// given: GroupSyncs without Groups, with a missing token, a wrong token, and an unknown Group
+// expected: Failed state with a message, Groups unknown to the endpoint left alone
+func TestReconcileGroupSyncFailures(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	server := newSCIMServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		label     string
+		groupSync *v1alpha1.GroupSync
+		secret    *corev1.Secret
+		synced    int
+	}{
+		{"no groups", mockGroupSync(server.URL), mockSecret("secret-token"), 0},
+		{"empty token", mockGroupSync(server.URL, "team-a"), mockSecret(""), 0},
+		{"missing secret", mockGroupSync(server.URL, "team-a"), &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "rbac-permissions-operator"}}, 0},
+		{"wrong token", mockGroupSync(server.URL, "team-a"), mockSecret("wrong"), 0},
+		{"unknown group", mockGroupSync(server.URL, "team-a", "team-z"), mockSecret("secret-token"), 1},
+	}
+	for _, test := range tests {
+		r := newTestReconciler(server, test.groupSync, test.secret)
+		groupSync := reconcileGroupSync(t, r)
+
+		if groupSync.Status.State != v1alpha1.GroupSyncFailed || groupSync.Status.Message == "" {
+			t.Errorf("%s: got state %s with message %q, want %s with a message", test.label, groupSync.Status.State, groupSync.Status.Message, v1alpha1.GroupSyncFailed)
+		}
+		if len(groupSync.Status.SyncedGroups) != test.synced {
+			t.Errorf("%s: got synced groups %v, want %d", test.label, groupSync.Status.SyncedGroups, test.synced)
+		}
+	}
+}
+
+// TestReconcileGroupSyncConflicts tests the Reconcile function
+// given: a GroupSync of a Group the endpoint serves, whose OpenShift Group exists and is not managed by the operator
+// expected: Failed state, the Group reported conflicting and its members left unchanged
+func TestReconcileGroupSyncConflicts(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	server := newSCIMServer(t)
+	defer server.Close()
+
+	admins := &unstructured.Unstructured{Object: map[string]interface{}{"users": []interface{}{"alice"}}}
+	admins.SetGroupVersionKind(groupGVK)
+	admins.SetName("cluster-admins")
+
+	r := newTestReconciler(server, mockGroupSync(server.URL, "team-a", "cluster-admins"), mockSecret("secret-token"), admins)
+	groupSync := reconcileGroupSync(t, r)
+
+	if groupSync.Status.State != v1alpha1.GroupSyncFailed || groupSync.Status.Message == "" {
+		t.Errorf("got state %s with message %q, want %s with a message", groupSync.Status.State, groupSync.Status.Message, v1alpha1.GroupSyncFailed)
+	}
+	if conflicts := groupSync.Status.ConflictingGroups; len(conflicts) != 1 || conflicts[0] != "cluster-admins" {
+		t.Errorf("got conflicting groups %v, want [cluster-admins]", conflicts)
+	}
+	if len(groupSync.Status.SyncedGroups) != 1 || groupSync.Status.SyncedGroups[0].Name != "team-a" {
+		t.Errorf("got synced groups %v, want team-a", groupSync.Status.SyncedGroups)
+	}
+	if users := getGroupUsers(t, r, "cluster-admins"); len(users) != 1 || users[0] != "alice" {
+		t.Errorf("got cluster-admins users %v, want [alice]", users)
+	}
+}
+
+// TestSecretToGroupSyncs tests the secretToGroupSyncs mapper
+// given: a Secret and GroupSyncs referencing it or not
+// expected: a reconcile request only for the GroupSync referencing the Secret
+func TestSecretToGroupSyncs(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	other := mockGroupSync("https://idp.example.com")
+	other.Name = "other"
+	other.Spec.CredentialsSecretRef = &v1alpha1.SecretKeyReference{Name: "other"}
+	mapper := &secretToGroupSyncs{
+		client: fake.NewFakeClient(mockGroupSync("https://idp.example.com"), other),
+	}
+
+	if requests := mapper.Map(handler.MapObject{Meta: mockSecret("secret-token")}); len(requests) != 1 || requests[0].Name != "testGroupSync" {
+		t.Errorf("expected a request for testGroupSync, got %v", requests)
+	}
+}
@@ -0,0 +1,133 @@
+package groupsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// scimPageSize is the number of Groups requested per page from the SCIM endpoint
+const scimPageSize = 100
+
+// scimListResponse is a page of a SCIM v2 list response (RFC 7644 section 3.4.2)
+type scimListResponse struct {
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    []scimGroup `json:"Resources"`
+}
+
+// scimGroup is a SCIM v2 Group resource (RFC 7643 section 4.2)
+type scimGroup struct {
+	DisplayName string       `json:"displayName"`
+	Members     []scimMember `json:"members"`
+}
+
+// scimMember is a member of a SCIM v2 Group, identified by the id of the User in value. Its display
+// is a human readable name, not a login.
+type scimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display"`
+}
+
+// scimUser is a SCIM v2 User resource (RFC 7643 section 4.1), whose userName is the login of the user
+type scimUser struct {
+	ID       string `json:"id"`
+	UserName string `json:"userName"`
+}
+
+// scimClient reads Groups from a SCIM v2 endpoint
+type scimClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// listGroups returns the members of every Group served by the endpoint, by Group name
+func (c *scimClient) listGroups(ctx context.Context) (map[string][]scimMember, error) {
+	groups := map[string][]scimMember{}
+
+	startIndex := 1
+	for {
+		page, err := c.getPage(ctx, startIndex)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range page.Resources {
+			groups[group.DisplayName] = group.Members
+		}
+
+		startIndex += len(page.Resources)
+		if len(page.Resources) == 0 || startIndex > page.TotalResults {
+			return groups, nil
+		}
+	}
+}
+
+// getPage requests the page of Groups starting at startIndex
+func (c *scimClient) getPage(ctx context.Context, startIndex int) (*scimListResponse, error) {
+	query := url.Values{}
+	query.Set("startIndex", strconv.Itoa(startIndex))
+	query.Set("count", strconv.Itoa(scimPageSize))
+
+	page := &scimListResponse{}
+	if err := c.get(ctx, "/Groups?"+query.Encode(), page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// userNames returns the userName of the Users of members, resolved by their id and cached in userNames
+// across the Groups of a sync. Members without an id are skipped, their display name is not a login.
+func (c *scimClient) userNames(ctx context.Context, members []scimMember, userNames map[string]string) ([]string, error) {
+	var names []string
+	for _, member := range members {
+		if member.Value == "" {
+			continue
+		}
+		name, ok := userNames[member.Value]
+		if !ok {
+			user := &scimUser{}
+			if err := c.get(ctx, "/Users/"+url.PathEscape(member.Value), user); err != nil {
+				return nil, fmt.Errorf("failed to resolve the member %s: %v", member.Value, err)
+			}
+			if user.UserName == "" {
+				return nil, fmt.Errorf("SCIM User %s has no userName", member.Value)
+			}
+			name = user.UserName
+			userNames[member.Value] = name
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// get requests path relative to the base URL of the endpoint and decodes the response into out
+func (c *scimClient) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(c.baseURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/scim+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("SCIM endpoint returned %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode SCIM response: %v", err)
+	}
+	return nil
+}
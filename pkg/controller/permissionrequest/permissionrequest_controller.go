@@ -0,0 +1,353 @@
+package permissionrequest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/readonly"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_permissionrequest")
+
+// Add creates a new PermissionRequest Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	r, err := newReconciler(mgr)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) (reconcile.Reconciler, error) {
+	// PermissionRequests and their RoleBindings are outside the watched namespace, they are read from the apiserver
+	apiClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconcilePermissionRequest{
+		client:    mgr.GetClient(),
+		apiClient: readonly.Wrap(apiClient, mgr.GetScheme()),
+		scheme:    mgr.GetScheme(),
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("permissionrequest-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// PermissionRequests are created in the namespaces of their requesters, the manager cache only
+	// holds the watched namespace so they are watched through a cache of every namespace
+	clusterCache, err := cache.New(mgr.GetConfig(), cache.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return err
+	}
+	if err := mgr.Add(clusterCache); err != nil {
+		return err
+	}
+	permissionRequests := &source.Kind{Type: &managedv1alpha1.PermissionRequest{}}
+	if err := permissionRequests.InjectCache(clusterCache); err != nil {
+		return err
+	}
+
+	// Watch for changes to primary resource PermissionRequest
+	err = c.Watch(permissionRequests, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// blank assignment to verify that ReconcilePermissionRequest implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcilePermissionRequest{}
+
+// ReconcilePermissionRequest reconciles a PermissionRequest object
+type ReconcilePermissionRequest struct {
+	// client reads objects from the cache and writes to the apiserver, for the operator config
+	client client.Client
+	// apiClient reads directly from the apiserver, for objects outside the watched namespace
+	apiClient client.Client
+	scheme    *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=permissionrequests,verbs=get;list;watch
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=permissionrequests/status,verbs=update
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;create;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;bind
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch,namespace=openshift-rbac-permissions-operator
+
+// Reconcile grants the ClusterRole of a PermissionRequest to its Group in the namespace of the
+// PermissionRequest, if the operator policy allows the ClusterRole and its requester, recorded by the
+// webhook, is a member of the Group allowed to bind the ClusterRole in the namespace. The RoleBinding is
+// owned by the PermissionRequest, so it is deleted with it.
+func (r *ReconcilePermissionRequest) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling PermissionRequest")
+
+	instance := &managedv1alpha1.PermissionRequest{}
+	err := r.apiClient.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if instance.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	operatorConfig, err := operatorconfig.GetOperatorConfig(context.TODO(), r.client)
+	if err != nil {
+		reqLogger.Error(err, "Failed to get operator config")
+		return reconcile.Result{}, err
+	}
+
+	reason := ""
+	if !operatorConfig.IsRequestRoleAllowed(instance.Spec.ClusterRoleName) {
+		reason = fmt.Sprintf("ClusterRole %s is not allowed by the operator policy", instance.Spec.ClusterRoleName)
+	} else if reason, err = r.checkRequester(instance); err != nil {
+		reqLogger.Error(err, "Failed to verify the requester")
+		return reconcile.Result{}, err
+	}
+
+	status := managedv1alpha1.PermissionRequestStatus{State: managedv1alpha1.PermissionRequestGranted}
+	if reason != "" {
+		// revoke the access granted before the policy or the rights of the requester changed
+		if err := r.deleteRoleBinding(instance); err != nil {
+			reqLogger.Error(err, "Failed to delete RoleBinding")
+			return reconcile.Result{}, err
+		}
+		reqLogger.Info("Denied PermissionRequest", "ClusterRole", instance.Spec.ClusterRoleName, "Reason", reason)
+		status = managedv1alpha1.PermissionRequestStatus{
+			State:   managedv1alpha1.PermissionRequestDenied,
+			Message: reason,
+		}
+	} else if err := r.ensureRoleBinding(instance); err != nil {
+		reqLogger.Error(err, "Failed to grant PermissionRequest")
+		status = managedv1alpha1.PermissionRequestStatus{
+			State:   managedv1alpha1.PermissionRequestFailed,
+			Message: err.Error(),
+		}
+		if statusErr := r.updateStatus(instance, status); statusErr != nil {
+			reqLogger.Error(statusErr, "Failed to update status.")
+		}
+		return reconcile.Result{}, err
+	} else {
+		status.RoleBindingName = roleBindingName(instance)
+	}
+
+	if err := r.updateStatus(instance, status); err != nil {
+		reqLogger.Error(err, "Failed to update status.")
+		return reconcile.Result{}, err
+	}
+
+	// RoleBindings are not watched, resync so changes to the RoleBinding or the policy are corrected
+	return reconcile.Result{RequeueAfter: operatorConfig.Jitter(operatorConfig.ResyncInterval)}, nil
+}
+
+// checkRequester returns the reason the requester of permissionRequest may not grant its ClusterRole to
+// its Group, none if they may. The operator binds the ClusterRole with its own rights, so the requester
+// must be a member of the Group and be allowed to bind the ClusterRole in the namespace themselves.
+func (r *ReconcilePermissionRequest) checkRequester(permissionRequest *managedv1alpha1.PermissionRequest) (string, error) {
+	requester, ok := utility.RequesterFromAnnotations(permissionRequest.Annotations)
+	if !ok {
+		return "no requester is recorded on the PermissionRequest", nil
+	}
+	if !isGroupMember(requester, permissionRequest.Spec.GroupName) {
+		return fmt.Sprintf("the requester is not a member of Group %s", permissionRequest.Spec.GroupName), nil
+	}
+	allowed, err := r.isBindAllowed(requester, permissionRequest)
+	if err != nil || allowed {
+		return "", err
+	}
+	return fmt.Sprintf("the requester is not allowed to bind ClusterRole %s in namespace %s", permissionRequest.Spec.ClusterRoleName, permissionRequest.Namespace), nil
+}
+
+// isGroupMember returns whether groupName is one of the groups of requester
+func isGroupMember(requester authenticationv1.UserInfo, groupName string) bool {
+	for _, group := range requester.Groups {
+		if group == groupName {
+			return true
+		}
+	}
+	return false
+}
+
+// isBindAllowed mirrors the RBAC escalation rules of the API server for requester: they may bind the
+// ClusterRole of permissionRequest in its namespace if they hold the "bind" verb on it, or if they already
+// hold every permission of the ClusterRole there. A missing ClusterRole may only be bound with the verb.
+func (r *ReconcilePermissionRequest) isBindAllowed(requester authenticationv1.UserInfo, permissionRequest *managedv1alpha1.PermissionRequest) (bool, error) {
+	allowed, err := r.isAccessAllowed(utility.NewSubjectAccessReview(requester, &authorizationv1.ResourceAttributes{
+		Namespace: permissionRequest.Namespace,
+		Group:     rbacv1.GroupName,
+		Resource:  "clusterroles",
+		Verb:      "bind",
+		Name:      permissionRequest.Spec.ClusterRoleName,
+	}, nil))
+	if err != nil || allowed {
+		return allowed, err
+	}
+
+	clusterRole := &rbacv1.ClusterRole{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: permissionRequest.Spec.ClusterRoleName}, clusterRole)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, sar := range utility.RuleSubjectAccessReviews(requester, clusterRole.Rules, permissionRequest.Namespace) {
+		allowed, err := r.isAccessAllowed(sar)
+		if err != nil || !allowed {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// isAccessAllowed submits the SubjectAccessReview and returns whether the access is allowed
+func (r *ReconcilePermissionRequest) isAccessAllowed(sar *authorizationv1.SubjectAccessReview) (bool, error) {
+	if err := r.client.Create(context.TODO(), sar); err != nil {
+		return false, err
+	}
+	return sar.Status.Allowed, nil
+}
+
+// ensureRoleBinding creates the RoleBinding granting the ClusterRole of permissionRequest, replacing
+// an outdated one since the role of a RoleBinding cannot be changed
+func (r *ReconcilePermissionRequest) ensureRoleBinding(permissionRequest *managedv1alpha1.PermissionRequest) error {
+	desired := newRoleBinding(permissionRequest)
+	if err := controllerutil.SetControllerReference(permissionRequest, desired, r.scheme); err != nil {
+		return err
+	}
+	defer bindinglock.Lock(bindinglock.RoleBinding, desired.Namespace, desired.Name)()
+
+	existing := &rbacv1.RoleBinding{}
+	err := r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, existing)
+	if err == nil {
+		if !metav1.IsControlledBy(existing, permissionRequest) {
+			return fmt.Errorf("RoleBinding %s exists and is not owned by the PermissionRequest", desired.Name)
+		}
+		if existing.RoleRef == desired.RoleRef && reflect.DeepEqual(existing.Subjects, desired.Subjects) {
+			if isManagedFor(existing.ObjectMeta, permissionRequest) {
+				return nil
+			}
+			// label a RoleBinding created before the bindings were labeled
+			if existing.Labels == nil {
+				existing.Labels = map[string]string{}
+			}
+			for key, value := range desired.Labels {
+				existing.Labels[key] = value
+			}
+			return r.apiClient.Update(context.TODO(), existing)
+		}
+		if err := r.apiClient.Delete(context.TODO(), existing); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	return r.apiClient.Create(context.TODO(), desired)
+}
+
+// deleteRoleBinding deletes the RoleBinding of permissionRequest, if it exists
+func (r *ReconcilePermissionRequest) deleteRoleBinding(permissionRequest *managedv1alpha1.PermissionRequest) error {
+	defer bindinglock.Lock(bindinglock.RoleBinding, permissionRequest.Namespace, roleBindingName(permissionRequest))()
+	existing := &rbacv1.RoleBinding{}
+	err := r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: permissionRequest.Namespace, Name: roleBindingName(permissionRequest)}, existing)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !metav1.IsControlledBy(existing, permissionRequest) {
+		return nil
+	}
+	if err := r.apiClient.Delete(context.TODO(), existing); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// updateStatus records status on permissionRequest, if it changed
+func (r *ReconcilePermissionRequest) updateStatus(permissionRequest *managedv1alpha1.PermissionRequest, status managedv1alpha1.PermissionRequestStatus) error {
+	if permissionRequest.Status == status {
+		return nil
+	}
+	permissionRequest.Status = status
+	return r.apiClient.Status().Update(context.TODO(), permissionRequest)
+}
+
+// roleBindingName returns the name of the RoleBinding of permissionRequest
+func roleBindingName(permissionRequest *managedv1alpha1.PermissionRequest) string {
+	return "permissionrequest-" + permissionRequest.Name
+}
+
+// newRoleBinding returns the RoleBinding granting the ClusterRole of permissionRequest to its Group.
+// API groups are set as defaulted by the apiserver, so an existing RoleBinding compares equal.
+func newRoleBinding(permissionRequest *managedv1alpha1.PermissionRequest) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      roleBindingName(permissionRequest),
+			Namespace: permissionRequest.Namespace,
+			Labels:    managedLabels(permissionRequest),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Group",
+				Name:     permissionRequest.Spec.GroupName,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     permissionRequest.Spec.ClusterRoleName,
+		},
+	}
+}
+
+// managedLabels returns the labels of the RoleBinding managed for permissionRequest. They carry no
+// GroupPermission owner labels, so the binding is not collected as an orphan of a GroupPermission.
+func managedLabels(permissionRequest *managedv1alpha1.PermissionRequest) map[string]string {
+	managedLabels := utility.ManagedByLabels()
+	managedLabels[operatorconfig.PermissionRequestNameLabel] = permissionRequest.Name
+	return managedLabels
+}
+
+// isManagedFor returns whether objectMeta belongs to the RoleBinding managed for permissionRequest
+func isManagedFor(objectMeta metav1.ObjectMeta, permissionRequest *managedv1alpha1.PermissionRequest) bool {
+	return labels.SelectorFromSet(managedLabels(permissionRequest)).Matches(labels.Set(objectMeta.Labels))
+}
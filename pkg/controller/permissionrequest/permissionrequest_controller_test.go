@@ -0,0 +1,280 @@
+package permissionrequest
+
+import (
+	"context"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var requestKey = types.NamespacedName{Namespace: "team-a", Name: "testPermissionRequest"}
+
+// mockPermissionRequest returns a PermissionRequest of clusterRoleName requested by a member of its Group
+// accessReviewClient allows to bind any ClusterRole
+func mockPermissionRequest(clusterRoleName string) *v1alpha1.PermissionRequest {
+	return &v1alpha1.PermissionRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      requestKey.Name,
+			Namespace: requestKey.Namespace,
+			UID:       "permission-request-uid",
+			Annotations: map[string]string{
+				operatorconfig.RequesterAnnotation:       "team-a-lead",
+				operatorconfig.RequesterGroupsAnnotation: "system:authenticated,team-a-developers",
+			},
+		},
+		Spec: v1alpha1.PermissionRequestSpec{
+			GroupName:       "team-a-developers",
+			ClusterRoleName: clusterRoleName,
+		},
+	}
+}
+
+// mockOperatorConfig returns the operator ConfigMap allowing PermissionRequests to grant view and edit
+func mockOperatorConfig() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      operatorconfig.OperatorConfigMapName,
+			Namespace: operatorconfig.OperatorNamespace,
+		},
+		Data: map[string]string{"permission_request_allowed_roles": "view,edit"},
+	}
+}
+
+// accessReviewClient answers the SubjectAccessReviews created with it as allowed for the team-a-lead user,
+// and for the team-a-member user except for the bind verb, and passes the other requests to the client it
+// wraps
+type accessReviewClient struct {
+	client.Client
+}
+
+func (c *accessReviewClient) Create(ctx context.Context, obj runtime.Object) error {
+	if sar, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		isBind := sar.Spec.ResourceAttributes != nil && sar.Spec.ResourceAttributes.Verb == "bind"
+		sar.Status.Allowed = sar.Spec.User == "team-a-lead" || (sar.Spec.User == "team-a-member" && !isBind)
+		return nil
+	}
+	return c.Client.Create(ctx, obj)
+}
+
+func newTestReconciler(t *testing.T, objs ...runtime.Object) *ReconcilePermissionRequest {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	fakeClient := &accessReviewClient{Client: fake.NewFakeClient(objs...)}
+	return &ReconcilePermissionRequest{
+		client:    fakeClient,
+		apiClient: fakeClient,
+		scheme:    scheme.Scheme,
+	}
+}
+
+// reconcilePermissionRequest reconciles the test PermissionRequest and returns it
+func reconcilePermissionRequest(t *testing.T, r *ReconcilePermissionRequest) *v1alpha1.PermissionRequest {
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: requestKey}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	permissionRequest := &v1alpha1.PermissionRequest{}
+	if err := r.apiClient.Get(context.TODO(), requestKey, permissionRequest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return permissionRequest
+}
+
+// TestReconcileGranted tests the Reconcile function
+// given: a PermissionRequest for a ClusterRole allowed by the policy, then for another allowed ClusterRole
+// expected: a RoleBinding owned by and labeled for the PermissionRequest grants the requested ClusterRole to the Group
+func TestReconcileGranted(t *testing.T) {
+	r := newTestReconciler(t, mockPermissionRequest("view"), mockOperatorConfig())
+
+	permissionRequest := reconcilePermissionRequest(t, r)
+	if permissionRequest.Status.State != v1alpha1.PermissionRequestGranted || permissionRequest.Status.RoleBindingName != "permissionrequest-testPermissionRequest" {
+		t.Fatalf("expected the request to be granted, got %+v", permissionRequest.Status)
+	}
+
+	roleBinding := &rbacv1.RoleBinding{}
+	roleBindingKey := types.NamespacedName{Namespace: requestKey.Namespace, Name: permissionRequest.Status.RoleBindingName}
+	if err := r.apiClient.Get(context.TODO(), roleBindingKey, roleBinding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if roleBinding.RoleRef.Name != "view" || roleBinding.Subjects[0].Name != "team-a-developers" {
+		t.Errorf("expected view granted to team-a-developers, got %v %v", roleBinding.RoleRef, roleBinding.Subjects)
+	}
+	if !metav1.IsControlledBy(roleBinding, permissionRequest) {
+		t.Errorf("expected the RoleBinding to be owned by the PermissionRequest")
+	}
+	if !isManagedFor(roleBinding.ObjectMeta, permissionRequest) {
+		t.Errorf("expected the RoleBinding to be labeled as managed for the PermissionRequest, got %v", roleBinding.Labels)
+	}
+
+	permissionRequest.Spec.ClusterRoleName = "edit"
+	if err := r.apiClient.Update(context.TODO(), permissionRequest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reconcilePermissionRequest(t, r)
+	if err := r.apiClient.Get(context.TODO(), roleBindingKey, roleBinding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if roleBinding.RoleRef.Name != "edit" {
+		t.Errorf("expected the RoleBinding to be replaced to grant edit, got %v", roleBinding.RoleRef)
+	}
+}
+
+// TestReconcileDenied tests the Reconcile function
+// given: a granted PermissionRequest for a ClusterRole no longer allowed by the policy
+// expected: the request is denied and its RoleBinding deleted
+func TestReconcileDenied(t *testing.T) {
+	permissionRequest := mockPermissionRequest("admin")
+	roleBinding := newRoleBinding(permissionRequest)
+	r := newTestReconciler(t, permissionRequest, mockOperatorConfig())
+	if err := r.ensureRoleBinding(permissionRequest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	permissionRequest = reconcilePermissionRequest(t, r)
+	if permissionRequest.Status.State != v1alpha1.PermissionRequestDenied || permissionRequest.Status.Message == "" {
+		t.Errorf("expected the request to be denied, got %+v", permissionRequest.Status)
+	}
+	err := r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: roleBinding.Namespace, Name: roleBinding.Name}, &rbacv1.RoleBinding{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected the RoleBinding to be deleted, got %v", err)
+	}
+}
+
+// TestReconcileUnlabeledRoleBinding tests the Reconcile function
+// given: a granted PermissionRequest whose RoleBinding was created before the bindings were labeled
+// expected: the RoleBinding is labeled as managed for the PermissionRequest, keeping its other labels
+func TestReconcileUnlabeledRoleBinding(t *testing.T) {
+	permissionRequest := mockPermissionRequest("view")
+	r := newTestReconciler(t, permissionRequest, mockOperatorConfig())
+	roleBinding := newRoleBinding(permissionRequest)
+	roleBinding.Labels = map[string]string{"team": "a"}
+	if err := controllerutil.SetControllerReference(permissionRequest, roleBinding, r.scheme); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.apiClient.Create(context.TODO(), roleBinding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	permissionRequest = reconcilePermissionRequest(t, r)
+	if permissionRequest.Status.State != v1alpha1.PermissionRequestGranted {
+		t.Errorf("Mismatch for state. Expected(%v), Found(%+v)", v1alpha1.PermissionRequestGranted, permissionRequest.Status)
+	}
+	existing := &rbacv1.RoleBinding{}
+	if err := r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: roleBinding.Namespace, Name: roleBinding.Name}, existing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !isManagedFor(existing.ObjectMeta, permissionRequest) || existing.Labels["team"] != "a" {
+		t.Errorf("Mismatch for labels. Expected(managed for the PermissionRequest and team=a), Found(%v)", existing.Labels)
+	}
+}
+
+// TestReconcileForeignRoleBinding tests the Reconcile function
+// given: a PermissionRequest whose RoleBinding name is taken by a RoleBinding it does not own
+// expected: the request fails and the RoleBinding is left unchanged
+func TestReconcileForeignRoleBinding(t *testing.T) {
+	roleBinding := newRoleBinding(mockPermissionRequest("view"))
+	roleBinding.RoleRef.Name = "cluster-admin"
+	r := newTestReconciler(t, mockPermissionRequest("view"), mockOperatorConfig(), roleBinding)
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: requestKey}); err == nil {
+		t.Errorf("expected an error for a RoleBinding not owned by the PermissionRequest")
+	}
+	permissionRequest := &v1alpha1.PermissionRequest{}
+	if err := r.apiClient.Get(context.TODO(), requestKey, permissionRequest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if permissionRequest.Status.State != v1alpha1.PermissionRequestFailed {
+		t.Errorf("expected the request to fail, got %+v", permissionRequest.Status)
+	}
+	existing := &rbacv1.RoleBinding{}
+	if err := r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: roleBinding.Namespace, Name: roleBinding.Name}, existing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if existing.RoleRef.Name != "cluster-admin" {
+		t.Errorf("expected the RoleBinding to be left unchanged, got %v", existing.RoleRef)
+	}
+}
+
+// TestReconcileRequester tests the Reconcile function
+// given: PermissionRequests for view, granted before, with various requesters
+// expected: the request is granted only if the requester is a member of the Group allowed to bind view,
+// or holding every permission of view, in the namespace; otherwise the RoleBinding is deleted
+func TestReconcileRequester(t *testing.T) {
+	viewClusterRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "view"},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"get", "list"},
+		}},
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		objs        []runtime.Object
+		expected    v1alpha1.PermissionRequestState
+	}{
+		{
+			name:     "no requester",
+			expected: v1alpha1.PermissionRequestDenied,
+		},
+		{
+			name:        "requester not a member of the Group",
+			annotations: map[string]string{operatorconfig.RequesterAnnotation: "team-a-lead", operatorconfig.RequesterGroupsAnnotation: "team-b-developers"},
+			expected:    v1alpha1.PermissionRequestDenied,
+		},
+		{
+			name:        "member without rights",
+			annotations: map[string]string{operatorconfig.RequesterAnnotation: "intern", operatorconfig.RequesterGroupsAnnotation: "team-a-developers"},
+			objs:        []runtime.Object{viewClusterRole},
+			expected:    v1alpha1.PermissionRequestDenied,
+		},
+		{
+			name:        "member holding the permissions of the ClusterRole",
+			annotations: map[string]string{operatorconfig.RequesterAnnotation: "team-a-member", operatorconfig.RequesterGroupsAnnotation: "team-a-developers"},
+			objs:        []runtime.Object{viewClusterRole},
+			expected:    v1alpha1.PermissionRequestGranted,
+		},
+		{
+			name:        "member without bind of a missing ClusterRole",
+			annotations: map[string]string{operatorconfig.RequesterAnnotation: "team-a-member", operatorconfig.RequesterGroupsAnnotation: "team-a-developers"},
+			expected:    v1alpha1.PermissionRequestDenied,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			permissionRequest := mockPermissionRequest("view")
+			permissionRequest.Annotations = test.annotations
+			roleBinding := newRoleBinding(permissionRequest)
+			r := newTestReconciler(t, append(test.objs, permissionRequest, mockOperatorConfig())...)
+			if err := r.ensureRoleBinding(permissionRequest); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			permissionRequest = reconcilePermissionRequest(t, r)
+			if permissionRequest.Status.State != test.expected {
+				t.Errorf("Mismatch for state. Expected(%v), Found(%+v)", test.expected, permissionRequest.Status)
+			}
+			err := r.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: roleBinding.Namespace, Name: roleBinding.Name}, &rbacv1.RoleBinding{})
+			if found := err == nil; found != (test.expected == v1alpha1.PermissionRequestGranted) {
+				t.Errorf("Mismatch for RoleBinding. Expected(%v), Found(%v)", test.expected == v1alpha1.PermissionRequestGranted, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentials resolves the credentials of external integrations (group sync, audit sinks,
+// notifications) from Secrets referenced by their spec.credentialsSecretRef, and keeps them out of
+// logs and status.
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultKey is the key of the Secret read when a reference does not set one
+	DefaultKey = "token"
+	// redacted replaces credentials in messages
+	redacted = "[REDACTED]"
+)
+
+// Resolve returns the credentials referenced by ref in namespace. Errors name the Secret and key but
+// never include Secret data, so they are safe to log and to record in status.
+func Resolve(ctx context.Context, c client.Client, namespace string, ref *managedv1alpha1.SecretKeyReference) (string, error) {
+	if ref == nil || ref.Name == "" {
+		return "", fmt.Errorf("credentialsSecretRef is not set")
+	}
+	key := Key(ref)
+
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", fmt.Errorf("credentials secret %s not found", ref.Name)
+		}
+		return "", fmt.Errorf("failed to get credentials secret %s: %v", ref.Name, err)
+	}
+
+	value := strings.TrimSpace(string(secret.Data[key]))
+	if value == "" {
+		return "", fmt.Errorf("credentials secret %s has no %q key", ref.Name, key)
+	}
+	return value, nil
+}
+
+// Key returns the key of the Secret referenced by ref
+func Key(ref *managedv1alpha1.SecretKeyReference) string {
+	if ref.Key == "" {
+		return DefaultKey
+	}
+	return ref.Key
+}
+
+// Redact replaces every occurrence of the credentials in message
+func Redact(message, credentials string) string {
+	if credentials == "" {
+		return message
+	}
+	return strings.Replace(message, credentials, redacted, -1)
+}
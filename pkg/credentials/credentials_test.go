@@ -0,0 +1,60 @@
+package credentials
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolve(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "ns"},
+		Data: map[string][]byte{
+			"token":    []byte("s3cr3t\n"),
+			"password": []byte("hunter2"),
+			"empty":    []byte(""),
+		},
+	}
+	c := fake.NewFakeClient(secret)
+
+	var tests = []struct {
+		label    string
+		ref      *managedv1alpha1.SecretKeyReference
+		expected string
+		valid    bool
+	}{
+		{"default key", &managedv1alpha1.SecretKeyReference{Name: "creds"}, "s3cr3t", true},
+		{"explicit key", &managedv1alpha1.SecretKeyReference{Name: "creds", Key: "password"}, "hunter2", true},
+		{"empty key", &managedv1alpha1.SecretKeyReference{Name: "creds", Key: "empty"}, "", false},
+		{"missing key", &managedv1alpha1.SecretKeyReference{Name: "creds", Key: "other"}, "", false},
+		{"missing secret", &managedv1alpha1.SecretKeyReference{Name: "other"}, "", false},
+		{"no reference", nil, "", false},
+	}
+	for _, test := range tests {
+		value, err := Resolve(context.TODO(), c, "ns", test.ref)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got error %v", test.label, test.valid, err)
+			continue
+		}
+		if value != test.expected {
+			t.Errorf("%s: got %q, want %q", test.label, value, test.expected)
+		}
+		if err != nil && (strings.Contains(err.Error(), "s3cr3t") || strings.Contains(err.Error(), "hunter2")) {
+			t.Errorf("%s: error leaks credentials: %v", test.label, err)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	if got := Redact("GET https://idp/?token=abc failed: abc", "abc"); got != "GET https://idp/?token=[REDACTED] failed: [REDACTED]" {
+		t.Errorf("got %q", got)
+	}
+	if got := Redact("failed", ""); got != "failed" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"context"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/bindinglock"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("gc")
+
+// blank assignment to verify that Collector implements manager.Runnable
+var _ manager.Runnable = &Collector{}
+
+// Collector collects the orphaned bindings of a shard once, when the manager it is added to starts, so
+// the collection runs on the leader of the shard only
+type Collector struct {
+	client  client.Client
+	dryRun  bool
+	inShard func(owner types.NamespacedName) bool
+}
+
+// NewCollector returns a Collector of the orphaned bindings of the owners inShard returns true for, see
+// CollectOrphanedBindings
+func NewCollector(c client.Client, dryRun bool, inShard func(owner types.NamespacedName) bool) *Collector {
+	return &Collector{client: c, dryRun: dryRun, inShard: inShard}
+}
+
+// Start implements manager.Runnable, it collects the orphaned bindings once. A failed collection is
+// logged and retried on the next start, it does not stop the manager.
+func (c *Collector) Start(stop <-chan struct{}) error {
+	orphans, err := CollectOrphanedBindings(context.TODO(), c.client, c.dryRun, c.inShard)
+	if err != nil {
+		log.Error(err, "Failed to collect orphaned bindings")
+	}
+	log.Info("Collected orphaned bindings", "Count", orphans, "DryRun", c.dryRun)
+	return nil
+}
+
+// collector deletes managed RBAC objects whose owning GroupPermission no longer exists
+type collector struct {
+	client client.Client
+	dryRun bool
+	// inShard returns whether the bindings of an owning GroupPermission are collected, all are when nil
+	inShard func(owner types.NamespacedName) bool
+	// owners caches whether each owning GroupPermission exists
+	owners map[types.NamespacedName]bool
+}
+
+// CollectOrphanedBindings deletes the managed ClusterRoleBindings and RoleBindings whose owning
+// GroupPermission no longer exists, e.g. because it was deleted while the operator was down and its
+// finalizer was removed by hand. Only the bindings of the owners inShard returns true for are collected,
+// every binding when inShard is nil. With dryRun orphans are only logged. Returns the number of orphans.
+func CollectOrphanedBindings(ctx context.Context, c client.Client, dryRun bool, inShard func(owner types.NamespacedName) bool) (int, error) {
+	col := &collector{
+		client:  c,
+		dryRun:  dryRun,
+		inShard: inShard,
+		owners:  map[types.NamespacedName]bool{},
+	}
+	opts := &client.ListOptions{LabelSelector: utility.ManagedSelector()}
+
+	clusterRoleBindingList := &rbacv1.ClusterRoleBindingList{}
+	if err := c.List(ctx, opts, clusterRoleBindingList); err != nil {
+		return 0, err
+	}
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := c.List(ctx, opts, roleBindingList); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range clusterRoleBindingList.Items {
+		collected, err := col.collect(ctx, "ClusterRoleBinding", &clusterRoleBindingList.Items[i].ObjectMeta, &clusterRoleBindingList.Items[i])
+		if err != nil {
+			return count, err
+		}
+		if collected {
+			count++
+		}
+	}
+	for i := range roleBindingList.Items {
+		collected, err := col.collect(ctx, "RoleBinding", &roleBindingList.Items[i].ObjectMeta, &roleBindingList.Items[i])
+		if err != nil {
+			return count, err
+		}
+		if collected {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// collect deletes obj if its owning GroupPermission does not exist, and returns whether it was an orphan
+func (col *collector) collect(ctx context.Context, kind string, objectMeta *metav1.ObjectMeta, obj runtime.Object) (bool, error) {
+	owner, ok := utility.ManagedOwner(*objectMeta)
+	if !ok {
+		// not owned by a GroupPermission
+		return false, nil
+	}
+	if col.inShard != nil && !col.inShard(owner) {
+		// left to the operator instance of the shard of the owner
+		return false, nil
+	}
+
+	exists, ok := col.owners[owner]
+	if !ok {
+		err := col.client.Get(ctx, owner, &managedv1alpha1.GroupPermission{})
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		exists = err == nil
+		col.owners[owner] = exists
+	}
+	if exists {
+		return false, nil
+	}
+
+	logger := log.WithValues("Kind", kind, "Namespace", objectMeta.Namespace, "Name", objectMeta.Name, "Owner", owner.String(), "DryRun", col.dryRun)
+	localmetrics.IncOrphanedBindingsCollected(kind, col.dryRun)
+	if col.dryRun {
+		logger.Info("Found orphaned binding")
+		return true, nil
+	}
+
+	logger.Info("Deleting orphaned binding")
+	unlock := bindinglock.Lock(kind, objectMeta.Namespace, objectMeta.Name)
+	err := col.client.Delete(ctx, obj)
+	unlock()
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	return true, nil
+}
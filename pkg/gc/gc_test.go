@@ -0,0 +1,157 @@
+package gc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func mockGroupPermission(namespace, name string) *v1alpha1.GroupPermission {
+	return &v1alpha1.GroupPermission{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+}
+
+func mockClusterRoleBinding(name string, labels map[string]string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+func mockRoleBinding(namespace, name string, labels map[string]string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+	}
+}
+
+func newTestClient() client.Client {
+	return fake.NewFakeClient(
+		mockGroupPermission("rbac-permissions-operator", "kept"),
+		mockClusterRoleBinding("owned", utility.ManagedLabels("rbac-permissions-operator", "kept")),
+		mockClusterRoleBinding("orphaned", utility.ManagedLabels("rbac-permissions-operator", "deleted")),
+		mockClusterRoleBinding("unmanaged", nil),
+		mockRoleBinding("team-a", "owned", utility.ManagedLabels("rbac-permissions-operator", "kept")),
+		mockRoleBinding("team-a", "orphaned", utility.ManagedLabels("rbac-permissions-operator", "deleted")),
+	)
+}
+
+func exists(t *testing.T, c client.Client, key types.NamespacedName, obj interface{}) bool {
+	var err error
+	switch o := obj.(type) {
+	case *rbacv1.ClusterRoleBinding:
+		err = c.Get(context.TODO(), key, o)
+	case *rbacv1.RoleBinding:
+		err = c.Get(context.TODO(), key, o)
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return err == nil
+}
+
+// TestCollectOrphanedBindings tests the CollectOrphanedBindings function
+// given: managed bindings of an existing and a deleted GroupPermission, and an unmanaged binding
+// expected: only the bindings of the deleted GroupPermission are deleted
+func TestCollectOrphanedBindings(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	c := newTestClient()
+	count, err := CollectOrphanedBindings(context.TODO(), c, false, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("got %d orphans, want 2", count)
+	}
+
+	tests := []struct {
+		key      types.NamespacedName
+		obj      interface{}
+		expected bool
+	}{
+		{types.NamespacedName{Name: "owned"}, &rbacv1.ClusterRoleBinding{}, true},
+		{types.NamespacedName{Name: "orphaned"}, &rbacv1.ClusterRoleBinding{}, false},
+		{types.NamespacedName{Name: "unmanaged"}, &rbacv1.ClusterRoleBinding{}, true},
+		{types.NamespacedName{Namespace: "team-a", Name: "owned"}, &rbacv1.RoleBinding{}, true},
+		{types.NamespacedName{Namespace: "team-a", Name: "orphaned"}, &rbacv1.RoleBinding{}, false},
+	}
+	for _, test := range tests {
+		if got := exists(t, c, test.key, test.obj); got != test.expected {
+			t.Errorf("%T %s: got exists %t, want %t", test.obj, test.key, got, test.expected)
+		}
+	}
+}
+
+// TestCollectOrphanedBindingsDryRun tests the CollectOrphanedBindings function with dryRun
+// given: managed bindings of a deleted GroupPermission
+// expected: the orphans are counted but not deleted
+func TestCollectOrphanedBindingsDryRun(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	c := newTestClient()
+	count, err := CollectOrphanedBindings(context.TODO(), c, true, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("got %d orphans, want 2", count)
+	}
+	if !exists(t, c, types.NamespacedName{Name: "orphaned"}, &rbacv1.ClusterRoleBinding{}) {
+		t.Errorf("orphaned ClusterRoleBinding was deleted on dry run")
+	}
+	if !exists(t, c, types.NamespacedName{Namespace: "team-a", Name: "orphaned"}, &rbacv1.RoleBinding{}) {
+		t.Errorf("orphaned RoleBinding was deleted on dry run")
+	}
+}
+
+// TestCollectOrphanedBindingsInShard tests the CollectOrphanedBindings function limited to a shard
+// given: managed bindings of deleted GroupPermissions in and out of the shard
+// expected: only the orphans of the GroupPermissions of the shard are deleted
+func TestCollectOrphanedBindingsInShard(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	c := newTestClient()
+	if err := c.Create(context.TODO(), mockRoleBinding("team-a", "other-shard", utility.ManagedLabels("team-b", "deleted"))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inShard := func(owner types.NamespacedName) bool { return owner.Namespace == "rbac-permissions-operator" }
+	count, err := CollectOrphanedBindings(context.TODO(), c, false, inShard)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Mismatch for orphans. Expected(2), Found(%d)", count)
+	}
+	if exists(t, c, types.NamespacedName{Namespace: "team-a", Name: "orphaned"}, &rbacv1.RoleBinding{}) {
+		t.Errorf("Mismatch for the orphan of the shard. Expected(deleted), Found(kept)")
+	}
+	if !exists(t, c, types.NamespacedName{Namespace: "team-a", Name: "other-shard"}, &rbacv1.RoleBinding{}) {
+		t.Errorf("Mismatch for the orphan of another shard. Expected(kept), Found(deleted)")
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grantdecision is the interface of the grant decision services: external services the operator
+// asks before it creates a binding, which may deny the binding or add annotations to it. A service
+// implements Decider and serves it over HTTP with Handler, the operator asks it through a Client.
+//
+// The operator POSTs a Request as JSON to the endpoint of the service, which answers with a Decision as
+// JSON and a 200 status. Any other status is a failure of the service.
+package grantdecision
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// Request is a binding computed by the operator, about to be created
+type Request struct {
+	Subject rbacv1.Subject `json:"subject"`
+	RoleRef rbacv1.RoleRef `json:"roleRef"`
+	// Namespace of a RoleBinding, empty for a ClusterRoleBinding
+	Namespace       string `json:"namespace,omitempty"`
+	GroupPermission Owner  `json:"groupPermission"`
+}
+
+// Owner identifies the GroupPermission a binding is managed for
+type Owner struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// Decision is the decision of a grant decision service on a binding
+type Decision struct {
+	Allowed bool `json:"allowed"`
+	// Message describing why the binding is denied
+	Message string `json:"message,omitempty"`
+	// Annotations added to the binding when it is allowed, such as the ID of the approval of the grant.
+	// The role and subject of the binding cannot be changed, they are the ones the escalation check and
+	// the policy verified.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Decider decides on the bindings the operator is about to create
+type Decider interface {
+	Decide(ctx context.Context, request Request) (Decision, error)
+}
+
+// DeciderFunc is a function implementing Decider
+type DeciderFunc func(ctx context.Context, request Request) (Decision, error)
+
+// Decide calls f
+func (f DeciderFunc) Decide(ctx context.Context, request Request) (Decision, error) {
+	return f(ctx, request)
+}
+
+// Client is the Decider of the grant decision service served at Endpoint
+type Client struct {
+	HTTPClient *http.Client
+	Endpoint   string
+}
+
+// Decide sends request to the grant decision service and returns its decision
+func (c *Client) Decide(ctx context.Context, request Request) (Decision, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return Decision{}, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Decision{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Decision{}, fmt.Errorf("grant decision service returned %s", resp.Status)
+	}
+	decision := Decision{}
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return Decision{}, fmt.Errorf("failed to decode grant decision: %v", err)
+	}
+	return decision, nil
+}
+
+// Handler returns the HTTP handler serving the decisions of decider
+func Handler(decider Decider) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		request := Request{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode grant request: %v", err), http.StatusBadRequest)
+			return
+		}
+		decision, err := decider.Decide(r.Context(), request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(decision); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// RoleDenylist is the reference Decider: it denies the bindings of the roles it holds, by "Kind name",
+// with the message of each, and allows the others annotated as decided by it
+type RoleDenylist map[string]string
+
+// DecidedByAnnotation is set by RoleDenylist on the bindings it allows
+const DecidedByAnnotation = "grantdecision.managed.openshift.io/decided-by"
+
+// Decide denies request when its role is in d
+func (d RoleDenylist) Decide(ctx context.Context, request Request) (Decision, error) {
+	if message, ok := d[request.RoleRef.Kind+" "+request.RoleRef.Name]; ok {
+		return Decision{Message: message}, nil
+	}
+	return Decision{Allowed: true, Annotations: map[string]string{DecidedByAnnotation: "role-denylist"}}, nil
+}
@@ -0,0 +1,67 @@
+package grantdecision
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestClientRoleDenylist(t *testing.T) {
+	server := httptest.NewServer(Handler(RoleDenylist{"ClusterRole cluster-admin": "cluster-admin is granted by break-glass only"}))
+	defer server.Close()
+	client := &Client{Endpoint: server.URL}
+
+	var tests = []struct {
+		label    string
+		roleRef  rbacv1.RoleRef
+		expected Decision
+	}{
+		{"denied", rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin"}, Decision{Message: "cluster-admin is granted by break-glass only"}},
+		{"allowed", rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"}, Decision{Allowed: true, Annotations: map[string]string{DecidedByAnnotation: "role-denylist"}}},
+		{"role of the same name", rbacv1.RoleRef{Kind: "Role", Name: "cluster-admin"}, Decision{Allowed: true, Annotations: map[string]string{DecidedByAnnotation: "role-denylist"}}},
+	}
+	for _, test := range tests {
+		decision, err := client.Decide(context.TODO(), Request{
+			Subject:         rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "team-a"},
+			RoleRef:         test.roleRef,
+			Namespace:       "team-a",
+			GroupPermission: Owner{Namespace: "team-a", Name: "team-a"},
+		})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.label, err)
+			continue
+		}
+		if !reflect.DeepEqual(decision, test.expected) {
+			t.Errorf("%s: got %+v, want %+v", test.label, decision, test.expected)
+		}
+	}
+}
+
+func TestClientFailures(t *testing.T) {
+	failing := httptest.NewServer(Handler(DeciderFunc(func(context.Context, Request) (Decision, error) {
+		return Decision{}, context.DeadlineExceeded
+	})))
+	defer failing.Close()
+	invalid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"allowed": "yes"}`))
+	}))
+	defer invalid.Close()
+
+	for label, endpoint := range map[string]string{"decider error": failing.URL, "invalid decision": invalid.URL} {
+		if _, err := (&Client{Endpoint: endpoint}).Decide(context.TODO(), Request{}); err == nil {
+			t.Errorf("%s: expected an error", label)
+		}
+	}
+}
+
+func TestHandlerMethod(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Handler(RoleDenylist{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("got status %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
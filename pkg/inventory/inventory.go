@@ -0,0 +1,263 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inventory periodically reports the RBAC objects managed by the operator in a ConfigMap,
+// for compliance scanners.
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("inventory")
+
+// Keys of the inventory ConfigMap
+const (
+	// inventoryKey holds the Inventory as JSON
+	inventoryKey = "inventory.json"
+	// revisionKey holds the revision of the Inventory, incremented every time it changes
+	revisionKey = "revision"
+	// generatedAtKey holds the time the Inventory was last generated, as RFC 3339
+	generatedAtKey = "generated_at"
+)
+
+// FormatVersion is the version of the format of the Inventory, changed on incompatible changes
+const FormatVersion = "v1"
+
+// Inventory is the desired state of the bindings managed by the operator, by Group
+type Inventory struct {
+	Version string  `json:"version"`
+	Groups  []Group `json:"groups"`
+	// GroupPermissions documented by a description or tags, for auditors
+	GroupPermissions []GroupPermission `json:"groupPermissions,omitempty"`
+}
+
+// Group lists the roles granted to a Group
+type Group struct {
+	Name string `json:"name"`
+	// GroupPermissions granting the roles, as namespace/name
+	GroupPermissions []string `json:"groupPermissions"`
+	// ClusterRoles granted cluster wide
+	ClusterRoles []string `json:"clusterRoles,omitempty"`
+	// Roles granted in Namespaces
+	Roles []Role `json:"roles,omitempty"`
+}
+
+// GroupPermission documents what a GroupPermission grants its permissions for
+type GroupPermission struct {
+	// Name of the GroupPermission, as namespace/name
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// Role lists the Namespaces a ClusterRole or Role is granted in
+type Role struct {
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Namespaces []string `json:"namespaces"`
+}
+
+// Build returns the Inventory of the bindings of groupPermissions in the Namespaces of namespaceList.
+// It is sorted, so the same state always results in the same Inventory.
+func Build(groupPermissions []managedv1alpha1.GroupPermission, namespaceList *corev1.NamespaceList) *Inventory {
+	type roleKey struct{ kind, name string }
+	type groupEntry struct {
+		groupPermissions map[string]bool
+		clusterRoles     map[string]bool
+		roles            map[roleKey]map[string]bool
+	}
+
+	entries := map[string]*groupEntry{}
+	var documented []GroupPermission
+	for _, groupPermission := range groupPermissions {
+		// the permissions of a groupNameSelector are granted by the GroupPermissions created for each Group
+		if groupPermission.Spec.GroupNameSelector != nil {
+			continue
+		}
+		entry, ok := entries[groupPermission.Spec.GroupName]
+		if !ok {
+			entry = &groupEntry{groupPermissions: map[string]bool{}, clusterRoles: map[string]bool{}, roles: map[roleKey]map[string]bool{}}
+			entries[groupPermission.Spec.GroupName] = entry
+		}
+		entry.groupPermissions[groupPermission.Namespace+"/"+groupPermission.Name] = true
+		if groupPermission.Spec.Description != "" || len(groupPermission.Spec.Tags) > 0 {
+			documented = append(documented, GroupPermission{
+				Name:        groupPermission.Namespace + "/" + groupPermission.Name,
+				Description: groupPermission.Spec.Description,
+				Tags:        groupPermission.Spec.Tags,
+			})
+		}
+
+		for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+			entry.clusterRoles[clusterRoleName] = true
+		}
+		for _, permission := range groupPermission.Spec.Permissions {
+			key := roleKey{kind: "ClusterRole", name: permission.ClusterRoleName}
+			if permission.RoleName != "" {
+				key = roleKey{kind: "Role", name: permission.RoleName}
+			}
+			matcher := utility.PermissionNamespaceMatcher(permission)
+			for _, namespace := range namespaceList.Items {
+				if utility.IsNamespaceRequesterExcluded(permission.ExcludedNamespaceRequesters, namespace.Annotations) ||
+					!matcher.Matches(namespace.Name) {
+					continue
+				}
+				if entry.roles[key] == nil {
+					entry.roles[key] = map[string]bool{}
+				}
+				entry.roles[key][namespace.Name] = true
+			}
+		}
+	}
+
+	inventory := &Inventory{Version: FormatVersion, Groups: []Group{}}
+	for name, entry := range entries {
+		group := Group{
+			Name:             name,
+			GroupPermissions: sortedKeys(entry.groupPermissions),
+			ClusterRoles:     sortedKeys(entry.clusterRoles),
+		}
+		for key, namespaces := range entry.roles {
+			group.Roles = append(group.Roles, Role{Kind: key.kind, Name: key.name, Namespaces: sortedKeys(namespaces)})
+		}
+		sort.Slice(group.Roles, func(i, j int) bool {
+			if group.Roles[i].Kind != group.Roles[j].Kind {
+				return group.Roles[i].Kind < group.Roles[j].Kind
+			}
+			return group.Roles[i].Name < group.Roles[j].Name
+		})
+		inventory.Groups = append(inventory.Groups, group)
+	}
+	sort.Slice(inventory.Groups, func(i, j int) bool { return inventory.Groups[i].Name < inventory.Groups[j].Name })
+	sort.Slice(documented, func(i, j int) bool { return documented[i].Name < documented[j].Name })
+	inventory.GroupPermissions = documented
+	return inventory
+}
+
+// sortedKeys returns the keys of set in sorted order, nil when set is empty
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update,namespace=openshift-rbac-permissions-operator
+
+// Write records inventory in the inventory ConfigMap of namespace, generated at now. The revision is
+// incremented only when the inventory changed.
+func Write(ctx context.Context, c client.Client, namespace string, inventory *Inventory, now time.Time) error {
+	content, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: operatorconfig.InventoryConfigMapName}, configMap)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		return c.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      operatorconfig.InventoryConfigMapName,
+			},
+			Data: map[string]string{
+				inventoryKey:   string(content),
+				revisionKey:    "1",
+				generatedAtKey: now.UTC().Format(time.RFC3339),
+			},
+		})
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	if configMap.Data[inventoryKey] != string(content) {
+		// an invalid revision was set by hand, restart the count
+		revision, _ := strconv.Atoi(configMap.Data[revisionKey])
+		configMap.Data[inventoryKey] = string(content)
+		configMap.Data[revisionKey] = strconv.Itoa(revision + 1)
+	}
+	configMap.Data[generatedAtKey] = now.UTC().Format(time.RFC3339)
+	return c.Update(ctx, configMap)
+}
+
+// Reporter writes the Inventory of the GroupPermissions of its namespace every interval
+type Reporter struct {
+	client    client.Client
+	namespace string
+	interval  time.Duration
+}
+
+// blank assignment to verify that Reporter implements manager.Runnable
+var _ manager.Runnable = &Reporter{}
+
+// NewReporter returns a Reporter writing the Inventory of the GroupPermissions of namespace every interval
+func NewReporter(c client.Client, namespace string, interval time.Duration) *Reporter {
+	return &Reporter{client: c, namespace: namespace, interval: interval}
+}
+
+// Start implements manager.Runnable, it reports until stop is closed
+func (r *Reporter) Start(stop <-chan struct{}) error {
+	wait.Until(func() {
+		if err := r.Report(context.TODO()); err != nil {
+			log.Error(err, "Failed to report inventory")
+		}
+	}, r.interval, stop)
+	return nil
+}
+
+// +kubebuilder:rbac:groups=managed.openshift.io,resources=grouppermissions,verbs=list,namespace=openshift-rbac-permissions-operator
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=list
+
+// Report builds and writes the Inventory once
+func (r *Reporter) Report(ctx context.Context) error {
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	if err := r.client.List(ctx, &client.ListOptions{Namespace: r.namespace}, groupPermissionList); err != nil {
+		return err
+	}
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.client.List(ctx, &client.ListOptions{}, namespaceList); err != nil {
+		return err
+	}
+
+	inventory := Build(groupPermissionList.Items, namespaceList)
+	if err := Write(ctx, r.client, r.namespace, inventory, time.Now()); err != nil {
+		return err
+	}
+	log.Info("Reported inventory", "Groups", len(inventory.Groups))
+	return nil
+}
@@ -0,0 +1,158 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const namespace = "rbac-permissions-operator"
+
+func mockGroupPermission(name, groupName string, clusterPermissions []string, permissions []v1alpha1.Permission) *v1alpha1.GroupPermission {
+	return &v1alpha1.GroupPermission{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1alpha1.GroupPermissionSpec{
+			GroupName:          groupName,
+			ClusterPermissions: clusterPermissions,
+			Permissions:        permissions,
+		},
+	}
+}
+
+func mockNamespaceList(names ...string) *corev1.NamespaceList {
+	namespaceList := &corev1.NamespaceList{}
+	for _, name := range names {
+		namespaceList.Items = append(namespaceList.Items, corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	return namespaceList
+}
+
+// TestBuild tests the Build function
+// given: GroupPermissions of two Groups, one Group granted by two GroupPermissions, one of them documented
+// expected: the roles of each Group merged and sorted, with the Namespaces they are granted in, and the
+// documented GroupPermission
+func TestBuild(t *testing.T) {
+	documented := mockGroupPermission("team-deployers", "team-a", []string{"view"}, []v1alpha1.Permission{
+		{RoleName: "deployer", NamespacesAllowedRegex: "^team-a-prod$", AllowFirst: true},
+	})
+	documented.Spec.Description = "deployments to production"
+	documented.Spec.Tags = []string{"pci"}
+	groupPermissions := []v1alpha1.GroupPermission{
+		*mockGroupPermission("sre", "sre", []string{"view", "cluster-reader"}, nil),
+		*mockGroupPermission("team-admins", "team-a", nil, []v1alpha1.Permission{
+			{ClusterRoleName: "admin", NamespacesAllowedRegex: "^team-a-.*", AllowFirst: true},
+		}),
+		*documented,
+	}
+
+	inventory := Build(groupPermissions, mockNamespaceList("default", "team-a-prod", "team-a-dev"))
+	expected := &Inventory{
+		Version: FormatVersion,
+		Groups: []Group{
+			{
+				Name:             "sre",
+				GroupPermissions: []string{namespace + "/sre"},
+				ClusterRoles:     []string{"cluster-reader", "view"},
+			},
+			{
+				Name:             "team-a",
+				GroupPermissions: []string{namespace + "/team-admins", namespace + "/team-deployers"},
+				ClusterRoles:     []string{"view"},
+				Roles: []Role{
+					{Kind: "ClusterRole", Name: "admin", Namespaces: []string{"team-a-dev", "team-a-prod"}},
+					{Kind: "Role", Name: "deployer", Namespaces: []string{"team-a-prod"}},
+				},
+			},
+		},
+		GroupPermissions: []GroupPermission{
+			{Name: namespace + "/team-deployers", Description: "deployments to production", Tags: []string{"pci"}},
+		},
+	}
+	if !reflect.DeepEqual(inventory, expected) {
+		t.Errorf("got %+v, want %+v", inventory, expected)
+	}
+}
+
+// getInventoryConfigMap returns the inventory ConfigMap
+func getInventoryConfigMap(t *testing.T, c client.Client) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: operatorconfig.InventoryConfigMapName}, configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return configMap
+}
+
+// TestWrite tests the Write function
+// given: an inventory written, written again unchanged, then changed
+// expected: the revision is incremented only when the inventory changed, the timestamp on every write
+func TestWrite(t *testing.T) {
+	c := fake.NewFakeClient()
+	now := time.Date(2026, time.October, 15, 9, 0, 0, 0, time.UTC)
+	inventory := Build([]v1alpha1.GroupPermission{*mockGroupPermission("sre", "sre", []string{"view"}, nil)}, mockNamespaceList())
+
+	tests := []struct {
+		label     string
+		inventory *Inventory
+		revision  string
+	}{
+		{"created", inventory, "1"},
+		{"unchanged", inventory, "1"},
+		{"changed", Build(nil, mockNamespaceList()), "2"},
+	}
+	for _, test := range tests {
+		now = now.Add(time.Hour)
+		if err := Write(context.TODO(), c, namespace, test.inventory, now); err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.label, err)
+		}
+		configMap := getInventoryConfigMap(t, c)
+		if configMap.Data[revisionKey] != test.revision {
+			t.Errorf("%s: got revision %s, want %s", test.label, configMap.Data[revisionKey], test.revision)
+		}
+		if configMap.Data[generatedAtKey] != now.Format(time.RFC3339) {
+			t.Errorf("%s: got generated at %s, want %s", test.label, configMap.Data[generatedAtKey], now.Format(time.RFC3339))
+		}
+		written := &Inventory{}
+		if err := json.Unmarshal([]byte(configMap.Data[inventoryKey]), written); err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.label, err)
+		}
+		if !reflect.DeepEqual(written, test.inventory) {
+			t.Errorf("%s: got %+v, want %+v", test.label, written, test.inventory)
+		}
+	}
+}
+
+// TestReport tests the Report function
+// given: a GroupPermission and Namespaces in the cluster
+// expected: the inventory ConfigMap is written with the Group of the GroupPermission
+func TestReport(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	c := fake.NewFakeClient(mockGroupPermission("sre", "sre", []string{"view"}, nil), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	if err := NewReporter(c, namespace, time.Hour).Report(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	written := &Inventory{}
+	if err := json.Unmarshal([]byte(getInventoryConfigMap(t, c).Data[inventoryKey]), written); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(written.Groups) != 1 || written.Groups[0].Name != "sre" {
+		t.Errorf("expected the inventory of the sre Group, got %+v", written)
+	}
+}
@@ -2,6 +2,7 @@ package localmetrics
 
 import (
 	"fmt"
+	"time"
 
 	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
@@ -42,18 +43,407 @@ var (
 		"stage",
 	})
 
+	// RBACOrphanedBindingsCollected for bindings whose GroupPermission no longer exists
+	RBACOrphanedBindingsCollected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "rbac_permissions_operator_orphaned_bindings_collected_total",
+		Help: "Managed bindings found without an owning GroupPermission at startup",
+	}, []string{
+		"kind",
+		"dry_run",
+	})
+
+	// RBACReconcilesCoalesced for reconciles triggered by Namespace events that joined a pending reconcile
+	RBACReconcilesCoalesced = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "rbac_permissions_operator_reconciles_coalesced_total",
+		Help: "Reconciles of GroupPermissions triggered by Namespace events coalesced into a pending reconcile",
+	})
+
+	// RBACShardGroupPermissions for the GroupPermissions reconciled by each shard of the operator
+	RBACShardGroupPermissions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rbac_permissions_operator_shard_grouppermissions",
+		Help: "GroupPermissions reconciled by the operator instance of a shard",
+	}, []string{
+		"shard",
+	})
+
+	// RBACGroupPermissionApplication for whether the bindings of each GroupPermission are all applied,
+	// partially applied or all failed
+	RBACGroupPermissionApplication = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rbac_permissions_operator_grouppermission_application",
+		Help: "Set to 1 for the state of the bindings of a GroupPermission: applied, partial or failed",
+	}, []string{
+		"group_permission_name",
+		"state",
+	})
+
+	// RBACReadOnlySkippedWrites for the writes the operator would have made outside of read-only mode
+	RBACReadOnlySkippedWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "rbac_permissions_operator_read_only_skipped_writes_total",
+		Help: "Writes to RBAC objects and Groups skipped in read-only mode",
+	}, []string{
+		"kind",
+		"verb",
+	})
+
+	// RBACWebhookDuration for the latency of the admission webhooks, which every apply of their objects waits for
+	RBACWebhookDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rbac_permissions_operator_webhook_duration_seconds",
+		Help:    "Duration of the admission reviews of the webhooks of the operator",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 12),
+	}, []string{
+		"webhook",
+		"allowed",
+	})
+
+	// RBACWebhookRejections for the admission reviews rejected by the webhooks, by reason
+	RBACWebhookRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "rbac_permissions_operator_webhook_rejections_total",
+		Help: "Admission reviews rejected by the webhooks of the operator",
+	}, []string{
+		"webhook",
+		"reason",
+	})
+
+	// RBACBuildInfo for the version of the running operator, so the rollout of a version can be tracked
+	RBACBuildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rbac_permissions_operator_build_info",
+		Help: "Set to 1 for the version and commit of the running operator",
+	}, []string{
+		"version",
+		"commit",
+	})
+
+	// RBACFeatureGates for the optional features of the operator turned on by its flags
+	RBACFeatureGates = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rbac_permissions_operator_feature_gate",
+		Help: "Set to 1 for the enabled features of the operator and 0 for the disabled ones",
+	}, []string{
+		"feature",
+	})
+
+	// RBACConfigValues for the values of the operator configuration, so its drift across clusters can be tracked
+	RBACConfigValues = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rbac_permissions_operator_config_value",
+		Help: "Values of the keys of the operator configuration, lists by their number of items",
+	}, []string{
+		"key",
+	})
+
+	// RBACStuckGroupPermissions for the GroupPermissions not Active within the stuck deadline after their spec changed
+	RBACStuckGroupPermissions = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "rbac_permissions_stuck_crs",
+		Help: "Number of GroupPermissions not Active within the stuck deadline after their spec changed",
+	})
+
+	// RBACStaleGrants for the roles bound by GroupPermissions their Group did not use within the stale grant age
+	RBACStaleGrants = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "rbac_permissions_stale_grants",
+		Help: "Number of roles bound by GroupPermissions their Group did not use within the stale grant age",
+	})
+
+	// RBACAPIRequests for the requests issued by the reconciles of GroupPermissions, to quantify the load of
+	// the operator on the apiserver
+	RBACAPIRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "rbac_permissions_operator_api_requests_total",
+		Help: "Requests issued by the reconciles of GroupPermissions, by verb and by whether the cache or the apiserver served them",
+	}, []string{
+		"verb",
+		"source",
+	})
+
+	// RBACReconcileAPIRequests for the requests to the apiserver issued by each reconcile of a GroupPermission
+	RBACReconcileAPIRequests = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "rbac_permissions_operator_reconcile_api_requests",
+		Help:    "Requests to the apiserver issued by a single reconcile of a GroupPermission",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	})
+
+	// RBACGroupCacheLookups for the lookups of the existence of Groups in the cache of the operator
+	RBACGroupCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "rbac_permissions_operator_group_cache_lookups_total",
+		Help: "Lookups of the existence of Groups in the cache of the operator, by whether the cache held it",
+	}, []string{
+		"result",
+	})
+
+	// RBACBindingPropagation for the time a Group waits for its access to a Namespace, to monitor an SLO on it
+	RBACBindingPropagation = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rbac_permissions_operator_binding_propagation_seconds",
+		Help:    "Time from the creation of a Namespace, or of the GroupPermission when it is newer, to the creation of the RoleBinding granting the Group access to it",
+		Buckets: []float64{0.5, 1, 2, 5, 10, 15, 30, 60, 120, 300, 600, 1800},
+	}, []string{
+		"trigger",
+	})
+
+	// RBACTerminatingNamespaceBindingsSkipped for the RoleBindings not created in allowed Namespaces being deleted
+	RBACTerminatingNamespaceBindingsSkipped = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "rbac_permissions_operator_terminating_namespace_bindings_skipped_total",
+		Help: "RoleBindings of allowed Namespaces being deleted that were skipped rather than created",
+	})
+
+	// RBACReconcilePanics for the reconciles of GroupPermissions that panicked
+	RBACReconcilePanics = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "rbac_permissions_operator_reconcile_panics_total",
+		Help: "Reconciles of GroupPermissions that panicked and quarantined their GroupPermission",
+	})
+
+	// RBACQuarantinedGroupPermissions for the GroupPermissions skipped since their reconcile panicked
+	RBACQuarantinedGroupPermissions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "rbac_permissions_operator_quarantined_grouppermission",
+		Help: "Set to 1 for a GroupPermission quarantined until its spec changes, since its reconcile panicked",
+	}, []string{
+		"namespace",
+		"group_permission_name",
+	})
+
+	// RBACGrantDecisions for the decisions of the grant decision service on the bindings, by result
+	RBACGrantDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "rbac_permissions_operator_grant_decisions_total",
+		Help: "Bindings sent to the grant decision service, by whether they were allowed, denied or the service failed",
+	}, []string{
+		"result",
+	})
+
+	// RBACGrantDecisionDuration for the latency of the grant decision service, which every binding created waits for
+	RBACGrantDecisionDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "rbac_permissions_operator_grant_decision_duration_seconds",
+		Help:    "Duration of the decisions of the grant decision service",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+	})
+
 	// MetricsList all metrics exported by this package
 	MetricsList = []prometheus.Collector{
 		RBACClusterwidePermissions,
 		RBACNamespacePermissions,
+		RBACOrphanedBindingsCollected,
+		RBACReconcilesCoalesced,
+		RBACShardGroupPermissions,
+		RBACGroupPermissionApplication,
+		RBACReadOnlySkippedWrites,
+		RBACWebhookDuration,
+		RBACWebhookRejections,
+		RBACBuildInfo,
+		RBACFeatureGates,
+		RBACConfigValues,
+		RBACStuckGroupPermissions,
+		RBACStaleGrants,
+		RBACAPIRequests,
+		RBACReconcileAPIRequests,
+		RBACGroupCacheLookups,
+		RBACBindingPropagation,
+		RBACTerminatingNamespaceBindingsSkipped,
+		RBACReconcilePanics,
+		RBACQuarantinedGroupPermissions,
+		RBACGrantDecisions,
+		RBACGrantDecisionDuration,
 	}
 )
 
+// IncOrphanedBindingsCollected - Helper function to count an orphaned binding
+// of the given kind, deleted or only found when dryRun is set
+func IncOrphanedBindingsCollected(kind string, dryRun bool) {
+	RBACOrphanedBindingsCollected.With(prometheus.Labels{
+		"kind":    kind,
+		"dry_run": allowFirstToString(dryRun),
+	}).Inc()
+}
+
+// IncReconcilesCoalesced - Helper function to count a reconcile coalesced
+// into a pending reconcile of the same GroupPermission
+func IncReconcilesCoalesced() {
+	RBACReconcilesCoalesced.Inc()
+}
+
+// IncReadOnlySkippedWrites - Helper function to count a write of verb
+// to an object of the given kind skipped in read-only mode
+func IncReadOnlySkippedWrites(kind, verb string) {
+	RBACReadOnlySkippedWrites.With(prometheus.Labels{
+		"kind": kind,
+		"verb": verb,
+	}).Inc()
+}
+
+// ObserveWebhookReview - Helper function to record the duration of an
+// admission review of webhook, and its reason when it was rejected
+func ObserveWebhookReview(webhook string, allowed bool, reason string, duration time.Duration) {
+	RBACWebhookDuration.With(prometheus.Labels{
+		"webhook": webhook,
+		"allowed": allowFirstToString(allowed),
+	}).Observe(duration.Seconds())
+	if !allowed {
+		RBACWebhookRejections.With(prometheus.Labels{
+			"webhook": webhook,
+			"reason":  reason,
+		}).Inc()
+	}
+}
+
+// SetBuildInfo - Helper function to set the version and commit of the
+// running operator
+func SetBuildInfo(version, commit string) {
+	RBACBuildInfo.Reset()
+	RBACBuildInfo.With(prometheus.Labels{
+		"version": version,
+		"commit":  commit,
+	}).Set(1.0)
+}
+
+// SetFeatureGate - Helper function to set whether feature is enabled
+func SetFeatureGate(feature string, enabled bool) {
+	value := 0.0
+	if enabled {
+		value = 1.0
+	}
+	RBACFeatureGates.With(prometheus.Labels{
+		"feature": feature,
+	}).Set(value)
+}
+
+// SetConfigValues - Helper function to set the values of the operator
+// configuration by key, removing the keys no longer set
+func SetConfigValues(values map[string]float64) {
+	RBACConfigValues.Reset()
+	for key, value := range values {
+		RBACConfigValues.With(prometheus.Labels{
+			"key": key,
+		}).Set(value)
+	}
+}
+
+// SetStuckGroupPermissions - Helper function to set the number of
+// GroupPermissions flagged as stuck
+func SetStuckGroupPermissions(count int) {
+	RBACStuckGroupPermissions.Set(float64(count))
+}
+
+// SetStaleGrants - Helper function to set the number of roles flagged
+// as stale grants
+func SetStaleGrants(count int) {
+	RBACStaleGrants.Set(float64(count))
+}
+
+// IncGroupCacheLookup - Helper function to count a lookup of the existence
+// of a Group in the cache, by whether it was a hit
+func IncGroupCacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	RBACGroupCacheLookups.With(prometheus.Labels{"result": result}).Inc()
+}
+
+// AddTerminatingNamespaceBindingsSkipped - Helper function to count the
+// RoleBindings skipped in Namespaces being deleted
+func AddTerminatingNamespaceBindingsSkipped(count int) {
+	RBACTerminatingNamespaceBindingsSkipped.Add(float64(count))
+}
+
+// IncReconcilePanics - Helper function to count a panicked reconcile
+func IncReconcilePanics() {
+	RBACReconcilePanics.Inc()
+}
+
+// SetGroupPermissionQuarantined - Helper function to set whether a
+// GroupPermission is quarantined, removing its metric when it is not
+func SetGroupPermissionQuarantined(gp *managedv1alpha1.GroupPermission, quarantined bool) {
+	if !quarantined {
+		RBACQuarantinedGroupPermissions.DeleteLabelValues(gp.ObjectMeta.GetNamespace(), gp.ObjectMeta.GetName())
+		return
+	}
+	RBACQuarantinedGroupPermissions.With(prometheus.Labels{
+		"namespace":             gp.ObjectMeta.GetNamespace(),
+		"group_permission_name": gp.ObjectMeta.GetName(),
+	}).Set(1.0)
+}
+
+// Results of the decisions of the grant decision service
+const (
+	GrantDecisionAllowed = "allowed"
+	GrantDecisionDenied  = "denied"
+	GrantDecisionFailed  = "failed"
+)
+
+// ObserveGrantDecision - Helper function to record the duration and result
+// of a decision of the grant decision service
+func ObserveGrantDecision(result string, duration time.Duration) {
+	RBACGrantDecisions.With(prometheus.Labels{"result": result}).Inc()
+	RBACGrantDecisionDuration.Observe(duration.Seconds())
+}
+
+// Sources of the requests issued by the reconciles
+const (
+	SourceCache     = "cache"
+	SourceAPIServer = "apiserver"
+)
+
+// Triggers of the creation of the RoleBindings whose propagation is observed
+const (
+	TriggerNamespace       = "namespace"
+	TriggerGroupPermission = "grouppermission"
+)
+
+// ObserveBindingPropagation - Helper function to record the time from
+// trigger to the creation of a RoleBinding
+func ObserveBindingPropagation(trigger string, latency time.Duration) {
+	RBACBindingPropagation.With(prometheus.Labels{"trigger": trigger}).Observe(latency.Seconds())
+}
+
+// ObserveReconcileAPIRequests - Helper function to record the requests
+// issued by a reconcile, by verb and source
+func ObserveReconcileAPIRequests(requests map[string]map[string]int) {
+	total := 0
+	for source, verbs := range requests {
+		for verb, count := range verbs {
+			RBACAPIRequests.With(prometheus.Labels{
+				"verb":   verb,
+				"source": source,
+			}).Add(float64(count))
+			if source == SourceAPIServer {
+				total += count
+			}
+		}
+	}
+	RBACReconcileAPIRequests.Observe(float64(total))
+}
+
+// SetShardGroupPermissions - Helper function to set the number of
+// GroupPermissions reconciled by shard, the label selector and Namespaces of the instance
+func SetShardGroupPermissions(shard string, count int) {
+	RBACShardGroupPermissions.With(prometheus.Labels{
+		"shard": shard,
+	}).Set(float64(count))
+}
+
+// Application states of the bindings of a GroupPermission
+const (
+	ApplicationApplied = "applied"
+	ApplicationPartial = "partial"
+	ApplicationFailed  = "failed"
+)
+
+// SetGroupPermissionApplication - Helper function to set the application
+// state of the bindings of a GroupPermission, removing its other states
+func SetGroupPermissionApplication(gp *managedv1alpha1.GroupPermission, state string) {
+	for _, other := range []string{ApplicationApplied, ApplicationPartial, ApplicationFailed} {
+		if other != state {
+			RBACGroupPermissionApplication.DeleteLabelValues(gp.ObjectMeta.GetName(), other)
+		}
+	}
+	RBACGroupPermissionApplication.With(prometheus.Labels{
+		"group_permission_name": gp.ObjectMeta.GetName(),
+		"state":                 state,
+	}).Set(1.0)
+}
+
 // DeletePrometheusMetric - Helper function to delete both clusterwide and
 // namespace permission metrics
 func DeletePrometheusMetric(gp *managedv1alpha1.GroupPermission) {
 	deleteRBACClusterPermissionMetric(gp)
 	deleteRBACNamespacePermissionMetric(gp)
+	for _, state := range []string{ApplicationApplied, ApplicationPartial, ApplicationFailed} {
+		RBACGroupPermissionApplication.DeleteLabelValues(gp.ObjectMeta.GetName(), state)
+	}
+	SetGroupPermissionQuarantined(gp, false)
 }
 
 // AddPrometheusMetric - Helper function to add both clusterwide and namespace
@@ -103,11 +493,11 @@ func addRBACNamespacePermissionMetric(gp *managedv1alpha1.GroupPermission) {
 		RBACNamespacePermissions.With(prometheus.Labels{
 			"group_name":            gp.Spec.GroupName,
 			"group_permission_name": gp.ObjectMeta.GetName(),
-			"cluster_role_name":     permission.ClusterRoleName,
+			"permission_name":       permission.ClusterRoleName,
 			"namespace_allow":       permission.NamespacesAllowedRegex,
 			"namespace_deny":        permission.NamespacesDeniedRegex,
 			"allow_first":           allowFirstToString(permission.AllowFirst),
-			"state":                 "1",
+			"stage":                 "1",
 		}).Set(1.0)
 	}
 }
@@ -2,6 +2,11 @@ package localmetrics
 
 import (
 	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 )
 
 func TestBoolToString(t *testing.T) {
@@ -19,3 +24,71 @@ func TestBoolToString(t *testing.T) {
 		}
 	}
 }
+
+func TestSetBuildInfo(t *testing.T) {
+	SetBuildInfo("0.1.0", "abcdef01")
+	SetBuildInfo("0.1.1", "12345678")
+	if value := testutil.ToFloat64(RBACBuildInfo.WithLabelValues("0.1.1", "12345678")); value != 1 {
+		t.Errorf("Expected the build info of the running version to be 1, but got %v\n", value)
+	}
+	if RBACBuildInfo.DeleteLabelValues("0.1.0", "abcdef01") {
+		t.Errorf("Expected the build info of the previous version to be removed\n")
+	}
+}
+
+func TestSetConfigValues(t *testing.T) {
+	SetConfigValues(map[string]float64{"jitter_factor": 0.1, "sensitive_roles": 2})
+	SetConfigValues(map[string]float64{"jitter_factor": 0.2})
+	if value := testutil.ToFloat64(RBACConfigValues.WithLabelValues("jitter_factor")); value != 0.2 {
+		t.Errorf("Expected a jitter_factor of 0.2, but got %v\n", value)
+	}
+	if RBACConfigValues.DeleteLabelValues("sensitive_roles") {
+		t.Errorf("Expected the keys no longer set to be removed\n")
+	}
+}
+
+func TestObserveReconcileAPIRequests(t *testing.T) {
+	before := testutil.ToFloat64(RBACAPIRequests.WithLabelValues("get", SourceCache))
+	ObserveReconcileAPIRequests(map[string]map[string]int{
+		SourceCache:     {"get": 3},
+		SourceAPIServer: {"get": 1, "create": 2},
+	})
+	if value := testutil.ToFloat64(RBACAPIRequests.WithLabelValues("get", SourceCache)) - before; value != 3 {
+		t.Errorf("Expected 3 gets served by the cache, but got %v\n", value)
+	}
+	if value := testutil.ToFloat64(RBACAPIRequests.WithLabelValues("create", SourceAPIServer)); value < 2 {
+		t.Errorf("Expected at least 2 creates sent to the apiserver, but got %v\n", value)
+	}
+}
+
+func TestIncGroupCacheLookup(t *testing.T) {
+	hits := testutil.ToFloat64(RBACGroupCacheLookups.WithLabelValues("hit"))
+	misses := testutil.ToFloat64(RBACGroupCacheLookups.WithLabelValues("miss"))
+	IncGroupCacheLookup(true)
+	IncGroupCacheLookup(false)
+	IncGroupCacheLookup(true)
+	if value := testutil.ToFloat64(RBACGroupCacheLookups.WithLabelValues("hit")) - hits; value != 2 {
+		t.Errorf("Expected 2 hits, but got %v\n", value)
+	}
+	if value := testutil.ToFloat64(RBACGroupCacheLookups.WithLabelValues("miss")) - misses; value != 1 {
+		t.Errorf("Expected 1 miss, but got %v\n", value)
+	}
+}
+
+func TestObserveBindingPropagation(t *testing.T) {
+	metric := &dto.Metric{}
+	ObserveBindingPropagation(TriggerNamespace, 3*time.Second)
+	ObserveBindingPropagation(TriggerNamespace, 45*time.Second)
+	if err := RBACBindingPropagation.WithLabelValues(TriggerNamespace).(prometheus.Histogram).Write(metric); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	histogram := metric.GetHistogram()
+	if histogram.GetSampleCount() != 2 || histogram.GetSampleSum() != 48 {
+		t.Errorf("Expected 2 samples summing to 48s, but got %d summing to %v\n", histogram.GetSampleCount(), histogram.GetSampleSum())
+	}
+	for _, bucket := range histogram.GetBucket() {
+		if bucket.GetUpperBound() == 30 && bucket.GetCumulativeCount() != 1 {
+			t.Errorf("Expected 1 sample within 30s, but got %d\n", bucket.GetCumulativeCount())
+		}
+	}
+}
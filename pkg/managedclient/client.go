@@ -0,0 +1,100 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package managedclient is a typed client of the managed.openshift.io API group, for other operators
+// to create and read GroupPermissions and the related objects without depending on the controllers.
+// Its interfaces are stable, methods are only ever added.
+package managedclient
+
+import (
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Interface reads and writes the objects of the managed.openshift.io API group
+type Interface interface {
+	GroupPermissions(namespace string) GroupPermissionInterface
+	GroupSyncs(namespace string) GroupSyncInterface
+	PermissionRequests(namespace string) PermissionRequestInterface
+	Elevations(namespace string) ElevationInterface
+}
+
+// managedClient implements Interface on top of a controller-runtime client
+type managedClient struct {
+	client client.Client
+}
+
+// blank assignment to verify that managedClient implements Interface
+var _ Interface = &managedClient{}
+
+// New returns an Interface calling the apiserver of cfg
+func New(cfg *rest.Config) (Interface, error) {
+	s, err := NewScheme()
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: s})
+	if err != nil {
+		return nil, err
+	}
+	return NewForClient(c), nil
+}
+
+// NewForClient returns an Interface calling c, whose scheme must hold the managed.openshift.io API group.
+// Use it with the client of a controller-runtime manager, or with a fake client in tests.
+func NewForClient(c client.Client) Interface {
+	return &managedClient{client: c}
+}
+
+// NewScheme returns a scheme holding the Kubernetes and managed.openshift.io API groups
+func NewScheme() (*runtime.Scheme, error) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		return nil, err
+	}
+	if err := apis.AddToScheme(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GroupPermissions returns the GroupPermissions of namespace
+func (c *managedClient) GroupPermissions(namespace string) GroupPermissionInterface {
+	return &groupPermissions{client: c.client, namespace: namespace}
+}
+
+// GroupSyncs returns the GroupSyncs of namespace
+func (c *managedClient) GroupSyncs(namespace string) GroupSyncInterface {
+	return &groupSyncs{client: c.client, namespace: namespace}
+}
+
+// PermissionRequests returns the PermissionRequests of namespace
+func (c *managedClient) PermissionRequests(namespace string) PermissionRequestInterface {
+	return &permissionRequests{client: c.client, namespace: namespace}
+}
+
+// Elevations returns the Elevations of namespace
+func (c *managedClient) Elevations(namespace string) ElevationInterface {
+	return &elevations{client: c.client, namespace: namespace}
+}
+
+// listOptions returns the options listing the objects of namespace matching selector, every object when nil
+func listOptions(namespace string, selector labels.Selector) *client.ListOptions {
+	return &client.ListOptions{Namespace: namespace, LabelSelector: selector}
+}
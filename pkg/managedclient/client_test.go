@@ -0,0 +1,96 @@
+package managedclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const namespace = "rbac-permissions-operator"
+
+// TestGroupPermissions tests the GroupPermissionInterface
+// given: GroupPermissions created, updated and deleted through the typed client
+// expected: each call is applied to the GroupPermissions of the namespace only
+func TestGroupPermissions(t *testing.T) {
+	s, err := NewScheme()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other := &v1alpha1.GroupPermission{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+	c := NewForClient(fake.NewFakeClientWithScheme(s, other))
+	groupPermissions := c.GroupPermissions(namespace)
+	ctx := context.TODO()
+
+	groupPermission := &v1alpha1.GroupPermission{
+		ObjectMeta: metav1.ObjectMeta{Name: "sre"},
+		Spec:       v1alpha1.GroupPermissionSpec{GroupName: "sre", ClusterPermissions: []string{"view"}},
+	}
+	if err := groupPermissions.Create(ctx, groupPermission); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := groupPermissions.Get(ctx, "sre")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Namespace != namespace || got.Spec.GroupName != "sre" {
+		t.Errorf("expected the GroupPermission created in %s, got %v", namespace, got)
+	}
+
+	got.Spec.ClusterPermissions = append(got.Spec.ClusterPermissions, "cluster-reader")
+	if err := groupPermissions.Update(ctx, got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got.Status.State = "Applied"
+	if err := groupPermissions.UpdateStatus(ctx, got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ = groupPermissions.Get(ctx, "sre"); len(got.Spec.ClusterPermissions) != 2 || got.Status.State != "Applied" {
+		t.Errorf("expected the spec and status updated, got %v", got)
+	}
+
+	list, err := groupPermissions.List(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "sre" {
+		t.Errorf("expected only the GroupPermission of %s, got %v", namespace, list.Items)
+	}
+
+	if err := groupPermissions.Delete(ctx, "sre"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := groupPermissions.Get(ctx, "sre"); !errors.IsNotFound(err) {
+		t.Errorf("expected the GroupPermission to be deleted, got %v", err)
+	}
+}
+
+// TestElevations tests the ElevationInterface
+// given: an Elevation created through the typed client
+// expected: the Elevation is listed in its namespace and not in another one
+func TestElevations(t *testing.T) {
+	s, err := NewScheme()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := NewForClient(fake.NewFakeClientWithScheme(s))
+	ctx := context.TODO()
+
+	elevation := &v1alpha1.Elevation{
+		ObjectMeta: metav1.ObjectMeta{Name: "incident"},
+		Spec:       v1alpha1.ElevationSpec{GroupPermissionName: "sre", DurationHours: 1, Justification: "incident"},
+	}
+	if err := c.Elevations(namespace).Create(ctx, elevation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if list, err := c.Elevations(namespace).List(ctx, nil); err != nil || len(list.Items) != 1 {
+		t.Errorf("expected the Elevation listed, got %v, %v", list, err)
+	}
+	if list, err := c.Elevations("default").List(ctx, nil); err != nil || len(list.Items) != 0 {
+		t.Errorf("expected no Elevation in default, got %v, %v", list, err)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY GroupPermission, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package managedclient
+
+import (
+	"context"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GroupPermissionInterface reads and writes the GroupPermissions of a namespace
+type GroupPermissionInterface interface {
+	Get(ctx context.Context, name string) (*managedv1alpha1.GroupPermission, error)
+	// List returns the GroupPermissions matching selector, every GroupPermission when selector is nil
+	List(ctx context.Context, selector labels.Selector) (*managedv1alpha1.GroupPermissionList, error)
+	Create(ctx context.Context, obj *managedv1alpha1.GroupPermission) error
+	Update(ctx context.Context, obj *managedv1alpha1.GroupPermission) error
+	UpdateStatus(ctx context.Context, obj *managedv1alpha1.GroupPermission) error
+	Delete(ctx context.Context, name string) error
+}
+
+// groupPermissions implements GroupPermissionInterface
+type groupPermissions struct {
+	client    client.Client
+	namespace string
+}
+
+// blank assignment to verify that groupPermissions implements GroupPermissionInterface
+var _ GroupPermissionInterface = &groupPermissions{}
+
+// Get returns the GroupPermission name
+func (c *groupPermissions) Get(ctx context.Context, name string) (*managedv1alpha1.GroupPermission, error) {
+	obj := &managedv1alpha1.GroupPermission{}
+	if err := c.client.Get(ctx, types.NamespacedName{Namespace: c.namespace, Name: name}, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// List returns the GroupPermissions matching selector
+func (c *groupPermissions) List(ctx context.Context, selector labels.Selector) (*managedv1alpha1.GroupPermissionList, error) {
+	list := &managedv1alpha1.GroupPermissionList{}
+	if err := c.client.List(ctx, listOptions(c.namespace, selector), list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Create creates obj in the namespace
+func (c *groupPermissions) Create(ctx context.Context, obj *managedv1alpha1.GroupPermission) error {
+	obj.Namespace = c.namespace
+	return c.client.Create(ctx, obj)
+}
+
+// Update updates obj, its status is ignored
+func (c *groupPermissions) Update(ctx context.Context, obj *managedv1alpha1.GroupPermission) error {
+	obj.Namespace = c.namespace
+	return c.client.Update(ctx, obj)
+}
+
+// UpdateStatus updates the status of obj
+func (c *groupPermissions) UpdateStatus(ctx context.Context, obj *managedv1alpha1.GroupPermission) error {
+	obj.Namespace = c.namespace
+	return c.client.Status().Update(ctx, obj)
+}
+
+// Delete deletes the GroupPermission name
+func (c *groupPermissions) Delete(ctx context.Context, name string) error {
+	obj := &managedv1alpha1.GroupPermission{}
+	obj.Namespace = c.namespace
+	obj.Name = name
+	return c.client.Delete(ctx, obj)
+}
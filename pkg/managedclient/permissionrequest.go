@@ -0,0 +1,89 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY PermissionRequest, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package managedclient
+
+import (
+	"context"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PermissionRequestInterface reads and writes the PermissionRequests of a namespace
+type PermissionRequestInterface interface {
+	Get(ctx context.Context, name string) (*managedv1alpha1.PermissionRequest, error)
+	// List returns the PermissionRequests matching selector, every PermissionRequest when selector is nil
+	List(ctx context.Context, selector labels.Selector) (*managedv1alpha1.PermissionRequestList, error)
+	Create(ctx context.Context, obj *managedv1alpha1.PermissionRequest) error
+	Update(ctx context.Context, obj *managedv1alpha1.PermissionRequest) error
+	UpdateStatus(ctx context.Context, obj *managedv1alpha1.PermissionRequest) error
+	Delete(ctx context.Context, name string) error
+}
+
+// permissionRequests implements PermissionRequestInterface
+type permissionRequests struct {
+	client    client.Client
+	namespace string
+}
+
+// blank assignment to verify that permissionRequests implements PermissionRequestInterface
+var _ PermissionRequestInterface = &permissionRequests{}
+
+// Get returns the PermissionRequest name
+func (c *permissionRequests) Get(ctx context.Context, name string) (*managedv1alpha1.PermissionRequest, error) {
+	obj := &managedv1alpha1.PermissionRequest{}
+	if err := c.client.Get(ctx, types.NamespacedName{Namespace: c.namespace, Name: name}, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// List returns the PermissionRequests matching selector
+func (c *permissionRequests) List(ctx context.Context, selector labels.Selector) (*managedv1alpha1.PermissionRequestList, error) {
+	list := &managedv1alpha1.PermissionRequestList{}
+	if err := c.client.List(ctx, listOptions(c.namespace, selector), list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Create creates obj in the namespace
+func (c *permissionRequests) Create(ctx context.Context, obj *managedv1alpha1.PermissionRequest) error {
+	obj.Namespace = c.namespace
+	return c.client.Create(ctx, obj)
+}
+
+// Update updates obj, its status is ignored
+func (c *permissionRequests) Update(ctx context.Context, obj *managedv1alpha1.PermissionRequest) error {
+	obj.Namespace = c.namespace
+	return c.client.Update(ctx, obj)
+}
+
+// UpdateStatus updates the status of obj
+func (c *permissionRequests) UpdateStatus(ctx context.Context, obj *managedv1alpha1.PermissionRequest) error {
+	obj.Namespace = c.namespace
+	return c.client.Status().Update(ctx, obj)
+}
+
+// Delete deletes the PermissionRequest name
+func (c *permissionRequests) Delete(ctx context.Context, name string) error {
+	obj := &managedv1alpha1.PermissionRequest{}
+	obj.Namespace = c.namespace
+	obj.Name = name
+	return c.client.Delete(ctx, obj)
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package migration upgrades the objects left by older versions of the operator on startup.
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("migration")
+
+// versionKey is the key of the migrations ConfigMap holding the version of the last migration applied
+const versionKey = "version"
+
+// Migration is an idempotent change of the objects left by older versions of the operator.
+// A Migration interrupted halfway is run again on the next startup.
+type Migration struct {
+	// Version orders the migrations, it must increase with every new Migration and never change
+	Version int
+	// Description of the change, for the logs
+	Description string
+	// Migrate applies the change with the client, namespace is the namespace of the operator
+	Migrate func(ctx context.Context, c client.Client, namespace string) error
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update,namespace=openshift-rbac-permissions-operator
+
+// Run applies, in order, the migrations newer than the version recorded in the migrations ConfigMap
+// of namespace, recording the version of each one applied. Returns the number of migrations applied.
+func Run(ctx context.Context, c client.Client, namespace string, migrations []Migration) (int, error) {
+	configMap := &corev1.ConfigMap{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: operatorconfig.MigrationsConfigMapName}, configMap)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return 0, err
+		}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      operatorconfig.MigrationsConfigMapName,
+			},
+			Data: map[string]string{versionKey: "0"},
+		}
+		if err := c.Create(ctx, configMap); err != nil {
+			return 0, err
+		}
+	}
+
+	version := 0
+	if s, ok := configMap.Data[versionKey]; ok {
+		version, err = strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q in ConfigMap %s: %v", versionKey, s, operatorconfig.MigrationsConfigMapName, err)
+		}
+	}
+
+	applied := 0
+	previous := 0
+	for _, migration := range migrations {
+		if migration.Version <= previous {
+			return applied, fmt.Errorf("migration %d is not after migration %d", migration.Version, previous)
+		}
+		previous = migration.Version
+		if migration.Version <= version {
+			continue
+		}
+
+		log.Info("Applying migration", "Version", migration.Version, "Description", migration.Description)
+		if err := migration.Migrate(ctx, c, namespace); err != nil {
+			return applied, fmt.Errorf("migration %d failed: %v", migration.Version, err)
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[versionKey] = strconv.Itoa(migration.Version)
+		if err := c.Update(ctx, configMap); err != nil {
+			return applied, err
+		}
+		applied++
+	}
+
+	return applied, nil
+}
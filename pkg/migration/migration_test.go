@@ -0,0 +1,213 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "rbac-permissions-operator"
+
+// recordingMigration returns a Migration of version appending its version to applied
+func recordingMigration(version int, applied *[]int) Migration {
+	return Migration{
+		Version:     version,
+		Description: "test",
+		Migrate: func(ctx context.Context, c client.Client, namespace string) error {
+			*applied = append(*applied, version)
+			return nil
+		},
+	}
+}
+
+// TestRun tests the Run function
+// given: migrations run on a new cluster, run again, then with a new migration
+// expected: each migration is applied once, in order, and the last version is recorded
+func TestRun(t *testing.T) {
+	c := fake.NewFakeClient()
+	var applied []int
+	migrations := []Migration{recordingMigration(1, &applied), recordingMigration(2, &applied)}
+
+	if count, err := Run(context.TODO(), c, testNamespace, migrations); err != nil || count != 2 {
+		t.Fatalf("expected 2 migrations applied, got %d, %v", count, err)
+	}
+	if count, err := Run(context.TODO(), c, testNamespace, migrations); err != nil || count != 0 {
+		t.Fatalf("expected no migration applied again, got %d, %v", count, err)
+	}
+	migrations = append(migrations, recordingMigration(3, &applied))
+	if count, err := Run(context.TODO(), c, testNamespace, migrations); err != nil || count != 1 {
+		t.Fatalf("expected the new migration applied, got %d, %v", count, err)
+	}
+
+	if len(applied) != 3 || applied[0] != 1 || applied[1] != 2 || applied[2] != 3 {
+		t.Errorf("expected migrations 1, 2, 3 applied once in order, got %v", applied)
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: operatorconfig.MigrationsConfigMapName}, configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if configMap.Data[versionKey] != "3" {
+		t.Errorf("expected version 3 recorded, got %q", configMap.Data[versionKey])
+	}
+}
+
+// TestRunFailure tests the Run function
+// given: migrations of which the second fails
+// expected: the version of the first is recorded and the failed one is retried on the next run
+func TestRunFailure(t *testing.T) {
+	c := fake.NewFakeClient()
+	var applied []int
+	failing := Migration{
+		Version: 2,
+		Migrate: func(ctx context.Context, c client.Client, namespace string) error {
+			return errors.New("failed")
+		},
+	}
+
+	if count, err := Run(context.TODO(), c, testNamespace, []Migration{recordingMigration(1, &applied), failing}); err == nil || count != 1 {
+		t.Fatalf("expected the second migration to fail after 1 applied, got %d, %v", count, err)
+	}
+	if count, err := Run(context.TODO(), c, testNamespace, []Migration{recordingMigration(1, &applied), recordingMigration(2, &applied)}); err != nil || count != 1 {
+		t.Fatalf("expected the failed migration to be retried, got %d, %v", count, err)
+	}
+	if len(applied) != 2 || applied[1] != 2 {
+		t.Errorf("expected migrations 1 then 2, got %v", applied)
+	}
+}
+
+// TestRunOrder tests the Run function
+// given: migrations out of order
+// expected: an error before any migration is applied twice
+func TestRunOrder(t *testing.T) {
+	var applied []int
+	_, err := Run(context.TODO(), fake.NewFakeClient(), testNamespace, []Migration{recordingMigration(2, &applied), recordingMigration(1, &applied)})
+	if err == nil {
+		t.Errorf("expected an error for migrations out of order")
+	}
+}
+
+// TestLabelUnmanagedBindings tests the labelUnmanagedBindings migration
+// given: unlabeled bindings created for a GroupPermission, and unrelated bindings
+// expected: only the bindings of the GroupPermission are labeled as managed by it
+func TestLabelUnmanagedBindings(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := &v1alpha1.GroupPermission{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "dedicated-admins"},
+		Spec: v1alpha1.GroupPermissionSpec{
+			GroupName:          "dedicated-admins",
+			ClusterPermissions: []string{"dedicated-readers"},
+			Permissions:        []v1alpha1.Permission{{ClusterRoleName: "admin", NamespacesAllowedRegex: ".*"}},
+		},
+	}
+	binding := func(namespace, clusterRoleName, groupName string) (metav1.ObjectMeta, rbacv1.RoleRef, []rbacv1.Subject) {
+		return metav1.ObjectMeta{Namespace: namespace, Name: clusterRoleName + "-" + groupName},
+			rbacv1.RoleRef{Kind: "ClusterRole", Name: clusterRoleName},
+			[]rbacv1.Subject{{Kind: "Group", Name: groupName}}
+	}
+	clusterRoleBinding := func(clusterRoleName, groupName string) *rbacv1.ClusterRoleBinding {
+		objectMeta, roleRef, subjects := binding("", clusterRoleName, groupName)
+		return &rbacv1.ClusterRoleBinding{ObjectMeta: objectMeta, RoleRef: roleRef, Subjects: subjects}
+	}
+	roleBinding := func(namespace, clusterRoleName, groupName string) *rbacv1.RoleBinding {
+		objectMeta, roleRef, subjects := binding(namespace, clusterRoleName, groupName)
+		return &rbacv1.RoleBinding{ObjectMeta: objectMeta, RoleRef: roleRef, Subjects: subjects}
+	}
+	foreign := roleBinding("team-b", "admin", "dedicated-admins")
+	foreign.Subjects = append(foreign.Subjects, rbacv1.Subject{Kind: "User", Name: "alice"})
+
+	c := fake.NewFakeClient(
+		groupPermission,
+		clusterRoleBinding("dedicated-readers", "dedicated-admins"),
+		clusterRoleBinding("cluster-admin", "dedicated-admins"),
+		roleBinding("team-a", "admin", "dedicated-admins"),
+		foreign,
+	)
+
+	if err := labelUnmanagedBindings(context.TODO(), c, testNamespace); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		key     types.NamespacedName
+		obj     interface{}
+		managed bool
+	}{
+		{types.NamespacedName{Name: "dedicated-readers-dedicated-admins"}, &rbacv1.ClusterRoleBinding{}, true},
+		{types.NamespacedName{Name: "cluster-admin-dedicated-admins"}, &rbacv1.ClusterRoleBinding{}, false},
+		{types.NamespacedName{Namespace: "team-a", Name: "admin-dedicated-admins"}, &rbacv1.RoleBinding{}, true},
+		{types.NamespacedName{Namespace: "team-b", Name: "admin-dedicated-admins"}, &rbacv1.RoleBinding{}, false},
+	}
+	for _, test := range tests {
+		var objectMeta *metav1.ObjectMeta
+		switch obj := test.obj.(type) {
+		case *rbacv1.ClusterRoleBinding:
+			if err := c.Get(context.TODO(), test.key, obj); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			objectMeta = &obj.ObjectMeta
+		case *rbacv1.RoleBinding:
+			if err := c.Get(context.TODO(), test.key, obj); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			objectMeta = &obj.ObjectMeta
+		}
+		if managed := utility.IsManagedFor(*objectMeta, testNamespace, "dedicated-admins"); managed != test.managed {
+			t.Errorf("%s: got managed %t, want %t", test.key, managed, test.managed)
+		}
+	}
+}
+
+// TestRecordRoleBindingSources tests the recordRoleBindingSources migration
+// given: managed RoleBindings of a GroupPermission recording no source, the UID of a deleted GroupPermission
+// of the same name and its UID, and a managed RoleBinding of another GroupPermission
+// expected: the UID of the GroupPermission is recorded on its RoleBindings, the other one is left as it is
+func TestRecordRoleBindingSources(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	groupPermission := &v1alpha1.GroupPermission{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "dedicated-admins", UID: "uid-1"}}
+	roleBinding := func(namespace, owner, uid string) *rbacv1.RoleBinding {
+		binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "admin-dedicated-admins"}}
+		utility.SetManagedLabels(&binding.ObjectMeta, testNamespace, owner)
+		if uid != "" {
+			binding.Annotations = map[string]string{operatorconfig.SourceUIDAnnotation: uid}
+		}
+		return binding
+	}
+	c := fake.NewFakeClient(groupPermission,
+		roleBinding("team-a", "dedicated-admins", ""),
+		roleBinding("team-b", "dedicated-admins", "uid-0"),
+		roleBinding("team-c", "dedicated-admins", "uid-1"),
+		roleBinding("team-d", "other", "uid-2"),
+	)
+
+	if err := recordRoleBindingSources(context.TODO(), c, testNamespace); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for namespace, expected := range map[string]string{"team-a": "uid-1", "team-b": "uid-1", "team-c": "uid-1", "team-d": "uid-2"} {
+		binding := &rbacv1.RoleBinding{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "admin-dedicated-admins"}, binding); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if uid := binding.Annotations[operatorconfig.SourceUIDAnnotation]; uid != expected {
+			t.Errorf("%s: Mismatch for source UID. Expected(%s), Found(%s)", namespace, expected, uid)
+		}
+	}
+}
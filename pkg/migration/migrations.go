@@ -0,0 +1,163 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"context"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Migrations of the operator, in order. Append new migrations, never change or remove released ones.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "Label the bindings created before managed bindings were labeled",
+		Migrate:     labelUnmanagedBindings,
+	},
+	{
+		Version:     2,
+		Description: "Record the source GroupPermission UID on the RoleBindings created before it was recorded",
+		Migrate:     recordRoleBindingSources,
+	},
+}
+
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings;rolebindings,verbs=list;update
+
+// labelUnmanagedBindings adds the managed labels to the bindings created for a GroupPermission of namespace by
+// operator versions that did not label them, so they are deleted with their GroupPermission and
+// collected once orphaned. A binding is recognized by its name, ClusterRole and Group.
+func labelUnmanagedBindings(ctx context.Context, c client.Client, namespace string) error {
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	if err := c.List(ctx, &client.ListOptions{Namespace: namespace}, groupPermissionList); err != nil {
+		return err
+	}
+	clusterRoleBindingList := &rbacv1.ClusterRoleBindingList{}
+	if err := c.List(ctx, &client.ListOptions{}, clusterRoleBindingList); err != nil {
+		return err
+	}
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := c.List(ctx, &client.ListOptions{}, roleBindingList); err != nil {
+		return err
+	}
+
+	// owners of the bindings by name, the first GroupPermission granting a binding owns it
+	clusterRoleBindingOwners := map[string]*managedv1alpha1.GroupPermission{}
+	roleBindingOwners := map[string]*managedv1alpha1.GroupPermission{}
+	for i := range groupPermissionList.Items {
+		groupPermission := &groupPermissionList.Items[i]
+		for _, clusterRoleName := range groupPermission.Spec.ClusterPermissions {
+			name := clusterRoleName + "-" + groupPermission.Spec.GroupName
+			if _, ok := clusterRoleBindingOwners[name]; !ok {
+				clusterRoleBindingOwners[name] = groupPermission
+			}
+		}
+		for _, permission := range groupPermission.Spec.Permissions {
+			if permission.ClusterRoleName == "" {
+				continue
+			}
+			name := permission.ClusterRoleName + "-" + groupPermission.Spec.GroupName
+			if _, ok := roleBindingOwners[name]; !ok {
+				roleBindingOwners[name] = groupPermission
+			}
+		}
+	}
+
+	for i := range clusterRoleBindingList.Items {
+		binding := &clusterRoleBindingList.Items[i]
+		owner := clusterRoleBindingOwners[binding.Name]
+		if owner == nil || !isBindingOf(binding.ObjectMeta, binding.RoleRef, binding.Subjects, owner) {
+			continue
+		}
+		utility.SetManagedLabels(&binding.ObjectMeta, owner.Namespace, owner.Name)
+		if err := c.Update(ctx, binding); err != nil {
+			return err
+		}
+		log.Info("Labeled binding", "Kind", "ClusterRoleBinding", "Name", binding.Name, "Owner", types.NamespacedName{Namespace: owner.Namespace, Name: owner.Name}.String())
+	}
+	for i := range roleBindingList.Items {
+		binding := &roleBindingList.Items[i]
+		owner := roleBindingOwners[binding.Name]
+		if owner == nil || !isBindingOf(binding.ObjectMeta, binding.RoleRef, binding.Subjects, owner) {
+			continue
+		}
+		utility.SetManagedLabels(&binding.ObjectMeta, owner.Namespace, owner.Name)
+		if err := c.Update(ctx, binding); err != nil {
+			return err
+		}
+		log.Info("Labeled binding", "Kind", "RoleBinding", "Namespace", binding.Namespace, "Name", binding.Name, "Owner", types.NamespacedName{Namespace: owner.Namespace, Name: owner.Name}.String())
+	}
+
+	return nil
+}
+
+// isBindingOf returns whether an unlabeled binding binds a ClusterRole to the Group of groupPermission
+// alone, as the operator created them
+func isBindingOf(objectMeta metav1.ObjectMeta, roleRef rbacv1.RoleRef, subjects []rbacv1.Subject, groupPermission *managedv1alpha1.GroupPermission) bool {
+	if utility.IsManaged(objectMeta) || roleRef.Kind != "ClusterRole" || len(subjects) != 1 {
+		return false
+	}
+	if objectMeta.Name != roleRef.Name+"-"+groupPermission.Spec.GroupName {
+		return false
+	}
+	return subjects[0].Kind == "Group" && subjects[0].Name == groupPermission.Spec.GroupName
+}
+
+// recordRoleBindingSources records the namespace/name and the UID of their GroupPermission of namespace on
+// the managed RoleBindings that do not record them, or record those of a deleted GroupPermission of the
+// same name, so the RoleBindings of a GroupPermission are found by its UID
+func recordRoleBindingSources(ctx context.Context, c client.Client, namespace string) error {
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	if err := c.List(ctx, &client.ListOptions{Namespace: namespace}, groupPermissionList); err != nil {
+		return err
+	}
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := c.List(ctx, &client.ListOptions{LabelSelector: utility.ManagedSelector()}, roleBindingList); err != nil {
+		return err
+	}
+
+	owners := map[types.NamespacedName]*managedv1alpha1.GroupPermission{}
+	for i := range groupPermissionList.Items {
+		groupPermission := &groupPermissionList.Items[i]
+		owners[types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}] = groupPermission
+	}
+
+	for i := range roleBindingList.Items {
+		binding := &roleBindingList.Items[i]
+		key, ok := utility.ManagedOwner(binding.ObjectMeta)
+		owner := owners[key]
+		if !ok || owner == nil || binding.Annotations[operatorconfig.SourceUIDAnnotation] == string(owner.UID) {
+			continue
+		}
+		if binding.Annotations == nil {
+			binding.Annotations = map[string]string{}
+		}
+		binding.Annotations[operatorconfig.SourceAnnotation] = key.String()
+		binding.Annotations[operatorconfig.SourceUIDAnnotation] = string(owner.UID)
+		if err := c.Update(ctx, binding); err != nil {
+			return err
+		}
+		log.Info("Recorded binding source", "Kind", "RoleBinding", "Namespace", binding.Namespace, "Name", binding.Name, "Owner", key.String())
+	}
+
+	return nil
+}
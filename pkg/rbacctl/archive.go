@@ -0,0 +1,369 @@
+package rbacctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// archiveVersion is the version of the format of the archive, changed on incompatible changes
+const archiveVersion = "v1"
+
+func init() {
+	Commands["export"] = Command{
+		Description: "Export the GroupPermissions and a snapshot of their managed bindings to an archive",
+		Run:         runExport,
+	}
+	Commands["import"] = Command{
+		Description: "Import the GroupPermissions of an archive and report the roles and Namespaces missing from the cluster",
+		Run:         runImport,
+	}
+}
+
+// archive holds the GroupPermissions of a cluster and the bindings they were managing, to rebuild the
+// cluster from
+type archive struct {
+	Version    string      `json:"version"`
+	ExportedAt metav1.Time `json:"exportedAt"`
+	// GroupPermissions without their status and the metadata set by the apiserver
+	GroupPermissions []managedv1alpha1.GroupPermission `json:"groupPermissions"`
+	// Bindings managed for the GroupPermissions when they were exported
+	Bindings []archivedBinding `json:"bindings,omitempty"`
+}
+
+// archivedBinding is a ClusterRoleBinding or a RoleBinding managed for a GroupPermission
+type archivedBinding struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// GroupPermission the binding is managed for, as namespace/name
+	Owner    string           `json:"owner"`
+	RoleRef  rbacv1.RoleRef   `json:"roleRef"`
+	Subjects []rbacv1.Subject `json:"subjects"`
+}
+
+// importReport is the outcome of the import of an archive
+type importReport struct {
+	// GroupPermissions by outcome, as namespace/name
+	created   []string
+	updated   []string
+	unchanged []string
+	// gaps are what the cluster lacks for the GroupPermissions to grant what they did when exported
+	gaps []string
+}
+
+// runExport writes the GroupPermissions of the cluster and their managed bindings to an archive
+func runExport(args []string, out io.Writer) error {
+	flags := pflag.NewFlagSet("export", pflag.ContinueOnError)
+	namespace := flags.StringP("namespace", "n", "", "Namespace of the GroupPermissions to export, every namespace when empty")
+	output := flags.StringP("output", "o", "", "File the archive is written to, the standard output when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	exported, err := exportArchive(context.TODO(), c, *namespace, time.Now())
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = fmt.Fprintf(out, "%s\n", content)
+		return err
+	}
+	if err := ioutil.WriteFile(*output, content, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Exported %d GroupPermissions and %d bindings to %s\n", len(exported.GroupPermissions), len(exported.Bindings), *output)
+	return nil
+}
+
+// runImport creates or updates the GroupPermissions of an archive, for the operator to reconcile their
+// bindings, and fails when the cluster lacks roles or Namespaces they were granting
+func runImport(args []string, out io.Writer) error {
+	flags := pflag.NewFlagSet("import", pflag.ContinueOnError)
+	file := flags.StringP("filename", "f", "", "File containing the archive written by export")
+	dryRun := flags.Bool("dry-run", false, "Only report what would be imported and the gaps, without changing the cluster")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("-f is required")
+	}
+
+	imported, err := loadArchive(*file)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	report, err := importArchive(context.TODO(), c, imported, *dryRun)
+	if err != nil {
+		return err
+	}
+	return printImportReport(out, report, *dryRun)
+}
+
+// exportArchive returns the archive of the GroupPermissions of namespace, of every namespace when
+// empty, and of their managed bindings, exported at now
+func exportArchive(ctx context.Context, c client.Client, namespace string, now time.Time) (*archive, error) {
+	groupPermissionList := &managedv1alpha1.GroupPermissionList{}
+	if err := c.List(ctx, &client.ListOptions{Namespace: namespace}, groupPermissionList); err != nil {
+		return nil, err
+	}
+	clusterRoleBindingList := &rbacv1.ClusterRoleBindingList{}
+	if err := c.List(ctx, &client.ListOptions{LabelSelector: utility.ManagedSelector()}, clusterRoleBindingList); err != nil {
+		return nil, err
+	}
+	roleBindingList := &rbacv1.RoleBindingList{}
+	if err := c.List(ctx, &client.ListOptions{LabelSelector: utility.ManagedSelector()}, roleBindingList); err != nil {
+		return nil, err
+	}
+
+	exported := &archive{Version: archiveVersion, ExportedAt: metav1.NewTime(now.UTC()), GroupPermissions: []managedv1alpha1.GroupPermission{}}
+	owners := map[string]bool{}
+	for _, groupPermission := range groupPermissionList.Items {
+		exported.GroupPermissions = append(exported.GroupPermissions, portableGroupPermission(&groupPermission))
+		owners[groupPermission.Namespace+"/"+groupPermission.Name] = true
+	}
+	sort.Slice(exported.GroupPermissions, func(i, j int) bool {
+		return exported.GroupPermissions[i].Namespace+"/"+exported.GroupPermissions[i].Name < exported.GroupPermissions[j].Namespace+"/"+exported.GroupPermissions[j].Name
+	})
+
+	record := func(kind string, objectMeta metav1.ObjectMeta, roleRef rbacv1.RoleRef, subjects []rbacv1.Subject) {
+		// bindings of Elevations and of GroupPermissions not exported are left out
+		owner, ok := utility.ManagedOwner(objectMeta)
+		if !ok || !utility.IsManaged(objectMeta) || !owners[owner.String()] {
+			return
+		}
+		exported.Bindings = append(exported.Bindings, archivedBinding{
+			Kind:      kind,
+			Namespace: objectMeta.Namespace,
+			Name:      objectMeta.Name,
+			Owner:     owner.String(),
+			RoleRef:   roleRef,
+			Subjects:  subjects,
+		})
+	}
+	for _, binding := range clusterRoleBindingList.Items {
+		record("ClusterRoleBinding", binding.ObjectMeta, binding.RoleRef, binding.Subjects)
+	}
+	for _, binding := range roleBindingList.Items {
+		record("RoleBinding", binding.ObjectMeta, binding.RoleRef, binding.Subjects)
+	}
+	sort.Slice(exported.Bindings, func(i, j int) bool {
+		a, b := exported.Bindings[i], exported.Bindings[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return exported, nil
+}
+
+// portableGroupPermission returns groupPermission without its status and the metadata that does not
+// carry over to another cluster. The generations of rollbackToGeneration do not either.
+func portableGroupPermission(groupPermission *managedv1alpha1.GroupPermission) managedv1alpha1.GroupPermission {
+	portable := managedv1alpha1.GroupPermission{
+		TypeMeta: metav1.TypeMeta{APIVersion: managedv1alpha1.SchemeGroupVersion.String(), Kind: "GroupPermission"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        groupPermission.Name,
+			Namespace:   groupPermission.Namespace,
+			Labels:      groupPermission.Labels,
+			Annotations: map[string]string{},
+		},
+		Spec: *groupPermission.Spec.DeepCopy(),
+	}
+	portable.Spec.RollbackToGeneration = 0
+	for key, value := range groupPermission.Annotations {
+		if key != operatorconfig.LastAppliedHashAnnotation {
+			portable.Annotations[key] = value
+		}
+	}
+	if len(portable.Annotations) == 0 {
+		portable.Annotations = nil
+	}
+	return portable
+}
+
+// loadArchive reads the archive of file
+func loadArchive(file string) (*archive, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	loaded := &archive{}
+	if err := json.Unmarshal(content, loaded); err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %v", file, err)
+	}
+	return loaded, nil
+}
+
+// importArchive creates the GroupPermissions of imported missing from the cluster and updates the
+// spec of the existing ones, unless dryRun, and reports the ClusterRoles, Roles and Namespaces the
+// cluster lacks for them to grant what they did when exported
+func importArchive(ctx context.Context, c client.Client, imported *archive, dryRun bool) (*importReport, error) {
+	if imported.Version != archiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %q, expected %s", imported.Version, archiveVersion)
+	}
+
+	clusterRoleList := &rbacv1.ClusterRoleList{}
+	if err := c.List(ctx, &client.ListOptions{}, clusterRoleList); err != nil {
+		return nil, err
+	}
+	clusterRoles := map[string]bool{}
+	for _, clusterRole := range clusterRoleList.Items {
+		clusterRoles[clusterRole.Name] = true
+	}
+	namespaceList := &corev1.NamespaceList{}
+	if err := c.List(ctx, &client.ListOptions{}, namespaceList); err != nil {
+		return nil, err
+	}
+	namespaces := map[string]bool{}
+	for _, namespace := range namespaceList.Items {
+		namespaces[namespace.Name] = true
+	}
+
+	report := &importReport{}
+	gaps := map[string]bool{}
+	// Roles created by the operator from the rules of a Permission, by GroupPermission
+	createdRoles := map[string]map[string]bool{}
+	for i := range imported.GroupPermissions {
+		groupPermission := &imported.GroupPermissions[i]
+		name := groupPermission.Namespace + "/" + groupPermission.Name
+
+		clusterRoleNames := append([]string{}, groupPermission.Spec.ClusterPermissions...)
+		createdRoles[name] = map[string]bool{}
+		for _, permission := range groupPermission.Spec.Permissions {
+			if permission.ClusterRoleName != "" {
+				clusterRoleNames = append(clusterRoleNames, permission.ClusterRoleName)
+			}
+			if len(permission.Rules) > 0 {
+				createdRoles[name][permission.RoleName] = true
+			}
+		}
+		for _, clusterRoleName := range clusterRoleNames {
+			if !clusterRoles[clusterRoleName] {
+				gaps[fmt.Sprintf("ClusterRole %s granted by GroupPermission %s does not exist", clusterRoleName, name)] = true
+			}
+		}
+
+		outcome, err := applyGroupPermission(ctx, c, groupPermission, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("unable to import GroupPermission %s: %v", name, err)
+		}
+		switch outcome {
+		case "created":
+			report.created = append(report.created, name)
+		case "updated":
+			report.updated = append(report.updated, name)
+		default:
+			report.unchanged = append(report.unchanged, name)
+		}
+	}
+
+	for _, binding := range imported.Bindings {
+		if binding.Kind != "RoleBinding" {
+			continue
+		}
+		if !namespaces[binding.Namespace] {
+			gaps[fmt.Sprintf("Namespace %s of the RoleBinding %s of GroupPermission %s does not exist", binding.Namespace, binding.Name, binding.Owner)] = true
+			continue
+		}
+		if binding.RoleRef.Kind != "Role" || createdRoles[binding.Owner][binding.RoleRef.Name] {
+			continue
+		}
+		err := c.Get(ctx, types.NamespacedName{Namespace: binding.Namespace, Name: binding.RoleRef.Name}, &rbacv1.Role{})
+		if errors.IsNotFound(err) {
+			gaps[fmt.Sprintf("Role %s/%s granted by GroupPermission %s does not exist", binding.Namespace, binding.RoleRef.Name, binding.Owner)] = true
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	for gap := range gaps {
+		report.gaps = append(report.gaps, gap)
+	}
+	sort.Strings(report.gaps)
+	return report, nil
+}
+
+// applyGroupPermission creates groupPermission, or updates the spec of the existing one, unless
+// dryRun. It returns whether it was created, updated or unchanged.
+func applyGroupPermission(ctx context.Context, c client.Client, groupPermission *managedv1alpha1.GroupPermission, dryRun bool) (string, error) {
+	existing := &managedv1alpha1.GroupPermission{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: groupPermission.Namespace, Name: groupPermission.Name}, existing)
+	if errors.IsNotFound(err) {
+		if !dryRun {
+			if err := c.Create(ctx, groupPermission.DeepCopy()); err != nil {
+				return "", err
+			}
+		}
+		return "created", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if reflect.DeepEqual(existing.Spec, groupPermission.Spec) {
+		return "unchanged", nil
+	}
+	if !dryRun {
+		existing.Spec = groupPermission.Spec
+		if err := c.Update(ctx, existing); err != nil {
+			return "", err
+		}
+	}
+	return "updated", nil
+}
+
+// printImportReport prints report to out, and returns an error when the cluster has gaps
+func printImportReport(out io.Writer, report *importReport, dryRun bool) error {
+	suffix := ""
+	if dryRun {
+		suffix = " (dry run)"
+	}
+	for _, name := range report.created {
+		fmt.Fprintf(out, "GroupPermission %s created%s\n", name, suffix)
+	}
+	for _, name := range report.updated {
+		fmt.Fprintf(out, "GroupPermission %s updated%s\n", name, suffix)
+	}
+	for _, name := range report.unchanged {
+		fmt.Fprintf(out, "GroupPermission %s unchanged\n", name)
+	}
+	for _, gap := range report.gaps {
+		fmt.Fprintf(out, "gap: %s\n", gap)
+	}
+	if len(report.gaps) > 0 {
+		return fmt.Errorf("%d gaps found, the GroupPermissions cannot grant everything they did when exported", len(report.gaps))
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package rbacctl
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func archiveGroupPermission() *managedv1alpha1.GroupPermission {
+	return &managedv1alpha1.GroupPermission{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "team-a",
+			Namespace:       operatorconfig.OperatorNamespace,
+			ResourceVersion: "42",
+			Annotations:     map[string]string{operatorconfig.LastAppliedHashAnnotation: "abc", operatorconfig.RequesterAnnotation: "bob"},
+		},
+		Spec: managedv1alpha1.GroupPermissionSpec{
+			GroupName:          "team-a",
+			ClusterPermissions: []string{"view"},
+			Permissions: []managedv1alpha1.Permission{
+				{ClusterRoleName: "admin", NamespacesAllowedRegex: "^team-a-.*"},
+				{RoleName: "deployer", NamespacesAllowedRegex: "^team-a-.*"},
+			},
+			RollbackToGeneration: 3,
+		},
+		Status: managedv1alpha1.GroupPermissionStatus{State: "Active"},
+	}
+}
+
+func archiveRoleBinding(namespace string, roleRef rbacv1.RoleRef) *rbacv1.RoleBinding {
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: roleRef.Name + "-team-a", Namespace: namespace},
+		RoleRef:    roleRef,
+		Subjects:   []rbacv1.Subject{{Kind: "Group", Name: "team-a"}},
+	}
+	utility.SetManagedLabels(&roleBinding.ObjectMeta, operatorconfig.OperatorNamespace, "team-a")
+	return roleBinding
+}
+
+func TestExportImportArchive(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	elevation := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "admin-incident", Labels: utility.ManagedByLabels()},
+		RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+	}
+	source := fake.NewFakeClient(archiveGroupPermission(), elevation,
+		archiveRoleBinding("team-a-dev", rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"}),
+		archiveRoleBinding("team-a-dev", rbacv1.RoleRef{Kind: "Role", Name: "deployer"}),
+		archiveRoleBinding("team-a-prod", rbacv1.RoleRef{Kind: "Role", Name: "deployer"}))
+
+	exported, err := exportArchive(context.TODO(), source, "", time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exported.GroupPermissions) != 1 || len(exported.Bindings) != 3 {
+		t.Fatalf("expected 1 GroupPermission and 3 bindings, got %+v", exported)
+	}
+	portable := exported.GroupPermissions[0]
+	expectedAnnotations := map[string]string{operatorconfig.RequesterAnnotation: "bob"}
+	if portable.ResourceVersion != "" || portable.Status.State != "" || portable.Spec.RollbackToGeneration != 0 || !reflect.DeepEqual(portable.Annotations, expectedAnnotations) {
+		t.Errorf("expected a portable GroupPermission, got %+v", portable)
+	}
+
+	// the rebuilt cluster lacks the view ClusterRole, the team-a-prod Namespace and the deployer Role of team-a-dev
+	target := fake.NewFakeClient(
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "admin"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a-dev"}})
+
+	if _, err := importArchive(context.TODO(), target, exported, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := target.Get(context.TODO(), types.NamespacedName{Namespace: operatorconfig.OperatorNamespace, Name: "team-a"}, &managedv1alpha1.GroupPermission{}); err == nil {
+		t.Errorf("expected the dry run to leave the cluster unchanged")
+	}
+
+	report, err := importArchive(context.TODO(), target, exported, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	imported := &managedv1alpha1.GroupPermission{}
+	if err := target.Get(context.TODO(), types.NamespacedName{Namespace: operatorconfig.OperatorNamespace, Name: "team-a"}, imported); err != nil {
+		t.Fatalf("expected the GroupPermission to be imported: %v", err)
+	}
+	if !reflect.DeepEqual(imported.Spec, portable.Spec) {
+		t.Errorf("Mismatch for imported spec. Expected(%+v), Found(%+v)", portable.Spec, imported.Spec)
+	}
+
+	out := &bytes.Buffer{}
+	err = printImportReport(out, report, false)
+	expected := "GroupPermission openshift-rbac-permissions-operator/team-a created\n" +
+		"gap: ClusterRole view granted by GroupPermission openshift-rbac-permissions-operator/team-a does not exist\n" +
+		"gap: Namespace team-a-prod of the RoleBinding deployer-team-a of GroupPermission openshift-rbac-permissions-operator/team-a does not exist\n" +
+		"gap: Role team-a-dev/deployer granted by GroupPermission openshift-rbac-permissions-operator/team-a does not exist\n"
+	if out.String() != expected {
+		t.Errorf("printImportReport() printed %q, expected %q", out.String(), expected)
+	}
+	if err == nil {
+		t.Errorf("expected an error for the gaps")
+	}
+
+	report, err = importArchive(context.TODO(), target, exported, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.unchanged) != 1 {
+		t.Errorf("expected the GroupPermission unchanged on a second import, got %+v", report)
+	}
+}
+
+func TestImportArchiveVersion(t *testing.T) {
+	_, err := importArchive(context.TODO(), fake.NewFakeClient(), &archive{Version: "v0"}, true)
+	if err == nil {
+		t.Errorf("expected an error for an unsupported version")
+	}
+}
@@ -0,0 +1,126 @@
+package rbacctl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const defaultCanIUser = "rbacctl-can-i"
+
+func init() {
+	Commands["can-i"] = Command{
+		Description: "Print the verbs the group of a GroupPermission is allowed on resources",
+		Run:         runCanI,
+	}
+}
+
+// accessMatrix holds the result of every verb for every resource
+type accessMatrix struct {
+	verbs     []string
+	resources []string
+	allowed   map[string]map[string]bool
+}
+
+// runCanI impersonates the group of a GroupPermission and runs a SelfSubjectAccessReview for each verb and resource
+func runCanI(args []string, out io.Writer) error {
+	flags := pflag.NewFlagSet("can-i", pflag.ContinueOnError)
+	file := flags.StringP("filename", "f", "", "File containing the GroupPermission")
+	name := flags.String("name", "", "Name of the GroupPermission on the cluster, used when -f is not set")
+	namespace := flags.StringP("namespace", "n", "", "Namespace of the GroupPermission on the cluster")
+	asGroup := flags.String("as-group", "", "Group to impersonate, defaults to the groupName of the GroupPermission")
+	asUser := flags.String("as", defaultCanIUser, "User to impersonate along with the group")
+	targetNamespace := flags.String("target-namespace", "", "Namespace to check access in, checks are made at cluster scope when empty")
+	verbs := flags.StringSlice("verbs", []string{"get", "list", "watch", "create", "update", "patch", "delete"}, "Verbs to check")
+	resources := flags.StringSlice("resources", nil, "Resources to check, in the form resource[.group][/subresource]")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(*resources) == 0 {
+		return fmt.Errorf("--resources is required")
+	}
+
+	group := *asGroup
+	if group == "" {
+		groupPermission, err := loadGroupPermission(*file, *namespace, *name)
+		if err != nil {
+			return err
+		}
+		group = groupPermission.Spec.GroupName
+	}
+
+	cfg, err := getConfig()
+	if err != nil {
+		return err
+	}
+	cfg.Impersonate.UserName = *asUser
+	cfg.Impersonate.Groups = []string{group}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	matrix := accessMatrix{
+		verbs:     *verbs,
+		resources: *resources,
+		allowed:   map[string]map[string]bool{},
+	}
+	for _, resource := range matrix.resources {
+		matrix.allowed[resource] = map[string]bool{}
+		for _, verb := range matrix.verbs {
+			attrs := parseResource(resource)
+			attrs.Verb = verb
+			attrs.Namespace = *targetNamespace
+			ssar, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(&authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attrs},
+			})
+			if err != nil {
+				return err
+			}
+			matrix.allowed[resource][verb] = ssar.Status.Allowed
+		}
+	}
+
+	fmt.Fprintf(out, "Access of group %q:\n", group)
+	return printAccessMatrix(out, matrix)
+}
+
+// parseResource parses resource[.group][/subresource] into ResourceAttributes
+func parseResource(resource string) *authorizationv1.ResourceAttributes {
+	attrs := &authorizationv1.ResourceAttributes{}
+
+	if i := strings.Index(resource, "/"); i >= 0 {
+		resource, attrs.Subresource = resource[:i], resource[i+1:]
+	}
+	if i := strings.Index(resource, "."); i >= 0 {
+		resource, attrs.Group = resource[:i], resource[i+1:]
+	}
+	attrs.Resource = resource
+
+	return attrs
+}
+
+// printAccessMatrix prints a table of resources by verbs with "yes" or "no" in each cell
+func printAccessMatrix(out io.Writer, matrix accessMatrix) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "RESOURCE\t%s\n", strings.ToUpper(strings.Join(matrix.verbs, "\t")))
+	for _, resource := range matrix.resources {
+		row := []string{resource}
+		for _, verb := range matrix.verbs {
+			if matrix.allowed[resource][verb] {
+				row = append(row, "yes")
+			} else {
+				row = append(row, "no")
+			}
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,51 @@
+package rbacctl
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseResource(t *testing.T) {
+	var tests = []struct {
+		resource    string
+		group       string
+		name        string
+		subresource string
+	}{
+		{"pods", "", "pods", ""},
+		{"pods/log", "", "pods", "log"},
+		{"deployments.apps", "apps", "deployments", ""},
+		{"deployments.apps/scale", "apps", "deployments", "scale"},
+		{"grouppermissions.managed.openshift.io", "managed.openshift.io", "grouppermissions", ""},
+	}
+	for _, test := range tests {
+		attrs := parseResource(test.resource)
+		if attrs.Group != test.group || attrs.Resource != test.name || attrs.Subresource != test.subresource {
+			t.Errorf("parseResource(%s) = (%s, %s, %s), expected (%s, %s, %s)", test.resource,
+				attrs.Group, attrs.Resource, attrs.Subresource, test.group, test.name, test.subresource)
+		}
+	}
+}
+
+func TestPrintAccessMatrix(t *testing.T) {
+	matrix := accessMatrix{
+		verbs:     []string{"get", "delete"},
+		resources: []string{"pods", "deployments.apps"},
+		allowed: map[string]map[string]bool{
+			"pods":             {"get": true, "delete": false},
+			"deployments.apps": {"get": true, "delete": true},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	if err := printAccessMatrix(out, matrix); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "RESOURCE          GET  DELETE\n" +
+		"pods              yes  no\n" +
+		"deployments.apps  yes  yes\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\nFound:\n%s", expected, out.String())
+	}
+}
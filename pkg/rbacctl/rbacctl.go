@@ -0,0 +1,92 @@
+package rbacctl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// Command is a rbacctl subcommand
+type Command struct {
+	// Short description of the command
+	Description string
+	// Run executes the command with the remaining command line arguments
+	Run func(args []string, out io.Writer) error
+}
+
+// Commands is the list of all rbacctl subcommands by name
+var Commands = map[string]Command{}
+
+// Usage prints the list of subcommands to out
+func Usage(out io.Writer) {
+	fmt.Fprintf(out, "Usage: rbacctl <command> [flags]\n\nCommands:\n")
+
+	var names []string
+	for name := range Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-12s %s\n", name, Commands[name].Description)
+	}
+}
+
+// getConfig returns a config to talk to the apiserver
+func getConfig() (*rest.Config, error) {
+	return config.GetConfig()
+}
+
+// newClient returns a client of the apiserver knowing the types of the operator
+func newClient() (client.Client, error) {
+	cfg, err := getConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: scheme.Scheme})
+}
+
+// loadGroupPermission reads a GroupPermission either from file, or from the cluster when file is empty
+func loadGroupPermission(file, namespace, name string) (*managedv1alpha1.GroupPermission, error) {
+	groupPermission := &managedv1alpha1.GroupPermission{}
+
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(groupPermission); err != nil {
+			return nil, fmt.Errorf("unable to decode %s: %v", file, err)
+		}
+		return groupPermission, nil
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("one of -f or --name is required")
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, groupPermission)
+	if err != nil {
+		return nil, err
+	}
+	return groupPermission, nil
+}
@@ -0,0 +1,164 @@
+package rbacctl
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	grouppermissionwebhook "github.com/openshift/rbac-permissions-operator/pkg/webhook/grouppermission"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func init() {
+	Commands["validate"] = Command{
+		Description: "Validate GroupPermission files offline with the checks of the admission webhook",
+		Run:         runValidate,
+	}
+}
+
+// validationResult is the outcome of the validation of a single GroupPermission
+type validationResult struct {
+	file string
+	name string
+	// reason of the webhook check rejecting the GroupPermission, empty when it is valid
+	reason   string
+	err      error
+	warnings []string
+}
+
+// runValidate validates the GroupPermissions of the files with the checks of the webhook that do not read
+// the cluster, and fails when one is rejected
+func runValidate(args []string, out io.Writer) error {
+	flags := pflag.NewFlagSet("validate", pflag.ContinueOnError)
+	files := flags.StringSliceP("filename", "f", nil, "Files containing GroupPermissions, as YAML or JSON documents")
+	configFile := flags.String("config", "", "File containing the ConfigMap of the operator configuration, the defaults are used when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(*files) == 0 {
+		return fmt.Errorf("-f is required")
+	}
+
+	operatorConfig := operatorconfig.DefaultOperatorConfig()
+	if *configFile != "" {
+		var err error
+		operatorConfig, err = loadOperatorConfig(*configFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	var results []validationResult
+	for _, file := range *files {
+		groupPermissions, err := loadGroupPermissions(file)
+		if err != nil {
+			return err
+		}
+		for _, groupPermission := range groupPermissions {
+			result := validateGroupPermission(groupPermission, operatorConfig)
+			result.file = file
+			results = append(results, result)
+		}
+	}
+	return printValidationResults(out, results)
+}
+
+// validateGroupPermission validates groupPermission as created with operatorConfig. Warnings are
+// returned for the spec the webhook records warnings about and for the protected Namespaces of
+// operatorConfig a Permission matches, in which no RoleBinding is created.
+func validateGroupPermission(groupPermission *managedv1alpha1.GroupPermission, operatorConfig *operatorconfig.OperatorConfig) validationResult {
+	result := validationResult{name: groupPermission.Name}
+	if groupPermission.Namespace != "" {
+		result.name = groupPermission.Namespace + "/" + groupPermission.Name
+	}
+
+	validated := groupPermission.DeepCopy()
+	result.reason, result.err = grouppermissionwebhook.ValidateSpec(validated, nil, operatorConfig)
+	if result.err != nil {
+		return result
+	}
+
+	result.warnings = utility.ServiceAccountGroupWarnings(&validated.Spec)
+	for i, permission := range validated.Spec.Permissions {
+		matcher := utility.PermissionNamespaceMatcher(permission)
+		for _, namespace := range operatorConfig.ProtectedNamespaces {
+			if matcher.Matches(namespace) {
+				result.warnings = append(result.warnings, fmt.Sprintf("permissions[%d] matches the protected Namespace %s, no RoleBinding is created in it", i, namespace))
+			}
+		}
+	}
+	return result
+}
+
+// printValidationResults prints the result of each GroupPermission to out, and returns an error when
+// one is invalid
+func printValidationResults(out io.Writer, results []validationResult) error {
+	invalid := 0
+	for _, result := range results {
+		if result.err != nil {
+			invalid++
+			fmt.Fprintf(out, "%s: GroupPermission %s is invalid (%s): %v\n", result.file, result.name, result.reason, result.err)
+			continue
+		}
+		fmt.Fprintf(out, "%s: GroupPermission %s is valid\n", result.file, result.name)
+		for _, warning := range result.warnings {
+			fmt.Fprintf(out, "%s: GroupPermission %s: warning: %s\n", result.file, result.name, warning)
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d GroupPermissions are invalid", invalid, len(results))
+	}
+	return nil
+}
+
+// loadGroupPermissions reads the GroupPermissions of the YAML or JSON documents of file, the documents
+// of other kinds are skipped
+func loadGroupPermissions(file string) ([]*managedv1alpha1.GroupPermission, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var groupPermissions []*managedv1alpha1.GroupPermission
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		document := map[string]interface{}{}
+		if err := decoder.Decode(&document); err != nil {
+			if err == io.EOF {
+				return groupPermissions, nil
+			}
+			return nil, fmt.Errorf("unable to decode %s: %v", file, err)
+		}
+		if document["kind"] != "GroupPermission" {
+			continue
+		}
+		groupPermission := &managedv1alpha1.GroupPermission{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(document, groupPermission); err != nil {
+			return nil, fmt.Errorf("unable to decode %s: %v", file, err)
+		}
+		groupPermissions = append(groupPermissions, groupPermission)
+	}
+}
+
+// loadOperatorConfig reads the operator configuration from the ConfigMap of file
+func loadOperatorConfig(file string) (*operatorconfig.OperatorConfig, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	configMap := &corev1.ConfigMap{}
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(configMap); err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %v", file, err)
+	}
+	return operatorconfig.OperatorConfigFromConfigMap(configMap)
+}
@@ -0,0 +1,102 @@
+package rbacctl
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validateGroupPermissions = `apiVersion: managed.openshift.io/v1alpha1
+kind: GroupPermission
+metadata:
+  name: valid
+  namespace: openshift-rbac-permissions-operator
+spec:
+  groupName: team-a
+  permissions:
+  - clusterRoleName: admin
+    namespacesAllowedRegex: "^(team-a-.*|kube-system)$"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: skipped
+---
+apiVersion: managed.openshift.io/v1alpha1
+kind: GroupPermission
+metadata:
+  name: invalid
+  namespace: openshift-rbac-permissions-operator
+spec:
+  groupName: team-b
+  permissions:
+  - clusterRoleName: admin
+    namespacesAllowedRegex: "^(team-b"
+`
+
+const validateOperatorConfig = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: rbac-permissions-operator
+data:
+  protected_namespaces: kube-system
+`
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	file := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return file
+}
+
+func TestRunValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rbacctl-validate")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := writeTestFile(t, dir, "grouppermissions.yaml", validateGroupPermissions)
+	config := writeTestFile(t, dir, "config.yaml", validateOperatorConfig)
+
+	out := &bytes.Buffer{}
+	err = runValidate([]string{"-f", file, "--config", config}, out)
+	if err == nil || err.Error() != "1 of 2 GroupPermissions are invalid" {
+		t.Errorf("runValidate() error = %v, expected 1 of 2 GroupPermissions are invalid", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	expected := []string{
+		file + ": GroupPermission openshift-rbac-permissions-operator/valid is valid",
+		file + ": GroupPermission openshift-rbac-permissions-operator/valid: warning: permissions[0] matches the protected Namespace kube-system, no RoleBinding is created in it",
+		file + ": GroupPermission openshift-rbac-permissions-operator/invalid is invalid (regex): ",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("runValidate() printed %q, expected %d lines", out.String(), len(expected))
+	}
+	for i := range expected {
+		if !strings.HasPrefix(lines[i], expected[i]) {
+			t.Errorf("runValidate() line %d = %q, expected prefix %q", i, lines[i], expected[i])
+		}
+	}
+}
+
+func TestRunValidateDefaultConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rbacctl-validate")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := writeTestFile(t, dir, "grouppermission.yaml", strings.Split(validateGroupPermissions, "---")[0])
+
+	out := &bytes.Buffer{}
+	if err := runValidate([]string{"-f", file}, out); err != nil {
+		t.Errorf("runValidate() error = %v, expected none", err)
+	}
+	if strings.Contains(out.String(), "warning") {
+		t.Errorf("runValidate() printed %q, expected no warning without protected Namespaces", out.String())
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package readonly runs the operator without writing RBAC objects and Groups, so it can be introduced
+// on a cluster while only reporting, in statuses, events, metrics and logs, what it would change.
+package readonly
+
+import (
+	"context"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("readonly")
+
+// guardedGroups are the API groups of the objects not written in read-only mode
+var guardedGroups = map[string]bool{
+	rbacv1.GroupName:    true,
+	"user.openshift.io": true,
+}
+
+// enabled is whether the operator runs in read-only mode, set with Enable before the clients are created
+var enabled bool
+
+// Enable turns on the read-only mode of the operator
+func Enable() {
+	enabled = true
+}
+
+// Disable turns off the read-only mode of the operator, for the tests of the clients wrapped by Wrap
+func Disable() {
+	enabled = false
+}
+
+// Enabled returns whether the operator runs in read-only mode
+func Enabled() bool {
+	return enabled
+}
+
+// Wrap returns c, skipping the writes to RBAC objects and Groups in read-only mode. The GroupVersionKind
+// of typed objects is found in scheme.
+func Wrap(c client.Client, scheme *runtime.Scheme) client.Client {
+	if !enabled {
+		return c
+	}
+	return &guardedClient{Client: c, scheme: scheme}
+}
+
+// NewClient creates the client of the manager like the default one, reading from the cache and
+// writing to the apiserver, wrapped by Wrap
+func NewClient(cache cache.Cache, config *rest.Config, options client.Options) (client.Client, error) {
+	c, err := client.New(config, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return Wrap(&client.DelegatingClient{
+		Reader: &client.DelegatingReader{
+			CacheReader:  cache,
+			ClientReader: c,
+		},
+		Writer:       c,
+		StatusClient: c,
+	}, options.Scheme), nil
+}
+
+// guardedClient skips the writes to objects of the guarded API groups, reporting them as successful.
+// Reads and status updates are passed through.
+type guardedClient struct {
+	client.Client
+	scheme *runtime.Scheme
+}
+
+// Create implements client.Writer
+func (c *guardedClient) Create(ctx context.Context, obj runtime.Object) error {
+	if c.skip(obj, "create") {
+		return nil
+	}
+	return c.Client.Create(ctx, obj)
+}
+
+// Update implements client.Writer
+func (c *guardedClient) Update(ctx context.Context, obj runtime.Object) error {
+	if c.skip(obj, "update") {
+		return nil
+	}
+	return c.Client.Update(ctx, obj)
+}
+
+// Delete implements client.Writer
+func (c *guardedClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOptionFunc) error {
+	if c.skip(obj, "delete") {
+		return nil
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// skip returns whether the write verb of obj is skipped, and records it when it is
+func (c *guardedClient) skip(obj runtime.Object, verb string) bool {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil || !guardedGroups[gvk.Group] {
+		return false
+	}
+
+	logger := log.WithValues("Kind", gvk.Kind, "Verb", verb)
+	if accessor, err := meta.Accessor(obj); err == nil {
+		logger = logger.WithValues("Namespace", accessor.GetNamespace(), "Name", accessor.GetName())
+	}
+	logger.Info("Skipped write in read-only mode")
+	localmetrics.IncReadOnlySkippedWrites(gvk.Kind, verb)
+	return true
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readonly
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestWrap tests the Wrap function
+// given: a client wrapped before and after read-only mode is enabled
+// expected: the client unchanged until enabled, then writes to RBAC objects and Groups skipped without error
+func TestWrap(t *testing.T) {
+	defer func() { enabled = false }()
+
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "admin-sre"},
+		RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+	}
+	fakeClient := fake.NewFakeClient(binding)
+	if c := Wrap(fakeClient, scheme.Scheme); c != fakeClient {
+		t.Fatalf("expected the client unchanged outside of read-only mode")
+	}
+
+	Enable()
+	c := Wrap(fakeClient, scheme.Scheme)
+
+	if err := c.Delete(context.TODO(), binding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: "admin-sre"}, &rbacv1.ClusterRoleBinding{}); err != nil {
+		t.Errorf("expected the ClusterRoleBinding not to be deleted, got %v", err)
+	}
+
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "admin-sre"},
+		RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+	}
+	if err := c.Create(context.TODO(), roleBinding); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "team-a", Name: "admin-sre"}, &rbacv1.RoleBinding{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the RoleBinding not to be created, got %v", err)
+	}
+
+	group := &unstructured.Unstructured{}
+	group.SetAPIVersion("user.openshift.io/v1")
+	group.SetKind("Group")
+	group.SetName("sre")
+	if err := c.Create(context.TODO(), group); err != nil {
+		t.Errorf("expected the creation of the Group to be skipped, got %v", err)
+	}
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "rbac-permissions-operator", Name: "inventory"}}
+	if err := c.Create(context.TODO(), configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("expected the ConfigMap to be created, got %v", err)
+	}
+}
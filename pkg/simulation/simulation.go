@@ -0,0 +1,228 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simulation serves the plan of candidate GroupPermissions computed against the live state
+// of the cluster, so they can be reviewed by users who are not allowed to write RBAC objects.
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/controller/grouppermission"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("simulation")
+
+const (
+	// Path is the path the simulations are served on
+	Path = "/simulate"
+	// Subresource is the subresource of grouppermissions a user must be allowed to get in the namespace
+	// of a candidate GroupPermission to simulate it
+	Subresource = "simulation"
+	// maxRequestBytes is the largest candidate GroupPermission accepted
+	maxRequestBytes = 1 << 20
+	// shutdownTimeout is how long the requests in flight are waited for when stopping
+	shutdownTimeout = 5 * time.Second
+	// certName and keyName are the files of the serving certificate and key in the cert directory,
+	// named as those of the webhook server
+	certName = "cert.pem"
+	keyName  = "key.pem"
+)
+
+// Response is the plan of a candidate GroupPermission
+type Response struct {
+	// Name of the GroupPermission, as namespace/name
+	Name string `json:"name"`
+	// Drift is the changes applying the GroupPermission would make to its bindings
+	Drift *grouppermission.Drift `json:"drift"`
+}
+
+// Server serves the simulations of candidate GroupPermissions to users authenticated with a bearer
+// token and allowed to get the simulation subresource of grouppermissions in their namespace
+type Server struct {
+	client    client.Client
+	address   string
+	certDir   string
+	namespace string
+	now       func() time.Time
+}
+
+// blank assignment to verify that Server implements manager.Runnable
+var _ manager.Runnable = &Server{}
+
+// NewServer returns a Server listening on address and reading the cluster with c, the candidate
+// GroupPermissions without a namespace are simulated in namespace. c must read from the apiserver
+// directly so the simulations use the live state. The Server serves TLS with the cert.pem and key.pem
+// of certDir, or plain HTTP without certDir, which is only accepted on a loopback address since the
+// requests carry bearer tokens.
+func NewServer(c client.Client, address, certDir, namespace string) (*Server, error) {
+	if certDir == "" && !isLoopback(address) {
+		return nil, fmt.Errorf("simulations served on %s require a cert directory, or a loopback address", address)
+	}
+	return &Server{client: c, address: address, certDir: certDir, namespace: namespace, now: time.Now}, nil
+}
+
+// isLoopback returns whether address only listens on the loopback interface
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Start implements manager.Runnable, it serves the simulations until stop is closed
+func (s *Server) Start(stop <-chan struct{}) error {
+	mux := http.NewServeMux()
+	mux.Handle(Path, s)
+	server := &http.Server{Addr: s.address, Handler: mux}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Info("Serving simulations", "Address", s.address, "TLS", s.certDir != "")
+		if s.certDir == "" {
+			errs <- server.ListenAndServe()
+			return
+		}
+		errs <- server.ListenAndServeTLS(path.Join(s.certDir, certName), path.Join(s.certDir, keyName))
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-stop:
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return server.Shutdown(ctx)
+	}
+}
+
+// ServeHTTP implements http.Handler, it simulates the GroupPermission posted as JSON
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := req.Context()
+
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == req.Header.Get("Authorization") {
+		http.Error(w, "a bearer token is required", http.StatusUnauthorized)
+		return
+	}
+	user, err := s.authenticate(ctx, token)
+	if err != nil {
+		log.Error(err, "Failed to review the token")
+		http.Error(w, "failed to review the token", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	groupPermission := &managedv1alpha1.GroupPermission{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRequestBytes)).Decode(groupPermission); err != nil {
+		http.Error(w, fmt.Sprintf("invalid GroupPermission: %v", err), http.StatusBadRequest)
+		return
+	}
+	if groupPermission.Namespace == "" {
+		groupPermission.Namespace = s.namespace
+	}
+	// a candidate is simulated as it would be applied, not as it is being deleted
+	groupPermission.DeletionTimestamp = nil
+
+	allowed, err := s.authorize(ctx, user, groupPermission.Namespace)
+	if err != nil {
+		log.Error(err, "Failed to review the access", "User", user.Username)
+		http.Error(w, "failed to review the access", http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, fmt.Sprintf("%s cannot get grouppermissions/%s in namespace %s", user.Username, Subresource, groupPermission.Namespace), http.StatusForbidden)
+		return
+	}
+
+	drift, err := grouppermission.Simulate(ctx, s.client, groupPermission, s.now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to simulate the GroupPermission: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+	log.Info("Simulated GroupPermission", "User", user.Username, "GroupPermission", groupPermission.Namespace+"/"+groupPermission.Name,
+		"Missing", len(drift.Missing), "Changed", len(drift.Changed), "Unexpected", len(drift.Unexpected))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&Response{Name: groupPermission.Namespace + "/" + groupPermission.Name, Drift: drift}); err != nil {
+		log.Error(err, "Failed to write the simulation")
+	}
+}
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+
+// authenticate returns the user token authenticates, nil when it is not valid
+func (s *Server) authenticate(ctx context.Context, token string) (*authenticationv1.UserInfo, error) {
+	review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := s.client.Create(ctx, review); err != nil {
+		return nil, err
+	}
+	if !review.Status.Authenticated {
+		return nil, nil
+	}
+	return &review.Status.User, nil
+}
+
+// authorize returns whether user is allowed to get the simulation subresource of grouppermissions in namespace
+func (s *Server) authorize(ctx context.Context, user *authenticationv1.UserInfo, namespace string) (bool, error) {
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        "get",
+				Group:       managedv1alpha1.SchemeGroupVersion.Group,
+				Resource:    "grouppermissions",
+				Subresource: Subresource,
+			},
+		},
+	}
+	if err := s.client.Create(ctx, review); err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
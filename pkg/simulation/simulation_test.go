@@ -0,0 +1,143 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/controller/grouppermission"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// reviewClient answers the TokenReviews and SubjectAccessReviews like an apiserver knowing a single
+// token, of the reviewer user, allowed in the allowed namespace only
+type reviewClient struct {
+	client.Client
+	allowed string
+}
+
+func (c *reviewClient) Create(ctx context.Context, obj runtime.Object) error {
+	switch review := obj.(type) {
+	case *authenticationv1.TokenReview:
+		if review.Spec.Token == "reviewer-token" {
+			review.Status.Authenticated = true
+			review.Status.User = authenticationv1.UserInfo{Username: "reviewer", Groups: []string{"reviewers"}}
+		}
+		return nil
+	case *authorizationv1.SubjectAccessReview:
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = review.Spec.User == "reviewer" && attributes.Subresource == Subresource && attributes.Namespace == c.allowed
+		return nil
+	}
+	return c.Client.Create(ctx, obj)
+}
+
+func mockNamespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+// TestServeHTTP tests the ServeHTTP function of Server
+// given: requests without a token, with an invalid token, for a namespace the reviewer is not allowed in,
+// with an invalid GroupPermission and with a valid one
+// expected: the valid request only is answered with the plan of the GroupPermission, the other ones
+// with an error status
+func TestServeHTTP(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+
+	c := &reviewClient{
+		Client:  fake.NewFakeClient(mockNamespace("team-a"), mockNamespace("default"), &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "admin"}}),
+		allowed: "rbac-permissions-operator",
+	}
+	server, err := NewServer(c, "127.0.0.1:0", "", "rbac-permissions-operator")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.now = func() time.Time { return time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	valid := `{"metadata":{"name":"team"},"spec":{"groupName":"team","permissions":[{"clusterRoleName":"admin","namespacesAllowedRegex":"^team-.*"}]}}`
+	var tests = []struct {
+		label    string
+		method   string
+		token    string
+		body     string
+		expected int
+	}{
+		{"get", http.MethodGet, "reviewer-token", valid, http.StatusMethodNotAllowed},
+		{"no token", http.MethodPost, "", valid, http.StatusUnauthorized},
+		{"invalid token", http.MethodPost, "other-token", valid, http.StatusUnauthorized},
+		{"forbidden namespace", http.MethodPost, "reviewer-token", strings.Replace(valid, `"name":"team"`, `"name":"team","namespace":"other"`, 1), http.StatusForbidden},
+		{"invalid json", http.MethodPost, "reviewer-token", `{"spec":`, http.StatusBadRequest},
+		{"invalid spec", http.MethodPost, "reviewer-token", `{"metadata":{"name":"team"},"spec":{"groupName":"team","permissions":[{}]}}`, http.StatusUnprocessableEntity},
+		{"valid", http.MethodPost, "reviewer-token", valid, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, Path, strings.NewReader(test.body))
+		if test.token != "" {
+			req.Header.Set("Authorization", "Bearer "+test.token)
+		}
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+
+		if recorder.Code != test.expected {
+			t.Errorf("Mismatch for status of %s. Expected(%d), Found(%d): %s", test.label, test.expected, recorder.Code, recorder.Body.String())
+			continue
+		}
+		if test.expected != http.StatusOK {
+			continue
+		}
+		response := &Response{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := &Response{
+			Name:  "rbac-permissions-operator/team",
+			Drift: &grouppermission.Drift{Missing: []string{"RoleBinding team-a/admin-team"}},
+		}
+		if !reflect.DeepEqual(response, expected) {
+			t.Errorf("Mismatch for response. Expected(%+v), Found(%+v)", expected, response)
+		}
+	}
+}
+
+// TestNewServer tests the NewServer function
+// given: loopback and non-loopback addresses, with and without a cert directory
+// expected: plain HTTP is only accepted on a loopback address
+func TestNewServer(t *testing.T) {
+	var tests = []struct {
+		address  string
+		certDir  string
+		accepted bool
+	}{
+		{"127.0.0.1:8443", "", true},
+		{"[::1]:8443", "", true},
+		{"localhost:8443", "", true},
+		{":8443", "", false},
+		{"0.0.0.0:8443", "", false},
+		{"10.0.0.1:8443", "", false},
+		{":8443", "/tmp/cert", true},
+	}
+	for _, test := range tests {
+		_, err := NewServer(nil, test.address, test.certDir, "rbac-permissions-operator")
+		if accepted := err == nil; accepted != test.accepted {
+			t.Errorf("Mismatch for %s with cert directory %q. Expected(%t), Found(%t, %v)", test.address, test.certDir, test.accepted, accepted, err)
+		}
+	}
+}
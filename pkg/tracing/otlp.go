@@ -0,0 +1,278 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables configuring the export, those of the OpenTelemetry SDKs
+const (
+	EnvTracesEndpoint = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	EnvEndpoint       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvTracesHeaders  = "OTEL_EXPORTER_OTLP_TRACES_HEADERS"
+	EnvHeaders        = "OTEL_EXPORTER_OTLP_HEADERS"
+	EnvServiceName    = "OTEL_SERVICE_NAME"
+	EnvSDKDisabled    = "OTEL_SDK_DISABLED"
+)
+
+// tracesPath is the path of the traces appended to the endpoint of every signal
+const tracesPath = "/v1/traces"
+
+// scopeName is the instrumentation scope of the spans
+const scopeName = "github.com/openshift/rbac-permissions-operator/pkg/tracing"
+
+// OTLPExporter exports spans to the OTLP/HTTP traces Endpoint of a collector, encoded as JSON
+type OTLPExporter struct {
+	HTTPClient  *http.Client
+	Endpoint    string
+	Headers     map[string]string
+	ServiceName string
+	// ServiceVersion is reported as service.version when set
+	ServiceVersion string
+}
+
+// Export implements Exporter
+func (e *OTLPExporter) Export(spans []*Span) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.Headers {
+		req.Header.Set(key, value)
+	}
+
+	httpClient := e.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// drained so the connection is reused
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("OTLP endpoint returned %s for %d spans", resp.Status, len(spans))
+	}
+	return nil
+}
+
+// exportRequest is the ExportTraceServiceRequest of OTLP, in its JSON encoding
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID      string     `json:"traceId"`
+	SpanID       string     `json:"spanId"`
+	ParentSpanID string     `json:"parentSpanId,omitempty"`
+	Name         string     `json:"name"`
+	Kind         SpanKind   `json:"kind"`
+	StartTime    string     `json:"startTimeUnixNano"`
+	EndTime      string     `json:"endTimeUnixNano"`
+	Attributes   []keyValue `json:"attributes,omitempty"`
+	Status       status     `json:"status"`
+}
+
+// status codes of OTLP
+const (
+	statusUnset = 0
+	statusError = 2
+)
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+// anyValue holds one of its values. 64 bit integers are encoded as strings, like protobuf does in JSON.
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// request returns the export request of spans
+func (e *OTLPExporter) request(spans []*Span) exportRequest {
+	resourceAttributes := []keyValue{attributeValue(String("service.name", e.ServiceName))}
+	if e.ServiceVersion != "" {
+		resourceAttributes = append(resourceAttributes, attributeValue(String("service.version", e.ServiceVersion)))
+	}
+
+	encoded := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		start, end, attributes, err := span.snapshot()
+		s := otlpSpan{
+			TraceID:   hexID(span.TraceID[:]),
+			SpanID:    hexID(span.SpanID[:]),
+			Name:      span.Name,
+			Kind:      span.Kind,
+			StartTime: unixNano(start),
+			EndTime:   unixNano(end),
+			Status:    status{Code: statusUnset},
+		}
+		if span.ParentID != [8]byte{} {
+			s.ParentSpanID = hexID(span.ParentID[:])
+		}
+		for _, attr := range attributes {
+			s.Attributes = append(s.Attributes, attributeValue(attr))
+		}
+		if err != "" {
+			s.Status = status{Code: statusError, Message: err}
+		}
+		encoded = append(encoded, s)
+	}
+
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   resource{Attributes: resourceAttributes},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: scopeName}, Spans: encoded}},
+	}}}
+}
+
+// attributeValue returns attr as an OTLP key value, values of other types are formatted as strings
+func attributeValue(attr Attribute) keyValue {
+	value := anyValue{}
+	switch v := attr.Value.(type) {
+	case string:
+		value.StringValue = &v
+	case bool:
+		value.BoolValue = &v
+	case int:
+		i := strconv.Itoa(v)
+		value.IntValue = &i
+	case int64:
+		i := strconv.FormatInt(v, 10)
+		value.IntValue = &i
+	case float64:
+		value.DoubleValue = &v
+	default:
+		s := fmt.Sprint(v)
+		value.StringValue = &s
+	}
+	return keyValue{Key: attr.Key, Value: value}
+}
+
+// hexID returns id hex encoded, as OTLP/JSON expects
+func hexID(id []byte) string {
+	return hex.EncodeToString(id)
+}
+
+// unixNano returns t in nanoseconds since the epoch, as a string like protobuf encodes uint64 in JSON
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// ExporterFromEnvironment returns the OTLPExporter configured by the environment variables of the
+// OpenTelemetry SDKs, or nil when no endpoint is set or the SDK is disabled. defaultServiceName is the
+// service name when OTEL_SERVICE_NAME is not set.
+func ExporterFromEnvironment(defaultServiceName string) (*OTLPExporter, error) {
+	if disabled, _ := strconv.ParseBool(os.Getenv(EnvSDKDisabled)); disabled {
+		return nil, nil
+	}
+
+	endpoint := os.Getenv(EnvTracesEndpoint)
+	if endpoint == "" {
+		// the endpoint of every signal is a base URL, the path of the traces is appended to it
+		base := os.Getenv(EnvEndpoint)
+		if base == "" {
+			return nil, nil
+		}
+		endpoint = strings.TrimSuffix(base, "/") + tracesPath
+	}
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q, must be an http or https URL", endpoint)
+	}
+
+	headers, err := parseHeaders(os.Getenv(EnvHeaders))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %v", EnvHeaders, err)
+	}
+	tracesHeaders, err := parseHeaders(os.Getenv(EnvTracesHeaders))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %v", EnvTracesHeaders, err)
+	}
+	for key, value := range tracesHeaders {
+		headers[key] = value
+	}
+
+	serviceName := os.Getenv(EnvServiceName)
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	return &OTLPExporter{
+		HTTPClient:  &http.Client{Timeout: 10 * time.Second},
+		Endpoint:    endpoint,
+		Headers:     headers,
+		ServiceName: serviceName,
+	}, nil
+}
+
+// parseHeaders parses a comma separated list of key=value headers
+func parseHeaders(value string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, header := range strings.Split(value, ",") {
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		parts := strings.SplitN(header, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("header %q is not a key=value pair", header)
+		}
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return headers, nil
+}
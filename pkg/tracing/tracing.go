@@ -0,0 +1,291 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing records the spans of the reconciles and of their API requests, and exports them to an
+// OpenTelemetry collector over OTLP/HTTP with JSON encoding.
+//
+// Spans are started with StartSpan from the Tracer set with SetTracer. Without a Tracer, StartSpan
+// returns a nil Span whose methods do nothing, so tracing costs nothing when it is not configured.
+package tracing
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("tracing")
+
+const (
+	// DefaultFlushInterval is the interval between two exports of the ended spans
+	DefaultFlushInterval = 5 * time.Second
+	// DefaultMaxQueueSize is the number of ended spans kept until the next export, further spans are dropped
+	DefaultMaxQueueSize = 2048
+)
+
+// SpanKind is the kind of a span, its values are those of OTLP
+type SpanKind int
+
+const (
+	// SpanKindInternal is an operation internal to the operator, such as a reconcile
+	SpanKindInternal SpanKind = 1
+	// SpanKindClient is a request to a remote service, such as the apiserver
+	SpanKindClient SpanKind = 3
+)
+
+// Attribute is a key and value describing a span. Values are strings, bools, ints, int64s or float64s.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns the attribute key set to the string value
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns the attribute key set to the int value
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Bool returns the attribute key set to the bool value
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is an operation of the operator, exported once it has ended
+type Span struct {
+	tracer *Tracer
+
+	TraceID  [16]byte
+	SpanID   [8]byte
+	ParentID [8]byte
+	Name     string
+	Kind     SpanKind
+
+	mutex      sync.Mutex
+	start      time.Time
+	end        time.Time
+	attributes []Attribute
+	err        string
+}
+
+// SetAttributes adds attrs to s, replacing the attributes of the same keys
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, attr := range attrs {
+		replaced := false
+		for i := range s.attributes {
+			if s.attributes[i].Key == attr.Key {
+				s.attributes[i] = attr
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.attributes = append(s.attributes, attr)
+		}
+	}
+}
+
+// RecordError marks s as failed with err, a nil err records nothing
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.err = err.Error()
+}
+
+// End ends s and queues it for export, later calls do nothing
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	if !s.end.IsZero() {
+		s.mutex.Unlock()
+		return
+	}
+	s.end = s.tracer.currentTime()
+	s.mutex.Unlock()
+	s.tracer.enqueue(s)
+}
+
+// Attributes returns the attributes of s
+func (s *Span) Attributes() []Attribute {
+	_, _, attributes, _ := s.snapshot()
+	return attributes
+}
+
+// ErrorMessage returns the error recorded by s, empty when none was
+func (s *Span) ErrorMessage() string {
+	_, _, _, err := s.snapshot()
+	return err
+}
+
+// snapshot returns the times, attributes and error of s
+func (s *Span) snapshot() (start, end time.Time, attributes []Attribute, err string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.start, s.end, append([]Attribute(nil), s.attributes...), s.err
+}
+
+// Exporter sends ended spans to a tracing backend
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+// Tracer starts spans and exports them in batches with its Exporter. It is a manager.Runnable exporting
+// the spans every FlushInterval until it is stopped.
+type Tracer struct {
+	Exporter      Exporter
+	FlushInterval time.Duration
+	MaxQueueSize  int
+
+	now     func() time.Time
+	mutex   sync.Mutex
+	queue   []*Span
+	dropped int
+}
+
+// NewTracer returns a Tracer exporting its spans with exporter
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{
+		Exporter:      exporter,
+		FlushInterval: DefaultFlushInterval,
+		MaxQueueSize:  DefaultMaxQueueSize,
+		now:           time.Now,
+	}
+}
+
+var (
+	globalMutex  sync.RWMutex
+	globalTracer *Tracer
+)
+
+// SetTracer sets the Tracer of the spans started by StartSpan, spans are not recorded when nil
+func SetTracer(t *Tracer) {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+	globalTracer = t
+}
+
+// Enabled returns whether spans are recorded
+func Enabled() bool {
+	globalMutex.RLock()
+	defer globalMutex.RUnlock()
+	return globalTracer != nil
+}
+
+// StartSpan starts a span named name, the child of parent or the root of a new trace when parent is nil.
+// It returns nil when no Tracer is set.
+func StartSpan(parent *Span, name string, kind SpanKind, attrs ...Attribute) *Span {
+	tracer := (*Tracer)(nil)
+	if parent != nil {
+		tracer = parent.tracer
+	} else {
+		globalMutex.RLock()
+		tracer = globalTracer
+		globalMutex.RUnlock()
+	}
+	if tracer == nil {
+		return nil
+	}
+
+	span := &Span{tracer: tracer, Name: name, Kind: kind, start: tracer.currentTime()}
+	if parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+	} else {
+		randomID(span.TraceID[:])
+	}
+	randomID(span.SpanID[:])
+	span.SetAttributes(attrs...)
+	return span
+}
+
+// randomID fills id with random bytes, IDs must not be all zeros
+func randomID(id []byte) {
+	for {
+		if _, err := rand.Read(id); err != nil {
+			panic(err)
+		}
+		for _, b := range id {
+			if b != 0 {
+				return
+			}
+		}
+	}
+}
+
+// currentTime returns the time spans start and end at
+func (t *Tracer) currentTime() time.Time {
+	if t.now == nil {
+		return time.Now()
+	}
+	return t.now()
+}
+
+// enqueue queues span for the next export, it is dropped when the queue is full
+func (t *Tracer) enqueue(span *Span) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.MaxQueueSize > 0 && len(t.queue) >= t.MaxQueueSize {
+		t.dropped++
+		return
+	}
+	t.queue = append(t.queue, span)
+}
+
+// Flush exports the spans ended since the last export, the spans of a failed export are dropped
+func (t *Tracer) Flush() error {
+	t.mutex.Lock()
+	spans, dropped := t.queue, t.dropped
+	t.queue, t.dropped = nil, 0
+	t.mutex.Unlock()
+
+	if dropped > 0 {
+		log.Info("Dropped spans, the export queue was full", "Count", dropped)
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	return t.Exporter.Export(spans)
+}
+
+// Start exports the spans every FlushInterval until stop is closed, then exports the remaining spans
+func (t *Tracer) Start(stop <-chan struct{}) error {
+	interval := t.FlushInterval
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	flush := func() {
+		if err := t.Flush(); err != nil {
+			log.Error(err, "Failed to export spans")
+		}
+	}
+	wait.Until(flush, interval, stop)
+	flush()
+	return nil
+}
@@ -0,0 +1,227 @@
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordingExporter records the spans exported
+type recordingExporter struct {
+	spans []*Span
+}
+
+func (e *recordingExporter) Export(spans []*Span) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func TestStartSpanWithoutTracer(t *testing.T) {
+	SetTracer(nil)
+	span := StartSpan(nil, "reconcile", SpanKindInternal, String("key", "value"))
+	if span != nil {
+		t.Fatalf("got span %+v, want nil", span)
+	}
+	// the methods of a nil span do nothing
+	span.SetAttributes(Int("count", 1))
+	span.RecordError(errors.New("failed"))
+	span.End()
+	if child := StartSpan(span, "get", SpanKindClient); child != nil {
+		t.Errorf("got child %+v, want nil", child)
+	}
+}
+
+func TestSpans(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer(exporter)
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	root := StartSpan(nil, "reconcile", SpanKindInternal, String("name", "a"))
+	child := StartSpan(root, "get", SpanKindClient)
+	child.RecordError(errors.New("not found"))
+	child.End()
+	root.SetAttributes(String("name", "b"), Int("count", 2))
+	root.End()
+	root.End()
+
+	if err := tracer.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exporter.spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(exporter.spans))
+	}
+	if child.TraceID != root.TraceID || child.ParentID != root.SpanID {
+		t.Errorf("child %x/%x is not in the trace of root %x/%x", child.TraceID, child.ParentID, root.TraceID, root.SpanID)
+	}
+	if root.ParentID != [8]byte{} {
+		t.Errorf("got root parent %x, want none", root.ParentID)
+	}
+	_, _, attributes, err := root.snapshot()
+	if expected := []Attribute{String("name", "b"), Int("count", 2)}; !reflect.DeepEqual(attributes, expected) {
+		t.Errorf("got attributes %v, want %v", attributes, expected)
+	}
+	if err != "" {
+		t.Errorf("got root error %q, want none", err)
+	}
+	if _, _, _, err := child.snapshot(); err != "not found" {
+		t.Errorf("got child error %q, want %q", err, "not found")
+	}
+
+	// exported spans are not exported again
+	exporter.spans = nil
+	if err := tracer.Flush(); err != nil || len(exporter.spans) != 0 {
+		t.Errorf("got %d spans and error %v, want none", len(exporter.spans), err)
+	}
+}
+
+func TestTracerQueueFull(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer(exporter)
+	tracer.MaxQueueSize = 2
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	for i := 0; i < 3; i++ {
+		StartSpan(nil, "reconcile", SpanKindInternal).End()
+	}
+	if err := tracer.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(exporter.spans) != 2 {
+		t.Errorf("got %d spans, want 2", len(exporter.spans))
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var received exportRequest
+	var contentType, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType, authorization = r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	exporter := &OTLPExporter{Endpoint: server.URL, ServiceName: "rbac-permissions-operator", Headers: map[string]string{"Authorization": "Bearer token"}}
+	tracer := NewTracer(exporter)
+	start := time.Unix(10, 5)
+	tracer.now = func() time.Time { return start }
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	root := StartSpan(nil, "Reconcile GroupPermission", SpanKindInternal, String("phase", "Ready"), Int("namespaces", 3), Bool("converged", true))
+	child := StartSpan(root, "get Namespace", SpanKindClient)
+	child.RecordError(errors.New("not found"))
+	child.End()
+	root.End()
+	if err := tracer.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if contentType != "application/json" || authorization != "Bearer token" {
+		t.Errorf("got Content-Type %q and Authorization %q", contentType, authorization)
+	}
+	if len(received.ResourceSpans) != 1 || len(received.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("got %+v, want a single resource and scope", received)
+	}
+	if name := *received.ResourceSpans[0].Resource.Attributes[0].Value.StringValue; name != "rbac-permissions-operator" {
+		t.Errorf("got service name %q", name)
+	}
+	spans := received.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	exported, parent := spans[0], spans[1]
+	if exported.Name != "get Namespace" || exported.Kind != SpanKindClient || exported.ParentSpanID != parent.SpanID || exported.TraceID != parent.TraceID {
+		t.Errorf("got child %+v of %+v", exported, parent)
+	}
+	if len(parent.TraceID) != 32 || len(parent.SpanID) != 16 || parent.ParentSpanID != "" {
+		t.Errorf("got IDs %q/%q/%q", parent.TraceID, parent.SpanID, parent.ParentSpanID)
+	}
+	if exported.Status != (status{Code: statusError, Message: "not found"}) || parent.Status != (status{Code: statusUnset}) {
+		t.Errorf("got statuses %+v and %+v", exported.Status, parent.Status)
+	}
+	if parent.StartTime != "10000000005" || parent.EndTime != "10000000005" {
+		t.Errorf("got times %s-%s", parent.StartTime, parent.EndTime)
+	}
+	values := map[string]string{}
+	for _, attr := range parent.Attributes {
+		value, _ := json.Marshal(attr.Value)
+		values[attr.Key] = string(value)
+	}
+	expected := map[string]string{"phase": `{"stringValue":"Ready"}`, "namespaces": `{"intValue":"3"}`, "converged": `{"boolValue":true}`}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("got attributes %v, want %v", values, expected)
+	}
+}
+
+func TestOTLPExporterFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tracer := NewTracer(&OTLPExporter{Endpoint: server.URL})
+	SetTracer(tracer)
+	defer SetTracer(nil)
+	StartSpan(nil, "reconcile", SpanKindInternal).End()
+	if err := tracer.Flush(); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestExporterFromEnvironment(t *testing.T) {
+	var tests = []struct {
+		label       string
+		env         map[string]string
+		endpoint    string
+		serviceName string
+		headers     map[string]string
+		err         bool
+	}{
+		{label: "not configured"},
+		{label: "endpoint of every signal", env: map[string]string{EnvEndpoint: "http://collector:4318/"},
+			endpoint: "http://collector:4318/v1/traces", serviceName: "operator", headers: map[string]string{}},
+		{label: "traces endpoint", env: map[string]string{EnvEndpoint: "http://collector:4318", EnvTracesEndpoint: "https://traces/otlp", EnvServiceName: "rbac"},
+			endpoint: "https://traces/otlp", serviceName: "rbac", headers: map[string]string{}},
+		{label: "headers", env: map[string]string{EnvEndpoint: "http://collector:4318", EnvHeaders: "a=1, b=2", EnvTracesHeaders: "b=3"},
+			endpoint: "http://collector:4318/v1/traces", serviceName: "operator", headers: map[string]string{"a": "1", "b": "3"}},
+		{label: "disabled", env: map[string]string{EnvEndpoint: "http://collector:4318", EnvSDKDisabled: "true"}},
+		{label: "invalid endpoint", env: map[string]string{EnvEndpoint: "collector:4317"}, err: true},
+		{label: "invalid headers", env: map[string]string{EnvEndpoint: "http://collector:4318", EnvHeaders: "token"}, err: true},
+	}
+	for _, test := range tests {
+		for _, key := range []string{EnvTracesEndpoint, EnvEndpoint, EnvTracesHeaders, EnvHeaders, EnvServiceName, EnvSDKDisabled} {
+			os.Unsetenv(key)
+		}
+		for key, value := range test.env {
+			os.Setenv(key, value)
+		}
+		exporter, err := ExporterFromEnvironment("operator")
+		if (err != nil) != test.err {
+			t.Errorf("%s: got error %v, want error %v", test.label, err, test.err)
+			continue
+		}
+		if test.endpoint == "" {
+			if exporter != nil {
+				t.Errorf("%s: got exporter %+v, want nil", test.label, exporter)
+			}
+			continue
+		}
+		if exporter == nil || exporter.Endpoint != test.endpoint || exporter.ServiceName != test.serviceName || !reflect.DeepEqual(exporter.Headers, test.headers) {
+			t.Errorf("%s: got exporter %+v, want endpoint %s, service name %s and headers %v", test.label, exporter, test.endpoint, test.serviceName, test.headers)
+		}
+	}
+	for _, key := range []string{EnvTracesEndpoint, EnvEndpoint, EnvTracesHeaders, EnvHeaders, EnvServiceName, EnvSDKDisabled} {
+		os.Unsetenv(key)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DelegableLabel returns the label marking a ClusterRole as delegable to the GroupPermissions of namespace
+func DelegableLabel(namespace string) string {
+	return operatorconfig.DelegableLabelPrefix + namespace
+}
+
+// IsDelegable returns whether clusterRole is labeled as delegable to the GroupPermissions of namespace
+func IsDelegable(clusterRole metav1.Object, namespace string) bool {
+	return clusterRole.GetLabels()[DelegableLabel(namespace)] == "true"
+}
+
+// UndelegableRoles returns the roles granted by the spec of a GroupPermission of namespace that are not
+// delegable to it, as "Kind name" in the order of the spec. Roles are never delegable, their rules are
+// not reviewed, nor are the ClusterRoles getClusterRole returns nil for.
+func UndelegableRoles(spec *managedv1alpha1.GroupPermissionSpec, namespace string, getClusterRole func(clusterRoleName string) (metav1.Object, error)) ([]string, error) {
+	var undelegable []string
+	seen := map[string]bool{}
+	for _, role := range grantedRoles(spec) {
+		if seen[role.kind+" "+role.name] {
+			continue
+		}
+		seen[role.kind+" "+role.name] = true
+		if role.kind == "ClusterRole" {
+			clusterRole, err := getClusterRole(role.name)
+			if err != nil {
+				return nil, err
+			}
+			if clusterRole != nil && IsDelegable(clusterRole, namespace) {
+				continue
+			}
+		}
+		undelegable = append(undelegable, role.kind+" "+role.name)
+	}
+	return undelegable, nil
+}
+
+// grantedRole is a role granted by a GroupPermission spec
+type grantedRole struct {
+	kind string
+	name string
+}
+
+// grantedRoles returns the roles granted by spec in its order
+func grantedRoles(spec *managedv1alpha1.GroupPermissionSpec) []grantedRole {
+	var roles []grantedRole
+	for _, clusterRoleName := range spec.ClusterPermissions {
+		roles = append(roles, grantedRole{kind: "ClusterRole", name: clusterRoleName})
+	}
+	for _, permission := range spec.Permissions {
+		if permission.RoleName != "" {
+			roles = append(roles, grantedRole{kind: "Role", name: permission.RoleName})
+		} else if permission.ClusterRoleName != "" {
+			roles = append(roles, grantedRole{kind: "ClusterRole", name: permission.ClusterRoleName})
+		}
+	}
+	return roles
+}
@@ -0,0 +1,59 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"reflect"
+	"testing"
+
+	api "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUndelegableRoles(t *testing.T) {
+	clusterRoles := map[string]*rbacv1.ClusterRole{
+		"view":  {ObjectMeta: metav1.ObjectMeta{Name: "view", Labels: map[string]string{DelegableLabel("team-a"): "true"}}},
+		"edit":  {ObjectMeta: metav1.ObjectMeta{Name: "edit", Labels: map[string]string{DelegableLabel("team-b"): "true"}}},
+		"admin": {ObjectMeta: metav1.ObjectMeta{Name: "admin"}},
+	}
+	getClusterRole := func(clusterRoleName string) (metav1.Object, error) {
+		if clusterRole, ok := clusterRoles[clusterRoleName]; ok {
+			return clusterRole, nil
+		}
+		return nil, nil
+	}
+	var tests = []struct {
+		label    string
+		spec     api.GroupPermissionSpec
+		expected []string
+	}{
+		{"delegable", api.GroupPermissionSpec{ClusterPermissions: []string{"view"}, Permissions: []api.Permission{{ClusterRoleName: "view"}}}, nil},
+		{"delegable to another namespace", api.GroupPermissionSpec{ClusterPermissions: []string{"edit"}}, []string{"ClusterRole edit"}},
+		{"not labeled", api.GroupPermissionSpec{Permissions: []api.Permission{{ClusterRoleName: "admin"}}}, []string{"ClusterRole admin"}},
+		{"missing", api.GroupPermissionSpec{ClusterPermissions: []string{"missing"}}, []string{"ClusterRole missing"}},
+		{"role", api.GroupPermissionSpec{ClusterPermissions: []string{"view"}, Permissions: []api.Permission{{RoleName: "deployer"}}}, []string{"Role deployer"}},
+	}
+	for _, test := range tests {
+		undelegable, err := UndelegableRoles(&test.spec, "team-a", getClusterRole)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.label, err)
+		}
+		if !reflect.DeepEqual(undelegable, test.expected) {
+			t.Errorf("%s: Mismatch for undelegable roles. Expected(%v), Found(%v)", test.label, test.expected, undelegable)
+		}
+	}
+}
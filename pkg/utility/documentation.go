@@ -0,0 +1,90 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"fmt"
+	"strings"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ValidateTags returns an error when a tag of a GroupPermission is empty, contains a comma, as the tags
+// are recorded comma separated, or is repeated
+func ValidateTags(spec *managedv1alpha1.GroupPermissionSpec) error {
+	seen := map[string]bool{}
+	for _, tag := range spec.Tags {
+		switch {
+		case strings.TrimSpace(tag) == "":
+			return fmt.Errorf("tags cannot be empty")
+		case strings.Contains(tag, ","):
+			return fmt.Errorf("tag %s cannot contain a comma", tag)
+		case seen[tag]:
+			return fmt.Errorf("tag %s is repeated", tag)
+		}
+		seen[tag] = true
+	}
+	return nil
+}
+
+// DocumentationAnnotations returns the annotations recording the Description and the Tags of a
+// GroupPermission, nil when it has neither
+func DocumentationAnnotations(spec *managedv1alpha1.GroupPermissionSpec) map[string]string {
+	var annotations map[string]string
+	for key, value := range map[string]string{
+		operatorconfig.DescriptionAnnotation: spec.Description,
+		operatorconfig.TagsAnnotation:        strings.Join(spec.Tags, ","),
+	} {
+		if value == "" {
+			continue
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[key] = value
+	}
+	return annotations
+}
+
+// SetDocumentationAnnotations records the Description and the Tags of a GroupPermission, when set, as
+// annotations of objectMeta
+func SetDocumentationAnnotations(objectMeta *metav1.ObjectMeta, spec *managedv1alpha1.GroupPermissionSpec) {
+	annotations := DocumentationAnnotations(spec)
+	if annotations == nil {
+		return
+	}
+	if objectMeta.Annotations == nil {
+		objectMeta.Annotations = map[string]string{}
+	}
+	for key, value := range annotations {
+		objectMeta.Annotations[key] = value
+	}
+}
+
+// AuditAnnotations returns the annotations of the events of a GroupPermission, routing them to the team
+// owning it and documenting its permissions, nil when it has none
+func AuditAnnotations(spec *managedv1alpha1.GroupPermissionSpec) map[string]string {
+	annotations := OwnerAnnotations(spec)
+	for key, value := range DocumentationAnnotations(spec) {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[key] = value
+	}
+	return annotations
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	api "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateTags(t *testing.T) {
+	var tests = []struct {
+		label string
+		tags  []string
+		valid bool
+	}{
+		{"no tags", nil, true},
+		{"tags", []string{"pci", "cost-center=42"}, true},
+		{"empty tag", []string{"pci", " "}, false},
+		{"comma", []string{"pci,sox"}, false},
+		{"repeated", []string{"pci", "pci"}, false},
+	}
+
+	for _, test := range tests {
+		err := ValidateTags(&api.GroupPermissionSpec{GroupName: "sre", Tags: test.tags})
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid=%t, got %v", test.label, test.valid, err)
+		}
+	}
+}
+
+func TestAuditAnnotations(t *testing.T) {
+	var tests = []struct {
+		label    string
+		spec     api.GroupPermissionSpec
+		expected map[string]string
+	}{
+		{"none", api.GroupPermissionSpec{GroupName: "sre"}, nil},
+		{"description", api.GroupPermissionSpec{GroupName: "sre", Description: "on call access"},
+			map[string]string{operatorconfig.DescriptionAnnotation: "on call access"}},
+		{"all", api.GroupPermissionSpec{GroupName: "sre", Description: "on call access", Tags: []string{"pci", "sox"}, Notify: &api.Notify{OwnerRef: "#team-sre"}},
+			map[string]string{
+				operatorconfig.OwnerRefAnnotation:    "#team-sre",
+				operatorconfig.DescriptionAnnotation: "on call access",
+				operatorconfig.TagsAnnotation:        "pci,sox",
+			}},
+	}
+
+	for _, test := range tests {
+		if got := AuditAnnotations(&test.spec); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.label, test.expected, got)
+		}
+	}
+}
+
+func TestSetDocumentationAnnotations(t *testing.T) {
+	objectMeta := &metav1.ObjectMeta{Annotations: map[string]string{operatorconfig.JustificationAnnotation: "on call"}}
+
+	SetDocumentationAnnotations(objectMeta, &api.GroupPermissionSpec{GroupName: "sre", Tags: []string{"pci"}})
+
+	expected := map[string]string{
+		operatorconfig.JustificationAnnotation: "on call",
+		operatorconfig.TagsAnnotation:          "pci",
+	}
+	if !reflect.DeepEqual(objectMeta.Annotations, expected) {
+		t.Errorf("expected %v, got %v", expected, objectMeta.Annotations)
+	}
+}
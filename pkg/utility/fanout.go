@@ -0,0 +1,75 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ValidateGroupNameSelector returns an error when a GroupPermission sets both or neither of GroupName
+// and GroupNameSelector, or a GroupNameSelector that is invalid or combined with fields only meaningful
+// for a single Group
+func ValidateGroupNameSelector(spec *managedv1alpha1.GroupPermissionSpec) error {
+	if spec.GroupNameSelector == nil {
+		if spec.GroupName == "" {
+			return fmt.Errorf("one of groupName and groupNameSelector must be set")
+		}
+		return nil
+	}
+	if spec.GroupName != "" {
+		return fmt.Errorf("groupName must not be set with groupNameSelector")
+	}
+	if spec.ServiceAccountsNamespace != "" || spec.CreateGroupIfMissing {
+		return fmt.Errorf("serviceAccountsNamespace and createGroupIfMissing must not be set with groupNameSelector")
+	}
+	if _, err := metav1.LabelSelectorAsSelector(spec.GroupNameSelector); err != nil {
+		return fmt.Errorf("invalid groupNameSelector: %v", err)
+	}
+	return nil
+}
+
+// FanOutSpec returns the spec of the GroupPermission granting groupName the permissions of a
+// GroupPermission with the GroupNameSelector spec
+func FanOutSpec(spec *managedv1alpha1.GroupPermissionSpec, groupName string) managedv1alpha1.GroupPermissionSpec {
+	fanOut := *spec.DeepCopy()
+	fanOut.GroupNameSelector = nil
+	fanOut.GroupName = groupName
+	return fanOut
+}
+
+// FanOutName returns the name of the GroupPermission granting groupName the permissions of the
+// GroupPermission named parentName. Group names that are not valid in a name are replaced by their hash.
+func FanOutName(parentName, groupName string) string {
+	name := parentName + "-" + groupName
+	if len(validation.IsDNS1123Subdomain(name)) == 0 {
+		return name
+	}
+	sum := sha256.Sum256([]byte(groupName))
+	suffix := hex.EncodeToString(sum[:])[:10]
+	prefix := parentName
+	if max := validation.DNS1123SubdomainMaxLength - len(suffix) - 1; len(prefix) > max {
+		prefix = prefix[:max]
+	}
+	// the truncated name may end with a dash or a dot, which cannot precede the suffix
+	return strings.TrimRight(prefix, "-.") + "-" + suffix
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	api "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestValidateGroupNameSelector(t *testing.T) {
+	tenants := &metav1.LabelSelector{MatchLabels: map[string]string{"team-kind": "tenant"}}
+	invalid := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team-kind", Operator: "Unknown"}}}
+	var tests = []struct {
+		spec  api.GroupPermissionSpec
+		valid bool
+	}{
+		{api.GroupPermissionSpec{GroupName: "team-a"}, true},
+		{api.GroupPermissionSpec{}, false},
+		{api.GroupPermissionSpec{GroupNameSelector: tenants}, true},
+		{api.GroupPermissionSpec{GroupName: "team-a", GroupNameSelector: tenants}, false},
+		{api.GroupPermissionSpec{GroupNameSelector: tenants, CreateGroupIfMissing: true}, false},
+		{api.GroupPermissionSpec{GroupNameSelector: invalid}, false},
+	}
+	for _, test := range tests {
+		if err := ValidateGroupNameSelector(&test.spec); (err == nil) != test.valid {
+			t.Errorf("FAILURE: ValidateGroupNameSelector(%+v) = %v, expected valid = %t", test.spec, err, test.valid)
+		}
+	}
+}
+
+func TestFanOutSpec(t *testing.T) {
+	spec := &api.GroupPermissionSpec{
+		GroupNameSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"team-kind": "tenant"}},
+		ClusterPermissions: []string{"view"},
+	}
+
+	fanOut := FanOutSpec(spec, "team-a")
+
+	expected := api.GroupPermissionSpec{GroupName: "team-a", ClusterPermissions: []string{"view"}}
+	if !reflect.DeepEqual(fanOut, expected) {
+		t.Errorf("Mismatch for spec. Expected(%+v), Found(%+v)", expected, fanOut)
+	}
+	if spec.GroupNameSelector == nil {
+		t.Errorf("expected the spec of the parent to be left unchanged")
+	}
+}
+
+func TestFanOutName(t *testing.T) {
+	var tests = []struct {
+		parentName string
+		groupName  string
+		name       string
+	}{
+		{"tenants", "team-a", "tenants-team-a"},
+		{"tenants", "Team A", "tenants-e18d322f14"},
+		{"tenants", "oidc:team-a", "tenants-c3f9ba5057"},
+	}
+	for _, test := range tests {
+		if name := FanOutName(test.parentName, test.groupName); name != test.name {
+			t.Errorf("Mismatch for FanOutName(%s, %s). Expected(%s), Found(%s)", test.parentName, test.groupName, test.name, name)
+		}
+	}
+}
+
+// FuzzFanOutName checks that the name of a fanned out GroupPermission is a valid name whenever the name
+// of its parent is, whatever the name of the Group
+func FuzzFanOutName(f *testing.F) {
+	f.Add("tenants", "team-a")
+	f.Add("tenants", "Team A")
+	f.Add("tenants", "oidc:team-a")
+	f.Add("a.b", "")
+	f.Add(strings.Repeat("a", 241)+".b", "team-a")
+	f.Fuzz(func(t *testing.T, parentName, groupName string) {
+		if len(validation.IsDNS1123Subdomain(parentName)) > 0 {
+			return
+		}
+		if name := FanOutName(parentName, groupName); len(validation.IsDNS1123Subdomain(name)) > 0 {
+			t.Errorf("FanOutName(%q, %q) = %q, which is not a valid name", parentName, groupName, name)
+		}
+	})
+}
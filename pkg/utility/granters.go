@@ -0,0 +1,52 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"strings"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AllowedGranters returns the groups whose members may author the GroupPermissions binding roles in
+// namespace, none when every GroupPermission may
+func AllowedGranters(namespace metav1.Object) []string {
+	var granters []string
+	for _, granter := range strings.Split(namespace.GetAnnotations()[operatorconfig.AllowedGrantersAnnotation], ",") {
+		if granter = strings.TrimSpace(granter); granter != "" {
+			granters = append(granters, granter)
+		}
+	}
+	return granters
+}
+
+// IsAllowedGranter returns whether a member of groups may author the GroupPermissions binding roles in
+// namespace
+func IsAllowedGranter(namespace metav1.Object, groups []string) bool {
+	granters := AllowedGranters(namespace)
+	if len(granters) == 0 {
+		return true
+	}
+	for _, group := range groups {
+		for _, granter := range granters {
+			if group == granter {
+				return true
+			}
+		}
+	}
+	return false
+}
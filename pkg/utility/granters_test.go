@@ -0,0 +1,45 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsAllowedGranter(t *testing.T) {
+	var tests = []struct {
+		granters string
+		groups   []string
+		allowed  bool
+	}{
+		{"", nil, true},
+		{" , ", nil, true},
+		{"team-a-admins", []string{"system:authenticated", "team-a-admins"}, true},
+		{"sre, team-a-admins", []string{"team-a-admins"}, true},
+		{"team-a-admins", []string{"team-b-admins"}, false},
+		{"team-a-admins", nil, false},
+	}
+	for _, test := range tests {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: map[string]string{operatorconfig.AllowedGrantersAnnotation: test.granters}}}
+		if allowed := IsAllowedGranter(namespace, test.groups); allowed != test.allowed {
+			t.Errorf("Mismatch for granters %q and groups %v. Expected(%t), Found(%t)", test.granters, test.groups, test.allowed, allowed)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SensitiveRoles returns the ClusterRoles granted by a GroupPermission that isSensitive reports as
+// sensitive, in the order of the spec
+func SensitiveRoles(spec *managedv1alpha1.GroupPermissionSpec, isSensitive func(clusterRoleName string) bool) []string {
+	var sensitive []string
+	seen := map[string]bool{}
+	add := func(clusterRoleName string) {
+		if clusterRoleName != "" && !seen[clusterRoleName] && isSensitive(clusterRoleName) {
+			seen[clusterRoleName] = true
+			sensitive = append(sensitive, clusterRoleName)
+		}
+	}
+	for _, clusterRoleName := range spec.ClusterPermissions {
+		add(clusterRoleName)
+	}
+	for _, permission := range spec.Permissions {
+		add(permission.ClusterRoleName)
+	}
+	return sensitive
+}
+
+// ValidateJustification returns an error when the TicketURL of a GroupPermission is not an http or
+// https URL, or when it grants sensitive ClusterRoles without a Justification and a TicketURL
+func ValidateJustification(spec *managedv1alpha1.GroupPermissionSpec, isSensitive func(clusterRoleName string) bool) error {
+	if spec.TicketURL != "" {
+		u, err := url.Parse(spec.TicketURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("ticketURL %s is not an http or https URL", spec.TicketURL)
+		}
+	}
+	sensitive := SensitiveRoles(spec, isSensitive)
+	if len(sensitive) > 0 && (strings.TrimSpace(spec.Justification) == "" || spec.TicketURL == "") {
+		return fmt.Errorf("granting the sensitive roles %s requires a justification and a ticketURL", strings.Join(sensitive, ", "))
+	}
+	return nil
+}
+
+// SetJustificationAnnotations records the Justification and the TicketURL of a GroupPermission, when
+// set, as annotations of objectMeta
+func SetJustificationAnnotations(objectMeta *metav1.ObjectMeta, spec *managedv1alpha1.GroupPermissionSpec) {
+	for key, value := range map[string]string{
+		operatorconfig.JustificationAnnotation: spec.Justification,
+		operatorconfig.TicketURLAnnotation:     spec.TicketURL,
+	} {
+		if value == "" {
+			continue
+		}
+		if objectMeta.Annotations == nil {
+			objectMeta.Annotations = map[string]string{}
+		}
+		objectMeta.Annotations[key] = value
+	}
+}
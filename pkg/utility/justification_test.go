@@ -0,0 +1,66 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	api "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateJustification(t *testing.T) {
+	isSensitive := func(clusterRoleName string) bool { return clusterRoleName == "cluster-admin" }
+	var tests = []struct {
+		label string
+		spec  api.GroupPermissionSpec
+		valid bool
+	}{
+		{"no sensitive role", api.GroupPermissionSpec{GroupName: "sre", ClusterPermissions: []string{"view"}}, true},
+		{"sensitive cluster permission", api.GroupPermissionSpec{GroupName: "sre", ClusterPermissions: []string{"cluster-admin"}}, false},
+		{"sensitive permission", api.GroupPermissionSpec{GroupName: "sre", Permissions: []api.Permission{{ClusterRoleName: "cluster-admin"}}}, false},
+		{"justification only", api.GroupPermissionSpec{GroupName: "sre", ClusterPermissions: []string{"cluster-admin"},
+			Justification: "on call"}, false},
+		{"justified", api.GroupPermissionSpec{GroupName: "sre", ClusterPermissions: []string{"cluster-admin"},
+			Justification: "on call", TicketURL: "https://issues.example.com/OHSS-1"}, true},
+		{"invalid ticket", api.GroupPermissionSpec{GroupName: "sre", TicketURL: "OHSS-1"}, false},
+	}
+
+	for _, test := range tests {
+		if err := ValidateJustification(&test.spec, isSensitive); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.label, test.valid, err)
+		}
+	}
+}
+
+func TestSetJustificationAnnotations(t *testing.T) {
+	objectMeta := metav1.ObjectMeta{}
+	SetJustificationAnnotations(&objectMeta, &api.GroupPermissionSpec{GroupName: "sre"})
+	if objectMeta.Annotations != nil {
+		t.Errorf("expected no annotations, got %v", objectMeta.Annotations)
+	}
+
+	SetJustificationAnnotations(&objectMeta, &api.GroupPermissionSpec{GroupName: "sre", Justification: "on call", TicketURL: "https://issues.example.com/OHSS-1"})
+	expected := map[string]string{
+		operatorconfig.JustificationAnnotation: "on call",
+		operatorconfig.TicketURLAnnotation:     "https://issues.example.com/OHSS-1",
+	}
+	if !reflect.DeepEqual(objectMeta.Annotations, expected) {
+		t.Errorf("expected annotations %v, got %v", expected, objectMeta.Annotations)
+	}
+}
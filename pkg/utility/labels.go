@@ -0,0 +1,161 @@
+// Copyright 2018 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"strconv"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ManagedByLabels returns the label marking the objects managed by the operator, without an owner
+func ManagedByLabels() map[string]string {
+	return map[string]string{operatorconfig.ManagedByLabel: operatorconfig.OperatorName}
+}
+
+// ManagedByRequirement returns the requirement of the label marking the objects managed by the operator
+func ManagedByRequirement() labels.Requirement {
+	// the key and the value are valid constants, NewRequirement cannot fail
+	requirement, _ := labels.NewRequirement(operatorconfig.ManagedByLabel, selection.Equals, []string{operatorconfig.OperatorName})
+	return *requirement
+}
+
+// ManagedSelector selects every object managed by the operator, whatever its owner
+func ManagedSelector() labels.Selector {
+	return labels.NewSelector().Add(ManagedByRequirement())
+}
+
+// ManagedLabels returns the labels of RBAC objects managed for the GroupPermission namespace/name
+func ManagedLabels(namespace, name string) map[string]string {
+	managedLabels := ManagedByLabels()
+	managedLabels[operatorconfig.OwnerNamespaceLabel] = namespace
+	managedLabels[operatorconfig.OwnerNameLabel] = name
+	return managedLabels
+}
+
+// ManagedForSelector selects the RBAC objects managed for the GroupPermission namespace/name
+func ManagedForSelector(namespace, name string) labels.Selector {
+	return labels.SelectorFromSet(ManagedLabels(namespace, name))
+}
+
+// ManagedOwner returns the GroupPermission owning the managed RBAC object of objectMeta, and whether it
+// has one. The objects managed for Elevations have none.
+func ManagedOwner(objectMeta metav1.ObjectMeta) (types.NamespacedName, bool) {
+	owner := types.NamespacedName{
+		Namespace: objectMeta.Labels[operatorconfig.OwnerNamespaceLabel],
+		Name:      objectMeta.Labels[operatorconfig.OwnerNameLabel],
+	}
+	return owner, IsManaged(objectMeta) && owner.Name != ""
+}
+
+// ParentLabels returns the labels of the objects created for the GroupPermission name, in its namespace,
+// by the label key naming it, such as FanOutParentLabel or InventoryOfLabel
+func ParentLabels(key, name string) map[string]string {
+	return map[string]string{key: name}
+}
+
+// ParentSelector selects the objects created for the GroupPermission name by the label key naming it
+func ParentSelector(key, name string) labels.Selector {
+	return labels.SelectorFromSet(ParentLabels(key, name))
+}
+
+// SetManagedLabels adds the labels of RBAC objects managed for the GroupPermission namespace/name to objectMeta
+func SetManagedLabels(objectMeta *metav1.ObjectMeta, namespace, name string) {
+	if objectMeta.Labels == nil {
+		objectMeta.Labels = map[string]string{}
+	}
+	for key, value := range ManagedLabels(namespace, name) {
+		objectMeta.Labels[key] = value
+	}
+}
+
+// SetSourceAnnotations records on objectMeta the namespace/name, the UID and the generation of the
+// GroupPermission source creating or updating the RBAC object, so it can be told whether it is current
+func SetSourceAnnotations(objectMeta *metav1.ObjectMeta, source metav1.Object) {
+	if objectMeta.Annotations == nil {
+		objectMeta.Annotations = map[string]string{}
+	}
+	objectMeta.Annotations[operatorconfig.SourceAnnotation] = source.GetNamespace() + "/" + source.GetName()
+	objectMeta.Annotations[operatorconfig.SourceUIDAnnotation] = string(source.GetUID())
+	objectMeta.Annotations[operatorconfig.SourceGenerationAnnotation] = strconv.FormatInt(source.GetGeneration(), 10)
+}
+
+// IsManaged returns whether objectMeta belongs to an RBAC object managed by the operator
+func IsManaged(objectMeta metav1.ObjectMeta) bool {
+	return ManagedSelector().Matches(labels.Set(objectMeta.Labels))
+}
+
+// IsManagedFor returns whether objectMeta belongs to an RBAC object managed for the GroupPermission namespace/name
+func IsManagedFor(objectMeta metav1.ObjectMeta, namespace, name string) bool {
+	return ManagedForSelector(namespace, name).Matches(labels.Set(objectMeta.Labels))
+}
+
+// RemoveManagedLabels removes the labels of RBAC objects managed by the operator from objectMeta, so the
+// object is left in place but is neither reconciled nor collected anymore
+func RemoveManagedLabels(objectMeta *metav1.ObjectMeta) {
+	for key := range ManagedLabels("", "") {
+		delete(objectMeta.Labels, key)
+	}
+}
+
+// SetPendingRemoval labels objectMeta as pending removal at due, it returns whether objectMeta changed
+func SetPendingRemoval(objectMeta *metav1.ObjectMeta, due time.Time) bool {
+	value := due.Format(time.RFC3339)
+	if objectMeta.Labels[operatorconfig.PendingRemovalLabel] == "true" && objectMeta.Annotations[operatorconfig.RemovalDueAnnotation] == value {
+		return false
+	}
+	if objectMeta.Labels == nil {
+		objectMeta.Labels = map[string]string{}
+	}
+	if objectMeta.Annotations == nil {
+		objectMeta.Annotations = map[string]string{}
+	}
+	objectMeta.Labels[operatorconfig.PendingRemovalLabel] = "true"
+	objectMeta.Annotations[operatorconfig.RemovalDueAnnotation] = value
+	return true
+}
+
+// ClearPendingRemoval removes the pending removal of objectMeta, it returns whether objectMeta changed
+func ClearPendingRemoval(objectMeta *metav1.ObjectMeta) bool {
+	_, labeled := objectMeta.Labels[operatorconfig.PendingRemovalLabel]
+	_, annotated := objectMeta.Annotations[operatorconfig.RemovalDueAnnotation]
+	delete(objectMeta.Labels, operatorconfig.PendingRemovalLabel)
+	delete(objectMeta.Annotations, operatorconfig.RemovalDueAnnotation)
+	return labeled || annotated
+}
+
+// IsPendingRemoval returns whether objectMeta is labeled as pending removal
+func IsPendingRemoval(objectMeta metav1.ObjectMeta) bool {
+	_, ok := objectMeta.Labels[operatorconfig.PendingRemovalLabel]
+	return ok
+}
+
+// RemovalDue returns when the object of objectMeta is due for removal, and whether it is pending removal
+// with a valid due time
+func RemovalDue(objectMeta metav1.ObjectMeta) (time.Time, bool) {
+	if !IsPendingRemoval(objectMeta) {
+		return time.Time{}, false
+	}
+	due, err := time.Parse(time.RFC3339, objectMeta.Annotations[operatorconfig.RemovalDueAnnotation])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return due, true
+}
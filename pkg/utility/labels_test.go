@@ -0,0 +1,106 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	api "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSetSourceAnnotations(t *testing.T) {
+	source := &api.GroupPermission{ObjectMeta: metav1.ObjectMeta{Namespace: "sre", Name: "oncall", UID: "1234", Generation: 3}}
+	objectMeta := &metav1.ObjectMeta{Annotations: map[string]string{operatorconfig.JustificationAnnotation: "on call"}}
+
+	SetSourceAnnotations(objectMeta, source)
+
+	expected := map[string]string{
+		operatorconfig.JustificationAnnotation:    "on call",
+		operatorconfig.SourceAnnotation:           "sre/oncall",
+		operatorconfig.SourceUIDAnnotation:        "1234",
+		operatorconfig.SourceGenerationAnnotation: "3",
+	}
+	if !reflect.DeepEqual(objectMeta.Annotations, expected) {
+		t.Errorf("expected %v, got %v", expected, objectMeta.Annotations)
+	}
+}
+
+func TestManagedSelectors(t *testing.T) {
+	managed := labels.Set(ManagedLabels("sre", "oncall"))
+	other := labels.Set(ManagedLabels("sre", "other"))
+	unmanaged := labels.Set{operatorconfig.OwnerNamespaceLabel: "sre", operatorconfig.OwnerNameLabel: "oncall"}
+
+	requirement := ManagedByRequirement()
+	if requirement.Key() != operatorconfig.ManagedByLabel || !requirement.Matches(managed) || requirement.Matches(unmanaged) {
+		t.Errorf("unexpected requirement %s", requirement.String())
+	}
+	if !ManagedSelector().Matches(managed) || !ManagedSelector().Matches(other) || ManagedSelector().Matches(unmanaged) {
+		t.Errorf("unexpected matches of %s", ManagedSelector().String())
+	}
+	selector := ManagedForSelector("sre", "oncall")
+	if !selector.Matches(managed) || selector.Matches(other) || selector.Matches(unmanaged) {
+		t.Errorf("unexpected matches of %s", selector.String())
+	}
+	parent := ParentSelector(operatorconfig.InventoryOfLabel, "oncall")
+	if !parent.Matches(labels.Set(ParentLabels(operatorconfig.InventoryOfLabel, "oncall"))) || parent.Matches(managed) {
+		t.Errorf("unexpected matches of %s", parent.String())
+	}
+}
+
+func TestManagedOwner(t *testing.T) {
+	tests := []struct {
+		name          string
+		labels        map[string]string
+		expectedOwner types.NamespacedName
+		expectedOk    bool
+	}{
+		{"owned", ManagedLabels("sre", "oncall"), types.NamespacedName{Namespace: "sre", Name: "oncall"}, true},
+		{"elevation", ManagedByLabels(), types.NamespacedName{}, false},
+		{"unmanaged", map[string]string{operatorconfig.OwnerNamespaceLabel: "sre", operatorconfig.OwnerNameLabel: "oncall"}, types.NamespacedName{Namespace: "sre", Name: "oncall"}, false},
+	}
+	for _, test := range tests {
+		owner, ok := ManagedOwner(metav1.ObjectMeta{Labels: test.labels})
+		if owner != test.expectedOwner || ok != test.expectedOk {
+			t.Errorf("%s: expected %v %t, got %v %t", test.name, test.expectedOwner, test.expectedOk, owner, ok)
+		}
+	}
+}
+
+func TestPendingRemoval(t *testing.T) {
+	due := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	objectMeta := &metav1.ObjectMeta{}
+
+	if !SetPendingRemoval(objectMeta, due) || SetPendingRemoval(objectMeta, due) {
+		t.Errorf("expected only the first SetPendingRemoval to change %v", objectMeta)
+	}
+	if found, ok := RemovalDue(*objectMeta); !ok || !found.Equal(due) {
+		t.Errorf("expected removal due at %s, got %s %t", due, found, ok)
+	}
+	if !ClearPendingRemoval(objectMeta) || ClearPendingRemoval(objectMeta) || IsPendingRemoval(*objectMeta) {
+		t.Errorf("expected only the first ClearPendingRemoval to change %v", objectMeta)
+	}
+
+	objectMeta.Labels[operatorconfig.PendingRemovalLabel] = "true"
+	if _, ok := RemovalDue(*objectMeta); ok || !IsPendingRemoval(*objectMeta) {
+		t.Errorf("expected a pending removal without a valid due time, got %v", objectMeta)
+	}
+}
@@ -15,44 +15,187 @@
 package utility
 
 import (
+	"fmt"
 	"regexp"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
 )
 
 // GetAllowedNamespaces returns a list of all namespaces that are allowed based on the input data.  Empty string regex is treated as unset.
 func IsNamespaceAllowed(namespacesAllowedRegex string, namespacesDeniedRegex string, allowFirst bool, namespace string) bool {
-	if allowFirst && namespacesAllowedRegex != "" {
-		// check allow first
-		// NOTE if allowed regex is missing nothing is allowed
-		allowed, _ := regexp.MatchString(namespacesAllowedRegex, namespace)
-		if allowed && namespacesDeniedRegex != "" {
-			// it's allowed.  now check that it is not denied.
-			denied, _ := regexp.MatchString(namespacesDeniedRegex, namespace)
-			if denied {
-				// it's denied
-				return false
-			}
+	return NewNamespaceMatcher(namespacesAllowedRegex, namespacesDeniedRegex, allowFirst, managedv1alpha1.ConflictDenyOverAllow).Matches(namespace)
+}
+
+// NamespaceMatcher decides whether a Permission applies in a Namespace from its regexes and conflict policy.
+// An empty regex is unset and an invalid one matches nothing.
+type NamespaceMatcher struct {
+	allowedRegex string
+	deniedRegex  string
+	allowed      *regexp.Regexp
+	denied       *regexp.Regexp
+	allowedErr   error
+	deniedErr    error
+	allowFirst   bool
+	policy       managedv1alpha1.ConflictPolicy
+}
+
+// NewNamespaceMatcher returns a NamespaceMatcher of the regexes, an empty policy is DenyOverAllow
+func NewNamespaceMatcher(namespacesAllowedRegex string, namespacesDeniedRegex string, allowFirst bool, policy managedv1alpha1.ConflictPolicy) *NamespaceMatcher {
+	m := &NamespaceMatcher{
+		allowedRegex: namespacesAllowedRegex,
+		deniedRegex:  namespacesDeniedRegex,
+		allowFirst:   allowFirst,
+		policy:       policy,
+	}
+	if m.policy == "" {
+		m.policy = managedv1alpha1.ConflictDenyOverAllow
+	}
+	if namespacesAllowedRegex != "" {
+		m.allowed, m.allowedErr = regexp.Compile(namespacesAllowedRegex)
+	}
+	if namespacesDeniedRegex != "" {
+		m.denied, m.deniedErr = regexp.Compile(namespacesDeniedRegex)
+	}
+	return m
+}
+
+// PermissionNamespaceMatcher returns the NamespaceMatcher of the regexes of permission
+func PermissionNamespaceMatcher(permission managedv1alpha1.Permission) *NamespaceMatcher {
+	return NewNamespaceMatcher(permission.NamespacesAllowedRegex, permission.NamespacesDeniedRegex, permission.AllowFirst, permission.ConflictPolicy)
+}
+
+// Matches returns whether namespace is allowed
+func (m *NamespaceMatcher) Matches(namespace string) bool {
+	return m.evaluate(namespace, nil)
+}
+
+// Explain returns whether namespace is allowed with the steps of the evaluation, so the precedence of
+// the regexes can be debugged. It is slower than Matches and only meant for explanations.
+func (m *NamespaceMatcher) Explain(namespace string) (bool, []string) {
+	steps := []string{}
+	allowed := m.evaluate(namespace, &steps)
+	return allowed, steps
+}
+
+// evaluate returns whether namespace is allowed, appending the steps of the evaluation to steps unless nil
+func (m *NamespaceMatcher) evaluate(namespace string, steps *[]string) bool {
+	step := func(format string, args ...interface{}) {
+		if steps != nil {
+			*steps = append(*steps, fmt.Sprintf(format, args...))
 		}
-		// it was not denied, return 'allowed' value
-		return allowed
-	} else {
-		// check deny first
-		// NOTE if deny regex is missing only the allowed regex applies
-		if namespacesDeniedRegex != "" {
-			denied, _ := regexp.MatchString(namespacesDeniedRegex, namespace)
-			if denied {
-				// it's denied
-				return false
-			}
+	}
+	matchAllowed := func() bool {
+		return m.match("namespacesAllowedRegex", m.allowedRegex, m.allowed, m.allowedErr, namespace, step)
+	}
+	matchDenied := func() bool {
+		return m.match("namespacesDeniedRegex", m.deniedRegex, m.denied, m.deniedErr, namespace, step)
+	}
+
+	if m.policy == managedv1alpha1.ConflictAllowOverDeny {
+		step("conflictPolicy is AllowOverDeny, namespacesAllowedRegex wins over namespacesDeniedRegex")
+		// every Namespace not denied is allowed, so a missing or invalid denied regex allows nothing
+		if m.deniedRegex == "" {
+			step("denied, AllowOverDeny requires a namespacesDeniedRegex")
+			return false
+		}
+		if m.deniedErr != nil {
+			matchDenied()
+			step("denied, AllowOverDeny requires a valid namespacesDeniedRegex")
+			return false
 		}
-		// it was not denied, check if it's allowed
-		if namespacesAllowedRegex != "" {
-			allowed, _ := regexp.MatchString(namespacesAllowedRegex, namespace)
-			if allowed {
-				// it's allowed
-				return true
+		if m.allowedRegex != "" && matchAllowed() {
+			step("allowed")
+			return true
+		}
+		if matchDenied() {
+			step("denied by namespacesDeniedRegex")
+			return false
+		}
+		step("allowed, the Namespace is not denied")
+		return true
+	}
+
+	if m.allowFirst && m.allowedRegex != "" {
+		step("allowFirst is set, namespacesAllowedRegex is evaluated first")
+		if !matchAllowed() {
+			step("denied, the Namespace is not allowed")
+			return false
+		}
+		if m.deniedRegex != "" && matchDenied() {
+			step("denied by namespacesDeniedRegex")
+			return false
+		}
+		step("allowed")
+		return true
+	}
+
+	if m.allowFirst {
+		step("allowFirst is ignored without a namespacesAllowedRegex")
+	}
+	if m.deniedRegex != "" && matchDenied() {
+		step("denied by namespacesDeniedRegex")
+		return false
+	}
+	if m.allowedRegex == "" {
+		step("denied by default, there is no namespacesAllowedRegex")
+		return false
+	}
+	if matchAllowed() {
+		step("allowed")
+		return true
+	}
+	step("denied by default, the Namespace is not allowed")
+	return false
+}
+
+// match returns whether the compiled regex of field matches namespace, recording the step
+func (m *NamespaceMatcher) match(field, regex string, compiled *regexp.Regexp, err error, namespace string, step func(string, ...interface{})) bool {
+	switch {
+	case err != nil:
+		step("%s %q is invalid and matches nothing: %v", field, regex, err)
+		return false
+	case compiled.MatchString(namespace):
+		step("%s %q matches", field, regex)
+		return true
+	default:
+		step("%s %q does not match", field, regex)
+		return false
+	}
+}
+
+// ValidateConflictPolicy returns an error when a Permission of spec has an unknown conflict policy, or
+// allows over deny without a valid denied regex and would allow every Namespace
+func ValidateConflictPolicy(spec *managedv1alpha1.GroupPermissionSpec) error {
+	for i, permission := range spec.Permissions {
+		switch permission.ConflictPolicy {
+		case "", managedv1alpha1.ConflictDenyOverAllow:
+		case managedv1alpha1.ConflictAllowOverDeny:
+			if permission.NamespacesDeniedRegex == "" {
+				return fmt.Errorf("conflictPolicy %s of permissions[%d] requires a namespacesDeniedRegex", permission.ConflictPolicy, i)
 			}
+			if _, err := regexp.Compile(permission.NamespacesDeniedRegex); err != nil {
+				return fmt.Errorf("conflictPolicy %s of permissions[%d] requires a valid namespacesDeniedRegex: %v", permission.ConflictPolicy, i, err)
+			}
+		default:
+			return fmt.Errorf("unknown conflictPolicy %s of permissions[%d], expected %s or %s", permission.ConflictPolicy, i,
+				managedv1alpha1.ConflictDenyOverAllow, managedv1alpha1.ConflictAllowOverDeny)
+		}
+	}
+	return nil
+}
+
+// IsNamespaceRequesterExcluded returns whether the requester recorded in the annotations of a namespace is
+// one of excludedRequesters. Namespaces without a recorded requester are never excluded.
+func IsNamespaceRequesterExcluded(excludedRequesters []string, annotations map[string]string) bool {
+	requester := annotations[operatorconfig.NamespaceRequesterAnnotation]
+	if requester == "" {
+		return false
+	}
+	for _, excluded := range excludedRequesters {
+		if excluded == requester {
+			return true
 		}
 	}
-	// it was not denied or allowed (implies it was denied, default behavior)
 	return false
 }
@@ -15,7 +15,10 @@
 package utility
 
 import (
+	"reflect"
 	"testing"
+
+	api "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
 )
 
 func TestIsNamespaceAllowed(t *testing.T) {
@@ -82,3 +85,122 @@ func TestIsNamespaceAllowed(t *testing.T) {
 		}
 	}
 }
+
+func TestIsNamespaceRequesterExcluded(t *testing.T) {
+	var tests = []struct {
+		excludedRequesters []string
+		annotations        map[string]string
+		excluded           bool
+	}{
+		{nil, nil, false},
+		{[]string{"system:serviceaccount:openshift-infra:bot"}, nil, false},
+		{[]string{"system:serviceaccount:openshift-infra:bot"}, map[string]string{"openshift.io/requester": "system:serviceaccount:openshift-infra:bot"}, true},
+		{[]string{"system:serviceaccount:openshift-infra:bot"}, map[string]string{"openshift.io/requester": "alice"}, false},
+		{[]string{"alice", "bob"}, map[string]string{"openshift.io/requester": "bob"}, true},
+		{nil, map[string]string{"openshift.io/requester": "bob"}, false},
+	}
+	for _, test := range tests {
+		if IsNamespaceRequesterExcluded(test.excludedRequesters, test.annotations) != test.excluded {
+			t.Errorf("FAILURE: IsNamespaceRequesterExcluded(%v, %v) = %t, expected = %t", test.excludedRequesters, test.annotations, !test.excluded, test.excluded)
+		}
+	}
+}
+
+func TestNamespaceMatcher(t *testing.T) {
+	var tests = []struct {
+		namespacesAllowedRegex string
+		namespacesDeniedRegex  string
+		allowFirst             bool
+		policy                 api.ConflictPolicy
+		namespace              string
+		steps                  []string
+	}{
+		{".*", "^openshift-.*", true, "", "openshift-monitoring", []string{
+			"allowFirst is set, namespacesAllowedRegex is evaluated first",
+			`namespacesAllowedRegex ".*" matches`,
+			`namespacesDeniedRegex "^openshift-.*" matches`,
+			"denied by namespacesDeniedRegex",
+		}},
+		{"^team-.*", "", true, api.ConflictDenyOverAllow, "somethingelse", []string{
+			"allowFirst is set, namespacesAllowedRegex is evaluated first",
+			`namespacesAllowedRegex "^team-.*" does not match`,
+			"denied, the Namespace is not allowed",
+		}},
+		{"", "^openshift-.*", true, "", "somethingelse", []string{
+			"allowFirst is ignored without a namespacesAllowedRegex",
+			`namespacesDeniedRegex "^openshift-.*" does not match`,
+			"denied by default, there is no namespacesAllowedRegex",
+		}},
+		{".*", "^openshift-.*", false, "", "somethingelse", []string{
+			`namespacesDeniedRegex "^openshift-.*" does not match`,
+			`namespacesAllowedRegex ".*" matches`,
+			"allowed",
+		}},
+		{"(", "", false, "", "somethingelse", []string{
+			"namespacesAllowedRegex \"(\" is invalid and matches nothing: error parsing regexp: missing closing ): `(`",
+			"denied by default, the Namespace is not allowed",
+		}},
+
+		// allow over deny, the allowed regex carves exceptions out of the denied one
+		{"^openshift-logging$", "^openshift-.*", false, api.ConflictAllowOverDeny, "openshift-logging", []string{
+			"conflictPolicy is AllowOverDeny, namespacesAllowedRegex wins over namespacesDeniedRegex",
+			`namespacesAllowedRegex "^openshift-logging$" matches`,
+			"allowed",
+		}},
+		{"^openshift-logging$", "^openshift-.*", true, api.ConflictAllowOverDeny, "openshift-monitoring", []string{
+			"conflictPolicy is AllowOverDeny, namespacesAllowedRegex wins over namespacesDeniedRegex",
+			`namespacesAllowedRegex "^openshift-logging$" does not match`,
+			`namespacesDeniedRegex "^openshift-.*" matches`,
+			"denied by namespacesDeniedRegex",
+		}},
+		{"", "^openshift-.*", false, api.ConflictAllowOverDeny, "somethingelse", []string{
+			"conflictPolicy is AllowOverDeny, namespacesAllowedRegex wins over namespacesDeniedRegex",
+			`namespacesDeniedRegex "^openshift-.*" does not match`,
+			"allowed, the Namespace is not denied",
+		}},
+		{".*", "", false, api.ConflictAllowOverDeny, "somethingelse", []string{
+			"conflictPolicy is AllowOverDeny, namespacesAllowedRegex wins over namespacesDeniedRegex",
+			"denied, AllowOverDeny requires a namespacesDeniedRegex",
+		}},
+		{".*", "(", false, api.ConflictAllowOverDeny, "somethingelse", []string{
+			"conflictPolicy is AllowOverDeny, namespacesAllowedRegex wins over namespacesDeniedRegex",
+			"namespacesDeniedRegex \"(\" is invalid and matches nothing: error parsing regexp: missing closing ): `(`",
+			"denied, AllowOverDeny requires a valid namespacesDeniedRegex",
+		}},
+	}
+	for _, test := range tests {
+		matcher := NewNamespaceMatcher(test.namespacesAllowedRegex, test.namespacesDeniedRegex, test.allowFirst, test.policy)
+		allowed, steps := matcher.Explain(test.namespace)
+		if matches := matcher.Matches(test.namespace); allowed != matches {
+			t.Errorf("FAILURE: Explain(%s) = %t, Matches = %t", test.namespace, allowed, matches)
+		}
+		if test.policy != api.ConflictAllowOverDeny {
+			if expected := IsNamespaceAllowed(test.namespacesAllowedRegex, test.namespacesDeniedRegex, test.allowFirst, test.namespace); allowed != expected {
+				t.Errorf("FAILURE: Explain(%s) = %t, IsNamespaceAllowed = %t", test.namespace, allowed, expected)
+			}
+		}
+		if !reflect.DeepEqual(steps, test.steps) {
+			t.Errorf("Mismatch for steps. Expected(%q), Found(%q)", test.steps, steps)
+		}
+	}
+}
+
+func TestValidateConflictPolicy(t *testing.T) {
+	var tests = []struct {
+		permission api.Permission
+		valid      bool
+	}{
+		{api.Permission{NamespacesAllowedRegex: ".*"}, true},
+		{api.Permission{NamespacesAllowedRegex: ".*", ConflictPolicy: api.ConflictDenyOverAllow}, true},
+		{api.Permission{NamespacesDeniedRegex: "^openshift-.*", ConflictPolicy: api.ConflictAllowOverDeny}, true},
+		{api.Permission{NamespacesAllowedRegex: ".*", ConflictPolicy: api.ConflictAllowOverDeny}, false},
+		{api.Permission{NamespacesDeniedRegex: "(", ConflictPolicy: api.ConflictAllowOverDeny}, false},
+		{api.Permission{NamespacesAllowedRegex: ".*", ConflictPolicy: "AllowFirst"}, false},
+	}
+	for _, test := range tests {
+		spec := &api.GroupPermissionSpec{Permissions: []api.Permission{test.permission}}
+		if err := ValidateConflictPolicy(spec); (err == nil) != test.valid {
+			t.Errorf("FAILURE: ValidateConflictPolicy(%+v) = %v, expected valid = %t", test.permission, err, test.valid)
+		}
+	}
+}
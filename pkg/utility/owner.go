@@ -0,0 +1,38 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+)
+
+// OwnerRef returns the team owning a GroupPermission, empty when it has none
+func OwnerRef(spec *managedv1alpha1.GroupPermissionSpec) string {
+	if spec.Notify == nil {
+		return ""
+	}
+	return spec.Notify.OwnerRef
+}
+
+// OwnerAnnotations returns the annotations routing the events of a GroupPermission to the team owning
+// it, nil when it has none
+func OwnerAnnotations(spec *managedv1alpha1.GroupPermissionSpec) map[string]string {
+	ownerRef := OwnerRef(spec)
+	if ownerRef == "" {
+		return nil
+	}
+	return map[string]string{operatorconfig.OwnerRefAnnotation: ownerRef}
+}
@@ -0,0 +1,42 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	api "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+)
+
+func TestOwnerAnnotations(t *testing.T) {
+	var tests = []struct {
+		label    string
+		spec     api.GroupPermissionSpec
+		expected map[string]string
+	}{
+		{"no notify", api.GroupPermissionSpec{GroupName: "sre"}, nil},
+		{"empty owner", api.GroupPermissionSpec{GroupName: "sre", Notify: &api.Notify{}}, nil},
+		{"owner", api.GroupPermissionSpec{GroupName: "sre", Notify: &api.Notify{OwnerRef: "#team-sre"}},
+			map[string]string{operatorconfig.OwnerRefAnnotation: "#team-sre"}},
+	}
+
+	for _, test := range tests {
+		if got := OwnerAnnotations(&test.spec); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.label, test.expected, got)
+		}
+	}
+}
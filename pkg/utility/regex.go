@@ -0,0 +1,164 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+)
+
+const (
+	// MaxRegexLength is the longest namespace regex accepted
+	MaxRegexLength = 1024
+	// MaxRegexComplexity is the largest number of instructions a namespace regex may compile to. Every
+	// Namespace is matched against every regex, so a regex blown up by nested repetitions would slow down
+	// the reconciles of clusters with many Namespaces.
+	MaxRegexComplexity = 5000
+)
+
+// ValidateRegex returns an error when regex is longer than MaxRegexLength, is not a valid RE2 expression
+// or compiles to more than MaxRegexComplexity instructions. Syntax errors report the offset of the
+// offending part of the regex.
+func ValidateRegex(regex string) error {
+	if len(regex) > MaxRegexLength {
+		return fmt.Errorf("is %d characters long, longer than the maximum of %d", len(regex), MaxRegexLength)
+	}
+	// regexp.Compile parses with the Perl flags
+	re, err := syntax.Parse(regex, syntax.Perl)
+	if err != nil {
+		if syntaxErr, ok := err.(*syntax.Error); ok {
+			return fmt.Errorf("is invalid at offset %d, %s: %q", strings.Index(regex, syntaxErr.Expr), syntaxErr.Code, syntaxErr.Expr)
+		}
+		return fmt.Errorf("is invalid: %v", err)
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return fmt.Errorf("is invalid: %v", err)
+	}
+	if len(prog.Inst) > MaxRegexComplexity {
+		return fmt.Errorf("compiles to %d instructions, more than the maximum of %d, simplify its repetitions", len(prog.Inst), MaxRegexComplexity)
+	}
+	return nil
+}
+
+// ValidateRegexes returns an error describing the first namespace regex of spec rejected by ValidateRegex
+func ValidateRegexes(spec *managedv1alpha1.GroupPermissionSpec) error {
+	for i, permission := range spec.Permissions {
+		if err := ValidateRegex(permission.NamespacesAllowedRegex); err != nil {
+			return fmt.Errorf("namespacesAllowedRegex of permissions[%d] %v", i, err)
+		}
+		if err := ValidateRegex(permission.NamespacesDeniedRegex); err != nil {
+			return fmt.Errorf("namespacesDeniedRegex of permissions[%d] %v", i, err)
+		}
+	}
+	return nil
+}
+
+// LiteralNamespaces returns the sorted names regex matches when it only matches whole literal names, such
+// as "^(team-a|team-b)$", and false otherwise or when it matches more than max names. The Namespaces of
+// such a regex can be got by name rather than matched against every Namespace of the cluster.
+func LiteralNamespaces(regex string, max int) ([]string, bool) {
+	re, err := syntax.Parse(regex, syntax.Perl)
+	if err != nil {
+		return nil, false
+	}
+	re = re.Simplify()
+	// with the Perl flags ^ and $ are the beginning and end of the name
+	if re.Op != syntax.OpConcat || len(re.Sub) < 2 || re.Sub[0].Op != syntax.OpBeginText || re.Sub[len(re.Sub)-1].Op != syntax.OpEndText {
+		return nil, false
+	}
+	names := []string{""}
+	for _, sub := range re.Sub[1 : len(re.Sub)-1] {
+		var ok bool
+		if names, ok = expandLiterals(names, sub, max); !ok {
+			return nil, false
+		}
+	}
+
+	unique := map[string]bool{}
+	for _, name := range names {
+		if name != "" {
+			unique[name] = true
+		}
+	}
+	names = names[:0]
+	for name := range unique {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, true
+}
+
+// expandLiterals returns every prefix followed by every string re matches, and false when re matches
+// more than literal strings or the result exceeds max strings
+func expandLiterals(prefixes []string, re *syntax.Regexp, max int) ([]string, bool) {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return prefixes, true
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return nil, false
+		}
+		var expanded []string
+		for _, prefix := range prefixes {
+			expanded = append(expanded, prefix+string(re.Rune))
+		}
+		return expanded, true
+	case syntax.OpCapture:
+		return expandLiterals(prefixes, re.Sub[0], max)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			var ok bool
+			if prefixes, ok = expandLiterals(prefixes, sub, max); !ok {
+				return nil, false
+			}
+		}
+		return prefixes, true
+	case syntax.OpAlternate:
+		var expanded []string
+		for _, sub := range re.Sub {
+			alternative, ok := expandLiterals(prefixes, sub, max)
+			if !ok || len(expanded)+len(alternative) > max {
+				return nil, false
+			}
+			expanded = append(expanded, alternative...)
+		}
+		return expanded, true
+	case syntax.OpCharClass:
+		// the ranges of a character class are pairs of their first and last runes
+		var runes []rune
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				if (len(runes)+1)*len(prefixes) > max {
+					return nil, false
+				}
+				runes = append(runes, r)
+			}
+		}
+		var expanded []string
+		for _, prefix := range prefixes {
+			for _, r := range runes {
+				expanded = append(expanded, prefix+string(r))
+			}
+		}
+		return expanded, true
+	default:
+		return nil, false
+	}
+}
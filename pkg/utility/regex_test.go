@@ -0,0 +1,152 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	api "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+)
+
+func TestValidateRegex(t *testing.T) {
+	var tests = []struct {
+		regex string
+		err   string
+	}{
+		{"", ""},
+		{"^(default|openshift.*|kube.*)$", ""},
+		{"^team-(", `is invalid at offset 0, missing closing ): "^team-("`},
+		{"^team-[a-", `is invalid at offset 6, missing closing ]: "[a-"`},
+		{"^team-a**", `is invalid at offset 7, invalid nested repetition operator: "**"`},
+		{strings.Repeat("a", MaxRegexLength+1), "is 1025 characters long, longer than the maximum of 1024"},
+		{"^team-a{1001}", `is invalid at offset 7, invalid repeat count: "{1001}"`},
+		{"a{1000}b{1000}c{1000}d{1000}e{1000}f{1000}", "compiles to 6002 instructions, more than the maximum of 5000, simplify its repetitions"},
+	}
+	for _, test := range tests {
+		err := ValidateRegex(test.regex)
+		if test.err == "" && err != nil {
+			t.Errorf("FAILURE: ValidateRegex(%.20s) = %v, expected no error", test.regex, err)
+		}
+		if test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("Mismatch for ValidateRegex(%.20s). Expected(%s), Found(%v)", test.regex, test.err, err)
+		}
+	}
+}
+
+func TestValidateRegexes(t *testing.T) {
+	spec := &api.GroupPermissionSpec{Permissions: []api.Permission{
+		{NamespacesAllowedRegex: "^team-.*"},
+		{NamespacesAllowedRegex: "^team-.*", NamespacesDeniedRegex: "^team-("},
+	}}
+	expected := `namespacesDeniedRegex of permissions[1] is invalid at offset 0, missing closing ): "^team-("`
+	if err := ValidateRegexes(spec); err == nil || err.Error() != expected {
+		t.Errorf("Mismatch for ValidateRegexes. Expected(%s), Found(%v)", expected, err)
+	}
+	spec.Permissions = spec.Permissions[:1]
+	if err := ValidateRegexes(spec); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLiteralNamespaces(t *testing.T) {
+	var tests = []struct {
+		regex string
+		names []string
+		ok    bool
+	}{
+		{"^team-a$", []string{"team-a"}, true},
+		{"^(team-a|team-b|default)$", []string{"default", "team-a", "team-b"}, true},
+		{"^team-[abc]$", []string{"team-a", "team-b", "team-c"}, true},
+		{"^(?:team-a|team-a)$", []string{"team-a"}, true},
+		{"^team-a", nil, false},
+		{"team-a$", nil, false},
+		{"^team-.*$", nil, false},
+		{"^team-[a-z]+$", nil, false},
+		{"(?i)^team-a$", nil, false},
+		{"(?m)^team-a$", nil, false},
+		{"^team-[0-9][0-9]$", nil, false},
+		{"^team-(", nil, false},
+		{"", nil, false},
+	}
+	for _, test := range tests {
+		names, ok := LiteralNamespaces(test.regex, 50)
+		if ok != test.ok || !reflect.DeepEqual(names, test.names) {
+			t.Errorf("Mismatch for LiteralNamespaces(%s). Expected(%v, %t), Found(%v, %t)", test.regex, test.names, test.ok, names, ok)
+		}
+	}
+}
+
+// FuzzNamespaceMatcher checks that no regexes nor Namespace name make the validation or the matching of
+// a Permission panic, that a validated regex compiles and that IsNamespaceAllowed is the DenyOverAllow
+// matcher
+func FuzzNamespaceMatcher(f *testing.F) {
+	f.Add("^team-.*", "^team-secret$", true, "team-a")
+	f.Add("", "^openshift-.*", false, "default")
+	f.Add("(a|b)*c{2,1000}", "[", false, "aaac")
+	f.Add("^(?i)TEAM-[a-z]+$", "\\z", true, "Team-a")
+	f.Fuzz(func(t *testing.T, allowed, denied string, allowFirst bool, namespace string) {
+		for _, regex := range []string{allowed, denied} {
+			if ValidateRegex(regex) != nil {
+				continue
+			}
+			if _, err := regexp.Compile(regex); err != nil {
+				t.Errorf("validated regex %q does not compile: %v", regex, err)
+			}
+		}
+		for _, policy := range []api.ConflictPolicy{"", api.ConflictDenyOverAllow, api.ConflictAllowOverDeny} {
+			NewNamespaceMatcher(allowed, denied, allowFirst, policy).Matches(namespace)
+		}
+		if IsNamespaceAllowed(allowed, denied, allowFirst, namespace) != NewNamespaceMatcher(allowed, denied, allowFirst, api.ConflictDenyOverAllow).Matches(namespace) {
+			t.Errorf("IsNamespaceAllowed(%q, %q, %t, %q) differs from the DenyOverAllow matcher", allowed, denied, allowFirst, namespace)
+		}
+	})
+}
+
+// FuzzLiteralNamespaces checks that LiteralNamespaces never panics, and that the names it returns are
+// at most max, distinct and each matched by the regex
+func FuzzLiteralNamespaces(f *testing.F) {
+	f.Add("^team-a$")
+	f.Add("^(team-a|team-b)$")
+	f.Add("^team-[ab]-(dev|prod)$")
+	f.Add("^team-.*$")
+	f.Add("^(?i)team-a$")
+	f.Add("^$")
+	f.Fuzz(func(t *testing.T, regex string) {
+		names, ok := LiteralNamespaces(regex, 50)
+		if !ok {
+			return
+		}
+		if len(names) > 50 {
+			t.Fatalf("LiteralNamespaces(%q) returned %d names, more than 50", regex, len(names))
+		}
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			t.Fatalf("LiteralNamespaces(%q) expanded a regex that does not compile: %v", regex, err)
+		}
+		seen := map[string]bool{}
+		for _, name := range names {
+			if seen[name] {
+				t.Errorf("LiteralNamespaces(%q) returned %q twice", regex, name)
+			}
+			seen[name] = true
+			if !re.MatchString(name) {
+				t.Errorf("LiteralNamespaces(%q) returned %q, which it does not match", regex, name)
+			}
+		}
+	})
+}
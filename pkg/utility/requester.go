@@ -0,0 +1,153 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"encoding/json"
+	"strings"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// requesterAnnotations are the annotations recording the requester
+var requesterAnnotations = []string{
+	operatorconfig.RequesterAnnotation,
+	operatorconfig.RequesterGroupsAnnotation,
+	operatorconfig.RequesterUIDAnnotation,
+	operatorconfig.RequesterExtraAnnotation,
+}
+
+// RequesterFromAnnotations returns the requester recorded in annotations by a mutating webhook, and
+// whether one was recorded. A requester whose extra information cannot be read is not returned, it would
+// be checked without the restrictions of its scopes.
+func RequesterFromAnnotations(annotations map[string]string) (authenticationv1.UserInfo, bool) {
+	user := annotations[operatorconfig.RequesterAnnotation]
+	if user == "" {
+		return authenticationv1.UserInfo{}, false
+	}
+
+	userInfo := authenticationv1.UserInfo{Username: user, UID: annotations[operatorconfig.RequesterUIDAnnotation]}
+	if g := annotations[operatorconfig.RequesterGroupsAnnotation]; g != "" {
+		userInfo.Groups = strings.Split(g, ",")
+	}
+	if e := annotations[operatorconfig.RequesterExtraAnnotation]; e != "" {
+		if err := json.Unmarshal([]byte(e), &userInfo.Extra); err != nil {
+			return authenticationv1.UserInfo{}, false
+		}
+	}
+	return userInfo, true
+}
+
+// SetRequesterAnnotations records userInfo in annotations as the requester
+func SetRequesterAnnotations(annotations map[string]string, userInfo authenticationv1.UserInfo) {
+	annotations[operatorconfig.RequesterAnnotation] = userInfo.Username
+	annotations[operatorconfig.RequesterGroupsAnnotation] = strings.Join(userInfo.Groups, ",")
+
+	if userInfo.UID != "" {
+		annotations[operatorconfig.RequesterUIDAnnotation] = userInfo.UID
+	} else {
+		delete(annotations, operatorconfig.RequesterUIDAnnotation)
+	}
+	if len(userInfo.Extra) != 0 {
+		// a map of string lists always marshals
+		extra, _ := json.Marshal(userInfo.Extra)
+		annotations[operatorconfig.RequesterExtraAnnotation] = string(extra)
+	} else {
+		delete(annotations, operatorconfig.RequesterExtraAnnotation)
+	}
+}
+
+// CopyRequesterAnnotations records in annotations the requester recorded in from, removing the requester
+// of annotations if from records none
+func CopyRequesterAnnotations(annotations map[string]string, from map[string]string) {
+	for _, key := range requesterAnnotations {
+		if value, ok := from[key]; ok {
+			annotations[key] = value
+		} else {
+			delete(annotations, key)
+		}
+	}
+}
+
+// NewSubjectAccessReview returns a SubjectAccessReview of the access of user to resourceAttributes or
+// nonResourceAttributes
+func NewSubjectAccessReview(user authenticationv1.UserInfo, resourceAttributes *authorizationv1.ResourceAttributes, nonResourceAttributes *authorizationv1.NonResourceAttributes) *authorizationv1.SubjectAccessReview {
+	var extra map[string]authorizationv1.ExtraValue
+	if len(user.Extra) != 0 {
+		extra = make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+		for key, value := range user.Extra {
+			extra[key] = authorizationv1.ExtraValue(value)
+		}
+	}
+	return &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:                  user.Username,
+			Groups:                user.Groups,
+			UID:                   user.UID,
+			Extra:                 extra,
+			ResourceAttributes:    resourceAttributes,
+			NonResourceAttributes: nonResourceAttributes,
+		},
+	}
+}
+
+// RuleSubjectAccessReviews returns one SubjectAccessReview of user for every verb, resource and
+// non-resource URL contained in rules. Resource checks are made in namespace, or at cluster scope
+// if namespace is empty.
+func RuleSubjectAccessReviews(user authenticationv1.UserInfo, rules []rbacv1.PolicyRule, namespace string) []*authorizationv1.SubjectAccessReview {
+	var sars []*authorizationv1.SubjectAccessReview
+
+	for _, rule := range rules {
+		for _, verb := range rule.Verbs {
+			for _, url := range rule.NonResourceURLs {
+				sars = append(sars, NewSubjectAccessReview(user, nil, &authorizationv1.NonResourceAttributes{
+					Path: url,
+					Verb: verb,
+				}))
+			}
+
+			// an empty apiGroup list never matches, the same as the authorizer
+			names := rule.ResourceNames
+			if len(names) == 0 {
+				names = []string{""}
+			}
+			for _, group := range rule.APIGroups {
+				for _, resource := range rule.Resources {
+					// split subresources, e.g. "pods/log"
+					subresource := ""
+					if i := strings.Index(resource, "/"); i >= 0 {
+						resource, subresource = resource[:i], resource[i+1:]
+					}
+					for _, name := range names {
+						sars = append(sars, NewSubjectAccessReview(user, &authorizationv1.ResourceAttributes{
+							Namespace:   namespace,
+							Group:       group,
+							Resource:    resource,
+							Subresource: subresource,
+							Verb:        verb,
+							Name:        name,
+						}, nil))
+					}
+				}
+			}
+		}
+	}
+
+	return sars
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"reflect"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+func TestRequesterAnnotations(t *testing.T) {
+	var tests = []struct {
+		label    string
+		userInfo authenticationv1.UserInfo
+		found    bool
+	}{
+		{"user with groups", authenticationv1.UserInfo{Username: "alice", Groups: []string{"system:authenticated", "team-a"}}, true},
+		{"scoped user", authenticationv1.UserInfo{Username: "alice", UID: "alice-uid", Extra: map[string]authenticationv1.ExtraValue{"scopes.authorization.openshift.io": {"user:info", "user:check-access"}}}, true},
+		{"user without groups", authenticationv1.UserInfo{Username: "alice"}, true},
+		{"no user", authenticationv1.UserInfo{}, false},
+	}
+
+	for _, test := range tests {
+		annotations := map[string]string{}
+		SetRequesterAnnotations(annotations, test.userInfo)
+		userInfo, found := RequesterFromAnnotations(annotations)
+		if found != test.found || (found && !reflect.DeepEqual(userInfo, test.userInfo)) {
+			t.Errorf("%s: Mismatch for requester. Expected(%+v, %v), Found(%+v, %v)", test.label, test.userInfo, test.found, userInfo, found)
+		}
+	}
+}
+
+func TestRequesterFromAnnotationsInvalidExtra(t *testing.T) {
+	annotations := map[string]string{
+		operatorconfig.RequesterAnnotation:      "alice",
+		operatorconfig.RequesterExtraAnnotation: "user:info",
+	}
+	if userInfo, found := RequesterFromAnnotations(annotations); found {
+		t.Errorf("Mismatch for requester. Expected(none), Found(%+v)", userInfo)
+	}
+}
+
+func TestCopyRequesterAnnotations(t *testing.T) {
+	annotations := map[string]string{"team": "a"}
+	SetRequesterAnnotations(annotations, authenticationv1.UserInfo{Username: "alice", UID: "alice-uid", Extra: map[string]authenticationv1.ExtraValue{"scopes.authorization.openshift.io": {"user:info"}}})
+	CopyRequesterAnnotations(annotations, map[string]string{operatorconfig.RequesterAnnotation: "bob", operatorconfig.RequesterGroupsAnnotation: "team-b"})
+
+	expected := map[string]string{
+		"team":                                   "a",
+		operatorconfig.RequesterAnnotation:       "bob",
+		operatorconfig.RequesterGroupsAnnotation: "team-b",
+	}
+	if !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("Mismatch for annotations. Expected(%v), Found(%v)", expected, annotations)
+	}
+}
+
+func TestNewSubjectAccessReview(t *testing.T) {
+	user := authenticationv1.UserInfo{
+		Username: "alice",
+		UID:      "alice-uid",
+		Groups:   []string{"team-a"},
+		Extra:    map[string]authenticationv1.ExtraValue{"scopes.authorization.openshift.io": {"user:info"}},
+	}
+	sar := NewSubjectAccessReview(user, &authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods"}, nil)
+
+	expected := authorizationv1.SubjectAccessReviewSpec{
+		User:               "alice",
+		UID:                "alice-uid",
+		Groups:             []string{"team-a"},
+		Extra:              map[string]authorizationv1.ExtraValue{"scopes.authorization.openshift.io": {"user:info"}},
+		ResourceAttributes: &authorizationv1.ResourceAttributes{Verb: "get", Resource: "pods"},
+	}
+	if !reflect.DeepEqual(sar.Spec, expected) {
+		t.Errorf("Mismatch for SubjectAccessReview. Expected(%+v), Found(%+v)", expected, sar.Spec)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"fmt"
+	"strings"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+)
+
+const (
+	// serviceAccountsGroup is the group of every service account of the cluster
+	serviceAccountsGroup = "system:serviceaccounts"
+	// serviceAccountUserPrefix prefixes the user name, not a group, of a single service account
+	serviceAccountUserPrefix = "system:serviceaccount:"
+)
+
+// ServiceAccountsGroupName returns the name of the group of the service accounts of namespace
+func ServiceAccountsGroupName(namespace string) string {
+	return serviceAccountsGroup + ":" + namespace
+}
+
+// ValidateServiceAccountsNamespace returns an error when the GroupName of a GroupPermission granting
+// the service accounts of a namespace is not their group, or when the Group is to be created, the
+// groups of service accounts are not Group objects
+func ValidateServiceAccountsNamespace(spec *managedv1alpha1.GroupPermissionSpec) error {
+	if spec.ServiceAccountsNamespace == "" {
+		return nil
+	}
+	if groupName := ServiceAccountsGroupName(spec.ServiceAccountsNamespace); spec.GroupName != groupName {
+		return fmt.Errorf("groupName must be %s, or unset, with serviceAccountsNamespace %s", groupName, spec.ServiceAccountsNamespace)
+	}
+	if spec.CreateGroupIfMissing {
+		return fmt.Errorf("createGroupIfMissing must not be set with serviceAccountsNamespace, the group of service accounts always exists")
+	}
+	return nil
+}
+
+// ServiceAccountGroupWarnings returns warnings about a GroupName written by hand for service accounts
+func ServiceAccountGroupWarnings(spec *managedv1alpha1.GroupPermissionSpec) []string {
+	if spec.ServiceAccountsNamespace != "" {
+		return nil
+	}
+	groupName := spec.GroupName
+	switch {
+	case groupName == serviceAccountsGroup:
+		return []string{"groupName " + groupName + " grants the permissions to every service account of the cluster"}
+	case strings.HasPrefix(groupName, serviceAccountsGroup+":"):
+		return []string{"groupName " + groupName + " can be set with serviceAccountsNamespace: " + strings.TrimPrefix(groupName, serviceAccountsGroup+":")}
+	case strings.HasPrefix(groupName, serviceAccountUserPrefix):
+		return []string{"groupName " + groupName + " is the user name of a service account, not a group, the permissions are granted to nobody. " +
+			"Use serviceAccountsNamespace to grant them to the service accounts of a namespace"}
+	}
+	return nil
+}
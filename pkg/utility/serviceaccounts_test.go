@@ -0,0 +1,60 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"testing"
+
+	api "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+)
+
+func TestValidateServiceAccountsNamespace(t *testing.T) {
+	var tests = []struct {
+		label string
+		spec  api.GroupPermissionSpec
+		valid bool
+	}{
+		{"no service accounts", api.GroupPermissionSpec{GroupName: "sre"}, true},
+		{"group of the namespace", api.GroupPermissionSpec{GroupName: "system:serviceaccounts:ci", ServiceAccountsNamespace: "ci"}, true},
+		{"other group", api.GroupPermissionSpec{GroupName: "sre", ServiceAccountsNamespace: "ci"}, false},
+		{"group to create", api.GroupPermissionSpec{GroupName: "system:serviceaccounts:ci", ServiceAccountsNamespace: "ci", CreateGroupIfMissing: true}, false},
+	}
+
+	for _, test := range tests {
+		if err := ValidateServiceAccountsNamespace(&test.spec); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.label, test.valid, err)
+		}
+	}
+}
+
+func TestServiceAccountGroupWarnings(t *testing.T) {
+	var tests = []struct {
+		label    string
+		spec     api.GroupPermissionSpec
+		warnings int
+	}{
+		{"group", api.GroupPermissionSpec{GroupName: "sre"}, 0},
+		{"service accounts namespace", api.GroupPermissionSpec{GroupName: "system:serviceaccounts:ci", ServiceAccountsNamespace: "ci"}, 0},
+		{"hand written group of a namespace", api.GroupPermissionSpec{GroupName: "system:serviceaccounts:ci"}, 1},
+		{"every service account", api.GroupPermissionSpec{GroupName: "system:serviceaccounts"}, 1},
+		{"user name of a service account", api.GroupPermissionSpec{GroupName: "system:serviceaccount:ci:builder"}, 1},
+	}
+
+	for _, test := range tests {
+		if warnings := ServiceAccountGroupWarnings(&test.spec); len(warnings) != test.warnings {
+			t.Errorf("%s: expected %d warnings, got %v", test.label, test.warnings, warnings)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"fmt"
+	"strings"
+
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// SubjectAPIGroup returns the API group of the Group subject of the bindings of a GroupPermission
+func SubjectAPIGroup(spec *managedv1alpha1.GroupPermissionSpec) string {
+	if spec.SubjectAPIGroup == "" {
+		return rbacv1.GroupName
+	}
+	return spec.SubjectAPIGroup
+}
+
+// GroupSubject returns the Group subject of the bindings of a GroupPermission
+func GroupSubject(spec *managedv1alpha1.GroupPermissionSpec) rbacv1.Subject {
+	return rbacv1.Subject{
+		Kind:     rbacv1.GroupKind,
+		APIGroup: SubjectAPIGroup(spec),
+		Name:     spec.GroupName,
+	}
+}
+
+// ValidateSubjectAPIGroup returns an error when the SubjectAPIGroup of a GroupPermission is not a
+// valid API group name
+func ValidateSubjectAPIGroup(spec *managedv1alpha1.GroupPermissionSpec) error {
+	if spec.SubjectAPIGroup == "" {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(spec.SubjectAPIGroup); len(errs) > 0 {
+		return fmt.Errorf("subjectAPIGroup %s is not a valid API group: %s", spec.SubjectAPIGroup, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// RoleRefAPIGroup returns the API group of the roles referenced by the bindings of a GroupPermission
+func RoleRefAPIGroup(spec *managedv1alpha1.GroupPermissionSpec) string {
+	if spec.RoleRefAPIGroup == "" {
+		return rbacv1.GroupName
+	}
+	return spec.RoleRefAPIGroup
+}
+
+// ValidateRoleRefAPIGroup returns an error when the RoleRefAPIGroup of a GroupPermission is not a
+// valid API group name
+func ValidateRoleRefAPIGroup(spec *managedv1alpha1.GroupPermissionSpec) error {
+	if spec.RoleRefAPIGroup == "" {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(spec.RoleRefAPIGroup); len(errs) > 0 {
+		return fmt.Errorf("roleRefAPIGroup %s is not a valid API group: %s", spec.RoleRefAPIGroup, strings.Join(errs, ", "))
+	}
+	return nil
+}
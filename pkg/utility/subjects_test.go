@@ -0,0 +1,76 @@
+// Copyright 2019 RedHat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utility
+
+import (
+	"testing"
+
+	api "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+)
+
+func TestValidateSubjectAPIGroup(t *testing.T) {
+	var tests = []struct {
+		label string
+		spec  api.GroupPermissionSpec
+		valid bool
+	}{
+		{"default", api.GroupPermissionSpec{GroupName: "sre"}, true},
+		{"rbac group", api.GroupPermissionSpec{GroupName: "sre", SubjectAPIGroup: "rbac.authorization.k8s.io"}, true},
+		{"custom group", api.GroupPermissionSpec{GroupName: "sre", SubjectAPIGroup: "idp.example.com"}, true},
+		{"uppercase", api.GroupPermissionSpec{GroupName: "sre", SubjectAPIGroup: "IdP.example.com"}, false},
+		{"with a version", api.GroupPermissionSpec{GroupName: "sre", SubjectAPIGroup: "idp.example.com/v1"}, false},
+	}
+
+	for _, test := range tests {
+		if err := ValidateSubjectAPIGroup(&test.spec); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.label, test.valid, err)
+		}
+	}
+}
+
+func TestGroupSubject(t *testing.T) {
+	subject := GroupSubject(&api.GroupPermissionSpec{GroupName: "sre"})
+	if subject.Kind != "Group" || subject.Name != "sre" || subject.APIGroup != "rbac.authorization.k8s.io" {
+		t.Errorf("unexpected default subject %v", subject)
+	}
+
+	subject = GroupSubject(&api.GroupPermissionSpec{GroupName: "sre", SubjectAPIGroup: "idp.example.com"})
+	if subject.APIGroup != "idp.example.com" {
+		t.Errorf("expected the API group idp.example.com, got %v", subject)
+	}
+}
+
+func TestValidateRoleRefAPIGroup(t *testing.T) {
+	var tests = []struct {
+		label string
+		spec  api.GroupPermissionSpec
+		valid bool
+	}{
+		{"default", api.GroupPermissionSpec{GroupName: "sre"}, true},
+		{"rbac group", api.GroupPermissionSpec{GroupName: "sre", RoleRefAPIGroup: "rbac.authorization.k8s.io"}, true},
+		{"custom group", api.GroupPermissionSpec{GroupName: "sre", RoleRefAPIGroup: "authz.example.com"}, true},
+		{"uppercase", api.GroupPermissionSpec{GroupName: "sre", RoleRefAPIGroup: "AuthZ.example.com"}, false},
+		{"with a version", api.GroupPermissionSpec{GroupName: "sre", RoleRefAPIGroup: "authz.example.com/v1"}, false},
+	}
+
+	for _, test := range tests {
+		if err := ValidateRoleRefAPIGroup(&test.spec); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.label, test.valid, err)
+		}
+	}
+	if group := RoleRefAPIGroup(&api.GroupPermissionSpec{GroupName: "sre"}); group != "rbac.authorization.k8s.io" {
+		t.Errorf("expected the default API group rbac.authorization.k8s.io, got %s", group)
+	}
+}
@@ -0,0 +1,10 @@
+package webhook
+
+import (
+	"github.com/openshift/rbac-permissions-operator/pkg/webhook/grouppermission"
+)
+
+func init() {
+	// BuildFuncs is a list of functions to build admission webhooks.
+	BuildFuncs = append(BuildFuncs, grouppermission.BuildMutating)
+}
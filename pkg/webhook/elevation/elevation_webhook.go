@@ -0,0 +1,119 @@
+package elevation
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+var log = logf.Log.WithName("webhook_elevation")
+
+// webhookName labels the metrics of the webhook
+const webhookName = "elevation"
+
+// Reasons of the rejections of Elevations, as counted by the metrics of the webhook
+const (
+	reasonDecode      = "decode"
+	reasonSpecChanged = "spec_changed"
+	reasonPatch       = "patch"
+)
+
+// BuildMutating builds the mutating admission webhook for Elevation objects
+func BuildMutating(mgr manager.Manager) (*admission.Webhook, error) {
+	return builder.NewWebhookBuilder().
+		Name("mutating.elevation.managed.openshift.io").
+		Mutating().
+		Path("/mutate-elevations").
+		Operations(admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update).
+		ForType(&managedv1alpha1.Elevation{}).
+		FailurePolicy(admissionregistrationv1beta1.Fail).
+		WithManager(mgr).
+		Handlers(&requesterRecorder{}).
+		Build()
+}
+
+// requesterRecorder records the user creating an Elevation as an annotation, the user the
+// controller grants the Elevation to
+type requesterRecorder struct {
+	decoder atypes.Decoder
+}
+
+// blank assignment to verify that requesterRecorder implements admission.Handler
+var _ admission.Handler = &requesterRecorder{}
+
+// Handle records the requester on the incoming Elevation, and rejects changes to the spec of an
+// existing Elevation. The duration and rejection reason of the review are recorded in the metrics.
+func (h *requesterRecorder) Handle(ctx context.Context, req atypes.Request) atypes.Response {
+	start := time.Now()
+	response, reason := h.handle(req)
+	allowed := response.Response != nil && response.Response.Allowed
+	if !allowed && reason == "" {
+		reason = reasonPatch
+	}
+	localmetrics.ObserveWebhookReview(webhookName, allowed, reason, time.Since(start))
+	return response
+}
+
+// handle reviews the incoming Elevation, and returns the reason it is rejected, if it is
+func (h *requesterRecorder) handle(req atypes.Request) (atypes.Response, string) {
+	instance := &managedv1alpha1.Elevation{}
+	if err := h.decoder.Decode(req, instance); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err), reasonDecode
+	}
+
+	var old *managedv1alpha1.Elevation
+	if req.AdmissionRequest.Operation == admissionv1beta1.Update {
+		old = &managedv1alpha1.Elevation{}
+		if err := h.decoder.Decode(atypes.Request{
+			AdmissionRequest: &admissionv1beta1.AdmissionRequest{Object: req.AdmissionRequest.OldObject},
+		}, old); err != nil {
+			return admission.ErrorResponse(http.StatusBadRequest, err), reasonDecode
+		}
+		if !reflect.DeepEqual(old.Spec, instance.Spec) {
+			return admission.ValidationResponse(false, "the spec of an Elevation cannot be changed, request a new Elevation"), reasonSpecChanged
+		}
+	}
+
+	mutated := instance.DeepCopy()
+	recordRequester(mutated, old, req.AdmissionRequest.UserInfo)
+	log.Info("Recorded requester", "Namespace", mutated.Namespace, "Name", mutated.Name, "Requester", mutated.Annotations[operatorconfig.RequesterAnnotation])
+
+	return admission.PatchResponse(instance, mutated), ""
+}
+
+// InjectDecoder injects the decoder into the requesterRecorder
+func (h *requesterRecorder) InjectDecoder(d atypes.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+// recordRequester sets the requester annotations on elevation, with the groups the requester must
+// share with its GroupPermission. On update the annotations of the old object are kept, an Elevation
+// is only ever granted to the user who created it.
+func recordRequester(elevation *managedv1alpha1.Elevation, old *managedv1alpha1.Elevation, userInfo authenticationv1.UserInfo) {
+	if elevation.Annotations == nil {
+		elevation.Annotations = map[string]string{}
+	}
+
+	if old != nil {
+		utility.CopyRequesterAnnotations(elevation.Annotations, old.Annotations)
+		return
+	}
+
+	utility.SetRequesterAnnotations(elevation.Annotations, userInfo)
+}
@@ -0,0 +1,122 @@
+package elevation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+func mockElevation(requester string) *v1alpha1.Elevation {
+	elevation := &v1alpha1.Elevation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testElevation",
+			Namespace: "rbac-permissions-operator",
+		},
+		Spec: v1alpha1.ElevationSpec{
+			GroupPermissionName: "testGroupPermission",
+			DurationHours:       1,
+			Justification:       "incident 42",
+		},
+	}
+	if requester != "" {
+		elevation.Annotations = map[string]string{operatorconfig.RequesterAnnotation: requester}
+	}
+	return elevation
+}
+
+func TestRecordRequester(t *testing.T) {
+	user := authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a"}}
+
+	var tests = []struct {
+		label        string
+		elevation    *v1alpha1.Elevation
+		old          *v1alpha1.Elevation
+		expectedUser string
+	}{
+		{"create", mockElevation(""), nil, "alice"},
+		{"create with forged annotation", mockElevation("cluster-admin"), nil, "alice"},
+		{"update", mockElevation(""), mockElevation("bob"), "bob"},
+		{"update with forged annotation", mockElevation("cluster-admin"), mockElevation("bob"), "bob"},
+		{"update without recorded requester", mockElevation("cluster-admin"), mockElevation(""), ""},
+	}
+
+	for _, test := range tests {
+		recordRequester(test.elevation, test.old, user)
+		if found := test.elevation.Annotations[operatorconfig.RequesterAnnotation]; found != test.expectedUser {
+			t.Errorf("%s: Mismatch for requester. Expected(%s), Found(%s)", test.label, test.expectedUser, found)
+		}
+	}
+
+	// the groups are recorded on creation only, along the requester
+	created := mockElevation("")
+	recordRequester(created, nil, user)
+	if groups := created.Annotations[operatorconfig.RequesterGroupsAnnotation]; groups != "team-a" {
+		t.Errorf("Mismatch for requester groups. Expected(team-a), Found(%s)", groups)
+	}
+	forged := mockElevation("")
+	forged.Annotations = map[string]string{operatorconfig.RequesterGroupsAnnotation: "admins"}
+	recordRequester(forged, mockElevation("bob"), user)
+	if groups, ok := forged.Annotations[operatorconfig.RequesterGroupsAnnotation]; ok {
+		t.Errorf("Mismatch for requester groups on update. Expected(none), Found(%s)", groups)
+	}
+}
+
+// TestHandleMetrics tests that Handle counts the rejected reviews by reason
+// given: an Elevation created, then updated with another spec
+// expected: the update only is counted as rejected because its spec changed
+func TestHandleMetrics(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := &requesterRecorder{decoder: decoder}
+	raw := func(elevation *v1alpha1.Elevation) runtime.RawExtension {
+		data, err := json.Marshal(elevation)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return runtime.RawExtension{Raw: data}
+	}
+	rejections := localmetrics.RBACWebhookRejections.WithLabelValues(webhookName, reasonSpecChanged)
+	before := testutil.ToFloat64(rejections)
+
+	created := handler.Handle(context.TODO(), atypes.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+		Operation: admissionv1beta1.Create,
+		Object:    raw(mockElevation("")),
+		UserInfo:  authenticationv1.UserInfo{Username: "alice"},
+	}})
+	if !created.Response.Allowed {
+		t.Fatalf("expected the creation to be allowed, got %+v", created.Response.Result)
+	}
+
+	changed := mockElevation("alice")
+	changed.Spec.DurationHours = 2
+	updated := handler.Handle(context.TODO(), atypes.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+		Operation: admissionv1beta1.Update,
+		Object:    raw(changed),
+		OldObject: raw(mockElevation("alice")),
+		UserInfo:  authenticationv1.UserInfo{Username: "alice"},
+	}})
+	if updated.Response.Allowed {
+		t.Fatalf("expected the change of spec to be rejected")
+	}
+	if value := testutil.ToFloat64(rejections) - before; value != 1 {
+		t.Errorf("expected 1 rejection counted, got %v", value)
+	}
+}
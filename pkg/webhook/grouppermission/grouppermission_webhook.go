@@ -0,0 +1,483 @@
+package grouppermission
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+var log = logf.Log.WithName("webhook_grouppermission")
+
+// webhookName labels the metrics of the webhook
+const webhookName = "grouppermission"
+
+// groupGVK is the OpenShift Group kind, handled as unstructured to avoid depending on the OpenShift API types
+var groupGVK = schema.GroupVersionKind{Group: "user.openshift.io", Version: "v1", Kind: "Group"}
+
+// Reasons of the rejections of GroupPermissions, as counted by the metrics of the webhook
+const (
+	reasonDecode                   = "decode"
+	reasonServiceAccountsNamespace = "service_accounts_namespace"
+	reasonSubjectAPIGroup          = "subject_api_group"
+	reasonRoleRefAPIGroup          = "role_ref_api_group"
+	reasonOperatorConfig           = "operator_config"
+	reasonJustification            = "justification"
+	reasonDelegation               = "delegation"
+	reasonGranters                 = "granters"
+	reasonConflictPolicy           = "conflict_policy"
+	reasonTags                     = "tags"
+	reasonRollback                 = "rollback"
+	reasonRegex                    = "regex"
+	reasonGroupNameSelector        = "group_name_selector"
+	reasonFanOut                   = "fan_out"
+	reasonPatch                    = "patch"
+)
+
+// BuildMutating builds the mutating admission webhook for GroupPermission objects
+func BuildMutating(mgr manager.Manager) (*admission.Webhook, error) {
+	return builder.NewWebhookBuilder().
+		Name("mutating.grouppermission.managed.openshift.io").
+		Mutating().
+		Path("/mutate-grouppermissions").
+		Operations(admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update).
+		ForType(&managedv1alpha1.GroupPermission{}).
+		FailurePolicy(admissionregistrationv1beta1.Fail).
+		WithManager(mgr).
+		Handlers(&requesterRecorder{}).
+		Build()
+}
+
+// requesterRecorder records the user making a change to a GroupPermission spec as annotations
+// so the controller can later verify the user was allowed to grant the requested roles
+type requesterRecorder struct {
+	decoder atypes.Decoder
+	// client reads the operator configuration
+	client client.Client
+}
+
+// blank assignment to verify that requesterRecorder implements admission.Handler
+var _ admission.Handler = &requesterRecorder{}
+
+// Handle records the requester on the incoming GroupPermission, and the duration and rejection
+// reason of the review in the metrics
+func (h *requesterRecorder) Handle(ctx context.Context, req atypes.Request) atypes.Response {
+	start := time.Now()
+	response, reason := h.handle(ctx, req)
+	allowed := response.Response != nil && response.Response.Allowed
+	if !allowed && reason == "" {
+		reason = reasonPatch
+	}
+	localmetrics.ObserveWebhookReview(webhookName, allowed, reason, time.Since(start))
+	return response
+}
+
+// handle reviews the incoming GroupPermission, and returns the reason it is rejected, if it is
+func (h *requesterRecorder) handle(ctx context.Context, req atypes.Request) (atypes.Response, string) {
+	instance := &managedv1alpha1.GroupPermission{}
+	if err := h.decoder.Decode(req, instance); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err), reasonDecode
+	}
+
+	var old *managedv1alpha1.GroupPermission
+	if req.AdmissionRequest.Operation == admissionv1beta1.Update {
+		old = &managedv1alpha1.GroupPermission{}
+		if err := h.decoder.Decode(atypes.Request{
+			AdmissionRequest: &admissionv1beta1.AdmissionRequest{Object: req.AdmissionRequest.OldObject},
+		}, old); err != nil {
+			return admission.ErrorResponse(http.StatusBadRequest, err), reasonDecode
+		}
+	}
+
+	mutated := instance.DeepCopy()
+	operatorConfig, err := operatorconfig.GetOperatorConfig(ctx, h.client)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusInternalServerError, err), reasonOperatorConfig
+	}
+	if reason, err := ValidateSpec(mutated, old, operatorConfig); err != nil {
+		return admission.ValidationResponse(false, err.Error()), reason
+	}
+	if err := h.validateDelegation(ctx, mutated, old, operatorConfig); err != nil {
+		if _, ok := err.(*delegationError); ok {
+			return admission.ValidationResponse(false, err.Error()), reasonDelegation
+		}
+		return admission.ErrorResponse(http.StatusInternalServerError, err), reasonDelegation
+	}
+	if err := h.validateGranters(ctx, mutated, old, req.AdmissionRequest.UserInfo); err != nil {
+		if _, ok := err.(*granterError); ok {
+			return admission.ValidationResponse(false, err.Error()), reasonGranters
+		}
+		return admission.ErrorResponse(http.StatusInternalServerError, err), reasonGranters
+	}
+	recordRequester(mutated, old, req.AdmissionRequest.UserInfo)
+	if err := h.inheritFanOutRequester(ctx, mutated); err != nil {
+		if _, ok := err.(*fanOutError); ok {
+			return admission.ValidationResponse(false, err.Error()), reasonFanOut
+		}
+		return admission.ErrorResponse(http.StatusInternalServerError, err), reasonFanOut
+	}
+	log.Info("Recorded requester", "Namespace", mutated.Namespace, "Name", mutated.Name, "Requester", mutated.Annotations[operatorconfig.RequesterAnnotation],
+		"Justification", mutated.Spec.Justification, "TicketURL", mutated.Spec.TicketURL)
+	if warnings := recordWarnings(mutated); len(warnings) > 0 {
+		log.Info("GroupPermission admitted with warnings", "Namespace", mutated.Namespace, "Name", mutated.Name, "Warnings", warnings)
+	}
+
+	return admission.PatchResponse(instance, mutated), ""
+}
+
+// InjectDecoder injects the decoder into the requesterRecorder
+func (h *requesterRecorder) InjectDecoder(d atypes.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+// InjectClient injects the client into the requesterRecorder
+func (h *requesterRecorder) InjectClient(c client.Client) error {
+	h.client = c
+	return nil
+}
+
+// ValidateSpec runs the checks of the webhook on groupPermission, updated from old unless nil, that do not
+// read the cluster, and returns the reason of the first one failing with its error. The GroupName of a
+// groupPermission granting the service accounts of a namespace is set first. They are shared with the
+// validate command of rbacctl, which lints GroupPermissions offline.
+func ValidateSpec(groupPermission *managedv1alpha1.GroupPermission, old *managedv1alpha1.GroupPermission, operatorConfig *operatorconfig.OperatorConfig) (string, error) {
+	if err := applyServiceAccountsNamespace(groupPermission); err != nil {
+		return reasonServiceAccountsNamespace, err
+	}
+	if err := validateSubjectAPIGroup(groupPermission, old); err != nil {
+		return reasonSubjectAPIGroup, err
+	}
+	if err := validateRoleRefAPIGroup(groupPermission, old); err != nil {
+		return reasonRoleRefAPIGroup, err
+	}
+	if err := utility.ValidateGroupNameSelector(&groupPermission.Spec); err != nil {
+		return reasonGroupNameSelector, err
+	}
+	if err := validateRegexes(groupPermission, old); err != nil {
+		return reasonRegex, err
+	}
+	if err := utility.ValidateConflictPolicy(&groupPermission.Spec); err != nil {
+		return reasonConflictPolicy, err
+	}
+	if err := utility.ValidateTags(&groupPermission.Spec); err != nil {
+		return reasonTags, err
+	}
+	if err := validateRollback(groupPermission, old); err != nil {
+		return reasonRollback, err
+	}
+	if err := validateJustification(groupPermission, old, operatorConfig); err != nil {
+		return reasonJustification, err
+	}
+	return "", nil
+}
+
+// recordRequester sets the requester annotations on groupPermission. When the spec is unchanged by an
+// update, restored by the operator for a rollback requested by the recorded requester, or only stripped
+// of grants by the operator, the annotations of the old object are kept, so metadata-only changes can
+// neither claim nor forge the identity of whoever granted the permissions.
+func recordRequester(groupPermission *managedv1alpha1.GroupPermission, old *managedv1alpha1.GroupPermission, userInfo authenticationv1.UserInfo) {
+	if groupPermission.Annotations == nil {
+		groupPermission.Annotations = map[string]string{}
+	}
+
+	if old != nil && (reflect.DeepEqual(old.Spec, groupPermission.Spec) || isRollback(groupPermission, old, userInfo) || isGrantRemoval(groupPermission, old, userInfo)) {
+		utility.CopyRequesterAnnotations(groupPermission.Annotations, old.Annotations)
+		return
+	}
+
+	utility.SetRequesterAnnotations(groupPermission.Annotations, userInfo)
+}
+
+// isRollback returns whether the update of old to groupPermission by userInfo is the operator restoring
+// the spec of a previous generation, the requester of the rollback is then the requester of the spec
+func isRollback(groupPermission *managedv1alpha1.GroupPermission, old *managedv1alpha1.GroupPermission, userInfo authenticationv1.UserInfo) bool {
+	return old.Spec.RollbackToGeneration != 0 && groupPermission.Spec.RollbackToGeneration == 0 && isOperator(userInfo)
+}
+
+// isGrantRemoval returns whether the update of old to groupPermission by userInfo is the operator removing
+// ClusterPermissions and Permissions from the spec, such as the stale grants removed by the reaper, and
+// changing nothing else. The requester of the remaining grants is still the requester of the spec.
+func isGrantRemoval(groupPermission *managedv1alpha1.GroupPermission, old *managedv1alpha1.GroupPermission, userInfo authenticationv1.UserInfo) bool {
+	if !isOperator(userInfo) {
+		return false
+	}
+
+	kept := 0
+	for _, clusterRoleName := range old.Spec.ClusterPermissions {
+		if kept < len(groupPermission.Spec.ClusterPermissions) && groupPermission.Spec.ClusterPermissions[kept] == clusterRoleName {
+			kept++
+		}
+	}
+	if kept != len(groupPermission.Spec.ClusterPermissions) {
+		return false
+	}
+	kept = 0
+	for _, permission := range old.Spec.Permissions {
+		if kept < len(groupPermission.Spec.Permissions) && reflect.DeepEqual(groupPermission.Spec.Permissions[kept], permission) {
+			kept++
+		}
+	}
+	if kept != len(groupPermission.Spec.Permissions) {
+		return false
+	}
+
+	// everything but the grants removed is unchanged
+	spec := old.Spec.DeepCopy()
+	spec.ClusterPermissions = groupPermission.Spec.ClusterPermissions
+	spec.Permissions = groupPermission.Spec.Permissions
+	return reflect.DeepEqual(*spec, groupPermission.Spec)
+}
+
+// isOperator returns whether userInfo is a service account of the operator
+func isOperator(userInfo authenticationv1.UserInfo) bool {
+	return strings.HasPrefix(userInfo.Username, "system:serviceaccount:"+operatorconfig.OperatorNamespace+":")
+}
+
+// validateRollback returns an error when the generation groupPermission rolls back to is not a previous
+// generation
+func validateRollback(groupPermission *managedv1alpha1.GroupPermission, old *managedv1alpha1.GroupPermission) error {
+	target := groupPermission.Spec.RollbackToGeneration
+	if target == 0 || (old != nil && old.Spec.RollbackToGeneration == target) {
+		return nil
+	}
+	if old == nil {
+		return fmt.Errorf("rollbackToGeneration cannot be set on creation")
+	}
+	if target < 0 || target >= old.Generation {
+		return fmt.Errorf("rollbackToGeneration must be a previous generation, lower than %d", old.Generation)
+	}
+	return nil
+}
+
+// applyServiceAccountsNamespace sets the GroupName of a groupPermission granting the service accounts
+// of a namespace to their group, and returns an error when the spec conflicts with it
+func applyServiceAccountsNamespace(groupPermission *managedv1alpha1.GroupPermission) error {
+	if groupPermission.Spec.ServiceAccountsNamespace != "" && groupPermission.Spec.GroupName == "" {
+		groupPermission.Spec.GroupName = utility.ServiceAccountsGroupName(groupPermission.Spec.ServiceAccountsNamespace)
+	}
+	return utility.ValidateServiceAccountsNamespace(&groupPermission.Spec)
+}
+
+// validateSubjectAPIGroup returns an error when the SubjectAPIGroup of groupPermission is invalid or
+// changed from old. The bindings are found by name, which the API group is not part of, so existing
+// bindings would keep the subject of the old API group.
+func validateSubjectAPIGroup(groupPermission *managedv1alpha1.GroupPermission, old *managedv1alpha1.GroupPermission) error {
+	if old != nil && utility.SubjectAPIGroup(&old.Spec) != utility.SubjectAPIGroup(&groupPermission.Spec) {
+		return fmt.Errorf("subjectAPIGroup cannot be changed from %s, recreate the GroupPermission instead", utility.SubjectAPIGroup(&old.Spec))
+	}
+	return utility.ValidateSubjectAPIGroup(&groupPermission.Spec)
+}
+
+// validateRoleRefAPIGroup returns an error when the RoleRefAPIGroup of groupPermission is invalid or
+// changed from old. The roleRef of a binding cannot be updated, existing bindings would keep the roles
+// of the old API group.
+func validateRoleRefAPIGroup(groupPermission *managedv1alpha1.GroupPermission, old *managedv1alpha1.GroupPermission) error {
+	if old != nil && utility.RoleRefAPIGroup(&old.Spec) != utility.RoleRefAPIGroup(&groupPermission.Spec) {
+		return fmt.Errorf("roleRefAPIGroup cannot be changed from %s, recreate the GroupPermission instead", utility.RoleRefAPIGroup(&old.Spec))
+	}
+	return utility.ValidateRoleRefAPIGroup(&groupPermission.Spec)
+}
+
+// validateJustification returns an error when groupPermission grants ClusterRoles the operator
+// configuration reports as sensitive without a justification and a ticket URL. Updates leaving the
+// spec unchanged are allowed, so the operator can still manage GroupPermissions created before.
+func validateJustification(groupPermission *managedv1alpha1.GroupPermission, old *managedv1alpha1.GroupPermission, operatorConfig *operatorconfig.OperatorConfig) error {
+	if old != nil && reflect.DeepEqual(old.Spec, groupPermission.Spec) {
+		return nil
+	}
+	return utility.ValidateJustification(&groupPermission.Spec, operatorConfig.IsSensitiveRole)
+}
+
+// validateRegexes returns an error when a namespace regex of groupPermission is invalid or exceeds the
+// limits of the operator. Updates leaving the spec unchanged are allowed, so the operator can still manage
+// GroupPermissions created before the limits.
+func validateRegexes(groupPermission *managedv1alpha1.GroupPermission, old *managedv1alpha1.GroupPermission) error {
+	if old != nil && reflect.DeepEqual(old.Spec, groupPermission.Spec) {
+		return nil
+	}
+	return utility.ValidateRegexes(&groupPermission.Spec)
+}
+
+// recordWarnings records the warnings about the spec of groupPermission as an annotation, removing
+// the annotation when there is none, and returns them
+func recordWarnings(groupPermission *managedv1alpha1.GroupPermission) []string {
+	warnings := utility.ServiceAccountGroupWarnings(&groupPermission.Spec)
+	if len(warnings) == 0 {
+		delete(groupPermission.Annotations, operatorconfig.AdmissionWarningsAnnotation)
+		return nil
+	}
+	if groupPermission.Annotations == nil {
+		groupPermission.Annotations = map[string]string{}
+	}
+	groupPermission.Annotations[operatorconfig.AdmissionWarningsAnnotation] = strings.Join(warnings, "; ")
+	return warnings
+}
+
+// delegationError is returned when a GroupPermission of a delegated Namespace grants roles not delegable to it
+type delegationError struct {
+	namespace   string
+	undelegable []string
+}
+
+func (e *delegationError) Error() string {
+	return fmt.Sprintf("GroupPermissions of Namespace %s may only grant the ClusterRoles labeled %s=true, not %s",
+		e.namespace, utility.DelegableLabel(e.namespace), strings.Join(e.undelegable, ", "))
+}
+
+// validateDelegation returns a delegationError when groupPermission is in a Namespace delegated by the
+// operator configuration and grants roles not delegable to it. Updates leaving the spec unchanged are
+// allowed, so the operator can still manage GroupPermissions created before the Namespace was delegated.
+func (h *requesterRecorder) validateDelegation(ctx context.Context, groupPermission *managedv1alpha1.GroupPermission, old *managedv1alpha1.GroupPermission, operatorConfig *operatorconfig.OperatorConfig) error {
+	if !operatorConfig.IsDelegatedNamespace(groupPermission.Namespace) {
+		return nil
+	}
+	if old != nil && reflect.DeepEqual(old.Spec, groupPermission.Spec) {
+		return nil
+	}
+	undelegable, err := utility.UndelegableRoles(&groupPermission.Spec, groupPermission.Namespace, func(clusterRoleName string) (metav1.Object, error) {
+		clusterRole := &rbacv1.ClusterRole{}
+		if err := h.client.Get(ctx, types.NamespacedName{Name: clusterRoleName}, clusterRole); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return clusterRole, nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(undelegable) > 0 {
+		return &delegationError{namespace: groupPermission.Namespace, undelegable: undelegable}
+	}
+	return nil
+}
+
+// granterError is returned when a GroupPermission binds roles in Namespaces whose allowed granters
+// exclude its requester
+type granterError struct {
+	user       string
+	namespaces []string
+}
+
+func (e *granterError) Error() string {
+	return fmt.Sprintf("Namespaces %s only allow GroupPermissions authored by members of their %s annotation, %s is not one",
+		strings.Join(e.namespaces, ", "), operatorconfig.AllowedGrantersAnnotation, e.user)
+}
+
+// validateGranters returns a granterError when a Permission of groupPermission matches Namespaces whose
+// allowed granters exclude the user of userInfo. Only the regexes of the Permissions are matched, the
+// controller checks the Namespaces bound in the end. Updates leaving the spec unchanged, and those of the
+// operator, are allowed since they keep the recorded requester.
+func (h *requesterRecorder) validateGranters(ctx context.Context, groupPermission *managedv1alpha1.GroupPermission, old *managedv1alpha1.GroupPermission, userInfo authenticationv1.UserInfo) error {
+	if len(groupPermission.Spec.Permissions) == 0 || isOperator(userInfo) {
+		return nil
+	}
+	if old != nil && reflect.DeepEqual(old.Spec, groupPermission.Spec) {
+		return nil
+	}
+	namespaceList := &corev1.NamespaceList{}
+	if err := h.client.List(ctx, &client.ListOptions{}, namespaceList); err != nil {
+		return err
+	}
+	var denied []string
+	for i := range namespaceList.Items {
+		namespace := &namespaceList.Items[i]
+		if utility.IsAllowedGranter(namespace, userInfo.Groups) {
+			continue
+		}
+		for _, permission := range groupPermission.Spec.Permissions {
+			if utility.PermissionNamespaceMatcher(permission).Matches(namespace.Name) {
+				denied = append(denied, namespace.Name)
+				break
+			}
+		}
+	}
+	if len(denied) > 0 {
+		return &granterError{user: userInfo.Username, namespaces: denied}
+	}
+	return nil
+}
+
+// fanOutError is returned when a GroupPermission labeled as created for a Group selected by the
+// groupNameSelector of another GroupPermission is not the one the operator creates for it
+type fanOutError struct {
+	message string
+}
+
+func (e *fanOutError) Error() string {
+	return e.message
+}
+
+// inheritFanOutRequester records on a GroupPermission created for a Group selected by the
+// groupNameSelector of another GroupPermission the requester of the latter, who granted the permissions,
+// instead of the operator creating it. The label must not let anyone else borrow that requester, so the
+// GroupPermission is rejected unless its name and spec are those the operator creates for a Group the
+// selector matches.
+func (h *requesterRecorder) inheritFanOutRequester(ctx context.Context, groupPermission *managedv1alpha1.GroupPermission) error {
+	parentName, ok := groupPermission.Labels[operatorconfig.FanOutParentLabel]
+	if !ok {
+		return nil
+	}
+	parent := &managedv1alpha1.GroupPermission{}
+	err := h.client.Get(ctx, types.NamespacedName{Namespace: groupPermission.Namespace, Name: parentName}, parent)
+	if errors.IsNotFound(err) {
+		return &fanOutError{message: fmt.Sprintf("GroupPermission %s of label %s does not exist", parentName, operatorconfig.FanOutParentLabel)}
+	}
+	if err != nil {
+		return err
+	}
+	if parent.Spec.GroupNameSelector == nil {
+		return &fanOutError{message: fmt.Sprintf("GroupPermission %s of label %s has no groupNameSelector", parentName, operatorconfig.FanOutParentLabel)}
+	}
+
+	groupName := groupPermission.Spec.GroupName
+	if groupPermission.Name != utility.FanOutName(parentName, groupName) || !reflect.DeepEqual(groupPermission.Spec, utility.FanOutSpec(&parent.Spec, groupName)) {
+		return &fanOutError{message: fmt.Sprintf("GroupPermission is not the one GroupPermission %s creates for Group %s", parentName, groupName)}
+	}
+	selector, err := metav1.LabelSelectorAsSelector(parent.Spec.GroupNameSelector)
+	if err != nil {
+		return &fanOutError{message: fmt.Sprintf("invalid groupNameSelector of GroupPermission %s: %v", parentName, err)}
+	}
+	group := &unstructured.Unstructured{}
+	group.SetGroupVersionKind(groupGVK)
+	err = h.client.Get(ctx, types.NamespacedName{Name: groupName}, group)
+	if errors.IsNotFound(err) {
+		return &fanOutError{message: fmt.Sprintf("Group %s does not exist", groupName)}
+	}
+	if err != nil {
+		return err
+	}
+	if !selector.Matches(labels.Set(group.GetLabels())) {
+		return &fanOutError{message: fmt.Sprintf("Group %s is not selected by the groupNameSelector of GroupPermission %s", groupName, parentName)}
+	}
+
+	utility.CopyRequesterAnnotations(groupPermission.Annotations, parent.Annotations)
+	return nil
+}
@@ -0,0 +1,549 @@
+package grouppermission
+
+import (
+	"context"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func mockGroupPermission(annotations map[string]string) *v1alpha1.GroupPermission {
+	return &v1alpha1.GroupPermission{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "testGroupPermission",
+			Namespace:   "rbac-permissions-operator",
+			Annotations: annotations,
+		},
+		Spec: v1alpha1.GroupPermissionSpec{
+			GroupName:          "exampleGroupName",
+			ClusterPermissions: []string{"exampleClusterRoleName"},
+		},
+	}
+}
+
+func TestRecordRequester(t *testing.T) {
+	user := authenticationv1.UserInfo{
+		Username: "alice",
+		UID:      "alice-uid",
+		Groups:   []string{"team-a", "system:authenticated"},
+		Extra:    map[string]authenticationv1.ExtraValue{"scopes.authorization.openshift.io": {"user:info"}},
+	}
+	recorded := func() map[string]string {
+		return map[string]string{
+			operatorconfig.RequesterAnnotation:       "bob",
+			operatorconfig.RequesterGroupsAnnotation: "team-b",
+		}
+	}
+	forged := func() map[string]string {
+		return map[string]string{
+			operatorconfig.RequesterAnnotation:    "cluster-admin",
+			operatorconfig.RequesterUIDAnnotation: "cluster-admin-uid",
+		}
+	}
+
+	changedSpec := mockGroupPermission(recorded())
+	changedSpec.Spec.ClusterPermissions = []string{"other"}
+
+	var tests = []struct {
+		label          string
+		groupPerm      *v1alpha1.GroupPermission
+		old            *v1alpha1.GroupPermission
+		expectedUser   string
+		expectedGroups string
+		expectedUID    string
+		expectedExtra  string
+	}{
+		{"create", mockGroupPermission(nil), nil, "alice", "team-a,system:authenticated", "alice-uid", `{"scopes.authorization.openshift.io":["user:info"]}`},
+		{"create with forged annotation", mockGroupPermission(forged()), nil, "alice", "team-a,system:authenticated", "alice-uid", `{"scopes.authorization.openshift.io":["user:info"]}`},
+		{"update of spec", mockGroupPermission(recorded()), changedSpec, "alice", "team-a,system:authenticated", "alice-uid", `{"scopes.authorization.openshift.io":["user:info"]}`},
+		{"update of metadata only", mockGroupPermission(nil), mockGroupPermission(recorded()), "bob", "team-b", "", ""},
+		{"update with forged annotation", mockGroupPermission(forged()), mockGroupPermission(recorded()), "bob", "team-b", "", ""},
+	}
+
+	for _, test := range tests {
+		recordRequester(test.groupPerm, test.old, user)
+		if found := test.groupPerm.Annotations[operatorconfig.RequesterAnnotation]; found != test.expectedUser {
+			t.Errorf("%s: Mismatch for requester. Expected(%s), Found(%s)", test.label, test.expectedUser, found)
+		}
+		if found := test.groupPerm.Annotations[operatorconfig.RequesterGroupsAnnotation]; found != test.expectedGroups {
+			t.Errorf("%s: Mismatch for requester groups. Expected(%s), Found(%s)", test.label, test.expectedGroups, found)
+		}
+		if found := test.groupPerm.Annotations[operatorconfig.RequesterUIDAnnotation]; found != test.expectedUID {
+			t.Errorf("%s: Mismatch for requester UID. Expected(%s), Found(%s)", test.label, test.expectedUID, found)
+		}
+		if found := test.groupPerm.Annotations[operatorconfig.RequesterExtraAnnotation]; found != test.expectedExtra {
+			t.Errorf("%s: Mismatch for requester extra. Expected(%s), Found(%s)", test.label, test.expectedExtra, found)
+		}
+	}
+}
+
+// TestRecordRequesterRollback tests the recordRequester function on the update restoring a spec
+// given: a GroupPermission rolled back to a previous spec by the operator, and by another user
+// expected: the requester of the rollback is kept when restored by the operator only
+func TestRecordRequesterRollback(t *testing.T) {
+	old := mockGroupPermission(map[string]string{
+		operatorconfig.RequesterAnnotation:       "bob",
+		operatorconfig.RequesterGroupsAnnotation: "team-b",
+	})
+	old.Spec.RollbackToGeneration = 2
+	operator := authenticationv1.UserInfo{Username: "system:serviceaccount:" + operatorconfig.OperatorNamespace + ":rbac-permissions-operator"}
+	other := authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a"}}
+
+	var tests = []struct {
+		label        string
+		user         authenticationv1.UserInfo
+		expectedUser string
+	}{
+		{"restored by the operator", operator, "bob"},
+		{"restored by another user", other, "alice"},
+	}
+
+	for _, test := range tests {
+		groupPermission := mockGroupPermission(nil)
+		groupPermission.Spec.ClusterPermissions = []string{"previous"}
+		recordRequester(groupPermission, old, test.user)
+		if found := groupPermission.Annotations[operatorconfig.RequesterAnnotation]; found != test.expectedUser {
+			t.Errorf("%s: Mismatch for requester. Expected(%s), Found(%s)", test.label, test.expectedUser, found)
+		}
+	}
+}
+
+// TestRecordRequesterGrantRemoval tests the recordRequester function on the update removing grants
+// given: a GroupPermission stripped of a ClusterPermission and a Permission, as by the stale grant reaper,
+// by the operator and by another user, and updated by the operator with a new grant
+// expected: the requester of the spec is kept when the operator only removes grants
+func TestRecordRequesterGrantRemoval(t *testing.T) {
+	old := mockGroupPermission(map[string]string{
+		operatorconfig.RequesterAnnotation:       "bob",
+		operatorconfig.RequesterGroupsAnnotation: "team-b",
+	})
+	old.Spec.ClusterPermissions = []string{"view", "edit", "admin"}
+	old.Spec.Permissions = []v1alpha1.Permission{{ClusterRoleName: "edit", NamespacesAllowedRegex: "^team-.*"}, {ClusterRoleName: "admin", NamespacesAllowedRegex: "^team-a$"}}
+	operator := authenticationv1.UserInfo{Username: "system:serviceaccount:" + operatorconfig.OperatorNamespace + ":rbac-permissions-operator"}
+	other := authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a"}}
+
+	removed := func() *v1alpha1.GroupPermission {
+		groupPermission := old.DeepCopy()
+		groupPermission.Annotations = nil
+		groupPermission.Spec.ClusterPermissions = []string{"view", "admin"}
+		groupPermission.Spec.Permissions = old.Spec.Permissions[1:]
+		return groupPermission
+	}
+	added := removed()
+	added.Spec.ClusterPermissions = []string{"view", "cluster-admin"}
+
+	var tests = []struct {
+		label        string
+		groupPerm    *v1alpha1.GroupPermission
+		user         authenticationv1.UserInfo
+		expectedUser string
+	}{
+		{"grants removed by the operator", removed(), operator, "bob"},
+		{"grants removed by another user", removed(), other, "alice"},
+		{"grant added by the operator", added, operator, operator.Username},
+	}
+
+	for _, test := range tests {
+		recordRequester(test.groupPerm, old, test.user)
+		if found := test.groupPerm.Annotations[operatorconfig.RequesterAnnotation]; found != test.expectedUser {
+			t.Errorf("%s: Mismatch for requester. Expected(%s), Found(%s)", test.label, test.expectedUser, found)
+		}
+	}
+}
+
+// TestApplyServiceAccountsNamespace tests the applyServiceAccountsNamespace function
+// given: GroupPermissions granting the service accounts of a namespace, with and without a GroupName
+// expected: the group of the service accounts set when unset, an error when another group is set
+func TestApplyServiceAccountsNamespace(t *testing.T) {
+	groupPermission := mockGroupPermission(nil)
+	groupPermission.Spec.GroupName = ""
+	groupPermission.Spec.ServiceAccountsNamespace = "ci"
+	if err := applyServiceAccountsNamespace(groupPermission); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if groupPermission.Spec.GroupName != "system:serviceaccounts:ci" {
+		t.Errorf("expected the group of the service accounts of ci, got %s", groupPermission.Spec.GroupName)
+	}
+
+	conflicting := mockGroupPermission(nil)
+	conflicting.Spec.ServiceAccountsNamespace = "ci"
+	if err := applyServiceAccountsNamespace(conflicting); err == nil {
+		t.Errorf("expected an error for groupName %s", conflicting.Spec.GroupName)
+	}
+}
+
+// TestValidateSubjectAPIGroup tests the validateSubjectAPIGroup function
+// given: GroupPermissions created and updated with valid, invalid and changed API groups of the subject
+// expected: an error for an invalid API group and for a changed one, unsetting the default is no change
+func TestValidateSubjectAPIGroup(t *testing.T) {
+	withAPIGroup := func(apiGroup string) *v1alpha1.GroupPermission {
+		groupPermission := mockGroupPermission(nil)
+		groupPermission.Spec.SubjectAPIGroup = apiGroup
+		return groupPermission
+	}
+
+	var tests = []struct {
+		label string
+		gp    *v1alpha1.GroupPermission
+		old   *v1alpha1.GroupPermission
+		valid bool
+	}{
+		{"created with the default", withAPIGroup(""), nil, true},
+		{"created with another API group", withAPIGroup("idp.example.com"), nil, true},
+		{"created with an invalid API group", withAPIGroup("idp.example.com/v1"), nil, false},
+		{"updated unchanged", withAPIGroup("idp.example.com"), withAPIGroup("idp.example.com"), true},
+		{"default set explicitly", withAPIGroup("rbac.authorization.k8s.io"), withAPIGroup(""), true},
+		{"changed", withAPIGroup("idp.example.com"), withAPIGroup(""), false},
+	}
+
+	for _, test := range tests {
+		if err := validateSubjectAPIGroup(test.gp, test.old); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.label, test.valid, err)
+		}
+	}
+}
+
+// TestValidateRoleRefAPIGroup tests the validateRoleRefAPIGroup function
+// given: GroupPermissions created and updated with various RoleRef API groups
+// expected: an error when the API group is invalid or changed by an update
+func TestValidateRoleRefAPIGroup(t *testing.T) {
+	withAPIGroup := func(apiGroup string) *v1alpha1.GroupPermission {
+		groupPermission := mockGroupPermission(nil)
+		groupPermission.Spec.RoleRefAPIGroup = apiGroup
+		return groupPermission
+	}
+
+	var tests = []struct {
+		label string
+		gp    *v1alpha1.GroupPermission
+		old   *v1alpha1.GroupPermission
+		valid bool
+	}{
+		{"created with the default", withAPIGroup(""), nil, true},
+		{"created with another API group", withAPIGroup("authz.example.com"), nil, true},
+		{"created with an invalid API group", withAPIGroup("authz.example.com/v1"), nil, false},
+		{"updated unchanged", withAPIGroup("authz.example.com"), withAPIGroup("authz.example.com"), true},
+		{"default set explicitly", withAPIGroup("rbac.authorization.k8s.io"), withAPIGroup(""), true},
+		{"changed", withAPIGroup("authz.example.com"), withAPIGroup(""), false},
+	}
+
+	for _, test := range tests {
+		if err := validateRoleRefAPIGroup(test.gp, test.old); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.label, test.valid, err)
+		}
+	}
+}
+
+// TestValidateJustification tests the validateJustification function
+// given: GroupPermissions created and updated to grant a sensitive role, with and without a justification
+// and a ticket URL
+// expected: an error when the spec grants a sensitive role without both, unless the spec is unchanged
+func TestValidateJustification(t *testing.T) {
+	operatorConfig := &operatorconfig.OperatorConfig{SensitiveRoles: []string{"cluster-admin"}}
+	granting := func(clusterRoleName string, justified bool) *v1alpha1.GroupPermission {
+		groupPermission := mockGroupPermission(nil)
+		groupPermission.Spec.ClusterPermissions = []string{clusterRoleName}
+		if justified {
+			groupPermission.Spec.Justification = "incident response"
+			groupPermission.Spec.TicketURL = "https://issues.example.com/OHSS-1"
+		}
+		return groupPermission
+	}
+
+	var tests = []struct {
+		label string
+		gp    *v1alpha1.GroupPermission
+		old   *v1alpha1.GroupPermission
+		valid bool
+	}{
+		{"created with another role", granting("view", false), nil, true},
+		{"created without justification", granting("cluster-admin", false), nil, false},
+		{"created with justification", granting("cluster-admin", true), nil, true},
+		{"updated without justification", granting("cluster-admin", false), granting("view", false), false},
+		{"updated unchanged", granting("cluster-admin", false), granting("cluster-admin", false), true},
+	}
+
+	for _, test := range tests {
+		if err := validateJustification(test.gp, test.old, operatorConfig); (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.label, test.valid, err)
+		}
+	}
+}
+
+// TestValidateRegexes tests the validateRegexes function
+// given: GroupPermissions created and updated with a valid and an invalid namespace regex
+// expected: an error with the offset of the syntax error when the regex is invalid, unless the spec is unchanged
+func TestValidateRegexes(t *testing.T) {
+	matching := func(regex string) *v1alpha1.GroupPermission {
+		groupPermission := mockGroupPermission(nil)
+		groupPermission.Spec.Permissions = []v1alpha1.Permission{{ClusterRoleName: "view", NamespacesAllowedRegex: regex}}
+		return groupPermission
+	}
+
+	var tests = []struct {
+		label string
+		gp    *v1alpha1.GroupPermission
+		old   *v1alpha1.GroupPermission
+		err   string
+	}{
+		{"created valid", matching("^team-.*"), nil, ""},
+		{"created invalid", matching("^team-[a-"), nil, `namespacesAllowedRegex of permissions[0] is invalid at offset 6, missing closing ]: "[a-"`},
+		{"updated invalid", matching("^team-[a-"), matching("^team-.*"), `namespacesAllowedRegex of permissions[0] is invalid at offset 6, missing closing ]: "[a-"`},
+		{"updated unchanged", matching("^team-[a-"), matching("^team-[a-"), ""},
+	}
+
+	for _, test := range tests {
+		err := validateRegexes(test.gp, test.old)
+		if test.err == "" && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.label, err)
+		}
+		if test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("%s: Mismatch for error. Expected(%s), Found(%v)", test.label, test.err, err)
+		}
+	}
+}
+
+// TestValidateRollback tests the validateRollback function
+// given: GroupPermissions of generation 3 rolling back to a previous, the current and a negative
+// generation, and a GroupPermission created with a rollback
+// expected: an error unless the generation is a previous one
+func TestValidateRollback(t *testing.T) {
+	rollingBack := func(generation, target int64) *v1alpha1.GroupPermission {
+		groupPermission := mockGroupPermission(nil)
+		groupPermission.Generation = generation
+		groupPermission.Spec.RollbackToGeneration = target
+		return groupPermission
+	}
+
+	var tests = []struct {
+		label string
+		gp    *v1alpha1.GroupPermission
+		old   *v1alpha1.GroupPermission
+		valid bool
+	}{
+		{"no rollback", rollingBack(3, 0), rollingBack(3, 0), true},
+		{"previous generation", rollingBack(3, 2), rollingBack(3, 0), true},
+		{"current generation", rollingBack(3, 3), rollingBack(3, 0), false},
+		{"negative generation", rollingBack(3, -1), rollingBack(3, 0), false},
+		{"created", rollingBack(0, 2), nil, false},
+	}
+
+	for _, test := range tests {
+		if err := validateRollback(test.gp, test.old); (err == nil) != test.valid {
+			t.Errorf("%s: Mismatch for valid. Expected(%t), Found(%v)", test.label, test.valid, err)
+		}
+	}
+}
+
+// TestValidateSpec tests the ValidateSpec function
+// given: GroupPermissions failing a single check of the webhook each, and a valid one for service accounts
+// expected: the reason of the failing check, none for the valid one whose GroupName is set
+func TestValidateSpec(t *testing.T) {
+	operatorConfig := &operatorconfig.OperatorConfig{SensitiveRoles: []string{"cluster-admin"}}
+	serviceAccounts := mockGroupPermission(nil)
+	serviceAccounts.Spec.GroupName = ""
+	serviceAccounts.Spec.ServiceAccountsNamespace = "team-a"
+	conflicting := serviceAccounts.DeepCopy()
+	conflicting.Spec.GroupName = "exampleGroupName"
+	invalidRegex := mockGroupPermission(nil)
+	invalidRegex.Spec.Permissions = []v1alpha1.Permission{{ClusterRoleName: "view", NamespacesAllowedRegex: "^team-[a-"}}
+	sensitive := mockGroupPermission(nil)
+	sensitive.Spec.ClusterPermissions = []string{"cluster-admin"}
+	invalidTags := mockGroupPermission(nil)
+	invalidTags.Spec.Tags = []string{"pci,sox"}
+
+	var tests = []struct {
+		label  string
+		gp     *v1alpha1.GroupPermission
+		reason string
+	}{
+		{"service accounts", serviceAccounts, ""},
+		{"conflicting service accounts", conflicting, reasonServiceAccountsNamespace},
+		{"invalid regex", invalidRegex, reasonRegex},
+		{"sensitive role without justification", sensitive, reasonJustification},
+		{"tag with a comma", invalidTags, reasonTags},
+	}
+
+	for _, test := range tests {
+		reason, err := ValidateSpec(test.gp, nil, operatorConfig)
+		if reason != test.reason || (err == nil) != (test.reason == "") {
+			t.Errorf("%s: Mismatch for reason. Expected(%q), Found(%q, %v)", test.label, test.reason, reason, err)
+		}
+	}
+	if expected := utility.ServiceAccountsGroupName("team-a"); serviceAccounts.Spec.GroupName != expected {
+		t.Errorf("Mismatch for GroupName. Expected(%s), Found(%s)", expected, serviceAccounts.Spec.GroupName)
+	}
+}
+
+// TestValidateDelegation tests the validateDelegation function
+// given: GroupPermissions of a delegated Namespace granting ClusterRoles delegable to it or not, and one
+// of another Namespace
+// expected: an error when a role not delegable to the delegated Namespace is granted, unless the spec is unchanged
+func TestValidateDelegation(t *testing.T) {
+	operatorConfig := &operatorconfig.OperatorConfig{DelegatedNamespaces: []string{"rbac-permissions-operator"}}
+	h := &requesterRecorder{client: fake.NewFakeClient(
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "view", Labels: map[string]string{utility.DelegableLabel("rbac-permissions-operator"): "true"}}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "admin"}})}
+	granting := func(namespace, clusterRoleName string) *v1alpha1.GroupPermission {
+		groupPermission := mockGroupPermission(nil)
+		groupPermission.Namespace = namespace
+		groupPermission.Spec.ClusterPermissions = []string{clusterRoleName}
+		return groupPermission
+	}
+
+	var tests = []struct {
+		label string
+		gp    *v1alpha1.GroupPermission
+		old   *v1alpha1.GroupPermission
+		valid bool
+	}{
+		{"created with a delegable role", granting("rbac-permissions-operator", "view"), nil, true},
+		{"created with a role not delegable", granting("rbac-permissions-operator", "admin"), nil, false},
+		{"created with a missing role", granting("rbac-permissions-operator", "missing"), nil, false},
+		{"created in another namespace", granting("team-a", "admin"), nil, true},
+		{"updated to a role not delegable", granting("rbac-permissions-operator", "admin"), granting("rbac-permissions-operator", "view"), false},
+		{"updated unchanged", granting("rbac-permissions-operator", "admin"), granting("rbac-permissions-operator", "admin"), true},
+	}
+
+	for _, test := range tests {
+		err := h.validateDelegation(context.TODO(), test.gp, test.old, operatorConfig)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.label, test.valid, err)
+		}
+		if _, ok := err.(*delegationError); err != nil && !ok {
+			t.Errorf("%s: expected a delegationError, got %v", test.label, err)
+		}
+	}
+}
+
+// TestValidateGranters tests the validateGranters function
+// given: GroupPermissions binding roles in Namespaces restricting their granters to team-a-admins or not,
+// created and updated by members of team-a-admins or not, and by the operator
+// expected: a granterError when a restricted Namespace is matched by a requester outside team-a-admins,
+// unless the spec is unchanged or the operator makes the change
+func TestValidateGranters(t *testing.T) {
+	h := &requesterRecorder{client: fake.NewFakeClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: map[string]string{operatorconfig.AllowedGrantersAnnotation: "team-a-admins"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}})}
+	binding := func(namespacesAllowedRegex string) *v1alpha1.GroupPermission {
+		groupPermission := mockGroupPermission(nil)
+		groupPermission.Spec.Permissions = []v1alpha1.Permission{{ClusterRoleName: "admin", NamespacesAllowedRegex: namespacesAllowedRegex}}
+		return groupPermission
+	}
+	member := authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a-admins"}}
+	other := authenticationv1.UserInfo{Username: "bob", Groups: []string{"team-b-admins"}}
+	operator := authenticationv1.UserInfo{Username: "system:serviceaccount:" + operatorconfig.OperatorNamespace + ":rbac-permissions-operator"}
+
+	var tests = []struct {
+		label    string
+		gp       *v1alpha1.GroupPermission
+		old      *v1alpha1.GroupPermission
+		userInfo authenticationv1.UserInfo
+		valid    bool
+	}{
+		{"created by a member", binding("^team-.*"), nil, member, true},
+		{"created by another requester", binding("^team-.*"), nil, other, false},
+		{"created by another requester out of the restricted namespace", binding("^team-b$"), nil, other, true},
+		{"updated by another requester into the restricted namespace", binding("^team-.*"), binding("^team-b$"), other, false},
+		{"updated unchanged by another requester", binding("^team-.*"), binding("^team-.*"), other, true},
+		{"updated by the operator", binding("^team-.*"), binding("^team-b$"), operator, true},
+	}
+
+	for _, test := range tests {
+		err := h.validateGranters(context.TODO(), test.gp, test.old, test.userInfo)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.label, test.valid, err)
+		}
+		if _, ok := err.(*granterError); err != nil && !ok {
+			t.Errorf("%s: expected a granterError, got %v", test.label, err)
+		}
+	}
+}
+
+// TestInheritFanOutRequester tests the inheritFanOutRequester function
+// given: GroupPermissions labeled as created for a Group selected by another GroupPermission, as the
+// operator creates them or not, and one without the label
+// expected: the requester of the selecting GroupPermission recorded on the first, a fanOutError for the
+// others labeled, and no change without the label
+func TestInheritFanOutRequester(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	parent := mockGroupPermission(map[string]string{operatorconfig.RequesterAnnotation: "alice", operatorconfig.RequesterGroupsAnnotation: "admins"})
+	parent.Name = "tenants"
+	parent.Spec.GroupName = ""
+	parent.Spec.GroupNameSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team-kind": "tenant"}}
+	group := func(name string, labels map[string]string) *unstructured.Unstructured {
+		group := &unstructured.Unstructured{}
+		group.SetGroupVersionKind(groupGVK)
+		group.SetName(name)
+		group.SetLabels(labels)
+		return group
+	}
+	h := &requesterRecorder{client: fake.NewFakeClient(parent, group("team-a", map[string]string{"team-kind": "tenant"}), group("ops", nil))}
+	fanOut := func(groupName string) *v1alpha1.GroupPermission {
+		groupPermission := mockGroupPermission(map[string]string{operatorconfig.RequesterAnnotation: "system:serviceaccount:openshift-rbac-permissions-operator:rbac-permissions-operator"})
+		groupPermission.Name = utility.FanOutName(parent.Name, groupName)
+		groupPermission.Labels = map[string]string{operatorconfig.FanOutParentLabel: parent.Name}
+		groupPermission.Spec = utility.FanOutSpec(&parent.Spec, groupName)
+		return groupPermission
+	}
+	escalated := fanOut("team-a")
+	escalated.Spec.ClusterPermissions = append(escalated.Spec.ClusterPermissions, "cluster-admin")
+
+	var tests = []struct {
+		label     string
+		gp        *v1alpha1.GroupPermission
+		valid     bool
+		requester string
+	}{
+		{"created for a selected Group", fanOut("team-a"), true, "alice"},
+		{"created with another spec", escalated, false, ""},
+		{"created for a Group not selected", fanOut("ops"), false, ""},
+		{"created for a missing Group", fanOut("team-b"), false, ""},
+		{"not labeled", mockGroupPermission(map[string]string{operatorconfig.RequesterAnnotation: "bob"}), true, "bob"},
+	}
+
+	for _, test := range tests {
+		err := h.inheritFanOutRequester(context.TODO(), test.gp)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: expected valid %t, got %v", test.label, test.valid, err)
+		}
+		if _, ok := err.(*fanOutError); err != nil && !ok {
+			t.Errorf("%s: expected a fanOutError, got %v", test.label, err)
+		}
+		if requester := test.gp.Annotations[operatorconfig.RequesterAnnotation]; test.valid && requester != test.requester {
+			t.Errorf("%s: Mismatch for requester. Expected(%s), Found(%s)", test.label, test.requester, requester)
+		}
+	}
+}
+
+// TestRecordWarnings tests the recordWarnings function
+// given: a GroupPermission with a hand written group of service accounts, then fixed
+// expected: the warning annotation set, then removed
+func TestRecordWarnings(t *testing.T) {
+	groupPermission := mockGroupPermission(nil)
+	groupPermission.Spec.GroupName = "system:serviceaccount:ci:builder"
+	if warnings := recordWarnings(groupPermission); len(warnings) != 1 || groupPermission.Annotations[operatorconfig.AdmissionWarningsAnnotation] == "" {
+		t.Errorf("expected a warning recorded, got %v, %v", warnings, groupPermission.Annotations)
+	}
+
+	groupPermission.Spec.GroupName = "system:serviceaccounts:ci"
+	groupPermission.Spec.ServiceAccountsNamespace = "ci"
+	if warnings := recordWarnings(groupPermission); len(warnings) != 0 {
+		t.Errorf("expected no warning, got %v", warnings)
+	}
+	if _, ok := groupPermission.Annotations[operatorconfig.AdmissionWarningsAnnotation]; ok {
+		t.Errorf("expected the warning annotation removed, got %v", groupPermission.Annotations)
+	}
+}
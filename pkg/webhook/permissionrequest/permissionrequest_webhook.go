@@ -0,0 +1,115 @@
+package permissionrequest
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"time"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	managedv1alpha1 "github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	"github.com/openshift/rbac-permissions-operator/pkg/localmetrics"
+	"github.com/openshift/rbac-permissions-operator/pkg/utility"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+var log = logf.Log.WithName("webhook_permissionrequest")
+
+// webhookName labels the metrics of the webhook
+const webhookName = "permissionrequest"
+
+// Reasons of the rejections of PermissionRequests, as counted by the metrics of the webhook
+const (
+	reasonDecode = "decode"
+	reasonPatch  = "patch"
+)
+
+// BuildMutating builds the mutating admission webhook for PermissionRequest objects
+func BuildMutating(mgr manager.Manager) (*admission.Webhook, error) {
+	return builder.NewWebhookBuilder().
+		Name("mutating.permissionrequest.managed.openshift.io").
+		Mutating().
+		Path("/mutate-permissionrequests").
+		Operations(admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update).
+		ForType(&managedv1alpha1.PermissionRequest{}).
+		FailurePolicy(admissionregistrationv1beta1.Fail).
+		WithManager(mgr).
+		Handlers(&requesterRecorder{}).
+		Build()
+}
+
+// requesterRecorder records the user requesting a PermissionRequest as an annotation, the user the
+// controller verifies is a member of its Group and allowed to bind its ClusterRole
+type requesterRecorder struct {
+	decoder atypes.Decoder
+}
+
+// blank assignment to verify that requesterRecorder implements admission.Handler
+var _ admission.Handler = &requesterRecorder{}
+
+// Handle records the requester on the incoming PermissionRequest. The duration and rejection reason of
+// the review are recorded in the metrics.
+func (h *requesterRecorder) Handle(ctx context.Context, req atypes.Request) atypes.Response {
+	start := time.Now()
+	response, reason := h.handle(req)
+	allowed := response.Response != nil && response.Response.Allowed
+	if !allowed && reason == "" {
+		reason = reasonPatch
+	}
+	localmetrics.ObserveWebhookReview(webhookName, allowed, reason, time.Since(start))
+	return response
+}
+
+// handle reviews the incoming PermissionRequest, and returns the reason it is rejected, if it is
+func (h *requesterRecorder) handle(req atypes.Request) (atypes.Response, string) {
+	instance := &managedv1alpha1.PermissionRequest{}
+	if err := h.decoder.Decode(req, instance); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err), reasonDecode
+	}
+
+	var old *managedv1alpha1.PermissionRequest
+	if req.AdmissionRequest.Operation == admissionv1beta1.Update {
+		old = &managedv1alpha1.PermissionRequest{}
+		if err := h.decoder.Decode(atypes.Request{
+			AdmissionRequest: &admissionv1beta1.AdmissionRequest{Object: req.AdmissionRequest.OldObject},
+		}, old); err != nil {
+			return admission.ErrorResponse(http.StatusBadRequest, err), reasonDecode
+		}
+	}
+
+	mutated := instance.DeepCopy()
+	recordRequester(mutated, old, req.AdmissionRequest.UserInfo)
+	log.Info("Recorded requester", "Namespace", mutated.Namespace, "Name", mutated.Name, "Requester", mutated.Annotations[operatorconfig.RequesterAnnotation])
+
+	return admission.PatchResponse(instance, mutated), ""
+}
+
+// InjectDecoder injects the decoder into the requesterRecorder
+func (h *requesterRecorder) InjectDecoder(d atypes.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+// recordRequester sets the requester annotations on permissionRequest. When the spec is unchanged by an
+// update the annotations of the old object are kept, so metadata-only changes can neither claim nor forge
+// the identity of whoever requested the ClusterRole.
+func recordRequester(permissionRequest *managedv1alpha1.PermissionRequest, old *managedv1alpha1.PermissionRequest, userInfo authenticationv1.UserInfo) {
+	if permissionRequest.Annotations == nil {
+		permissionRequest.Annotations = map[string]string{}
+	}
+
+	if old != nil && reflect.DeepEqual(old.Spec, permissionRequest.Spec) {
+		utility.CopyRequesterAnnotations(permissionRequest.Annotations, old.Annotations)
+		return
+	}
+
+	utility.SetRequesterAnnotations(permissionRequest.Annotations, userInfo)
+}
@@ -0,0 +1,102 @@
+package permissionrequest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis"
+	"github.com/openshift/rbac-permissions-operator/pkg/apis/managed/v1alpha1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	atypes "sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+func mockPermissionRequest(requester, clusterRoleName string) *v1alpha1.PermissionRequest {
+	permissionRequest := &v1alpha1.PermissionRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testPermissionRequest",
+			Namespace: "team-a",
+		},
+		Spec: v1alpha1.PermissionRequestSpec{
+			GroupName:       "team-a-devs",
+			ClusterRoleName: clusterRoleName,
+		},
+	}
+	if requester != "" {
+		permissionRequest.Annotations = map[string]string{operatorconfig.RequesterAnnotation: requester}
+	}
+	return permissionRequest
+}
+
+// TestRecordRequester tests the recordRequester function
+// given: PermissionRequests created, updated with and without a change of spec, with and without a forged requester
+// expected: the requester is the user creating or changing the spec, kept on metadata-only updates
+func TestRecordRequester(t *testing.T) {
+	user := authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a-devs"}}
+
+	var tests = []struct {
+		label             string
+		permissionRequest *v1alpha1.PermissionRequest
+		old               *v1alpha1.PermissionRequest
+		expectedUser      string
+	}{
+		{"create", mockPermissionRequest("", "view"), nil, "alice"},
+		{"create with forged annotation", mockPermissionRequest("cluster-admin", "view"), nil, "alice"},
+		{"metadata update", mockPermissionRequest("", "view"), mockPermissionRequest("bob", "view"), "bob"},
+		{"metadata update with forged annotation", mockPermissionRequest("cluster-admin", "view"), mockPermissionRequest("bob", "view"), "bob"},
+		{"metadata update without recorded requester", mockPermissionRequest("cluster-admin", "view"), mockPermissionRequest("", "view"), ""},
+		{"spec update", mockPermissionRequest("bob", "edit"), mockPermissionRequest("bob", "view"), "alice"},
+	}
+
+	for _, test := range tests {
+		recordRequester(test.permissionRequest, test.old, user)
+		if found := test.permissionRequest.Annotations[operatorconfig.RequesterAnnotation]; found != test.expectedUser {
+			t.Errorf("%s: Mismatch for requester. Expected(%s), Found(%s)", test.label, test.expectedUser, found)
+		}
+	}
+}
+
+// TestHandle tests the Handle function
+// given: a PermissionRequest created with a forged requester
+// expected: the creation is allowed and patched with the requester and their groups
+func TestHandle(t *testing.T) {
+	if err := apis.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatalf("Unable to add apis scheme: (%v)", err)
+	}
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := &requesterRecorder{decoder: decoder}
+	data, err := json.Marshal(mockPermissionRequest("cluster-admin", "view"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	response := handler.Handle(context.TODO(), atypes.Request{AdmissionRequest: &admissionv1beta1.AdmissionRequest{
+		Operation: admissionv1beta1.Create,
+		Object:    runtime.RawExtension{Raw: data},
+		UserInfo:  authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a-devs"}},
+	}})
+	if !response.Response.Allowed {
+		t.Fatalf("Mismatch for allowed. Expected(true), Found(%+v)", response.Response.Result)
+	}
+	patched := map[string]string{}
+	for _, patch := range response.Patches {
+		if value, ok := patch.Value.(string); ok {
+			patched[patch.Path] = value
+		}
+	}
+	if user := patched["/metadata/annotations/managed.openshift.io~1requester"]; user != "alice" {
+		t.Errorf("Mismatch for the patched requester. Expected(alice), Found(%v)", response.Patches)
+	}
+	if groups := patched["/metadata/annotations/managed.openshift.io~1requester-groups"]; groups != "team-a-devs" {
+		t.Errorf("Mismatch for the patched requester groups. Expected(team-a-devs), Found(%v)", response.Patches)
+	}
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	operatorconfig "github.com/openshift/rbac-permissions-operator/config"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	serverPort    int32 = 9443
+	serverCertDir       = "/tmp/cert"
+)
+
+// BuildFuncs is a list of functions to build all admission webhooks served by the operator
+var BuildFuncs []func(manager.Manager) (*admission.Webhook, error)
+
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups="",resources=services;secrets,verbs=get;list;watch;create;update,namespace=openshift-rbac-permissions-operator
+
+// AddToManager builds all admission webhooks and registers them with a webhook server
+// that is added to the Manager
+func AddToManager(m manager.Manager) error {
+	disableConfigInstaller := false
+	svr, err := webhook.NewServer(operatorconfig.OperatorName+"-admission-server", m, webhook.ServerOptions{
+		Port:                          serverPort,
+		CertDir:                       serverCertDir,
+		DisableWebhookConfigInstaller: &disableConfigInstaller,
+		BootstrapOptions: &webhook.BootstrapOptions{
+			MutatingWebhookConfigName: operatorconfig.OperatorName + "-mutating",
+			Secret: &types.NamespacedName{
+				Namespace: operatorconfig.OperatorNamespace,
+				Name:      operatorconfig.OperatorName + "-webhook-cert",
+			},
+			Service: &webhook.Service{
+				Namespace: operatorconfig.OperatorNamespace,
+				Name:      operatorconfig.OperatorName + "-webhook",
+				Selectors: map[string]string{
+					"name": operatorconfig.OperatorName,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var webhooks []webhook.Webhook
+	for _, f := range BuildFuncs {
+		wh, err := f(m)
+		if err != nil {
+			return err
+		}
+		webhooks = append(webhooks, wh)
+	}
+	return svr.Register(webhooks...)
+}
@@ -2,4 +2,6 @@ package version
 
 var (
 	Version = "0.0.1"
+	// Commit is the commit the operator is built from, set at build time
+	Commit = "unknown"
 )